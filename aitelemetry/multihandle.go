@@ -0,0 +1,62 @@
+package aitelemetry
+
+import "sync"
+
+// multiHandle sends telemetry to several handles, e.g. appinsights and an OTLP collector side by side.
+type multiHandle []TelemetryHandle
+
+// NewMultiTelemetry returns a handle that sends telemetry to all the handles. Nil handles are skipped and a single
+// handle is returned as is.
+func NewMultiTelemetry(handles ...TelemetryHandle) TelemetryHandle {
+	var m multiHandle
+	for _, th := range handles {
+		if th != nil {
+			m = append(m, th)
+		}
+	}
+
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
+	}
+	return m
+}
+
+func (m multiHandle) TrackLog(report Report) {
+	for _, th := range m {
+		th.TrackLog(report)
+	}
+}
+
+func (m multiHandle) TrackMetric(metric Metric) {
+	for _, th := range m {
+		th.TrackMetric(metric)
+	}
+}
+
+func (m multiHandle) TrackEvent(event Event) {
+	for _, th := range m {
+		th.TrackEvent(event)
+	}
+}
+
+// Close closes the handles concurrently so they share the timeout.
+func (m multiHandle) Close(timeout int) {
+	var wg sync.WaitGroup
+	for _, th := range m {
+		wg.Add(1)
+		go func(th TelemetryHandle) {
+			defer wg.Done()
+			th.Close(timeout)
+		}(th)
+	}
+	wg.Wait()
+}
+
+func (m multiHandle) Flush() {
+	for _, th := range m {
+		th.Flush()
+	}
+}
@@ -0,0 +1,326 @@
+package aitelemetry
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTLP protocols
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+const (
+	instrumentationName = "github.com/Azure/azure-container-networking/aitelemetry"
+	eventNameKey        = "event.name"
+	messageKey          = "message"
+	severityKey         = "severity"
+	reportSpanName      = "report"
+	resourceIDKey       = "ResourceID"
+	contextKey          = "Context"
+)
+
+var errUnknownOTLPProtocol = errors.New("unknown otlp protocol")
+
+// OTLPConfig configures the export of telemetry to an OpenTelemetry collector.
+type OTLPConfig struct {
+	AppName    string
+	AppVersion string
+	// Endpoint is the host:port of the collector.
+	Endpoint string
+	// Protocol is grpc or http/protobuf, grpc if empty.
+	Protocol string
+	// Insecure disables TLS to the collector.
+	Insecure bool
+	// Headers are sent with each export, e.g. for authentication.
+	Headers map[string]string
+	// BatchInterval is the interval in seconds at which queued telemetry is exported.
+	BatchInterval                int
+	DisableMetadataRefreshThread bool
+	RefreshTimeout               int
+	DebugMode                    bool
+}
+
+// otlpHandle sends reports and events as spans and metrics as gauges to an OTLP collector, using only the stable
+// trace and metric SDKs. It also registers its tracer provider globally, so spans started with otel.Tracer are
+// exported with them.
+type otlpHandle struct {
+	appName                      string
+	appVersion                   string
+	hostName                     string
+	tracerProvider               *sdktrace.TracerProvider
+	meterProvider                *sdkmetric.MeterProvider
+	tracer                       trace.Tracer
+	meter                        metric.Meter
+	gauges                       sync.Map
+	metadata                     common.Metadata
+	disableMetadataRefreshThread bool
+	refreshTimeout               int
+	debugMode                    bool
+	rwmutex                      sync.RWMutex
+}
+
+// NewOTLPTelemetry creates a telemetry handle that exports to an OpenTelemetry collector.
+func NewOTLPTelemetry(config OTLPConfig) (TelemetryHandle, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("OTLP endpoint is empty")
+	}
+
+	if config.BatchInterval == 0 {
+		config.BatchInterval = defaultBatchIntervalInSecs
+	}
+	if config.RefreshTimeout == 0 {
+		config.RefreshTimeout = defaultRefreshTimeoutInSecs
+	}
+	interval := time.Duration(config.BatchInterval) * time.Second
+
+	ctx := context.Background()
+	hostName, _ := os.Hostname()
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(config.AppName),
+		semconv.ServiceVersion(config.AppVersion),
+		semconv.HostName(hostName),
+		semconv.OSTypeKey.String(runtime.GOOS),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create otlp resource")
+	}
+
+	spanExporter, metricExporter, err := newOTLPExporters(ctx, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	th := &otlpHandle{
+		appName:  config.AppName,
+		hostName: hostName,
+		tracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithBatcher(spanExporter, sdktrace.WithBatchTimeout(interval)),
+		),
+		meterProvider: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(interval))),
+		),
+		appVersion:                   config.AppVersion,
+		disableMetadataRefreshThread: config.DisableMetadataRefreshThread,
+		refreshTimeout:               config.RefreshTimeout,
+		debugMode:                    config.DebugMode,
+	}
+	th.tracer = th.tracerProvider.Tracer(instrumentationName)
+	th.meter = th.meterProvider.Meter(instrumentationName)
+	otel.SetTracerProvider(th.tracerProvider)
+
+	if th.disableMetadataRefreshThread {
+		th.getMetadata()
+	} else {
+		go th.getMetadata()
+	}
+
+	return th, nil
+}
+
+// newOTLPExporters creates the span and metric exporters for the configured protocol.
+func newOTLPExporters(ctx context.Context, config *OTLPConfig) (sdktrace.SpanExporter, sdkmetric.Exporter, error) {
+	switch config.Protocol {
+	case "", OTLPProtocolGRPC:
+		traceOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint), otlptracegrpc.WithHeaders(config.Headers)}
+		metricOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(config.Endpoint), otlpmetricgrpc.WithHeaders(config.Headers)}
+		if config.Insecure {
+			traceOpts = append(traceOpts, otlptracegrpc.WithInsecure())
+			metricOpts = append(metricOpts, otlpmetricgrpc.WithInsecure())
+		}
+
+		spanExporter, err := otlptracegrpc.New(ctx, traceOpts...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create otlp span exporter")
+		}
+		metricExporter, err := otlpmetricgrpc.New(ctx, metricOpts...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create otlp metric exporter")
+		}
+		return spanExporter, metricExporter, nil
+
+	case OTLPProtocolHTTP:
+		traceOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint), otlptracehttp.WithHeaders(config.Headers)}
+		metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(config.Endpoint), otlpmetrichttp.WithHeaders(config.Headers)}
+		if config.Insecure {
+			traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+			metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+		}
+
+		spanExporter, err := otlptracehttp.New(ctx, traceOpts...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create otlp span exporter")
+		}
+		metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create otlp metric exporter")
+		}
+		return spanExporter, metricExporter, nil
+	}
+
+	return nil, nil, errors.Wrapf(errUnknownOTLPProtocol, "%s", config.Protocol)
+}
+
+// getMetadata loads the host metadata which is added to all telemetry once available.
+func (th *otlpHandle) getMetadata() {
+	for {
+		metadata, err := common.GetHostMetadata(metadataFile)
+		if err == nil {
+			th.rwmutex.Lock()
+			th.metadata = metadata
+			th.rwmutex.Unlock()
+			return
+		}
+
+		if th.disableMetadataRefreshThread {
+			th.debugLog("[OTLP] Error getting metadata %v", err)
+			return
+		}
+
+		th.debugLog("[OTLP] Error getting metadata %v. Sleep for %d", err, th.refreshTimeout)
+		time.Sleep(time.Duration(th.refreshTimeout) * time.Second)
+	}
+}
+
+// commonAttributes returns the app and host metadata attributes, the same ones appinsights telemetry carries
+// as properties.
+func (th *otlpHandle) commonAttributes(appVersion string) map[string]string {
+	if appVersion == "" {
+		appVersion = th.appVersion
+	}
+	attrs := map[string]string{
+		appNameStr:  th.appName,
+		versionStr:  appVersion,
+		osStr:       runtime.GOOS,
+		hostNameKey: th.hostName,
+	}
+
+	th.rwmutex.RLock()
+	metadata := th.metadata
+	th.rwmutex.RUnlock()
+
+	if metadata.SubscriptionID != "" {
+		attrs[subscriptionIDStr] = metadata.SubscriptionID
+		attrs[locationStr] = metadata.Location
+		attrs[resourceGroupStr] = metadata.ResourceGroupName
+		attrs[vmSizeStr] = metadata.VMSize
+		attrs[osVersionStr] = metadata.OSVersion
+		attrs[vmIDStr] = metadata.VMID
+		attrs[vmNameStr] = metadata.VMName
+	}
+	return attrs
+}
+
+// emit sends a span without duration carrying the attributes, which is how the report or event shows up in the
+// collector next to the spans of the app.
+func (th *otlpHandle) emit(name, severity string, attrs map[string]string) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs)+1)
+	kvs = append(kvs, attribute.String(severityKey, severity))
+	for key, value := range attrs {
+		kvs = append(kvs, attribute.String(key, value))
+	}
+	_, span := th.tracer.Start(context.Background(), name, trace.WithAttributes(kvs...))
+	span.End()
+}
+
+// TrackLog sends the report as a span, the message and custom dimensions are added as attributes.
+func (th *otlpHandle) TrackLog(report Report) {
+	attrs := th.commonAttributes(report.AppVersion)
+	attrs[contextKey] = report.Context
+	for key, value := range report.CustomDimensions {
+		attrs[key] = value
+	}
+	attrs[messageKey] = report.Message
+	th.emit(reportSpanName, "WARN", attrs)
+}
+
+// TrackEvent sends the event as a span named after the event.
+func (th *otlpHandle) TrackEvent(event Event) {
+	attrs := th.commonAttributes("")
+	for key, value := range event.Properties {
+		attrs[key] = value
+	}
+	attrs[eventNameKey] = event.EventName
+	attrs[resourceIDKey] = event.ResourceID
+	th.emit(event.EventName, "INFO", attrs)
+}
+
+// TrackMetric records the metric value on a gauge of the same name, the custom dimensions are added as attributes.
+func (th *otlpHandle) TrackMetric(m Metric) {
+	gauge, ok := th.gauges.Load(m.Name)
+	if !ok {
+		g, err := th.meter.Float64Gauge(m.Name)
+		if err != nil {
+			th.debugLog("[OTLP] Error creating gauge %s: %v", m.Name, err)
+			return
+		}
+		gauge, _ = th.gauges.LoadOrStore(m.Name, g)
+	}
+
+	attrs := th.commonAttributes(m.AppVersion)
+	for key, value := range m.CustomDimensions {
+		attrs[key] = value
+	}
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		kvs = append(kvs, attribute.String(key, value))
+	}
+	gauge.(metric.Float64Gauge).Record(context.Background(), m.Value, metric.WithAttributes(kvs...))
+}
+
+// Close flushes the queued telemetry and shuts the exporters down, waiting up to timeout seconds.
+func (th *otlpHandle) Close(timeout int) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	if err := th.tracerProvider.Shutdown(ctx); err != nil {
+		th.debugLog("[OTLP] Error shutting down tracer provider: %v", err)
+	}
+	if err := th.meterProvider.Shutdown(ctx); err != nil {
+		th.debugLog("[OTLP] Error shutting down meter provider: %v", err)
+	}
+}
+
+// Flush forces the queued telemetry to be exported.
+func (th *otlpHandle) Flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout*time.Second)
+	defer cancel()
+
+	if err := th.tracerProvider.ForceFlush(ctx); err != nil {
+		th.debugLog("[OTLP] Error flushing spans: %v", err)
+	}
+	if err := th.meterProvider.ForceFlush(ctx); err != nil {
+		th.debugLog("[OTLP] Error flushing metrics: %v", err)
+	}
+}
+
+func (th *otlpHandle) debugLog(format string, args ...interface{}) {
+	if th.debugMode {
+		log.Printf(format, args...)
+	}
+}
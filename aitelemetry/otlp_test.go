@@ -0,0 +1,156 @@
+package aitelemetry
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// fakeCollector is an OTLP/gRPC collector keeping the exported spans and metrics.
+type fakeCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	colmetricspb.UnimplementedMetricsServiceServer
+
+	mu      sync.Mutex
+	spans   []*tracepb.Span
+	metrics []*metricspb.Metric
+}
+
+func (c *fakeCollector) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			c.spans = append(c.spans, ss.GetSpans()...)
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+type fakeMetricsService struct {
+	*fakeCollector
+}
+
+func (c fakeMetricsService) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			c.metrics = append(c.metrics, sm.GetMetrics()...)
+		}
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func startFakeCollector(t *testing.T) (*fakeCollector, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	c := &fakeCollector{}
+	srv := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(srv, c)
+	colmetricspb.RegisterMetricsServiceServer(srv, fakeMetricsService{c})
+	go srv.Serve(lis) //nolint:errcheck // stopped with the test
+	t.Cleanup(srv.Stop)
+	return c, lis.Addr().String()
+}
+
+func attributes(kvs []*commonpb.KeyValue) map[string]string {
+	attrs := map[string]string{}
+	for _, kv := range kvs {
+		attrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return attrs
+}
+
+func TestOTLPTelemetry(t *testing.T) {
+	collector, endpoint := startFakeCollector(t)
+
+	otlp, err := NewOTLPTelemetry(OTLPConfig{
+		AppName:                      "testapp",
+		AppVersion:                   "v1.0.0",
+		Endpoint:                     endpoint,
+		Insecure:                     true,
+		DisableMetadataRefreshThread: true,
+	})
+	require.NoError(t, err)
+
+	otlp.TrackLog(Report{Message: "test report", Context: "ctx", CustomDimensions: map[string]string{"dim": "value"}})
+	otlp.TrackEvent(Event{EventName: "test event", ResourceID: "nc1", Properties: map[string]string{"prop": "value"}})
+	otlp.TrackMetric(Metric{Name: "test_metric", Value: 2.5, CustomDimensions: map[string]string{"dim": "value"}})
+	otlp.Flush()
+
+	collector.mu.Lock()
+	require.Len(t, collector.spans, 2)
+	report := collector.spans[0]
+	require.Equal(t, reportSpanName, report.GetName())
+	attrs := attributes(report.GetAttributes())
+	require.Equal(t, "test report", attrs[messageKey])
+	require.Equal(t, "WARN", attrs[severityKey])
+	require.Equal(t, "value", attrs["dim"])
+	require.Equal(t, "ctx", attrs[contextKey])
+	require.Equal(t, "testapp", attrs[appNameStr])
+	require.Equal(t, "v1.0.0", attrs[versionStr])
+
+	require.Equal(t, "test event", collector.spans[1].GetName())
+	attrs = attributes(collector.spans[1].GetAttributes())
+	require.Equal(t, "test event", attrs[eventNameKey])
+	require.Equal(t, "nc1", attrs[resourceIDKey])
+	require.Equal(t, "value", attrs["prop"])
+
+	require.Len(t, collector.metrics, 1)
+	require.Equal(t, "test_metric", collector.metrics[0].GetName())
+	points := collector.metrics[0].GetGauge().GetDataPoints()
+	require.Len(t, points, 1)
+	require.InDelta(t, 2.5, points[0].GetAsDouble(), 0)
+	require.Equal(t, "value", attributes(points[0].GetAttributes())["dim"])
+	collector.mu.Unlock()
+
+	otlp.Close(1)
+}
+
+func TestNewOTLPTelemetryErrors(t *testing.T) {
+	_, err := NewOTLPTelemetry(OTLPConfig{})
+	require.Error(t, err)
+
+	_, err = NewOTLPTelemetry(OTLPConfig{Endpoint: "localhost:4317", Protocol: "udp"})
+	require.ErrorIs(t, err, errUnknownOTLPProtocol)
+}
+
+type countingHandle struct {
+	logs, metrics, events, flushes, closes int
+}
+
+func (c *countingHandle) TrackLog(Report)    { c.logs++ }
+func (c *countingHandle) TrackMetric(Metric) { c.metrics++ }
+func (c *countingHandle) TrackEvent(Event)   { c.events++ }
+func (c *countingHandle) Close(int)          { c.closes++ }
+func (c *countingHandle) Flush()             { c.flushes++ }
+
+func TestMultiTelemetry(t *testing.T) {
+	require.Nil(t, NewMultiTelemetry(nil, nil))
+
+	a := &countingHandle{}
+	require.Same(t, a, NewMultiTelemetry(nil, a))
+
+	b := &countingHandle{}
+	m := NewMultiTelemetry(a, nil, b)
+	m.TrackLog(Report{})
+	m.TrackMetric(Metric{})
+	m.TrackEvent(Event{})
+	m.Flush()
+	m.Close(1)
+	for _, h := range []*countingHandle{a, b} {
+		require.Equal(t, countingHandle{logs: 1, metrics: 1, events: 1, flushes: 1, closes: 1}, *h)
+	}
+}
@@ -1,92 +1,130 @@
 package main
 
 import (
-	"bytes"
-	"net"
-	"os/exec"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/config"
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/daemon"
 	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/egress"
 	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/ingress"
-	"github.com/vishvananda/netlink"
-
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/metrics"
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/tc"
+	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/rlimit"
+	"github.com/google/nftables"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
-var logger *zap.Logger
+// slbProbeTable is the nftables table which rewrote the health probe addresses before these programs.
+const slbProbeTable = "azureSLBProbe"
+
+var version string
 
 func main() {
-	// Set up logger
-	config := zap.NewProductionConfig()
-	config.OutputPaths = []string{"stdout", "/var/log/azure-ipv6-hp-bpf.log"}
-	logger, _ = config.Build()
+	configPath := flag.String("config", config.DefaultPath, "path of the config file")
+	flag.Parse()
 
-	// Remove resource limits for kernels <5.11.
-	if err := rlimit.RemoveMemlock(); err != nil {
-		logger.Error("Removing memlock", zap.Error(err))
-		return
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Check 'nft -n list tables ip6' to see if table exists
-	cmd := exec.Command("nft", "-n", "list", "tables", "ip6")
-	output, err := cmd.CombinedOutput()
+	zcfg := zap.NewProductionConfig()
+	zcfg.OutputPaths = []string{"stdout", cfg.LogFile}
+	logger, err := zcfg.Build()
 	if err != nil {
-		logger.Error("error running 'nft -n list tables ip6'", zap.Error(err), zap.String("output", string(output)))
-		return
+		fmt.Fprintf(os.Stderr, "failed to create logger: %v\n", err)
+		os.Exit(1)
 	}
+	defer logger.Sync() //nolint:errcheck // best effort
+	logger.Info("Starting ipv6-hp-bpf", zap.String("version", version), zap.Any("config", cfg))
 
-	// if azureSLBProbe table exists, delete it
-	if bytes.Contains(output, []byte("azureSLBProbe")) {
-		cmd := exec.Command("nft", "delete", "table", "ip6", "azureSLBProbe")
-		err = cmd.Run()
-		if err != nil {
-			logger.Error("failed to run 'nft delete table ip6 azureSLBProbe'", zap.Error(err))
-			return
-		}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := run(ctx, cfg, logger); err != nil {
+		logger.Error("Exiting", zap.Error(err))
+		os.Exit(1) //nolint:gocritic // the deferred cancel is not needed on exit
 	}
+	logger.Info("Detached filters, exiting")
+}
 
-	ifname := "eth0"
-	iface, err := net.InterfaceByName(ifname)
-	if err != nil {
-		logger.Error("Getting interface", zap.String("interface", ifname), zap.Error(err))
+func run(ctx context.Context, cfg *config.Config, logger *zap.Logger) error {
+	// Remove resource limits for kernels <5.11.
+	if err := rlimit.RemoveMemlock(); err != nil {
+		return fmt.Errorf("failed to remove memlock: %w", err)
 	}
-	logger.Info("Interface has index", zap.String("interface", ifname), zap.Int("index", iface.Index))
 
-	// Create a qdisc filter for traffic on the interface.
-	fq := &netlink.GenericQdisc{
-		QdiscAttrs: netlink.QdiscAttrs{
-			LinkIndex: iface.Index,
-			Handle:    netlink.MakeHandle(0xffff, 0),
-			Parent:    netlink.HANDLE_CLSACT,
-		},
-		QdiscType: "clsact",
-	}
-	if err := netlink.QdiscReplace(fq); err != nil {
-		logger.Error("failed setting egress qdisc", zap.Error(err))
-		return
+	if !cfg.KeepNftTable {
+		if err := removeSLBProbeTable(logger); err != nil {
+			return err
+		}
 	}
 
-	// Load the compiled eBPF ELF and load it into the kernel.
-	// Set up ingress and egress filters to attach to eth0 clsact qdisc
+	if err := os.MkdirAll(cfg.PinPath, 0o700); err != nil { //nolint:gomnd // only root reads the pins
+		return fmt.Errorf("failed to create pin path %s: %w", cfg.PinPath, err)
+	}
 	var objsEgress egress.EgressObjects
+	if err := egress.Load(&objsEgress, cfg.PinPath); err != nil {
+		return err
+	}
 	defer objsEgress.Close()
-	if err := egress.LoadEgressObjects(&objsEgress, nil); err != nil {
-		logger.Error("Failed to load eBPF egress objects", zap.Error(err))
+	var objsIngress ingress.IngressObjects
+	if err := ingress.Load(&objsIngress, cfg.PinPath); err != nil {
+		return err
 	}
-	if err := egress.SetupEgressFilter(iface.Index, &objsEgress, logger); err != nil {
-		logger.Error("Setting up egress filter", zap.Error(err))
-	} else {
-		logger.Info("Successfully set egress filter on", zap.String("interface", ifname))
+	defer objsIngress.Close()
+
+	prometheus.MustRegister(metrics.NewCounterCollector(logger, map[string]*ebpf.Map{
+		"egress":  objsEgress.EgressCounters,
+		"ingress": objsIngress.IngressCounters,
+	}))
+	srv := &http.Server{
+		Addr:              cfg.MetricsAddress,
+		Handler:           promhttp.Handler(),
+		ReadHeaderTimeout: 10 * time.Second, //nolint:gomnd // default
 	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Failed to serve metrics", zap.Error(err))
+		}
+	}()
+	defer srv.Close()
 
-	var objsIngress ingress.IngressObjects
-	if err := ingress.LoadIngressObjects(&objsIngress, nil); err != nil {
-		logger.Error("Loading eBPF ingress objects", zap.Error(err))
+	selector := daemon.Selector{Patterns: cfg.Interfaces, MAC: cfg.MAC()}
+	filters := []tc.Filter{egress.Filter(&objsEgress), ingress.Filter(&objsIngress)}
+	return daemon.New(logger, selector, filters, cfg.ResyncInterval()).Run(ctx) //nolint:wrapcheck // already wrapped
+}
+
+// removeSLBProbeTable deletes the azureSLBProbe nftables table, if it exists.
+func removeSLBProbeTable(logger *zap.Logger) error {
+	conn, err := nftables.New()
+	if err != nil {
+		return fmt.Errorf("failed to connect to nftables: %w", err)
 	}
-	defer objsIngress.Close()
-	if err := ingress.SetupIngressFilter(iface.Index, &objsIngress, logger); err != nil {
-		logger.Error("Setting up ingress filter", zap.Error(err))
-	} else {
-		logger.Info("Successfully set ingress filter on", zap.String("interface", ifname))
+	tables, err := conn.ListTablesOfFamily(nftables.TableFamilyIPv6)
+	if err != nil {
+		return fmt.Errorf("failed to list ip6 nftables tables: %w", err)
+	}
+	for _, table := range tables {
+		if table.Name != slbProbeTable {
+			continue
+		}
+		conn.DelTable(table)
+		if err := conn.Flush(); err != nil {
+			return fmt.Errorf("failed to delete nftables table ip6 %s: %w", slbProbeTable, err)
+		}
+		logger.Info("Deleted nftables table", zap.String("table", slbProbeTable))
 	}
+	return nil
 }
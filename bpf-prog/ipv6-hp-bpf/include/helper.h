@@ -4,6 +4,21 @@
 #define L4_HDR_OFF (ETH_HLEN + sizeof(struct ipv6hdr))
 #define BPF_F_PSEUDO_HDR (1ULL << 4)
 
+// Indexes of the counters in the per-CPU counter map of each program. They must match pkg/metrics.
+enum counter
+{
+    COUNTER_REWRITTEN = 0, // packets with a probe address which was rewritten
+    COUNTER_DROPPED,       // packets dropped because the rewrite failed
+    COUNTER_MAX,
+};
+
+static __always_inline void increment_counter(void *map, __u32 key)
+{
+    __u64 *value = bpf_map_lookup_elem(map, &key);
+    if (value)
+        *value += 1;
+}
+
 static __always_inline bool compare_ipv6_addr(const struct in6_addr *addr1, const struct in6_addr *addr2)
 {
 #pragma unroll
@@ -0,0 +1,94 @@
+// Package probepacket builds the IPv6 TCP health probe packets the programs are tested with.
+package probepacket
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+const (
+	ethHeaderLen  = 14
+	ipv6HeaderLen = 40
+	tcpHeaderLen  = 20
+
+	protoTCP  = 6
+	protoUDP  = 17
+	etherIPv6 = 0x86dd
+
+	// SrcOffset and DstOffset are the offsets of the IPv6 source and destination addresses in the packet.
+	SrcOffset = ethHeaderLen + 8
+	DstOffset = ethHeaderLen + 24
+)
+
+var (
+	// LinkLocal is the address the Azure load balancer health probes are sent from.
+	LinkLocal = netip.MustParseAddr("fe80::1234:5678:9abc")
+	// GlobalUnicast is the address the programs translate LinkLocal to for the pods.
+	GlobalUnicast = netip.MustParseAddr("2603:1062:0:1:fe80:1234:5678:9abc")
+)
+
+// TCP returns an Ethernet frame of an IPv6 TCP SYN from src to dst, with a valid TCP checksum.
+func TCP(src, dst netip.Addr) []byte {
+	return build(src, dst, protoTCP)
+}
+
+// UDP returns an Ethernet frame of an IPv6 UDP header from src to dst.
+func UDP(src, dst netip.Addr) []byte {
+	return build(src, dst, protoUDP)
+}
+
+func build(src, dst netip.Addr, proto byte) []byte {
+	pkt := make([]byte, ethHeaderLen+ipv6HeaderLen+tcpHeaderLen)
+	binary.BigEndian.PutUint16(pkt[12:], etherIPv6)
+
+	ip := pkt[ethHeaderLen:]
+	ip[0] = 6 << 4
+	binary.BigEndian.PutUint16(ip[4:], tcpHeaderLen)
+	ip[6] = proto
+	ip[7] = 64
+	s, d := src.As16(), dst.As16()
+	copy(ip[8:], s[:])
+	copy(ip[24:], d[:])
+
+	l4 := ip[ipv6HeaderLen:]
+	binary.BigEndian.PutUint16(l4[0:], 51234)
+	binary.BigEndian.PutUint16(l4[2:], 80)
+	if proto == protoTCP {
+		l4[12] = 5 << 4 // data offset
+		l4[13] = 0x02   // SYN
+		binary.BigEndian.PutUint16(l4[14:], 64240)
+		binary.BigEndian.PutUint16(l4[16:], ^checksum(pkt))
+	}
+	return pkt
+}
+
+// Addrs returns the source and destination addresses of the packet.
+func Addrs(pkt []byte) (src, dst netip.Addr) {
+	src = netip.AddrFrom16([16]byte(pkt[SrcOffset : SrcOffset+16]))
+	dst = netip.AddrFrom16([16]byte(pkt[DstOffset : DstOffset+16]))
+	return src, dst
+}
+
+// ValidTCPChecksum returns whether the TCP checksum of the packet is valid for its addresses.
+func ValidTCPChecksum(pkt []byte) bool {
+	return checksum(pkt) == 0xffff
+}
+
+// checksum returns the ones' complement sum of the IPv6 pseudo header and the TCP segment of the packet.
+func checksum(pkt []byte) uint16 {
+	ip := pkt[ethHeaderLen:]
+	l4 := ip[ipv6HeaderLen:]
+	var sum uint32
+	add := func(b []byte) {
+		for i := 0; i+1 < len(b); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i:]))
+		}
+	}
+	add(ip[8:40])
+	sum += uint32(len(l4)) + uint32(ip[6])
+	add(l4)
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return uint16(sum)
+}
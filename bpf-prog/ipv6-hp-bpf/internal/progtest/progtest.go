@@ -0,0 +1,92 @@
+// Package progtest runs the probepacket test packets through the loaded programs.
+package progtest
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/internal/probepacket"
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/rlimit"
+)
+
+const (
+	// tcActUnspec is TC_ACT_UNSPEC, which lets the packet continue.
+	tcActUnspec = ^uint32(0)
+	// counterRewritten is COUNTER_REWRITTEN in include/helper.h.
+	counterRewritten uint32 = 0
+)
+
+// Load removes the memlock limit and loads the programs with load, skipping the test if they can't be loaded.
+func Load(t *testing.T, load func() error) {
+	t.Helper()
+	if err := rlimit.RemoveMemlock(); err != nil {
+		t.Skipf("can't remove memlock: %v", err)
+	}
+	if err := load(); err != nil {
+		t.Skipf("can't load the program, requires CAP_BPF: %v", err)
+	}
+}
+
+// Case is a packet run through a program, and what the program must do with it.
+type Case struct {
+	Name string
+	Pkt  []byte
+	// Want is the address the program must leave in the packet, at the side Run checks.
+	Want netip.Addr
+	// Rewritten is the increase of COUNTER_REWRITTEN, a rewritten packet must have a valid TCP checksum.
+	Rewritten uint64
+}
+
+// Src returns the source address of a packet.
+func Src(pkt []byte) netip.Addr {
+	src, _ := probepacket.Addrs(pkt)
+	return src
+}
+
+// Dst returns the destination address of a packet.
+func Dst(pkt []byte) netip.Addr {
+	_, dst := probepacket.Addrs(pkt)
+	return dst
+}
+
+// Run runs each Case through prog, which must let the packet continue, and checks the address returned by addr and
+// the rewritten counter of the per-CPU counters map.
+func Run(t *testing.T, prog *ebpf.Program, counters *ebpf.Map, addr func([]byte) netip.Addr, tests []Case) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			before := counter(t, counters, counterRewritten)
+			out := make([]byte, len(tt.Pkt))
+			ret, err := prog.Run(&ebpf.RunOptions{Data: tt.Pkt, DataOut: out})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ret != tcActUnspec {
+				t.Fatalf("got verdict %d, want TC_ACT_UNSPEC", int32(ret))
+			}
+			if got := addr(out); got != tt.Want {
+				t.Fatalf("got address %s, want %s", got, tt.Want)
+			}
+			if tt.Rewritten > 0 && !probepacket.ValidTCPChecksum(out) {
+				t.Fatal("invalid TCP checksum after rewrite")
+			}
+			if got := counter(t, counters, counterRewritten) - before; got != tt.Rewritten {
+				t.Fatalf("rewritten counter increased by %d, want %d", got, tt.Rewritten)
+			}
+		})
+	}
+}
+
+func counter(t *testing.T, counters *ebpf.Map, key uint32) uint64 {
+	t.Helper()
+	var values []uint64
+	if err := counters.Lookup(key, &values); err != nil {
+		t.Fatal(err)
+	}
+	var sum uint64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
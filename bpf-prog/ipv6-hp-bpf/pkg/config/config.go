@@ -0,0 +1,106 @@
+// Package config contains the config file of the ipv6-hp-bpf daemon.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"time"
+)
+
+// DefaultPath is where the daemon reads its config from if no path is given.
+const DefaultPath = "/etc/azure-ipv6-hp-bpf/config.json"
+
+// Config of the daemon. The zero value of each field is replaced by its default.
+type Config struct {
+	// Interfaces are the name patterns, as in path.Match, of the interfaces the programs are attached to. Defaults
+	// to eth0 if PrimaryMAC is not set either.
+	Interfaces []string `json:"interfaces"`
+	// PrimaryMAC selects the interface with this MAC address, whatever its name, in addition to Interfaces.
+	PrimaryMAC string `json:"primaryMAC"`
+	// MetricsAddress is the address the Prometheus metrics are served on.
+	MetricsAddress string `json:"metricsAddress"`
+	// PinPath is the bpffs directory the counter maps are pinned in, so that they persist across restarts.
+	PinPath string `json:"pinPath"`
+	// ResyncIntervalSecs is how often the filters are checked and re-attached if they were removed without a link
+	// event, such as when another agent replaces the clsact qdisc.
+	ResyncIntervalSecs int `json:"resyncIntervalSecs"`
+	// KeepNftTable keeps the azureSLBProbe nftables table, which is removed by default as the programs replace it.
+	KeepNftTable bool `json:"keepNftTable"`
+	// LogFile is the file the logs are written to, in addition to stdout.
+	LogFile string `json:"logFile"`
+}
+
+// Load reads the config file at path, and sets the defaults. If the file does not exist the default config is
+// returned.
+func Load(p string) (*Config, error) {
+	c := &Config{}
+	b, err := os.ReadFile(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read config file %s: %w", p, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, c); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config file %s: %w", p, err)
+		}
+	}
+	c.setDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) setDefaults() {
+	if len(c.Interfaces) == 0 && c.PrimaryMAC == "" {
+		c.Interfaces = []string{"eth0"}
+	}
+	if c.MetricsAddress == "" {
+		c.MetricsAddress = ":9096"
+	}
+	if c.PinPath == "" {
+		c.PinPath = "/sys/fs/bpf/azure-ipv6-hp-bpf"
+	}
+	if c.ResyncIntervalSecs == 0 {
+		c.ResyncIntervalSecs = 30
+	}
+	if c.LogFile == "" {
+		c.LogFile = "/var/log/azure-ipv6-hp-bpf.log"
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	for _, pattern := range c.Interfaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid interface pattern %q: %w", pattern, err)
+		}
+	}
+	if c.PrimaryMAC != "" {
+		if _, err := net.ParseMAC(c.PrimaryMAC); err != nil {
+			return fmt.Errorf("invalid primaryMAC: %w", err)
+		}
+	}
+	if _, _, err := net.SplitHostPort(c.MetricsAddress); err != nil {
+		return fmt.Errorf("invalid metricsAddress: %w", err)
+	}
+	if c.ResyncIntervalSecs < 0 {
+		return errors.New("resyncIntervalSecs must not be negative")
+	}
+	return nil
+}
+
+// ResyncInterval returns ResyncIntervalSecs as a duration.
+func (c *Config) ResyncInterval() time.Duration {
+	return time.Duration(c.ResyncIntervalSecs) * time.Second
+}
+
+// MAC returns the parsed PrimaryMAC, or nil if it is not set.
+func (c *Config) MAC() net.HardwareAddr {
+	mac, _ := net.ParseMAC(c.PrimaryMAC)
+	return mac
+}
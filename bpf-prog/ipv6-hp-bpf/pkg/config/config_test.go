@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Interfaces:         []string{"eth0"},
+		MetricsAddress:     ":9096",
+		PinPath:            "/sys/fs/bpf/azure-ipv6-hp-bpf",
+		ResyncIntervalSecs: 30,
+		LogFile:            "/var/log/azure-ipv6-hp-bpf.log",
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("got %+v, want %+v", c, want)
+	}
+	if c.ResyncInterval() != 30*time.Second {
+		t.Fatalf("got resync interval %v", c.ResyncInterval())
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name:    "primary mac only",
+			content: `{"primaryMAC": "00:0d:3a:01:02:03", "metricsAddress": "127.0.0.1:9000", "keepNftTable": true}`,
+			want: &Config{
+				PrimaryMAC:         "00:0d:3a:01:02:03",
+				MetricsAddress:     "127.0.0.1:9000",
+				PinPath:            "/sys/fs/bpf/azure-ipv6-hp-bpf",
+				ResyncIntervalSecs: 30,
+				KeepNftTable:       true,
+				LogFile:            "/var/log/azure-ipv6-hp-bpf.log",
+			},
+		},
+		{
+			name:    "patterns",
+			content: `{"interfaces": ["eth*", "enP*"], "resyncIntervalSecs": 5}`,
+			want: &Config{
+				Interfaces:         []string{"eth*", "enP*"},
+				MetricsAddress:     ":9096",
+				PinPath:            "/sys/fs/bpf/azure-ipv6-hp-bpf",
+				ResyncIntervalSecs: 5,
+				LogFile:            "/var/log/azure-ipv6-hp-bpf.log",
+			},
+		},
+		{
+			name:    "malformed",
+			content: `{`,
+			wantErr: true,
+		},
+		{
+			name:    "bad pattern",
+			content: `{"interfaces": ["eth["]}`,
+			wantErr: true,
+		},
+		{
+			name:    "bad mac",
+			content: `{"primaryMAC": "eth0"}`,
+			wantErr: true,
+		},
+		{
+			name:    "bad metrics address",
+			content: `{"metricsAddress": "9096"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(p, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(p)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
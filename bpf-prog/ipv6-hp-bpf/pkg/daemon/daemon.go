@@ -0,0 +1,209 @@
+// Package daemon keeps the health probe programs attached to the selected interfaces as they come and go.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"time"
+
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/tc"
+	"github.com/vishvananda/netlink"
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+// Selector selects the interfaces the programs are attached to, by name pattern or by MAC address.
+type Selector struct {
+	// Patterns are matched against the interface name as in path.Match.
+	Patterns []string
+	MAC      net.HardwareAddr
+}
+
+// Matches returns whether the interface is selected.
+func (s Selector) Matches(attrs *netlink.LinkAttrs) bool {
+	if len(s.MAC) > 0 && bytes.Equal(s.MAC, attrs.HardwareAddr) {
+		return true
+	}
+	for _, pattern := range s.Patterns {
+		if ok, _ := path.Match(pattern, attrs.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type linkClient interface {
+	LinkList() ([]netlink.Link, error)
+	LinkSubscribe(ch chan<- netlink.LinkUpdate, done <-chan struct{}) error
+}
+
+type filterClient interface {
+	Attached(netlink.Link, tc.Filter) (bool, error)
+	Attach(netlink.Link, tc.Filter) error
+	Detach(netlink.Link, tc.Filter) error
+}
+
+type netlinkClient struct{}
+
+func (netlinkClient) LinkList() ([]netlink.Link, error) {
+	return netlink.LinkList() //nolint:wrapcheck // wrapped by caller
+}
+
+func (netlinkClient) LinkSubscribe(ch chan<- netlink.LinkUpdate, done <-chan struct{}) error {
+	return netlink.LinkSubscribe(ch, done) //nolint:wrapcheck // wrapped by caller
+}
+
+// Daemon attaches the filters to the selected interfaces, and keeps them attached: the interfaces are reconciled on
+// every link event and every resync interval, so the filters are attached to new interfaces and re-attached when
+// they are lost, such as after a link flap or a replace of the clsact qdisc. The filters are detached on shutdown.
+type Daemon struct {
+	log      *zap.Logger
+	selector Selector
+	filters  []tc.Filter
+	resync   time.Duration
+	links    linkClient
+	tc       filterClient
+
+	// attached are the interfaces the filters are attached to, by index.
+	attached map[int]netlink.Link
+}
+
+// New returns a Daemon which attaches the filters to the interfaces selected by selector.
+func New(log *zap.Logger, selector Selector, filters []tc.Filter, resync time.Duration) *Daemon {
+	return &Daemon{
+		log:      log,
+		selector: selector,
+		filters:  filters,
+		resync:   resync,
+		links:    netlinkClient{},
+		tc:       tc.Client{},
+		attached: map[int]netlink.Link{},
+	}
+}
+
+// Run reconciles the interfaces until ctx is done, and then detaches the filters.
+func (d *Daemon) Run(ctx context.Context) error {
+	updates := make(chan netlink.LinkUpdate, 16) //nolint:gomnd // absorbs bursts of events
+	done := make(chan struct{})
+	defer close(done)
+	if err := d.links.LinkSubscribe(updates, done); err != nil {
+		return fmt.Errorf("failed to subscribe to link updates: %w", err)
+	}
+
+	d.resyncAll()
+	var tick <-chan time.Time
+	if d.resync > 0 {
+		ticker := time.NewTicker(d.resync)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			d.detachAll()
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				d.detachAll()
+				return errors.New("link updates subscription closed")
+			}
+			d.handle(update)
+		case <-tick:
+			d.resyncAll()
+		}
+	}
+}
+
+func (d *Daemon) handle(update netlink.LinkUpdate) {
+	if update.Header.Type == unix.RTM_DELLINK {
+		attrs := update.Link.Attrs()
+		if _, ok := d.attached[attrs.Index]; ok {
+			d.log.Info("Interface deleted", zap.String("interface", attrs.Name))
+			delete(d.attached, attrs.Index)
+			attachedInterfaces.Set(float64(len(d.attached)))
+		}
+		return
+	}
+	d.reconcile(update.Link)
+}
+
+func (d *Daemon) resyncAll() {
+	links, err := d.links.LinkList()
+	if err != nil {
+		d.log.Error("Failed to list interfaces", zap.Error(err))
+		return
+	}
+	present := map[int]bool{}
+	for _, link := range links {
+		present[link.Attrs().Index] = true
+		d.reconcile(link)
+	}
+	// forget the interfaces deleted while an event may have been missed.
+	for index := range d.attached {
+		if !present[index] {
+			delete(d.attached, index)
+		}
+	}
+	attachedInterfaces.Set(float64(len(d.attached)))
+}
+
+// reconcile attaches the filters to the link if it is selected and they are missing, or detaches them if it is no
+// longer selected. The filters are always replaced the first time an interface is seen, to replace those of a
+// previous run.
+func (d *Daemon) reconcile(link netlink.Link) {
+	attrs := link.Attrs()
+	_, known := d.attached[attrs.Index]
+	if !d.selector.Matches(attrs) {
+		if known {
+			d.log.Info("Interface no longer selected, detaching filters", zap.String("interface", attrs.Name))
+			d.detach(link)
+			delete(d.attached, attrs.Index)
+			attachedInterfaces.Set(float64(len(d.attached)))
+		}
+		return
+	}
+
+	for _, f := range d.filters {
+		if known {
+			ok, err := d.tc.Attached(link, f)
+			if err != nil {
+				d.log.Error("Failed to check filter", zap.String("interface", attrs.Name), zap.String("filter", f.Name), zap.Error(err))
+				continue
+			}
+			if ok {
+				continue
+			}
+			d.log.Info("Filter is missing, re-attaching", zap.String("interface", attrs.Name), zap.String("filter", f.Name))
+			reattaches.Inc()
+		}
+		if err := d.tc.Attach(link, f); err != nil {
+			d.log.Error("Failed to attach filter", zap.String("interface", attrs.Name), zap.String("filter", f.Name), zap.Error(err))
+			attachErrors.Inc()
+			return
+		}
+		d.log.Info("Attached filter", zap.String("interface", attrs.Name), zap.Int("index", attrs.Index), zap.String("filter", f.Name))
+	}
+	d.attached[attrs.Index] = link
+	attachedInterfaces.Set(float64(len(d.attached)))
+}
+
+func (d *Daemon) detach(link netlink.Link) {
+	for _, f := range d.filters {
+		if err := d.tc.Detach(link, f); err != nil {
+			d.log.Error("Failed to detach filter", zap.String("interface", link.Attrs().Name), zap.String("filter", f.Name), zap.Error(err))
+		}
+	}
+}
+
+func (d *Daemon) detachAll() {
+	for index, link := range d.attached {
+		d.detach(link)
+		d.log.Info("Detached filters", zap.String("interface", link.Attrs().Name))
+		delete(d.attached, index)
+	}
+	attachedInterfaces.Set(0)
+}
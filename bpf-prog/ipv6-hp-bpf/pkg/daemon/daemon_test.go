@@ -0,0 +1,217 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/tc"
+	"github.com/vishvananda/netlink"
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+type fakeLinks struct {
+	links      []netlink.Link
+	subscribed chan chan<- netlink.LinkUpdate
+}
+
+func (f *fakeLinks) LinkList() ([]netlink.Link, error) {
+	return f.links, nil
+}
+
+func (f *fakeLinks) LinkSubscribe(ch chan<- netlink.LinkUpdate, _ <-chan struct{}) error {
+	if f.subscribed != nil {
+		f.subscribed <- ch
+	}
+	return nil
+}
+
+// fakeFilters records the filters attached to each interface by name.
+type fakeFilters struct {
+	sync.Mutex
+	attached map[string][]string
+	attaches int
+}
+
+func (f *fakeFilters) Attached(link netlink.Link, filter tc.Filter) (bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.attachedLocked(link, filter), nil
+}
+
+func (f *fakeFilters) attachedLocked(link netlink.Link, filter tc.Filter) bool {
+	for _, name := range f.attached[link.Attrs().Name] {
+		if name == filter.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeFilters) Attach(link netlink.Link, filter tc.Filter) error {
+	f.Lock()
+	defer f.Unlock()
+	f.attaches++
+	if !f.attachedLocked(link, filter) {
+		f.attached[link.Attrs().Name] = append(f.attached[link.Attrs().Name], filter.Name)
+	}
+	return nil
+}
+
+func (f *fakeFilters) Detach(link netlink.Link, filter tc.Filter) error {
+	f.Lock()
+	defer f.Unlock()
+	var names []string
+	for _, name := range f.attached[link.Attrs().Name] {
+		if name != filter.Name {
+			names = append(names, name)
+		}
+	}
+	f.attached[link.Attrs().Name] = names
+	if len(names) == 0 {
+		delete(f.attached, link.Attrs().Name)
+	}
+	return nil
+}
+
+func (f *fakeFilters) interfaces() []string {
+	f.Lock()
+	defer f.Unlock()
+	var names []string
+	for name := range f.attached {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func dummy(index int, name, mac string) netlink.Link {
+	hw, _ := net.ParseMAC(mac)
+	return &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Index: index, Name: name, HardwareAddr: hw}}
+}
+
+func newTestDaemon(selector Selector, links ...netlink.Link) (*Daemon, *fakeLinks, *fakeFilters) {
+	fl := &fakeLinks{links: links}
+	ff := &fakeFilters{attached: map[string][]string{}}
+	d := New(zap.NewNop(), selector, []tc.Filter{{Name: "egress"}, {Name: "ingress"}}, 0)
+	d.links = fl
+	d.tc = ff
+	return d, fl, ff
+}
+
+func TestSelector(t *testing.T) {
+	mac, _ := net.ParseMAC("00:0d:3a:01:02:03")
+	s := Selector{Patterns: []string{"eth*"}, MAC: mac}
+	tests := []struct {
+		link netlink.Link
+		want bool
+	}{
+		{dummy(1, "eth0", "00:0d:3a:aa:bb:cc"), true},
+		{dummy(2, "enP1s1", "00:0d:3a:01:02:03"), true},
+		{dummy(3, "lo", ""), false},
+		{dummy(4, "azv123", "aa:aa:aa:aa:aa:aa"), false},
+	}
+	for _, tt := range tests {
+		if got := s.Matches(tt.link.Attrs()); got != tt.want {
+			t.Errorf("Matches(%s) = %v, want %v", tt.link.Attrs().Name, got, tt.want)
+		}
+	}
+}
+
+func TestResyncAttachesSelectedInterfaces(t *testing.T) {
+	d, _, ff := newTestDaemon(Selector{Patterns: []string{"eth*"}},
+		dummy(1, "lo", ""), dummy(2, "eth0", ""), dummy(3, "eth1", ""), dummy(4, "cilium_host", ""))
+
+	d.resyncAll()
+	if got, want := ff.interfaces(), []string{"eth0", "eth1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("attached to %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(ff.attached["eth0"], []string{"egress", "ingress"}) {
+		t.Fatalf("attached %v to eth0", ff.attached["eth0"])
+	}
+
+	// filters which are still attached are left alone.
+	d.resyncAll()
+	if ff.attaches != 4 {
+		t.Fatalf("got %d attaches, want 4", ff.attaches)
+	}
+}
+
+func TestReattachMissingFilter(t *testing.T) {
+	eth0 := dummy(2, "eth0", "")
+	d, _, ff := newTestDaemon(Selector{Patterns: []string{"eth0"}}, eth0)
+	d.resyncAll()
+
+	// the qdisc was replaced, dropping the ingress filter.
+	ff.attached["eth0"] = []string{"egress"}
+	d.handle(netlink.LinkUpdate{Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK}, Link: eth0})
+	if !reflect.DeepEqual(ff.attached["eth0"], []string{"egress", "ingress"}) {
+		t.Fatalf("attached %v to eth0", ff.attached["eth0"])
+	}
+}
+
+func TestDeletedAndRecreatedInterface(t *testing.T) {
+	eth0 := dummy(2, "eth0", "")
+	d, _, ff := newTestDaemon(Selector{Patterns: []string{"eth0"}}, eth0)
+	d.resyncAll()
+
+	d.handle(netlink.LinkUpdate{Header: unix.NlMsghdr{Type: unix.RTM_DELLINK}, Link: eth0})
+	delete(ff.attached, "eth0")
+	if len(d.attached) != 0 {
+		t.Fatalf("deleted interface still attached: %v", d.attached)
+	}
+
+	// the interface comes back with a new index and has no filters.
+	eth0 = dummy(5, "eth0", "")
+	d.handle(netlink.LinkUpdate{Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK}, Link: eth0})
+	if !reflect.DeepEqual(ff.attached["eth0"], []string{"egress", "ingress"}) {
+		t.Fatalf("attached %v to eth0", ff.attached["eth0"])
+	}
+	if _, ok := d.attached[5]; !ok {
+		t.Fatalf("got attached %v", d.attached)
+	}
+}
+
+func TestRenamedInterfaceIsDetached(t *testing.T) {
+	d, _, ff := newTestDaemon(Selector{Patterns: []string{"eth0"}}, dummy(2, "eth0", ""))
+	d.resyncAll()
+
+	renamed := dummy(2, "eth9", "")
+	ff.attached["eth9"] = ff.attached["eth0"]
+	delete(ff.attached, "eth0")
+	d.handle(netlink.LinkUpdate{Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK}, Link: renamed})
+	if len(ff.attached) != 0 || len(d.attached) != 0 {
+		t.Fatalf("filters still attached: %v %v", ff.attached, d.attached)
+	}
+}
+
+func TestRunDetachesOnShutdown(t *testing.T) {
+	d, fl, ff := newTestDaemon(Selector{Patterns: []string{"eth*"}}, dummy(2, "eth0", ""))
+	fl.subscribed = make(chan chan<- netlink.LinkUpdate)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+
+	updates := <-fl.subscribed
+	updates <- netlink.LinkUpdate{Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK}, Link: dummy(3, "eth1", "")}
+	deadline := time.Now().Add(5 * time.Second)
+	for !reflect.DeepEqual(ff.interfaces(), []string{"eth0", "eth1"}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("attached to %v", ff.interfaces())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := ff.interfaces(); len(got) != 0 {
+		t.Fatalf("filters still attached after shutdown: %v", got)
+	}
+}
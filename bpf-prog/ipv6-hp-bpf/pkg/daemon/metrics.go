@@ -0,0 +1,26 @@
+package daemon
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	attachedInterfaces = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ipv6_hp_bpf_attached_interfaces",
+		Help: "Number of interfaces the health probe filters are attached to.",
+	})
+	attachErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ipv6_hp_bpf_attach_errors_total",
+		Help: "Number of failures to attach the health probe filters to an interface.",
+	})
+	reattaches = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ipv6_hp_bpf_reattaches_total",
+		Help: "Number of health probe filters found missing from an interface and re-attached.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		attachedInterfaces,
+		attachErrors,
+		reattaches,
+	)
+}
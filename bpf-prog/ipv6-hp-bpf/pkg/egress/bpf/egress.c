@@ -12,6 +12,14 @@
 #include <stdbool.h>
 #include "../../../include/helper.h"
 
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __type(key, __u32);
+    __type(value, __u64);
+    __uint(max_entries, COUNTER_MAX);
+} egress_counters SEC(".maps");
+
 SEC("classifier")
 int gua_to_linklocal(struct __sk_buff *skb)
 {
@@ -59,6 +67,7 @@ int gua_to_linklocal(struct __sk_buff *skb)
         if (ret != 0)
         {
             bpf_printk("bpf_skb_store_bytes failed to store new destination address with error code %d.\n", ret);
+            increment_counter(&egress_counters, COUNTER_DROPPED);
             return TC_ACT_SHOT;
         }
 
@@ -72,8 +81,11 @@ int gua_to_linklocal(struct __sk_buff *skb)
         if (ret < 0)
         {
             bpf_printk("csum_l4_replace failed to update checksum: %d", ret);
+            increment_counter(&egress_counters, COUNTER_DROPPED);
             return TC_ACT_SHOT;
         }
+
+        increment_counter(&egress_counters, COUNTER_REWRITTEN);
     }
 
     return TC_ACT_UNSPEC;
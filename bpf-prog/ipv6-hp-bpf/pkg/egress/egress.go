@@ -1,57 +1,43 @@
 package egress
 
 import (
-	"syscall"
+	"fmt"
 
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/tc"
+	"github.com/cilium/ebpf"
 	"github.com/vishvananda/netlink"
-	"go.uber.org/zap"
 )
 
-// SetupEgressFilter sets up the egress filter
-func SetupEgressFilter(ifaceIndex int, objs *EgressObjects, logger *zap.Logger) error {
-	link, err := netlink.LinkByIndex(ifaceIndex)
-	if err != nil {
-		logger.Error("Failed to get link", zap.Error(err))
-		return err
-	}
+const (
+	// FilterName identifies the egress filter on the interfaces.
+	FilterName = "ipv6_hp_egress"
+	// CountersMapName is the name of the map of the egress packet counters, and of its pin.
+	CountersMapName = "egress_counters"
+)
 
-	// Get the list of filters on the link
-	filters, err := netlink.FilterList(link, netlink.HANDLE_MIN_EGRESS)
+// Load loads the egress program and its counters map into the kernel. If pinPath is not empty the counters map is
+// pinned in it, and the map already pinned there by a previous run is reused.
+func Load(objs *EgressObjects, pinPath string) error {
+	spec, err := LoadEgress()
 	if err != nil {
-		logger.Error("Failed to get filter list", zap.Error(err))
-		return err
+		return fmt.Errorf("failed to load egress spec: %w", err)
 	}
-
-	// Check if egress filter exists and delete it. Filter is identified by its name.
-	// this is to avoid duplicate filters after restarting the daemonset
-	for _, filter := range filters {
-		if filter, ok := filter.(*netlink.BpfFilter); ok && filter.Name == "ipv6_hp_egress" {
-			if err := netlink.FilterDel(filter); err != nil {
-				logger.Error("Failed to delete filter", zap.Error(err))
-				return err
-			}
-			break
-		}
+	var opts ebpf.CollectionOptions
+	if pinPath != "" {
+		spec.Maps[CountersMapName].Pinning = ebpf.PinByName
+		opts.Maps.PinPath = pinPath
 	}
-
-	egressFilter := &netlink.BpfFilter{
-		FilterAttrs: netlink.FilterAttrs{
-			LinkIndex: ifaceIndex,
-			Parent:    netlink.HANDLE_MIN_EGRESS,
-			Protocol:  syscall.ETH_P_ALL,
-			Priority:  1,
-		},
-		Fd:           objs.GuaToLinklocal.FD(),
-		Name:         "ipv6_hp_egress",
-		DirectAction: true,
+	if err := spec.LoadAndAssign(objs, &opts); err != nil {
+		return fmt.Errorf("failed to load egress objects: %w", err)
 	}
+	return nil
+}
 
-	if err := netlink.FilterReplace(egressFilter); err != nil {
-		logger.Error("failed setting egress filter", zap.Error(err))
-		return err
-	} else {
-		logger.Info("Successfully set egress filter on", zap.Int("ifaceIndex", ifaceIndex))
+// Filter returns the egress filter of the loaded program.
+func Filter(objs *EgressObjects) tc.Filter {
+	return tc.Filter{
+		Name:    FilterName,
+		Parent:  netlink.HANDLE_MIN_EGRESS,
+		Program: objs.GuaToLinklocal,
 	}
-
-	return nil
 }
@@ -0,0 +1,34 @@
+package egress
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/internal/probepacket"
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/internal/progtest"
+)
+
+func TestGuaToLinklocal(t *testing.T) {
+	var objs EgressObjects
+	progtest.Load(t, func() error { return Load(&objs, "") })
+	t.Cleanup(func() { objs.Close() })
+
+	pod := probepacket.GlobalUnicast.Prev()
+	progtest.Run(t, objs.GuaToLinklocal, objs.EgressCounters, progtest.Dst, []progtest.Case{
+		{
+			Name:      "probe reply is sent to the link local address",
+			Pkt:       probepacket.TCP(pod, probepacket.GlobalUnicast),
+			Want:      probepacket.LinkLocal,
+			Rewritten: 1,
+		},
+		{
+			Name: "other destination is unchanged",
+			Pkt:  probepacket.TCP(pod, pod),
+			Want: pod,
+		},
+		{
+			Name: "udp is unchanged",
+			Pkt:  probepacket.UDP(pod, probepacket.GlobalUnicast),
+			Want: probepacket.GlobalUnicast,
+		},
+	})
+}
@@ -11,6 +11,14 @@
 #include <stdbool.h>
 #include "../../../include/helper.h"
 
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __type(key, __u32);
+    __type(value, __u64);
+    __uint(max_entries, COUNTER_MAX);
+} ingress_counters SEC(".maps");
+
 SEC("classifier")
 int linklocal_to_gua(struct __sk_buff *skb)
 {
@@ -57,6 +65,7 @@ int linklocal_to_gua(struct __sk_buff *skb)
         if (ret != 0)
         {
             bpf_printk("bpf_skb_store_bytes failed to store new source address with error code %d.\n", ret);
+            increment_counter(&ingress_counters, COUNTER_DROPPED);
             return TC_ACT_SHOT;
         }
 
@@ -70,8 +79,11 @@ int linklocal_to_gua(struct __sk_buff *skb)
         if (ret < 0)
         {
             bpf_printk("csum_l4_replace failed to update checksum: %d", ret);
+            increment_counter(&ingress_counters, COUNTER_DROPPED);
             return TC_ACT_SHOT;
         }
+
+        increment_counter(&ingress_counters, COUNTER_REWRITTEN);
     }
 
     return TC_ACT_UNSPEC;
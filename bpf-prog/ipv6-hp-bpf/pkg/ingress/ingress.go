@@ -1,57 +1,43 @@
 package ingress
 
 import (
-	"syscall"
+	"fmt"
 
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/pkg/tc"
+	"github.com/cilium/ebpf"
 	"github.com/vishvananda/netlink"
-	"go.uber.org/zap"
 )
 
-// SetupIngressFilter sets up the ingress filter
-func SetupIngressFilter(ifaceIndex int, objs *IngressObjects, logger *zap.Logger) error {
-	link, err := netlink.LinkByIndex(ifaceIndex)
-	if err != nil {
-		logger.Error("Failed to get link", zap.Error(err))
-		return err
-	}
+const (
+	// FilterName identifies the ingress filter on the interfaces.
+	FilterName = "ipv6_hp_ingress"
+	// CountersMapName is the name of the map of the ingress packet counters, and of its pin.
+	CountersMapName = "ingress_counters"
+)
 
-	// Get the list of filters on the link
-	filters, err := netlink.FilterList(link, netlink.HANDLE_MIN_INGRESS)
+// Load loads the ingress program and its counters map into the kernel. If pinPath is not empty the counters map is
+// pinned in it, and the map already pinned there by a previous run is reused.
+func Load(objs *IngressObjects, pinPath string) error {
+	spec, err := LoadIngress()
 	if err != nil {
-		logger.Error("Failed to get filter list", zap.Error(err))
-		return err
+		return fmt.Errorf("failed to load ingress spec: %w", err)
 	}
-
-	// Check if egress filter exists and delete it. Filter is identified by its name.
-	// this is to avoid duplicate filters after restarting the daemonsetS
-	for _, filter := range filters {
-		if filter, ok := filter.(*netlink.BpfFilter); ok && filter.Name == "ipv6_hp_ingress" {
-			if err := netlink.FilterDel(filter); err != nil {
-				logger.Error("Failed to delete filter", zap.Error(err))
-				return err
-			}
-			break
-		}
+	var opts ebpf.CollectionOptions
+	if pinPath != "" {
+		spec.Maps[CountersMapName].Pinning = ebpf.PinByName
+		opts.Maps.PinPath = pinPath
 	}
-
-	ingressFilter := &netlink.BpfFilter{
-		FilterAttrs: netlink.FilterAttrs{
-			LinkIndex: ifaceIndex,
-			Parent:    netlink.HANDLE_MIN_INGRESS,
-			Protocol:  syscall.ETH_P_ALL,
-			Priority:  1,
-		},
-		Fd:           objs.LinklocalToGua.FD(),
-		Name:         "ipv6_hp_ingress",
-		DirectAction: true,
+	if err := spec.LoadAndAssign(objs, &opts); err != nil {
+		return fmt.Errorf("failed to load ingress objects: %w", err)
 	}
+	return nil
+}
 
-	if err := netlink.FilterReplace(ingressFilter); err != nil {
-		logger.Error("failed setting ingress filter", zap.Error(err))
-		return err
-	} else {
-		logger.Info("Successfully set ingress filter on", zap.Int("ifaceIndex", ifaceIndex))
+// Filter returns the ingress filter of the loaded program.
+func Filter(objs *IngressObjects) tc.Filter {
+	return tc.Filter{
+		Name:    FilterName,
+		Parent:  netlink.HANDLE_MIN_INGRESS,
+		Program: objs.LinklocalToGua,
 	}
-
-	return nil
 }
@@ -0,0 +1,34 @@
+package ingress
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/internal/probepacket"
+	"github.com/Azure/azure-container-networking/bpf-prog/ipv6-hp-bpf/internal/progtest"
+)
+
+func TestLinklocalToGua(t *testing.T) {
+	var objs IngressObjects
+	progtest.Load(t, func() error { return Load(&objs, "") })
+	t.Cleanup(func() { objs.Close() })
+
+	pod := probepacket.GlobalUnicast.Prev()
+	progtest.Run(t, objs.LinklocalToGua, objs.IngressCounters, progtest.Src, []progtest.Case{
+		{
+			Name:      "probe is received from the global unicast address",
+			Pkt:       probepacket.TCP(probepacket.LinkLocal, pod),
+			Want:      probepacket.GlobalUnicast,
+			Rewritten: 1,
+		},
+		{
+			Name: "other source is unchanged",
+			Pkt:  probepacket.TCP(pod, pod),
+			Want: pod,
+		},
+		{
+			Name: "udp is unchanged",
+			Pkt:  probepacket.UDP(probepacket.LinkLocal, pod),
+			Want: probepacket.LinkLocal,
+		},
+	})
+}
@@ -0,0 +1,65 @@
+// Package metrics exports the packet counters of the health probe programs to Prometheus.
+package metrics
+
+import (
+	"github.com/cilium/ebpf"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// The indexes of the counters in the counter maps, as in include/helper.h.
+const (
+	counterRewritten uint32 = iota
+	counterDropped
+)
+
+var counterResults = []struct {
+	key    uint32
+	result string
+}{
+	{counterRewritten, "rewritten"},
+	{counterDropped, "dropped"},
+}
+
+var packetsDesc = prometheus.NewDesc(
+	"ipv6_hp_bpf_packets_total",
+	"Number of health probe packets handled by the programs, by direction and result.",
+	[]string{"direction", "result"},
+	nil,
+)
+
+// CounterCollector collects the per-CPU counter maps of the programs when the metrics are scraped. The maps are
+// pinned, so the counters are not reset when the daemon restarts.
+type CounterCollector struct {
+	log *zap.Logger
+	// maps are the counter maps by direction.
+	maps map[string]*ebpf.Map
+}
+
+var _ prometheus.Collector = (*CounterCollector)(nil)
+
+// NewCounterCollector returns a collector of the counter maps, by the direction of their program.
+func NewCounterCollector(log *zap.Logger, maps map[string]*ebpf.Map) *CounterCollector {
+	return &CounterCollector{log: log, maps: maps}
+}
+
+func (c *CounterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- packetsDesc
+}
+
+func (c *CounterCollector) Collect(ch chan<- prometheus.Metric) {
+	for direction, m := range c.maps {
+		for _, counter := range counterResults {
+			var values []uint64
+			if err := m.Lookup(counter.key, &values); err != nil {
+				c.log.Error("Failed to read counter", zap.String("direction", direction), zap.String("result", counter.result), zap.Error(err))
+				continue
+			}
+			var sum uint64
+			for _, v := range values {
+				sum += v
+			}
+			ch <- prometheus.MustNewConstMetric(packetsDesc, prometheus.CounterValue, float64(sum), direction, counter.result)
+		}
+	}
+}
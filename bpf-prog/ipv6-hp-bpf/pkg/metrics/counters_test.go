@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/rlimit"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+func TestCounterCollector(t *testing.T) {
+	if err := rlimit.RemoveMemlock(); err != nil {
+		t.Skipf("can't remove memlock: %v", err)
+	}
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.PerCPUArray,
+		KeySize:    4,
+		ValueSize:  8,
+		MaxEntries: 2,
+	})
+	if err != nil {
+		t.Skipf("can't create map, requires CAP_BPF: %v", err)
+	}
+	defer m.Close()
+
+	values := make([]uint64, ebpf.MustPossibleCPU())
+	values[0] = 3
+	values[len(values)-1] += 2
+	if err := m.Put(counterRewritten, values); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCounterCollector(zap.NewNop(), map[string]*ebpf.Map{"egress": m})
+	want := `
+# HELP ipv6_hp_bpf_packets_total Number of health probe packets handled by the programs, by direction and result.
+# TYPE ipv6_hp_bpf_packets_total counter
+ipv6_hp_bpf_packets_total{direction="egress",result="dropped"} 0
+ipv6_hp_bpf_packets_total{direction="egress",result="rewritten"} 5
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
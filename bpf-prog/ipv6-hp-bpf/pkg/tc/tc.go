@@ -0,0 +1,98 @@
+// Package tc attaches BPF programs as direct-action filters of the clsact qdisc of interfaces.
+package tc
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	"github.com/cilium/ebpf"
+	"github.com/vishvananda/netlink"
+)
+
+// Filter is a BPF program attached to the ingress or egress hook of the clsact qdisc. It is identified on the
+// interfaces by its name.
+type Filter struct {
+	Name string
+	// Parent is netlink.HANDLE_MIN_INGRESS or netlink.HANDLE_MIN_EGRESS.
+	Parent  uint32
+	Program *ebpf.Program
+}
+
+// Client attaches and detaches Filters with netlink.
+type Client struct{}
+
+// Attached returns whether the filter is attached to the link.
+func (Client) Attached(link netlink.Link, f Filter) (bool, error) {
+	filter, err := find(link, f)
+	return filter != nil, err
+}
+
+// Attach adds the clsact qdisc to the link if it does not have one, and attaches the filter, replacing a filter of
+// the same name which may have been left by a previous run.
+func (Client) Attach(link netlink.Link, f Filter) error {
+	qdisc := &netlink.GenericQdisc{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(0xffff, 0),
+			Parent:    netlink.HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := netlink.QdiscReplace(qdisc); err != nil {
+		return fmt.Errorf("failed to set clsact qdisc on %s: %w", link.Attrs().Name, err)
+	}
+
+	if err := detach(link, f); err != nil {
+		return err
+	}
+	filter := &netlink.BpfFilter{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    f.Parent,
+			Protocol:  syscall.ETH_P_ALL,
+			Priority:  1,
+		},
+		Fd:           f.Program.FD(),
+		Name:         f.Name,
+		DirectAction: true,
+	}
+	if err := netlink.FilterReplace(filter); err != nil {
+		return fmt.Errorf("failed to attach filter %s on %s: %w", f.Name, link.Attrs().Name, err)
+	}
+	return nil
+}
+
+// Detach removes the filter from the link, if it is attached. The clsact qdisc is left in place, as other programs
+// such as cilium's may be attached to it.
+func (Client) Detach(link netlink.Link, f Filter) error {
+	return detach(link, f)
+}
+
+func detach(link netlink.Link, f Filter) error {
+	filter, err := find(link, f)
+	if err != nil || filter == nil {
+		return err
+	}
+	if err := netlink.FilterDel(filter); err != nil && !errors.Is(err, syscall.ENOENT) {
+		return fmt.Errorf("failed to delete filter %s on %s: %w", f.Name, link.Attrs().Name, err)
+	}
+	return nil
+}
+
+func find(link netlink.Link, f Filter) (*netlink.BpfFilter, error) {
+	filters, err := netlink.FilterList(link, f.Parent)
+	if err != nil {
+		// the link has no clsact qdisc, so no filters.
+		if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOENT) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list filters on %s: %w", link.Attrs().Name, err)
+	}
+	for _, filter := range filters {
+		if filter, ok := filter.(*netlink.BpfFilter); ok && filter.Name == f.Name {
+			return filter, nil
+		}
+	}
+	return nil, nil
+}
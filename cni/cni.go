@@ -14,6 +14,12 @@ const (
 	CmdAdd = "ADD"
 	// CmdGet - CNI GET command.
 	CmdGet = "GET"
+	// CmdCheck - CNI CHECK command.
+	CmdCheck = "CHECK"
+	// CmdStatus - CNI STATUS command.
+	CmdStatus = "STATUS"
+	// CmdGC - CNI GC command.
+	CmdGC = "GC"
 	// CmdDel - CNI DEL command.
 	CmdDel = "DEL"
 	// CmdUpdate - CNI UPDATE command.
@@ -26,13 +32,17 @@ const (
 
 	// CNI errors.
 	ErrRuntime = 100
+	// ErrEndpointDrift - CHECK found the endpoint dataplane no longer matches the persisted state.
+	ErrEndpointDrift = 101
+	// ErrPluginNotAvailable - STATUS found the plugin cannot service ADD requests (well-known CNI code).
+	ErrPluginNotAvailable = 50
 
 	// DefaultVersion is the CNI version used when no version is specified in a network config file.
 	defaultVersion = "0.2.0"
 )
 
 // Supported CNI versions.
-var supportedVersions = []string{"0.1.0", "0.2.0", "0.3.0", "0.3.1", "0.4.0"}
+var supportedVersions = []string{"0.1.0", "0.2.0", "0.3.0", "0.3.1", "0.4.0", "1.0.0", "1.1.0"}
 
 // CNI contract.
 type PluginApi interface {
@@ -41,3 +51,19 @@ type PluginApi interface {
 	Delete(args *cniSkel.CmdArgs) error
 	Update(args *cniSkel.CmdArgs) error
 }
+
+// CheckApi is implemented by plugins with a real CHECK implementation.
+// Plugins that do not implement it have CHECK dispatched to Get.
+type CheckApi interface {
+	Check(args *cniSkel.CmdArgs) error
+}
+
+// StatusApi is implemented by plugins that support the CNI STATUS command.
+type StatusApi interface {
+	Status(args *cniSkel.CmdArgs) error
+}
+
+// GcApi is implemented by plugins that support the CNI GC command.
+type GcApi interface {
+	GC(args *cniSkel.CmdArgs) error
+}
@@ -28,9 +28,19 @@ type PortMapping struct {
 	HostIp        string `json:"hostIP,omitempty"`
 }
 
+// BandwidthEntry is the bandwidth capability of the runtime config, with rates in bits per second and bursts in bits.
+// https://github.com/containernetworking/cni/blob/main/CONVENTIONS.md#well-known-capabilities
+type BandwidthEntry struct {
+	IngressRate  uint64 `json:"ingressRate,omitempty"`
+	IngressBurst uint64 `json:"ingressBurst,omitempty"`
+	EgressRate   uint64 `json:"egressRate,omitempty"`
+	EgressBurst  uint64 `json:"egressBurst,omitempty"`
+}
+
 type RuntimeConfig struct {
 	PortMappings []PortMapping    `json:"portMappings,omitempty"`
 	DNS          RuntimeDNSConfig `json:"dns,omitempty"`
+	Bandwidth    *BandwidthEntry  `json:"bandwidth,omitempty"`
 }
 
 // https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/dockershim/network/cni/cni.go#L104
@@ -55,6 +65,7 @@ type NetworkConfig struct {
 	CNIVersion                    string          `json:"cniVersion,omitempty"`
 	Name                          string          `json:"name,omitempty"`
 	Type                          string          `json:"type,omitempty"`
+	Capabilities                  map[string]bool `json:"capabilities,omitempty"`
 	Mode                          string          `json:"mode,omitempty"`
 	Master                        string          `json:"master,omitempty"`
 	AdapterName                   string          `json:"adapterName,omitempty"`
@@ -72,13 +83,29 @@ type NetworkConfig struct {
 	EnableExactMatchForPodName    bool            `json:"enableExactMatchForPodName,omitempty"`
 	DisableHairpinOnHostInterface bool            `json:"disableHairpinOnHostInterface,omitempty"`
 	DisableIPTableLock            bool            `json:"disableIPTableLock,omitempty"`
+	EnableNFTables                bool            `json:"enableNFTables,omitempty"` // iptables and ebtables rules are programmed with nftables if set
 	CNSUrl                        string          `json:"cnsurl,omitempty"`
+	CNSGRPCAddress                string          `json:"cnsGrpcAddress,omitempty"` // CNS IPAM APIs are called over gRPC if set
 	ExecutionMode                 string          `json:"executionMode,omitempty"`
+	StoreType                     string          `json:"storeType,omitempty"` // json or bolt, the state is migrated to it; unset keeps the current store
 	IPAM                          IPAM            `json:"ipam,omitempty"`
 	DNS                           cniTypes.DNS    `json:"dns,omitempty"`
 	RuntimeConfig                 RuntimeConfig   `json:"runtimeConfig,omitempty"`
 	WindowsSettings               WindowsSettings `json:"windowsSettings,omitempty"`
 	AdditionalArgs                []KVPair        `json:"AdditionalArgs,omitempty"`
+	// ValidAttachments is set by the runtime on GC and lists every attachment that is still in use.
+	ValidAttachments []cniTypes.GCAttachment `json:"cni.dev/valid-attachments,omitempty"`
+	// GCDryRun makes GC report the endpoints it would remove without removing them.
+	GCDryRun bool `json:"gcDryRun,omitempty"`
+	// Tracing exports the spans of the ADD and DEL commands, which are continued by CNS.
+	Tracing *TracingConfig `json:"tracing,omitempty"`
+}
+
+type TracingConfig struct {
+	// Exporter is the name of the span exporter, only file is supported as stdout carries the CNI result.
+	Exporter string `json:"exporter,omitempty"`
+	// Path of the file the spans are appended to.
+	Path string `json:"path,omitempty"`
 }
 
 type WindowsSettings struct {
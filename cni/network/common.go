@@ -96,3 +96,13 @@ func PrintCNIError(msg string) {
 	}
 	cniErr.Print()
 }
+
+// PrintCNIStatusError prints a STATUS error telling the runtime the plugin cannot service ADD.
+func PrintCNIStatusError(msg string) {
+	logger.Error(msg)
+	cniErr := &cniTypes.Error{
+		Code: cni.ErrPluginNotAvailable,
+		Msg:  msg,
+	}
+	cniErr.Print()
+}
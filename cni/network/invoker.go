@@ -1,6 +1,7 @@
 package network
 
 import (
+	"context"
 	"net"
 
 	"github.com/Azure/azure-container-networking/cni"
@@ -13,10 +14,10 @@ import (
 // or simply act as a client to an external ipam, such as azure-cns.
 type IPAMInvoker interface {
 	// Add returns two results, one IPv4, the other IPv6.
-	Add(IPAMAddConfig) (IPAMAddResult, error)
+	Add(context.Context, IPAMAddConfig) (IPAMAddResult, error)
 
 	// Delete calls to the invoker source, and returns error. Returning an error here will fail the CNI Delete call.
-	Delete(address *net.IPNet, nwCfg *cni.NetworkConfig, args *cniSkel.CmdArgs, options map[string]interface{}) error
+	Delete(ctx context.Context, address *net.IPNet, nwCfg *cni.NetworkConfig, args *cniSkel.CmdArgs, options map[string]interface{}) error
 }
 
 type IPAMAddConfig struct {
@@ -1,6 +1,7 @@
 package network
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -485,7 +486,7 @@ func TestCNSIPAMInvoker_Add_Overlay(t *testing.T) {
 			if tt.fields.ipamMode != "" {
 				invoker.ipamMode = tt.fields.ipamMode
 			}
-			ipamAddResult, err := invoker.Add(IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
+			ipamAddResult, err := invoker.Add(context.Background(), IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
 			if tt.wantErr {
 				require.Error(err)
 			} else {
@@ -792,7 +793,7 @@ func TestCNSIPAMInvoker_Add(t *testing.T) {
 			if tt.fields.ipamMode != "" {
 				invoker.ipamMode = tt.fields.ipamMode
 			}
-			ipamAddResult, err := invoker.Add(IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
+			ipamAddResult, err := invoker.Add(context.Background(), IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
 			if tt.wantErr {
 				require.Error(err)
 			} else {
@@ -922,7 +923,7 @@ func TestCNSIPAMInvoker_Add_UnsupportedAPI(t *testing.T) {
 			if tt.fields.ipamMode != "" {
 				invoker.ipamMode = tt.fields.ipamMode
 			}
-			ipamAddResult, err := invoker.Add(IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
+			ipamAddResult, err := invoker.Add(context.Background(), IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
 			if err != nil && tt.wantErr {
 				t.Fatalf("expected an error %+v but none received", err)
 			}
@@ -1041,7 +1042,7 @@ func TestRequestIPAPIsFail(t *testing.T) {
 			if tt.fields.ipamMode != "" {
 				invoker.ipamMode = tt.fields.ipamMode
 			}
-			_, err := invoker.Add(IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
+			_, err := invoker.Add(context.Background(), IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
 			if err == nil && tt.wantErr {
 				t.Fatalf("expected an error %+v but none received", err)
 			}
@@ -1116,7 +1117,7 @@ func TestCNSIPAMInvoker_Delete(t *testing.T) {
 				podNamespace: tt.fields.podNamespace,
 				cnsClient:    tt.fields.cnsClient,
 			}
-			err := invoker.Delete(tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
+			err := invoker.Delete(context.Background(), tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
 			if tt.wantErr {
 				require.Error(err)
 			} else {
@@ -1207,7 +1208,7 @@ func TestCNSIPAMInvoker_Delete_Overlay(t *testing.T) {
 				podNamespace: tt.fields.podNamespace,
 				cnsClient:    tt.fields.cnsClient,
 			}
-			err := invoker.Delete(tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
+			err := invoker.Delete(context.Background(), tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
 			if tt.wantErr {
 				require.Error(err)
 			} else {
@@ -1272,7 +1273,7 @@ func TestCNSIPAMInvoker_Delete_NotSupportedAPI(t *testing.T) {
 				podNamespace: tt.fields.podNamespace,
 				cnsClient:    tt.fields.cnsClient,
 			}
-			err := invoker.Delete(tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
+			err := invoker.Delete(context.Background(), tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
 			if tt.wantErr {
 				require.Error(err)
 			} else {
@@ -1332,7 +1333,7 @@ func TestReleaseIPAPIsFail(t *testing.T) {
 				podNamespace: tt.fields.podNamespace,
 				cnsClient:    tt.fields.cnsClient,
 			}
-			err := invoker.Delete(tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
+			err := invoker.Delete(context.Background(), tt.args.address, tt.args.nwCfg, tt.args.args, tt.args.options)
 			if !errors.Is(err, errNoReleaseIPFound) {
 				t.Fatalf("expected an error %s but %v received", errNoReleaseIPFound, err)
 			}
@@ -1371,26 +1372,20 @@ func Test_setHostOptions(t *testing.T) {
 				},
 			},
 			wantOptions: map[string]interface{}{
-				network.IPTablesKey: []iptables.IPTableEntry{
+				network.IPTablesKey: []iptables.RuleChange{
+					{Action: iptables.NewChain, Table: iptables.Nat, Chain: iptables.Swift},
+					{Action: iptables.Append, Table: iptables.Nat, Chain: iptables.Postrouting, Target: iptables.Swift},
 					{
-						Version: "4",
-						Params:  "-t nat -N SWIFT",
+						Action: iptables.Insert, Table: iptables.Nat, Chain: iptables.Swift,
+						Match: " -m addrtype ! --dst-type local -s 10.0.1.0/24 -d 168.63.129.16 -p udp --dport 53", Target: "SNAT --to 10.0.1.20",
 					},
 					{
-						Version: "4",
-						Params:  "-t nat -A POSTROUTING  -j SWIFT",
+						Action: iptables.Insert, Table: iptables.Nat, Chain: iptables.Swift,
+						Match: " -m addrtype ! --dst-type local -s 10.0.1.0/24 -d 168.63.129.16 -p tcp --dport 53", Target: "SNAT --to 10.0.1.20",
 					},
 					{
-						Version: "4",
-						Params:  "-t nat -I SWIFT 1  -m addrtype ! --dst-type local -s 10.0.1.0/24 -d 168.63.129.16 -p udp --dport 53 -j SNAT --to 10.0.1.20",
-					},
-					{
-						Version: "4",
-						Params:  "-t nat -I SWIFT 1  -m addrtype ! --dst-type local -s 10.0.1.0/24 -d 168.63.129.16 -p tcp --dport 53 -j SNAT --to 10.0.1.20",
-					},
-					{
-						Version: "4",
-						Params:  "-t nat -I SWIFT 1  -m addrtype ! --dst-type local -s 10.0.1.0/24 -d 169.254.169.254 -p tcp --dport 80 -j SNAT --to 10.0.0.3",
+						Action: iptables.Insert, Table: iptables.Nat, Chain: iptables.Swift,
+						Match: " -m addrtype ! --dst-type local -s 10.0.1.0/24 -d 169.254.169.254 -p tcp --dport 80", Target: "SNAT --to 10.0.0.3",
 					},
 				},
 				network.RoutesKey: []network.RouteInfo{
@@ -1544,7 +1539,7 @@ func TestCNSIPAMInvoker_Add_SwiftV2(t *testing.T) {
 				podNamespace: tt.fields.podNamespace,
 				cnsClient:    tt.fields.cnsClient,
 			}
-			ipamAddResult, err := invoker.Add(IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
+			ipamAddResult, err := invoker.Add(context.Background(), IPAMAddConfig{nwCfg: tt.args.nwCfg, args: tt.args.args, options: tt.args.options})
 			if tt.wantErr {
 				require.Error(err)
 			} else {
@@ -10,6 +10,7 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-container-networking/aitelemetry"
@@ -19,6 +20,7 @@ import (
 	"github.com/Azure/azure-container-networking/cns"
 	cnscli "github.com/Azure/azure-container-networking/cns/client"
 	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/ebtables"
 	"github.com/Azure/azure-container-networking/iptables"
 	"github.com/Azure/azure-container-networking/netio"
 	"github.com/Azure/azure-container-networking/netlink"
@@ -28,10 +30,12 @@ import (
 	nnscontracts "github.com/Azure/azure-container-networking/proto/nodenetworkservice/3.302.0.744"
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/Azure/azure-container-networking/telemetry"
+	"github.com/Azure/azure-container-networking/tracing"
 	cniSkel "github.com/containernetworking/cni/pkg/skel"
 	cniTypes "github.com/containernetworking/cni/pkg/types"
 	cniTypesCurr "github.com/containernetworking/cni/pkg/types/100"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -144,6 +148,13 @@ func (plugin *NetPlugin) SetCNIReport(report *telemetry.CNIReport, tb *telemetry
 	plugin.tb = tb
 }
 
+// setNetfilterOptions applies the options of the node's network configuration to the iptables and ebtables clients.
+func setNetfilterOptions(nwCfg *cni.NetworkConfig) {
+	iptables.DisableIPTableLock = nwCfg.DisableIPTableLock
+	iptables.EnableNFTables = nwCfg.EnableNFTables
+	ebtables.EnableNFTables = nwCfg.EnableNFTables
+}
+
 // Starts the plugin.
 func (plugin *NetPlugin) Start(config *common.PluginConfig) error {
 	// Initialize base plugin.
@@ -273,6 +284,15 @@ func GetEndpointID(args *cniSkel.CmdArgs) string {
 	return infraEpId
 }
 
+// newCNSClient creates a client of the CNS at baseURL, which calls the IPAM APIs over gRPC if the network
+// config sets the address of the CNS gRPC server.
+func newCNSClient(baseURL string, nwCfg *cni.NetworkConfig) (*cnscli.Client, error) {
+	if nwCfg.CNSGRPCAddress != "" {
+		return cnscli.NewWithGRPC(baseURL, nwCfg.CNSGRPCAddress, defaultRequestTimeout) //nolint:wrapcheck // callers wrap
+	}
+	return cnscli.New(baseURL, defaultRequestTimeout) //nolint:wrapcheck // callers wrap
+}
+
 // getPodInfo returns POD info by parsing the CNI args.
 func (plugin *NetPlugin) getPodInfo(args string) (name, ns string, err error) {
 	podCfg, err := cni.ParseCniArgs(args)
@@ -347,8 +367,8 @@ func addNatIPV6SubnetInfo(nwCfg *cni.NetworkConfig,
 	}
 }
 
-func (plugin *NetPlugin) addIpamInvoker(ipamAddConfig IPAMAddConfig) (IPAMAddResult, error) {
-	ipamAddResult, err := plugin.ipamInvoker.Add(ipamAddConfig)
+func (plugin *NetPlugin) addIpamInvoker(ctx context.Context, ipamAddConfig IPAMAddConfig) (IPAMAddResult, error) {
+	ipamAddResult, err := plugin.ipamInvoker.Add(ctx, ipamAddConfig)
 	if err != nil {
 		return IPAMAddResult{}, errors.Wrap(err, "failed to add ipam invoker")
 	}
@@ -406,10 +426,17 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 		return err
 	}
 
-	iptables.DisableIPTableLock = nwCfg.DisableIPTableLock
+	setNetfilterOptions(nwCfg)
 	plugin.setCNIReportDetails(nwCfg, CNI_ADD, "")
 
+	ctx, span := tracing.Start(context.Background(), "cni.ADD",
+		attribute.String("containerID", args.ContainerID),
+		attribute.String("netNS", args.Netns),
+		attribute.String("ifName", args.IfName))
+
 	defer func() {
+		tracing.End(span, err)
+
 		operationTimeMs := time.Since(startTime).Milliseconds()
 		cniMetric.Metric = aitelemetry.Metric{
 			Name:             telemetry.CNIAddTimeMetricStr,
@@ -464,6 +491,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 	}
 
 	plugin.report.ContainerName = k8sPodName + ":" + k8sNamespace
+	span.SetAttributes(attribute.String("pod", k8sPodName), attribute.String("namespace", k8sNamespace))
 
 	k8sContainerID := args.ContainerID
 	if len(k8sContainerID) == 0 {
@@ -483,7 +511,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 	if nwCfg.ExecutionMode == string(util.Baremetal) {
 		var res *nnscontracts.ConfigureContainerNetworkingResponse
 		logger.Info("Baremetal mode. Calling vnet agent for ADD")
-		res, err = plugin.nnsClient.AddContainerNetworking(context.Background(), k8sPodName, args.Netns)
+		res, err = plugin.nnsClient.AddContainerNetworking(ctx, k8sPodName, args.Netns)
 
 		if err == nil {
 			ipamAddResult.interfaceInfo[string(cns.InfraNIC)] = network.InterfaceInfo{
@@ -504,7 +532,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 		}
 	}
 
-	cnsClient, err := cnscli.New(nwCfg.CNSUrl, defaultRequestTimeout)
+	cnsClient, err := newCNSClient(nwCfg.CNSUrl, nwCfg)
 	if err != nil {
 		return fmt.Errorf("failed to create cns client with error: %w", err)
 	}
@@ -525,7 +553,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 			return fmt.Errorf("%w", err)
 		}
 
-		ipamAddResult, err = plugin.multitenancyClient.GetAllNetworkContainers(context.TODO(), nwCfg, k8sPodName, k8sNamespace, args.IfName)
+		ipamAddResult, err = plugin.multitenancyClient.GetAllNetworkContainers(ctx, nwCfg, k8sPodName, k8sNamespace, args.IfName)
 		if err != nil {
 			err = fmt.Errorf("GetAllNetworkContainers failed for podname %s namespace %s. error: %w", k8sPodName, k8sNamespace, err)
 			logger.Error("GetAllNetworkContainers failed",
@@ -554,7 +582,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 			}
 		}
 
-		ipamAddResult, err = plugin.addIpamInvoker(ipamAddConfig)
+		ipamAddResult, err = plugin.addIpamInvoker(ctx, ipamAddConfig)
 		if err != nil {
 			return fmt.Errorf("IPAM Invoker Add failed with error: %w", err)
 		}
@@ -575,7 +603,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 					// This used to only be called for infraNIC, test if this breaks scenarios
 					// If it does then will have to search for infraNIC
 					if ifInfo.NICType == cns.InfraNIC {
-						plugin.cleanupAllocationOnError(ifInfo.IPConfigs, nwCfg, args, options)
+						plugin.cleanupAllocationOnError(ctx, ifInfo.IPConfigs, nwCfg, args, options)
 					}
 				}
 			}
@@ -621,7 +649,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 		//	ipamAddResult.interfaceInfo[ifIndex].IPConfigs, epInfo.Data[network.VlanIDKey], k8sPodName, k8sNamespace, plugin.nm.GetNumberOfEndpoints("", nwCfg.Name)))
 		endpointIndex++
 	}
-	cnsclient, err := cnscli.New(nwCfg.CNSUrl, defaultRequestTimeout)
+	cnsclient, err := newCNSClient(nwCfg.CNSUrl, nwCfg)
 	if err != nil {
 		return errors.Wrap(err, "failed to create cns client")
 	}
@@ -647,7 +675,7 @@ func (plugin *NetPlugin) Add(args *cniSkel.CmdArgs) error {
 		}
 	}()
 
-	err = plugin.nm.EndpointCreate(cnsclient, epInfos)
+	err = plugin.nm.EndpointCreate(ctx, cnsclient, epInfos)
 	if err != nil {
 		return errors.Wrap(err, "failed to create endpoint") // behavior can change if you don't assign to err prior to returning
 	}
@@ -807,6 +835,12 @@ func (plugin *NetPlugin) createEpInfo(opt *createEpInfoOpt) (*network.EndpointIn
 	}
 	endpointInfo.EndpointPolicies = append(endpointInfo.EndpointPolicies, epPolicies...)
 
+	if opt.ifInfo.NICType == cns.InfraNIC {
+		// host ports are mapped and bandwidth is shaped on the pod's primary interface only
+		endpointInfo.PortMappings = getPortMappings(opt.nwCfg)
+		endpointInfo.Bandwidth = getBandwidthLimits(opt.nwCfg)
+	}
+
 	if opt.ipamAddResult.ipv6Enabled { // not specific to this particular interface
 		endpointInfo.IPV6Mode = string(util.IpamMode(opt.nwCfg.IPAM.Mode)) // TODO: check IPV6Mode field can be deprecated and can we add IsIPv6Enabled flag for generic working
 	}
@@ -830,6 +864,7 @@ func (plugin *NetPlugin) createEpInfo(opt *createEpInfoOpt) (*network.EndpointIn
 
 // cleanup allocated ipv4 and ipv6 addresses if they exist
 func (plugin *NetPlugin) cleanupAllocationOnError(
+	ctx context.Context,
 	result []*network.IPConfig,
 	nwCfg *cni.NetworkConfig,
 	args *cniSkel.CmdArgs,
@@ -837,7 +872,7 @@ func (plugin *NetPlugin) cleanupAllocationOnError(
 ) {
 	if result != nil {
 		for i := 0; i < len(result); i++ {
-			if er := plugin.ipamInvoker.Delete(&result[i].Address, nwCfg, args, options); er != nil {
+			if er := plugin.ipamInvoker.Delete(ctx, &result[i].Address, nwCfg, args, options); er != nil {
 				logger.Error("Failed to cleanup ip allocation on failure", zap.Error(er))
 			}
 		}
@@ -916,7 +951,7 @@ func (plugin *NetPlugin) Get(args *cniSkel.CmdArgs) error {
 
 	logger.Info("Read network configuration", zap.Any("config", nwCfg))
 
-	iptables.DisableIPTableLock = nwCfg.DisableIPTableLock
+	setNetfilterOptions(nwCfg)
 
 	// Initialize values from network config.
 	if networkID, err = plugin.getNetworkName(args.Netns, nil, nwCfg); err != nil {
@@ -962,6 +997,352 @@ func (plugin *NetPlugin) Get(args *cniSkel.CmdArgs) error {
 	return nil
 }
 
+// Check handles CNI CHECK commands.
+// It compares every endpoint persisted for the container with the live dataplane and
+// returns an error naming the state that has drifted.
+func (plugin *NetPlugin) Check(args *cniSkel.CmdArgs) error {
+	var (
+		err   error
+		nwCfg *cni.NetworkConfig
+	)
+
+	logger.Info("Processing CHECK command",
+		zap.String("container", args.ContainerID),
+		zap.String("netns", args.Netns),
+		zap.String("ifname", args.IfName),
+		zap.String("args", args.Args),
+		zap.String("path", args.Path))
+
+	defer func() {
+		logger.Info("CHECK command completed", zap.Error(err))
+	}()
+
+	// Parse network configuration from stdin.
+	if nwCfg, err = cni.ParseNetworkConfig(args.StdinData); err != nil {
+		err = plugin.Errorf("Failed to parse network configuration: %v.", err)
+		return err
+	}
+
+	setNetfilterOptions(nwCfg)
+
+	if plugin.nm.IsStatelessCNIMode() {
+		// Stateless CNI keeps no endpoint state of its own to compare against.
+		logger.Info("Skipping CHECK in stateless CNI mode")
+		return nil
+	}
+
+	epInfos := plugin.nm.GetEndpointInfosFromContainerID(args.ContainerID)
+	if len(epInfos) == 0 {
+		err = cniTypes.NewError(cniTypes.ErrUnknownContainer, "no endpoint found for container "+args.ContainerID, "")
+		return err
+	}
+
+	var drifts []string
+	for _, epInfo := range epInfos {
+		checkErr := plugin.nm.CheckEndpoint(epInfo.NetworkID, epInfo.EndpointID)
+		if checkErr == nil {
+			continue
+		}
+
+		if !network.IsEndpointDriftError(checkErr) {
+			err = plugin.Errorf("Failed to check endpoint %s: %v", epInfo.EndpointID, checkErr)
+			return err
+		}
+
+		drifts = append(drifts, checkErr.Error())
+	}
+
+	if len(drifts) > 0 {
+		err = plugin.Error(&cniTypes.Error{
+			Code:    cni.ErrEndpointDrift,
+			Msg:     "endpoint state has drifted from the dataplane",
+			Details: strings.Join(drifts, "\n"),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// Status handles CNI STATUS commands.
+// It reports the plugin as unavailable when the state store or CNS cannot be reached,
+// since ADD would fail in either case.
+func (plugin *NetPlugin) Status(args *cniSkel.CmdArgs) error {
+	var (
+		err   error
+		nwCfg *cni.NetworkConfig
+	)
+
+	logger.Info("Processing STATUS command")
+
+	defer func() {
+		logger.Info("STATUS command completed", zap.Error(err))
+	}()
+
+	// Parse network configuration from stdin.
+	if nwCfg, err = cni.ParseNetworkConfig(args.StdinData); err != nil {
+		err = plugin.Errorf("Failed to parse network configuration: %v.", err)
+		return err
+	}
+
+	if !plugin.nm.IsStatelessCNIMode() {
+		if statusErr := plugin.storeStatus(); statusErr != nil {
+			err = plugin.Error(&cniTypes.Error{
+				Code:    cni.ErrPluginNotAvailable,
+				Msg:     "state store is not reachable",
+				Details: statusErr.Error(),
+			})
+			return err
+		}
+	}
+
+	if nwCfg.IPAM.Type == network.AzureCNS || nwCfg.MultiTenancy || plugin.nm.IsStatelessCNIMode() {
+		if statusErr := cnsStatus(nwCfg.CNSUrl); statusErr != nil {
+			err = plugin.Error(&cniTypes.Error{
+				Code:    cni.ErrPluginNotAvailable,
+				Msg:     "CNS is not reachable",
+				Details: statusErr.Error(),
+			})
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storeStatus verifies the key-value store is initialized and, if the state file exists, accessible.
+func (plugin *NetPlugin) storeStatus() error {
+	if plugin.Store == nil {
+		return errors.New("store is not initialized")
+	}
+
+	if !plugin.Store.Exists() {
+		// A node that has not created any endpoint yet has no state file.
+		return nil
+	}
+
+	_, err := plugin.Store.GetModificationTime()
+	return errors.Wrap(err, "failed to stat state file")
+}
+
+// cnsStatus probes CNS with a request that does not touch IPAM state.
+func cnsStatus(cnsURL string) error {
+	cnsClient, err := cnscli.New(cnsURL, defaultRequestTimeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to create cns client")
+	}
+
+	_, err = cnsClient.NumOfCPUCores(context.TODO())
+	return errors.Wrap(err, "failed to query cns")
+}
+
+// GC handles CNI GC commands.
+// It removes the endpoints of the configured network which are not in the runtime's list of
+// valid attachments and releases their IPs in CNS or through the IPAM plugin.
+func (plugin *NetPlugin) GC(args *cniSkel.CmdArgs) error {
+	var (
+		err   error
+		nwCfg *cni.NetworkConfig
+	)
+
+	logger.Info("Processing GC command", zap.ByteString("stdinData", args.StdinData))
+
+	defer func() {
+		logger.Info("GC command completed", zap.Error(err))
+	}()
+
+	// Parse network configuration from stdin.
+	if nwCfg, err = cni.ParseNetworkConfig(args.StdinData); err != nil {
+		err = plugin.Errorf("Failed to parse network configuration: %v.", err)
+		return err
+	}
+
+	setNetfilterOptions(nwCfg)
+
+	if plugin.nm.IsStatelessCNIMode() {
+		// In stateless CNI the endpoint state lives in CNS, which reconciles it against the pods on the node.
+		logger.Info("Skipping GC in stateless CNI mode")
+		return nil
+	}
+
+	if nwCfg.MultiTenancy {
+		// Multitenant networks are named after the network container of each pod, so there is no single
+		// network to reconcile from the config alone.
+		logger.Info("Skipping GC for multitenant network config")
+		return nil
+	}
+
+	var cnsClient cnsclient
+	if nwCfg.IPAM.Type == network.AzureCNS {
+		if cnsClient, err = newCNSClient(nwCfg.CNSUrl, nwCfg); err != nil {
+			err = plugin.Errorf("Failed to create cns client: %v", err)
+			return err
+		}
+	}
+
+	err = plugin.gc(nwCfg, cnsClient)
+	return err
+}
+
+func (plugin *NetPlugin) gc(nwCfg *cni.NetworkConfig, cnsClient cnsclient) error {
+	networkID, err := plugin.getNetworkID("", nil, nwCfg)
+	if err != nil {
+		return plugin.Errorf("Failed to get network id: %v", err)
+	}
+
+	endpoints, err := plugin.nm.GetAllEndpoints(networkID)
+	if err != nil {
+		if errors.Is(err, store.ErrStoreEmpty) || network.IsNetworkNotFoundError(err) {
+			logger.Info("No endpoints to garbage collect", zap.String("network", networkID))
+			return nil
+		}
+		return plugin.Errorf("Failed to list endpoints of network %s: %v", networkID, err)
+	}
+
+	staleEpInfos := staleEndpoints(endpoints, nwCfg.ValidAttachments)
+	if len(staleEpInfos) == 0 {
+		return nil
+	}
+
+	if nwCfg.GCDryRun {
+		for _, epInfo := range staleEpInfos {
+			logger.Info("GC dry run: would delete endpoint",
+				zap.String("endpointID", epInfo.EndpointID),
+				zap.String("containerID", epInfo.ContainerID),
+				zap.String("ifName", epInfo.IfName),
+				zap.Any("ipAddresses", epInfo.IPAddresses))
+		}
+		return nil
+	}
+
+	// Without CNS the IPs are released through the IPAM plugin, like on DEL.
+	var nwInfo network.EndpointInfo
+	if cnsClient == nil {
+		if nwInfo, err = plugin.nm.GetNetworkInfo(networkID); err != nil {
+			return plugin.Errorf("Failed to query network %s: %v", networkID, err)
+		}
+		if plugin.ipamInvoker == nil {
+			plugin.ipamInvoker = NewAzureIpamInvoker(plugin, &nwInfo)
+		}
+	}
+
+	var (
+		deletedEpInfos []*network.EndpointInfo
+		failures       []string
+	)
+	for _, epInfo := range staleEpInfos {
+		logger.Info("Deleting stale endpoint",
+			zap.String("endpointID", epInfo.EndpointID),
+			zap.String("containerID", epInfo.ContainerID),
+			zap.String("ifName", epInfo.IfName))
+		sendEvent(plugin, fmt.Sprintf("GC deleting stale endpoint:%v", epInfo.EndpointID))
+
+		if err = plugin.nm.DeleteEndpoint(epInfo.NetworkID, epInfo.EndpointID, epInfo); err != nil {
+			failures = append(failures, fmt.Sprintf("failed to delete endpoint %s: %v", epInfo.EndpointID, err))
+			continue
+		}
+		deletedEpInfos = append(deletedEpInfos, epInfo)
+
+		if epInfo.NICType == cns.DelegatedVMNIC || len(epInfo.IPAddresses) == 0 {
+			continue
+		}
+
+		if cnsClient != nil {
+			err = releaseEndpointIPs(cnsClient, epInfo)
+		} else {
+			err = plugin.releaseIPAMAddresses(nwCfg, epInfo, nwInfo.Options)
+		}
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(deletedEpInfos) > 0 {
+		if err = plugin.nm.DeleteState(deletedEpInfos); err != nil {
+			failures = append(failures, fmt.Sprintf("failed to save state: %v", err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return plugin.RetriableError(errors.New(strings.Join(failures, "; ")))
+	}
+
+	return nil
+}
+
+// staleEndpoints returns the endpoints which don't belong to a valid attachment.
+// Infra NIC endpoints are matched on the container id and interface name. Other endpoints store the master
+// interface name instead of the attachment's, so they are only matched on the container id.
+func staleEndpoints(endpoints map[string]*network.EndpointInfo, validAttachments []cniTypes.GCAttachment) []*network.EndpointInfo {
+	type attachmentKey struct {
+		containerID string
+		ifName      string
+	}
+
+	validAttachmentKeys := make(map[attachmentKey]struct{}, len(validAttachments))
+	validContainers := make(map[string]struct{}, len(validAttachments))
+	for _, attachment := range validAttachments {
+		validAttachmentKeys[attachmentKey{containerID: attachment.ContainerID, ifName: attachment.IfName}] = struct{}{}
+		validContainers[attachment.ContainerID] = struct{}{}
+	}
+
+	var staleEpInfos []*network.EndpointInfo
+	for _, epInfo := range endpoints {
+		if epInfo.ContainerID == "" {
+			// Without a container id the owner of the endpoint is unknown, so leave it alone.
+			continue
+		}
+		isInfraNIC := epInfo.NICType == cns.InfraNIC || epInfo.NICType == ""
+		if isInfraNIC && epInfo.IfName != "" {
+			if _, ok := validAttachmentKeys[attachmentKey{containerID: epInfo.ContainerID, ifName: epInfo.IfName}]; ok {
+				continue
+			}
+		} else if _, ok := validContainers[epInfo.ContainerID]; ok {
+			continue
+		}
+		staleEpInfos = append(staleEpInfos, epInfo)
+	}
+	return staleEpInfos
+}
+
+// releaseIPAMAddresses releases the endpoint's addresses through the IPAM plugin.
+func (plugin *NetPlugin) releaseIPAMAddresses(nwCfg *cni.NetworkConfig, epInfo *network.EndpointInfo, options map[string]interface{}) error {
+	for i := range epInfo.IPAddresses {
+		// the invoker sets the address and pool of the IPAM config it is given
+		ipamCfg := *nwCfg
+		if err := plugin.ipamInvoker.Delete(context.TODO(), &epInfo.IPAddresses[i], &ipamCfg, nil, options); err != nil {
+			return errors.Wrapf(err, "failed to release address %s of endpoint %s", epInfo.IPAddresses[i].String(), epInfo.EndpointID)
+		}
+	}
+	return nil
+}
+
+// releaseEndpointIPs releases the IPs that CNS assigned to the endpoint's pod interface.
+func releaseEndpointIPs(cnsClient cnsclient, epInfo *network.EndpointInfo) error {
+	orchestratorContext, err := json.Marshal(cns.KubernetesPodInfo{
+		PodName:      epInfo.PODName,
+		PodNamespace: epInfo.PODNameSpace,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal orchestrator context")
+	}
+
+	ipConfigs := cns.IPConfigsRequest{
+		OrchestratorContext: orchestratorContext,
+		PodInterfaceID:      epInfo.EndpointID,
+		InfraContainerID:    epInfo.ContainerID,
+	}
+	for i := range epInfo.IPAddresses {
+		ipConfigs.DesiredIPAddresses = append(ipConfigs.DesiredIPAddresses, epInfo.IPAddresses[i].IP.String())
+	}
+
+	if err := cnsClient.ReleaseIPs(context.TODO(), ipConfigs); err != nil {
+		return errors.Wrapf(err, "failed to release IPs %v of endpoint %s", ipConfigs.DesiredIPAddresses, epInfo.EndpointID)
+	}
+
+	return nil
+}
+
 // Delete handles CNI delete commands.
 func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	var (
@@ -986,7 +1367,13 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	sendEvent(plugin, fmt.Sprintf("[cni-net] Processing DEL command with args {ContainerID:%v Netns:%v IfName:%v Args:%v Path:%v, StdinData:%s}.",
 		args.ContainerID, args.Netns, args.IfName, args.Args, args.Path, args.StdinData))
 
+	ctx, span := tracing.Start(context.Background(), "cni.DEL",
+		attribute.String("containerID", args.ContainerID),
+		attribute.String("netNS", args.Netns),
+		attribute.String("ifName", args.IfName))
+
 	defer func() {
+		tracing.End(span, err)
 		logger.Info("DEL command completed",
 			zap.String("pod", k8sPodName),
 			zap.Error(err))
@@ -1002,11 +1389,12 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	if k8sPodName, k8sNamespace, err = plugin.getPodInfo(args.Args); err != nil {
 		logger.Error("Failed to get POD info", zap.Error(err))
 	}
+	span.SetAttributes(attribute.String("pod", k8sPodName), attribute.String("namespace", k8sNamespace))
 
 	plugin.setCNIReportDetails(nwCfg, CNI_DEL, "")
 	plugin.report.ContainerName = k8sPodName + ":" + k8sNamespace
 
-	iptables.DisableIPTableLock = nwCfg.DisableIPTableLock
+	setNetfilterOptions(nwCfg)
 
 	sendMetricFunc := func() {
 		operationTimeMs := time.Since(startTime).Milliseconds()
@@ -1026,7 +1414,7 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	if nwCfg.ExecutionMode == string(util.Baremetal) {
 		// schedule send metric before attempting delete
 		defer sendMetricFunc()
-		_, err = plugin.nnsClient.DeleteContainerNetworking(context.Background(), k8sPodName, args.Netns)
+		_, err = plugin.nnsClient.DeleteContainerNetworking(ctx, k8sPodName, args.Netns)
 		if err != nil {
 			return fmt.Errorf("nnsClient.DeleteContainerNetworking failed with err %w", err)
 		}
@@ -1035,7 +1423,7 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	if plugin.ipamInvoker == nil {
 		switch nwCfg.IPAM.Type {
 		case network.AzureCNS:
-			cnsClient, cnsErr := cnscli.New("", defaultRequestTimeout)
+			cnsClient, cnsErr := newCNSClient("", nwCfg)
 			if cnsErr != nil {
 				logger.Error("failed to create cns client", zap.Error(cnsErr))
 				return errors.Wrap(cnsErr, "failed to create cns client")
@@ -1102,7 +1490,7 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 			logger.Error("Release ip by ContainerID (endpoint not found)",
 				zap.String("containerID", args.ContainerID))
 			sendEvent(plugin, fmt.Sprintf("Release ip by ContainerID (endpoint not found):%v", args.ContainerID))
-			if err = plugin.ipamInvoker.Delete(nil, nwCfg, args, nwInfo.Options); err != nil {
+			if err = plugin.ipamInvoker.Delete(ctx, nil, nwCfg, args, nwInfo.Options); err != nil {
 				return plugin.RetriableError(fmt.Errorf("failed to release address(no endpoint): %w", err))
 			}
 		}
@@ -1115,7 +1503,10 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 	// delete endpoints
 	for _, epInfo := range epInfos {
 		// in stateless, network id is not populated in epInfo, but in stateful cni, it is (nw id is used in stateful)
-		if err = plugin.nm.DeleteEndpoint(epInfo.NetworkID, epInfo.EndpointID, epInfo); err != nil {
+		err = tracing.Trace(ctx, "network.DeleteEndpoint", func(context.Context) error {
+			return plugin.nm.DeleteEndpoint(epInfo.NetworkID, epInfo.EndpointID, epInfo)
+		}, attribute.String("endpointID", epInfo.EndpointID))
+		if err != nil {
 			// An error will not be returned if the endpoint is not found
 			// return a retriable error so the container runtime will retry this DEL later
 			// the implementation of this function returns nil if the endpoint doens't exist, so
@@ -1138,7 +1529,7 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 			for i := range epInfo.IPAddresses {
 				logger.Info("Release ip", zap.String("ip", epInfo.IPAddresses[i].IP.String()))
 				sendEvent(plugin, fmt.Sprintf("Release ip:%s", epInfo.IPAddresses[i].IP.String()))
-				err = plugin.ipamInvoker.Delete(&epInfo.IPAddresses[i], nwCfg, args, nwInfo.Options)
+				err = plugin.ipamInvoker.Delete(ctx, &epInfo.IPAddresses[i], nwCfg, args, nwInfo.Options)
 				if err != nil {
 					return plugin.RetriableError(fmt.Errorf("failed to release address: %w", err))
 				}
@@ -1146,7 +1537,7 @@ func (plugin *NetPlugin) Delete(args *cniSkel.CmdArgs) error {
 		} else if epInfo.EnableInfraVnet { // remove in future PR
 			nwCfg.IPAM.Subnet = nwInfo.Subnets[0].Prefix.String()
 			nwCfg.IPAM.Address = epInfo.InfraVnetIP.IP.String()
-			err = plugin.ipamInvoker.Delete(nil, nwCfg, args, nwInfo.Options)
+			err = plugin.ipamInvoker.Delete(ctx, nil, nwCfg, args, nwInfo.Options)
 			if err != nil {
 				return plugin.RetriableError(fmt.Errorf("failed to release address: %w", err))
 			}
@@ -1191,7 +1582,7 @@ func (plugin *NetPlugin) Update(args *cniSkel.CmdArgs) error {
 
 	logger.Info("Read network configuration", zap.Any("config", nwCfg))
 
-	iptables.DisableIPTableLock = nwCfg.DisableIPTableLock
+	setNetfilterOptions(nwCfg)
 	plugin.setCNIReportDetails(nwCfg, CNI_UPDATE, "")
 
 	defer func() {
@@ -1284,7 +1675,7 @@ func (plugin *NetPlugin) Update(args *cniSkel.CmdArgs) error {
 		return plugin.Errorf(err.Error())
 	}
 
-	cnsclient, err := cnscli.New(nwCfg.CNSUrl, defaultRequestTimeout)
+	cnsclient, err := newCNSClient(nwCfg.CNSUrl, nwCfg)
 	if err != nil {
 		logger.Error("failed to initialized cns client",
 			zap.String("url", nwCfg.CNSUrl),
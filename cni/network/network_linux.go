@@ -108,6 +108,36 @@ func getPoliciesFromRuntimeCfg(_ *cni.NetworkConfig, _ bool) ([]policy.Policy, e
 	return nil, nil
 }
 
+// getPortMappings returns the port mappings from the runtime config for the linux endpoint clients to program.
+func getPortMappings(nwCfg *cni.NetworkConfig) []network.PortMapping {
+	portMappings := make([]network.PortMapping, 0, len(nwCfg.RuntimeConfig.PortMappings))
+	for _, mapping := range nwCfg.RuntimeConfig.PortMappings {
+		portMappings = append(portMappings, network.PortMapping{
+			HostPort:      mapping.HostPort,
+			ContainerPort: mapping.ContainerPort,
+			Protocol:      mapping.Protocol,
+			HostIP:        mapping.HostIp,
+		})
+	}
+
+	return portMappings
+}
+
+// getBandwidthLimits returns the bandwidth limits from the runtime config for the linux endpoint clients to shape.
+func getBandwidthLimits(nwCfg *cni.NetworkConfig) *network.BandwidthLimits {
+	bw := nwCfg.RuntimeConfig.Bandwidth
+	if bw == nil || (bw.IngressRate == 0 && bw.EgressRate == 0) {
+		return nil
+	}
+
+	return &network.BandwidthLimits{
+		IngressRate:  bw.IngressRate,
+		IngressBurst: bw.IngressBurst,
+		EgressRate:   bw.EgressRate,
+		EgressBurst:  bw.EgressBurst,
+	}
+}
+
 func addIPV6EndpointPolicy(nwInfo network.NetworkInfo) (policy.Policy, error) {
 	return policy.Policy{}, nil
 }
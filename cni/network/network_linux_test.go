@@ -4,8 +4,10 @@
 package network
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/Azure/azure-container-networking/cni"
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/network"
 	"github.com/stretchr/testify/assert"
@@ -160,3 +162,36 @@ func TestAddSnatForDns(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPortMappings(t *testing.T) {
+	nwCfg := &cni.NetworkConfig{
+		RuntimeConfig: cni.RuntimeConfig{
+			PortMappings: []cni.PortMapping{
+				{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+				{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIp: "10.0.0.1"},
+			},
+		},
+	}
+
+	require.Equal(t, []network.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "10.0.0.1"},
+	}, getPortMappings(nwCfg))
+	require.Empty(t, getPortMappings(&cni.NetworkConfig{}))
+}
+
+func TestGetBandwidthLimits(t *testing.T) {
+	var nwCfg cni.NetworkConfig
+	err := json.Unmarshal([]byte(`{"runtimeConfig":{"bandwidth":{"ingressRate":1000000,"ingressBurst":80000,"egressRate":2000000,"egressBurst":160000}}}`), &nwCfg)
+	require.NoError(t, err)
+
+	require.Equal(t, &network.BandwidthLimits{
+		IngressRate:  1000000,
+		IngressBurst: 80000,
+		EgressRate:   2000000,
+		EgressBurst:  160000,
+	}, getBandwidthLimits(&nwCfg))
+
+	require.Nil(t, getBandwidthLimits(&cni.NetworkConfig{}))
+	require.Nil(t, getBandwidthLimits(&cni.NetworkConfig{RuntimeConfig: cni.RuntimeConfig{Bandwidth: &cni.BandwidthEntry{IngressBurst: 80000}}}))
+}
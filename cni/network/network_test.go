@@ -1,8 +1,12 @@
 package network
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime"
 	"testing"
@@ -16,8 +20,10 @@ import (
 	"github.com/Azure/azure-container-networking/network/networkutils"
 	"github.com/Azure/azure-container-networking/network/policy"
 	"github.com/Azure/azure-container-networking/nns"
+	"github.com/Azure/azure-container-networking/store"
 	"github.com/Azure/azure-container-networking/telemetry"
 	cniSkel "github.com/containernetworking/cni/pkg/skel"
+	cniTypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -606,6 +612,268 @@ func TestPluginGet(t *testing.T) {
 	}
 }
 
+func TestPluginCheck(t *testing.T) {
+	plugin, _ := cni.NewPlugin("name", "0.4.0")
+
+	tests := []struct {
+		name     string
+		methods  []string
+		wantErr  bool
+		wantCode uint
+	}{
+		{
+			name:    "CNI Check happy path",
+			methods: []string{CNI_ADD, "CHECK"},
+			wantErr: false,
+		},
+		{
+			name:     "CNI Check fail with unknown container",
+			methods:  []string{"CHECK"},
+			wantErr:  true,
+			wantCode: cniTypes.ErrUnknownContainer,
+		},
+		{
+			name:     "CNI Check fail after delete",
+			methods:  []string{CNI_ADD, CNI_DEL, "CHECK"},
+			wantErr:  true,
+			wantCode: cniTypes.ErrUnknownContainer,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			netPlugin := &NetPlugin{
+				Plugin:      plugin,
+				nm:          acnnetwork.NewMockNetworkmanager(acnnetwork.NewMockEndpointClient(nil)),
+				ipamInvoker: NewMockIpamInvoker(false, false, false, false, false),
+				report:      &telemetry.CNIReport{},
+				tb:          &telemetry.TelemetryBuffer{},
+			}
+
+			var err error
+			for _, method := range tt.methods {
+				switch method {
+				case CNI_ADD:
+					err = netPlugin.Add(args)
+				case CNI_DEL:
+					err = netPlugin.Delete(args)
+				case "CHECK":
+					err = netPlugin.Check(args)
+				}
+			}
+
+			if tt.wantErr {
+				var cniErr *cniTypes.Error
+				require.ErrorAs(t, err, &cniErr)
+				require.Equal(t, tt.wantCode, cniErr.Code)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPluginStatus(t *testing.T) {
+	cnsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(cns.NumOfCPUCoresResponse{NumOfCPUCores: 1})
+	}))
+	defer cnsServer.Close()
+
+	tests := []struct {
+		name     string
+		cnsURL   string
+		store    bool
+		wantErr  bool
+		wantCode uint
+	}{
+		{
+			name:    "CNI Status happy path",
+			cnsURL:  cnsServer.URL,
+			store:   true,
+			wantErr: false,
+		},
+		{
+			name:     "CNI Status fail when store is not initialized",
+			cnsURL:   cnsServer.URL,
+			store:    false,
+			wantErr:  true,
+			wantCode: cni.ErrPluginNotAvailable,
+		},
+		{
+			name:     "CNI Status fail when CNS is not reachable",
+			cnsURL:   "http://127.0.0.1:1",
+			store:    true,
+			wantErr:  true,
+			wantCode: cni.ErrPluginNotAvailable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, _ := cni.NewPlugin("name", "1.1.0")
+			if tt.store {
+				plugin.Store = store.NewMockStore("")
+			}
+
+			netPlugin := &NetPlugin{
+				Plugin: plugin,
+				nm:     acnnetwork.NewMockNetworkmanager(acnnetwork.NewMockEndpointClient(nil)),
+				report: &telemetry.CNIReport{},
+				tb:     &telemetry.TelemetryBuffer{},
+			}
+
+			statusCfg := nwCfg
+			statusCfg.CNSUrl = tt.cnsURL
+			err := netPlugin.Status(&cniSkel.CmdArgs{StdinData: statusCfg.Serialize()})
+
+			if tt.wantErr {
+				var cniErr *cniTypes.Error
+				require.ErrorAs(t, err, &cniErr)
+				require.Equal(t, tt.wantCode, cniErr.Code)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPluginGC(t *testing.T) {
+	plugin, _ := cni.NewPlugin("name", "1.1.0")
+
+	liveEpInfo := &acnnetwork.EndpointInfo{
+		EndpointID:  "live-ctr-eth0",
+		ContainerID: "live-container",
+		IfName:      eth0IfName,
+		NetworkID:   nwCfg.Name,
+		IPAddresses: []net.IPNet{{IP: net.ParseIP("10.240.0.4"), Mask: net.CIDRMask(16, 32)}},
+	}
+	// a second attachment of the live container which the runtime no longer knows about
+	staleAttachmentEpInfo := &acnnetwork.EndpointInfo{
+		EndpointID:  "live-ctr-net1",
+		ContainerID: "live-container",
+		IfName:      "net1",
+		NetworkID:   nwCfg.Name,
+	}
+	// delegated NIC endpoints store the master interface name, so they stay with their container
+	liveDelegatedEpInfo := &acnnetwork.EndpointInfo{
+		EndpointID:  "live-ctr-1",
+		ContainerID: "live-container",
+		IfName:      "eth1",
+		NICType:     cns.DelegatedVMNIC,
+		NetworkID:   nwCfg.Name,
+	}
+	staleEpInfo := &acnnetwork.EndpointInfo{
+		EndpointID:   "stale-ct-eth0",
+		ContainerID:  "stale-container",
+		IfName:       eth0IfName,
+		NetworkID:    nwCfg.Name,
+		PODName:      "stale-pod",
+		PODNameSpace: "default",
+		IPAddresses:  []net.IPNet{{IP: net.ParseIP("10.240.0.5"), Mask: net.CIDRMask(16, 32)}},
+	}
+	staleReleaseRequest := cns.IPConfigsRequest{
+		OrchestratorContext: marshallPodInfo(cns.KubernetesPodInfo{PodName: "stale-pod", PodNamespace: "default"}),
+		PodInterfaceID:      "stale-ct-eth0",
+		InfraContainerID:    "stale-container",
+		DesiredIPAddresses:  []string{"10.240.0.5"},
+	}
+	liveEndpoints := []string{"live-ctr-eth0", "live-ctr-1"}
+
+	tests := []struct {
+		name          string
+		withoutCNS    bool
+		dryRun        bool
+		releaseErr    error
+		wantErr       bool
+		wantEndpoints []string
+	}{
+		{
+			name:          "GC removes stale endpoints",
+			wantEndpoints: liveEndpoints,
+		},
+		{
+			name:          "GC fails to release IPs",
+			releaseErr:    errors.New("release failed"), //nolint:goerr113 // test error
+			wantErr:       true,
+			wantEndpoints: liveEndpoints,
+		},
+		{
+			name:          "GC releases IPs through the IPAM plugin without CNS",
+			withoutCNS:    true,
+			wantEndpoints: liveEndpoints,
+		},
+		{
+			name:   "GC dry run deletes nothing",
+			dryRun: true,
+			// releasing the IPs in CNS would fail GC
+			releaseErr:    errors.New("release failed"), //nolint:goerr113 // test error
+			wantEndpoints: []string{"live-ctr-eth0", "live-ctr-net1", "live-ctr-1", "stale-ct-eth0"},
+		},
+		{
+			name:          "GC dry run releases no IPs through the IPAM plugin",
+			withoutCNS:    true,
+			dryRun:        true,
+			wantEndpoints: []string{"live-ctr-eth0", "live-ctr-net1", "live-ctr-1", "stale-ct-eth0"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			nm := acnnetwork.NewMockNetworkmanager(acnnetwork.NewMockEndpointClient(nil))
+			for _, epInfo := range []*acnnetwork.EndpointInfo{liveEpInfo, staleAttachmentEpInfo, liveDelegatedEpInfo, staleEpInfo} {
+				nm.TestEndpointInfoMap[epInfo.EndpointID] = epInfo
+			}
+			nm.TestNetworkInfoMap[nwCfg.Name] = &acnnetwork.EndpointInfo{NetworkID: nwCfg.Name}
+
+			ipamInvoker := NewMockIpamInvoker(false, false, false, false, false)
+			ipamInvoker.ipMap[staleEpInfo.IPAddresses[0].String()] = true
+			netPlugin := &NetPlugin{
+				Plugin:      plugin,
+				nm:          nm,
+				ipamInvoker: ipamInvoker,
+				report:      &telemetry.CNIReport{},
+				tb:          &telemetry.TelemetryBuffer{},
+			}
+
+			var cnsClient cnsclient
+			if !tt.withoutCNS {
+				cnsClient = &MockCNSClient{
+					require: require.New(t),
+					releaseIPs: releaseIPsHandler{
+						ipconfigArgument: staleReleaseRequest,
+						err:              tt.releaseErr,
+					},
+				}
+			}
+
+			gcCfg := nwCfg
+			gcCfg.ValidAttachments = []cniTypes.GCAttachment{{ContainerID: "live-container", IfName: eth0IfName}}
+			gcCfg.GCDryRun = tt.dryRun
+
+			err := netPlugin.gc(&gcCfg, cnsClient)
+			if tt.wantErr {
+				var cniErr *cniTypes.Error
+				require.ErrorAs(t, err, &cniErr)
+				require.Equal(t, cniTypes.ErrTryAgainLater, cniErr.Code)
+			} else {
+				require.NoError(t, err)
+			}
+
+			endpoints, _ := nm.GetAllEndpoints(nwCfg.Name)
+			require.Len(t, endpoints, len(tt.wantEndpoints))
+			for _, endpointID := range tt.wantEndpoints {
+				require.Contains(t, endpoints, endpointID)
+			}
+
+			// the IPAM plugin only releases the IPs when there's no CNS
+			require.Equal(t, !tt.withoutCNS || tt.dryRun, ipamInvoker.ipMap[staleEpInfo.IPAddresses[0].String()])
+		})
+	}
+}
+
 /*
 Multitenancy scenarios
 */
@@ -249,6 +249,16 @@ func getPoliciesFromRuntimeCfg(nwCfg *cni.NetworkConfig, isIPv6Enabled bool) ([]
 	return policies, nil
 }
 
+// getPortMappings returns nil on windows where port mappings are programmed as HNS endpoint policies.
+func getPortMappings(_ *cni.NetworkConfig) []network.PortMapping {
+	return nil
+}
+
+// getBandwidthLimits returns nil on windows where bandwidth limits are not shaped by the plugin.
+func getBandwidthLimits(_ *cni.NetworkConfig) *network.BandwidthLimits {
+	return nil
+}
+
 func getEndpointPolicies(args PolicyArgs) ([]policy.Policy, error) {
 	var policies []policy.Policy
 
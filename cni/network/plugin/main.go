@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -18,6 +19,8 @@ import (
 	"github.com/Azure/azure-container-networking/platform"
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/Azure/azure-container-networking/telemetry"
+	"github.com/Azure/azure-container-networking/tracing"
+	cniTypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -89,26 +92,33 @@ func rootExecute() error {
 	// Check CNI_COMMAND value
 	cniCmd := os.Getenv(cni.Cmd)
 
-	if cniCmd != cni.CmdVersion {
-		logger.Info("Environment variable set", zap.String("CNI_COMMAND", cniCmd))
-
-		cniReport.GetReport(pluginName, version, ipamQueryURL)
+	var (
+		// started is set once the store is locked, startFailed once a failure to start is reported.
+		started, startFailed bool
+		stopTracing          = func() {}
+	)
 
-		var upTime time.Time
-		p := platform.NewExecClient(logger)
-		upTime, err = p.GetLastRebootTime()
-		if err == nil {
-			cniReport.VMUptime = upTime.Format("2006-01-02 15:04:05")
+	// start locks the store and starts the plugin. The network config of the command selects the store type, so the
+	// command handlers call it once the config is parsed. Commands without a network config, like
+	// GET_ENDPOINT_STATE, pass nil and open the store the node already uses.
+	start := func(nwCfg *cni.NetworkConfig) error {
+		if nwCfg != nil {
+			config.StoreType = nwCfg.StoreType
+			if nwCfg.Tracing != nil && nwCfg.Tracing.Exporter != "" {
+				stopTracing = initTracing(*nwCfg.Tracing)
+			}
 		}
 
 		// CNI Acquires lock
-		if err = netPlugin.Plugin.InitializeKeyValueStore(&config); err != nil {
-			network.PrintCNIError(fmt.Sprintf("Failed to initialize key-value store of network plugin: %v", err))
+		if err := netPlugin.Plugin.InitializeKeyValueStore(&config); err != nil {
+			startFailed = true
+			msg := fmt.Sprintf("Failed to initialize key-value store of network plugin: %v", err)
+			logger.Error(msg)
 
 			tb = telemetry.NewTelemetryBuffer(logger)
 			if tberr := tb.Connect(); tberr != nil {
 				logger.Error("Cannot connect to telemetry service", zap.Error(tberr))
-				return errors.Wrap(err, "lock acquire error")
+				return &cniTypes.Error{Code: cniTypes.ErrTryAgainLater, Msg: msg}
 			}
 
 			network.ReportPluginError(reportManager, tb, err)
@@ -127,38 +137,75 @@ func rootExecute() error {
 			}
 
 			tb.Close()
-			return errors.Wrap(err, "lock acquire error")
+			return &cniTypes.Error{Code: cniTypes.ErrTryAgainLater, Msg: msg}
 		}
-
-		defer func() {
-			if errUninit := netPlugin.Plugin.UninitializeKeyValueStore(); errUninit != nil {
-				logger.Error("Failed to uninitialize key-value store of network plugin", zap.Error(errUninit))
-			}
-
-			if recover() != nil {
-				os.Exit(1)
-			}
-		}()
+		started = true
 
 		// Start telemetry process if not already started. This should be done inside lock, otherwise multiple process
 		// end up creating/killing telemetry process results in undesired state.
 		tb = telemetry.NewTelemetryBuffer(logger)
 		tb.ConnectToTelemetryService(telemetryNumRetries, telemetryWaitTimeInMilliseconds)
-		defer tb.Close()
 
 		netPlugin.SetCNIReport(cniReport, tb)
 
 		t := time.Now()
 		cniReport.Timestamp = t.Format("2006-01-02 15:04:05")
 
-		if err = netPlugin.Start(&config); err != nil {
-			network.PrintCNIError(fmt.Sprintf("Failed to start network plugin, err:%v.\n", err))
+		if err := netPlugin.Start(&config); err != nil {
+			startFailed = true
+			msg := fmt.Sprintf("Failed to start network plugin, err:%v.", err)
+			logger.Error(msg)
 			network.ReportPluginError(reportManager, tb, err)
-			panic("network plugin start fatal error")
+
+			code := uint(cniTypes.ErrTryAgainLater)
+			if cniCmd == cni.CmdStatus {
+				// An unreadable state store means the plugin cannot service ADD; report it instead of crashing.
+				code = cni.ErrPluginNotAvailable
+			}
+			return &cniTypes.Error{Code: code, Msg: msg}
+		}
+
+		return nil
+	}
+
+	defer func() {
+		if started {
+			tb.Close()
+
+			if errUninit := netPlugin.Plugin.UninitializeKeyValueStore(); errUninit != nil {
+				logger.Error("Failed to uninitialize key-value store of network plugin", zap.Error(errUninit))
+			}
+		}
+
+		stopTracing()
+
+		if recover() != nil {
+			os.Exit(1)
+		}
+	}()
+
+	if cniCmd != cni.CmdVersion {
+		logger.Info("Environment variable set", zap.String("CNI_COMMAND", cniCmd))
+
+		cniReport.GetReport(pluginName, version, ipamQueryURL)
+
+		var upTime time.Time
+		p := platform.NewExecClient(logger)
+		upTime, err = p.GetLastRebootTime()
+		if err == nil {
+			cniReport.VMUptime = upTime.Format("2006-01-02 15:04:05")
 		}
 
 		// used to dump state
 		if cniCmd == cni.CmdGetEndpointsState {
+			if err = start(nil); err != nil {
+				var cniErr *cniTypes.Error
+				if errors.As(err, &cniErr) {
+					_ = cniErr.Print()
+				}
+				return errors.Wrap(err, "Start netplugin failure")
+			}
+
 			logger.Debug("Retrieving state")
 			var simpleState *api.AzureCNIState
 			simpleState, err = netPlugin.GetAllEndpointState("azure")
@@ -176,10 +223,10 @@ func rootExecute() error {
 		}
 	}
 
-	handled, _ := network.HandleIfCniUpdate(netPlugin.Update)
+	handled, _ := network.HandleIfCniUpdate(cni.WithStart(netPlugin.Update, start))
 	if handled {
 		logger.Info("CNI UPDATE finished.")
-	} else if err = netPlugin.Execute(cni.PluginApi(netPlugin)); err != nil {
+	} else if err = netPlugin.ExecuteWithStart(cni.PluginApi(netPlugin), start); err != nil {
 		logger.Error("Failed to execute network plugin", zap.Error(err))
 	}
 
@@ -189,13 +236,40 @@ func rootExecute() error {
 
 	netPlugin.Stop()
 
-	if err != nil {
+	// A failure to start is already reported.
+	if err != nil && !startFailed {
 		network.ReportPluginError(reportManager, tb, err)
 	}
 
 	return errors.Wrap(err, "Execute netplugin failure")
 }
 
+// initTracing exports the spans of the command as configured, the returned function flushes them.
+func initTracing(cfg cni.TracingConfig) func() {
+	noop := func() {}
+	if cfg.Exporter == tracing.ExporterStdout {
+		logger.Error("Trace exporter writes to stdout which carries the CNI result, tracing is disabled")
+		return noop
+	}
+
+	shutdown, err := tracing.Init(tracing.Config{
+		Exporter:       cfg.Exporter,
+		Path:           cfg.Path,
+		ServiceName:    name,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		logger.Error("Failed to initialize tracing", zap.Error(err))
+		return noop
+	}
+
+	return func() {
+		if err := shutdown(context.Background()); err != nil {
+			logger.Error("Failed to flush traces", zap.Error(err))
+		}
+	}
+}
+
 // Main is the entry point for CNI network plugin.
 func main() {
 	// Initialize and parse command line arguments.
@@ -64,8 +64,39 @@ func (plugin *Plugin) Uninitialize() {
 	plugin.Plugin.Uninitialize()
 }
 
+// StartFunc starts a plugin for a command with the network configuration of the command. The configuration is nil
+// for a command without one or if it cannot be parsed, which the command handler then reports.
+type StartFunc func(nwCfg *NetworkConfig) error
+
+// WithStart returns a command handler which starts the plugin with the parsed network configuration of the command
+// before calling cmd, so settings needed to start the plugin, such as the store type, come from the configuration.
+func WithStart(cmd func(*cniSkel.CmdArgs) error, start StartFunc) func(*cniSkel.CmdArgs) error {
+	if cmd == nil {
+		return nil
+	}
+
+	return func(args *cniSkel.CmdArgs) error {
+		nwCfg, err := ParseNetworkConfig(args.StdinData)
+		if err != nil {
+			nwCfg = nil
+		}
+
+		if err = start(nwCfg); err != nil {
+			return err
+		}
+
+		return cmd(args)
+	}
+}
+
 // Execute executes the CNI command.
-func (plugin *Plugin) Execute(api PluginApi) (err error) {
+func (plugin *Plugin) Execute(api PluginApi) error {
+	return plugin.ExecuteWithStart(api, nil)
+}
+
+// ExecuteWithStart executes the CNI command, calling start with the network configuration of the command before
+// its handler if start is not nil.
+func (plugin *Plugin) ExecuteWithStart(api PluginApi, start StartFunc) (err error) {
 	// Recover from panics and convert them to CNI errors.
 	defer func() {
 		if r := recover(); r != nil {
@@ -90,7 +121,29 @@ func (plugin *Plugin) Execute(api PluginApi) (err error) {
 	pluginInfo := cniVers.PluginSupports(supportedVersions...)
 
 	// Parse args and call the appropriate cmd handler.
-	cniErr := cniSkel.PluginMainWithError(api.Add, api.Get, api.Delete, pluginInfo, plugin.version)
+	funcs := cniSkel.CNIFuncs{
+		Add:   api.Add,
+		Check: api.Get,
+		Del:   api.Delete,
+	}
+	if checkAPI, ok := api.(CheckApi); ok {
+		funcs.Check = checkAPI.Check
+	}
+	if statusAPI, ok := api.(StatusApi); ok {
+		funcs.Status = statusAPI.Status
+	}
+	if gcAPI, ok := api.(GcApi); ok {
+		funcs.GC = gcAPI.GC
+	}
+	if start != nil {
+		funcs.Add = WithStart(funcs.Add, start)
+		funcs.Check = WithStart(funcs.Check, start)
+		funcs.Del = WithStart(funcs.Del, start)
+		funcs.Status = WithStart(funcs.Status, start)
+		funcs.GC = WithStart(funcs.GC, start)
+	}
+
+	cniErr := cniSkel.PluginMainFuncsWithError(funcs, pluginInfo, plugin.version)
 	if cniErr != nil {
 		cniErr.Print()
 		return cniErr
@@ -191,7 +244,7 @@ func (plugin *Plugin) InitializeKeyValueStore(config *common.PluginConfig) error
 			return errors.Wrap(err, "error creating new filelock")
 		}
 
-		plugin.Store, err = store.NewJsonFileStore(platform.CNIRuntimePath+plugin.Name+".json", lockclient, storeLogger)
+		plugin.Store, err = store.NewStore(config.StoreType, platform.CNIRuntimePath+plugin.Name, lockclient, storeLogger)
 		if err != nil {
 			logger.Error("Failed to create store", zap.Error(err))
 			return err
@@ -167,9 +167,27 @@ func main() {
 		GetEnvRetryWaitTimeInSecs:    config.GetEnvRetryWaitTimeInSecs,
 	}
 
-	if tb.CreateAITelemetryHandle(aiConfig, config.DisableAll, config.DisableTrace, config.DisableMetric) != nil {
+	if tb.CreateAITelemetryHandle(aiConfig, config.DisableAll, config.DisableMetric, config.DisableTrace) != nil {
 		logger.Error("AI Handle creation error", zap.Error(err))
 	}
+
+	if config.OTLPEndpoint != "" {
+		otlpConfig := aitelemetry.OTLPConfig{
+			AppName:                      pluginName,
+			AppVersion:                   version,
+			Endpoint:                     config.OTLPEndpoint,
+			Protocol:                     config.OTLPProtocol,
+			Insecure:                     config.OTLPInsecure,
+			Headers:                      config.OTLPHeaders,
+			BatchInterval:                config.BatchIntervalInSecs,
+			RefreshTimeout:               config.RefreshTimeoutInSecs,
+			DisableMetadataRefreshThread: config.DisableMetadataThread,
+			DebugMode:                    config.DebugMode,
+		}
+		if err = tb.CreateOTLPTelemetryHandle(otlpConfig, config.DisableAll, config.DisableMetric, config.DisableTrace); err != nil {
+			logger.Error("OTLP Handle creation error", zap.Error(err))
+		}
+	}
 	logger.Info("Report to host interval", zap.Duration("seconds", config.ReportToHostIntervalInSeconds))
 	tb.PushData(context.Background())
 	telemetry.CloseAITelemetryHandle()
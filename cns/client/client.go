@@ -11,9 +11,12 @@ import (
 	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
+	pb "github.com/Azure/azure-container-networking/cns/grpc/v1alpha"
 	"github.com/Azure/azure-container-networking/cns/restserver"
 	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/tracing"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -56,6 +59,10 @@ type do interface {
 type Client struct {
 	client do
 	routes map[string]url.URL
+	// grpc is set in gRPC mode, see NewWithGRPC.
+	grpc           pb.CNSClient
+	conn           *grpc.ClientConn
+	requestTimeout time.Duration
 }
 
 type ConnectionFailureErr struct {
@@ -79,7 +86,8 @@ func New(baseURL string, requestTimeout time.Duration) (*Client, error) {
 
 	return &Client{
 		client: &http.Client{
-			Timeout: requestTimeout,
+			Timeout:   requestTimeout,
+			Transport: tracing.NewTransport(nil),
 		},
 		routes: routes,
 	}, nil
@@ -398,6 +406,12 @@ func (c *Client) RequestIPs(ctx context.Context, ipconfig cns.IPConfigsRequest)
 		}
 	}()
 
+	if c.grpc != nil {
+		var response *cns.IPConfigsResponse
+		response, err = c.requestIPsGRPC(ctx, ipconfig)
+		return response, err
+	}
+
 	var body bytes.Buffer
 	err = json.NewEncoder(&body).Encode(ipconfig)
 	if err != nil {
@@ -442,6 +456,10 @@ func (c *Client) RequestIPs(ctx context.Context, ipconfig cns.IPConfigsRequest)
 
 // ReleaseIPs calls releaseIPs on which releases the IPs on the pod
 func (c *Client) ReleaseIPs(ctx context.Context, ipconfig cns.IPConfigsRequest) error {
+	if c.grpc != nil {
+		return c.releaseIPsGRPC(ctx, ipconfig)
+	}
+
 	var body bytes.Buffer
 	err := json.NewEncoder(&body).Encode(ipconfig)
 	if err != nil {
@@ -1025,6 +1043,10 @@ func (c *Client) GetHomeAz(ctx context.Context) (*cns.GetHomeAzResponse, error)
 
 // GetEndpoint calls the EndpointHandlerAPI in CNS to retrieve the state of a given EndpointID
 func (c *Client) GetEndpoint(ctx context.Context, endpointID string) (*restserver.GetEndpointResponse, error) {
+	if c.grpc != nil {
+		return c.getEndpointGRPC(ctx, endpointID)
+	}
+
 	// build the request
 	u := c.routes[cns.EndpointAPI]
 	uString := u.String() + endpointID
@@ -1060,6 +1082,10 @@ func (c *Client) GetEndpoint(ctx context.Context, endpointID string) (*restserve
 // UpdateEndpoint calls the EndpointHandlerAPI in CNS
 // to update the state of a given EndpointID with either HNSEndpointID or HostVethName
 func (c *Client) UpdateEndpoint(ctx context.Context, endpointID string, ipInfo map[string]*restserver.IPInfo) (*cns.Response, error) {
+	if c.grpc != nil {
+		return c.updateEndpointGRPC(ctx, endpointID, ipInfo)
+	}
+
 	// build the request
 	var body bytes.Buffer
 
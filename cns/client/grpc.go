@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	cnsgrpc "github.com/Azure/azure-container-networking/cns/grpc"
+	pb "github.com/Azure/azure-container-networking/cns/grpc/v1alpha"
+	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/Azure/azure-container-networking/tracing"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrGRPCDisabled is returned by the APIs which are only served over gRPC, when the client does not use gRPC.
+	ErrGRPCDisabled = errors.New("client was not created with a gRPC address")
+	// ErrWatchResync is returned when a watch can not be resumed and must start over with a snapshot.
+	ErrWatchResync = errors.New("watch can not be resumed, resync with a snapshot")
+)
+
+// NewWithGRPC returns a new CNS client which calls the IPAM and endpoint APIs (RequestIPs, ReleaseIPs,
+// GetEndpoint and UpdateEndpoint) over gRPC at grpcAddress, and the other APIs over HTTP at baseURL.
+// The connection is established lazily, on the first call.
+func NewWithGRPC(baseURL, grpcAddress string, requestTimeout time.Duration, opts ...grpc.DialOption) (*Client, error) {
+	c, err := New(baseURL, requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.GRPCDialOption()}, opts...)
+	conn, err := grpc.NewClient(grpcAddress, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create gRPC client for %s", grpcAddress)
+	}
+
+	c.conn = conn
+	c.grpc = pb.NewCNSClient(conn)
+	c.requestTimeout = requestTimeout
+	return c, nil
+}
+
+// Close closes the gRPC connection of the client, if any.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return errors.Wrap(c.conn.Close(), "failed to close gRPC connection")
+}
+
+// withTimeout bounds a gRPC call by the request timeout of the client, as the http client does for REST calls.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.requestTimeout)
+}
+
+// grpcError converts the status error of a failed gRPC call to a CNSClientError carrying the CNS response code.
+func grpcError(err error) error {
+	code, message := cnsgrpc.ResponseCode(err)
+	return &CNSClientError{
+		Code: code,
+		Err:  errors.New(message),
+	}
+}
+
+func (c *Client) requestIPsGRPC(ctx context.Context, ipconfig cns.IPConfigsRequest) (*cns.IPConfigsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.grpc.RequestIPConfigs(ctx, cnsgrpc.IPConfigsRequestToPB(&ipconfig))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &cns.IPConfigsResponse{
+		PodIPInfo: cnsgrpc.PodIPInfoFromPB(resp.GetPodIPInfo()),
+		Response: cns.Response{
+			ReturnCode: types.Success,
+		},
+	}, nil
+}
+
+func (c *Client) releaseIPsGRPC(ctx context.Context, ipconfig cns.IPConfigsRequest) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := c.grpc.ReleaseIPConfigs(ctx, cnsgrpc.IPConfigsRequestToPB(&ipconfig)); err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+func (c *Client) getEndpointGRPC(ctx context.Context, endpointID string) (*restserver.GetEndpointResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.grpc.GetEndpoint(ctx, &pb.GetEndpointRequest{EndpointID: endpointID})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	ipInfo, err := cnsgrpc.IPInfoFromPB(resp.GetIfnameToIPMap())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode GetEndpointResponse")
+	}
+
+	return &restserver.GetEndpointResponse{
+		Response: restserver.Response{
+			ReturnCode: types.Success,
+		},
+		EndpointInfo: restserver.EndpointInfo{
+			PodName:       resp.GetPodName(),
+			PodNamespace:  resp.GetPodNamespace(),
+			IfnameToIPMap: ipInfo,
+		},
+	}, nil
+}
+
+func (c *Client) updateEndpointGRPC(ctx context.Context, endpointID string, ipInfo map[string]*restserver.IPInfo) (*cns.Response, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req := &pb.UpdateEndpointRequest{
+		EndpointID:    endpointID,
+		IfnameToIPMap: cnsgrpc.IPInfoToPB(ipInfo),
+	}
+	if _, err := c.grpc.UpdateEndpoint(ctx, req); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &cns.Response{ReturnCode: types.Success}, nil
+}
+
+// Watch calls handle with each change to the IP pool, network container and endpoint state of CNS until the
+// context is done, handle fails or the stream breaks. It requires a client created with NewWithGRPC.
+// A broken watch is resumed with the StreamID and Sequence of the last event handled, and must start over with
+// a snapshot if Watch returns ErrWatchResync, e.g. after CNS restarted.
+func (c *Client) Watch(ctx context.Context, req restserver.WatchRequest, handle func(*restserver.WatchEvent) error) error {
+	if c.grpc == nil {
+		return ErrGRPCDisabled
+	}
+
+	stream, err := c.grpc.Watch(ctx, &pb.WatchRequest{
+		StreamID:     req.StreamID,
+		FromSequence: req.FromSequence,
+		Snapshot:     req.Snapshot,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to start watch")
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if status.Code(err) == codes.OutOfRange {
+				return errors.Wrap(ErrWatchResync, status.Convert(err).Message())
+			}
+			return errors.Wrap(err, "watch failed")
+		}
+		event, err := cnsgrpc.WatchEventFromPB(resp)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode WatchEvent")
+		}
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	cnsgrpc "github.com/Azure/azure-container-networking/cns/grpc"
+	pb "github.com/Azure/azure-container-networking/cns/grpc/v1alpha"
+	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCTestClient serves the CNS gRPC API of the test service over an in-memory listener
+// and returns a client which calls it.
+func newGRPCTestClient(t *testing.T) *Client {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterCNSServer(server, &cnsgrpc.CNS{Logger: zap.NewNop(), State: svc})
+	go server.Serve(lis) //nolint:errcheck // stopped on cleanup
+	t.Cleanup(server.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+	c, err := NewWithGRPC("", "passthrough:///bufnet", 10*time.Second, grpc.WithContextDialer(dialer))
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestGRPCRequestAndReleaseIPs(t *testing.T) {
+	cnsClient := newGRPCTestClient(t)
+
+	orchestratorContext, err := json.Marshal(cns.KubernetesPodInfo{PodName: testpodname, PodNamespace: testpodnamespace})
+	require.NoError(t, err)
+	req := cns.IPConfigsRequest{
+		OrchestratorContext: orchestratorContext,
+		PodInterfaceID:      "some-guid-1-eth0",
+		InfraContainerID:    "some-guid-1",
+	}
+
+	// release whatever is assigned to the pod by the tests above, release is idempotent.
+	require.NoError(t, cnsClient.ReleaseIPs(context.TODO(), req))
+	addTestStateToRestServer(t, []string{primaryIP})
+
+	resp, err := cnsClient.RequestIPs(context.TODO(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.PodIPInfo, 1)
+
+	podIPInfo := resp.PodIPInfo[0]
+	assert.Equal(t, primaryIP, podIPInfo.PodIPConfig.IPAddress)
+	assert.EqualValues(t, subnetPrfixLength, podIPInfo.PodIPConfig.PrefixLength)
+	assert.Equal(t, dnsServers, podIPInfo.NetworkContainerPrimaryIPConfig.DNSServers)
+	assert.Equal(t, gatewayIP, podIPInfo.NetworkContainerPrimaryIPConfig.GatewayIPAddress)
+
+	ipaddresses, err := cnsClient.GetIPAddressesMatchingStates(context.TODO(), types.Assigned)
+	require.NoError(t, err)
+	require.Len(t, ipaddresses, 1)
+
+	require.NoError(t, cnsClient.ReleaseIPs(context.TODO(), req))
+
+	ipaddresses, err = cnsClient.GetIPAddressesMatchingStates(context.TODO(), types.Assigned)
+	require.NoError(t, err)
+	require.Empty(t, ipaddresses)
+}
+
+func TestGRPCErrors(t *testing.T) {
+	cnsClient := newGRPCTestClient(t)
+
+	_, err := cnsClient.RequestIPs(context.TODO(), cns.IPConfigsRequest{PodInterfaceID: "grpc-pod-eth0", InfraContainerID: "grpc-pod"})
+	var clientErr *CNSClientError
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, types.EmptyOrchestratorContext, clientErr.Code)
+
+	// the test service does not manage endpoint state.
+	_, err = cnsClient.GetEndpoint(context.TODO(), "0123456789abcdef0123456789abcdef")
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, types.UnexpectedError, clientErr.Code)
+}
+
+func TestGRPCWatch(t *testing.T) {
+	cnsClient := newGRPCTestClient(t)
+	addTestStateToRestServer(t, []string{primaryIP})
+
+	errStop := errors.New("stop")
+	var first *restserver.WatchEvent
+	err := cnsClient.Watch(context.TODO(), restserver.WatchRequest{Snapshot: true}, func(e *restserver.WatchEvent) error {
+		first = e
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.True(t, first.Snapshot)
+	require.NotEmpty(t, first.StreamID)
+
+	err = cnsClient.Watch(context.TODO(), restserver.WatchRequest{StreamID: "previous", FromSequence: 1}, func(*restserver.WatchEvent) error {
+		return nil
+	})
+	require.ErrorIs(t, err, ErrWatchResync)
+
+	httpClient, err := New("", time.Second)
+	require.NoError(t, err)
+	err = httpClient.Watch(context.TODO(), restserver.WatchRequest{}, func(*restserver.WatchEvent) error { return nil })
+	require.ErrorIs(t, err, ErrGRPCDisabled)
+}
@@ -10,17 +10,11 @@ import (
 	"github.com/pkg/errors"
 )
 
-// portmapConfig is the config for the upstream portmap plugin
-var portmapConfig any = struct {
-	Type         string          `json:"type"`
-	Capabilities map[string]bool `json:"capabilities"`
-	SNAT         bool            `json:"snat"`
-}{
-	Type: "portmap",
-	Capabilities: map[string]bool{
-		"portMappings": true,
-	},
-	SNAT: true,
+// azureCNICapabilities are the runtime config capabilities of azure-vnet, which programs the host ports and
+// shapes the bandwidth of the pods itself
+var azureCNICapabilities = map[string]bool{
+	"portMappings": true,
+	"bandwidth":    true,
 }
 
 // Generate writes the CNI conflist to the Generator's output stream
@@ -31,6 +25,7 @@ func (v *V4OverlayGenerator) Generate() error {
 		Plugins: []any{
 			cni.NetworkConfig{
 				Type:              overlaycniType,
+				Capabilities:      azureCNICapabilities,
 				Mode:              cninet.OpModeTransparent,
 				ExecutionMode:     string(util.V4Swift),
 				IPsToRouteViaHost: []string{nodeLocalDNSIP},
@@ -39,7 +34,6 @@ func (v *V4OverlayGenerator) Generate() error {
 					Mode: string(util.V4Overlay),
 				},
 			},
-			portmapConfig,
 		},
 	}
 
@@ -60,6 +54,7 @@ func (v *DualStackOverlayGenerator) Generate() error {
 		Plugins: []any{
 			cni.NetworkConfig{
 				Type:              overlaycniType,
+				Capabilities:      azureCNICapabilities,
 				Mode:              cninet.OpModeTransparent,
 				IPsToRouteViaHost: []string{nodeLocalDNSIP},
 				IPAM: cni.IPAM{
@@ -67,7 +62,6 @@ func (v *DualStackOverlayGenerator) Generate() error {
 					Mode: string(util.DualStackOverlay),
 				},
 			},
-			portmapConfig,
 		},
 	}
 
@@ -88,6 +82,7 @@ func (v *OverlayGenerator) Generate() error {
 		Plugins: []any{
 			cni.NetworkConfig{
 				Type:              overlaycniType,
+				Capabilities:      azureCNICapabilities,
 				Mode:              cninet.OpModeTransparent,
 				IPsToRouteViaHost: []string{nodeLocalDNSIP},
 				IPAM: cni.IPAM{
@@ -95,7 +90,6 @@ func (v *OverlayGenerator) Generate() error {
 					Mode: string(util.Overlay),
 				},
 			},
-			portmapConfig,
 		},
 	}
 
@@ -142,6 +136,7 @@ func (v *SWIFTGenerator) Generate() error {
 		Plugins: []any{
 			cni.NetworkConfig{
 				Type:              azureType,
+				Capabilities:      azureCNICapabilities,
 				Mode:              cninet.OpModeTransparent,
 				ExecutionMode:     string(util.V4Swift),
 				IPsToRouteViaHost: []string{nodeLocalDNSIP},
@@ -149,7 +144,6 @@ func (v *SWIFTGenerator) Generate() error {
 					Type: network.AzureCNS,
 				},
 			},
-			portmapConfig,
 		},
 	}
 
@@ -5,6 +5,7 @@ package common
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/Azure/azure-container-networking/cns/logger"
 	acn "github.com/Azure/azure-container-networking/common"
@@ -41,6 +42,8 @@ type ServiceConfig struct {
 	Server      server
 	ChannelMode string
 	TLSSettings tls.TlsSettings
+	// WrapHandler wraps the handlers of the listener if set, e.g. to continue the trace context of the caller.
+	WrapHandler func(handler http.Handler, path string) http.Handler
 }
 
 // server struct to store primaryInterfaceIP from VM, port where customer provides by -p and temporary flag EnableLocalServer
@@ -34,6 +34,7 @@ type CNSConfig struct {
 	EnableAsyncPodDelete        bool
 	EnableCNIConflistGeneration bool
 	EnableIPAMv2                bool
+	IPAMv2PredictiveScaling     *PredictiveScalingSettings
 	EnablePprof                 bool
 	EnableStateMigration        bool
 	EnableSubnetScarcity        bool
@@ -47,6 +48,7 @@ type CNSConfig struct {
 	MetricsBindAddress          string
 	ProgramSNATIPTables         bool
 	SWIFTV2Mode                 SWIFTV2Mode
+	StoreType                   string
 	SyncHostNCTimeoutMs         int
 	SyncHostNCVersionIntervalMs int
 	TLSCertificatePath          string
@@ -54,6 +56,7 @@ type CNSConfig struct {
 	TLSPort                     string
 	TLSSubjectName              string
 	TelemetrySettings           TelemetrySettings
+	TracingSettings             TracingSettings
 	UseHTTPS                    bool
 	UseMTLS                     bool
 	WatchPods                   bool `json:"-"`
@@ -62,7 +65,7 @@ type CNSConfig struct {
 }
 
 type TelemetrySettings struct {
-	// Flag to disable the telemetry.
+	// Flag to disable the appinsights telemetry, OTLP export is only controlled by OTLP.Endpoint.
 	DisableAll bool
 	// Flag to Disable sending trace.
 	DisableTrace bool
@@ -86,6 +89,26 @@ type TelemetrySettings struct {
 	SnapshotIntervalInMins int
 	// AppInsightsInstrumentationKey allows the user to override the default appinsights ikey
 	AppInsightsInstrumentationKey string
+	// OTLP configures the export of telemetry to an OpenTelemetry collector, alongside appinsights
+	OTLP OTLPSettings
+}
+
+type OTLPSettings struct {
+	// Endpoint is the host:port of the collector, export is disabled if empty
+	Endpoint string
+	// Protocol is grpc or http/protobuf, grpc if empty
+	Protocol string
+	// Disable TLS to the collector
+	Insecure bool
+	// Headers sent with each export, e.g. for authentication
+	Headers map[string]string
+}
+
+type TracingSettings struct {
+	// Exporter the spans are written to, stdout or file. If empty, spans are only exported with the OTLP telemetry.
+	Exporter string
+	// Path of the file the file exporter appends the spans to
+	Path string
 }
 
 type ManagedSettings struct {
@@ -109,8 +132,24 @@ type KeyVaultSettings struct {
 	RefreshIntervalInHrs int
 }
 
+// PredictiveScalingSettings enables the predictive scaling of the IPAM v2 pool, which requests IPs ahead
+// of the growth of the demand.
+type PredictiveScalingSettings struct {
+	// WindowSecs is how far back the demand is tracked to measure its growth rate.
+	WindowSecs int
+	// HorizonSecs is how far ahead the growth of the demand is provisioned for.
+	HorizonSecs int
+	// DecayHalfLifeSecs is how fast the pool scales back down once the demand stops growing.
+	DecayHalfLifeSecs int
+	// ResyncIntervalSecs is how often the pool is reconciled while the demand does not change.
+	ResyncIntervalSecs int
+}
+
 type GRPCSettings struct {
-	Enable    bool
+	// Enable is always reset to false, it predates the gRPC APIs and configs in the field may set it.
+	Enable bool
+	// ServeAPIs starts the gRPC server with the IPAM, endpoint and watch APIs. It is off by default.
+	ServeAPIs bool
 	IPAddress string
 	Port      uint16
 }
@@ -149,6 +188,11 @@ func readConfigFromFile(f string) (*CNSConfig, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read config file %s", f)
 	}
+	return parseConfig(content)
+}
+
+// parseConfig unmarshals the content of a config file in to a CNSConfig.
+func parseConfig(content []byte) (*CNSConfig, error) {
 	var config CNSConfig
 	if err := json.Unmarshal(content, &config); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal config")
@@ -197,6 +241,24 @@ func setAZRSettingsDefaults(azrSettings *AZRSettings) {
 	}
 }
 
+func setPredictiveScalingSettingsDefaults(settings *PredictiveScalingSettings) {
+	if settings == nil {
+		return
+	}
+	if settings.WindowSecs == 0 {
+		settings.WindowSecs = 60 //nolint:gomnd // default times
+	}
+	if settings.HorizonSecs == 0 {
+		settings.HorizonSecs = 30 //nolint:gomnd // default times
+	}
+	if settings.DecayHalfLifeSecs == 0 {
+		settings.DecayHalfLifeSecs = 120 //nolint:gomnd // default times
+	}
+	if settings.ResyncIntervalSecs == 0 {
+		settings.ResyncIntervalSecs = 15 //nolint:gomnd // default times
+	}
+}
+
 func setKeyVaultSettingsDefaults(kvs *KeyVaultSettings) {
 	if kvs.RefreshIntervalInHrs == 0 {
 		kvs.RefreshIntervalInHrs = 12 //nolint:gomnd // default times
@@ -209,6 +271,7 @@ func SetCNSConfigDefaults(config *CNSConfig) {
 	setManagedSettingDefaults(&config.ManagedSettings)
 	setKeyVaultSettingsDefaults(&config.KeyVaultSettings)
 	setAZRSettingsDefaults(&config.AZRSettings)
+	setPredictiveScalingSettingsDefaults(config.IPAMv2PredictiveScaling)
 
 	if config.ChannelMode == "" {
 		config.ChannelMode = cns.Direct
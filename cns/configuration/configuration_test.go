@@ -246,7 +246,8 @@ func TestSetCNSConfigDefaults(t *testing.T) {
 					PopulateHomeAzCacheRetryIntervalSecs: 10,
 				},
 				GRPCSettings: GRPCSettings{
-					Enable:    false,
+					Enable:    true,
+					ServeAPIs: true,
 					IPAddress: "192.168.1.1",
 					Port:      9090,
 				},
@@ -276,6 +277,7 @@ func TestSetCNSConfigDefaults(t *testing.T) {
 				AsyncPodDeletePath: "/var/run/azure-vnet/deleteIDs",
 				GRPCSettings: GRPCSettings{
 					Enable:    false,
+					ServeAPIs: true,
 					IPAddress: "192.168.1.1",
 					Port:      9090,
 				},
@@ -0,0 +1,39 @@
+package configuration
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	reloadResultSuccess = "success"
+	reloadResultInvalid = "invalid"
+	reloadResultError   = "error"
+)
+
+// reloads counts the reloads of the config file by result: success, invalid if the new config was rejected, or error
+// if it could not be read or a change could not be applied.
+var reloads = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cns_config_reloads_total",
+		Help: "Number of reloads of the CNS config file, by result.",
+	},
+	[]string{"result"},
+)
+
+// restartRequired is set to 1 for each field of the config file which differs from the running config and only
+// takes effect after CNS is restarted.
+var restartRequired = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cns_config_restart_required",
+		Help: "Set for the changed CNS config fields which require a restart to take effect.",
+	},
+	[]string{"field"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		reloads,
+		restartRequired,
+	)
+}
@@ -0,0 +1,303 @@
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// reloadDebounce coalesces the bursts of events written by editors and by the kubelet when it swaps a ConfigMap.
+const reloadDebounce = 500 * time.Millisecond
+
+// ReloadHook applies the change from oldConfig to newConfig to a running subsystem. The configs must not be modified.
+type ReloadHook func(oldConfig, newConfig *CNSConfig) error
+
+type reloadHook struct {
+	name   string
+	fields []string
+	hook   ReloadHook
+}
+
+// Reloader reloads the CNS config when its file changes or on SIGHUP. The fields which have a registered
+// ReloadHook are applied to the running CNS, the other changed fields only take effect after a restart and are
+// logged and reported in the cns_config_restart_required metric.
+type Reloader struct {
+	path string
+
+	mu      sync.Mutex
+	hooks   []reloadHook
+	running *CNSConfig
+	content []byte
+	restart []string
+}
+
+// NewReloader returns a Reloader for the config file at the cmdLineConfigPath, or from the env or default path if it
+// is empty, as for ReadConfig. current is the config CNS was started with, after SetCNSConfigDefaults, and is copied
+// so it must be passed before CNS changes it at runtime.
+func NewReloader(cmdLineConfigPath string, current *CNSConfig) (*Reloader, error) {
+	path, err := getConfigFilePath(cmdLineConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	running := *current
+	if current.IPAMv2PredictiveScaling != nil {
+		settings := *current.IPAMv2PredictiveScaling
+		running.IPAMv2PredictiveScaling = &settings
+	}
+	content, _ := os.ReadFile(path)
+	return &Reloader{
+		path:    path,
+		running: &running,
+		content: content,
+	}, nil
+}
+
+// Register adds a hook called when any of the fields changes. The fields are the dotted paths of the CNSConfig
+// fields, such as "TelemetrySettings.DisableTrace". Hooks are called in the order they are registered.
+func (r *Reloader) Register(name string, hook ReloadHook, fields ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, reloadHook{name: name, fields: fields, hook: hook})
+}
+
+// Reload reads and validates the config file and applies the changed fields. An invalid config is rejected and the
+// running config kept. The fields of a hook which fails keep their running value, and are retried on the next
+// Reload.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		reloads.WithLabelValues(reloadResultError).Inc()
+		return errors.Wrapf(err, "failed to read config file %s", r.path)
+	}
+	r.content = content
+	return r.reload()
+}
+
+func (r *Reloader) reload() error {
+	newConfig, err := parseConfig(r.content)
+	if err != nil {
+		reloads.WithLabelValues(reloadResultError).Inc()
+		return err
+	}
+	SetCNSConfigDefaults(newConfig)
+	if err := Validate(newConfig); err != nil {
+		reloads.WithLabelValues(reloadResultInvalid).Inc()
+		return errors.Wrap(err, "rejected invalid config")
+	}
+
+	changed := diffConfig(r.running, newConfig)
+	applied := map[string]bool{}
+	var errs []string
+	for _, h := range r.hooks {
+		fields := h.changedFields(changed)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, f := range fields {
+			applied[f] = true
+		}
+		if err := h.hook(r.running, newConfig); err != nil {
+			errs = append(errs, h.name+": "+err.Error())
+			continue
+		}
+		for _, f := range fields {
+			copyField(r.running, newConfig, f)
+		}
+		logger.Printf("[Configuration] Applied %s config change to %v", h.name, fields)
+	}
+
+	var restart []string
+	for _, f := range changed {
+		if !applied[f] {
+			restart = append(restart, f)
+		}
+	}
+	r.restart = restart
+	restartRequired.Reset()
+	for _, f := range restart {
+		restartRequired.WithLabelValues(f).Set(1)
+	}
+	if len(restart) > 0 {
+		logger.Warnf("[Configuration] Config fields changed which require a restart of CNS to take effect: %v", restart)
+	}
+
+	if len(errs) > 0 {
+		reloads.WithLabelValues(reloadResultError).Inc()
+		return errors.Errorf("failed to apply config changes: %s", strings.Join(errs, "; "))
+	}
+	reloads.WithLabelValues(reloadResultSuccess).Inc()
+	return nil
+}
+
+// RestartRequired returns the fields which changed in the last reload and which only take effect after a restart.
+func (r *Reloader) RestartRequired() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.restart...)
+}
+
+// Run reloads the config whenever its file changes, or CNS receives SIGHUP, until ctx is done. The directory of the
+// file is watched rather than the file, to follow the symlink swap of a mounted ConfigMap.
+func (r *Reloader) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "failed to create config file watcher")
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(r.path)); err != nil {
+		return errors.Wrapf(err, "failed to watch config dir of %s", r.path)
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	logger.Printf("[Configuration] Watching %s for config changes", r.path)
+	debounce := time.NewTimer(0)
+	if !debounce.Stop() {
+		<-debounce.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sighup:
+			logger.Printf("[Configuration] Received SIGHUP, reloading config")
+			if err := r.Reload(); err != nil {
+				logger.Errorf("[Configuration] Failed to reload config: %v", err)
+			}
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			debounce.Reset(reloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Errorf("[Configuration] Config file watcher error: %v", err)
+		case <-debounce.C:
+			if err := r.reloadIfChanged(); err != nil {
+				logger.Errorf("[Configuration] Failed to reload config: %v", err)
+			}
+		}
+	}
+}
+
+// reloadIfChanged reloads the config if the content of its file changed since it was last read.
+func (r *Reloader) reloadIfChanged() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		reloads.WithLabelValues(reloadResultError).Inc()
+		return errors.Wrapf(err, "failed to read config file %s", r.path)
+	}
+	if bytes.Equal(content, r.content) {
+		return nil
+	}
+	r.content = content
+	logger.Printf("[Configuration] Config file %s changed, reloading config", r.path)
+	return r.reload()
+}
+
+// changedFields returns the fields of the hook which are in changed, or are nested in a field of the hook.
+func (h *reloadHook) changedFields(changed []string) []string {
+	var fields []string
+	for _, c := range changed {
+		for _, f := range h.fields {
+			if c == f || strings.HasPrefix(c, f+".") {
+				fields = append(fields, c)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// diffConfig returns the sorted dotted paths of the leaf fields which differ between a and b. The fields which are
+// not read from the file are skipped.
+func diffConfig(a, b *CNSConfig) []string {
+	var changed []string
+	diffStruct(reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), "", &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+func diffStruct(a, b reflect.Value, prefix string, changed *[]string) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		path := prefix + f.Name
+		if f.Type.Kind() == reflect.Struct {
+			diffStruct(a.Field(i), b.Field(i), path+".", changed)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			*changed = append(*changed, path)
+		}
+	}
+}
+
+// copyField sets the field at the dotted path of dst to its value in src.
+func copyField(dst, src *CNSConfig, path string) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for _, name := range strings.Split(path, ".") {
+		d, s = d.FieldByName(name), s.FieldByName(name)
+	}
+	d.Set(s)
+}
+
+// Validate returns an error if the config, with the defaults set, is invalid.
+func Validate(config *CNSConfig) error {
+	var errs []string
+	switch config.ChannelMode {
+	case cns.Direct, cns.Managed, cns.CRD, cns.MultiTenantCRD:
+	default:
+		errs = append(errs, "unknown ChannelMode "+config.ChannelMode)
+	}
+	switch config.SWIFTV2Mode {
+	case "", SFSWIFTV2, K8sSWIFTV2:
+	default:
+		errs = append(errs, "unknown SWIFTV2Mode "+string(config.SWIFTV2Mode))
+	}
+	if config.SyncHostNCVersionIntervalMs <= 0 {
+		errs = append(errs, "SyncHostNCVersionIntervalMs must be positive")
+	}
+	if config.SyncHostNCTimeoutMs <= 0 {
+		errs = append(errs, "SyncHostNCTimeoutMs must be positive")
+	}
+	if config.MellanoxMonitorIntervalSecs < 0 {
+		errs = append(errs, "MellanoxMonitorIntervalSecs must not be negative")
+	}
+	if _, _, err := net.SplitHostPort(config.MetricsBindAddress); err != nil {
+		errs = append(errs, "invalid MetricsBindAddress: "+err.Error())
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
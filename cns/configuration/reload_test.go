@@ -0,0 +1,203 @@
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.InitLogger("testlogs", 0, 0, "./")
+}
+
+func writeConfig(t *testing.T, path string, config *CNSConfig) {
+	t.Helper()
+	b, err := json.Marshal(config)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+}
+
+func newTestReloader(t *testing.T) (r *Reloader, path string, config *CNSConfig) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "cns_config.json")
+	config = &CNSConfig{}
+	SetCNSConfigDefaults(config)
+	writeConfig(t, path, config)
+	r, err := NewReloader(path, config)
+	require.NoError(t, err)
+	return r, path, config
+}
+
+func TestReloadAppliesHook(t *testing.T) {
+	r, path, config := newTestReloader(t)
+	var got []int
+	r.Register("sync", func(oldConfig, newConfig *CNSConfig) error {
+		got = append(got, oldConfig.SyncHostNCVersionIntervalMs, newConfig.SyncHostNCVersionIntervalMs)
+		return nil
+	}, "SyncHostNCVersionIntervalMs")
+	var telemetry []string
+	r.Register("telemetry", func(_, newConfig *CNSConfig) error {
+		telemetry = append(telemetry, "called")
+		return nil
+	}, "TelemetrySettings")
+
+	// unchanged config calls no hook.
+	require.NoError(t, r.Reload())
+	assert.Empty(t, got)
+	assert.Empty(t, telemetry)
+
+	config.SyncHostNCVersionIntervalMs = 2000
+	config.TelemetrySettings.DisableTrace = true
+	writeConfig(t, path, config)
+	require.NoError(t, r.Reload())
+	assert.Equal(t, []int{1000, 2000}, got)
+	assert.Equal(t, []string{"called"}, telemetry)
+	assert.Empty(t, r.RestartRequired())
+
+	// the applied change is the new running config.
+	require.NoError(t, r.Reload())
+	assert.Equal(t, []int{1000, 2000}, got)
+}
+
+func TestReloadHookFailureIsRetried(t *testing.T) {
+	r, path, config := newTestReloader(t)
+	fail := true
+	calls := 0
+	r.Register("pprof", func(_, _ *CNSConfig) error {
+		calls++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}, "EnablePprof")
+
+	config.EnablePprof = true
+	writeConfig(t, path, config)
+	require.Error(t, r.Reload())
+	fail = false
+	require.NoError(t, r.Reload())
+	require.NoError(t, r.Reload())
+	assert.Equal(t, 2, calls)
+}
+
+func TestReloadRestartRequired(t *testing.T) {
+	r, path, config := newTestReloader(t)
+	config.ChannelMode = "CRD"
+	config.ManagedSettings.NodeID = "node"
+	writeConfig(t, path, config)
+	require.NoError(t, r.Reload())
+	assert.Equal(t, []string{"ChannelMode", "ManagedSettings.NodeID"}, r.RestartRequired())
+
+	// reverting the change clears it.
+	config.ChannelMode = "Direct"
+	config.ManagedSettings.NodeID = ""
+	writeConfig(t, path, config)
+	require.NoError(t, r.Reload())
+	assert.Empty(t, r.RestartRequired())
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	r, path, config := newTestReloader(t)
+	calls := 0
+	r.Register("healthserver", func(_, _ *CNSConfig) error {
+		calls++
+		return nil
+	}, "MetricsBindAddress")
+
+	config.MetricsBindAddress = "9091"
+	writeConfig(t, path, config)
+	require.Error(t, r.Reload())
+	assert.Zero(t, calls)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	require.Error(t, r.Reload())
+	assert.Zero(t, calls)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*CNSConfig)
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			modify: func(*CNSConfig) {},
+		},
+		{
+			name:    "unknown channel mode",
+			modify:  func(c *CNSConfig) { c.ChannelMode = "Indirect" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown swiftv2 mode",
+			modify:  func(c *CNSConfig) { c.SWIFTV2Mode = "SWIFTV3" },
+			wantErr: true,
+		},
+		{
+			name:    "negative sync interval",
+			modify:  func(c *CNSConfig) { c.SyncHostNCVersionIntervalMs = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "negative mellanox interval",
+			modify:  func(c *CNSConfig) { c.MellanoxMonitorIntervalSecs = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "metrics address without port",
+			modify:  func(c *CNSConfig) { c.MetricsBindAddress = "localhost" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &CNSConfig{}
+			SetCNSConfigDefaults(config)
+			tt.modify(config)
+			err := Validate(config)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunReloadsOnFileChange(t *testing.T) {
+	r, path, config := newTestReloader(t)
+	applied := make(chan int, 1)
+	r.Register("sync", func(_, newConfig *CNSConfig) error {
+		applied <- newConfig.SyncHostNCVersionIntervalMs
+		return nil
+	}, "SyncHostNCVersionIntervalMs")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+
+	// write until the watcher has started and picked up the change, slower than the debounce so that it fires.
+	config.SyncHostNCVersionIntervalMs = 3000
+	require.Eventually(t, func() bool {
+		writeConfig(t, path, config)
+		select {
+		case got := <-applied:
+			return got == 3000
+		default:
+			return false
+		}
+	}, 10*time.Second, time.Second)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -3,9 +3,14 @@ package grpc
 import (
 	"context"
 
+	"github.com/Azure/azure-container-networking/cns"
 	pb "github.com/Azure/azure-container-networking/cns/grpc/v1alpha"
 	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // CNSService defines the CNS gRPC service.
@@ -26,3 +31,89 @@ func (s *CNS) GetNodeInfo(_ context.Context, req *pb.NodeInfoRequest) (*pb.NodeI
 	// todo: Implement the logic
 	return &pb.NodeInfoResponse{}, nil
 }
+
+// RequestIPConfigs assigns IP configurations to a pod interface, like the requestipconfigs REST API.
+func (s *CNS) RequestIPConfigs(ctx context.Context, req *pb.IPConfigsRequest) (*pb.IPConfigsResponse, error) {
+	s.Logger.Info("RequestIPConfigs called", zap.String("infraContainerID", req.GetInfraContainerID()), zap.String("podInterfaceID", req.GetPodInterfaceID()))
+	resp, err := s.State.RequestIPConfigs(ctx, IPConfigsRequestFromPB(req))
+	if err != nil {
+		s.Logger.Error("RequestIPConfigs failed", zap.String("infraContainerID", req.GetInfraContainerID()), zap.Error(err))
+		return nil, failure(resp, err)
+	}
+	return &pb.IPConfigsResponse{PodIPInfo: PodIPInfoToPB(resp.PodIPInfo)}, nil
+}
+
+// ReleaseIPConfigs releases the IP configurations assigned to a pod interface, like the releaseipconfigs REST API.
+func (s *CNS) ReleaseIPConfigs(ctx context.Context, req *pb.IPConfigsRequest) (*pb.ReleaseIPConfigsResponse, error) {
+	s.Logger.Info("ReleaseIPConfigs called", zap.String("infraContainerID", req.GetInfraContainerID()), zap.String("podInterfaceID", req.GetPodInterfaceID()))
+	resp, err := s.State.ReleaseIPConfigHandlerHelper(ctx, IPConfigsRequestFromPB(req))
+	if err != nil {
+		s.Logger.Error("ReleaseIPConfigs failed", zap.String("infraContainerID", req.GetInfraContainerID()), zap.Error(err))
+		return nil, failure(resp, err)
+	}
+	return &pb.ReleaseIPConfigsResponse{}, nil
+}
+
+// GetEndpoint retrieves the state of an endpoint managed by CNS.
+func (s *CNS) GetEndpoint(_ context.Context, req *pb.GetEndpointRequest) (*pb.GetEndpointResponse, error) {
+	s.Logger.Info("GetEndpoint called", zap.String("endpointID", req.GetEndpointID()))
+	endpointInfo, code, err := s.State.GetEndpointState(req.GetEndpointID())
+	if err != nil {
+		return nil, ResponseError(code, err.Error())
+	}
+	return &pb.GetEndpointResponse{
+		PodName:       endpointInfo.PodName,
+		PodNamespace:  endpointInfo.PodNamespace,
+		IfnameToIPMap: IPInfoToPB(endpointInfo.IfnameToIPMap),
+	}, nil
+}
+
+// UpdateEndpoint updates the interfaces of an endpoint managed by CNS.
+func (s *CNS) UpdateEndpoint(_ context.Context, req *pb.UpdateEndpointRequest) (*pb.UpdateEndpointResponse, error) {
+	s.Logger.Info("UpdateEndpoint called", zap.String("endpointID", req.GetEndpointID()))
+	ipInfo, err := IPInfoFromPB(req.GetIfnameToIPMap())
+	if err != nil {
+		return nil, ResponseError(types.InvalidRequest, err.Error())
+	}
+	if code, err := s.State.UpdateEndpointState(req.GetEndpointID(), ipInfo); err != nil {
+		return nil, ResponseError(code, err.Error())
+	}
+	return &pb.UpdateEndpointResponse{}, nil
+}
+
+// Watch streams the changes to the IP pool, network container and endpoint state of CNS until the client goes away.
+func (s *CNS) Watch(req *pb.WatchRequest, stream pb.CNS_WatchServer) error {
+	s.Logger.Info("Watch called", zap.String("streamID", req.GetStreamID()), zap.Uint64("fromSequence", req.GetFromSequence()), zap.Bool("snapshot", req.GetSnapshot()))
+	watchReq := restserver.WatchRequest{
+		StreamID:     req.GetStreamID(),
+		FromSequence: req.GetFromSequence(),
+		Snapshot:     req.GetSnapshot(),
+	}
+	err := s.State.Watch(stream.Context(), watchReq, func(e *restserver.WatchEvent) error {
+		return stream.Send(WatchEventToPB(e)) //nolint:wrapcheck // the status must be returned as is
+	})
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, restserver.ErrWatchStreamChanged), errors.Is(err, restserver.ErrWatchSequenceExpired):
+		s.Logger.Info("Watch can not be resumed", zap.String("streamID", req.GetStreamID()), zap.Error(err))
+		return status.Error(codes.OutOfRange, err.Error()) //nolint:wrapcheck // the status must be returned as is
+	case errors.Is(err, restserver.ErrInvalidWatchSequence):
+		return status.Error(codes.InvalidArgument, err.Error()) //nolint:wrapcheck // the status must be returned as is
+	default:
+		s.Logger.Error("Watch failed", zap.String("streamID", req.GetStreamID()), zap.Error(err))
+		return err
+	}
+}
+
+// failure returns the status error of a failed IPAM call from the response of the REST helper.
+func failure(resp *cns.IPConfigsResponse, err error) error {
+	if resp == nil || resp.Response.ReturnCode == types.Success {
+		return ResponseError(types.UnexpectedError, err.Error())
+	}
+	message := resp.Response.Message
+	if message == "" {
+		message = err.Error()
+	}
+	return ResponseError(resp.Response.ReturnCode, message)
+}
@@ -0,0 +1,348 @@
+package grpc
+
+import (
+	"net"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	pb "github.com/Azure/azure-container-networking/cns/grpc/v1alpha"
+	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The conversions between the CNS types and their gRPC messages are shared by the server and cns/client.
+
+// IPConfigsRequestToPB converts an IPConfigsRequest to its gRPC message.
+func IPConfigsRequestToPB(req *cns.IPConfigsRequest) *pb.IPConfigsRequest {
+	return &pb.IPConfigsRequest{
+		DesiredIPAddresses:  req.DesiredIPAddresses,
+		PodInterfaceID:      req.PodInterfaceID,
+		InfraContainerID:    req.InfraContainerID,
+		OrchestratorContext: req.OrchestratorContext,
+		Ifname:              req.Ifname,
+	}
+}
+
+// IPConfigsRequestFromPB converts a gRPC message to an IPConfigsRequest.
+func IPConfigsRequestFromPB(req *pb.IPConfigsRequest) cns.IPConfigsRequest {
+	return cns.IPConfigsRequest{
+		DesiredIPAddresses:  req.GetDesiredIPAddresses(),
+		PodInterfaceID:      req.GetPodInterfaceID(),
+		InfraContainerID:    req.GetInfraContainerID(),
+		OrchestratorContext: req.GetOrchestratorContext(),
+		Ifname:              req.GetIfname(),
+	}
+}
+
+func ipSubnetToPB(s cns.IPSubnet) *pb.IPSubnet {
+	return &pb.IPSubnet{IpAddress: s.IPAddress, PrefixLength: uint32(s.PrefixLength)}
+}
+
+func ipSubnetFromPB(s *pb.IPSubnet) cns.IPSubnet {
+	return cns.IPSubnet{IPAddress: s.GetIpAddress(), PrefixLength: uint8(s.GetPrefixLength())}
+}
+
+// PodIPInfoToPB converts the IP configurations assigned to a pod to their gRPC messages.
+func PodIPInfoToPB(infos []cns.PodIpInfo) []*pb.PodIPInfo {
+	out := make([]*pb.PodIPInfo, 0, len(infos))
+	for i := range infos {
+		info := &infos[i]
+		routes := make([]*pb.Route, 0, len(info.Routes))
+		for _, r := range info.Routes {
+			routes = append(routes, &pb.Route{IpAddress: r.IPAddress, GatewayIPAddress: r.GatewayIPAddress, InterfaceToUse: r.InterfaceToUse})
+		}
+		out = append(out, &pb.PodIPInfo{
+			PodIPConfig: ipSubnetToPB(info.PodIPConfig),
+			NetworkContainerPrimaryIPConfig: &pb.IPConfiguration{
+				IpSubnet:         ipSubnetToPB(info.NetworkContainerPrimaryIPConfig.IPSubnet),
+				DnsServers:       info.NetworkContainerPrimaryIPConfig.DNSServers,
+				GatewayIPAddress: info.NetworkContainerPrimaryIPConfig.GatewayIPAddress,
+			},
+			HostPrimaryIPInfo: &pb.HostIPInfo{
+				Gateway:   info.HostPrimaryIPInfo.Gateway,
+				PrimaryIP: info.HostPrimaryIPInfo.PrimaryIP,
+				Subnet:    info.HostPrimaryIPInfo.Subnet,
+			},
+			NicType:           string(info.NICType),
+			InterfaceName:     info.InterfaceName,
+			MacAddress:        info.MacAddress,
+			SkipDefaultRoutes: info.SkipDefaultRoutes,
+			Routes:            routes,
+		})
+	}
+	return out
+}
+
+// PodIPInfoFromPB converts gRPC messages to the IP configurations assigned to a pod.
+func PodIPInfoFromPB(infos []*pb.PodIPInfo) []cns.PodIpInfo {
+	out := make([]cns.PodIpInfo, 0, len(infos))
+	for _, info := range infos {
+		var routes []cns.Route
+		for _, r := range info.GetRoutes() {
+			routes = append(routes, cns.Route{IPAddress: r.GetIpAddress(), GatewayIPAddress: r.GetGatewayIPAddress(), InterfaceToUse: r.GetInterfaceToUse()})
+		}
+		ncConfig := info.GetNetworkContainerPrimaryIPConfig()
+		hostInfo := info.GetHostPrimaryIPInfo()
+		out = append(out, cns.PodIpInfo{
+			PodIPConfig: ipSubnetFromPB(info.GetPodIPConfig()),
+			NetworkContainerPrimaryIPConfig: cns.IPConfiguration{
+				IPSubnet:         ipSubnetFromPB(ncConfig.GetIpSubnet()),
+				DNSServers:       ncConfig.GetDnsServers(),
+				GatewayIPAddress: ncConfig.GetGatewayIPAddress(),
+			},
+			HostPrimaryIPInfo: cns.HostIPInfo{
+				Gateway:   hostInfo.GetGateway(),
+				PrimaryIP: hostInfo.GetPrimaryIP(),
+				Subnet:    hostInfo.GetSubnet(),
+			},
+			NICType:           cns.NICType(info.GetNicType()),
+			InterfaceName:     info.GetInterfaceName(),
+			MacAddress:        info.GetMacAddress(),
+			SkipDefaultRoutes: info.GetSkipDefaultRoutes(),
+			Routes:            routes,
+		})
+	}
+	return out
+}
+
+func ipNetsToPB(ipNets []net.IPNet) []string {
+	out := make([]string, 0, len(ipNets))
+	for i := range ipNets {
+		out = append(out, ipNets[i].String())
+	}
+	return out
+}
+
+func ipNetsFromPB(cidrs []string) ([]net.IPNet, error) {
+	var out []net.IPNet
+	for _, cidr := range cidrs {
+		ip, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ip address %s", cidr)
+		}
+		out = append(out, net.IPNet{IP: ip, Mask: ipNet.Mask})
+	}
+	return out, nil
+}
+
+// IPInfoToPB converts the state of the interfaces of an endpoint to their gRPC messages.
+func IPInfoToPB(ipInfos map[string]*restserver.IPInfo) map[string]*pb.IPInfo {
+	out := make(map[string]*pb.IPInfo, len(ipInfos))
+	for ifName, info := range ipInfos {
+		if info == nil {
+			continue
+		}
+		out[ifName] = &pb.IPInfo{
+			Ipv4:          ipNetsToPB(info.IPv4),
+			Ipv6:          ipNetsToPB(info.IPv6),
+			HnsEndpointID: info.HnsEndpointID,
+			HnsNetworkID:  info.HnsNetworkID,
+			HostVethName:  info.HostVethName,
+			MacAddress:    info.MacAddress,
+			NicType:       string(info.NICType),
+		}
+	}
+	return out
+}
+
+// IPInfoFromPB converts gRPC messages to the state of the interfaces of an endpoint.
+func IPInfoFromPB(ipInfos map[string]*pb.IPInfo) (map[string]*restserver.IPInfo, error) {
+	out := make(map[string]*restserver.IPInfo, len(ipInfos))
+	for ifName, info := range ipInfos {
+		ipv4, err := ipNetsFromPB(info.GetIpv4())
+		if err != nil {
+			return nil, err
+		}
+		ipv6, err := ipNetsFromPB(info.GetIpv6())
+		if err != nil {
+			return nil, err
+		}
+		out[ifName] = &restserver.IPInfo{
+			IPv4:          ipv4,
+			IPv6:          ipv6,
+			HnsEndpointID: info.GetHnsEndpointID(),
+			HnsNetworkID:  info.GetHnsNetworkID(),
+			HostVethName:  info.GetHostVethName(),
+			MacAddress:    info.GetMacAddress(),
+			NICType:       cns.NICType(info.GetNicType()),
+		}
+	}
+	return out, nil
+}
+
+var watchEventTypes = map[restserver.WatchEventType]pb.WatchEventType{
+	restserver.IPConfigUpdated:         pb.WatchEventType_IP_CONFIG_UPDATED,
+	restserver.IPConfigDeleted:         pb.WatchEventType_IP_CONFIG_DELETED,
+	restserver.NetworkContainerUpdated: pb.WatchEventType_NETWORK_CONTAINER_UPDATED,
+	restserver.NetworkContainerDeleted: pb.WatchEventType_NETWORK_CONTAINER_DELETED,
+	restserver.EndpointUpdated:         pb.WatchEventType_ENDPOINT_UPDATED,
+	restserver.EndpointDeleted:         pb.WatchEventType_ENDPOINT_DELETED,
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// WatchEventToPB converts a change to the state of CNS to its gRPC message.
+func WatchEventToPB(e *restserver.WatchEvent) *pb.WatchEvent {
+	out := &pb.WatchEvent{
+		StreamID:  e.StreamID,
+		Sequence:  e.Sequence,
+		Timestamp: unixNano(e.Time),
+		Type:      watchEventTypes[e.Type],
+		Snapshot:  e.Snapshot,
+	}
+	if ip := e.IPConfig; ip != nil {
+		out.IpConfig = &pb.IPConfigurationStatus{
+			Id:                  ip.ID,
+			NcID:                ip.NCID,
+			IpAddress:           ip.IPAddress,
+			State:               string(ip.GetState()),
+			LastStateTransition: unixNano(ip.LastStateTransition),
+		}
+		if ip.PodInfo != nil {
+			out.IpConfig.PodName = ip.PodInfo.Name()
+			out.IpConfig.PodNamespace = ip.PodInfo.Namespace()
+			out.IpConfig.InfraContainerID = ip.PodInfo.InfraContainerID()
+			out.IpConfig.PodInterfaceID = ip.PodInfo.InterfaceID()
+		}
+	}
+	if nc := e.NetworkContainer; nc != nil {
+		out.NetworkContainer = &pb.NetworkContainer{
+			Id:          nc.ID,
+			Type:        nc.Type,
+			Version:     nc.Version,
+			HostVersion: nc.HostVersion,
+			IpConfiguration: &pb.IPConfiguration{
+				IpSubnet:         ipSubnetToPB(nc.IPConfiguration.IPSubnet),
+				DnsServers:       nc.IPConfiguration.DNSServers,
+				GatewayIPAddress: nc.IPConfiguration.GatewayIPAddress,
+			},
+		}
+	}
+	if e.EndpointID != "" {
+		out.Endpoint = &pb.Endpoint{EndpointID: e.EndpointID}
+		if e.Endpoint != nil {
+			out.Endpoint.PodName = e.Endpoint.PodName
+			out.Endpoint.PodNamespace = e.Endpoint.PodNamespace
+			out.Endpoint.IfnameToIPMap = IPInfoToPB(e.Endpoint.IfnameToIPMap)
+		}
+	}
+	return out
+}
+
+// WatchEventFromPB converts a gRPC message to a change to the state of CNS.
+func WatchEventFromPB(e *pb.WatchEvent) (*restserver.WatchEvent, error) {
+	out := &restserver.WatchEvent{
+		StreamID: e.GetStreamID(),
+		Sequence: e.GetSequence(),
+		Time:     fromUnixNano(e.GetTimestamp()),
+		Snapshot: e.GetSnapshot(),
+	}
+	for t, pbType := range watchEventTypes {
+		if pbType == e.GetType() {
+			out.Type = t
+		}
+	}
+	if ip := e.GetIpConfig(); ip != nil {
+		out.IPConfig = &cns.IPConfigurationStatus{
+			ID:        ip.GetId(),
+			NCID:      ip.GetNcID(),
+			IPAddress: ip.GetIpAddress(),
+		}
+		if ip.GetPodName() != "" || ip.GetInfraContainerID() != "" {
+			out.IPConfig.PodInfo = cns.NewPodInfo(ip.GetInfraContainerID(), ip.GetPodInterfaceID(), ip.GetPodName(), ip.GetPodNamespace())
+		}
+		out.IPConfig.SetState(types.IPState(ip.GetState()))
+		out.IPConfig.LastStateTransition = fromUnixNano(ip.GetLastStateTransition())
+	}
+	if nc := e.GetNetworkContainer(); nc != nil {
+		ipConfig := nc.GetIpConfiguration()
+		out.NetworkContainer = &restserver.WatchNetworkContainer{
+			ID:          nc.GetId(),
+			Type:        nc.GetType(),
+			Version:     nc.GetVersion(),
+			HostVersion: nc.GetHostVersion(),
+			IPConfiguration: cns.IPConfiguration{
+				IPSubnet:         ipSubnetFromPB(ipConfig.GetIpSubnet()),
+				DNSServers:       ipConfig.GetDnsServers(),
+				GatewayIPAddress: ipConfig.GetGatewayIPAddress(),
+			},
+		}
+	}
+	if ep := e.GetEndpoint(); ep != nil {
+		out.EndpointID = ep.GetEndpointID()
+		if out.Type != restserver.EndpointDeleted {
+			ipInfo, err := IPInfoFromPB(ep.GetIfnameToIPMap())
+			if err != nil {
+				return nil, err
+			}
+			out.Endpoint = &restserver.EndpointInfo{
+				PodName:       ep.GetPodName(),
+				PodNamespace:  ep.GetPodNamespace(),
+				IfnameToIPMap: ipInfo,
+			}
+		}
+	}
+	return out, nil
+}
+
+// statusCode maps a CNS response code to the closest gRPC status code.
+func statusCode(code types.ResponseCode) codes.Code {
+	switch code {
+	case types.Success:
+		return codes.OK
+	case types.InvalidParameter, types.InvalidRequest, types.EmptyOrchestratorContext, types.UnsupportedOrchestratorContext,
+		types.UnsupportedOrchestratorType, types.UnsupportedNetworkContainerType, types.MalformedSubnet:
+		return codes.InvalidArgument
+	case types.NotFound, types.UnknownContainerID, types.ReservationNotFound:
+		return codes.NotFound
+	case types.FailedToAllocateIPConfig, types.AddressUnavailable:
+		return codes.ResourceExhausted
+	case types.UnsupportedAPI:
+		return codes.Unimplemented
+	default:
+		return codes.Internal
+	}
+}
+
+// ResponseError returns the status error of a failed call, carrying the CNS response code in an ErrorDetail.
+func ResponseError(code types.ResponseCode, message string) error {
+	st := status.New(statusCode(code), message)
+	if withDetail, err := st.WithDetails(&pb.ErrorDetail{ReturnCode: int32(code), Message: message}); err == nil {
+		st = withDetail
+	}
+	return st.Err() //nolint:wrapcheck // the status must be returned as is
+}
+
+// ResponseCode returns the CNS response code and message carried by the status error of a failed call.
+// Errors without an ErrorDetail, e.g. transport errors, map to UnsupportedAPI if the server does not
+// implement the method and to UnexpectedError otherwise.
+func ResponseCode(err error) (types.ResponseCode, string) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return types.UnexpectedError, err.Error()
+	}
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*pb.ErrorDetail); ok {
+			return types.ResponseCode(d.GetReturnCode()), d.GetMessage()
+		}
+	}
+	if st.Code() == codes.Unimplemented {
+		return types.UnsupportedAPI, st.Message()
+	}
+	return types.UnexpectedError, st.Message()
+}
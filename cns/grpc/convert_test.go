@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIPConfigsRequestConversion(t *testing.T) {
+	req := cns.IPConfigsRequest{
+		DesiredIPAddresses:  []string{"10.0.0.4"},
+		PodInterfaceID:      "abcdef-eth0",
+		InfraContainerID:    "abcdef",
+		OrchestratorContext: []byte(`{"PodName":"pod","PodNamespace":"ns"}`),
+		Ifname:              "eth0",
+	}
+	require.Equal(t, req, IPConfigsRequestFromPB(IPConfigsRequestToPB(&req)))
+}
+
+func TestPodIPInfoConversion(t *testing.T) {
+	infos := []cns.PodIpInfo{
+		{
+			PodIPConfig: cns.IPSubnet{IPAddress: "10.0.0.4", PrefixLength: 24},
+			NetworkContainerPrimaryIPConfig: cns.IPConfiguration{
+				IPSubnet:         cns.IPSubnet{IPAddress: "10.0.0.0", PrefixLength: 24},
+				DNSServers:       []string{"168.63.129.16"},
+				GatewayIPAddress: "10.0.0.1",
+			},
+			HostPrimaryIPInfo: cns.HostIPInfo{Gateway: "10.224.0.1", PrimaryIP: "10.224.0.4", Subnet: "10.224.0.0/16"},
+			NICType:           cns.InfraNIC,
+			InterfaceName:     "eth0",
+			MacAddress:        "00:0d:3a:00:00:01",
+			SkipDefaultRoutes: true,
+			Routes:            []cns.Route{{IPAddress: "10.1.0.0/16", GatewayIPAddress: "10.0.0.1", InterfaceToUse: "eth0"}},
+		},
+		{
+			PodIPConfig: cns.IPSubnet{IPAddress: "fd00::4", PrefixLength: 64},
+			NICType:     cns.InfraNIC,
+		},
+	}
+	require.Equal(t, infos, PodIPInfoFromPB(PodIPInfoToPB(infos)))
+}
+
+func TestIPInfoConversion(t *testing.T) {
+	ipv4, ipv4Net, _ := net.ParseCIDR("10.0.0.4/24")
+	ipv6, ipv6Net, _ := net.ParseCIDR("fd00::4/64")
+	ipInfo := map[string]*restserver.IPInfo{
+		"eth0": {
+			IPv4:         []net.IPNet{{IP: ipv4, Mask: ipv4Net.Mask}},
+			IPv6:         []net.IPNet{{IP: ipv6, Mask: ipv6Net.Mask}},
+			HostVethName: "azv1234",
+			NICType:      cns.InfraNIC,
+		},
+		"eth1": {
+			MacAddress: "00:0d:3a:00:00:01",
+			NICType:    cns.DelegatedVMNIC,
+		},
+	}
+
+	got, err := IPInfoFromPB(IPInfoToPB(ipInfo))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "10.0.0.4/24", got["eth0"].IPv4[0].String())
+	require.Equal(t, "fd00::4/64", got["eth0"].IPv6[0].String())
+	require.Equal(t, ipInfo["eth0"].HostVethName, got["eth0"].HostVethName)
+	require.Equal(t, ipInfo["eth1"].MacAddress, got["eth1"].MacAddress)
+	require.Equal(t, cns.DelegatedVMNIC, got["eth1"].NICType)
+
+	pbInfo := IPInfoToPB(ipInfo)
+	pbInfo["eth0"].Ipv4 = []string{"10.0.0.4"}
+	_, err = IPInfoFromPB(pbInfo)
+	require.Error(t, err)
+}
+
+func TestWatchEventConversion(t *testing.T) {
+	ipConfig := &cns.IPConfigurationStatus{
+		ID:        "id",
+		NCID:      "nc",
+		IPAddress: "10.0.0.4",
+		PodInfo:   cns.NewPodInfo("abcdef", "abcdef-eth0", "pod", "ns"),
+	}
+	ipConfig.SetState(types.Assigned)
+	_, ipNet, _ := net.ParseCIDR("10.0.0.4/24")
+	now := time.Unix(0, time.Now().UnixNano())
+
+	events := []*restserver.WatchEvent{
+		{StreamID: "stream", Sequence: 1, Time: now, Type: restserver.IPConfigUpdated, IPConfig: ipConfig},
+		{StreamID: "stream", Sequence: 2, Time: now, Type: restserver.NetworkContainerUpdated, Snapshot: true, NetworkContainer: &restserver.WatchNetworkContainer{
+			ID:              "nc",
+			Type:            "Docker",
+			Version:         "1",
+			HostVersion:     "0",
+			IPConfiguration: cns.IPConfiguration{IPSubnet: cns.IPSubnet{IPAddress: "10.0.0.0", PrefixLength: 24}, GatewayIPAddress: "10.0.0.1"},
+		}},
+		{StreamID: "stream", Sequence: 3, Time: now, Type: restserver.EndpointUpdated, EndpointID: "abcdef", Endpoint: &restserver.EndpointInfo{
+			PodName:       "pod",
+			PodNamespace:  "ns",
+			IfnameToIPMap: map[string]*restserver.IPInfo{"eth0": {IPv4: []net.IPNet{*ipNet}, NICType: cns.InfraNIC}},
+		}},
+		{StreamID: "stream", Sequence: 4, Time: now, Type: restserver.EndpointDeleted, EndpointID: "abcdef"},
+	}
+
+	for _, e := range events {
+		got, err := WatchEventFromPB(WatchEventToPB(e))
+		require.NoError(t, err)
+		require.Equal(t, e.StreamID, got.StreamID)
+		require.Equal(t, e.Sequence, got.Sequence)
+		require.True(t, e.Time.Equal(got.Time))
+		require.Equal(t, e.Type, got.Type)
+		require.Equal(t, e.Snapshot, got.Snapshot)
+		require.Equal(t, e.NetworkContainer, got.NetworkContainer)
+		require.Equal(t, e.EndpointID, got.EndpointID)
+		if e.Endpoint != nil {
+			require.Equal(t, "10.0.0.0/24", got.Endpoint.IfnameToIPMap["eth0"].IPv4[0].String())
+		} else {
+			require.Nil(t, got.Endpoint)
+		}
+		if e.IPConfig != nil {
+			require.Equal(t, e.IPConfig.ID, got.IPConfig.ID)
+			require.Equal(t, types.Assigned, got.IPConfig.GetState())
+			require.True(t, e.IPConfig.LastStateTransition.Equal(got.IPConfig.LastStateTransition))
+			require.Equal(t, e.IPConfig.PodInfo.Key(), got.IPConfig.PodInfo.Key())
+			require.Equal(t, e.IPConfig.PodInfo.InfraContainerID(), got.IPConfig.PodInfo.InfraContainerID())
+		}
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       types.ResponseCode
+		statusCode codes.Code
+	}{
+		{name: "invalid request", code: types.InvalidRequest, statusCode: codes.InvalidArgument},
+		{name: "empty orchestrator context", code: types.EmptyOrchestratorContext, statusCode: codes.InvalidArgument},
+		{name: "not found", code: types.NotFound, statusCode: codes.NotFound},
+		{name: "failed to allocate", code: types.FailedToAllocateIPConfig, statusCode: codes.ResourceExhausted},
+		{name: "unexpected", code: types.UnexpectedError, statusCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResponseError(tt.code, "message")
+			require.Equal(t, tt.statusCode, status.Code(err))
+			code, message := ResponseCode(err)
+			require.Equal(t, tt.code, code)
+			require.Equal(t, "message", message)
+		})
+	}
+
+	code, _ := ResponseCode(status.Error(codes.Unimplemented, "unknown method"))
+	require.Equal(t, types.UnsupportedAPI, code)
+	code, _ = ResponseCode(status.Error(codes.Unavailable, "connection refused"))
+	require.Equal(t, types.UnexpectedError, code)
+	code, _ = ResponseCode(errors.New("not a status"))
+	require.Equal(t, types.UnexpectedError, code)
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	pb "github.com/Azure/azure-container-networking/cns/grpc/v1alpha"
+	"github.com/Azure/azure-container-networking/tracing"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -52,7 +53,7 @@ func (s *Server) Start() error {
 	}
 	log.Printf("[Listener] Started listening on gRPC endpoint %s.", address)
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(tracing.GRPCServerOption())
 	pb.RegisterCNSServer(grpcServer, s.CnsService)
 
 	// Register reflection service on gRPC server.
@@ -21,6 +21,11 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	CNS_SetOrchestratorInfo_FullMethodName = "/cns.CNS/SetOrchestratorInfo"
 	CNS_GetNodeInfo_FullMethodName         = "/cns.CNS/GetNodeInfo"
+	CNS_RequestIPConfigs_FullMethodName    = "/cns.CNS/RequestIPConfigs"
+	CNS_ReleaseIPConfigs_FullMethodName    = "/cns.CNS/ReleaseIPConfigs"
+	CNS_GetEndpoint_FullMethodName         = "/cns.CNS/GetEndpoint"
+	CNS_UpdateEndpoint_FullMethodName      = "/cns.CNS/UpdateEndpoint"
+	CNS_Watch_FullMethodName               = "/cns.CNS/Watch"
 )
 
 // CNSClient is the client API for CNS service.
@@ -32,6 +37,19 @@ type CNSClient interface {
 	// Retrieves detailed information about a specific node.
 	// Primarily used for health checks.
 	GetNodeInfo(ctx context.Context, in *NodeInfoRequest, opts ...grpc.CallOption) (*NodeInfoResponse, error)
+	// Assigns IP configurations to a pod interface.
+	// Equivalent to the requestipconfigs REST API.
+	RequestIPConfigs(ctx context.Context, in *IPConfigsRequest, opts ...grpc.CallOption) (*IPConfigsResponse, error)
+	// Releases the IP configurations assigned to a pod interface.
+	// Equivalent to the releaseipconfigs REST API.
+	ReleaseIPConfigs(ctx context.Context, in *IPConfigsRequest, opts ...grpc.CallOption) (*ReleaseIPConfigsResponse, error)
+	// Retrieves the state of an endpoint managed by CNS.
+	GetEndpoint(ctx context.Context, in *GetEndpointRequest, opts ...grpc.CallOption) (*GetEndpointResponse, error)
+	// Updates the interfaces of an endpoint managed by CNS.
+	UpdateEndpoint(ctx context.Context, in *UpdateEndpointRequest, opts ...grpc.CallOption) (*UpdateEndpointResponse, error)
+	// Streams the changes to the IP pool, network container and endpoint state of CNS.
+	// A watch is resumed from the stream ID and sequence number of the last event received.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (CNS_WatchClient, error)
 }
 
 type cNSClient struct {
@@ -60,6 +78,74 @@ func (c *cNSClient) GetNodeInfo(ctx context.Context, in *NodeInfoRequest, opts .
 	return out, nil
 }
 
+func (c *cNSClient) RequestIPConfigs(ctx context.Context, in *IPConfigsRequest, opts ...grpc.CallOption) (*IPConfigsResponse, error) {
+	out := new(IPConfigsResponse)
+	err := c.cc.Invoke(ctx, CNS_RequestIPConfigs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) ReleaseIPConfigs(ctx context.Context, in *IPConfigsRequest, opts ...grpc.CallOption) (*ReleaseIPConfigsResponse, error) {
+	out := new(ReleaseIPConfigsResponse)
+	err := c.cc.Invoke(ctx, CNS_ReleaseIPConfigs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) GetEndpoint(ctx context.Context, in *GetEndpointRequest, opts ...grpc.CallOption) (*GetEndpointResponse, error) {
+	out := new(GetEndpointResponse)
+	err := c.cc.Invoke(ctx, CNS_GetEndpoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) UpdateEndpoint(ctx context.Context, in *UpdateEndpointRequest, opts ...grpc.CallOption) (*UpdateEndpointResponse, error) {
+	out := new(UpdateEndpointResponse)
+	err := c.cc.Invoke(ctx, CNS_UpdateEndpoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNSClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (CNS_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CNS_ServiceDesc.Streams[0], CNS_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cNSWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CNS_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type cNSWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *cNSWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CNSServer is the server API for CNS service.
 // All implementations must embed UnimplementedCNSServer
 // for forward compatibility
@@ -69,6 +155,19 @@ type CNSServer interface {
 	// Retrieves detailed information about a specific node.
 	// Primarily used for health checks.
 	GetNodeInfo(context.Context, *NodeInfoRequest) (*NodeInfoResponse, error)
+	// Assigns IP configurations to a pod interface.
+	// Equivalent to the requestipconfigs REST API.
+	RequestIPConfigs(context.Context, *IPConfigsRequest) (*IPConfigsResponse, error)
+	// Releases the IP configurations assigned to a pod interface.
+	// Equivalent to the releaseipconfigs REST API.
+	ReleaseIPConfigs(context.Context, *IPConfigsRequest) (*ReleaseIPConfigsResponse, error)
+	// Retrieves the state of an endpoint managed by CNS.
+	GetEndpoint(context.Context, *GetEndpointRequest) (*GetEndpointResponse, error)
+	// Updates the interfaces of an endpoint managed by CNS.
+	UpdateEndpoint(context.Context, *UpdateEndpointRequest) (*UpdateEndpointResponse, error)
+	// Streams the changes to the IP pool, network container and endpoint state of CNS.
+	// A watch is resumed from the stream ID and sequence number of the last event received.
+	Watch(*WatchRequest, CNS_WatchServer) error
 	mustEmbedUnimplementedCNSServer()
 }
 
@@ -82,6 +181,21 @@ func (UnimplementedCNSServer) SetOrchestratorInfo(context.Context, *SetOrchestra
 func (UnimplementedCNSServer) GetNodeInfo(context.Context, *NodeInfoRequest) (*NodeInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNodeInfo not implemented")
 }
+func (UnimplementedCNSServer) RequestIPConfigs(context.Context, *IPConfigsRequest) (*IPConfigsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestIPConfigs not implemented")
+}
+func (UnimplementedCNSServer) ReleaseIPConfigs(context.Context, *IPConfigsRequest) (*ReleaseIPConfigsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseIPConfigs not implemented")
+}
+func (UnimplementedCNSServer) GetEndpoint(context.Context, *GetEndpointRequest) (*GetEndpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEndpoint not implemented")
+}
+func (UnimplementedCNSServer) UpdateEndpoint(context.Context, *UpdateEndpointRequest) (*UpdateEndpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateEndpoint not implemented")
+}
+func (UnimplementedCNSServer) Watch(*WatchRequest, CNS_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedCNSServer) mustEmbedUnimplementedCNSServer() {}
 
 // UnsafeCNSServer may be embedded to opt out of forward compatibility for this service.
@@ -131,6 +245,99 @@ func _CNS_GetNodeInfo_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CNS_RequestIPConfigs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IPConfigsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).RequestIPConfigs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CNS_RequestIPConfigs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).RequestIPConfigs(ctx, req.(*IPConfigsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_ReleaseIPConfigs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IPConfigsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).ReleaseIPConfigs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CNS_ReleaseIPConfigs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).ReleaseIPConfigs(ctx, req.(*IPConfigsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_GetEndpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEndpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).GetEndpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CNS_GetEndpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).GetEndpoint(ctx, req.(*GetEndpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_UpdateEndpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEndpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNSServer).UpdateEndpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CNS_UpdateEndpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNSServer).UpdateEndpoint(ctx, req.(*UpdateEndpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNS_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CNSServer).Watch(m, &cNSWatchServer{stream})
+}
+
+type CNS_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type cNSWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *cNSWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // CNS_ServiceDesc is the grpc.ServiceDesc for CNS service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -146,7 +353,29 @@ var CNS_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetNodeInfo",
 			Handler:    _CNS_GetNodeInfo_Handler,
 		},
+		{
+			MethodName: "RequestIPConfigs",
+			Handler:    _CNS_RequestIPConfigs_Handler,
+		},
+		{
+			MethodName: "ReleaseIPConfigs",
+			Handler:    _CNS_ReleaseIPConfigs_Handler,
+		},
+		{
+			MethodName: "GetEndpoint",
+			Handler:    _CNS_GetEndpoint_Handler,
+		},
+		{
+			MethodName: "UpdateEndpoint",
+			Handler:    _CNS_UpdateEndpoint_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _CNS_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "cns/grpc/proto/server.proto",
 }
@@ -1,15 +1,24 @@
 package healthserver
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
-func Start(log *zap.Logger, addr string, healthz, readyz http.Handler) {
+// shutdownTimeout bounds how long the requests in flight on the previous address are drained when the server is
+// moved to a new address.
+const shutdownTimeout = 5 * time.Second
+
+func newHandler(healthz, readyz http.Handler) http.Handler {
 	e := echo.New()
 	e.HideBanner = true
 	e.GET("/healthz", echo.WrapHandler(http.StripPrefix("/healthz", healthz)))
@@ -17,7 +26,67 @@ func Start(log *zap.Logger, addr string, healthz, readyz http.Handler) {
 	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
 		ErrorHandling: promhttp.HTTPErrorOnError,
 	})))
-	if err := e.Start(addr); err != nil {
-		log.Error("failed to run healthserver", zap.Error(err))
+	return e
+}
+
+// Server serves the healthz, readyz and metrics endpoints, and can be moved to a new address while running.
+type Server struct {
+	log     *zap.Logger
+	handler http.Handler
+
+	mu   sync.Mutex
+	srv  *http.Server
+	addr string
+}
+
+// NewServer returns a Server for the healthz and readyz handlers. It does not listen until Listen is called.
+func NewServer(log *zap.Logger, healthz, readyz http.Handler) *Server {
+	return &Server{
+		log:     log,
+		handler: newHandler(healthz, readyz),
+	}
+}
+
+// Listen starts serving on addr. If the Server is already serving on another address, that one is shut down once
+// the new address is bound; if the new address can't be bound the Server keeps serving on the previous one.
+func (s *Server) Listen(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.srv != nil && s.addr == addr {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", addr)
+	}
+	srv := &http.Server{Handler: s.handler} //nolint:gosec // no timeouts, as before
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("failed to run healthserver", zap.String("addr", addr), zap.Error(err))
+		}
+	}()
+
+	prev := s.srv
+	s.srv, s.addr = srv, addr
+	if prev != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := prev.Shutdown(ctx); err != nil {
+			s.log.Error("failed to shut down healthserver on previous address", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Shutdown stops serving.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.srv == nil {
+		return nil
 	}
+	err := s.srv.Shutdown(ctx)
+	s.srv, s.addr = nil, ""
+	return errors.Wrap(err, "failed to shut down healthserver")
 }
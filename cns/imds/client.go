@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/Azure/azure-container-networking/tracing"
 	"github.com/avast/retry-go/v4"
 	"github.com/pkg/errors"
 )
@@ -71,7 +72,7 @@ func NewClient(opts ...ClientOption) *Client {
 	}
 
 	return &Client{
-		cli:    &http.Client{},
+		cli:    &http.Client{Transport: tracing.NewTransport(nil)},
 		config: config,
 	}
 }
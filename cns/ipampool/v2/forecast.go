@@ -0,0 +1,113 @@
+package v2
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// PredictiveOptions configures a PredictiveForecaster.
+type PredictiveOptions struct {
+	// Window is how far back the demand is tracked to measure its growth rate.
+	Window time.Duration
+	// Horizon is how far ahead the growth of the demand is provisioned for, roughly the time it
+	// takes for the NodeNetworkConfig to be updated with a new request.
+	Horizon time.Duration
+	// DecayHalfLife is how fast the forecast decays back to the demand once it stops growing.
+	DecayHalfLife time.Duration
+}
+
+type sample struct {
+	at     time.Time
+	demand int64
+}
+
+// PredictiveForecaster forecasts the demand from its growth rate over a sliding window, so that the pool
+// is scaled up ahead of bursts instead of one NodeNetworkConfig round trip at a time. Once the demand stops
+// growing the forecast holds and decays exponentially back down to it, which keeps the pool from
+// oscillating when the demand is spiky.
+type PredictiveForecaster struct {
+	opts    PredictiveOptions
+	mu      sync.Mutex
+	samples []sample
+	held    float64
+	heldAt  time.Time
+}
+
+// NewPredictiveForecaster returns a PredictiveForecaster with the given options.
+func NewPredictiveForecaster(opts PredictiveOptions) *PredictiveForecaster {
+	return &PredictiveForecaster{opts: opts}
+}
+
+// Observe records the demand at the given time.
+func (f *PredictiveForecaster) Observe(now time.Time, demand int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.samples = append(f.samples, sample{at: now, demand: demand})
+	f.prune(now)
+}
+
+// prune drops the samples which are out of the window at the given time, but always keeps the latest one.
+func (f *PredictiveForecaster) prune(now time.Time) {
+	cutoff := now.Add(-f.opts.Window)
+	i := 0
+	for i < len(f.samples)-1 && f.samples[i].at.Before(cutoff) {
+		i++
+	}
+	f.samples = f.samples[i:]
+}
+
+// Forecast returns the demand to size the pool for at the given time, which is never less than the
+// latest demand observed. The demand is not observed again while it does not change, so the growth
+// rate is measured over the samples still in the window at that time.
+func (f *PredictiveForecaster) Forecast(now time.Time) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.samples) == 0 {
+		return 0
+	}
+	f.prune(now)
+	current := float64(f.samples[len(f.samples)-1].demand)
+	rate := growthRate(f.samples)
+	projected := current + rate*f.opts.Horizon.Seconds()
+
+	decayed := current
+	if f.held > current && f.opts.DecayHalfLife > 0 {
+		elapsed := now.Sub(f.heldAt).Seconds()
+		decayed = current + (f.held-current)*math.Pow(0.5, elapsed/f.opts.DecayHalfLife.Seconds()) //nolint:gomnd // half life
+	}
+	f.held = math.Max(projected, decayed)
+	if f.held-current < 1 {
+		// the decay never quite reaches the demand, stop holding less than an IP over it.
+		f.held = current
+	}
+	f.heldAt = now
+
+	forecastDemand.Set(f.held)
+	forecastGrowthRate.Set(rate)
+	return int64(math.Ceil(f.held))
+}
+
+// growthRate returns the slope of the least squares fit of the demand over time, in IPs per second.
+// Only growth is forecast, a shrinking demand is left to the decay.
+func growthRate(samples []sample) float64 {
+	if len(samples) < 2 { //nolint:gomnd // a line needs two points
+		return 0
+	}
+	origin := samples[0].at
+	var sumT, sumD, sumTT, sumTD float64
+	for _, s := range samples {
+		t := s.at.Sub(origin).Seconds()
+		d := float64(s.demand)
+		sumT += t
+		sumD += d
+		sumTT += t * t
+		sumTD += t * d
+	}
+	n := float64(len(samples))
+	variance := n*sumTT - sumT*sumT
+	if variance == 0 {
+		return 0
+	}
+	return math.Max((n*sumTD-sumT*sumD)/variance, 0)
+}
@@ -0,0 +1,189 @@
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testPredictiveOptions = PredictiveOptions{
+	Window:        time.Minute,
+	Horizon:       30 * time.Second,
+	DecayHalfLife: time.Minute,
+}
+
+// trace replays a synthetic demand trace, sampled every step, and returns the forecast after each sample.
+func trace(f *PredictiveForecaster, start time.Time, step time.Duration, demand ...int64) []int64 {
+	forecasts := make([]int64, len(demand))
+	for i, d := range demand {
+		now := start.Add(time.Duration(i) * step)
+		f.Observe(now, d)
+		forecasts[i] = f.Forecast(now)
+	}
+	return forecasts
+}
+
+func TestForecastSteadyDemand(t *testing.T) {
+	f := NewPredictiveForecaster(testPredictiveOptions)
+	assert.Equal(t, int64(0), f.Forecast(time.Now()))
+	for _, forecast := range trace(f, time.Now(), 5*time.Second, 10, 10, 10, 10, 10) {
+		assert.Equal(t, int64(10), forecast)
+	}
+}
+
+func TestForecastBurst(t *testing.T) {
+	f := NewPredictiveForecaster(testPredictiveOptions)
+	start := time.Now()
+	// a Deployment scaling from 10 to 200 replicas over a minute, +16 Pods every 5 seconds.
+	demand := []int64{10}
+	for demand[len(demand)-1] < 200 {
+		demand = append(demand, demand[len(demand)-1]+16)
+	}
+	forecasts := trace(f, start, 5*time.Second, demand...)
+
+	for i := 1; i < len(demand); i++ {
+		// the growth rate is 3.2 IPs per second, so the forecast is 96 IPs ahead of the demand.
+		assert.Equal(t, demand[i]+96, forecasts[i], "sample %d", i)
+	}
+
+	// once the burst is over, the forecast holds and decays back down to the demand.
+	end := start.Add(time.Duration(len(demand)-1) * 5 * time.Second)
+	peak := forecasts[len(forecasts)-1]
+	last := demand[len(demand)-1]
+	var previous int64 = peak
+	for i := 1; i <= 20; i++ {
+		now := end.Add(time.Duration(i) * 30 * time.Second)
+		f.Observe(now, last)
+		forecast := f.Forecast(now)
+		assert.LessOrEqual(t, forecast, previous)
+		assert.GreaterOrEqual(t, forecast, last)
+		previous = forecast
+	}
+	assert.Equal(t, last, previous)
+}
+
+func TestForecastBurstThenSilence(t *testing.T) {
+	f := NewPredictiveForecaster(testPredictiveOptions)
+	start := time.Now()
+	forecasts := trace(f, start, 5*time.Second, 10, 26, 42, 58, 74)
+	peak := forecasts[len(forecasts)-1]
+	require.Equal(t, int64(74+96), peak)
+
+	// the demand does not change again, so it is not observed and only the resync ticks forecast.
+	end := start.Add(4 * 5 * time.Second)
+	previous := peak
+	for i := 1; i <= 40; i++ {
+		forecast := f.Forecast(end.Add(time.Duration(i) * 15 * time.Second))
+		assert.LessOrEqual(t, forecast, previous, "tick %d", i)
+		assert.GreaterOrEqual(t, forecast, int64(74), "tick %d", i)
+		previous = forecast
+	}
+	// once the burst is out of the window the forecast decays back down to the demand.
+	assert.Equal(t, int64(74), previous)
+}
+
+func TestForecastDecay(t *testing.T) {
+	f := NewPredictiveForecaster(testPredictiveOptions)
+	start := time.Now()
+	// the growth rate is 100 IPs per second, the forecast is 3000 IPs ahead of the demand.
+	forecasts := trace(f, start, time.Second, 0, 100)
+	require.Equal(t, int64(3100), forecasts[1])
+
+	// the demand stops growing, after a half life the forecast is halfway back down to it.
+	f.Observe(start.Add(time.Minute+time.Second), 100)
+	require.Equal(t, int64(1600), f.Forecast(start.Add(time.Minute+time.Second)))
+
+	// a shrinking demand is not forecast, the forecast keeps decaying toward it.
+	f.Observe(start.Add(2*time.Minute+time.Second), 0)
+	assert.Equal(t, int64(800), f.Forecast(start.Add(2*time.Minute+time.Second)))
+}
+
+func TestGrowthRate(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name    string
+		samples []sample
+		want    float64
+	}{
+		{
+			name: "no samples",
+		},
+		{
+			name:    "single sample",
+			samples: []sample{{at: start, demand: 10}},
+		},
+		{
+			name:    "linear growth",
+			samples: []sample{{at: start, demand: 0}, {at: start.Add(time.Second), demand: 2}, {at: start.Add(2 * time.Second), demand: 4}},
+			want:    2,
+		},
+		{
+			name:    "shrinking",
+			samples: []sample{{at: start, demand: 4}, {at: start.Add(time.Second), demand: 2}},
+		},
+		{
+			name:    "same time",
+			samples: []sample{{at: start, demand: 4}, {at: start, demand: 8}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, tt.want, growthRate(tt.samples), 1e-9)
+		})
+	}
+}
+
+type forecasterMock int64
+
+func (forecasterMock) Observe(time.Time, int64) {}
+
+func (f forecasterMock) Forecast(time.Time) int64 {
+	return int64(f)
+}
+
+func TestReconcileForecast(t *testing.T) {
+	tests := []struct {
+		name        string
+		demand      int64
+		forecast    int64
+		wantRequest int64
+	}{
+		{
+			name:        "scale up ahead of demand",
+			demand:      10,
+			forecast:    100,
+			wantRequest: 112,
+		},
+		{
+			name:        "never below demand",
+			demand:      40,
+			forecast:    0,
+			wantRequest: 48,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			nnccli := &nncClientMock{}
+			pm := &Monitor{
+				z:          zap.NewNop(),
+				demand:     tt.demand,
+				request:    16,
+				scaler:     scaler{batch: 16, buffer: .5, max: 250},
+				forecaster: forecasterMock(tt.forecast),
+				nnccli:     nnccli,
+				store:      &ipStateStoreMock{},
+			}
+			require.NoError(t, pm.reconcile(context.Background()))
+			assert.Equal(t, tt.wantRequest, pm.request)
+			assert.Equal(t, tt.wantRequest, nnccli.req.RequestedIPCount)
+		})
+	}
+}
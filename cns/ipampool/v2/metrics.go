@@ -0,0 +1,35 @@
+package v2
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	observedDemand = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cx_ipam_demand_ips",
+			Help: "IPs demanded by the Pods scheduled on this CNS Node.",
+		},
+	)
+	forecastDemand = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cx_ipam_forecast_demand_ips",
+			Help: "IPs demand forecast by the predictive scaler, the pool is sized for it.",
+		},
+	)
+	forecastGrowthRate = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cx_ipam_forecast_growth_rate",
+			Help: "Growth rate of the IP demand measured by the predictive scaler, in IPs per second.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		observedDemand,
+		forecastDemand,
+		forecastGrowthRate,
+	)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/ipampool"
 	"github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1alpha1"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
 	"github.com/pkg/errors"
@@ -35,13 +37,25 @@ type scaler struct {
 	max       int64
 }
 
+// Forecaster predicts the demand to size the pool for from the history of the demand.
+// Without a Forecaster the pool is sized for the current demand.
+type Forecaster interface {
+	// Observe records the demand at the given time.
+	Observe(now time.Time, demand int64)
+	// Forecast returns the demand to size the pool for at the given time.
+	Forecast(now time.Time) int64
+}
+
 type Monitor struct {
 	z            *zap.Logger
 	scaler       scaler
+	forecaster   Forecaster
+	resync       time.Duration
 	nnccli       nodeNetworkConfigSpecUpdater
 	store        ipStateStore
 	demand       int64
 	request      int64
+	metricLabels [3]string // subnet, subnet CIDR and pod network ARM ID labels of the ipampool metrics
 	demandSource <-chan int
 	cssSource    <-chan v1alpha1.ClusterSubnetState
 	nncSource    <-chan v1alpha.NodeNetworkConfig
@@ -49,8 +63,20 @@ type Monitor struct {
 	once         sync.Once
 }
 
-func NewMonitor(z *zap.Logger, store ipStateStore, nnccli nodeNetworkConfigSpecUpdater, demandSource <-chan int, nncSource <-chan v1alpha.NodeNetworkConfig, cssSource <-chan v1alpha1.ClusterSubnetState) *Monitor { //nolint:lll // it's fine
-	return &Monitor{
+// Option configures a Monitor.
+type Option func(*Monitor)
+
+// WithForecaster sizes the pool for the demand forecast by f instead of the current demand. The pool is
+// reconciled every resync interval too, so that the forecast can decay while the demand does not change.
+func WithForecaster(f Forecaster, resync time.Duration) Option {
+	return func(pm *Monitor) {
+		pm.forecaster = f
+		pm.resync = resync
+	}
+}
+
+func NewMonitor(z *zap.Logger, store ipStateStore, nnccli nodeNetworkConfigSpecUpdater, demandSource <-chan int, nncSource <-chan v1alpha.NodeNetworkConfig, cssSource <-chan v1alpha1.ClusterSubnetState, opts ...Option) *Monitor { //nolint:lll // it's fine
+	pm := &Monitor{
 		z:            z.With(zap.String("component", "ipam-pool-monitor")),
 		store:        store,
 		nnccli:       nnccli,
@@ -59,6 +85,10 @@ func NewMonitor(z *zap.Logger, store ipStateStore, nnccli nodeNetworkConfigSpecU
 		nncSource:    nncSource,
 		started:      make(chan interface{}),
 	}
+	for _, opt := range opts {
+		opt(pm)
+	}
+	return pm
 }
 
 // Start begins the Monitor's pool reconcile loop.
@@ -66,6 +96,12 @@ func NewMonitor(z *zap.Logger, store ipStateStore, nnccli nodeNetworkConfigSpecU
 // Subsequently, it will run run once per RefreshDelay and attempt to re-reconcile the pool.
 func (pm *Monitor) Start(ctx context.Context) error {
 	pm.z.Debug("starting")
+	var resync <-chan time.Time // nil, and never ready, unless the forecast needs to be resynced.
+	if pm.forecaster != nil && pm.resync > 0 {
+		ticker := time.NewTicker(pm.resync)
+		defer ticker.Stop()
+		resync = ticker.C
+	}
 	for {
 		// proceed when things happen:
 		select {
@@ -73,7 +109,12 @@ func (pm *Monitor) Start(ctx context.Context) error {
 			return errors.Wrap(ctx.Err(), "pool monitor context closed")
 		case demand := <-pm.demandSource: // updated demand for IPs, recalculate request
 			pm.demand = int64(demand)
+			observedDemand.Set(float64(pm.demand))
+			if pm.forecaster != nil {
+				pm.forecaster.Observe(time.Now(), pm.demand)
+			}
 			pm.z.Info("demand update", zap.Int64("demand", pm.demand))
+		case <-resync: // let the forecast decay, recalculate request
 		case css := <-pm.cssSource: // received an updated ClusterSubnetState, recalculate request
 			pm.scaler.exhausted = css.Status.Exhausted
 			pm.z.Info("exhaustion update", zap.Bool("exhausted", pm.scaler.exhausted))
@@ -81,6 +122,10 @@ func (pm *Monitor) Start(ctx context.Context) error {
 			pm.scaler.max = int64(math.Min(float64(nnc.Status.Scaler.MaxIPCount), DefaultMaxIPs))
 			pm.scaler.batch = int64(math.Min(math.Max(float64(nnc.Status.Scaler.BatchSize), 1), float64(pm.scaler.max)))
 			pm.scaler.buffer = math.Abs(float64(nnc.Status.Scaler.RequestThresholdPercent)) / 100 //nolint:gomnd // it's a percentage
+			if len(nnc.Status.NetworkContainers) > 0 {
+				nc := &nnc.Status.NetworkContainers[0]
+				pm.metricLabels = [3]string{nc.SubnetName, nc.SubnetAddressSpace, ipampool.GenerateARMID(nc)}
+			}
 			pm.once.Do(func() {
 				pm.request = nnc.Spec.RequestedIPCount
 				close(pm.started) // close the init channel the first time we fully receive a NodeNetworkConfig.
@@ -111,9 +156,15 @@ func (pm *Monitor) reconcile(ctx context.Context) error {
 		s.buffer = 1
 	}
 
+	// size the pool for the forecast demand, if any, but never for less than the current demand.
+	demand := pm.demand
+	if pm.forecaster != nil {
+		demand = max(demand, pm.forecaster.Forecast(time.Now()))
+	}
+
 	// calculate the target state from the current pool state and scaler
-	target := calculateTargetIPCountOrMax(pm.demand, s.batch, s.max, s.buffer)
-	pm.z.Info("calculated new request", zap.Int64("demand", pm.demand), zap.Int64("batch", s.batch), zap.Int64("max", s.max), zap.Float64("buffer", s.buffer), zap.Int64("target", target))
+	target := calculateTargetIPCountOrMax(demand, s.batch, s.max, s.buffer)
+	pm.z.Info("calculated new request", zap.Int64("demand", pm.demand), zap.Int64("forecast", demand), zap.Int64("batch", s.batch), zap.Int64("max", s.max), zap.Float64("buffer", s.buffer), zap.Int64("target", target)) //nolint:lll // it's fine
 	delta := target - pm.request
 	if delta == 0 {
 		return nil
@@ -128,6 +179,7 @@ func (pm *Monitor) reconcile(ctx context.Context) error {
 		return errors.Wrap(err, "failed to UpdateSpec with NNC client")
 	}
 	pm.request = target
+	ipampool.IpamRequestedIPConfigCount.WithLabelValues(pm.metricLabels[:]...).Set(float64(pm.request))
 	pm.z.Info("scaled pool", zap.Int64("request", pm.request))
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 
 	"github.com/Azure/azure-container-networking/aitelemetry"
 	"github.com/Azure/azure-container-networking/cns/types"
@@ -16,9 +17,9 @@ import (
 type CNSLogger struct {
 	logger               *log.Logger
 	th                   aitelemetry.TelemetryHandle
-	DisableTraceLogging  bool
-	DisableMetricLogging bool
-	DisableEventLogging  bool
+	disableTraceLogging  atomic.Bool
+	disableMetricLogging atomic.Bool
+	disableEventLogging  atomic.Bool
 
 	zapLogger *zap.Logger
 
@@ -62,9 +63,28 @@ func (c *CNSLogger) InitAIWithIKey(aiConfig aitelemetry.AIConfig, instrumentatio
 
 	c.th = th
 	c.logger.Printf("AI Telemetry Handle created")
-	c.DisableMetricLogging = disableMetricLogging
-	c.DisableTraceLogging = disableTraceLogging
-	c.DisableEventLogging = disableEventLogging
+	c.SetTelemetryLevels(disableTraceLogging, disableMetricLogging, disableEventLogging)
+}
+
+// InitOTLP adds export to an OpenTelemetry collector, alongside appinsights if it is initialized.
+func (c *CNSLogger) InitOTLP(otlpConfig aitelemetry.OTLPConfig, disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
+	th, err := aitelemetry.NewOTLPTelemetry(otlpConfig)
+	if err != nil {
+		c.logger.Errorf("Error initializing OTLP Telemetry:%v", err)
+		return
+	}
+
+	c.th = aitelemetry.NewMultiTelemetry(c.th, th)
+	c.logger.Printf("OTLP Telemetry Handle created")
+	c.SetTelemetryLevels(disableTraceLogging, disableMetricLogging, disableEventLogging)
+}
+
+// SetTelemetryLevels sets which of the traces, metrics and events are not sent to telemetry. It can be called
+// while the logger is in use.
+func (c *CNSLogger) SetTelemetryLevels(disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
+	c.disableTraceLogging.Store(disableTraceLogging)
+	c.disableMetricLogging.Store(disableMetricLogging)
+	c.disableEventLogging.Store(disableEventLogging)
 }
 
 // wait time for closing AI telemetry session.
@@ -89,7 +109,7 @@ func (c *CNSLogger) Printf(format string, args ...any) {
 	c.logger.Logf(format, args...)
 	c.zapLogger.Info(fmt.Sprintf(format, args...))
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -101,7 +121,7 @@ func (c *CNSLogger) Debugf(format string, args ...any) {
 	c.logger.Debugf(format, args...)
 	c.zapLogger.Debug(fmt.Sprintf(format, args...))
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -113,7 +133,7 @@ func (c *CNSLogger) Warnf(format string, args ...any) {
 	c.logger.Warnf(format, args...)
 	c.zapLogger.Warn(fmt.Sprintf(format, args...))
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -125,7 +145,7 @@ func (c *CNSLogger) Errorf(format string, args ...any) {
 	c.logger.Errorf(format, args...)
 	c.zapLogger.Error(fmt.Sprintf(format, args...))
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -136,7 +156,7 @@ func (c *CNSLogger) Errorf(format string, args ...any) {
 func (c *CNSLogger) Request(tag string, request any, err error) {
 	c.logger.Request(tag, request, err)
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -153,7 +173,7 @@ func (c *CNSLogger) Request(tag string, request any, err error) {
 func (c *CNSLogger) Response(tag string, response any, returnCode types.ResponseCode, err error) {
 	c.logger.Response(tag, response, int(returnCode), returnCode.String(), err)
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -173,7 +193,7 @@ func (c *CNSLogger) Response(tag string, response any, returnCode types.Response
 func (c *CNSLogger) ResponseEx(tag string, request, response any, returnCode types.ResponseCode, err error) {
 	c.logger.ResponseEx(tag, request, response, int(returnCode), returnCode.String(), err)
 
-	if c.th == nil || c.DisableTraceLogging {
+	if c.th == nil || c.disableTraceLogging.Load() {
 		return
 	}
 
@@ -213,7 +233,7 @@ func (c *CNSLogger) sendTraceInternal(msg string) {
 }
 
 func (c *CNSLogger) LogEvent(event aitelemetry.Event) {
-	if c.th == nil || c.DisableEventLogging {
+	if c.th == nil || c.disableEventLogging.Load() {
 		return
 	}
 
@@ -224,7 +244,7 @@ func (c *CNSLogger) LogEvent(event aitelemetry.Event) {
 }
 
 func (c *CNSLogger) SendMetric(metric aitelemetry.Metric) {
-	if c.th == nil || c.DisableMetricLogging {
+	if c.th == nil || c.disableMetricLogging.Load() {
 		return
 	}
 
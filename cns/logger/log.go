@@ -29,6 +29,14 @@ func InitAIWithIKey(aiConfig aitelemetry.AIConfig, instrumentationKey string, di
 	Log.InitAIWithIKey(aiConfig, instrumentationKey, disableTraceLogging, disableMetricLogging, disableEventLogging)
 }
 
+func InitOTLP(otlpConfig aitelemetry.OTLPConfig, disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
+	Log.InitOTLP(otlpConfig, disableTraceLogging, disableMetricLogging, disableEventLogging)
+}
+
+func SetTelemetryLevels(disableTraceLogging, disableMetricLogging, disableEventLogging bool) {
+	Log.SetTelemetryLevels(disableTraceLogging, disableMetricLogging, disableEventLogging)
+}
+
 func SetContextDetails(orchestrator, nodeID string) {
 	Log.SetContextDetails(orchestrator, nodeID)
 }
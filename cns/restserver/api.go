@@ -1002,6 +1002,7 @@ func (service *HTTPRestService) deleteNetworkContainer(w http.ResponseWriter, r
 
 		if service.state.ContainerStatus != nil {
 			delete(service.state.ContainerStatus, ncid)
+			service.notifyNetworkContainer(NetworkContainerDeleted, ncid)
 		}
 
 		if service.state.ContainerIDByOrchestratorContext != nil {
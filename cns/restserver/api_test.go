@@ -14,6 +14,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -31,9 +32,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-const (
-	cnsJsonFileName = "azure-cns.json"
-)
+// cnsJsonFileName is the CNS state file of the test service, in a directory created by TestMain.
+var cnsJsonFileName string
 
 type IPAddress struct {
 	XMLName   xml.Name `xml:"IPAddress"`
@@ -172,6 +172,13 @@ func TestMain(m *testing.M) {
 	var err error
 	logger.InitLogger("testlogs", 0, 0, "./")
 
+	stateDir, err := os.MkdirTemp("", "cns-restserver-test")
+	if err != nil {
+		fmt.Printf("Failed to create the CNS state directory. Error: %v", err)
+		os.Exit(1)
+	}
+	cnsJsonFileName = filepath.Join(stateDir, "azure-cns.json")
+
 	// Create the service.
 	if err = startService(); err != nil {
 		fmt.Printf("Failed to start CNS Service. Error: %v", err)
@@ -204,6 +211,7 @@ func TestMain(m *testing.M) {
 	// Cleanup.
 	service.Stop()
 	nmAgentServer.Stop()
+	os.RemoveAll(stateDir)
 
 	os.Exit(exitCode)
 }
@@ -1718,7 +1726,11 @@ func startService() error {
 	}
 
 	if service != nil {
-		// Create empty azure-cns.json. CNS should start successfully by deleting this file
+		// Create empty azure-cns.json. CNS should start successfully by deleting this file.
+		// Generations left by a previous run would be recovered from instead, remove them first.
+		for n := 1; n <= store.DefaultGenerations; n++ {
+			os.Remove(fmt.Sprintf("%s.%d", cnsJsonFileName, n))
+		}
 		file, _ := os.Create(cnsJsonFileName)
 		file.Close()
 
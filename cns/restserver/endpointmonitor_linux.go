@@ -0,0 +1,125 @@
+package restserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/Azure/azure-container-networking/netlink"
+	"github.com/pkg/errors"
+)
+
+var errSubscriptionClosed = errors.New("netlink subscription closed")
+
+// endpointRemovalGracePeriod is how long a removal is held before it is reported. CNI DEL deletes the host interface
+// of an endpoint before it releases the endpoint in CNS, so the removals of endpoints released within it are expected.
+const endpointRemovalGracePeriod = 30 * time.Second
+
+// endpointRemoval is the removal of the host interface or of the route to an IP of a managed endpoint.
+type endpointRemoval struct {
+	endpointID string
+	// resource is the removed "link" or "route".
+	resource string
+	hostVeth string
+	ip       net.IP
+}
+
+// MonitorEndpointNetwork watches the kernel for the host interfaces and routes of the endpoints managed
+// by CNS, and reports those which are removed outside of CNS, which would otherwise go unnoticed until
+// the next request for the endpoint fails. It runs until ctx is done.
+func (service *HTTPRestService) MonitorEndpointNetwork(ctx context.Context) error {
+	events, err := netlink.Subscribe(ctx, netlink.GroupLink, netlink.GroupIPv4Route, netlink.GroupIPv6Route)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to link and route changes")
+	}
+
+	for event := range events {
+		if removal, ok := service.endpointRemoval(event); ok {
+			time.AfterFunc(endpointRemovalGracePeriod, func() { service.reportEndpointRemoval(removal) })
+		}
+	}
+
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "endpoint network monitor context closed")
+	}
+	return errSubscriptionClosed
+}
+
+// endpointRemoval returns the removal of the host interface or route of a managed endpoint in the event.
+func (service *HTTPRestService) endpointRemoval(event netlink.Event) (endpointRemoval, bool) {
+	switch event.Type {
+	case netlink.EventDelLink:
+		name := event.Link.Info().Name
+		if endpointID, ok := service.endpointByHostVeth(name); ok {
+			return endpointRemoval{endpointID: endpointID, resource: "link", hostVeth: name}, true
+		}
+	case netlink.EventDelRoute:
+		// only the host routes to the endpoint IPs are managed.
+		dst := event.Route.Dst
+		if dst == nil {
+			return endpointRemoval{}, false
+		}
+		if ones, bits := dst.Mask.Size(); ones != bits {
+			return endpointRemoval{}, false
+		}
+		if endpointID, ok := service.endpointByIP(dst.IP); ok {
+			return endpointRemoval{endpointID: endpointID, resource: "route", ip: dst.IP}, true
+		}
+	case netlink.EventOverrun:
+		logger.Errorf("[endpointmonitor] Link and route changes were dropped, removals may not be reported")
+	}
+	return endpointRemoval{}, false
+}
+
+// reportEndpointRemoval reports the removal if the endpoint still has the removed interface or IP in CNS, that is
+// if it was not released since.
+func (service *HTTPRestService) reportEndpointRemoval(removal endpointRemoval) {
+	switch removal.resource {
+	case "link":
+		if endpointID, ok := service.endpointByHostVeth(removal.hostVeth); ok && endpointID == removal.endpointID {
+			endpointNetworkRemovedCount.WithLabelValues(removal.resource).Inc()
+			logger.Errorf("[endpointmonitor] Host interface %s of endpoint %s was removed", removal.hostVeth, removal.endpointID)
+		}
+	case "route":
+		if endpointID, ok := service.endpointByIP(removal.ip); ok && endpointID == removal.endpointID {
+			endpointNetworkRemovedCount.WithLabelValues(removal.resource).Inc()
+			logger.Errorf("[endpointmonitor] Route to %s of endpoint %s was removed", removal.ip, removal.endpointID)
+		}
+	}
+}
+
+// endpointByHostVeth returns the ID of the endpoint which has the given host interface.
+func (service *HTTPRestService) endpointByHostVeth(name string) (string, bool) {
+	service.RLock()
+	defer service.RUnlock()
+	for endpointID, endpoint := range service.EndpointState {
+		for _, ipInfo := range endpoint.IfnameToIPMap {
+			if ipInfo != nil && ipInfo.HostVethName == name {
+				return endpointID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// endpointByIP returns the ID of the endpoint which has the given IP.
+func (service *HTTPRestService) endpointByIP(ip net.IP) (string, bool) {
+	service.RLock()
+	defer service.RUnlock()
+	for endpointID, endpoint := range service.EndpointState {
+		for _, ipInfo := range endpoint.IfnameToIPMap {
+			if ipInfo == nil {
+				continue
+			}
+			for _, ipNets := range [][]net.IPNet{ipInfo.IPv4, ipInfo.IPv6} {
+				for i := range ipNets {
+					if ipNets[i].IP.Equal(ip) {
+						return endpointID, true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,57 @@
+package restserver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/netlink"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointRemoval(t *testing.T) {
+	svc := getTestService()
+	ip, ipNet, _ := net.ParseCIDR("10.0.0.4/24")
+	svc.EndpointState["abcdef"] = &EndpointInfo{
+		PodName:      "pod",
+		PodNamespace: "ns",
+		IfnameToIPMap: map[string]*IPInfo{
+			"eth0": {
+				IPv4:         []net.IPNet{{IP: ip, Mask: ipNet.Mask}},
+				HostVethName: "azv1234",
+			},
+		},
+	}
+	link := endpointNetworkRemovedCount.WithLabelValues("link")
+	route := endpointNetworkRemovedCount.WithLabelValues("route")
+	links, routes := testutil.ToFloat64(link), testutil.ToFloat64(route)
+
+	report := func(event netlink.Event) {
+		if removal, ok := svc.endpointRemoval(event); ok {
+			svc.reportEndpointRemoval(removal)
+		}
+	}
+
+	// changes to the managed network are not reported.
+	report(netlink.Event{Type: netlink.EventNewLink, Link: &netlink.LinkInfo{Name: "azv1234"}})
+	// nor are removals of unmanaged links and routes.
+	report(netlink.Event{Type: netlink.EventDelLink, Link: &netlink.LinkInfo{Name: "azv5678"}})
+	report(netlink.Event{Type: netlink.EventDelRoute, Route: &netlink.Route{Dst: ipNet}})
+	report(netlink.Event{Type: netlink.EventDelRoute, Route: &netlink.Route{}})
+	require.Equal(t, links, testutil.ToFloat64(link))
+	require.Equal(t, routes, testutil.ToFloat64(route))
+
+	report(netlink.Event{Type: netlink.EventDelLink, Link: &netlink.LinkInfo{Name: "azv1234"}})
+	require.Equal(t, links+1, testutil.ToFloat64(link))
+
+	hostRoute := netlink.Event{Type: netlink.EventDelRoute, Route: &netlink.Route{Dst: &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}}}
+	report(hostRoute)
+	require.Equal(t, routes+1, testutil.ToFloat64(route))
+
+	// removals of endpoints released before the grace period ends, as on CNI DEL, are not reported.
+	removal, ok := svc.endpointRemoval(hostRoute)
+	require.True(t, ok)
+	delete(svc.EndpointState, "abcdef")
+	svc.reportEndpointRemoval(removal)
+	require.Equal(t, routes+1, testutil.ToFloat64(route))
+}
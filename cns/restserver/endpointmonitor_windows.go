@@ -0,0 +1,8 @@
+package restserver
+
+import "context"
+
+// MonitorEndpointNetwork is a no-op on Windows, where the endpoints are HNS endpoints.
+func (service *HTTPRestService) MonitorEndpointNetwork(context.Context) error {
+	return nil
+}
@@ -263,6 +263,7 @@ func (service *HTTPRestService) syncHostNCVersion(ctx context.Context, channelMo
 		ncInfo.HostVersion = nmaNCVersionStr
 		logger.Printf("Updated NC %s host version to %s", ncID, ncInfo.HostVersion)
 		service.state.ContainerStatus[ncID] = ncInfo
+		service.notifyNetworkContainer(NetworkContainerUpdated, ncID)
 		// if we successfully updated the NC, pop it from the needs update set.
 		delete(outdatedNCs, ncID)
 	}
@@ -457,6 +458,7 @@ func (service *HTTPRestService) DeleteNetworkContainerInternal(
 	defer service.Unlock()
 	if service.state.ContainerStatus != nil {
 		delete(service.state.ContainerStatus, ncid)
+		service.notifyNetworkContainer(NetworkContainerDeleted, ncid)
 	}
 
 	if service.state.ContainerIDByOrchestratorContext != nil {
@@ -503,6 +505,7 @@ func (service *HTTPRestService) MustEnsureNoStaleNCs(validNCIDs []string) {
 
 			logger.Errorf("[Azure CNS] Found stale NC ID %s in CNS state. Removing...", ncID)
 			delete(service.state.ContainerStatus, ncID)
+			service.notifyNetworkContainer(NetworkContainerDeleted, ncID)
 			mutated = true
 		}
 	}
@@ -17,7 +17,9 @@ import (
 	"github.com/Azure/azure-container-networking/cns/types"
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/store"
+	"github.com/Azure/azure-container-networking/tracing"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/maps"
 )
 
@@ -50,7 +52,10 @@ func (service *HTTPRestService) requestIPConfigHandlerHelper(ctx context.Context
 	// record a pod requesting an IP
 	service.podsPendingIPAssignment.Push(podInfo.Key())
 
+	// the IP pool lock is taken for the assignment, its wait shows in the span
+	_, assignSpan := tracing.Start(ctx, "restserver.AssignIPConfigs", attribute.String("pod", podInfo.Key()))
 	podIPInfo, err := requestIPConfigsHelper(service, ipconfigsRequest) //nolint:contextcheck // appease linter for revert PR
+	tracing.End(assignSpan, err)
 	if err != nil {
 		return &cns.IPConfigsResponse{
 			Response: cns.Response{
@@ -166,6 +171,19 @@ func (service *HTTPRestService) RequestIPConfigHandler(w http.ResponseWriter, r
 	logger.ResponseEx(service.Name+operationName, ipconfigsRequest, reserveResp, reserveResp.Response.ReturnCode, err)
 }
 
+// RequestIPConfigs assigns IPConfigs to the pod of the request, going through the IPConfigsHandlerMiddleware if set.
+// It backs both the REST and the gRPC APIs.
+func (service *HTTPRestService) RequestIPConfigs(ctx context.Context, ipconfigsRequest cns.IPConfigsRequest) (*cns.IPConfigsResponse, error) {
+	// Check if IPConfigsHandlerMiddleware is set
+	if service.IPConfigsHandlerMiddleware != nil {
+		// Wrap the default datapath handlers with the middleware
+		wrappedHandler := service.IPConfigsHandlerMiddleware.IPConfigsRequestHandlerWrapper(service.requestIPConfigHandlerHelper, service.ReleaseIPConfigHandlerHelper)
+		return wrappedHandler(ctx, ipconfigsRequest)
+	}
+
+	return service.requestIPConfigHandlerHelper(ctx, ipconfigsRequest)
+}
+
 // RequestIPConfigsHandler requests multiple IPConfigs from the CNS state
 func (service *HTTPRestService) RequestIPConfigsHandler(w http.ResponseWriter, r *http.Request) {
 	var ipconfigsRequest cns.IPConfigsRequest
@@ -175,17 +193,7 @@ func (service *HTTPRestService) RequestIPConfigsHandler(w http.ResponseWriter, r
 	if err != nil {
 		return
 	}
-	var ipConfigsResp *cns.IPConfigsResponse
-
-	// Check if IPConfigsHandlerMiddleware is set
-	if service.IPConfigsHandlerMiddleware != nil {
-		// Wrap the default datapath handlers with the middleware
-		wrappedHandler := service.IPConfigsHandlerMiddleware.IPConfigsRequestHandlerWrapper(service.requestIPConfigHandlerHelper, service.ReleaseIPConfigHandlerHelper)
-		ipConfigsResp, err = wrappedHandler(r.Context(), ipconfigsRequest)
-	} else {
-		ipConfigsResp, err = service.requestIPConfigHandlerHelper(r.Context(), ipconfigsRequest) // nolint:contextcheck // appease linter
-	}
-
+	ipConfigsResp, err := service.RequestIPConfigs(r.Context(), ipconfigsRequest)
 	if err != nil {
 		w.Header().Set(cnsReturnCode, ipConfigsResp.Response.ReturnCode.String())
 		err = common.Encode(w, &ipConfigsResp)
@@ -252,10 +260,11 @@ func (service *HTTPRestService) updateEndpointState(ipconfigsRequest cns.IPConfi
 			service.EndpointState[ipconfigsRequest.InfraContainerID] = endpointInfo
 		}
 
-		err := service.EndpointStateStore.Write(EndpointStoreKey, service.EndpointState)
+		err := EndpointStateSchema.Write(service.EndpointStateStore, EndpointStoreKey, service.EndpointState)
 		if err != nil {
 			return fmt.Errorf("failed to write endpoint state to store: %w", err)
 		}
+		service.notifyEndpoint(EndpointUpdated, ipconfigsRequest.InfraContainerID, service.EndpointState[ipconfigsRequest.InfraContainerID])
 	}
 	return nil
 }
@@ -284,7 +293,10 @@ func (service *HTTPRestService) ReleaseIPConfigHandlerHelper(ctx context.Context
 		}
 	}
 
-	if err := service.releaseIPConfigs(podInfo); err != nil {
+	_, releaseSpan := tracing.Start(ctx, "restserver.ReleaseIPConfigs", attribute.String("pod", podInfo.Key()))
+	err := service.releaseIPConfigs(podInfo)
+	tracing.End(releaseSpan, err)
+	if err != nil {
 		return &cns.IPConfigsResponse{
 			Response: cns.Response{
 				ReturnCode: types.UnexpectedError,
@@ -392,10 +404,11 @@ func (service *HTTPRestService) removeEndpointState(podInfo cns.PodInfo) error {
 	logger.Printf("[removeEndpointState] Removing endpoint state for infra container %s", podInfo.InfraContainerID())
 	if _, ok := service.EndpointState[podInfo.InfraContainerID()]; ok {
 		delete(service.EndpointState, podInfo.InfraContainerID())
-		err := service.EndpointStateStore.Write(EndpointStoreKey, service.EndpointState)
+		err := EndpointStateSchema.Write(service.EndpointStateStore, EndpointStoreKey, service.EndpointState)
 		if err != nil {
 			return fmt.Errorf("failed to write endpoint state to store: %w", err)
 		}
+		service.notifyEndpoint(EndpointDeleted, podInfo.InfraContainerID(), nil)
 	} else { // will not fail if no endpoint state for infra container id is found
 		logger.Printf("[removeEndpointState] No endpoint state found for infra container %s", podInfo.InfraContainerID())
 	}
@@ -509,6 +522,7 @@ func (service *HTTPRestService) updateIPConfigState(ipID string, updatedState ty
 		ipConfig.SetState(updatedState)
 		ipConfig.PodInfo = podInfo
 		service.PodIPConfigState[ipID] = ipConfig
+		service.notifyIPConfig(IPConfigUpdated, ipConfig)
 		return ipConfig, nil
 	}
 
@@ -727,6 +741,7 @@ func (service *HTTPRestService) MarkExistingIPsAsPendingRelease(pendingIPIDs []s
 			logger.Printf("[MarkExistingIPsAsPending]: Marking IP [%+v] to PendingRelease", ipconfig)
 			ipconfig.SetState(types.PendingRelease)
 			service.PodIPConfigState[id] = ipconfig
+			service.notifyIPConfig(IPConfigUpdated, ipconfig)
 		} else {
 			logger.Errorf("Inconsistent state, ipconfig with ID [%v] marked as pending release, but does not exist in state", id)
 		}
@@ -1053,7 +1068,7 @@ func (service *HTTPRestService) GetEndpointHelper(endpointID string) (*EndpointI
 		return nil, ErrStoreEmpty
 	}
 
-	err := service.EndpointStateStore.Read(EndpointStoreKey, &service.EndpointState)
+	err := EndpointStateSchema.Read(service.EndpointStateStore, EndpointStoreKey, &service.EndpointState)
 	if err != nil {
 
 		if errors.Is(err, store.ErrKeyNotFound) {
@@ -1079,6 +1094,46 @@ func (service *HTTPRestService) GetEndpointHelper(endpointID string) (*EndpointI
 	return nil, ErrEndpointStateNotFound
 }
 
+// GetEndpointState returns the state of the given endpointID along with the response code describing a failure.
+// It backs the GetEndpoint gRPC API.
+func (service *HTTPRestService) GetEndpointState(endpointID string) (*EndpointInfo, types.ResponseCode, error) {
+	service.Lock()
+	defer service.Unlock()
+	// Check if CNS is managing the CNI statefile
+	if service.Options[common.OptManageEndpointState] == false {
+		return nil, types.UnexpectedError, ErrOptManageEndpointState
+	}
+	if len(endpointID) < ContainerIDLength {
+		return nil, types.InvalidRequest, errors.Errorf("[GetEndpointState] invalid endpoint ID %q", endpointID)
+	}
+	endpointInfo, err := service.GetEndpointHelper(endpointID)
+	if err != nil {
+		if errors.Is(err, ErrEndpointStateNotFound) {
+			return nil, types.NotFound, err
+		}
+		return nil, types.UnexpectedError, err
+	}
+	return endpointInfo, types.Success, nil
+}
+
+// UpdateEndpointState updates the state of the given endpointID and returns the response code describing a failure.
+// It backs the UpdateEndpoint gRPC API.
+func (service *HTTPRestService) UpdateEndpointState(endpointID string, req map[string]*IPInfo) (types.ResponseCode, error) {
+	service.Lock()
+	defer service.Unlock()
+	// Check if CNS is managing the CNI statefile
+	if service.Options[common.OptManageEndpointState] == false {
+		return types.UnexpectedError, ErrOptManageEndpointState
+	}
+	if err := verifyUpdateEndpointStateRequest(req); err != nil {
+		return types.InvalidRequest, err
+	}
+	if err := service.UpdateEndpointHelper(endpointID, req); err != nil {
+		return types.UnexpectedError, err
+	}
+	return types.Success, nil
+}
+
 // UpdateEndpointHandler handles the incoming UpdateEndpoint requests with http Patch method
 func (service *HTTPRestService) UpdateEndpointHandler(w http.ResponseWriter, r *http.Request) {
 	logger.Printf("[updateEndpoint] updateEndpoint for %s", r.URL.Path)
@@ -1141,11 +1196,12 @@ func (service *HTTPRestService) UpdateEndpointHelper(endpointID string, req map[
 			// updating the ipInfoMap
 			updateIPInfoMap(endpointInfo.IfnameToIPMap, interfaceInfo, ifName, endpointID)
 		}
-		err := service.EndpointStateStore.Write(EndpointStoreKey, service.EndpointState)
+		err := EndpointStateSchema.Write(service.EndpointStateStore, EndpointStoreKey, service.EndpointState)
 		if err != nil {
 			return fmt.Errorf("[updateEndpoint] failed to write endpoint state to store for pod %s :  %w", endpointInfo.PodName, err)
 		}
 		logger.Printf("[updateEndpoint] successfully write the state to the file %s", endpointID)
+		service.notifyEndpoint(EndpointUpdated, endpointID, endpointInfo)
 		return nil
 	}
 	return errors.New("[updateEndpoint] endpoint could not be found in the statefile")
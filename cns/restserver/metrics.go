@@ -94,6 +94,14 @@ var (
 		},
 		[]string{},
 	)
+	endpointNetworkRemovedCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "cx_endpoint_network_removed_total",
+			Help:        "Count of host interfaces and routes of managed endpoints removed outside of CNS",
+			ConstLabels: prometheus.Labels{customerMetricLabel: customerMetricLabelValue},
+		},
+		[]string{"resource"},
+	)
 	pendingReleaseIPCount = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name:        "cx_pending_release_ips_v2",
@@ -116,6 +124,7 @@ func init() {
 		availableIPCount,
 		pendingProgrammingIPCount,
 		pendingReleaseIPCount,
+		endpointNetworkRemovedCount,
 	)
 }
 
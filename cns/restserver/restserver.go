@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/azure-container-networking/cns"
@@ -72,6 +73,9 @@ type HTTPRestService struct {
 	cniConflistGenerator       CNIConflistGenerator
 	generateCNIConflistOnce    sync.Once
 	IPConfigsHandlerMiddleware cns.IPConfigsHandlerMiddleware
+	watch                      watchLog
+	pprofEnabled               atomic.Bool
+	registerPProfOnce          sync.Once
 }
 
 type CNIConflistGenerator interface {
@@ -103,6 +107,8 @@ type IPInfo struct {
 	HostVethName  string      `json:",omitempty"`
 	MacAddress    string      `json:",omitempty"`
 	NICType       cns.NICType
+	// HasHostPortRules is set when CNI programmed host port rules for the endpoint, so they are only looked up on delete if set.
+	HasHostPortRules bool `json:",omitempty"`
 }
 
 type GetHTTPServiceDataResponse struct {
@@ -233,7 +239,10 @@ func (service *HTTPRestService) Init(config *common.ServiceConfig) error {
 		return err
 	}
 
-	service.restoreState()
+	if err = service.restoreState(); err != nil {
+		return err
+	}
+
 	err = service.restoreNetworkState()
 	if err != nil {
 		logger.Errorf("[Azure CNS]  Failed to restore network state, err:%v.", err)
@@ -314,21 +323,46 @@ func (service *HTTPRestService) Init(config *common.ServiceConfig) error {
 	return nil
 }
 
+// RegisterPProfEndpoints registers the pprof endpoints on the listener and enables them.
 func (service *HTTPRestService) RegisterPProfEndpoints() {
-	if service.Listener != nil {
+	if service.Listener == nil {
+		return
+	}
+	service.registerPProfOnce.Do(func() {
 		mux := service.Listener.GetMux()
-		mux.Handle("/debug/pprof/allocs", pprof.Handler("allocs"))
-		mux.Handle("/debug/pprof/block", pprof.Handler("block"))
-		mux.Handle("/debug/pprof/goroutine", pprof.Handler("goroutine"))
-		mux.Handle("/debug/pprof/heap", pprof.Handler("heap"))
-		mux.Handle("/debug/pprof/mutex", pprof.Handler("mutex"))
-		mux.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
-		mux.HandleFunc("/debug/pprof/", pprof.Index)
-		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.Handle("/debug/pprof/allocs", service.pprofGate(pprof.Handler("allocs")))
+		mux.Handle("/debug/pprof/block", service.pprofGate(pprof.Handler("block")))
+		mux.Handle("/debug/pprof/goroutine", service.pprofGate(pprof.Handler("goroutine")))
+		mux.Handle("/debug/pprof/heap", service.pprofGate(pprof.Handler("heap")))
+		mux.Handle("/debug/pprof/mutex", service.pprofGate(pprof.Handler("mutex")))
+		mux.Handle("/debug/pprof/threadcreate", service.pprofGate(pprof.Handler("threadcreate")))
+		mux.Handle("/debug/pprof/", service.pprofGate(http.HandlerFunc(pprof.Index)))
+		mux.Handle("/debug/pprof/cmdline", service.pprofGate(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle("/debug/pprof/profile", service.pprofGate(http.HandlerFunc(pprof.Profile)))
+		mux.Handle("/debug/pprof/symbol", service.pprofGate(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle("/debug/pprof/trace", service.pprofGate(http.HandlerFunc(pprof.Trace)))
+	})
+	service.pprofEnabled.Store(true)
+}
+
+// SetPProfEnabled enables or disables the pprof endpoints. The endpoints are registered the first time they are
+// enabled, and answer 404 Not Found while they are disabled.
+func (service *HTTPRestService) SetPProfEnabled(enabled bool) {
+	if enabled {
+		service.RegisterPProfEndpoints()
+		return
 	}
+	service.pprofEnabled.Store(false)
+}
+
+func (service *HTTPRestService) pprofGate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !service.pprofEnabled.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
 }
 
 // Start starts the CNS listener.
@@ -24,6 +24,112 @@ import (
 
 // This file contains the utility/helper functions called by either HTTP APIs or Exported/Internal APIs on HTTPRestService
 
+// stateSchema lists the migrations of the CNS state persisted under storeKey.
+var stateSchema = store.MustNewSchema("cns",
+	store.Migration{
+		Version:     1,
+		Description: "start versioning the CNS state",
+		Up:          store.NoopMigration,
+		Down:        store.NoopMigration,
+	},
+)
+
+// EndpointStateSchema lists the migrations of the endpoint state persisted under EndpointStoreKey.
+var EndpointStateSchema = store.MustNewSchema("endpoints",
+	store.Migration{
+		Version:     1,
+		Description: "start versioning the endpoint state",
+		Up:          store.NoopMigration,
+		Down:        store.NoopMigration,
+	},
+	store.Migration{
+		Version:     2,
+		Description: "key unnamed interfaces by the infra interface name",
+		Up:          nameInfraInterfaces,
+		// Version 1 reads interfaces keyed by the infra interface name, as written by stateless CNI.
+		Down: store.NoopMigration,
+	},
+)
+
+// nameInfraInterfaces moves the interface persisted without a name, which CNS wrote before the interface
+// name was part of the request, under the infra interface name and marks it as the infra NIC.
+func nameInfraInterfaces(state store.State) error {
+	for endpointID, raw := range state {
+		var endpoint map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &endpoint); err != nil {
+			return errors.Wrapf(err, "failed to decode endpoint %s", endpointID)
+		}
+
+		var interfaces map[string]map[string]json.RawMessage
+		if v, ok := endpoint["IfnameToIPMap"]; ok {
+			if err := json.Unmarshal(v, &interfaces); err != nil {
+				return errors.Wrapf(err, "failed to decode interfaces of endpoint %s", endpointID)
+			}
+		}
+
+		ipInfo, ok := interfaces[""]
+		if !ok {
+			continue
+		}
+		delete(interfaces, "")
+
+		// An interface already keyed by name is newer than the unnamed one.
+		if _, ok := interfaces[InfraInterfaceName]; ok {
+			logger.Printf("[Azure CNS] Dropping unnamed interface of endpoint %s which also has %s", endpointID, InfraInterfaceName)
+		} else {
+			if nicType, ok := ipInfo["NICType"]; !ok || string(nicType) == `""` {
+				b, err := json.Marshal(cns.InfraNIC)
+				if err != nil {
+					return errors.Wrap(err, "failed to encode NIC type")
+				}
+				ipInfo["NICType"] = b
+			}
+			interfaces[InfraInterfaceName] = ipInfo
+		}
+
+		b, err := json.Marshal(interfaces)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode interfaces of endpoint %s", endpointID)
+		}
+		endpoint["IfnameToIPMap"] = b
+
+		if state[endpointID], err = json.Marshal(endpoint); err != nil {
+			return errors.Wrapf(err, "failed to encode endpoint %s", endpointID)
+		}
+	}
+
+	return nil
+}
+
+// DowngradeState rewrites the persisted CNS state and endpoint state for a CNS that knows only the first
+// version migrations of each schema, so that it can be rolled back to without losing state. Version 0 is
+// the format used before the state was versioned. The endpoint state store may be nil.
+func DowngradeState(stateStore, endpointStateStore store.KeyValueStore, version int) error {
+	downgrade := func(schema *store.Schema, kvs store.KeyValueStore, key string) error {
+		if kvs == nil || !kvs.Exists() {
+			return nil
+		}
+
+		// State written by this CNS is never ahead of its own schema.
+		to := version
+		if to > schema.Version() {
+			to = schema.Version()
+		}
+
+		err := schema.Downgrade(kvs, key, to)
+		if errors.Is(err, store.ErrKeyNotFound) || errors.Is(err, store.ErrStoreEmpty) {
+			return nil
+		}
+		return err //nolint:wrapcheck // the schema names the state
+	}
+
+	if err := downgrade(stateSchema, stateStore, storeKey); err != nil {
+		return err
+	}
+
+	return downgrade(EndpointStateSchema, endpointStateStore, EndpointStoreKey)
+}
+
 // Get the network info from the service network state
 func (service *HTTPRestService) getNetworkInfo(networkName string) (*networkInfo, bool) {
 	service.RLock()
@@ -57,7 +163,7 @@ func (service *HTTPRestService) saveState() error {
 
 	// Update time stamp.
 	service.state.TimeStamp = time.Now()
-	err := service.store.Write(storeKey, &service.state)
+	err := stateSchema.Write(service.store, storeKey, &service.state)
 	if err != nil {
 		logger.Errorf("[Azure CNS] Failed to save state, err: %v", err)
 	}
@@ -66,33 +172,39 @@ func (service *HTTPRestService) saveState() error {
 }
 
 // restoreState restores CNS state from persistent store.
-func (service *HTTPRestService) restoreState() {
+// It fails only if the state was written by a newer CNS, which must downgrade it first.
+func (service *HTTPRestService) restoreState() error {
 	logger.Printf("[Azure CNS] restoreState")
 
 	// Skip if a store is not provided.
 	if service.store == nil {
 		logger.Printf("[Azure CNS]  store not initialized.")
-		return
+		return nil
 	}
 
 	// Read any persisted state.
-	err := service.store.Read(storeKey, &service.state)
+	err := stateSchema.Read(service.store, storeKey, &service.state)
 	if err != nil {
-		if err == store.ErrKeyNotFound {
+		switch {
+		case err == store.ErrKeyNotFound:
 			// Nothing to restore.
 			logger.Printf("[Azure CNS]  No state to restore.\n")
-		} else {
+		case errors.Is(err, store.ErrSchemaTooNew):
+			// Keep the state, overwriting it would lose what this version does not know about.
+			logger.Errorf("[Azure CNS]  Refusing to restore state, err:%v", err)
+			return err
+		default:
 			logger.Errorf("[Azure CNS]  Failed to restore state, err:%v. Removing azure-cns.json", err)
 			service.store.Remove()
 		}
 
-		return
+		return nil
 	}
 
 	logger.Printf("[Azure CNS]  Restored state, %+v\n", service.state)
 
 	if service.Options[acn.OptManageEndpointState] == true {
-		err := service.EndpointStateStore.Read(EndpointStoreKey, &service.EndpointState)
+		err := EndpointStateSchema.Read(service.EndpointStateStore, EndpointStoreKey, &service.EndpointState)
 		if err != nil {
 			if errors.Is(err, store.ErrKeyNotFound) {
 				// Nothing to restore.
@@ -100,11 +212,13 @@ func (service *HTTPRestService) restoreState() {
 			} else {
 				logger.Errorf("[Azure CNS]  Failed to restore endpoint state, err:%v. Removing endpoints.json", err)
 			}
-			return
+			return nil
 		}
 		logger.Printf("[Azure CNS]  Restored endpoint state, %+v\n", service.EndpointState)
 
 	}
+
+	return nil
 }
 
 func (service *HTTPRestService) saveNetworkContainerGoalState(req cns.CreateNetworkContainerRequest) (types.ResponseCode, string) { //nolint // legacy
@@ -211,6 +325,7 @@ func (service *HTTPRestService) saveNetworkContainerGoalState(req cns.CreateNetw
 		return types.UnsupportedNetworkContainerType, errMsg
 	}
 
+	service.notifyNetworkContainer(NetworkContainerUpdated, req.NetworkContainerid)
 	service.saveState()
 	return 0, ""
 }
@@ -310,6 +425,7 @@ func (service *HTTPRestService) addIPConfigStateUntransacted(ncID string, hostVe
 		logger.Printf("[Azure-Cns] Add IP %s as %s", ipconfig.IPAddress, newIPCNSStatus)
 
 		service.PodIPConfigState[ipID] = ipconfigStatus
+		service.notifyIPConfig(IPConfigUpdated, ipconfigStatus)
 
 		// Todo Update batch API and maintain the count
 	}
@@ -347,7 +463,10 @@ func (service *HTTPRestService) removeToBeDeletedIPStateUntransacted(
 	logger.Printf("[Azure-Cns] Delete the PodIpConfigState, IpId: %s, IPConfigStatus: %v",
 		ipID,
 		service.PodIPConfigState[ipID])
-	delete(service.PodIPConfigState, ipID)
+	if ipConfigStatus, exists := service.PodIPConfigState[ipID]; exists {
+		delete(service.PodIPConfigState, ipID)
+		service.notifyIPConfig(IPConfigDeleted, ipConfigStatus)
+	}
 	return 0, ""
 }
 
@@ -1,9 +1,17 @@
 package restserver
 
 import (
+	"encoding/json"
+	"net"
+	"path/filepath"
 	"testing"
 
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/common"
+	"github.com/Azure/azure-container-networking/processlock"
+	"github.com/Azure/azure-container-networking/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAreNCsPresent(t *testing.T) {
@@ -159,3 +167,111 @@ func TestDeleteNCs(t *testing.T) {
 		})
 	}
 }
+
+// test that state is persisted with its schema version and that state from a newer CNS is not discarded
+func TestSaveRestoreStateSchema(t *testing.T) {
+	kvs := store.NewMockStore("")
+	service := HTTPRestService{
+		Service: &cns.Service{Service: &common.Service{}},
+		store:   kvs,
+		state:   &httpRestServiceState{Location: "westus"},
+	}
+	require.NoError(t, service.saveState())
+
+	var persisted store.State
+	require.NoError(t, kvs.Read(storeKey, &persisted))
+	assert.JSONEq(t, "1", string(persisted[store.SchemaVersionField]))
+
+	service.state = &httpRestServiceState{}
+	require.NoError(t, service.restoreState())
+	assert.Equal(t, "westus", service.state.Location)
+
+	persisted[store.SchemaVersionField] = json.RawMessage("2")
+	require.NoError(t, kvs.Write(storeKey, persisted))
+	require.ErrorIs(t, service.restoreState(), store.ErrSchemaTooNew)
+	require.NoError(t, kvs.Read(storeKey, &persisted))
+	assert.Contains(t, persisted, "Location")
+}
+
+// test that an unnamed interface is moved under the infra interface name and that the state round trips through a rollback
+func TestEndpointStateSchemaRoundTrip(t *testing.T) {
+	kvs, err := store.NewJsonFileStore(filepath.Join(t.TempDir(), "endpoints.json"), processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+
+	ipv4 := []net.IPNet{{IP: net.IPv4(10, 0, 0, 4), Mask: net.CIDRMask(24, 32)}}
+	unversioned := map[string]*EndpointInfo{
+		"legacy": {PodName: "a", PodNamespace: "default", IfnameToIPMap: map[string]*IPInfo{
+			"": {IPv4: ipv4, HostVethName: "azv1"},
+		}},
+		"current": {PodName: "b", PodNamespace: "default", IfnameToIPMap: map[string]*IPInfo{
+			InfraInterfaceName: {IPv4: ipv4, HostVethName: "azv2", NICType: cns.InfraNIC},
+		}},
+	}
+	require.NoError(t, kvs.Write(EndpointStoreKey, unversioned))
+
+	want := map[string]*EndpointInfo{
+		"legacy": {PodName: "a", PodNamespace: "default", IfnameToIPMap: map[string]*IPInfo{
+			InfraInterfaceName: {IPv4: ipv4, HostVethName: "azv1", NICType: cns.InfraNIC},
+		}},
+		"current": unversioned["current"],
+	}
+
+	var upgraded map[string]*EndpointInfo
+	require.NoError(t, EndpointStateSchema.Read(kvs, EndpointStoreKey, &upgraded))
+	assert.Equal(t, want, upgraded)
+	require.NoError(t, EndpointStateSchema.Write(kvs, EndpointStoreKey, upgraded))
+
+	var persisted store.State
+	require.NoError(t, kvs.Read(EndpointStoreKey, &persisted))
+	assert.JSONEq(t, "2", string(persisted[store.SchemaVersionField]))
+
+	// A CNS predating the schema decodes the endpoints directly and cannot skip the version field.
+	require.NoError(t, DowngradeState(nil, kvs, 0))
+	var rolledBack map[string]*EndpointInfo
+	require.NoError(t, kvs.Read(EndpointStoreKey, &rolledBack))
+	assert.Equal(t, want, rolledBack)
+
+	var reupgraded map[string]*EndpointInfo
+	require.NoError(t, EndpointStateSchema.Read(kvs, EndpointStoreKey, &reupgraded))
+	assert.Equal(t, want, reupgraded)
+}
+
+// test that a rollback downgrades each store no further than its own schema and skips missing state
+func TestDowngradeState(t *testing.T) {
+	dir := t.TempDir()
+	stateStore, err := store.NewJsonFileStore(filepath.Join(dir, "azure-cns.json"), processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	service := HTTPRestService{
+		Service: &cns.Service{Service: &common.Service{}},
+		store:   stateStore,
+		state:   &httpRestServiceState{Location: "westus"},
+	}
+	require.NoError(t, service.saveState())
+
+	endpointStore, err := store.NewJsonFileStore(filepath.Join(dir, "endpoints.json"), processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, EndpointStateSchema.Write(endpointStore, EndpointStoreKey, map[string]*EndpointInfo{}))
+
+	require.ErrorIs(t, DowngradeState(stateStore, endpointStore, -1), store.ErrInvalidSchemaVersion)
+
+	// Version 2 is past the CNS state schema, which stays at its version 1.
+	require.NoError(t, DowngradeState(stateStore, endpointStore, 2))
+	var persisted store.State
+	require.NoError(t, stateStore.Read(storeKey, &persisted))
+	assert.JSONEq(t, "1", string(persisted[store.SchemaVersionField]))
+
+	require.NoError(t, DowngradeState(stateStore, endpointStore, 1))
+	require.NoError(t, endpointStore.Read(EndpointStoreKey, &persisted))
+	assert.JSONEq(t, "1", string(persisted[store.SchemaVersionField]))
+
+	require.NoError(t, DowngradeState(stateStore, nil, 0))
+	var unversioned store.State
+	require.NoError(t, stateStore.Read(storeKey, &unversioned))
+	assert.NotContains(t, unversioned, store.SchemaVersionField)
+	assert.Contains(t, unversioned, "Location")
+
+	missing, err := store.NewJsonFileStore(filepath.Join(dir, "missing.json"), processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, DowngradeState(missing, nil, 0))
+	assert.False(t, missing.Exists())
+}
@@ -0,0 +1,255 @@
+package restserver
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// watchBufferSize is the number of events kept to resume watches from.
+const watchBufferSize = 4096
+
+var (
+	// ErrWatchStreamChanged is returned when resuming a watch of a previous stream, e.g. from before CNS restarted.
+	ErrWatchStreamChanged = errors.New("watch stream changed, resync with a snapshot")
+	// ErrWatchSequenceExpired is returned when the events to resume a watch from are no longer buffered.
+	ErrWatchSequenceExpired = errors.New("watch sequence expired, resync with a snapshot")
+	// ErrInvalidWatchSequence is returned when resuming a watch from an event which was never sent.
+	ErrInvalidWatchSequence = errors.New("invalid watch sequence")
+)
+
+// WatchEventType is the kind of change carried by a WatchEvent.
+type WatchEventType string
+
+const (
+	IPConfigUpdated         WatchEventType = "IPConfigUpdated"
+	IPConfigDeleted         WatchEventType = "IPConfigDeleted"
+	NetworkContainerUpdated WatchEventType = "NetworkContainerUpdated"
+	NetworkContainerDeleted WatchEventType = "NetworkContainerDeleted"
+	EndpointUpdated         WatchEventType = "EndpointUpdated"
+	EndpointDeleted         WatchEventType = "EndpointDeleted"
+)
+
+// WatchNetworkContainer is the state of a network container carried by a WatchEvent.
+type WatchNetworkContainer struct {
+	ID              string
+	Type            string
+	Version         string
+	HostVersion     string
+	IPConfiguration cns.IPConfiguration
+}
+
+// WatchEvent is a change to the IP pool, network container or endpoint state of CNS.
+// Exactly one of IPConfig, NetworkContainer and Endpoint is set, depending on the Type.
+type WatchEvent struct {
+	StreamID string
+	// Sequence increases by one with each event of the stream. Snapshot events carry the sequence
+	// of the last event they include.
+	Sequence         uint64
+	Time             time.Time
+	Type             WatchEventType
+	Snapshot         bool
+	IPConfig         *cns.IPConfigurationStatus
+	NetworkContainer *WatchNetworkContainer
+	EndpointID       string
+	Endpoint         *EndpointInfo
+}
+
+// WatchRequest describes where a watch starts.
+type WatchRequest struct {
+	// StreamID and FromSequence resume the watch after the last event received. The watch starts from
+	// the current state if StreamID is empty.
+	StreamID     string
+	FromSequence uint64
+	// Snapshot sends the current state as snapshot events before the changes.
+	Snapshot bool
+}
+
+// watchLog keeps the latest events in a ring buffer and wakes up the watchers when an event is published.
+// The zero value is ready to use.
+type watchLog struct {
+	sync.Mutex
+	streamID string
+	head     uint64 // the sequence of the latest event
+	events   []WatchEvent
+	changed  chan struct{}
+}
+
+// init must be called with the lock held.
+func (l *watchLog) init() {
+	if l.changed != nil {
+		return
+	}
+	l.streamID = uuid.New().String()
+	l.events = make([]WatchEvent, watchBufferSize)
+	l.changed = make(chan struct{})
+}
+
+func (l *watchLog) publish(e WatchEvent) { //nolint:gocritic // events are small and copied into the ring
+	l.Lock()
+	defer l.Unlock()
+	l.init()
+	l.head++
+	e.StreamID = l.streamID
+	e.Sequence = l.head
+	e.Time = time.Now()
+	l.events[l.head%watchBufferSize] = e
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// position returns the stream ID and the sequence of the latest event.
+func (l *watchLog) position() (string, uint64) {
+	l.Lock()
+	defer l.Unlock()
+	l.init()
+	return l.streamID, l.head
+}
+
+// since returns the events after the given sequence and a channel closed when the next event is published.
+func (l *watchLog) since(sequence uint64) ([]WatchEvent, <-chan struct{}, error) {
+	l.Lock()
+	defer l.Unlock()
+	l.init()
+	if sequence > l.head {
+		return nil, nil, errors.Wrapf(ErrInvalidWatchSequence, "sequence %d is after the latest event %d", sequence, l.head)
+	}
+	if l.head-sequence > watchBufferSize {
+		return nil, nil, errors.Wrapf(ErrWatchSequenceExpired, "sequence %d", sequence)
+	}
+	events := make([]WatchEvent, 0, l.head-sequence)
+	for s := sequence + 1; s <= l.head; s++ {
+		events = append(events, l.events[s%watchBufferSize])
+	}
+	return events, l.changed, nil
+}
+
+// Watch sends the changes to the IP pool, network container and endpoint state of CNS until the context
+// is done or send fails. Events are published by the state mutators while they hold the service lock, so
+// a snapshot taken under the lock is consistent with the sequence it is sent with.
+func (service *HTTPRestService) Watch(ctx context.Context, req WatchRequest, send func(*WatchEvent) error) error {
+	streamID, head := service.watch.position()
+
+	var sequence uint64
+	switch {
+	case req.Snapshot:
+		var snapshot []WatchEvent
+		snapshot, sequence = service.watchSnapshot()
+		for i := range snapshot {
+			if err := send(&snapshot[i]); err != nil {
+				return err
+			}
+		}
+	case req.StreamID == "":
+		sequence = head
+	case req.StreamID != streamID:
+		return errors.Wrapf(ErrWatchStreamChanged, "stream %s", req.StreamID)
+	default:
+		sequence = req.FromSequence
+	}
+
+	for {
+		events, changed, err := service.watch.since(sequence)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if err := send(&events[i]); err != nil {
+				return err
+			}
+			sequence = events[i].Sequence
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// watchSnapshot returns the current state as snapshot events, along with the sequence of the latest event.
+func (service *HTTPRestService) watchSnapshot() ([]WatchEvent, uint64) {
+	service.RLock()
+	defer service.RUnlock()
+	streamID, head := service.watch.position()
+	now := time.Now()
+
+	snapshot := make([]WatchEvent, 0, len(service.PodIPConfigState)+len(service.EndpointState))
+	add := func(e WatchEvent) { //nolint:gocritic // copied into the snapshot
+		e.StreamID = streamID
+		e.Sequence = head
+		e.Time = now
+		e.Snapshot = true
+		snapshot = append(snapshot, e)
+	}
+	if service.state != nil {
+		for ncID := range service.state.ContainerStatus {
+			add(WatchEvent{Type: NetworkContainerUpdated, NetworkContainer: service.watchNetworkContainer(ncID)})
+		}
+	}
+	for _, ipConfig := range service.PodIPConfigState { //nolint:gocritic // copy is ok
+		ipConfig := ipConfig
+		add(WatchEvent{Type: IPConfigUpdated, IPConfig: &ipConfig})
+	}
+	for endpointID, endpointInfo := range service.EndpointState {
+		add(WatchEvent{Type: EndpointUpdated, EndpointID: endpointID, Endpoint: copyEndpointInfo(endpointInfo)})
+	}
+	return snapshot, head
+}
+
+// notifyIPConfig publishes the state of an IP, the caller must hold the service lock.
+func (service *HTTPRestService) notifyIPConfig(eventType WatchEventType, ipConfig cns.IPConfigurationStatus) { //nolint:gocritic // copied into the event
+	service.watch.publish(WatchEvent{Type: eventType, IPConfig: &ipConfig})
+}
+
+// notifyNetworkContainer publishes the state of a network container, the caller must hold the service lock.
+func (service *HTTPRestService) notifyNetworkContainer(eventType WatchEventType, ncID string) {
+	nc := &WatchNetworkContainer{ID: ncID}
+	if eventType != NetworkContainerDeleted {
+		nc = service.watchNetworkContainer(ncID)
+	}
+	service.watch.publish(WatchEvent{Type: eventType, NetworkContainer: nc})
+}
+
+// notifyEndpoint publishes the state of an endpoint, the caller must hold the service lock.
+func (service *HTTPRestService) notifyEndpoint(eventType WatchEventType, endpointID string, endpointInfo *EndpointInfo) {
+	service.watch.publish(WatchEvent{Type: eventType, EndpointID: endpointID, Endpoint: copyEndpointInfo(endpointInfo)})
+}
+
+func (service *HTTPRestService) watchNetworkContainer(ncID string) *WatchNetworkContainer {
+	ncStatus := service.state.ContainerStatus[ncID]
+	return &WatchNetworkContainer{
+		ID:              ncID,
+		Type:            ncStatus.CreateNetworkContainerRequest.NetworkContainerType,
+		Version:         ncStatus.VMVersion,
+		HostVersion:     ncStatus.HostVersion,
+		IPConfiguration: ncStatus.CreateNetworkContainerRequest.IPConfiguration,
+	}
+}
+
+// copyEndpointInfo returns a deep copy of the endpoint state, which keeps changing after it is published.
+func copyEndpointInfo(endpointInfo *EndpointInfo) *EndpointInfo {
+	if endpointInfo == nil {
+		return nil
+	}
+	c := &EndpointInfo{
+		PodName:       endpointInfo.PodName,
+		PodNamespace:  endpointInfo.PodNamespace,
+		IfnameToIPMap: make(map[string]*IPInfo, len(endpointInfo.IfnameToIPMap)),
+	}
+	for ifName, ipInfo := range endpointInfo.IfnameToIPMap {
+		if ipInfo == nil {
+			continue
+		}
+		ipInfoCopy := *ipInfo
+		ipInfoCopy.IPv4 = append([]net.IPNet(nil), ipInfo.IPv4...)
+		ipInfoCopy.IPv6 = append([]net.IPNet(nil), ipInfo.IPv6...)
+		c.IfnameToIPMap[ifName] = &ipInfoCopy
+	}
+	return c
+}
@@ -0,0 +1,135 @@
+package restserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/cns/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchLog(t *testing.T) {
+	var l watchLog
+	for i := 0; i < 3; i++ {
+		l.publish(WatchEvent{Type: IPConfigUpdated})
+	}
+
+	events, changed, err := l.since(0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	for i := range events {
+		require.Equal(t, uint64(i+1), events[i].Sequence)
+		require.NotEmpty(t, events[i].StreamID)
+	}
+
+	events, _, err = l.since(3)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	_, _, err = l.since(4)
+	require.ErrorIs(t, err, ErrInvalidWatchSequence)
+
+	l.publish(WatchEvent{Type: IPConfigDeleted})
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected watchers to be woken up by the new event")
+	}
+
+	// only the latest events are kept to resume from.
+	for i := 0; i < watchBufferSize; i++ {
+		l.publish(WatchEvent{Type: IPConfigUpdated})
+	}
+	_, _, err = l.since(3)
+	require.ErrorIs(t, err, ErrWatchSequenceExpired)
+	events, _, err = l.since(4)
+	require.NoError(t, err)
+	require.Len(t, events, watchBufferSize)
+	require.Equal(t, uint64(5), events[0].Sequence)
+}
+
+// watch runs a watch of the service in the background and returns the channels of its events and result.
+func watch(ctx context.Context, svc *HTTPRestService, req WatchRequest) (<-chan *WatchEvent, <-chan error) {
+	events := make(chan *WatchEvent, 100)
+	result := make(chan error, 1)
+	go func() {
+		result <- svc.Watch(ctx, req, func(e *WatchEvent) error {
+			events <- e
+			return nil
+		})
+	}()
+	return events, result
+}
+
+func nextEvent(t *testing.T, events <-chan *WatchEvent) *WatchEvent {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a watch event")
+		return nil
+	}
+}
+
+func TestWatch(t *testing.T) {
+	svc := getTestService()
+	ipconfigs := map[string]cns.IPConfigurationStatus{
+		testIPID1: NewPodState(testIP1, testIPID1, testNCID, types.Available, 0),
+	}
+	require.NoError(t, UpdatePodIPConfigState(t, svc, ipconfigs, testNCID))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, result := watch(ctx, svc, WatchRequest{Snapshot: true})
+
+	// the watch starts with the current state.
+	e := nextEvent(t, events)
+	require.True(t, e.Snapshot)
+	require.Equal(t, NetworkContainerUpdated, e.Type)
+	require.Equal(t, testNCID, e.NetworkContainer.ID)
+	e = nextEvent(t, events)
+	require.True(t, e.Snapshot)
+	require.Equal(t, IPConfigUpdated, e.Type)
+	require.Equal(t, types.Available, e.IPConfig.GetState())
+
+	// followed by the changes.
+	req := cns.IPConfigsRequest{
+		PodInterfaceID:   testPod1Info.InterfaceID(),
+		InfraContainerID: testPod1Info.InfraContainerID(),
+		Ifname:           "eth0",
+	}
+	req.OrchestratorContext, _ = testPod1Info.OrchestratorContext()
+	podIPInfo, err := requestIPConfigsHelper(svc, req)
+	require.NoError(t, err)
+
+	e = nextEvent(t, events)
+	require.False(t, e.Snapshot)
+	require.Equal(t, IPConfigUpdated, e.Type)
+	require.Equal(t, testIPID1, e.IPConfig.ID)
+	require.Equal(t, types.Assigned, e.IPConfig.GetState())
+	require.Equal(t, testPod1Info.Key(), e.IPConfig.PodInfo.Key())
+
+	require.NoError(t, svc.updateEndpointState(req, testPod1Info, podIPInfo))
+	e = nextEvent(t, events)
+	require.Equal(t, EndpointUpdated, e.Type)
+	require.Equal(t, req.InfraContainerID, e.EndpointID)
+	require.Equal(t, testIP1, e.Endpoint.IfnameToIPMap["eth0"].IPv4[0].IP.String())
+
+	cancel()
+	require.NoError(t, <-result)
+
+	// a broken watch is resumed after the last event received.
+	require.NoError(t, svc.removeEndpointState(testPod1Info))
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events, _ = watch(ctx, svc, WatchRequest{StreamID: e.StreamID, FromSequence: e.Sequence})
+	resumed := nextEvent(t, events)
+	require.Equal(t, EndpointDeleted, resumed.Type)
+	require.Equal(t, e.Sequence+1, resumed.Sequence)
+
+	// but not from another stream.
+	_, result = watch(ctx, svc, WatchRequest{StreamID: "previous", FromSequence: e.Sequence})
+	require.ErrorIs(t, <-result, ErrWatchStreamChanged)
+}
@@ -100,6 +100,9 @@ func (service *Service) AddListener(config *common.ServiceConfig) error {
 	if err != nil {
 		return errors.Wrap(err, "Failed to construct url for node listener")
 	}
+	if config.WrapHandler != nil {
+		nodeListener.WrapHandlers(config.WrapHandler)
+	}
 
 	// only use TLS connection for DNC/CNS listener:
 	if config.TLSSettings.TLSPort != "" {
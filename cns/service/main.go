@@ -63,6 +63,7 @@ import (
 	localtls "github.com/Azure/azure-container-networking/server/tls"
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/Azure/azure-container-networking/telemetry"
+	"github.com/Azure/azure-container-networking/tracing"
 	"github.com/avast/retry-go/v4"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -345,6 +346,13 @@ var args = acn.ArgumentList{
 		Type:         "string",
 		DefaultValue: "",
 	},
+	{
+		Name:         acn.OptDowngradeState,
+		Shorthand:    acn.OptDowngradeStateAlias,
+		Description:  "Rewrite the persisted state at the given schema version for a rollback and exit, 0 for CNS versions before the state was versioned",
+		Type:         "string",
+		DefaultValue: "",
+	},
 }
 
 // init() is executed before main() whenever this package is imported
@@ -525,6 +533,7 @@ func main() {
 	telemetryDaemonEnabled := acn.GetArg(acn.OptTelemetryService).(bool)
 	cniConflistFilepathArg := acn.GetArg(acn.OptCNIConflistFilepath).(string)
 	cniConflistScenarioArg := acn.GetArg(acn.OptCNIConflistScenario).(string)
+	downgradeStateArg := acn.GetArg(acn.OptDowngradeState).(string)
 
 	if vers {
 		printVersion()
@@ -570,10 +579,20 @@ func main() {
 		}
 	}
 	configuration.SetCNSConfigDefaults(cnsconfig)
+	syncHostNCVersionIntervalMs.Store(int64(cnsconfig.SyncHostNCVersionIntervalMs))
+
+	// the reloader must snapshot the config before it is changed at runtime below.
+	reloader, err := configuration.NewReloader(cmdLineConfigPath, cnsconfig)
+	if err != nil {
+		logger.Errorf("fatal: failed to create cns config reloader: %v", err)
+		os.Exit(1)
+	}
+	registerSyncHostNCVersionReload(reloader)
 
-	disableTelemetry := cnsconfig.TelemetrySettings.DisableAll
+	ts := cnsconfig.TelemetrySettings
+	// DisableAll turns appinsights off, OTLP export is enabled on its own by setting a collector endpoint.
+	disableTelemetry := ts.DisableAll
 	if !disableTelemetry {
-		ts := cnsconfig.TelemetrySettings
 		aiConfig := aitelemetry.AIConfig{
 			AppName:                      name,
 			AppVersion:                   version,
@@ -590,6 +609,44 @@ func main() {
 			logger.InitAI(aiConfig, ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
 		}
 	}
+	if ts.OTLP.Endpoint != "" {
+		otlpConfig := aitelemetry.OTLPConfig{
+			AppName:                      name,
+			AppVersion:                   version,
+			Endpoint:                     ts.OTLP.Endpoint,
+			Protocol:                     ts.OTLP.Protocol,
+			Insecure:                     ts.OTLP.Insecure,
+			Headers:                      ts.OTLP.Headers,
+			BatchInterval:                ts.TelemetryBatchIntervalInSecs,
+			RefreshTimeout:               ts.RefreshIntervalInSecs,
+			DisableMetadataRefreshThread: ts.DisableMetadataRefreshThread,
+			DebugMode:                    ts.DebugMode,
+		}
+		logger.InitOTLP(otlpConfig, ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
+		disableTelemetry = false
+	}
+	if !disableTelemetry {
+		registerTelemetryReload(reloader)
+	}
+	if tcfg := cnsconfig.TracingSettings; tcfg.Exporter != "" {
+		shutdownTracing, tracingErr := tracing.Init(tracing.Config{
+			Exporter:       tcfg.Exporter,
+			Path:           tcfg.Path,
+			ServiceName:    name,
+			ServiceVersion: version,
+		})
+		if tracingErr != nil {
+			logger.Errorf("Failed to initialize tracing: %v", tracingErr)
+		} else {
+			// the HTTP APIs continue the trace context sent by CNI
+			config.WrapHandler = tracing.NewHandler
+			defer func() {
+				if err := shutdownTracing(context.Background()); err != nil {
+					logger.Errorf("Failed to flush traces: %v", err)
+				}
+			}()
+		}
+	}
 	logger.Printf("[Azure CNS] Using config: %+v", cnsconfig)
 
 	_, envEnableConflistGeneration := os.LookupEnv(envVarEnableCNIConflistGeneration)
@@ -649,7 +706,11 @@ func main() {
 			return nil
 		}),
 	}
-	go healthserver.Start(z, cnsconfig.MetricsBindAddress, &healthz.Handler{}, readyChecker)
+	healthServer := healthserver.NewServer(z, &healthz.Handler{}, readyChecker)
+	if err := healthServer.Listen(cnsconfig.MetricsBindAddress); err != nil {
+		z.Error("failed to run healthserver", zap.Error(err))
+	}
+	registerHealthServerReload(reloader, healthServer)
 
 	nmaConfig, err := nmagent.NewConfig(cnsconfig.WireserverIP)
 	if err != nil {
@@ -705,8 +766,8 @@ func main() {
 	}
 
 	// Create the key value store.
-	storeFileName := storeFileLocation + name + ".json"
-	config.Store, err = store.NewJsonFileStore(storeFileName, lockclient, nil)
+	storeFileName := storeFileLocation + name
+	config.Store, err = store.NewStore(cnsconfig.StoreType, storeFileName, lockclient, nil)
 	if err != nil {
 		logger.Errorf("Failed to create store file: %s, due to error %v\n", storeFileName, err)
 		return
@@ -728,15 +789,29 @@ func main() {
 			return
 		}
 		// Create the key value store.
-		storeFileName := endpointStorePath + endpointStoreName + ".json"
+		storeFileName := endpointStorePath + endpointStoreName
 		logger.Printf("EndpointStoreState path is %s", storeFileName)
-		endpointStateStore, err = store.NewJsonFileStore(storeFileName, endpointStoreLock, nil)
+		endpointStateStore, err = store.NewStore(cnsconfig.StoreType, storeFileName, endpointStoreLock, nil)
 		if err != nil {
 			logger.Errorf("Failed to create endpoint state store file: %s, due to error %v\n", storeFileName, err)
 			return
 		}
 	}
 
+	if downgradeStateArg != "" {
+		stateVersion, err := strconv.Atoi(downgradeStateArg)
+		if err != nil {
+			logger.Errorf("Invalid state version %s: %v", downgradeStateArg, err)
+			os.Exit(1)
+		}
+		if err = restserver.DowngradeState(config.Store, endpointStateStore, stateVersion); err != nil {
+			logger.Errorf("Failed to downgrade state to version %d: %v", stateVersion, err)
+			os.Exit(1)
+		}
+		logger.Printf("Downgraded state to version %d", stateVersion)
+		os.Exit(0)
+	}
+
 	wsProxy := wireserver.Proxy{
 		Host:       cnsconfig.WireserverIP,
 		HTTPClient: &http.Client{},
@@ -813,7 +888,9 @@ func main() {
 		// reg key value for PriorityVLANTag = 3  --> Packet priority and VLAN enabled
 		// for more details goto https://docs.nvidia.com/networking/display/winof2v230/Configuring+the+Driver+Registry+Keys#ConfiguringtheDriverRegistryKeys-GeneralRegistryKeysGeneralRegistryKeys
 		if platform.HasMellanoxAdapter() {
-			go platform.MonitorAndSetMellanoxRegKeyPriorityVLANTag(rootCtx, cnsconfig.MellanoxMonitorIntervalSecs)
+			mellanox := &mellanoxMonitor{}
+			mellanox.start(rootCtx, cnsconfig.MellanoxMonitorIntervalSecs)
+			registerMellanoxMonitorReload(rootCtx, reloader, mellanox)
 		}
 	}
 
@@ -873,7 +950,7 @@ func main() {
 	}
 
 	// Conditionally initialize and start the gRPC server
-	if cnsconfig.GRPCSettings.Enable {
+	if cnsconfig.GRPCSettings.ServeAPIs {
 		// Define gRPC server settings
 		settings := grpc.ServerSettings{
 			IPAddress: cnsconfig.GRPCSettings.IPAddress,
@@ -881,7 +958,7 @@ func main() {
 		}
 
 		// Initialize CNS service
-		cnsService := &grpc.CNS{Logger: z}
+		cnsService := &grpc.CNS{Logger: z, State: httpRemoteRestService}
 
 		// Create a new gRPC server
 		server, grpcErr := grpc.NewServer(settings, cnsService, z)
@@ -905,6 +982,7 @@ func main() {
 		if cnsconfig.EnablePprof {
 			httpRemoteRestService.RegisterPProfEndpoints()
 		}
+		registerPProfReload(reloader, httpRemoteRestService)
 
 		err = httpRemoteRestService.Start(&config)
 		if err != nil {
@@ -914,6 +992,12 @@ func main() {
 
 	}
 
+	go func() {
+		if err := reloader.Run(rootCtx); err != nil {
+			logger.Errorf("[Azure CNS] Failed to watch config, config changes will require a restart: %v", err)
+		}
+	}()
+
 	// if user does not provide cns url by -c option, then start http local server
 	// TODO: we will deprecated -c option in next phase and start local server in any case
 	if config.Server.EnableLocalServer {
@@ -961,6 +1045,19 @@ func main() {
 		}()
 	}
 
+	if cnsconfig.ManageEndpointState {
+		go func() {
+			_ = retry.Do(func() error {
+				z.Info("starting endpoint network monitor")
+				if err := httpRemoteRestService.MonitorEndpointNetwork(rootCtx); err != nil {
+					z.Error("endpoint network monitor stopped, will retry", zap.Error(err))
+					return errors.Wrap(err, "endpoint network monitor stopped, will retry")
+				}
+				return nil
+			}, retry.DelayType(retry.BackOffDelay), retry.Attempts(0), retry.Context(rootCtx)) // infinite cancellable exponential backoff retrier
+		}()
+	}
+
 	if !disableTelemetry {
 		go metric.SendHeartBeat(rootCtx, time.Minute*time.Duration(cnsconfig.TelemetrySettings.HeartBeatIntervalInMins), homeAzMonitor, cnsconfig.ChannelMode)
 		go httpRemoteRestService.SendNCSnapShotPeriodically(rootCtx, cnsconfig.TelemetrySettings.SnapshotIntervalInMins)
@@ -1161,17 +1258,9 @@ func InitializeMultiTenantController(ctx context.Context, httpRestService cns.HT
 	logger.Printf("Starting SyncHostNCVersion")
 	go func() {
 		// Periodically poll vfp programmed NC version from NMAgent
-		tickerChannel := time.Tick(time.Duration(cnsconfig.SyncHostNCVersionIntervalMs) * time.Millisecond)
-		for {
-			select {
-			case <-tickerChannel:
-				timedCtx, cancel := context.WithTimeout(ctx, time.Duration(cnsconfig.SyncHostNCVersionIntervalMs)*time.Millisecond)
-				httpRestServiceImpl.SyncHostNCVersion(timedCtx, cnsconfig.ChannelMode)
-				cancel()
-			case <-ctx.Done():
-				return
-			}
-		}
+		runSyncHostNCVersionLoop(ctx, func(ctx context.Context) {
+			httpRestServiceImpl.SyncHostNCVersion(ctx, cnsconfig.ChannelMode)
+		})
 	}()
 
 	return nil
@@ -1299,7 +1388,7 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 			return errors.Wrap(err, "failed to create CNS EndpointState From CNI")
 		}
 		// endpoint state needs tobe loaded in memory so the subsequent Delete calls remove the state and release the IPs.
-		if err = httpRestServiceImplementation.EndpointStateStore.Read(restserver.EndpointStoreKey, &httpRestServiceImplementation.EndpointState); err != nil {
+		if err = restserver.EndpointStateSchema.Read(httpRestServiceImplementation.EndpointStateStore, restserver.EndpointStoreKey, &httpRestServiceImplementation.EndpointState); err != nil {
 			return errors.Wrap(err, "failed to restore endpoint state")
 		}
 	}
@@ -1430,7 +1519,16 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 	ipDemandCh := make(chan int)
 	if cnsconfig.EnableIPAMv2 {
 		nncCh := make(chan v1alpha.NodeNetworkConfig)
-		poolMonitor = ipampoolv2.NewMonitor(z, httpRestServiceImplementation, cachedscopedcli, ipDemandCh, nncCh, cssCh).AsV1(nncCh)
+		var poolOpts []ipampoolv2.Option
+		if settings := cnsconfig.IPAMv2PredictiveScaling; settings != nil {
+			forecaster := ipampoolv2.NewPredictiveForecaster(ipampoolv2.PredictiveOptions{
+				Window:        time.Duration(settings.WindowSecs) * time.Second,
+				Horizon:       time.Duration(settings.HorizonSecs) * time.Second,
+				DecayHalfLife: time.Duration(settings.DecayHalfLifeSecs) * time.Second,
+			})
+			poolOpts = append(poolOpts, ipampoolv2.WithForecaster(forecaster, time.Duration(settings.ResyncIntervalSecs)*time.Second))
+		}
+		poolMonitor = ipampoolv2.NewMonitor(z, httpRestServiceImplementation, cachedscopedcli, ipDemandCh, nncCh, cssCh, poolOpts...).AsV1(nncCh)
 	} else {
 		poolOpts := ipampool.Options{
 			RefreshDelay: poolIPAMRefreshRateInMilliseconds * time.Millisecond,
@@ -1536,18 +1634,10 @@ func InitializeCRDState(ctx context.Context, httpRestService cns.HTTPService, cn
 	go func() {
 		logger.Printf("Starting SyncHostNCVersion loop.")
 		// Periodically poll vfp programmed NC version from NMAgent
-		tickerChannel := time.Tick(time.Duration(cnsconfig.SyncHostNCVersionIntervalMs) * time.Millisecond)
-		for {
-			select {
-			case <-tickerChannel:
-				timedCtx, cancel := context.WithTimeout(ctx, time.Duration(cnsconfig.SyncHostNCVersionIntervalMs)*time.Millisecond)
-				httpRestServiceImplementation.SyncHostNCVersion(timedCtx, cnsconfig.ChannelMode)
-				cancel()
-			case <-ctx.Done():
-				logger.Printf("Stopping SyncHostNCVersion loop.")
-				return
-			}
-		}
+		runSyncHostNCVersionLoop(ctx, func(ctx context.Context) {
+			httpRestServiceImplementation.SyncHostNCVersion(ctx, cnsconfig.ChannelMode)
+		})
+		logger.Printf("Stopping SyncHostNCVersion loop.")
 	}()
 	logger.Printf("Initialized SyncHostNCVersion loop.")
 	return nil
@@ -1598,7 +1688,7 @@ func PopulateCNSEndpointState(endpointStateStore store.KeyValueStore) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to create CNS Endpoint state from CNI")
 	}
-	err = endpointStateStore.Write(restserver.EndpointStoreKey, endpointState)
+	err = restserver.EndpointStateSchema.Write(endpointStateStore, restserver.EndpointStoreKey, endpointState)
 	if err != nil {
 		return fmt.Errorf("failed to write endpoint state to store: %w", err)
 	}
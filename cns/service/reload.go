@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-container-networking/cns/configuration"
+	"github.com/Azure/azure-container-networking/cns/healthserver"
+	"github.com/Azure/azure-container-networking/cns/logger"
+	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/platform"
+)
+
+// syncHostNCVersionIntervalMs is the interval of the SyncHostNCVersion loop, which is changed by a config reload.
+var syncHostNCVersionIntervalMs atomic.Int64
+
+// runSyncHostNCVersionLoop calls syncHostNCVersion every syncHostNCVersionIntervalMs, with a timeout of the interval,
+// until ctx is done.
+func runSyncHostNCVersionLoop(ctx context.Context, syncHostNCVersion func(context.Context)) {
+	for {
+		interval := time.Duration(syncHostNCVersionIntervalMs.Load()) * time.Millisecond
+		select {
+		case <-time.After(interval):
+			timedCtx, cancel := context.WithTimeout(ctx, interval)
+			syncHostNCVersion(timedCtx)
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// mellanoxMonitor runs the Mellanox PriorityVLANTag monitor, and restarts it when its interval is changed.
+type mellanoxMonitor struct {
+	sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (m *mellanoxMonitor) start(ctx context.Context, intervalSecs int) {
+	m.Lock()
+	defer m.Unlock()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	go platform.MonitorAndSetMellanoxRegKeyPriorityVLANTag(ctx, intervalSecs)
+}
+
+func registerTelemetryReload(r *configuration.Reloader) {
+	r.Register("telemetry", func(_, newConfig *configuration.CNSConfig) error {
+		ts := newConfig.TelemetrySettings
+		logger.SetTelemetryLevels(ts.DisableTrace, ts.DisableMetric, ts.DisableEvent)
+		return nil
+	}, "TelemetrySettings.DisableTrace", "TelemetrySettings.DisableMetric", "TelemetrySettings.DisableEvent")
+}
+
+func registerSyncHostNCVersionReload(r *configuration.Reloader) {
+	r.Register("SyncHostNCVersion", func(_, newConfig *configuration.CNSConfig) error {
+		syncHostNCVersionIntervalMs.Store(int64(newConfig.SyncHostNCVersionIntervalMs))
+		return nil
+	}, "SyncHostNCVersionIntervalMs")
+}
+
+func registerHealthServerReload(r *configuration.Reloader, s *healthserver.Server) {
+	r.Register("healthserver", func(_, newConfig *configuration.CNSConfig) error {
+		return s.Listen(newConfig.MetricsBindAddress) //nolint:wrapcheck // already wrapped
+	}, "MetricsBindAddress")
+}
+
+func registerMellanoxMonitorReload(ctx context.Context, r *configuration.Reloader, m *mellanoxMonitor) {
+	r.Register("mellanox", func(_, newConfig *configuration.CNSConfig) error {
+		m.start(ctx, newConfig.MellanoxMonitorIntervalSecs)
+		return nil
+	}, "MellanoxMonitorIntervalSecs")
+}
+
+func registerPProfReload(r *configuration.Reloader, service *restserver.HTTPRestService) {
+	r.Register("pprof", func(_, newConfig *configuration.CNSConfig) error {
+		service.SetPProfEnabled(newConfig.EnablePprof)
+		return nil
+	}, "EnablePprof")
+}
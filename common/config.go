@@ -145,6 +145,10 @@ const (
 	// OptCNIConflistFilepathAlias TODO: a "shorthand" is required for the acn args package but this isn't helpful
 	OptCNIConflistFilepathAlias = "cniconflist"
 
+	// Downgrade the persisted CNS state to a schema version before a rollback
+	OptDowngradeState      = "downgrade-state"
+	OptDowngradeStateAlias = "ds"
+
 	// OptCNIConflistFilepath
 	OptCNIConflistScenario = "cni-conflist-scenario"
 	// OptCNIConflistScenarioAlias "shorthand" for the cni conflist scenairo, see above
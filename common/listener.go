@@ -25,6 +25,7 @@ type Listener struct {
 	listener     net.Listener
 	tlsListener  net.Listener
 	mux          *http.ServeMux
+	wrapHandler  func(handler http.Handler, path string) http.Handler
 }
 
 // NewListener creates a new Listener.
@@ -126,8 +127,17 @@ func (l *Listener) AddEndpoint(endpoint string) {
 	l.endpoints = append(l.endpoints, endpoint)
 }
 
+// WrapHandlers wraps the protocol handlers registered afterwards with wrap, e.g. to trace them.
+func (l *Listener) WrapHandlers(wrap func(handler http.Handler, path string) http.Handler) {
+	l.wrapHandler = wrap
+}
+
 // AddHandler registers a protocol handler.
 func (l *Listener) AddHandler(path string, handler http.HandlerFunc) {
+	if l.wrapHandler != nil {
+		l.mux.Handle(path, l.wrapHandler(handler, path))
+		return
+	}
 	l.mux.HandleFunc(path, handler)
 }
 
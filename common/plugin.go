@@ -41,6 +41,7 @@ type PluginConfig struct {
 	Listener  *Listener
 	ErrChan   chan error
 	Store     store.KeyValueStore
+	StoreType string
 	Stateless bool
 }
 
@@ -0,0 +1,31 @@
+package v1alpha1
+
+import (
+	"github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1beta1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = (*ClusterSubnetState)(nil)
+
+// ConvertTo converts the ClusterSubnetState to the v1beta1 hub version.
+func (src *ClusterSubnetState) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.ClusterSubnetState)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", dstRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = v1beta1.ClusterSubnetStateStatus(src.Status)
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this ClusterSubnetState.
+func (dst *ClusterSubnetState) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.ClusterSubnetState)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", srcRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = ClusterSubnetStateStatus(src.Status)
+	return nil
+}
@@ -0,0 +1,4 @@
+package v1beta1
+
+// Hub marks v1beta1 as the version the other ClusterSubnetState versions are converted to and from.
+func (*ClusterSubnetState) Hub() {}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/crd"
 	"github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1alpha1"
+	"github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1beta1"
 	"github.com/pkg/errors"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	typedv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
@@ -24,6 +25,7 @@ var Scheme = runtime.NewScheme()
 func init() {
 	_ = scheme.AddToScheme(Scheme)
 	_ = v1alpha1.AddToScheme(Scheme)
+	_ = v1beta1.AddToScheme(Scheme)
 }
 
 // Installer provides methods to manage the lifecycle of the ClusterSubnetState resource definition.
@@ -75,6 +77,10 @@ func (i *Installer) InstallOrUpdate(ctx context.Context) (*v1.CustomResourceDefi
 		}
 	}
 	if !reflect.DeepEqual(css.Spec.Versions, current.Spec.Versions) {
+		// keep the conversion webhook configured in the cluster, the embedded definitions do not set one
+		if css.Spec.Conversion == nil {
+			css.Spec.Conversion = current.Spec.Conversion
+		}
 		css.SetResourceVersion(current.GetResourceVersion())
 		previous := *current
 		current, err = i.cli.Update(ctx, css, metav1.UpdateOptions{})
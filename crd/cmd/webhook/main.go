@@ -0,0 +1,101 @@
+// Command webhook serves the defaulting and validating admission webhooks of the multitenancy and NodeNetworkConfig
+// CRDs, and the conversion webhook between their API versions. It is deployed by deploy.yaml next to this file, the
+// webhook configurations pointing the API server to it are in webhook.yaml, and conversion.yaml enables the conversion
+// of the CRDs.
+package main
+
+import (
+	"flag"
+	"os"
+
+	cssv1alpha1 "github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1alpha1"
+	cssv1beta1 "github.com/Azure/azure-container-networking/crd/clustersubnetstate/api/v1beta1"
+	mtv1alpha1 "github.com/Azure/azure-container-networking/crd/multitenancy/api/v1alpha1"
+	mtv1beta1 "github.com/Azure/azure-container-networking/crd/multitenancy/api/v1beta1"
+	mtwebhook "github.com/Azure/azure-container-networking/crd/multitenancy/webhook"
+	nncv1alpha "github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	nncv1beta1 "github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1beta1"
+	nncwebhook "github.com/Azure/azure-container-networking/crd/nodenetworkconfig/webhook"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func main() {
+	var (
+		port        int
+		certDir     string
+		metricsAddr string
+		healthAddr  string
+	)
+	flag.IntVar(&port, "port", webhook.DefaultPort, "port the webhook server listens on")
+	flag.StringVar(&certDir, "cert-dir", "", "directory containing tls.crt and tls.key, defaults to <tmp>/k8s-webhook-server/serving-certs")
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "address the metrics endpoint binds to, 0 disables it")
+	flag.StringVar(&healthAddr, "health-probe-bind-address", ":8081", "address the health probe endpoints bind to")
+	opts := ctrlzap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(ctrlzap.New(ctrlzap.UseFlagOptions(&opts)))
+	log := ctrl.Log.WithName("webhook")
+
+	if err := run(port, certDir, metricsAddr, healthAddr); err != nil {
+		log.Error(err, "webhook server failed")
+		os.Exit(1)
+	}
+}
+
+func run(port int, certDir, metricsAddr, healthAddr string) error {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return errors.Wrap(err, "failed to add client-go scheme")
+	}
+	// all the versions must be in the scheme for the conversion webhook to convert between them
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		cssv1alpha1.AddToScheme, cssv1beta1.AddToScheme,
+		mtv1alpha1.AddToScheme, mtv1beta1.AddToScheme,
+		nncv1alpha.AddToScheme, nncv1beta1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return errors.Wrap(err, "failed to add CRD scheme")
+		}
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                ctrlmetrics.Options{BindAddress: metricsAddr},
+		HealthProbeBindAddress: healthAddr,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    port,
+			CertDir: certDir,
+		}),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create manager")
+	}
+
+	if err := mtwebhook.SetupWebhookWithManager(mgr); err != nil {
+		return err //nolint:wrapcheck // already wrapped
+	}
+	if err := nncwebhook.SetupWebhookWithManager(mgr); err != nil {
+		return err //nolint:wrapcheck // already wrapped
+	}
+	// the ClusterSubnetState has no admission webhook, only the conversion one
+	if err := ctrl.NewWebhookManagedBy(mgr).For(&cssv1beta1.ClusterSubnetState{}).Complete(); err != nil {
+		return errors.Wrap(err, "failed to set up ClusterSubnetState conversion webhook")
+	}
+
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		return errors.Wrap(err, "failed to add health check")
+	}
+	if err := mgr.AddReadyzCheck("readyz", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		return errors.Wrap(err, "failed to add ready check")
+	}
+
+	return errors.Wrap(mgr.Start(ctrl.SetupSignalHandler()), "failed to start manager")
+}
@@ -0,0 +1,213 @@
+package v1alpha1
+
+import (
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1beta1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// The deprecated fields removed in v1beta1 are folded into the fields replacing them when converting to v1beta1, and
+// derived back from those when converting from v1beta1, as long as that is unambiguous.
+
+var (
+	_ conversion.Convertible = (*MultitenantPodNetworkConfig)(nil)
+	_ conversion.Convertible = (*NodeInfo)(nil)
+	_ conversion.Convertible = (*PodNetwork)(nil)
+	_ conversion.Convertible = (*PodNetworkInstance)(nil)
+)
+
+// ConvertTo converts the MultitenantPodNetworkConfig to the v1beta1 hub version. A status only using the deprecated
+// fields is converted to a single vnet NIC InterfaceInfo.
+func (src *MultitenantPodNetworkConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.MultitenantPodNetworkConfig)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.MultitenantPodNetworkConfigSpec(src.Spec)
+	dst.Status = v1beta1.MultitenantPodNetworkConfigStatus{}
+	for _, info := range src.Status.InterfaceInfos {
+		dst.Status.InterfaceInfos = append(dst.Status.InterfaceInfos, v1beta1.InterfaceInfo{
+			NCID:            info.NCID,
+			PrimaryIP:       info.PrimaryIP,
+			MacAddress:      info.MacAddress,
+			GatewayIP:       info.GatewayIP,
+			DeviceType:      v1beta1.DeviceType(info.DeviceType),
+			AccelnetEnabled: info.AccelnetEnabled,
+		})
+	}
+	if len(dst.Status.InterfaceInfos) == 0 && src.Status.NCID != "" {
+		dst.Status.InterfaceInfos = []v1beta1.InterfaceInfo{{
+			NCID:       src.Status.NCID,
+			PrimaryIP:  src.Status.PrimaryIP,
+			MacAddress: src.Status.MacAddress,
+			GatewayIP:  src.Status.GatewayIP,
+			DeviceType: v1beta1.DeviceTypeVnetNIC,
+		}}
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this MultitenantPodNetworkConfig. The deprecated fields are set from
+// the InterfaceInfo if there is a single one, of a vnet NIC.
+func (dst *MultitenantPodNetworkConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.MultitenantPodNetworkConfig)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = MultitenantPodNetworkConfigSpec(src.Spec)
+	dst.Status = MultitenantPodNetworkConfigStatus{}
+	for _, info := range src.Status.InterfaceInfos {
+		dst.Status.InterfaceInfos = append(dst.Status.InterfaceInfos, InterfaceInfo{
+			NCID:            info.NCID,
+			PrimaryIP:       info.PrimaryIP,
+			MacAddress:      info.MacAddress,
+			GatewayIP:       info.GatewayIP,
+			DeviceType:      DeviceType(info.DeviceType),
+			AccelnetEnabled: info.AccelnetEnabled,
+		})
+	}
+	if infos := src.Status.InterfaceInfos; len(infos) == 1 && infos[0].DeviceType == v1beta1.DeviceTypeVnetNIC {
+		dst.Status.NCID = infos[0].NCID
+		dst.Status.PrimaryIP = infos[0].PrimaryIP
+		dst.Status.MacAddress = infos[0].MacAddress
+		dst.Status.GatewayIP = infos[0].GatewayIP
+	}
+	return nil
+}
+
+// ConvertTo converts the NodeInfo to the v1beta1 hub version.
+func (src *NodeInfo) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.NodeInfo)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", dstRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.NodeInfoSpec(src.Spec)
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this NodeInfo.
+func (dst *NodeInfo) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.NodeInfo)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", srcRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = NodeInfoSpec(src.Spec)
+	return nil
+}
+
+// ConvertTo converts the PodNetwork to the v1beta1 hub version, using VnetGUID as the NetworkID if that is not set.
+func (src *PodNetwork) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.PodNetwork)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.PodNetworkSpec{
+		NetworkID:        src.Spec.NetworkID,
+		DeviceType:       v1beta1.DeviceType(src.Spec.DeviceType),
+		SubnetResourceID: src.Spec.SubnetResourceID,
+		SubnetGUID:       src.Spec.SubnetGUID,
+	}
+	if dst.Spec.NetworkID == "" {
+		dst.Spec.NetworkID = src.Spec.VnetGUID
+	}
+	dst.Status = v1beta1.PodNetworkStatus{
+		Status:          v1beta1.Status(src.Status.Status),
+		AddressPrefixes: src.Status.AddressPrefixes,
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this PodNetwork. The NetworkID of a vnet is its GUID, so it is also
+// set as the VnetGUID.
+func (dst *PodNetwork) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.PodNetwork)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = PodNetworkSpec{
+		NetworkID:        src.Spec.NetworkID,
+		DeviceType:       DeviceType(src.Spec.DeviceType),
+		SubnetResourceID: src.Spec.SubnetResourceID,
+		SubnetGUID:       src.Spec.SubnetGUID,
+	}
+	if src.Spec.DeviceType == "" || src.Spec.DeviceType == v1beta1.DeviceTypeVnetNIC {
+		dst.Spec.VnetGUID = src.Spec.NetworkID
+	}
+	dst.Status = PodNetworkStatus{
+		Status:          Status(src.Status.Status),
+		AddressPrefixes: src.Status.AddressPrefixes,
+	}
+	return nil
+}
+
+// ConvertTo converts the PodNetworkInstance to the v1beta1 hub version. The deprecated PodNetwork and
+// PodIPReservationSize are converted to a PodNetworkConfig if there are none.
+func (src *PodNetworkInstance) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.PodNetworkInstance)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.PodNetworkInstanceSpec{}
+	for _, config := range src.Spec.PodNetworkConfigs {
+		dst.Spec.PodNetworkConfigs = append(dst.Spec.PodNetworkConfigs, v1beta1.PodNetworkConfig(config))
+	}
+	if len(dst.Spec.PodNetworkConfigs) == 0 && src.Spec.PodNetwork != "" {
+		dst.Spec.PodNetworkConfigs = []v1beta1.PodNetworkConfig{{
+			PodNetwork:           src.Spec.PodNetwork,
+			PodIPReservationSize: src.Spec.PodIPReservationSize,
+		}}
+	}
+	dst.Status = v1beta1.PodNetworkInstanceStatus{
+		PodIPAddresses: src.Status.PodIPAddresses,
+		Status:         v1beta1.PNIStatus(src.Status.Status),
+	}
+	if src.Status.PodNetworkStatuses != nil {
+		dst.Status.PodNetworkStatuses = make(map[string]v1beta1.PNIStatus, len(src.Status.PodNetworkStatuses))
+		for name, status := range src.Status.PodNetworkStatuses {
+			dst.Status.PodNetworkStatuses[name] = v1beta1.PNIStatus(status)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this PodNetworkInstance. The deprecated PodNetwork and
+// PodIPReservationSize are set from the PodNetworkConfig if there is a single one.
+func (dst *PodNetworkInstance) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.PodNetworkInstance)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = PodNetworkInstanceSpec{}
+	for _, config := range src.Spec.PodNetworkConfigs {
+		dst.Spec.PodNetworkConfigs = append(dst.Spec.PodNetworkConfigs, PodNetworkConfig(config))
+	}
+	if len(src.Spec.PodNetworkConfigs) == 1 {
+		dst.Spec.PodNetwork = src.Spec.PodNetworkConfigs[0].PodNetwork
+		dst.Spec.PodIPReservationSize = src.Spec.PodNetworkConfigs[0].PodIPReservationSize
+	}
+	dst.Status = PodNetworkInstanceStatus{
+		PodIPAddresses: src.Status.PodIPAddresses,
+		Status:         PNIStatus(src.Status.Status),
+	}
+	if src.Status.PodNetworkStatuses != nil {
+		dst.Status.PodNetworkStatuses = make(map[string]PNIStatus, len(src.Status.PodNetworkStatuses))
+		for name, status := range src.Status.PodNetworkStatuses {
+			dst.Status.PodNetworkStatuses[name] = PNIStatus(status)
+		}
+	}
+	return nil
+}
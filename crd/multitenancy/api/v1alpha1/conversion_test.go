@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+func TestIsConvertible(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	for _, obj := range []runtime.Object{&MultitenantPodNetworkConfig{}, &NodeInfo{}, &PodNetwork{}, &PodNetworkInstance{}} {
+		ok, err := conversion.IsConvertible(scheme, obj)
+		require.NoError(t, err)
+		require.True(t, ok, "%T", obj)
+	}
+}
+
+func TestPodNetworkInstanceConversion(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "pni", Namespace: "default"}
+	tests := []struct {
+		name     string
+		pni      *PodNetworkInstance
+		hub      *v1beta1.PodNetworkInstance
+		restored *PodNetworkInstance
+	}{
+		{
+			name: "deprecated fields only",
+			pni: &PodNetworkInstance{ObjectMeta: meta, Spec: PodNetworkInstanceSpec{
+				PodNetwork:           "pn",
+				PodIPReservationSize: 2,
+			}},
+			hub: &v1beta1.PodNetworkInstance{ObjectMeta: meta, Spec: v1beta1.PodNetworkInstanceSpec{
+				PodNetworkConfigs: []v1beta1.PodNetworkConfig{{PodNetwork: "pn", PodIPReservationSize: 2}},
+			}},
+			restored: &PodNetworkInstance{ObjectMeta: meta, Spec: PodNetworkInstanceSpec{
+				PodNetwork:           "pn",
+				PodIPReservationSize: 2,
+				PodNetworkConfigs:    []PodNetworkConfig{{PodNetwork: "pn", PodIPReservationSize: 2}},
+			}},
+		},
+		{
+			name: "multiple podnetworks",
+			pni: &PodNetworkInstance{
+				ObjectMeta: meta,
+				Spec: PodNetworkInstanceSpec{
+					PodNetworkConfigs: []PodNetworkConfig{{PodNetwork: "pn1"}, {PodNetwork: "pn2", PodIPReservationSize: 1}},
+				},
+				Status: PodNetworkInstanceStatus{
+					Status:             PNIStatusReady,
+					PodNetworkStatuses: map[string]PNIStatus{"pn1": PNIStatusReady},
+				},
+			},
+			hub: &v1beta1.PodNetworkInstance{
+				ObjectMeta: meta,
+				Spec: v1beta1.PodNetworkInstanceSpec{
+					PodNetworkConfigs: []v1beta1.PodNetworkConfig{{PodNetwork: "pn1"}, {PodNetwork: "pn2", PodIPReservationSize: 1}},
+				},
+				Status: v1beta1.PodNetworkInstanceStatus{
+					Status:             v1beta1.PNIStatusReady,
+					PodNetworkStatuses: map[string]v1beta1.PNIStatus{"pn1": v1beta1.PNIStatusReady},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &v1beta1.PodNetworkInstance{}
+			require.NoError(t, tt.pni.ConvertTo(hub))
+			require.Equal(t, tt.hub, hub)
+
+			restored := &PodNetworkInstance{}
+			require.NoError(t, restored.ConvertFrom(hub))
+			want := tt.restored
+			if want == nil {
+				want = tt.pni
+			}
+			require.Equal(t, want, restored)
+		})
+	}
+}
+
+func TestPodNetworkConversion(t *testing.T) {
+	pn := &PodNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "pn"},
+		Spec:       PodNetworkSpec{VnetGUID: "vnet", SubnetGUID: "subnet", SubnetResourceID: "subnetID"},
+		Status:     PodNetworkStatus{Status: Ready, AddressPrefixes: []string{"10.0.0.0/24"}},
+	}
+	hub := &v1beta1.PodNetwork{}
+	require.NoError(t, pn.ConvertTo(hub))
+	require.Equal(t, "vnet", hub.Spec.NetworkID)
+
+	restored := &PodNetwork{}
+	require.NoError(t, restored.ConvertFrom(hub))
+	pn.Spec.NetworkID = "vnet"
+	require.Equal(t, pn, restored)
+
+	// the network of an infiniband NIC is not a vnet
+	hub.Spec.DeviceType = v1beta1.DeviceTypeInfiniBandNIC
+	require.NoError(t, restored.ConvertFrom(hub))
+	require.Empty(t, restored.Spec.VnetGUID)
+}
+
+func TestMultitenantPodNetworkConfigConversion(t *testing.T) {
+	mtpnc := &MultitenantPodNetworkConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Spec:       MultitenantPodNetworkConfigSpec{PodNetwork: "pn", PodName: "pod"},
+		Status: MultitenantPodNetworkConfigStatus{
+			NCID:       "nc",
+			PrimaryIP:  "10.0.0.1/32",
+			MacAddress: "00:00:00:00:00:01",
+			GatewayIP:  "10.0.0.254",
+		},
+	}
+	hub := &v1beta1.MultitenantPodNetworkConfig{}
+	require.NoError(t, mtpnc.ConvertTo(hub))
+	require.True(t, hub.IsReady())
+	require.Equal(t, []v1beta1.InterfaceInfo{{
+		NCID:       "nc",
+		PrimaryIP:  "10.0.0.1/32",
+		MacAddress: "00:00:00:00:00:01",
+		GatewayIP:  "10.0.0.254",
+		DeviceType: v1beta1.DeviceTypeVnetNIC,
+	}}, hub.Status.InterfaceInfos)
+
+	restored := &MultitenantPodNetworkConfig{}
+	require.NoError(t, restored.ConvertFrom(hub))
+	require.Equal(t, mtpnc.Status.PrimaryIP, restored.Status.PrimaryIP)
+	require.Len(t, restored.Status.InterfaceInfos, 1)
+	require.True(t, restored.IsReady())
+
+	// several interfaces are only in InterfaceInfos
+	hub.Status.InterfaceInfos = append(hub.Status.InterfaceInfos, v1beta1.InterfaceInfo{NCID: "ib", DeviceType: v1beta1.DeviceTypeInfiniBandNIC})
+	require.NoError(t, restored.ConvertFrom(hub))
+	require.Empty(t, restored.Status.NCID)
+	require.Len(t, restored.Status.InterfaceInfos, 2)
+}
+
+func TestNodeInfoConversion(t *testing.T) {
+	ni := &NodeInfo{ObjectMeta: metav1.ObjectMeta{Name: "node"}, Spec: NodeInfoSpec{VMUniqueID: "vm"}}
+	hub := &v1beta1.NodeInfo{}
+	require.NoError(t, ni.ConvertTo(hub))
+	restored := &NodeInfo{}
+	require.NoError(t, restored.ConvertFrom(hub))
+	require.Equal(t, ni, restored)
+}
+
@@ -13,6 +13,7 @@ import (
 
 // MultitenantPodNetworkConfig is the Schema for the multitenantpodnetworkconfigs API
 // +kubebuilder:resource:shortName=mtpnc,scope=Namespaced
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:metadata:labels=managed=
 // +kubebuilder:metadata:labels=owner=
@@ -0,0 +1,13 @@
+package v1beta1
+
+// Hub marks v1beta1 as the version the other MultitenantPodNetworkConfig versions are converted to and from.
+func (*MultitenantPodNetworkConfig) Hub() {}
+
+// Hub marks v1beta1 as the version the other NodeInfo versions are converted to and from.
+func (*NodeInfo) Hub() {}
+
+// Hub marks v1beta1 as the version the other PodNetwork versions are converted to and from.
+func (*PodNetwork) Hub() {}
+
+// Hub marks v1beta1 as the version the other PodNetworkInstance versions are converted to and from.
+func (*PodNetworkInstance) Hub() {}
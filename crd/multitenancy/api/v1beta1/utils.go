@@ -0,0 +1,18 @@
+package v1beta1
+
+// IsReady checks if all the required fields in the MTPNC status are populated
+func (m *MultitenantPodNetworkConfig) IsReady() bool {
+	if len(m.Status.InterfaceInfos) == 0 {
+		return false
+	}
+	for _, interfaceInfo := range m.Status.InterfaceInfos {
+		if interfaceInfo.NCID == "" ||
+			interfaceInfo.PrimaryIP == "" ||
+			interfaceInfo.MacAddress == "" ||
+			interfaceInfo.GatewayIP == "" ||
+			interfaceInfo.DeviceType == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/crd"
 	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1alpha1"
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1beta1"
 	"github.com/pkg/errors"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	typedv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
@@ -23,6 +24,7 @@ var Scheme = runtime.NewScheme()
 func init() {
 	_ = scheme.AddToScheme(Scheme)
 	_ = v1alpha1.AddToScheme(Scheme)
+	_ = v1beta1.AddToScheme(Scheme)
 }
 
 // Installer provides methods to manage the lifecycle of the custom resource definition.
@@ -74,6 +76,10 @@ func (i *Installer) InstallOrUpdateMTPNC(ctx context.Context) (*v1.CustomResourc
 		}
 	}
 	if !reflect.DeepEqual(mtpnc.Spec.Versions, current.Spec.Versions) {
+		// keep the conversion webhook configured in the cluster, the embedded definitions do not set one
+		if mtpnc.Spec.Conversion == nil {
+			mtpnc.Spec.Conversion = current.Spec.Conversion
+		}
 		mtpnc.SetResourceVersion(current.GetResourceVersion())
 		previous := *current
 		current, err = i.cli.Update(ctx, mtpnc, metav1.UpdateOptions{})
@@ -110,6 +116,10 @@ func (i *Installer) InstallOrUpdateNodeInfo(ctx context.Context) (*v1.CustomReso
 		}
 	}
 	if !reflect.DeepEqual(nodeinfo.Spec.Versions, current.Spec.Versions) {
+		// keep the conversion webhook configured in the cluster, the embedded definitions do not set one
+		if nodeinfo.Spec.Conversion == nil {
+			nodeinfo.Spec.Conversion = current.Spec.Conversion
+		}
 		nodeinfo.SetResourceVersion(current.GetResourceVersion())
 		previous := *current
 		current, err = i.cli.Update(ctx, nodeinfo, metav1.UpdateOptions{})
@@ -146,6 +156,10 @@ func (i *Installer) InstallOrUpdatePodNetwork(ctx context.Context) (*v1.CustomRe
 		}
 	}
 	if !reflect.DeepEqual(podNetwork.Spec.Versions, current.Spec.Versions) {
+		// keep the conversion webhook configured in the cluster, the embedded definitions do not set one
+		if podNetwork.Spec.Conversion == nil {
+			podNetwork.Spec.Conversion = current.Spec.Conversion
+		}
 		podNetwork.SetResourceVersion(current.GetResourceVersion())
 		previous := *current
 		current, err = i.cli.Update(ctx, podNetwork, metav1.UpdateOptions{})
@@ -182,6 +196,10 @@ func (i *Installer) InstallOrUpdatePodNetworkInstance(ctx context.Context) (*v1.
 		}
 	}
 	if !reflect.DeepEqual(podnetworkinstance.Spec.Versions, current.Spec.Versions) {
+		// keep the conversion webhook configured in the cluster, the embedded definitions do not set one
+		if podnetworkinstance.Spec.Conversion == nil {
+			podnetworkinstance.Spec.Conversion = current.Spec.Conversion
+		}
 		podnetworkinstance.SetResourceVersion(current.GetResourceVersion())
 		previous := *current
 		current, err = i.cli.Update(ctx, podnetworkinstance, metav1.UpdateOptions{})
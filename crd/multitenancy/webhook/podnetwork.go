@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1alpha1"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-multitenancy-acn-azure-com-v1alpha1-podnetwork,mutating=true,failurePolicy=fail,sideEffects=None,groups=multitenancy.acn.azure.com,resources=podnetworks,verbs=create;update,versions=v1alpha1,name=mpodnetwork.multitenancy.acn.azure.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-multitenancy-acn-azure-com-v1alpha1-podnetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=multitenancy.acn.azure.com,resources=podnetworks,verbs=create;update;delete,versions=v1alpha1,name=vpodnetwork.multitenancy.acn.azure.com,admissionReviewVersions=v1
+
+// PodNetworkWebhook defaults and validates PodNetworks. The PodNetworkInstances referencing a PodNetwork are looked
+// up with Client when it is deleted.
+type PodNetworkWebhook struct {
+	Client client.Reader
+}
+
+var (
+	_ webhook.CustomDefaulter = (*PodNetworkWebhook)(nil)
+	_ webhook.CustomValidator = (*PodNetworkWebhook)(nil)
+)
+
+var (
+	pnGroupKind     = v1alpha1.GroupVersion.WithKind("PodNetwork").GroupKind()
+	pnGroupResource = v1alpha1.GroupVersion.WithResource("podnetworks").GroupResource()
+)
+
+const subnetResourceType = "Microsoft.Network/virtualNetworks/subnets"
+
+// Default sets NetworkID from the deprecated VnetGUID, if it is not set.
+func (w *PodNetworkWebhook) Default(_ context.Context, obj runtime.Object) error {
+	pn, ok := obj.(*v1alpha1.PodNetwork)
+	if !ok {
+		return errors.Errorf("expected a PodNetwork but got %T", obj)
+	}
+
+	if pn.Spec.NetworkID == "" {
+		pn.Spec.NetworkID = pn.Spec.VnetGUID
+	}
+	return nil
+}
+
+// ValidateCreate validates the spec.
+func (w *PodNetworkWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pn, ok := obj.(*v1alpha1.PodNetwork)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetwork but got %T", obj)
+	}
+	return nil, toInvalid(pnGroupKind, pn.Name, validatePodNetworkSpec(&pn.Spec))
+}
+
+// ValidateUpdate validates the spec and that the network and subnet, once set, are not changed: the IPs of the pods
+// already attached to the PodNetwork were allocated from them.
+func (w *PodNetworkWebhook) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPN, ok := oldObj.(*v1alpha1.PodNetwork)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetwork but got %T", oldObj)
+	}
+	pn, ok := newObj.(*v1alpha1.PodNetwork)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetwork but got %T", newObj)
+	}
+
+	allErrs := validatePodNetworkSpec(&pn.Spec)
+	specPath := field.NewPath("spec")
+	for _, f := range []struct {
+		name     string
+		old, new string
+	}{
+		{"networkID", oldPN.Spec.NetworkID, pn.Spec.NetworkID},
+		{"subnetResourceID", oldPN.Spec.SubnetResourceID, pn.Spec.SubnetResourceID},
+		{"subnetGUID", oldPN.Spec.SubnetGUID, pn.Spec.SubnetGUID},
+	} {
+		if f.old != "" && !strings.EqualFold(f.old, f.new) {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child(f.name), "field is immutable once set"))
+		}
+	}
+	return nil, toInvalid(pnGroupKind, pn.Name, allErrs)
+}
+
+// ValidateDelete rejects the delete of a PodNetwork which is still referenced by PodNetworkInstances.
+func (w *PodNetworkWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pn, ok := obj.(*v1alpha1.PodNetwork)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetwork but got %T", obj)
+	}
+
+	var pnis v1alpha1.PodNetworkInstanceList
+	if err := w.Client.List(ctx, &pnis); err != nil {
+		return nil, errors.Wrap(err, "failed to list PodNetworkInstances")
+	}
+
+	var users []string
+	for i := range pnis.Items {
+		if podNetworkNames(&pnis.Items[i].Spec)[pn.Name] {
+			users = append(users, pnis.Items[i].Namespace+"/"+pnis.Items[i].Name)
+		}
+	}
+	if len(users) > 0 {
+		return nil, apierrors.NewForbidden(pnGroupResource, pn.Name,
+			errors.Errorf("it is referenced by PodNetworkInstances %s", strings.Join(users, ", ")))
+	}
+	return nil, nil
+}
+
+func validatePodNetworkSpec(spec *v1alpha1.PodNetworkSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if spec.VnetGUID != "" && spec.NetworkID != "" && !strings.EqualFold(spec.VnetGUID, spec.NetworkID) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("vnetGUID"), spec.VnetGUID, "must match spec.networkID"))
+	}
+
+	if spec.SubnetGUID != "" {
+		if _, err := uuid.Parse(spec.SubnetGUID); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("subnetGUID"), spec.SubnetGUID, "must be a GUID"))
+		}
+	}
+
+	if spec.SubnetResourceID != "" {
+		id, err := arm.ParseResourceID(spec.SubnetResourceID)
+		if err != nil || !strings.EqualFold(id.ResourceType.String(), subnetResourceType) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("subnetResourceID"), spec.SubnetResourceID,
+				"must be the resource ID of a "+subnetResourceType))
+		}
+	}
+	return allErrs
+}
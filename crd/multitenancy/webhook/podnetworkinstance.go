@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-multitenancy-acn-azure-com-v1alpha1-podnetworkinstance,mutating=true,failurePolicy=fail,sideEffects=None,groups=multitenancy.acn.azure.com,resources=podnetworkinstances,verbs=create;update,versions=v1alpha1,name=mpodnetworkinstance.multitenancy.acn.azure.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-multitenancy-acn-azure-com-v1alpha1-podnetworkinstance,mutating=false,failurePolicy=fail,sideEffects=None,groups=multitenancy.acn.azure.com,resources=podnetworkinstances,verbs=create;update,versions=v1alpha1,name=vpodnetworkinstance.multitenancy.acn.azure.com,admissionReviewVersions=v1
+
+// PodNetworkInstanceWebhook defaults and validates PodNetworkInstances. The PodNetworks they reference are looked up
+// with Client.
+type PodNetworkInstanceWebhook struct {
+	Client client.Reader
+}
+
+var (
+	_ webhook.CustomDefaulter = (*PodNetworkInstanceWebhook)(nil)
+	_ webhook.CustomValidator = (*PodNetworkInstanceWebhook)(nil)
+)
+
+var pniGroupKind = v1alpha1.GroupVersion.WithKind("PodNetworkInstance").GroupKind()
+
+// Default moves the deprecated PodNetwork and PodIPReservationSize to PodNetworkConfigs, if those are not set.
+func (w *PodNetworkInstanceWebhook) Default(_ context.Context, obj runtime.Object) error {
+	pni, ok := obj.(*v1alpha1.PodNetworkInstance)
+	if !ok {
+		return errors.Errorf("expected a PodNetworkInstance but got %T", obj)
+	}
+
+	if len(pni.Spec.PodNetworkConfigs) == 0 && pni.Spec.PodNetwork != "" {
+		pni.Spec.PodNetworkConfigs = []v1alpha1.PodNetworkConfig{{
+			PodNetwork:           pni.Spec.PodNetwork,
+			PodIPReservationSize: pni.Spec.PodIPReservationSize,
+		}}
+	}
+	return nil
+}
+
+// ValidateCreate validates the spec and that all the referenced PodNetworks exist.
+func (w *PodNetworkInstanceWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pni, ok := obj.(*v1alpha1.PodNetworkInstance)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetworkInstance but got %T", obj)
+	}
+
+	allErrs := validatePodNetworkInstanceSpec(&pni.Spec)
+	if len(allErrs) == 0 {
+		errs, err := w.validatePodNetworksExist(ctx, &pni.Spec, nil)
+		if err != nil {
+			return nil, err
+		}
+		allErrs = append(allErrs, errs...)
+	}
+	return nil, toInvalid(pniGroupKind, pni.Name, allErrs)
+}
+
+// ValidateUpdate validates the spec and that the PodNetworks added by the update exist. PodNetworks referenced
+// before the update are not looked up again, so that a PodNetworkInstance can still be updated once one of them
+// is gone.
+func (w *PodNetworkInstanceWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPNI, ok := oldObj.(*v1alpha1.PodNetworkInstance)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetworkInstance but got %T", oldObj)
+	}
+	pni, ok := newObj.(*v1alpha1.PodNetworkInstance)
+	if !ok {
+		return nil, errors.Errorf("expected a PodNetworkInstance but got %T", newObj)
+	}
+
+	allErrs := validatePodNetworkInstanceSpec(&pni.Spec)
+	if len(allErrs) == 0 {
+		errs, err := w.validatePodNetworksExist(ctx, &pni.Spec, podNetworkNames(&oldPNI.Spec))
+		if err != nil {
+			return nil, err
+		}
+		allErrs = append(allErrs, errs...)
+	}
+	return nil, toInvalid(pniGroupKind, pni.Name, allErrs)
+}
+
+// ValidateDelete allows all deletes.
+func (w *PodNetworkInstanceWebhook) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validatePodNetworkInstanceSpec(spec *v1alpha1.PodNetworkInstanceSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if spec.PodIPReservationSize < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("podIPReservationSize"), spec.PodIPReservationSize, "must be greater than or equal to 0"))
+	}
+
+	configsPath := specPath.Child("podNetworkConfigs")
+	if len(spec.PodNetworkConfigs) == 0 {
+		allErrs = append(allErrs, field.Required(configsPath, "at least one PodNetwork must be referenced"))
+	}
+
+	seen := map[string]bool{}
+	for i := range spec.PodNetworkConfigs {
+		config := &spec.PodNetworkConfigs[i]
+		path := configsPath.Index(i)
+		switch {
+		case config.PodNetwork == "":
+			allErrs = append(allErrs, field.Required(path.Child("podNetwork"), "the name of a PodNetwork is required"))
+		case seen[config.PodNetwork]:
+			allErrs = append(allErrs, field.Duplicate(path.Child("podNetwork"), config.PodNetwork))
+		}
+		seen[config.PodNetwork] = true
+
+		if config.PodIPReservationSize < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("podIPReservationSize"), config.PodIPReservationSize, "must be greater than or equal to 0"))
+		}
+	}
+
+	// the deprecated field is only kept for the consumers which have not moved to PodNetworkConfigs yet
+	if spec.PodNetwork != "" && len(spec.PodNetworkConfigs) > 0 && !seen[spec.PodNetwork] {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("podnetwork"), spec.PodNetwork, "must be one of the PodNetworks of spec.podNetworkConfigs"))
+	}
+	return allErrs
+}
+
+// validatePodNetworksExist looks up the PodNetworks of the spec, except the ones in skip.
+func (w *PodNetworkInstanceWebhook) validatePodNetworksExist(ctx context.Context, spec *v1alpha1.PodNetworkInstanceSpec, skip map[string]bool) (field.ErrorList, error) {
+	var allErrs field.ErrorList
+	for i := range spec.PodNetworkConfigs {
+		name := spec.PodNetworkConfigs[i].PodNetwork
+		if skip[name] {
+			continue
+		}
+
+		var pn v1alpha1.PodNetwork
+		if err := w.Client.Get(ctx, types.NamespacedName{Name: name}, &pn); err != nil {
+			if apierrors.IsNotFound(err) {
+				path := field.NewPath("spec", "podNetworkConfigs").Index(i).Child("podNetwork")
+				allErrs = append(allErrs, field.NotFound(path, name))
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get PodNetwork %s", name)
+		}
+	}
+	return allErrs, nil
+}
+
+func podNetworkNames(spec *v1alpha1.PodNetworkInstanceSpec) map[string]bool {
+	names := make(map[string]bool, len(spec.PodNetworkConfigs)+1)
+	for i := range spec.PodNetworkConfigs {
+		names[spec.PodNetworkConfigs[i].PodNetwork] = true
+	}
+	if spec.PodNetwork != "" {
+		names[spec.PodNetwork] = true
+	}
+	return names
+}
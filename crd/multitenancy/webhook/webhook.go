@@ -0,0 +1,43 @@
+// Package webhook contains the defaulting and validating admission webhooks of the multitenancy CRDs, which enforce
+// the invariants across fields and objects that the CRD schema cannot express.
+package webhook
+
+import (
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SetupWebhookWithManager registers the PodNetwork and PodNetworkInstance webhooks with the webhook server of the
+// manager. The manager scheme must contain the multitenancy v1alpha1 types.
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	pn := &PodNetworkWebhook{Client: mgr.GetClient()}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.PodNetwork{}).
+		WithDefaulter(pn).
+		WithValidator(pn).
+		Complete(); err != nil {
+		return errors.Wrap(err, "failed to set up PodNetwork webhook")
+	}
+
+	pni := &PodNetworkInstanceWebhook{Client: mgr.GetClient()}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.PodNetworkInstance{}).
+		WithDefaulter(pni).
+		WithValidator(pni).
+		Complete(); err != nil {
+		return errors.Wrap(err, "failed to set up PodNetworkInstance webhook")
+	}
+	return nil
+}
+
+// toInvalid returns an Invalid API error listing errs, or nil if there are none.
+func toInvalid(gk schema.GroupKind, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(gk, name, errs)
+}
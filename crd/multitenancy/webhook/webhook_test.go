@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/crd/multitenancy/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	subnetID   = "/subscriptions/9b8218f9-902a-4d20-a65c-e98acec5362f/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet"
+	subnetGUID = "5b6a3f36-3c0b-4a5f-9f5c-4e4e2d7e2b1c"
+	vnetGUID   = "0c6e2a3b-7a7e-4a36-9e2b-0b6f5e0f2c11"
+)
+
+func newClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func podNetwork(name string) *v1alpha1.PodNetwork {
+	return &v1alpha1.PodNetwork{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func pni(configs ...v1alpha1.PodNetworkConfig) *v1alpha1.PodNetworkInstance {
+	return &v1alpha1.PodNetworkInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "pni", Namespace: "default"},
+		Spec:       v1alpha1.PodNetworkInstanceSpec{PodNetworkConfigs: configs},
+	}
+}
+
+func TestPodNetworkInstanceDefault(t *testing.T) {
+	w := &PodNetworkInstanceWebhook{}
+	obj := &v1alpha1.PodNetworkInstance{Spec: v1alpha1.PodNetworkInstanceSpec{PodNetwork: "pn", PodIPReservationSize: 2}}
+	require.NoError(t, w.Default(context.Background(), obj))
+	require.Equal(t, []v1alpha1.PodNetworkConfig{{PodNetwork: "pn", PodIPReservationSize: 2}}, obj.Spec.PodNetworkConfigs)
+
+	// set configs are kept
+	obj = pni(v1alpha1.PodNetworkConfig{PodNetwork: "other"})
+	obj.Spec.PodNetwork = "pn"
+	require.NoError(t, w.Default(context.Background(), obj))
+	require.Equal(t, []v1alpha1.PodNetworkConfig{{PodNetwork: "other"}}, obj.Spec.PodNetworkConfigs)
+}
+
+func TestPodNetworkInstanceValidateCreate(t *testing.T) {
+	w := &PodNetworkInstanceWebhook{Client: newClient(t, podNetwork("pn1"), podNetwork("pn2"))}
+
+	tests := []struct {
+		name    string
+		pni     *v1alpha1.PodNetworkInstance
+		wantErr string
+	}{
+		{
+			name: "valid",
+			pni:  pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1", PodIPReservationSize: 1}, v1alpha1.PodNetworkConfig{PodNetwork: "pn2"}),
+		},
+		{
+			name:    "no podnetworks",
+			pni:     pni(),
+			wantErr: "spec.podNetworkConfigs: Required value",
+		},
+		{
+			name:    "negative reservation size",
+			pni:     pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1", PodIPReservationSize: -1}),
+			wantErr: "spec.podNetworkConfigs[0].podIPReservationSize: Invalid value: -1",
+		},
+		{
+			name:    "duplicate podnetwork",
+			pni:     pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1"}, v1alpha1.PodNetworkConfig{PodNetwork: "pn1"}),
+			wantErr: `spec.podNetworkConfigs[1].podNetwork: Duplicate value: "pn1"`,
+		},
+		{
+			name:    "missing podnetwork",
+			pni:     pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1"}, v1alpha1.PodNetworkConfig{PodNetwork: "missing"}),
+			wantErr: `spec.podNetworkConfigs[1].podNetwork: Not found: "missing"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := w.ValidateCreate(context.Background(), tt.pni)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), err)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPodNetworkInstanceValidateUpdate(t *testing.T) {
+	w := &PodNetworkInstanceWebhook{Client: newClient(t, podNetwork("pn2"))}
+
+	// pn1 is gone, but was already referenced
+	oldPNI := pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1"})
+	_, err := w.ValidateUpdate(context.Background(), oldPNI, pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1"}, v1alpha1.PodNetworkConfig{PodNetwork: "pn2"}))
+	require.NoError(t, err)
+
+	_, err = w.ValidateUpdate(context.Background(), oldPNI, pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1"}, v1alpha1.PodNetworkConfig{PodNetwork: "pn3"}))
+	require.ErrorContains(t, err, `Not found: "pn3"`)
+}
+
+func TestPodNetworkDefault(t *testing.T) {
+	pn := podNetwork("pn")
+	pn.Spec.VnetGUID = vnetGUID
+	require.NoError(t, (&PodNetworkWebhook{}).Default(context.Background(), pn))
+	require.Equal(t, vnetGUID, pn.Spec.NetworkID)
+}
+
+func TestPodNetworkValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1alpha1.PodNetworkSpec
+		wantErr string
+	}{
+		{
+			name: "valid",
+			spec: v1alpha1.PodNetworkSpec{NetworkID: vnetGUID, VnetGUID: vnetGUID, SubnetGUID: subnetGUID, SubnetResourceID: subnetID},
+		},
+		{
+			name:    "mismatched network",
+			spec:    v1alpha1.PodNetworkSpec{NetworkID: "other", VnetGUID: vnetGUID},
+			wantErr: "spec.vnetGUID",
+		},
+		{
+			name:    "invalid subnet GUID",
+			spec:    v1alpha1.PodNetworkSpec{SubnetGUID: "subnet"},
+			wantErr: "spec.subnetGUID",
+		},
+		{
+			name:    "invalid subnet resource ID",
+			spec:    v1alpha1.PodNetworkSpec{SubnetResourceID: "subnet"},
+			wantErr: "spec.subnetResourceID",
+		},
+		{
+			name:    "not a subnet",
+			spec:    v1alpha1.PodNetworkSpec{SubnetResourceID: "/subscriptions/9b8218f9-902a-4d20-a65c-e98acec5362f/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet"},
+			wantErr: "spec.subnetResourceID",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pn := podNetwork("pn")
+			pn.Spec = tt.spec
+			_, err := (&PodNetworkWebhook{}).ValidateCreate(context.Background(), pn)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), err)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPodNetworkValidateUpdate(t *testing.T) {
+	oldPN := podNetwork("pn")
+	oldPN.Spec.SubnetResourceID = subnetID
+	pn := oldPN.DeepCopy()
+	pn.Spec.SubnetGUID = subnetGUID
+	_, err := (&PodNetworkWebhook{}).ValidateUpdate(context.Background(), oldPN, pn)
+	require.NoError(t, err)
+
+	pn.Spec.SubnetResourceID = subnetID + "2"
+	_, err = (&PodNetworkWebhook{}).ValidateUpdate(context.Background(), oldPN, pn)
+	require.ErrorContains(t, err, "spec.subnetResourceID: Forbidden")
+}
+
+func TestPodNetworkValidateDelete(t *testing.T) {
+	w := &PodNetworkWebhook{Client: newClient(t, pni(v1alpha1.PodNetworkConfig{PodNetwork: "pn1"}))}
+
+	_, err := w.ValidateDelete(context.Background(), podNetwork("pn1"))
+	require.True(t, apierrors.IsForbidden(err), err)
+	require.ErrorContains(t, err, "default/pni")
+
+	_, err = w.ValidateDelete(context.Background(), podNetwork("pn2"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+package v1alpha
+
+import (
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1beta1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = (*NodeNetworkConfig)(nil)
+
+// ConvertTo converts the NodeNetworkConfig to the v1beta1 hub version.
+func (src *NodeNetworkConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.NodeNetworkConfig)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.NodeNetworkConfigSpec{
+		RequestedIPCount: src.Spec.RequestedIPCount,
+		IPsNotInUse:      src.Spec.IPsNotInUse,
+	}
+	dst.Status = v1beta1.NodeNetworkConfigStatus{
+		AssignedIPCount: src.Status.AssignedIPCount,
+		Scaler:          v1beta1.Scaler(src.Status.Scaler),
+		Status:          v1beta1.Status(src.Status.Status),
+	}
+	for i := range src.Status.NetworkContainers {
+		nc := &src.Status.NetworkContainers[i]
+		dstNC := v1beta1.NetworkContainer{
+			ID:                 nc.ID,
+			AssignmentMode:     v1beta1.AssignmentMode(nc.AssignmentMode),
+			Type:               v1beta1.NCType(nc.Type),
+			PrimaryIP:          nc.PrimaryIP,
+			SubnetName:         nc.SubnetName,
+			DefaultGateway:     nc.DefaultGateway,
+			SubnetAddressSpace: nc.SubnetAddressSpace,
+			Version:            nc.Version,
+			NodeIP:             nc.NodeIP,
+			SubscriptionID:     nc.SubscriptionID,
+			ResourceGroupID:    nc.ResourceGroupID,
+			VNETID:             nc.VNETID,
+			SubnetID:           nc.SubnetID,
+			Status:             v1beta1.NCStatus(nc.Status),
+		}
+		for _, ip := range nc.IPAssignments {
+			dstNC.IPAssignments = append(dstNC.IPAssignments, v1beta1.IPAssignment(ip))
+		}
+		dst.Status.NetworkContainers = append(dst.Status.NetworkContainers, dstNC)
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this NodeNetworkConfig.
+func (dst *NodeNetworkConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.NodeNetworkConfig)
+	if !ok {
+		return errors.Errorf("unexpected hub type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = NodeNetworkConfigSpec{
+		RequestedIPCount: src.Spec.RequestedIPCount,
+		IPsNotInUse:      src.Spec.IPsNotInUse,
+	}
+	dst.Status = NodeNetworkConfigStatus{
+		AssignedIPCount: src.Status.AssignedIPCount,
+		Scaler:          Scaler(src.Status.Scaler),
+		Status:          Status(src.Status.Status),
+	}
+	for i := range src.Status.NetworkContainers {
+		nc := &src.Status.NetworkContainers[i]
+		dstNC := NetworkContainer{
+			ID:                 nc.ID,
+			AssignmentMode:     AssignmentMode(nc.AssignmentMode),
+			Type:               NCType(nc.Type),
+			PrimaryIP:          nc.PrimaryIP,
+			SubnetName:         nc.SubnetName,
+			DefaultGateway:     nc.DefaultGateway,
+			SubnetAddressSpace: nc.SubnetAddressSpace,
+			Version:            nc.Version,
+			NodeIP:             nc.NodeIP,
+			SubscriptionID:     nc.SubscriptionID,
+			ResourceGroupID:    nc.ResourceGroupID,
+			VNETID:             nc.VNETID,
+			SubnetID:           nc.SubnetID,
+			Status:             NCStatus(nc.Status),
+		}
+		for _, ip := range nc.IPAssignments {
+			dstNC.IPAssignments = append(dstNC.IPAssignments, IPAssignment(ip))
+		}
+		dst.Status.NetworkContainers = append(dst.Status.NetworkContainers, dstNC)
+	}
+	return nil
+}
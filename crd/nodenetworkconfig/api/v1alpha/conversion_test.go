@@ -0,0 +1,46 @@
+package v1alpha
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+func TestConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	ok, err := conversion.IsConvertible(scheme, &NodeNetworkConfig{})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	nnc := &NodeNetworkConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "kube-system"},
+		Spec:       NodeNetworkConfigSpec{RequestedIPCount: 16, IPsNotInUse: []string{"10.0.0.2"}},
+		Status: NodeNetworkConfigStatus{
+			AssignedIPCount: 16,
+			Scaler:          Scaler{BatchSize: 16, MaxIPCount: 250},
+			Status:          Updated,
+			NetworkContainers: []NetworkContainer{{
+				ID:             "nc",
+				AssignmentMode: Dynamic,
+				Type:           Overlay,
+				PrimaryIP:      "10.0.0.1",
+				IPAssignments:  []IPAssignment{{Name: "ip", IP: "10.0.0.3"}},
+				Version:        1,
+				Status:         NCUpdateSuccess,
+			}},
+		},
+	}
+	hub := &v1beta1.NodeNetworkConfig{}
+	require.NoError(t, nnc.ConvertTo(hub))
+	require.Equal(t, "10.0.0.3", hub.Status.NetworkContainers[0].IPAssignments[0].IP)
+
+	restored := &NodeNetworkConfig{}
+	require.NoError(t, restored.ConvertFrom(hub))
+	require.Equal(t, nnc, restored)
+}
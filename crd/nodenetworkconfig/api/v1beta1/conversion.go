@@ -0,0 +1,4 @@
+package v1beta1
+
+// Hub marks v1beta1 as the version the other NodeNetworkConfig versions are converted to and from.
+func (*NodeNetworkConfig) Hub() {}
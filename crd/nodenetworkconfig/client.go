@@ -6,6 +6,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/crd"
 	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1beta1"
 	"github.com/pkg/errors"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	typedv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
@@ -25,6 +26,7 @@ var Scheme = runtime.NewScheme()
 func init() {
 	_ = scheme.AddToScheme(Scheme)
 	_ = v1alpha.AddToScheme(Scheme)
+	_ = v1beta1.AddToScheme(Scheme)
 }
 
 // Installer provides methods to manage the lifecycle of the NodeNetworkConfig resource definition.
@@ -76,6 +78,10 @@ func (i *Installer) InstallOrUpdate(ctx context.Context) (*v1.CustomResourceDefi
 		}
 	}
 	if !reflect.DeepEqual(nnc.Spec.Versions, current.Spec.Versions) {
+		// keep the conversion webhook configured in the cluster, the embedded definitions do not set one
+		if nnc.Spec.Conversion == nil {
+			nnc.Spec.Conversion = current.Spec.Conversion
+		}
 		nnc.SetResourceVersion(current.GetResourceVersion())
 		previous := *current
 		current, err = i.cli.Update(ctx, nnc, metav1.UpdateOptions{})
@@ -0,0 +1,91 @@
+// Package webhook contains the validating admission webhook of the NodeNetworkConfig CRD.
+package webhook
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-acn-azure-com-v1alpha-nodenetworkconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=acn.azure.com,resources=nodenetworkconfigs,verbs=create;update,versions=v1alpha,name=vnodenetworkconfig.acn.azure.com,admissionReviewVersions=v1
+
+// NodeNetworkConfigWebhook validates the spec of NodeNetworkConfigs, which is written by CNS.
+type NodeNetworkConfigWebhook struct{}
+
+var _ webhook.CustomValidator = (*NodeNetworkConfigWebhook)(nil)
+
+var nncGroupKind = v1alpha.GroupVersion.WithKind("NodeNetworkConfig").GroupKind()
+
+// SetupWebhookWithManager registers the NodeNetworkConfig webhook with the webhook server of the manager. The
+// manager scheme must contain the v1alpha types.
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha.NodeNetworkConfig{}).
+		WithValidator(&NodeNetworkConfigWebhook{}).
+		Complete()
+	return errors.Wrap(err, "failed to set up NodeNetworkConfig webhook")
+}
+
+// ValidateCreate validates the spec.
+func (w *NodeNetworkConfigWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(obj)
+}
+
+// ValidateUpdate validates the spec of the updated object.
+func (w *NodeNetworkConfigWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(newObj)
+}
+
+// ValidateDelete allows all deletes.
+func (w *NodeNetworkConfigWebhook) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *NodeNetworkConfigWebhook) validate(obj runtime.Object) (admission.Warnings, error) {
+	nnc, ok := obj.(*v1alpha.NodeNetworkConfig)
+	if !ok {
+		return nil, errors.Errorf("expected a NodeNetworkConfig but got %T", obj)
+	}
+
+	allErrs := validateSpec(&nnc.Spec, &nnc.Status)
+	if len(allErrs) == 0 {
+		return nil, nil
+	}
+	return nil, apierrors.NewInvalid(nncGroupKind, nnc.Name, allErrs)
+}
+
+func validateSpec(spec *v1alpha.NodeNetworkConfigSpec, status *v1alpha.NodeNetworkConfigStatus) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	countPath := specPath.Child("requestedIPCount")
+	if spec.RequestedIPCount < 0 {
+		allErrs = append(allErrs, field.Invalid(countPath, spec.RequestedIPCount, "must be greater than or equal to 0"))
+	}
+	// the max is only known once DNC-RC has reconciled the NNC
+	if maxIPs := status.Scaler.MaxIPCount; maxIPs > 0 && spec.RequestedIPCount > maxIPs {
+		allErrs = append(allErrs, field.Invalid(countPath, spec.RequestedIPCount, "must not be greater than status.scaler.maxIPCount"))
+	}
+
+	ipsPath := specPath.Child("ipsNotInUse")
+	seen := make(map[netip.Addr]bool, len(spec.IPsNotInUse))
+	for i, ip := range spec.IPsNotInUse {
+		addr, err := netip.ParseAddr(ip)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(ipsPath.Index(i), ip, "must be an IP address"))
+		case seen[addr]:
+			allErrs = append(allErrs, field.Duplicate(ipsPath.Index(i), ip))
+		}
+		seen[addr] = true
+	}
+	return allErrs
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/crd/nodenetworkconfig/api/v1alpha"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1alpha.NodeNetworkConfigSpec
+		max     int64
+		wantErr string
+	}{
+		{
+			name: "valid",
+			spec: v1alpha.NodeNetworkConfigSpec{RequestedIPCount: 16, IPsNotInUse: []string{"10.0.0.1", "fd00::1"}},
+			max:  250,
+		},
+		{
+			name: "max not reconciled yet",
+			spec: v1alpha.NodeNetworkConfigSpec{RequestedIPCount: 500},
+		},
+		{
+			name:    "negative request",
+			spec:    v1alpha.NodeNetworkConfigSpec{RequestedIPCount: -1},
+			wantErr: "spec.requestedIPCount: Invalid value: -1",
+		},
+		{
+			name:    "request over max",
+			spec:    v1alpha.NodeNetworkConfigSpec{RequestedIPCount: 300},
+			max:     250,
+			wantErr: "must not be greater than status.scaler.maxIPCount",
+		},
+		{
+			name:    "invalid IP",
+			spec:    v1alpha.NodeNetworkConfigSpec{IPsNotInUse: []string{"10.0.0.1", "10.0.0.256"}},
+			wantErr: `spec.ipsNotInUse[1]: Invalid value: "10.0.0.256"`,
+		},
+		{
+			name:    "duplicate IP",
+			spec:    v1alpha.NodeNetworkConfigSpec{IPsNotInUse: []string{"10.0.0.1", "10.0.0.1"}},
+			wantErr: `spec.ipsNotInUse[1]: Duplicate value: "10.0.0.1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nnc := &v1alpha.NodeNetworkConfig{Spec: tt.spec}
+			nnc.Name = "node"
+			nnc.Status.Scaler.MaxIPCount = tt.max
+			_, err := (&NodeNetworkConfigWebhook{}).ValidateUpdate(context.Background(), nnc, nnc)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), err)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
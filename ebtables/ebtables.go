@@ -8,9 +8,14 @@ import (
 	"net"
 	"strings"
 
+	"github.com/Azure/azure-container-networking/cni/log"
 	"github.com/Azure/azure-container-networking/platform"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
+var logger = log.CNILogger.With(zap.String("component", "cni-ebtables"))
+
 const (
 	// Ebtable actions.
 	Append = "-A"
@@ -32,6 +37,12 @@ const (
 	RedirectAccept = "redirect --redirect-target ACCEPT"
 )
 
+// EnableNFTables programs the rules with nftables over netlink instead of running the ebtables binary.
+// The ebtables binary is still used if the kernel does not support nftables.
+var EnableNFTables bool
+
+var errNFTablesUnavailable = errors.New("nftables is unavailable")
+
 // SetSnatForInterface sets a MAC SNAT rule for an interface.
 func SetSnatForInterface(interfaceName string, macAddress net.HardwareAddr, action string) error {
 	table := Nat
@@ -129,6 +140,14 @@ func GetEbtableRules(tableName, chainName string) ([]string, error) {
 		inChain bool
 		rules   []string
 	)
+	if EnableNFTables {
+		rules, err := getNFTRules(tableName, chainName)
+		if !errors.Is(err, errNFTablesUnavailable) {
+			return rules, err
+		}
+		logger.Warn("Falling back to ebtables", zap.Error(err))
+	}
+
 	p := platform.NewExecClient(nil)
 	command := fmt.Sprintf(
 		"ebtables -t %s -L %s --Lmac2",
@@ -226,6 +245,14 @@ func EbTableRuleExists(tableName, chainName, matchSet string) (bool, error) {
 
 // runEbCmd runs an EB rule command.
 func runEbCmd(table, action, chain, rule string) error {
+	if EnableNFTables {
+		err := runNFTCmd(table, action, chain, rule)
+		if !errors.Is(err, errNFTablesUnavailable) {
+			return err
+		}
+		logger.Warn("Falling back to ebtables", zap.Error(err))
+	}
+
 	p := platform.NewExecClient(nil)
 	command := fmt.Sprintf("ebtables -t %s %s %s %s", table, action, chain, rule)
 	_, err := p.ExecuteCommand(command)
@@ -0,0 +1,525 @@
+package ebtables
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/google/nftables/userdata"
+	"github.com/google/nftables/xt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+// The nftables backend programs the ebtables rules in the bridge family tables ebtables-nft uses. The arpreply,
+// snat, dnat and redirect targets have no native nftables equivalent and are programmed as the ebtables
+// extensions through nft_compat, as ebtables-nft does. The ebtables rule spec is kept as the comment of the rule.
+
+var (
+	errUnsupportedRule = errors.New("unsupported ebtables rule")
+	errRuleNotFound    = errors.New("rule not found")
+)
+
+const regData = 1
+
+// NFT_META_BRI_BROUTE, the meta key of the broute decision of a bridge prerouting chain.
+const metaKeyBRIBroute expr.MetaKey = 36
+
+// ebtables verdicts of the extension targets, from linux/netfilter_bridge/ebtables.h.
+const (
+	ebtAccept   int32 = -1
+	ebtDrop     int32 = -2
+	ebtContinue int32 = -3
+	ebtReturn   int32 = -4
+	// ebtNATARPBit of the snat target verdict is cleared to also rewrite the sender address of ARP packets.
+	ebtNATARPBit int32 = 0x10
+)
+
+var ebtVerdicts = map[string]int32{
+	"ACCEPT":   ebtAccept,
+	"DROP":     ebtDrop,
+	"CONTINUE": ebtContinue,
+	"RETURN":   ebtReturn,
+}
+
+var etherTypes = map[string]uint16{
+	"ARP":    unix.ETH_P_ARP,
+	"IPV4":   unix.ETH_P_IP,
+	"IPV6":   unix.ETH_P_IPV6,
+	"802_1Q": unix.ETH_P_8021Q,
+}
+
+var arpOps = map[string]uint16{
+	"Request": 1,
+	"Reply":   2,
+}
+
+var ip6Protocols = map[string]byte{
+	"tcp":       unix.IPPROTO_TCP,
+	"udp":       unix.IPPROTO_UDP,
+	"ipv6-icmp": unix.IPPROTO_ICMPV6,
+	"icmpv6":    unix.IPPROTO_ICMPV6,
+}
+
+var icmp6Types = map[string]byte{
+	"router-solicitation":     133,
+	"router-advertisement":    134,
+	"neighbour-solicitation":  135,
+	"neighbour-advertisement": 136,
+}
+
+// the priorities of the ebtables hooks, from linux/netfilter_bridge.h. broute runs before all of them.
+const (
+	brPriBroute        = -400
+	brPriNATDstBridged = -300
+	brPriFilterBridged = -200
+	brPriNATDstOther   = 100
+	brPriFilterOther   = 200
+	brPriNATSrc        = 300
+)
+
+type baseChain struct {
+	hook     *nftables.ChainHook
+	priority nftables.ChainPriority
+}
+
+// baseChains are the built-in chains of the ebtables tables, as registered by ebtables-nft.
+var baseChains = map[string]map[string]baseChain{
+	Filter: {
+		"INPUT":  {nftables.ChainHookInput, brPriFilterBridged},
+		Forward:  {nftables.ChainHookForward, brPriFilterBridged},
+		"OUTPUT": {nftables.ChainHookOutput, brPriFilterOther},
+	},
+	Nat: {
+		PreRouting:  {nftables.ChainHookPrerouting, brPriNATDstBridged},
+		"OUTPUT":    {nftables.ChainHookOutput, brPriNATDstOther},
+		PostRouting: {nftables.ChainHookPostrouting, brPriNATSrc},
+	},
+	Broute: {
+		Brouting: {nftables.ChainHookPrerouting, brPriBroute},
+	},
+}
+
+var (
+	nftOnce sync.Once
+	nft     *nftClient
+	nftErr  error
+)
+
+func defaultNFTClient() (*nftClient, error) {
+	nftOnce.Do(func() {
+		nft, nftErr = newNFTClient()
+	})
+	return nft, nftErr
+}
+
+// runNFTCmd runs the ebtables command with the nftables backend. errNFTablesUnavailable is returned if the
+// kernel does not support nftables.
+func runNFTCmd(table, action, chain, rule string) error {
+	c, err := defaultNFTClient()
+	if err != nil {
+		return err
+	}
+	return c.run(table, action, chain, rule)
+}
+
+// getNFTRules returns the rules of the chain programmed with the nftables backend.
+func getNFTRules(table, chain string) ([]string, error) {
+	c, err := defaultNFTClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.rules(table, chain)
+}
+
+// nftClient runs ebtables commands as nftables transactions.
+type nftClient struct {
+	conn *nftables.Conn
+}
+
+func newNFTClient(opts ...nftables.ConnOption) (*nftClient, error) {
+	conn, err := nftables.New(opts...)
+	if err != nil {
+		return nil, errors.Wrapf(errNFTablesUnavailable, "failed to open nftables connection: %v", err)
+	}
+	if _, err := conn.ListTablesOfFamily(nftables.TableFamilyBridge); err != nil {
+		return nil, errors.Wrapf(errNFTablesUnavailable, "failed to list nftables tables: %v", err)
+	}
+	return &nftClient{conn: conn}, nil
+}
+
+// run runs the ebtables command as one nftables transaction.
+func (c *nftClient) run(tableName, action, chainName, rule string) error {
+	spec := canonicalRule(rule)
+	logger.Info("Running nftables command", zap.String("table", tableName), zap.String("action", action),
+		zap.String("chain", chainName), zap.String("rule", spec))
+
+	table := &nftables.Table{Name: tableName, Family: nftables.TableFamilyBridge}
+	switch action {
+	case Append:
+		base, ok := baseChains[tableName][chainName]
+		if !ok {
+			return errors.Wrapf(errUnsupportedRule, "chain %s in table %s", chainName, tableName)
+		}
+		exprs, err := ruleExprs(tableName, rule)
+		if err != nil {
+			return err
+		}
+		// the chain is created with its first rule, adding an existing chain again would reset its policy.
+		chain, err := c.conn.ListChain(table, chainName)
+		if err != nil {
+			policy := nftables.ChainPolicyAccept
+			c.conn.AddTable(table)
+			chain = c.conn.AddChain(&nftables.Chain{
+				Name:     chainName,
+				Table:    table,
+				Hooknum:  base.hook,
+				Priority: nftables.ChainPriorityRef(base.priority),
+				Type:     nftables.ChainTypeFilter,
+				Policy:   &policy,
+			})
+		}
+		c.conn.AddRule(&nftables.Rule{
+			Table:    table,
+			Chain:    chain,
+			Exprs:    exprs,
+			UserData: userdata.AppendString(nil, userdata.TypeComment, spec),
+		})
+	case Delete:
+		rules, err := c.chainRules(table, chainName)
+		if err != nil {
+			return err
+		}
+		var found *nftables.Rule
+		for _, r := range rules {
+			if comment, ok := userdata.GetString(r.UserData, userdata.TypeComment); ok && comment == spec {
+				found = r
+				break
+			}
+		}
+		if found == nil {
+			return errors.Wrapf(errRuleNotFound, "%s in chain %s", spec, chainName)
+		}
+		if err := c.conn.DelRule(found); err != nil {
+			return errors.Wrap(err, "failed to delete rule")
+		}
+	default:
+		return errors.Wrapf(errUnsupportedRule, "action %s", action)
+	}
+
+	return errors.Wrapf(c.conn.Flush(), "failed to apply nftables command %s %s %s", action, chainName, spec)
+}
+
+// rules returns the specs of the rules of the chain, a missing chain has none.
+func (c *nftClient) rules(tableName, chainName string) ([]string, error) {
+	rules, err := c.chainRules(&nftables.Table{Name: tableName, Family: nftables.TableFamilyBridge}, chainName)
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]string, 0, len(rules))
+	for _, r := range rules {
+		if comment, ok := userdata.GetString(r.UserData, userdata.TypeComment); ok {
+			specs = append(specs, comment)
+		}
+	}
+	return specs, nil
+}
+
+func (c *nftClient) chainRules(table *nftables.Table, chainName string) ([]*nftables.Rule, error) {
+	chain, err := c.conn.ListChain(table, chainName)
+	if err != nil {
+		// the chain is created with its first rule.
+		return nil, nil //nolint:nilerr // a missing chain has no rules
+	}
+	rules, err := c.conn.GetRules(table, chain)
+	return rules, errors.Wrapf(err, "failed to list rules of chain %s", chainName)
+}
+
+// canonicalRule orders the rule the way ebtables lists it, with the protocol first and without the target
+// options which are the default, so the specs compare equal to the ones listed by ebtables.
+func canonicalRule(rule string) string {
+	fields := strings.Fields(rule)
+	var protocol, rest []string
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case (fields[i] == "-p" || fields[i] == "--protocol") && i+1 < len(fields):
+			protocol = append(protocol, "-p", fields[i+1])
+			i++
+		case strings.HasSuffix(fields[i], "-target") && i+1 < len(fields) && fields[i+1] == "ACCEPT":
+			i++
+		default:
+			rest = append(rest, fields[i])
+		}
+	}
+	return strings.Join(append(protocol, rest...), " ")
+}
+
+// ruleExprs translates an ebtables rule to nftables expressions. Only the matches and targets used by CNI are
+// supported.
+func ruleExprs(table, rule string) ([]expr.Any, error) {
+	var (
+		exprs  []expr.Any
+		negate bool
+	)
+	fields := strings.Fields(rule)
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f == "!" {
+			negate = true
+			continue
+		}
+		if f == "-j" || f == "--jump" {
+			target, err := targetExprs(table, fields[i+1:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %s", rule)
+			}
+			return append(exprs, target...), nil
+		}
+		if i+1 == len(fields) {
+			return nil, errors.Wrapf(errUnsupportedRule, "%s needs a value: %s", f, rule)
+		}
+		i++
+		value := fields[i]
+		op := expr.CmpOpEq
+		if negate {
+			op = expr.CmpOpNeq
+		}
+
+		var (
+			match []expr.Any
+			err   error
+		)
+		switch f {
+		case "-p", "--protocol":
+			etherType, ok := etherTypes[strings.ToUpper(value)]
+			if !ok {
+				return nil, errors.Wrapf(errUnsupportedRule, "protocol %s: %s", value, rule)
+			}
+			match = payloadExprs(expr.PayloadBaseLLHeader, 12, binaryutil.BigEndian.PutUint16(etherType), op) //nolint:gomnd // ether type
+		case "-i", "--in-interface", "-o", "--out-interface":
+			key := expr.MetaKeyIIFNAME
+			if f == "-o" || f == "--out-interface" {
+				key = expr.MetaKeyOIFNAME
+			}
+			match = ifNameExprs(key, value, op)
+		case "-s", "--source", "-d", "--destination":
+			var offset uint32 // ether daddr
+			if f == "-s" || f == "--source" {
+				offset = 6 // ether saddr
+			}
+			match, err = macExprs(offset, value, op)
+		case "--arp-op":
+			arpOp, ok := arpOps[value]
+			if !ok {
+				return nil, errors.Wrapf(errUnsupportedRule, "arp op %s: %s", value, rule)
+			}
+			match = payloadExprs(expr.PayloadBaseNetworkHeader, 6, binaryutil.BigEndian.PutUint16(arpOp), op) //nolint:gomnd // arp op
+		case "--arp-ip-src":
+			match, err = ipExprs(14, value, op, false) //nolint:gomnd // arp spa
+		case "--arp-ip-dst":
+			match, err = ipExprs(24, value, op, false) //nolint:gomnd // arp tpa
+		case "--ip-src", "--ip-source":
+			match, err = ipExprs(12, value, op, false) //nolint:gomnd // ip saddr
+		case "--ip-dst", "--ip-destination":
+			match, err = ipExprs(16, value, op, false) //nolint:gomnd // ip daddr
+		case "--ip6-src", "--ip6-source":
+			match, err = ipExprs(8, value, op, true) //nolint:gomnd // ip6 saddr
+		case "--ip6-dst", "--ip6-destination":
+			match, err = ipExprs(24, value, op, true) //nolint:gomnd // ip6 daddr
+		case "--ip6-proto", "--ip6-protocol":
+			proto, ok := ip6Protocols[value]
+			if !ok {
+				return nil, errors.Wrapf(errUnsupportedRule, "ip6 protocol %s: %s", value, rule)
+			}
+			match = payloadExprs(expr.PayloadBaseNetworkHeader, 6, []byte{proto}, op) //nolint:gomnd // ip6 nexthdr
+		case "--ip6-icmp-type":
+			icmpType, ok := icmp6Types[value]
+			if !ok {
+				return nil, errors.Wrapf(errUnsupportedRule, "icmpv6 type %s: %s", value, rule)
+			}
+			match = payloadExprs(expr.PayloadBaseTransportHeader, 0, []byte{icmpType}, op)
+		default:
+			return nil, errors.Wrapf(errUnsupportedRule, "option %s: %s", f, rule)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %s", rule)
+		}
+		exprs = append(exprs, match...)
+		negate = false
+	}
+
+	return nil, errors.Wrapf(errUnsupportedRule, "no target: %s", rule)
+}
+
+// targetExprs translates the target of an ebtables rule, starting with its name.
+func targetExprs(table string, fields []string) ([]expr.Any, error) {
+	if len(fields) == 0 {
+		return nil, errors.Wrap(errUnsupportedRule, "empty target")
+	}
+	target := fields[0]
+	options := map[string]string{}
+	flags := map[string]bool{}
+	for i := 1; i < len(fields); i++ {
+		if i+1 < len(fields) && !strings.HasPrefix(fields[i+1], "-") {
+			options[fields[i]] = fields[i+1]
+			i++
+		} else {
+			flags[fields[i]] = true
+		}
+	}
+	verdict := func(option string) (int32, error) {
+		v, ok := options[option]
+		if !ok {
+			return ebtAccept, nil
+		}
+		if verdict, ok := ebtVerdicts[v]; ok {
+			return verdict, nil
+		}
+		return 0, errors.Wrapf(errUnsupportedRule, "%s %s", option, v)
+	}
+
+	// in the broute table ACCEPT routes the frame instead of bridging it.
+	var broute []expr.Any
+	if table == Broute {
+		broute = []expr.Any{
+			&expr.Immediate{Register: regData, Data: []byte{1}},
+			&expr.Meta{Key: metaKeyBRIBroute, SourceRegister: true, Register: regData},
+		}
+	}
+
+	switch target {
+	case "ACCEPT":
+		return append(broute, &expr.Verdict{Kind: expr.VerdictAccept}), nil
+	case "DROP":
+		if table == Broute {
+			// DROP bridges the frame.
+			return []expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}, nil
+		}
+		return []expr.Any{&expr.Verdict{Kind: expr.VerdictDrop}}, nil
+	case "RETURN":
+		return []expr.Any{&expr.Verdict{Kind: expr.VerdictReturn}}, nil
+	case "arpreply":
+		mac, err := net.ParseMAC(options["--arpreply-mac"])
+		if err != nil {
+			return nil, errors.Wrapf(errUnsupportedRule, "arpreply mac %s", options["--arpreply-mac"])
+		}
+		v, err := verdict("--arpreply-target")
+		if err != nil {
+			return nil, err
+		}
+		return []expr.Any{compatTarget(target, natInfo(mac, v))}, nil
+	case "snat", "dnat":
+		option := "--to-src"
+		if target == "dnat" {
+			option = "--to-dst"
+		}
+		mac, err := net.ParseMAC(options[option])
+		if err != nil {
+			return nil, errors.Wrapf(errUnsupportedRule, "%s %s", option, options[option])
+		}
+		v, err := verdict("--" + target + "-target")
+		if err != nil {
+			return nil, err
+		}
+		if flags["--snat-arp"] {
+			v ^= ebtNATARPBit
+		}
+		return []expr.Any{compatTarget(target, natInfo(mac, v))}, nil
+	case "redirect":
+		v, err := verdict("--redirect-target")
+		if err != nil {
+			return nil, err
+		}
+		if v != ebtAccept {
+			broute = nil
+		}
+		// ebt_redirect_info, padded to 8 bytes.
+		info := append(binaryutil.NativeEndian.PutUint32(uint32(v)), make([]byte, 4)...)
+		return append(broute, compatTarget(target, info)), nil
+	}
+
+	return nil, errors.Wrapf(errUnsupportedRule, "target %s", strings.Join(fields, " "))
+}
+
+// compatTarget programs an ebtables extension target through nft_compat.
+func compatTarget(name string, info []byte) *expr.Target {
+	i := xt.Unknown(info)
+	return &expr.Target{Name: name, Info: &i}
+}
+
+// natInfo is the ebt_nat_info and ebt_arpreply_info of the snat, dnat and arpreply targets, padded to 8 bytes.
+func natInfo(mac net.HardwareAddr, verdict int32) []byte {
+	info := make([]byte, 8, 16) //nolint:gomnd // the mac is padded to the alignment of the verdict
+	copy(info, mac)
+	return append(append(info, binaryutil.NativeEndian.PutUint32(uint32(verdict))...), make([]byte, 4)...)
+}
+
+func payloadExprs(base expr.PayloadBase, offset uint32, data []byte, op expr.CmpOp) []expr.Any {
+	return []expr.Any{
+		&expr.Payload{DestRegister: regData, Base: base, Offset: offset, Len: uint32(len(data))},
+		&expr.Cmp{Op: op, Register: regData, Data: data},
+	}
+}
+
+// ipExprs matches the address at the offset of the network header against an address or prefix.
+func ipExprs(offset uint32, value string, op expr.CmpOp, ipv6 bool) ([]expr.Any, error) {
+	if !strings.Contains(value, "/") {
+		bits := 32
+		if ipv6 {
+			bits = 128
+		}
+		value += "/" + strconv.Itoa(bits)
+	}
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil || (ipNet.IP.To4() == nil) != ipv6 {
+		return nil, errors.Wrapf(errUnsupportedRule, "address %s", value)
+	}
+	ip := ipNet.IP.To4()
+	if ipv6 {
+		ip = ipNet.IP.To16()
+	}
+
+	exprs := []expr.Any{&expr.Payload{DestRegister: regData, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: uint32(len(ip))}}
+	if ones, bits := ipNet.Mask.Size(); ones != bits {
+		exprs = append(exprs, &expr.Bitwise{SourceRegister: regData, DestRegister: regData, Len: uint32(len(ip)), Mask: ipNet.Mask, Xor: make([]byte, len(ip))})
+	}
+	return append(exprs, &expr.Cmp{Op: op, Register: regData, Data: ip}), nil
+}
+
+// macExprs matches the MAC address at the offset of the ethernet header, which is either an address or one of
+// the unicast, multicast and broadcast address types.
+func macExprs(offset uint32, value string, op expr.CmpOp) ([]expr.Any, error) {
+	load := &expr.Payload{DestRegister: regData, Base: expr.PayloadBaseLLHeader, Offset: offset, Len: 1}
+	groupBit := &expr.Bitwise{SourceRegister: regData, DestRegister: regData, Len: 1, Mask: []byte{1}, Xor: []byte{0}}
+	switch value {
+	case "unicast":
+		return []expr.Any{load, groupBit, &expr.Cmp{Op: op, Register: regData, Data: []byte{0}}}, nil
+	case "multicast":
+		return []expr.Any{load, groupBit, &expr.Cmp{Op: op, Register: regData, Data: []byte{1}}}, nil
+	case "broadcast":
+		value = "ff:ff:ff:ff:ff:ff"
+	}
+	mac, err := net.ParseMAC(value)
+	if err != nil || len(mac) != 6 {
+		return nil, errors.Wrapf(errUnsupportedRule, "mac %s", value)
+	}
+	return payloadExprs(expr.PayloadBaseLLHeader, offset, mac, op), nil
+}
+
+// ifNameExprs matches the input or output bridge port name, a trailing + matches the names with its prefix.
+func ifNameExprs(key expr.MetaKey, name string, op expr.CmpOp) []expr.Any {
+	data := []byte(strings.TrimSuffix(name, "+"))
+	if !strings.HasSuffix(name, "+") {
+		data = append(data, bytes.Repeat([]byte{0}, unix.IFNAMSIZ-len(data))...)
+	}
+	return []expr.Any{
+		&expr.Meta{Key: key, Register: regData},
+		&expr.Cmp{Op: op, Register: regData, Data: data},
+	}
+}
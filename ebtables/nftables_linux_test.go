@@ -0,0 +1,199 @@
+package ebtables
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/netns"
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/google/nftables/xt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestCanonicalRule(t *testing.T) {
+	require.Equal(t, "-p IPv4 --ip-dst 10.0.0.4 -j redirect", canonicalRule("--ip-dst 10.0.0.4 -p IPv4 -j redirect --redirect-target ACCEPT"))
+	require.Equal(t, "-p ARP --arp-op Request --arp-ip-dst 10.0.0.4 -j arpreply --arpreply-mac 12:34:56:78:9a:bc --arpreply-target DROP",
+		canonicalRule("-p ARP --arp-op Request --arp-ip-dst 10.0.0.4 -j arpreply --arpreply-mac 12:34:56:78:9a:bc --arpreply-target DROP"))
+}
+
+func TestRuleExprs(t *testing.T) {
+	verdict := func(v int32) []byte {
+		return append(binaryutil.NativeEndian.PutUint32(uint32(v)), 0, 0, 0, 0)
+	}
+	arpreplyInfo := xt.Unknown(append([]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0, 0}, verdict(ebtDrop)...))
+	snatInfo := xt.Unknown(append([]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0, 0}, verdict(ebtAccept^ebtNATARPBit)...))
+	redirectInfo := xt.Unknown(verdict(ebtAccept))
+
+	tests := []struct {
+		name    string
+		table   string
+		rule    string
+		want    []expr.Any
+		wantErr bool
+	}{
+		{
+			name:  "arp reply",
+			table: Nat,
+			rule:  "-p ARP --arp-op Request --arp-ip-dst 10.0.0.4 -j arpreply --arpreply-mac 12:34:56:78:9a:bc --arpreply-target DROP",
+			want: []expr.Any{
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseLLHeader, Offset: 12, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0x08, 0x06}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 6, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0, 1}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 24, Len: 4},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{10, 0, 0, 4}},
+				&expr.Target{Name: "arpreply", Info: &arpreplyInfo},
+			},
+		},
+		{
+			name:  "snat for interface",
+			table: Nat,
+			rule:  "-s unicast -o eth0 -j snat --to-src 12:34:56:78:9a:bc --snat-arp --snat-target ACCEPT",
+			want: []expr.Any{
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseLLHeader, Offset: 6, Len: 1},
+				&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 1, Mask: []byte{1}, Xor: []byte{0}},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0}},
+				&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte("eth0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+				&expr.Target{Name: "snat", Info: &snatInfo},
+			},
+		},
+		{
+			name:  "broute by prefix",
+			table: Broute,
+			rule:  "-p IPv4 --ip-dst 10.0.0.0/24 -j redirect --redirect-target ACCEPT",
+			want: []expr.Any{
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseLLHeader, Offset: 12, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0x08, 0x00}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 16, Len: 4},
+				&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: []byte{255, 255, 255, 0}, Xor: []byte{0, 0, 0, 0}},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{10, 0, 0, 0}},
+				&expr.Immediate{Register: 1, Data: []byte{1}},
+				&expr.Meta{Key: metaKeyBRIBroute, SourceRegister: true, Register: 1},
+				&expr.Target{Name: "redirect", Info: &redirectInfo},
+			},
+		},
+		{
+			name:  "drop neighbor solicitation",
+			table: Filter,
+			rule:  "-p IPv6 --ip6-proto ipv6-icmp --ip6-icmp-type neighbour-solicitation -o eth0 -j DROP",
+			want: []expr.Any{
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseLLHeader, Offset: 12, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0x86, 0xdd}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 6, Len: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{unix.IPPROTO_ICMPV6}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 0, Len: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{135}},
+				&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte("eth0\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+				&expr.Verdict{Kind: expr.VerdictDrop},
+			},
+		},
+		{
+			name:  "interface prefix",
+			table: Nat,
+			rule:  "-i azv+ -j ACCEPT",
+			want: []expr.Any{
+				&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte("azv")},
+				&expr.Verdict{Kind: expr.VerdictAccept},
+			},
+		},
+		{
+			name:    "address of the wrong family",
+			table:   Nat,
+			rule:    "-p IPv6 --ip6-dst 10.0.0.4 -j ACCEPT",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported target",
+			table:   Nat,
+			rule:    "-j mark --mark-set 1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ruleExprs(tt.table, tt.rule)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errUnsupportedRule)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// newTestNFTClient returns a client for a new network namespace, or skips the test if nftables can not be
+// programmed.
+func newTestNFTClient(t *testing.T) *nftClient {
+	t.Helper()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ns := netns.New()
+	origin, err := ns.Get()
+	require.NoError(t, err)
+	defer unix.Close(origin)
+
+	name := "ebnftest"
+	fd, err := ns.NewNamed(name)
+	if err != nil {
+		t.Skipf("unable to create a network namespace: %v", err)
+	}
+	require.NoError(t, ns.Set(origin))
+	t.Cleanup(func() {
+		unix.Close(fd)
+		_ = ns.DeleteNamed(name)
+	})
+
+	c, err := newNFTClient(nftables.WithNetNSFd(fd))
+	if err != nil {
+		t.Skipf("nftables is unavailable: %v", err)
+	}
+	return c
+}
+
+func TestNFTClient(t *testing.T) {
+	c := newTestNFTClient(t)
+
+	rules, err := c.rules(Nat, PreRouting)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	dnat := "-p IPv4 -i eth0 --ip-dst 10.0.0.4 -j dnat --to-dst 12:34:56:78:9a:bc --dnat-target ACCEPT"
+	drop := "-p IPv6 --ip6-proto ipv6-icmp --ip6-icmp-type neighbour-solicitation -o eth0 -j DROP"
+	require.NoError(t, c.run(Nat, Append, PreRouting, dnat))
+	require.NoError(t, c.run(Filter, Append, Forward, drop))
+
+	rules, err = c.rules(Nat, PreRouting)
+	require.NoError(t, err)
+	require.Equal(t, []string{"-p IPv4 -i eth0 --ip-dst 10.0.0.4 -j dnat --to-dst 12:34:56:78:9a:bc"}, rules)
+
+	require.NoError(t, c.run(Nat, Delete, PreRouting, dnat))
+	require.ErrorIs(t, c.run(Nat, Delete, PreRouting, dnat), errRuleNotFound)
+	rules, err = c.rules(Nat, PreRouting)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	require.NoError(t, c.run(Filter, Delete, Forward, drop))
+	require.ErrorIs(t, c.run(Nat, Append, Forward, drop), errUnsupportedRule)
+
+	// rules appended to an existing chain keep its policy.
+	table := &nftables.Table{Name: Filter, Family: nftables.TableFamilyBridge}
+	chain, err := c.conn.ListChain(table, Forward)
+	require.NoError(t, err)
+	policy := nftables.ChainPolicyDrop
+	chain.Policy = &policy
+	c.conn.AddChain(chain)
+	require.NoError(t, c.conn.Flush())
+	require.NoError(t, c.run(Filter, Append, Forward, drop))
+	chain, err = c.conn.ListChain(table, Forward)
+	require.NoError(t, err)
+	require.Equal(t, nftables.ChainPolicyDrop, *chain.Policy)
+}
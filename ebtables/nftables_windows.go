@@ -0,0 +1,9 @@
+package ebtables
+
+func runNFTCmd(string, string, string, string) error {
+	return errNFTablesUnavailable
+}
+
+func getNFTRules(string, string) ([]string, error) {
+	return nil, errNFTablesUnavailable
+}
@@ -18,6 +18,16 @@ const (
 	storeKey = "IPAM"
 )
 
+// stateSchema lists the migrations of the address manager state persisted under storeKey.
+var stateSchema = store.MustNewSchema("ipam",
+	store.Migration{
+		Version:     1,
+		Description: "start versioning the ipam state",
+		Up:          store.NoopMigration,
+		Down:        store.NoopMigration,
+	},
+)
+
 // AddressManager manages the set of address spaces and pools allocated to containers.
 type addressManager struct {
 	Version    string
@@ -101,7 +111,7 @@ func (am *addressManager) restore(rehydrateIpamInfoOnReboot bool) error {
 	}
 
 	// Read any persisted state.
-	err := am.store.Read(storeKey, am)
+	err := stateSchema.Read(am.store, storeKey, am)
 	if err != nil {
 		if err == store.ErrKeyNotFound {
 			logger.Info("store key not found")
@@ -171,7 +181,7 @@ func (am *addressManager) save() error {
 	am.TimeStamp = time.Now()
 
 	logger.Info("saving ipam state")
-	err := am.store.Write(storeKey, am)
+	err := stateSchema.Write(am.store, storeKey, am)
 	if err == nil {
 		logger.Info("Save succeeded")
 	} else {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/cni/log"
 	"github.com/Azure/azure-container-networking/platform"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -46,9 +47,13 @@ const (
 
 // actions
 const (
-	Insert = "I"
-	Append = "A"
-	Delete = "D"
+	Insert      = "I"
+	Append      = "A"
+	Delete      = "D"
+	NewChain    = "N"
+	DeleteChain = "X"
+	// DeleteCommented deletes the rules of a chain with a comment, it is only used in transactions
+	DeleteCommented = "DC"
 )
 
 // states
@@ -82,6 +87,12 @@ const (
 
 var DisableIPTableLock bool
 
+// EnableNFTables programs the rules with nftables over netlink instead of running the iptables binaries.
+// The iptables binaries are still used if the kernel does not support nftables.
+var EnableNFTables bool
+
+var errNFTablesUnavailable = errors.New("nftables is unavailable")
+
 type IPTableEntry struct {
 	Version string
 	Params  string
@@ -97,6 +108,14 @@ func NewClient() *Client {
 func (c *Client) RunCmd(version, params string) error {
 	var cmd string
 
+	if EnableNFTables {
+		err := runNFTCmd(version, params)
+		if !errors.Is(err, errNFTablesUnavailable) {
+			return err
+		}
+		logger.Warn("Falling back to iptables", zap.Error(err))
+	}
+
 	p := platform.NewExecClient(logger)
 	iptCmd := iptables
 	if version == V6 {
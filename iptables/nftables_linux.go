@@ -0,0 +1,706 @@
+package iptables
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/google/nftables/userdata"
+	"github.com/google/nftables/xt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+// The nftables backend programs the commands built by the client as nftables rules over netlink, in the same
+// tables and chains iptables-nft uses. Addresses, interfaces, protocols and ports are matched with native
+// expressions, the conntrack, addrtype and mark matches and the NAT and MARK targets are programmed as the xtables
+// extensions through nft_compat, as iptables-nft does, so that iptables-nft-save and kube-proxy can still parse
+// the tables. The iptables rule spec is kept as the comment of the rule, it is how rules are found again to check
+// or delete them.
+
+var (
+	errUnsupportedCommand = errors.New("unsupported iptables command")
+	errUnsupportedRule    = errors.New("unsupported iptables rule")
+	errChainNotFound      = errors.New("chain not found")
+	errRuleNotFound       = errors.New("rule not found")
+)
+
+// register used by the rule expressions.
+const regAddr = 1
+
+// IP protocol numbers of the protocols matched by -p.
+var protocols = map[string]byte{
+	"icmp":      unix.IPPROTO_ICMP,
+	"tcp":       unix.IPPROTO_TCP,
+	"udp":       unix.IPPROTO_UDP,
+	"ipv6-icmp": unix.IPPROTO_ICMPV6,
+	"icmpv6":    unix.IPPROTO_ICMPV6,
+	"sctp":      unix.IPPROTO_SCTP,
+}
+
+// conntrack state bits matched by --state and --ctstate, the same in nftables and xt_conntrack.
+var ctStates = map[string]uint32{
+	"INVALID":     expr.CtStateBitINVALID,
+	"ESTABLISHED": expr.CtStateBitESTABLISHED,
+	"RELATED":     expr.CtStateBitRELATED,
+	"NEW":         expr.CtStateBitNEW,
+	"UNTRACKED":   expr.CtStateBitUNTRACKED,
+}
+
+// match modules which only enable options that are translated on their own.
+var matchModules = map[string]bool{
+	"tcp":       true,
+	"udp":       true,
+	"state":     true,
+	"conntrack": true,
+	"addrtype":  true,
+	"mark":      true,
+	"comment":   true,
+}
+
+type baseChain struct {
+	hook     *nftables.ChainHook
+	priority *nftables.ChainPriority
+	typ      nftables.ChainType
+}
+
+// baseChains are the built-in chains of the iptables tables, as registered by iptables-nft.
+var baseChains = map[string]map[string]baseChain{
+	Filter: {
+		Input:   {nftables.ChainHookInput, nftables.ChainPriorityFilter, nftables.ChainTypeFilter},
+		Forward: {nftables.ChainHookForward, nftables.ChainPriorityFilter, nftables.ChainTypeFilter},
+		Output:  {nftables.ChainHookOutput, nftables.ChainPriorityFilter, nftables.ChainTypeFilter},
+	},
+	Nat: {
+		Prerouting:  {nftables.ChainHookPrerouting, nftables.ChainPriorityNATDest, nftables.ChainTypeNAT},
+		Input:       {nftables.ChainHookInput, nftables.ChainPriorityNATSource, nftables.ChainTypeNAT},
+		Output:      {nftables.ChainHookOutput, nftables.ChainPriorityNATDest, nftables.ChainTypeNAT},
+		Postrouting: {nftables.ChainHookPostrouting, nftables.ChainPriorityNATSource, nftables.ChainTypeNAT},
+	},
+	Mangle: {
+		Prerouting:  {nftables.ChainHookPrerouting, nftables.ChainPriorityMangle, nftables.ChainTypeFilter},
+		Input:       {nftables.ChainHookInput, nftables.ChainPriorityMangle, nftables.ChainTypeFilter},
+		Forward:     {nftables.ChainHookForward, nftables.ChainPriorityMangle, nftables.ChainTypeFilter},
+		Output:      {nftables.ChainHookOutput, nftables.ChainPriorityMangle, nftables.ChainTypeRoute},
+		Postrouting: {nftables.ChainHookPostrouting, nftables.ChainPriorityMangle, nftables.ChainTypeFilter},
+	},
+}
+
+var (
+	nftOnce sync.Once
+	nft     *nftClient
+	nftErr  error
+)
+
+func getNFTClient() (*nftClient, error) {
+	nftOnce.Do(func() {
+		nft, nftErr = newNFTClient()
+	})
+	return nft, nftErr
+}
+
+// runNFTCmd runs the iptables commands with the nftables backend as one transaction. errNFTablesUnavailable is
+// returned if the kernel does not support nftables.
+func runNFTCmd(version string, params ...string) error {
+	c, err := getNFTClient()
+	if err != nil {
+		return err
+	}
+	return c.run(version, params...)
+}
+
+// saveNFT returns the chains and rules of the table in the iptables-save format.
+func saveNFT(version, table string) (string, error) {
+	c, err := getNFTClient()
+	if err != nil {
+		return "", err
+	}
+	return c.save(version, table)
+}
+
+// nftClient runs iptables commands as nftables transactions.
+type nftClient struct {
+	opts []nftables.ConnOption
+}
+
+func newNFTClient(opts ...nftables.ConnOption) (*nftClient, error) {
+	conn, err := nftables.New(opts...)
+	if err != nil {
+		return nil, errors.Wrapf(errNFTablesUnavailable, "failed to open nftables connection: %v", err)
+	}
+	if _, err := conn.ListTablesOfFamily(nftables.TableFamilyIPv4); err != nil {
+		return nil, errors.Wrapf(errNFTablesUnavailable, "failed to list nftables tables: %v", err)
+	}
+	return &nftClient{opts: opts}, nil
+}
+
+// nftCmd is an iptables command parsed from its params.
+type nftCmd struct {
+	table  string
+	action string
+	chain  string
+	// rule is the match and target of the rule, normalized to single spaces.
+	rule string
+}
+
+// parseNFTCmd parses the params of the iptables commands built by the client.
+func parseNFTCmd(params string) (nftCmd, error) {
+	cmd := nftCmd{table: Filter}
+	fields := strings.Fields(params)
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i]; f {
+		case "-w", "-t":
+			if i+1 == len(fields) {
+				return cmd, errors.Wrapf(errUnsupportedCommand, "%s needs a value: %s", f, params)
+			}
+			i++
+			if f == "-t" {
+				cmd.table = fields[i]
+			}
+		case "-N", "-X", "-F", "-L", "-nL", "-C", "-A", "-I", "-D":
+			if i+1 == len(fields) {
+				return cmd, errors.Wrapf(errUnsupportedCommand, "%s needs a chain: %s", f, params)
+			}
+			cmd.action = f
+			if f == "-nL" {
+				cmd.action = "-L"
+			}
+			cmd.chain = fields[i+1]
+			rest := fields[i+2:]
+			if f == "-I" && len(rest) > 0 {
+				// rules are only inserted at the head of the chain.
+				if n, err := strconv.Atoi(rest[0]); err == nil {
+					if n != 1 {
+						return cmd, errors.Wrapf(errUnsupportedCommand, "insert at position %d: %s", n, params)
+					}
+					rest = rest[1:]
+				}
+			}
+			cmd.rule = strings.Join(rest, " ")
+			return cmd, nil
+		default:
+			return cmd, errors.Wrapf(errUnsupportedCommand, "unknown option %s: %s", f, params)
+		}
+	}
+	return cmd, errors.Wrapf(errUnsupportedCommand, "no command: %s", params)
+}
+
+func tableFamily(version string) nftables.TableFamily {
+	if version == V6 {
+		return nftables.TableFamilyIPv6
+	}
+	return nftables.TableFamilyIPv4
+}
+
+// nftBatch queues the commands of one run on its own connection, so a failed run leaves nothing behind to be
+// flushed with the next one.
+type nftBatch struct {
+	conn *nftables.Conn
+	// chains are the user chains created earlier in the batch, by table and name.
+	chains map[string]*nftables.Chain
+}
+
+// run runs the iptables commands as one nftables transaction, either all of them are applied or none is.
+// Lookups with -L and -C are only run on their own.
+func (c *nftClient) run(version string, params ...string) error {
+	conn, err := nftables.New(c.opts...)
+	if err != nil {
+		return errors.Wrap(err, "failed to open nftables connection")
+	}
+	b := &nftBatch{conn: conn, chains: map[string]*nftables.Chain{}}
+
+	for _, p := range params {
+		cmd, err := parseNFTCmd(p)
+		if err != nil {
+			return err
+		}
+		if (cmd.action == "-L" || cmd.action == "-C") && len(params) > 1 {
+			return errors.Wrapf(errUnsupportedCommand, "lookup in a transaction: %s", p)
+		}
+		logger.Info("Running nftables command", zap.String("version", version), zap.String("params", p))
+
+		table := &nftables.Table{Name: cmd.table, Family: tableFamily(version)}
+		switch cmd.action {
+		case "-N":
+			b.conn.AddTable(table)
+			b.chains[table.Name+"/"+cmd.chain] = b.conn.AddChain(&nftables.Chain{Name: cmd.chain, Table: table})
+		case "-L":
+			_, err = b.getChain(table, cmd.chain)
+			return err
+		case "-X", "-F":
+			chain, err := b.getChain(table, cmd.chain)
+			if err != nil {
+				return err
+			}
+			if cmd.action == "-X" {
+				b.conn.DelChain(chain)
+				delete(b.chains, table.Name+"/"+cmd.chain)
+			} else {
+				b.conn.FlushChain(chain)
+			}
+		case "-C", "-D":
+			rule, err := b.findRule(table, cmd.chain, cmd.rule)
+			if err != nil || cmd.action == "-C" {
+				return err
+			}
+			if err := b.conn.DelRule(rule); err != nil {
+				return errors.Wrap(err, "failed to delete rule")
+			}
+		case "-A", "-I":
+			exprs, err := ruleExprs(table.Family, cmd.rule)
+			if err != nil {
+				return err
+			}
+			chain, err := b.ensureChain(table, cmd.chain)
+			if err != nil {
+				return err
+			}
+			rule := &nftables.Rule{
+				Table:    table,
+				Chain:    chain,
+				Exprs:    exprs,
+				UserData: userdata.AppendString(nil, userdata.TypeComment, cmd.rule),
+			}
+			if cmd.action == "-A" {
+				b.conn.AddRule(rule)
+			} else {
+				b.conn.InsertRule(rule)
+			}
+		}
+	}
+
+	return errors.Wrapf(b.conn.Flush(), "failed to apply nftables commands %v", params)
+}
+
+// save returns the chains of the table and the rules programmed by the client in the iptables-save format.
+// Rules added by other programs have no iptables rule spec and are left out.
+func (c *nftClient) save(version, tableName string) (string, error) {
+	conn, err := nftables.New(c.opts...)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open nftables connection")
+	}
+	chains, err := conn.ListChainsOfTableFamily(tableFamily(version))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list nftables chains")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%s\n", tableName)
+	var rules []string
+	for _, chain := range chains {
+		if chain.Table.Name != tableName {
+			continue
+		}
+		fmt.Fprintf(&sb, ":%s - [0:0]\n", chain.Name)
+		chainRules, err := conn.GetRules(chain.Table, chain)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to list rules of chain %s", chain.Name)
+		}
+		for _, rule := range chainRules {
+			if spec, ok := userdata.GetString(rule.UserData, userdata.TypeComment); ok {
+				rules = append(rules, fmt.Sprintf("-A %s %s\n", chain.Name, spec))
+			}
+		}
+	}
+	for _, rule := range rules {
+		sb.WriteString(rule)
+	}
+	sb.WriteString("COMMIT\n")
+	return sb.String(), nil
+}
+
+func (b *nftBatch) getChain(table *nftables.Table, name string) (*nftables.Chain, error) {
+	if chain, ok := b.chains[table.Name+"/"+name]; ok {
+		return chain, nil
+	}
+	chain, err := b.conn.ListChain(table, name)
+	if err != nil {
+		return nil, errors.Wrapf(errChainNotFound, "%s in table %s: %v", name, table.Name, err)
+	}
+	return chain, nil
+}
+
+// ensureChain returns the chain, adding the table and built-in chain to the transaction if they are missing.
+// An existing built-in chain is not added again, that would reset its policy to accept.
+// User chains have to be created first, as with iptables.
+func (b *nftBatch) ensureChain(table *nftables.Table, name string) (*nftables.Chain, error) {
+	base, ok := baseChains[table.Name][name]
+	if !ok {
+		return b.getChain(table, name)
+	}
+	if chain, err := b.getChain(table, name); err == nil {
+		return chain, nil
+	}
+	policy := nftables.ChainPolicyAccept
+	b.conn.AddTable(table)
+	chain := b.conn.AddChain(&nftables.Chain{
+		Name:     name,
+		Table:    table,
+		Hooknum:  base.hook,
+		Priority: base.priority,
+		Type:     base.typ,
+		Policy:   &policy,
+	})
+	b.chains[table.Name+"/"+name] = chain
+	return chain, nil
+}
+
+// findRule returns the first rule of the chain with the given spec.
+func (b *nftBatch) findRule(table *nftables.Table, chainName, spec string) (*nftables.Rule, error) {
+	chain, err := b.getChain(table, chainName)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := b.conn.GetRules(table, chain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list rules of chain %s", chainName)
+	}
+	for _, rule := range rules {
+		if comment, ok := userdata.GetString(rule.UserData, userdata.TypeComment); ok && comment == spec {
+			return rule, nil
+		}
+	}
+	return nil, errors.Wrapf(errRuleNotFound, "%s in chain %s", spec, chainName)
+}
+
+// ruleExprs translates the match and target of an iptables rule to nftables expressions. Only the matches and
+// targets used by CNI are supported.
+func ruleExprs(family nftables.TableFamily, spec string) ([]expr.Any, error) {
+	var (
+		exprs    []expr.Any
+		negate   bool
+		protocol string
+	)
+	fields := strings.Fields(spec)
+	next := func(i int) (string, error) {
+		if i+1 == len(fields) {
+			return "", errors.Wrapf(errUnsupportedRule, "%s needs a value: %s", fields[i], spec)
+		}
+		return fields[i+1], nil
+	}
+
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f == "!" {
+			negate = true
+			continue
+		}
+		if f == "-j" || f == "--jump" {
+			target, err := targetExprs(family, fields[i+1:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %s", spec)
+			}
+			return append(exprs, target...), nil
+		}
+
+		value, err := next(i)
+		if err != nil {
+			return nil, err
+		}
+		i++
+		op := expr.CmpOpEq
+		if negate {
+			op = expr.CmpOpNeq
+		}
+
+		switch f {
+		case "-s", "--source", "-d", "--destination":
+			match, err := addrExprs(family, f == "-s" || f == "--source", value, op)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %s", spec)
+			}
+			exprs = append(exprs, match...)
+		case "-i", "--in-interface":
+			exprs = append(exprs, ifNameExprs(expr.MetaKeyIIFNAME, value, op)...)
+		case "-o", "--out-interface":
+			exprs = append(exprs, ifNameExprs(expr.MetaKeyOIFNAME, value, op)...)
+		case "-p", "--protocol":
+			proto, ok := protocols[strings.ToLower(value)]
+			if !ok {
+				return nil, errors.Wrapf(errUnsupportedRule, "protocol %s: %s", value, spec)
+			}
+			protocol = strings.ToLower(value)
+			exprs = append(exprs,
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: regAddr},
+				&expr.Cmp{Op: op, Register: regAddr, Data: []byte{proto}})
+		case "--dport", "--destination-port", "--sport", "--source-port":
+			if protocol != TCP && protocol != UDP {
+				return nil, errors.Wrapf(errUnsupportedRule, "%s needs -p tcp or udp: %s", f, spec)
+			}
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return nil, errors.Wrapf(errUnsupportedRule, "port %s: %s", value, spec)
+			}
+			var offset uint32 = 2
+			if f == "--sport" || f == "--source-port" {
+				offset = 0
+			}
+			exprs = append(exprs,
+				&expr.Payload{DestRegister: regAddr, Base: expr.PayloadBaseTransportHeader, Offset: offset, Len: 2},
+				&expr.Cmp{Op: op, Register: regAddr, Data: binaryutil.BigEndian.PutUint16(uint16(port))})
+		case "--state", "--ctstate":
+			var bits uint32
+			for _, state := range strings.Split(value, ",") {
+				bit, ok := ctStates[state]
+				if !ok {
+					return nil, errors.Wrapf(errUnsupportedRule, "state %s: %s", state, spec)
+				}
+				bits |= bit
+			}
+			exprs = append(exprs, conntrackMatch(bits, negate))
+		case "--dst-type", "--src-type":
+			if strings.ToUpper(value) != "LOCAL" {
+				return nil, errors.Wrapf(errUnsupportedRule, "address type %s: %s", value, spec)
+			}
+			exprs = append(exprs, addrTypeMatch(f == "--src-type", negate))
+		case "--mark":
+			mark, mask, err := parseMark(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %s", spec)
+			}
+			exprs = append(exprs, markMatch(mark, mask, negate))
+		case "-m", "--match":
+			if !matchModules[value] {
+				return nil, errors.Wrapf(errUnsupportedRule, "match %s: %s", value, spec)
+			}
+		case "--comment":
+			// the whole spec is kept as the comment of the rule.
+		default:
+			return nil, errors.Wrapf(errUnsupportedRule, "option %s: %s", f, spec)
+		}
+		negate = false
+	}
+
+	return nil, errors.Wrapf(errUnsupportedRule, "no target: %s", spec)
+}
+
+// targetExprs translates the target of an iptables rule, starting with its name.
+func targetExprs(family nftables.TableFamily, fields []string) ([]expr.Any, error) {
+	if len(fields) == 0 {
+		return nil, errors.Wrap(errUnsupportedRule, "empty target")
+	}
+	target, args := fields[0], fields[1:]
+	option := func(names ...string) (string, error) {
+		if len(args) == 2 {
+			for _, name := range names {
+				if args[0] == name {
+					return args[1], nil
+				}
+			}
+		}
+		return "", errors.Wrapf(errUnsupportedRule, "target %s", strings.Join(fields, " "))
+	}
+
+	switch target {
+	case Accept:
+		return []expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}, nil
+	case Drop:
+		return []expr.Any{&expr.Verdict{Kind: expr.VerdictDrop}}, nil
+	case Return:
+		return []expr.Any{&expr.Verdict{Kind: expr.VerdictReturn}}, nil
+	case Masquerade:
+		if family == nftables.TableFamilyIPv6 {
+			return []expr.Any{&expr.Target{Name: Masquerade, Info: &xt.NatRange{}}}, nil
+		}
+		return []expr.Any{&expr.Target{Name: Masquerade, Info: &xt.NatIPv4MultiRangeCompat{{}}}}, nil
+	case Snat:
+		value, err := option("--to", "--to-source")
+		if err != nil {
+			return nil, err
+		}
+		ip, err := familyIP(family, value)
+		if err != nil {
+			return nil, err
+		}
+		return []expr.Any{natTarget(Snat, ip, 0)}, nil
+	case "DNAT":
+		value, err := option("--to-destination", "--to")
+		if err != nil {
+			return nil, err
+		}
+		host, port, err := net.SplitHostPort(value)
+		if err != nil {
+			host, port = value, ""
+		}
+		ip, err := familyIP(family, host)
+		if err != nil {
+			return nil, err
+		}
+		var p uint64
+		if port != "" {
+			if p, err = strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+				return nil, errors.Wrapf(errUnsupportedRule, "port %s", port)
+			}
+		}
+		return []expr.Any{natTarget("DNAT", ip, uint16(p))}, nil
+	case "MARK":
+		value, err := option("--set-mark", "--set-xmark")
+		if err != nil {
+			return nil, err
+		}
+		mark, mask, err := parseMark(value)
+		if err != nil {
+			return nil, err
+		}
+		if args[0] == "--set-mark" {
+			// --set-mark ORs the value into the bits of the mask, it is --set-xmark of both.
+			mask |= mark
+		}
+		// xt_mark_tginfo2, the target sets mark = (mark & ^mask) ^ value.
+		info := xt.Unknown(append(binaryutil.NativeEndian.PutUint32(mark), binaryutil.NativeEndian.PutUint32(mask)...))
+		return []expr.Any{&expr.Target{Name: "MARK", Rev: 2, Info: &info}}, nil
+	}
+
+	if len(args) > 0 || strings.HasPrefix(target, "-") {
+		return nil, errors.Wrapf(errUnsupportedRule, "target %s", strings.Join(fields, " "))
+	}
+	// anything else is a jump to a user chain.
+	return []expr.Any{&expr.Verdict{Kind: expr.VerdictJump, Chain: target}}, nil
+}
+
+// xt_addrtype flags of the inverted address type matches.
+const (
+	addrTypeInvertSource xt.AddrTypeFlags = 1 << iota
+	addrTypeInvertDest
+)
+
+// conntrackMatch matches the conntrack state of the packet against any of the state bits, with revision 3 of the
+// conntrack match which iptables uses for both --state and --ctstate.
+func conntrackMatch(bits uint32, negate bool) *expr.Match {
+	info := &xt.ConntrackMtinfo3{}
+	info.MatchFlags = uint16(xt.ConntrackState)
+	if negate {
+		info.InvertFlags = uint16(xt.ConntrackState)
+	}
+	info.StateMask = uint16(bits)
+	return &expr.Match{Name: "conntrack", Rev: 3, Info: info}
+}
+
+// addrTypeMatch matches local source or destination addresses.
+func addrTypeMatch(source, negate bool) *expr.Match {
+	info := &xt.AddrTypeV1{}
+	if source {
+		info.Source = uint16(xt.AddrTypeLocal)
+		if negate {
+			info.Flags = addrTypeInvertSource
+		}
+	} else {
+		info.Dest = uint16(xt.AddrTypeLocal)
+		if negate {
+			info.Flags = addrTypeInvertDest
+		}
+	}
+	return &expr.Match{Name: "addrtype", Rev: 1, Info: info}
+}
+
+// markMatch matches the masked packet mark, the info is xt_mark_mtinfo1 padded to 16 bytes.
+func markMatch(mark, mask uint32, negate bool) *expr.Match {
+	info := make(xt.Unknown, 16) //nolint:gomnd // size of the padded info
+	copy(info, binaryutil.NativeEndian.PutUint32(mark&mask))
+	copy(info[4:], binaryutil.NativeEndian.PutUint32(mask))
+	if negate {
+		info[8] = 1
+	}
+	return &expr.Match{Name: "mark", Rev: 1, Info: &info}
+}
+
+// natTarget is the SNAT or DNAT target to the address, and to the port unless it is 0.
+func natTarget(name string, ip net.IP, port uint16) *expr.Target {
+	info := &xt.NatRange{Flags: uint(xt.NatRangeMapIPs), MinIP: ip, MaxIP: ip}
+	if port != 0 {
+		info.Flags |= uint(xt.NatRangeProtoSpecified)
+		info.MinPort, info.MaxPort = port, port
+	}
+	return &expr.Target{Name: name, Rev: 1, Info: info}
+}
+
+// addrExprs matches the source or destination address of the packet against an address or prefix.
+func addrExprs(family nftables.TableFamily, source bool, value string, op expr.CmpOp) ([]expr.Any, error) {
+	var ipNet *net.IPNet
+	if strings.Contains(value, "/") {
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.Wrapf(errUnsupportedRule, "address %s", value)
+		}
+		ipNet = n
+	} else {
+		ip, err := familyIP(family, value)
+		if err != nil {
+			return nil, err
+		}
+		ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)} //nolint:gomnd // bits in a byte
+	}
+
+	ip := ipNet.IP.To4()
+	offset := uint32(12) // saddr in the IPv4 header
+	if family == nftables.TableFamilyIPv6 {
+		ip = ipNet.IP.To16()
+		offset = 8 // saddr in the IPv6 header
+	}
+	if ip == nil || (family == nftables.TableFamilyIPv6) != (ipNet.IP.To4() == nil) {
+		return nil, errors.Wrapf(errUnsupportedRule, "address %s of the wrong family", value)
+	}
+	if !source {
+		offset += uint32(len(ip))
+	}
+
+	exprs := []expr.Any{&expr.Payload{DestRegister: regAddr, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: uint32(len(ip))}}
+	if ones, bits := ipNet.Mask.Size(); ones != bits {
+		exprs = append(exprs, &expr.Bitwise{SourceRegister: regAddr, DestRegister: regAddr, Len: uint32(len(ip)), Mask: ipNet.Mask, Xor: make([]byte, len(ip))})
+	}
+	return append(exprs, &expr.Cmp{Op: op, Register: regAddr, Data: ip.Mask(ipNet.Mask)}), nil
+}
+
+// ifNameExprs matches the input or output interface name, a trailing + matches the names with its prefix.
+func ifNameExprs(key expr.MetaKey, name string, op expr.CmpOp) []expr.Any {
+	data := []byte(strings.TrimSuffix(name, "+"))
+	if !strings.HasSuffix(name, "+") {
+		data = append(data, make([]byte, unix.IFNAMSIZ-len(data))...)
+	}
+	return []expr.Any{
+		&expr.Meta{Key: key, Register: regAddr},
+		&expr.Cmp{Op: op, Register: regAddr, Data: data},
+	}
+}
+
+// familyIP parses the IP, in the length of the table family's addresses.
+func familyIP(family nftables.TableFamily, value string) (net.IP, error) {
+	ip := net.ParseIP(strings.Trim(value, "[]"))
+	if ip == nil {
+		return nil, errors.Wrapf(errUnsupportedRule, "address %s", value)
+	}
+	if family == nftables.TableFamilyIPv6 {
+		if ip.To4() != nil {
+			return nil, errors.Wrapf(errUnsupportedRule, "IPv4 address %s in an IPv6 rule", value)
+		}
+		return ip.To16(), nil
+	}
+	if ip.To4() == nil {
+		return nil, errors.Wrapf(errUnsupportedRule, "IPv6 address %s in an IPv4 rule", value)
+	}
+	return ip.To4(), nil
+}
+
+// parseMark parses a mark in the value[/mask] format of iptables.
+func parseMark(value string) (mark, mask uint32, err error) {
+	markValue, maskValue, hasMask := strings.Cut(value, "/")
+	m, err := strconv.ParseUint(markValue, 0, 32)
+	if err != nil {
+		return 0, 0, errors.Wrapf(errUnsupportedRule, "mark %s", value)
+	}
+	mask = ^uint32(0)
+	if hasMask {
+		k, err := strconv.ParseUint(maskValue, 0, 32)
+		if err != nil {
+			return 0, 0, errors.Wrapf(errUnsupportedRule, "mark %s", value)
+		}
+		mask = uint32(k)
+	}
+	return uint32(m), mask, nil
+}
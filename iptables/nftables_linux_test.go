@@ -0,0 +1,318 @@
+package iptables
+
+import (
+	"net"
+	"runtime"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/netns"
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/google/nftables/userdata"
+	"github.com/google/nftables/xt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseNFTCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		want    nftCmd
+		wantErr bool
+	}{
+		{
+			name:   "create chain",
+			params: "-t nat -N SWIFT",
+			want:   nftCmd{table: Nat, action: "-N", chain: Swift},
+		},
+		{
+			name:   "list chain",
+			params: "-t filter -nL AZURECNIINPUT",
+			want:   nftCmd{table: Filter, action: "-L", chain: CNIInputChain},
+		},
+		{
+			name:   "insert rule",
+			params: "-w 60 -t filter -I AZURECNIINPUT 1  -i azSnatbr -m state --state ESTABLISHED,RELATED -j ACCEPT",
+			want:   nftCmd{table: Filter, action: "-I", chain: CNIInputChain, rule: "-i azSnatbr -m state --state ESTABLISHED,RELATED -j ACCEPT"},
+		},
+		{
+			name:   "append jump",
+			params: "-t nat -A POSTROUTING  -j SWIFT",
+			want:   nftCmd{table: Nat, action: "-A", chain: Postrouting, rule: "-j SWIFT"},
+		},
+		{
+			name:    "insert in the middle",
+			params:  "-t filter -I FORWARD 2 -j ACCEPT",
+			wantErr: true,
+		},
+		{
+			name:    "unknown option",
+			params:  "-t filter -P FORWARD DROP",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNFTCmd(tt.params)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errUnsupportedCommand)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// xtInfo is the xtables extension info of the values in native byte order.
+func xtInfo(values ...uint32) *xt.Unknown {
+	var info xt.Unknown
+	for _, v := range values {
+		info = append(info, binaryutil.NativeEndian.PutUint32(v)...)
+	}
+	return &info
+}
+
+func TestRuleExprs(t *testing.T) {
+	tests := []struct {
+		name    string
+		family  nftables.TableFamily
+		spec    string
+		want    []expr.Any
+		wantErr bool
+	}{
+		{
+			name:   "masquerade prefix",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-s 169.254.128.0/17 -j MASQUERADE",
+			want: []expr.Any{
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 12, Len: 4},
+				&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: []byte{255, 255, 128, 0}, Xor: []byte{0, 0, 0, 0}},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{169, 254, 128, 0}},
+				&expr.Target{Name: "MASQUERADE", Info: &xt.NatIPv4MultiRangeCompat{{}}},
+			},
+		},
+		{
+			name:   "accept from interface",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-i eth0.2 -j ACCEPT",
+			want: []expr.Any{
+				&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte("eth0.2\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+				&expr.Verdict{Kind: expr.VerdictAccept},
+			},
+		},
+		{
+			name:   "snat dns to nc",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-m addrtype ! --dst-type local -d 168.63.129.16 -p udp --dport 53 -j SNAT --to 10.0.0.4",
+			want: []expr.Any{
+				&expr.Match{Name: "addrtype", Rev: 1, Info: &xt.AddrTypeV1{Dest: uint16(xt.AddrTypeLocal), Flags: addrTypeInvertDest}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 16, Len: 4},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{168, 63, 129, 16}},
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{unix.IPPROTO_UDP}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0, 53}},
+				&expr.Target{Name: "SNAT", Rev: 1, Info: &xt.NatRange{Flags: uint(xt.NatRangeMapIPs), MinIP: net.IP{10, 0, 0, 4}, MaxIP: net.IP{10, 0, 0, 4}}},
+			},
+		},
+		{
+			name:   "established from bridge",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-o azSnatbr -m state --state ESTABLISHED,RELATED -j ACCEPT",
+			want: []expr.Any{
+				&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte("azSnatbr\x00\x00\x00\x00\x00\x00\x00\x00")},
+				&expr.Match{Name: "conntrack", Rev: 3, Info: &xt.ConntrackMtinfo3{ConntrackMtinfo2: xt.ConntrackMtinfo2{
+					ConntrackMtinfoBase: xt.ConntrackMtinfoBase{MatchFlags: uint16(xt.ConntrackState)},
+					StateMask:           uint16(expr.CtStateBitESTABLISHED | expr.CtStateBitRELATED),
+				}}},
+				&expr.Verdict{Kind: expr.VerdictAccept},
+			},
+		},
+		{
+			name:   "host port dnat",
+			family: nftables.TableFamilyIPv6,
+			spec:   "-p tcp --dport 8080 -j DNAT --to-destination [fd00::4]:80",
+			want: []expr.Any{
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{unix.IPPROTO_TCP}},
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0x1f, 0x90}},
+				&expr.Target{Name: "DNAT", Rev: 1, Info: &xt.NatRange{
+					Flags:   uint(xt.NatRangeMapIPs | xt.NatRangeProtoSpecified),
+					MinIP:   net.ParseIP("fd00::4"),
+					MaxIP:   net.ParseIP("fd00::4"),
+					MinPort: 80,
+					MaxPort: 80,
+				}},
+			},
+		},
+		{
+			name:   "set mark",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-j MARK --set-mark 333",
+			want: []expr.Any{
+				&expr.Target{Name: "MARK", Rev: 2, Info: xtInfo(333, 0xffffffff)},
+			},
+		},
+		{
+			name:   "not marked",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-m mark ! --mark 0x2000/0x2000 -j RETURN",
+			want: []expr.Any{
+				&expr.Match{Name: "mark", Rev: 1, Info: xtInfo(0x2000, 0x2000, 1, 0)},
+				&expr.Verdict{Kind: expr.VerdictReturn},
+			},
+		},
+		{
+			name:   "jump",
+			family: nftables.TableFamilyIPv4,
+			spec:   "-j AZURECNIINPUT",
+			want:   []expr.Any{&expr.Verdict{Kind: expr.VerdictJump, Chain: CNIInputChain}},
+		},
+		{
+			name:    "address of the wrong family",
+			family:  nftables.TableFamilyIPv6,
+			spec:    "-s 10.0.0.0/8 -j ACCEPT",
+			wantErr: true,
+		},
+		{
+			name:    "port without protocol",
+			family:  nftables.TableFamilyIPv4,
+			spec:    "--dport 53 -j ACCEPT",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported match",
+			family:  nftables.TableFamilyIPv4,
+			spec:    "-m physdev --physdev-in eth0 -j ACCEPT",
+			wantErr: true,
+		},
+		{
+			name:    "no target",
+			family:  nftables.TableFamilyIPv4,
+			spec:    "-s 10.0.0.0/8",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ruleExprs(tt.family, tt.spec)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errUnsupportedRule)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// newTestNFTClient returns a client for a new network namespace, or skips the test if nftables can not be
+// programmed.
+func newTestNFTClient(t *testing.T) *nftClient {
+	t.Helper()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ns := netns.New()
+	origin, err := ns.Get()
+	require.NoError(t, err)
+	defer unix.Close(origin)
+
+	name := "nftest"
+	fd, err := ns.NewNamed(name)
+	if err != nil {
+		t.Skipf("unable to create a network namespace: %v", err)
+	}
+	require.NoError(t, ns.Set(origin))
+	t.Cleanup(func() {
+		unix.Close(fd)
+		_ = ns.DeleteNamed(name)
+	})
+
+	c, err := newNFTClient(nftables.WithNetNSFd(fd))
+	if err != nil {
+		t.Skipf("nftables is unavailable: %v", err)
+	}
+	return c
+}
+
+func TestNFTClient(t *testing.T) {
+	c := newTestNFTClient(t)
+	table := &nftables.Table{Name: Filter, Family: nftables.TableFamilyIPv4}
+
+	require.ErrorIs(t, c.run(V4, "-t filter -nL AZURECNIINPUT"), errChainNotFound)
+	require.ErrorIs(t, c.run(V4, "-t filter -A AZURECNIINPUT -j ACCEPT"), errChainNotFound)
+	require.NoError(t, c.run(V4, "-t filter -N AZURECNIINPUT"))
+	require.NoError(t, c.run(V4, "-t filter -nL AZURECNIINPUT"))
+
+	// the built-in chain and its table are created with the first rule.
+	require.NoError(t, c.run(V4, "-t filter -I INPUT 1  -j AZURECNIINPUT"))
+	require.NoError(t, c.run(V4, "-t filter -A AZURECNIINPUT -s 10.0.0.0/8 -j DROP"))
+	require.NoError(t, c.run(V4, "-t filter -I AZURECNIINPUT 1 -i azSnatbr -m state --state ESTABLISHED,RELATED -j ACCEPT"))
+	require.NoError(t, c.run(V4, "-t filter -C INPUT -j AZURECNIINPUT"))
+
+	conn, err := nftables.New(c.opts...)
+	require.NoError(t, err)
+	chain, err := conn.ListChain(table, CNIInputChain)
+	require.NoError(t, err)
+	rules, err := conn.GetRules(table, chain)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	var specs []string
+	for _, rule := range rules {
+		spec, _ := userdata.GetString(rule.UserData, userdata.TypeComment)
+		specs = append(specs, spec)
+	}
+	require.Equal(t, []string{"-i azSnatbr -m state --state ESTABLISHED,RELATED -j ACCEPT", "-s 10.0.0.0/8 -j DROP"}, specs)
+
+	require.NoError(t, c.run(V4, "-t filter -D AZURECNIINPUT -s 10.0.0.0/8 -j DROP"))
+	require.ErrorIs(t, c.run(V4, "-t filter -C AZURECNIINPUT -s 10.0.0.0/8 -j DROP"), errRuleNotFound)
+	require.ErrorIs(t, c.run(V4, "-t filter -D AZURECNIINPUT -s 10.0.0.0/8 -j DROP"), errRuleNotFound)
+
+	require.NoError(t, c.run(V4, "-t filter -F AZURECNIINPUT"))
+	require.NoError(t, c.run(V4, "-t filter -D INPUT -j AZURECNIINPUT"))
+	require.NoError(t, c.run(V4, "-t filter -X AZURECNIINPUT"))
+	require.ErrorIs(t, c.run(V4, "-t filter -nL AZURECNIINPUT"), errChainNotFound)
+
+	// nat rules of both families.
+	require.NoError(t, c.run(V4, "-t nat -A POSTROUTING -s 169.254.128.0/17 -j MASQUERADE"))
+	require.NoError(t, c.run(V6, "-t nat -N AZURECNIHOSTPORT"))
+	require.NoError(t, c.run(V6, "-t nat -A AZURECNIHOSTPORT -p tcp --dport 8080 -j DNAT --to-destination [fd00::4]:80"))
+	require.NoError(t, c.run(V6, "-t mangle -A POSTROUTING -j MARK --set-mark 0x0"))
+
+	// a batch is applied as a whole, or not at all if one of its commands fails.
+	require.ErrorIs(t, c.run(V4, "-t nat -N AZURECNIHOSTPORT", "-t nat -A AZURECNIHOSTPORT -j ACCEPT", "-t nat -A MISSING -j ACCEPT"), errChainNotFound)
+	require.ErrorIs(t, c.run(V4, "-t nat -nL AZURECNIHOSTPORT"), errChainNotFound)
+	require.NoError(t, c.run(V4, "-t nat -N AZURECNIHOSTPORT", "-t nat -A AZURECNIHOSTPORT -j ACCEPT", "-t nat -A PREROUTING -j AZURECNIHOSTPORT"))
+
+	saved, err := c.save(V4, Nat)
+	require.NoError(t, err)
+	state := parseSave(saved)
+	require.True(t, state.chains["AZURECNIHOSTPORT"])
+	require.Equal(t, 1, state.rules[Prerouting][ruleKey("-j AZURECNIHOSTPORT")])
+	require.Equal(t, 1, state.rules[Postrouting][ruleKey("-s 169.254.128.0/17 -j MASQUERADE")])
+
+	// rules appended to an existing built-in chain keep its policy.
+	drop := nftables.ChainPolicyDrop
+	conn.AddChain(&nftables.Chain{
+		Name:     Forward,
+		Table:    table,
+		Hooknum:  nftables.ChainHookForward,
+		Priority: nftables.ChainPriorityFilter,
+		Type:     nftables.ChainTypeFilter,
+		Policy:   &drop,
+	})
+	require.NoError(t, conn.Flush())
+	require.NoError(t, c.run(V4, "-t filter -A FORWARD -m addrtype ! --dst-type local -m mark --mark 0x2000/0x2000 -j ACCEPT"))
+	forward, err := conn.ListChain(table, Forward)
+	require.NoError(t, err)
+	require.Equal(t, nftables.ChainPolicyDrop, *forward.Policy)
+}
@@ -0,0 +1,9 @@
+package iptables
+
+func runNFTCmd(string, ...string) error {
+	return errNFTablesUnavailable
+}
+
+func saveNFT(string, string) (string, error) {
+	return "", errNFTablesUnavailable
+}
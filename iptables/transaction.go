@@ -0,0 +1,408 @@
+package iptables
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	iptablesSave     = "iptables-save"
+	ip6tablesSave    = "ip6tables-save"
+	iptablesRestore  = "iptables-restore"
+	ip6tablesRestore = "ip6tables-restore"
+)
+
+// saveTable and restoreTables run iptables-save and iptables-restore, they are replaced in tests.
+var (
+	saveTable     = execSave
+	restoreTables = execRestore
+)
+
+// RuleChange is a change of a transaction. Match and Target are only set for rule changes.
+type RuleChange struct {
+	Action string
+	Table  string
+	Chain  string
+	Match  string
+	Target string
+}
+
+// Transaction collects the rule changes of an ip version which are applied together by Commit. Changes which are
+// already in effect, e.g. adding a rule that exists or deleting a chain that is gone, are skipped, so the same
+// transaction can be committed again.
+type Transaction struct {
+	version string
+	changes []RuleChange
+	commit  func(version string, changes []RuleChange) error
+}
+
+// NewTransaction returns a transaction which is applied with commit.
+func NewTransaction(version string, commit func(version string, changes []RuleChange) error) *Transaction {
+	return &Transaction{version: version, commit: commit}
+}
+
+// Begin starts a transaction. Commit applies its changes with one iptables-restore per table, so a table sees
+// either all of them or none.
+func (c *Client) Begin(version string) *Transaction {
+	return NewTransaction(version, c.commit)
+}
+
+// CreateChain creates the chain if it does not exist.
+func (t *Transaction) CreateChain(tableName, chainName string) {
+	t.changes = append(t.changes, RuleChange{Action: NewChain, Table: tableName, Chain: chainName})
+}
+
+// DeleteChain flushes and deletes the chain if it exists.
+func (t *Transaction) DeleteChain(tableName, chainName string) {
+	t.changes = append(t.changes, RuleChange{Action: DeleteChain, Table: tableName, Chain: chainName})
+}
+
+// Add appends the rule to the chain if it does not exist.
+func (t *Transaction) Add(tableName, chainName, match, target string) {
+	t.changes = append(t.changes, RuleChange{Action: Append, Table: tableName, Chain: chainName, Match: match, Target: target})
+}
+
+// Insert inserts the rule at the beginning of the chain if it does not exist.
+func (t *Transaction) Insert(tableName, chainName, match, target string) {
+	t.changes = append(t.changes, RuleChange{Action: Insert, Table: tableName, Chain: chainName, Match: match, Target: target})
+}
+
+// Delete deletes the rule if it exists.
+func (t *Transaction) Delete(tableName, chainName, match, target string) {
+	t.changes = append(t.changes, RuleChange{Action: Delete, Table: tableName, Chain: chainName, Match: match, Target: target})
+}
+
+// DeleteCommented deletes the rules of the chain whose comment is comment, e.g. rules tagged with the id of their
+// owner when the rules themselves were not recorded.
+func (t *Transaction) DeleteCommented(tableName, chainName, comment string) {
+	t.changes = append(t.changes, RuleChange{Action: DeleteCommented, Table: tableName, Chain: chainName, Match: comment})
+}
+
+// AddChanges adds changes built without a client, e.g. by the CNI IPAM invoker, to the transaction.
+func (t *Transaction) AddChanges(changes ...RuleChange) {
+	t.changes = append(t.changes, changes...)
+}
+
+// Changes returns the changes of the transaction in order.
+func (t *Transaction) Changes() []RuleChange {
+	return t.changes
+}
+
+// Commit applies the changes of the transaction.
+func (t *Transaction) Commit() error {
+	if len(t.changes) == 0 {
+		return nil
+	}
+	return t.commit(t.version, t.changes)
+}
+
+// commit applies the changes table by table, in the order the tables first appear in the changes.
+func (c *Client) commit(version string, changes []RuleChange) error {
+	var tables []string
+	byTable := map[string][]RuleChange{}
+	for _, change := range changes {
+		if _, ok := byTable[change.Table]; !ok {
+			tables = append(tables, change.Table)
+		}
+		byTable[change.Table] = append(byTable[change.Table], change)
+	}
+
+	for _, table := range tables {
+		saved, err := c.save(version, table)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save table %s", table)
+		}
+
+		lines := parseSave(saved).plan(byTable[table])
+		if len(lines) == 0 {
+			logger.Info("Table is up to date", zap.String("version", version), zap.String("table", table))
+			continue
+		}
+
+		if err := c.restore(version, table, lines); err != nil {
+			return errors.Wrapf(err, "failed to restore table %s", table)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) save(version, table string) (string, error) {
+	if EnableNFTables {
+		out, err := saveNFT(version, table)
+		if !errors.Is(err, errNFTablesUnavailable) {
+			return out, err
+		}
+		logger.Warn("Falling back to iptables-save", zap.Error(err))
+	}
+	return saveTable(version, table)
+}
+
+// restore applies the iptables-restore lines of the table in one transaction.
+func (c *Client) restore(version, table string, lines []string) error {
+	logger.Info("Restoring iptables rules", zap.String("version", version), zap.String("table", table), zap.Strings("lines", lines))
+
+	if EnableNFTables {
+		params := make([]string, 0, len(lines))
+		for _, line := range lines {
+			if strings.HasPrefix(line, ":") {
+				line = "-N " + strings.Fields(line[1:])[0]
+			}
+			params = append(params, fmt.Sprintf("-t %s %s", table, line))
+		}
+		err := runNFTCmd(version, params...)
+		if !errors.Is(err, errNFTablesUnavailable) {
+			return err
+		}
+		logger.Warn("Falling back to iptables-restore", zap.Error(err))
+	}
+
+	var payload strings.Builder
+	fmt.Fprintf(&payload, "*%s\n", table)
+	for _, line := range lines {
+		payload.WriteString(line + "\n")
+	}
+	payload.WriteString("COMMIT\n")
+	return restoreTables(version, payload.String())
+}
+
+func execSave(version, table string) (string, error) {
+	saveCmd := iptablesSave
+	if version == V6 {
+		saveCmd = ip6tablesSave
+	}
+
+	out, err := exec.Command(saveCmd, "-t", table).Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "%s -t %s failed", saveCmd, table)
+	}
+	return string(out), nil
+}
+
+// execRestore runs iptables-restore without flushing the tables of the payload.
+func execRestore(version, payload string) error {
+	restoreCmd := iptablesRestore
+	if version == V6 {
+		restoreCmd = ip6tablesRestore
+	}
+
+	args := []string{"--noflush"}
+	if !DisableIPTableLock {
+		args = append(args, "-w", strconv.Itoa(lockTimeout))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(restoreCmd, args...)
+	cmd.Stdin = strings.NewReader(payload)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "%s failed: %s", restoreCmd, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// tableState is the content of a table as seen by iptables-save.
+type tableState struct {
+	chains map[string]bool
+	// rules counts the rules of each chain by rule key.
+	rules map[string]map[string]int
+	// specs are the rule specs of each chain as printed by iptables-save.
+	specs map[string][]string
+}
+
+// parseSave parses the iptables-save output of a table.
+func parseSave(out string) *tableState {
+	s := &tableState{chains: map[string]bool{}, rules: map[string]map[string]int{}, specs: map[string][]string{}}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) > 0 && strings.HasPrefix(fields[0], ":"):
+			s.chains[fields[0][1:]] = true
+		case len(fields) > 1 && fields[0] == "-A":
+			spec := strings.Join(fields[2:], " ")
+			s.addRule(fields[1], ruleKey(spec))
+			s.specs[fields[1]] = append(s.specs[fields[1]], spec)
+		}
+	}
+	return s
+}
+
+func (s *tableState) addRule(chain, key string) {
+	s.chains[chain] = true
+	if s.rules[chain] == nil {
+		s.rules[chain] = map[string]int{}
+	}
+	s.rules[chain][key]++
+}
+
+// plan returns the iptables-restore lines for the changes which are not in effect yet. New chains are declared
+// first, with --noflush only the declared chains are flushed so existing chains are never declared.
+func (s *tableState) plan(changes []RuleChange) []string {
+	var chains, lines []string
+	for _, change := range changes {
+		spec := strings.Join(strings.Fields(fmt.Sprintf("%s -j %s", change.Match, change.Target)), " ")
+		key := ruleKey(spec)
+
+		switch change.Action {
+		case NewChain:
+			if s.chains[change.Chain] {
+				continue
+			}
+			s.chains[change.Chain] = true
+			chains = append(chains, fmt.Sprintf(":%s - [0:0]", change.Chain))
+		case DeleteChain:
+			if !s.chains[change.Chain] {
+				continue
+			}
+			delete(s.chains, change.Chain)
+			delete(s.rules, change.Chain)
+			delete(s.specs, change.Chain)
+			lines = append(lines, "-F "+change.Chain, "-X "+change.Chain)
+		case Append, Insert:
+			if s.rules[change.Chain][key] > 0 {
+				continue
+			}
+			s.addRule(change.Chain, key)
+			if change.Action == Append {
+				lines = append(lines, fmt.Sprintf("-A %s %s", change.Chain, spec))
+			} else {
+				lines = append(lines, fmt.Sprintf("-I %s 1 %s", change.Chain, spec))
+			}
+		case Delete:
+			if s.rules[change.Chain][key] == 0 {
+				continue
+			}
+			s.rules[change.Chain][key]--
+			lines = append(lines, fmt.Sprintf("-D %s %s", change.Chain, spec))
+		case DeleteCommented:
+			var kept []string
+			for _, saved := range s.specs[change.Chain] {
+				savedKey := ruleKey(saved)
+				if ruleComment(saved) != change.Match || s.rules[change.Chain][savedKey] == 0 {
+					kept = append(kept, saved)
+					continue
+				}
+				s.rules[change.Chain][savedKey]--
+				lines = append(lines, fmt.Sprintf("-D %s %s", change.Chain, saved))
+			}
+			s.specs[change.Chain] = kept
+		}
+	}
+	return append(chains, lines...)
+}
+
+// ruleComment returns the comment of a rule spec, or "" if it has none.
+func ruleComment(spec string) string {
+	fields := strings.Fields(spec)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "--comment" {
+			return strings.Trim(fields[i+1], `"`)
+		}
+	}
+	return ""
+}
+
+// ruleKey normalizes a rule spec so a rule built by the client compares equal to the rule printed by iptables-save,
+// which orders the matches its own way, loads match modules explicitly and prints addresses and marks in canonical
+// form.
+func ruleKey(spec string) string {
+	fields := strings.Fields(strings.ReplaceAll(spec, `"`, ""))
+	j := len(fields)
+	for i, f := range fields {
+		if f == "-j" || f == "-g" {
+			j = i
+			break
+		}
+	}
+
+	var groups []string
+	for i := 0; i < j; {
+		group := []string{fields[i]}
+		if fields[i] == "!" && i+1 < j {
+			i++
+			group = append(group, fields[i])
+		}
+		option := group[len(group)-1]
+		for i++; i < j && !strings.HasPrefix(fields[i], "-") && fields[i] != "!"; i++ {
+			group = append(group, normalizeValue(option, fields[i]))
+		}
+		if option != "-m" {
+			groups = append(groups, strings.Join(group, " "))
+		}
+	}
+	sort.Strings(groups)
+
+	return strings.Join(append(groups, normalizeTarget(fields[j:])...), " ")
+}
+
+func normalizeValue(option, value string) string {
+	switch option {
+	case "-s", "-d":
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		if _, ipNet, err := net.ParseCIDR(value); err == nil {
+			return ipNet.String()
+		}
+	case "--state", "--ctstate":
+		states := strings.Split(value, ",")
+		sort.Strings(states)
+		return strings.Join(states, ",")
+	case "-p":
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+// normalizeTarget rewrites the target options to the form printed by iptables-save.
+func normalizeTarget(target []string) []string {
+	if len(target) < 2 {
+		return target
+	}
+	normalized := append([]string(nil), target...)
+	for i := 2; i < len(normalized); i++ {
+		switch opt := normalized[i]; {
+		case opt == "--to" && normalized[1] == "SNAT":
+			normalized[i] = "--to-source"
+		case opt == "--to" && normalized[1] == "DNAT":
+			normalized[i] = "--to-destination"
+		case (opt == "--set-mark" || opt == "--set-xmark") && i+1 < len(normalized):
+			if mark, ok := xmark(normalized[i+1], opt == "--set-mark"); ok {
+				normalized[i], normalized[i+1] = "--set-xmark", mark
+			}
+		}
+	}
+	return normalized
+}
+
+// xmark returns the value/mask of a MARK --set-xmark option. --set-mark value/mask is the same as
+// --set-xmark value/(mask|value).
+func xmark(mark string, setMark bool) (string, bool) {
+	valueStr, maskStr, hasMask := strings.Cut(mark, "/")
+	value, err := strconv.ParseUint(valueStr, 0, 32)
+	if err != nil {
+		return "", false
+	}
+	mask := uint64(0xffffffff)
+	if hasMask {
+		if mask, err = strconv.ParseUint(maskStr, 0, 32); err != nil {
+			return "", false
+		}
+		if setMark {
+			mask |= value
+		}
+	}
+	return fmt.Sprintf("%#x/%#x", value, mask), true
+}
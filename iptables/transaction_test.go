@@ -0,0 +1,152 @@
+package iptables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const natSave = `# Generated by iptables-save v1.8.7 on Thu Jan  1 00:00:00 1970
+*nat
+:PREROUTING ACCEPT [0:0]
+:INPUT ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:POSTROUTING ACCEPT [0:0]
+:AZURECNIHOSTPORT - [0:0]
+-A PREROUTING -j AZURECNIHOSTPORT
+-A POSTROUTING -m mark --mark 0x80/0x80 -j MASQUERADE
+-A POSTROUTING -s 169.254.128.0/17 -m comment --comment "snat for pods" -j SNAT --to-source 10.0.0.4
+-A AZURECNIHOSTPORT -d 10.1.0.0/16 -p tcp -m tcp --dport 8080 -j MARK --set-xmark 0x80/0x80
+-A AZURECNIHOSTPORT -m addrtype --dst-type LOCAL -m comment --comment "ep3" -j AZCNIHP-3
+COMMIT
+# Completed on Thu Jan  1 00:00:00 1970
+`
+
+func TestRuleKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  string
+		saved string
+	}{
+		{
+			name:  "jump",
+			spec:  " -j AZURECNIHOSTPORT",
+			saved: "-j AZURECNIHOSTPORT",
+		},
+		{
+			name:  "match modules and order",
+			spec:  "-p tcp --dport 8080 -d 10.0.0.4 -s 10.0.0.5 -j DNAT --to-destination 10.0.0.5:80",
+			saved: "-s 10.0.0.5/32 -d 10.0.0.4/32 -p tcp -m tcp --dport 8080 -j DNAT --to-destination 10.0.0.5:80",
+		},
+		{
+			name:  "negation and states",
+			spec:  "! -d 10.1.0.0/16 -m state --state ESTABLISHED,RELATED -j ACCEPT",
+			saved: "! -d 10.1.0.0/16 -m state --state RELATED,ESTABLISHED -j ACCEPT",
+		},
+		{
+			name:  "ipv6 address",
+			spec:  "-s fd00::4 -j MASQUERADE",
+			saved: "-s fd00::4/128 -j MASQUERADE",
+		},
+		{
+			name:  "comment",
+			spec:  "-m comment --comment abc -j ACCEPT",
+			saved: `-m comment --comment "abc" -j ACCEPT`,
+		},
+		{
+			name:  "snat",
+			spec:  "-s 169.254.128.0/17 -j SNAT --to 10.0.0.4",
+			saved: "-s 169.254.128.0/17 -j SNAT --to-source 10.0.0.4",
+		},
+		{
+			name:  "set mark",
+			spec:  "-j MARK --set-mark 8192",
+			saved: "-j MARK --set-xmark 0x2000/0xffffffff",
+		},
+		{
+			name:  "set masked mark",
+			spec:  "-j MARK --set-mark 0x2000/0x3000",
+			saved: "-j MARK --set-xmark 0x2000/0x3000",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, ruleKey(tt.saved), ruleKey(tt.spec))
+		})
+	}
+
+	require.NotEqual(t, ruleKey("-d 10.0.0.4 -j ACCEPT"), ruleKey("! -d 10.0.0.4 -j ACCEPT"))
+	require.NotEqual(t, ruleKey("-p tcp --dport 80 -j ACCEPT"), ruleKey("-p udp --dport 80 -j ACCEPT"))
+}
+
+func TestPlan(t *testing.T) {
+	changes := []RuleChange{
+		{Action: NewChain, Table: Nat, Chain: "AZURECNIHOSTPORT"},
+		{Action: NewChain, Table: Nat, Chain: "AZCNIHP-1"},
+		{Action: Append, Table: Nat, Chain: Prerouting, Target: "AZURECNIHOSTPORT"},
+		{Action: Append, Table: Nat, Chain: Output, Target: "AZURECNIHOSTPORT"},
+		{Action: Append, Table: Nat, Chain: Postrouting, Match: "-m mark --mark 0x80/0x80", Target: Masquerade},
+		{Action: Append, Table: Nat, Chain: "AZCNIHP-1", Match: "-p tcp --dport 8080 -s 10.0.0.5", Target: "MARK --set-xmark 0x80/0x80"},
+		{Action: Append, Table: Nat, Chain: "AZCNIHP-1", Match: "-p tcp --dport 8080 -s 10.0.0.5", Target: "MARK --set-xmark 0x80/0x80"},
+		{Action: Insert, Table: Nat, Chain: "AZURECNIHOSTPORT", Match: "-m comment --comment ep1", Target: "AZCNIHP-1"},
+		{Action: Delete, Table: Nat, Chain: "AZURECNIHOSTPORT", Match: "-p tcp --dport 8080 -d 10.1.0.0/16", Target: "MARK --set-xmark 0x80/0x80"},
+		{Action: Delete, Table: Nat, Chain: "AZURECNIHOSTPORT", Match: "-p tcp --dport 8080 -d 10.1.0.0/16", Target: "MARK --set-xmark 0x80/0x80"},
+		{Action: DeleteChain, Table: Nat, Chain: "AZCNIHP-2"},
+	}
+
+	lines := parseSave(natSave).plan(changes)
+	require.Equal(t, []string{
+		":AZCNIHP-1 - [0:0]",
+		"-A OUTPUT -j AZURECNIHOSTPORT",
+		"-A AZCNIHP-1 -p tcp --dport 8080 -s 10.0.0.5 -j MARK --set-xmark 0x80/0x80",
+		"-I AZURECNIHOSTPORT 1 -m comment --comment ep1 -j AZCNIHP-1",
+		"-D AZURECNIHOSTPORT -p tcp --dport 8080 -d 10.1.0.0/16 -j MARK --set-xmark 0x80/0x80",
+	}, lines)
+
+	lines = parseSave(natSave).plan([]RuleChange{{Action: DeleteChain, Table: Nat, Chain: "AZURECNIHOSTPORT"}})
+	require.Equal(t, []string{"-F AZURECNIHOSTPORT", "-X AZURECNIHOSTPORT"}, lines)
+
+	// rules are found by their comment, and deleted once
+	lines = parseSave(natSave).plan([]RuleChange{
+		{Action: DeleteCommented, Table: Nat, Chain: "AZURECNIHOSTPORT", Match: "ep3"},
+		{Action: DeleteCommented, Table: Nat, Chain: "AZURECNIHOSTPORT", Match: "ep3"},
+		{Action: DeleteCommented, Table: Nat, Chain: "AZURECNIHOSTPORT", Match: "ep4"},
+	})
+	require.Equal(t, []string{`-D AZURECNIHOSTPORT -m addrtype --dst-type LOCAL -m comment --comment "ep3" -j AZCNIHP-3`}, lines)
+}
+
+func TestCommit(t *testing.T) {
+	saves := map[string]string{V4 + Nat: natSave}
+	var restored []string
+	saveTable = func(version, table string) (string, error) {
+		return saves[version+table], nil
+	}
+	restoreTables = func(version, payload string) error {
+		restored = append(restored, version+":"+payload)
+		return nil
+	}
+	t.Cleanup(func() {
+		saveTable, restoreTables = execSave, execRestore
+	})
+
+	c := NewClient()
+	tx := c.Begin(V4)
+	tx.CreateChain(Nat, "AZURECNIHOSTPORT")
+	tx.Add(Nat, Prerouting, "", "AZURECNIHOSTPORT")
+	tx.Insert(Filter, Forward, "-d 10.0.0.4", Drop)
+	tx.Add(Nat, Output, "", "AZURECNIHOSTPORT")
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{
+		"4:*nat\n-A OUTPUT -j AZURECNIHOSTPORT\nCOMMIT\n",
+		"4:*filter\n-I FORWARD 1 -d 10.0.0.4 -j DROP\nCOMMIT\n",
+	}, restored)
+
+	// nothing is restored if the table is up to date.
+	restored = nil
+	tx = c.Begin(V4)
+	tx.Add(Nat, Prerouting, "", "AZURECNIHOSTPORT")
+	tx.Delete(Nat, Output, "", "AZURECNIHOSTPORT")
+	require.NoError(t, tx.Commit())
+	require.Empty(t, restored)
+}
@@ -11,11 +11,14 @@ import (
 
 type getInterfaceValidationFn func(name string) (*net.Interface, error)
 
+type getInterfaceAddrsFn func(iface *net.Interface) ([]net.Addr, error)
+
 type MockNetIO struct {
 	fail           bool
 	failAttempt    int
 	numTimesCalled int
 	getInterfaceFn getInterfaceValidationFn
+	getAddrsFn     getInterfaceAddrsFn
 }
 
 // ErrMockNetIOFail - mock netio error
@@ -36,6 +39,10 @@ func (netshim *MockNetIO) SetGetInterfaceValidatonFn(fn getInterfaceValidationFn
 	netshim.getInterfaceFn = fn
 }
 
+func (netshim *MockNetIO) SetGetInterfaceAddrsFn(fn getInterfaceAddrsFn) {
+	netshim.getAddrsFn = fn
+}
+
 func (netshim *MockNetIO) GetNetworkInterfaceByName(name string) (*net.Interface, error) {
 	netshim.numTimesCalled++
 
@@ -58,6 +65,10 @@ func (netshim *MockNetIO) GetNetworkInterfaceByName(name string) (*net.Interface
 }
 
 func (netshim *MockNetIO) GetNetworkInterfaceAddrs(iface *net.Interface) ([]net.Addr, error) {
+	if netshim.getAddrsFn != nil {
+		return netshim.getAddrsFn(iface)
+	}
+
 	return []net.Addr{}, nil
 }
 
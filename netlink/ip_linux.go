@@ -7,8 +7,10 @@
 package netlink
 
 import (
+	"bytes"
 	"net"
 
+	"github.com/pkg/errors"
 	"golang.org/x/sys/unix"
 )
 
@@ -79,6 +81,61 @@ func (n Netlink) DeleteIPAddress(ifName string, ipAddress net.IP, ipNet *net.IPN
 	return n.setIPAddress(ifName, ipAddress, ipNet, false)
 }
 
+// Address represents an IP address of a network interface.
+type Address struct {
+	LinkIndex int
+	Family    int
+	IPNet     *net.IPNet
+	Scope     int
+	Flags     int
+}
+
+// deserializeAddress decodes a netlink message into an Address struct.
+func deserializeAddress(msg *message) (*Address, error) {
+	if len(msg.data) < unix.SizeofIfAddrmsg {
+		return nil, errors.Wrap(errInvalidMessage, "address message too short")
+	}
+
+	// Parse interface address message.
+	ifAddr := deserializeIfAddrMsg(msg.data)
+	attrs := msg.getAttributes(ifAddr)
+
+	address := Address{
+		LinkIndex: int(ifAddr.Index),
+		Family:    int(ifAddr.Family),
+		Scope:     int(ifAddr.Scope),
+		Flags:     int(ifAddr.Flags),
+	}
+
+	// Populate address attributes.
+	// The local address is the address of the interface, IFA_ADDRESS is the peer
+	// address on point-to-point interfaces.
+	var local, peer net.IP
+	for _, attr := range attrs {
+		switch attr.Type {
+		case unix.IFA_LOCAL:
+			local = net.IP(attr.value)
+		case unix.IFA_ADDRESS:
+			peer = net.IP(attr.value)
+		case unix.IFA_FLAGS:
+			address.Flags = int(encoder.Uint32(attr.value[0:4]))
+		}
+	}
+
+	ip := local
+	if ip == nil {
+		ip = peer
+	}
+	if ip != nil {
+		address.IPNet = &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(int(ifAddr.Prefixlen), 8*len(ip)),
+		}
+	}
+
+	return &address, nil
+}
+
 // Route represents a netlink route.
 type Route struct {
 	Family     int
@@ -98,6 +155,10 @@ type Route struct {
 
 // deserializeRoute decodes a netlink message into a Route struct.
 func deserializeRoute(msg *message) (*Route, error) {
+	if len(msg.data) < unix.SizeofRtMsg {
+		return nil, errors.Wrap(errInvalidMessage, "route message too short")
+	}
+
 	// Parse route message.
 	rtmsg := deserializeRtMsg(msg.data)
 	attrs := msg.getAttributes(rtmsg)
@@ -289,6 +350,191 @@ func (Netlink) DeleteIPRoute(route *Route) error {
 	return setIpRoute(route, false)
 }
 
+// Rule represents a netlink policy routing rule.
+type Rule struct {
+	Family   int
+	Priority int
+	Table    int
+	Mark     int
+	Mask     int
+	Src      *net.IPNet
+	Dst      *net.IPNet
+	IifName  string
+	OifName  string
+	Invert   bool
+}
+
+// deserializeRule decodes a netlink message into a Rule struct.
+func deserializeRule(msg *message) (*Rule, error) {
+	if len(msg.data) < sizeofRuleMsg {
+		return nil, errors.Wrap(errInvalidMessage, "rule message too short")
+	}
+
+	// Parse rule message.
+	rulemsg := deserializeRuleMsg(msg.data)
+	attrs := msg.getAttributes(rulemsg)
+
+	// Initialize a new rule object.
+	rule := Rule{
+		Family: int(rulemsg.Family),
+		Table:  int(rulemsg.Table),
+		Invert: rulemsg.Flags&unix.FIB_RULE_INVERT != 0,
+	}
+
+	// Populate rule attributes.
+	for _, attr := range attrs {
+		switch attr.Type {
+		case unix.FRA_SRC:
+			rule.Src = &net.IPNet{
+				IP:   attr.value,
+				Mask: net.CIDRMask(int(rulemsg.SrcLen), 8*len(attr.value)),
+			}
+		case unix.FRA_DST:
+			rule.Dst = &net.IPNet{
+				IP:   attr.value,
+				Mask: net.CIDRMask(int(rulemsg.DstLen), 8*len(attr.value)),
+			}
+		case unix.FRA_PRIORITY:
+			rule.Priority = int(encoder.Uint32(attr.value[0:4]))
+		case unix.FRA_FWMARK:
+			rule.Mark = int(encoder.Uint32(attr.value[0:4]))
+		case unix.FRA_FWMASK:
+			rule.Mask = int(encoder.Uint32(attr.value[0:4]))
+		case unix.FRA_TABLE:
+			rule.Table = int(encoder.Uint32(attr.value[0:4]))
+		case unix.FRA_IIFNAME:
+			rule.IifName = string(bytes.TrimRight(attr.value, "\x00"))
+		case unix.FRA_OIFNAME:
+			rule.OifName = string(bytes.TrimRight(attr.value, "\x00"))
+		}
+	}
+
+	return &rule, nil
+}
+
+// GetIPRules returns the policy routing rules of the given family, or of all families if family is 0.
+func (Netlink) GetIPRules(family int) ([]*Rule, error) {
+	s, err := getSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(unix.RTM_GETRULE, unix.NLM_F_DUMP)
+	req.addPayload(newRuleMsg(family))
+
+	msgs, err := s.sendAndWaitForResponse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*Rule, 0, len(msgs))
+	for _, msg := range msgs {
+		rule, err := deserializeRule(msg)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// newIPRuleRequest creates a policy routing rule set request.
+func newIPRuleRequest(rule *Rule, add bool) *message {
+	var msgType, flags int
+
+	if add {
+		msgType = unix.RTM_NEWRULE
+		flags = unix.NLM_F_CREATE | unix.NLM_F_EXCL | unix.NLM_F_ACK
+	} else {
+		msgType = unix.RTM_DELRULE
+		flags = unix.NLM_F_ACK
+	}
+
+	req := newRequest(msgType, flags)
+
+	family := rule.Family
+	if family == 0 {
+		family = unix.AF_INET
+		if rule.Src != nil {
+			family = GetIPAddressFamily(rule.Src.IP)
+		} else if rule.Dst != nil {
+			family = GetIPAddressFamily(rule.Dst.IP)
+		}
+	}
+
+	msg := newRuleMsg(family)
+
+	// Tables above 255 only fit in the table attribute.
+	if rule.Table < 256 { //nolint:gomnd // size of the table field
+		msg.Table = uint8(rule.Table)
+	}
+
+	if rule.Invert {
+		msg.Flags |= unix.FIB_RULE_INVERT
+	}
+
+	req.addPayload(msg)
+
+	if rule.Src != nil {
+		prefixLength, _ := rule.Src.Mask.Size()
+		msg.SrcLen = uint8(prefixLength)
+		req.addPayload(newAttributeIpAddress(unix.FRA_SRC, rule.Src.IP))
+	}
+
+	if rule.Dst != nil {
+		prefixLength, _ := rule.Dst.Mask.Size()
+		msg.DstLen = uint8(prefixLength)
+		req.addPayload(newAttributeIpAddress(unix.FRA_DST, rule.Dst.IP))
+	}
+
+	if rule.Priority != 0 {
+		req.addPayload(newAttributeUint32(unix.FRA_PRIORITY, uint32(rule.Priority)))
+	}
+
+	if rule.Mark != 0 {
+		req.addPayload(newAttributeUint32(unix.FRA_FWMARK, uint32(rule.Mark)))
+	}
+
+	if rule.Mask != 0 {
+		req.addPayload(newAttributeUint32(unix.FRA_FWMASK, uint32(rule.Mask)))
+	}
+
+	if rule.Table != 0 {
+		req.addPayload(newAttributeUint32(unix.FRA_TABLE, uint32(rule.Table)))
+	}
+
+	if rule.IifName != "" {
+		req.addPayload(newAttributeStringZ(unix.FRA_IIFNAME, rule.IifName))
+	}
+
+	if rule.OifName != "" {
+		req.addPayload(newAttributeStringZ(unix.FRA_OIFNAME, rule.OifName))
+	}
+
+	return req
+}
+
+// setIPRule sends a policy routing rule set request.
+func setIPRule(rule *Rule, add bool) error {
+	s, err := getSocket()
+	if err != nil {
+		return err
+	}
+
+	return s.sendAndWaitForAck(newIPRuleRequest(rule, add))
+}
+
+// AddIPRule adds a policy routing rule.
+func (Netlink) AddIPRule(rule *Rule) error {
+	return setIPRule(rule, true)
+}
+
+// DeleteIPRule deletes a policy routing rule.
+func (Netlink) DeleteIPRule(rule *Rule) error {
+	return setIPRule(rule, false)
+}
+
 // GetIPAddressFamily returns the address family of an IP address.
 func GetIPAddressFamily(ip net.IP) int {
 	if len(ip) <= net.IPv4len {
@@ -7,6 +7,7 @@
 package netlink
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 
@@ -47,10 +48,12 @@ type Link interface {
 type LinkInfo struct {
 	Type        string
 	Name        string
+	Index       int
 	Flags       net.Flags
 	MTU         uint
 	TxQLen      uint
 	ParentIndex int
+	MasterIndex int
 	MacAddress  net.HardwareAddr
 	IPAddr      net.IP
 }
@@ -423,44 +426,293 @@ func (Netlink) SetLinkHairpin(bridgeName string, on bool) error {
 }
 
 // SetOrRemoveLinkAddress sets/removes static arp entry based on mode
-func (Netlink) SetOrRemoveLinkAddress(linkInfo LinkInfo, mode, linkState int) error {
-	s, err := getSocket()
+func (n Netlink) SetOrRemoveLinkAddress(linkInfo LinkInfo, mode, linkState int) error {
+	iface, err := net.InterfaceByName(linkInfo.Name)
 	if err != nil {
 		return err
 	}
 
-	var req *message
-	state := 0
+	neigh := &Neigh{
+		LinkIndex:    iface.Index,
+		State:        linkState,
+		IP:           linkInfo.IPAddr,
+		HardwareAddr: linkInfo.MacAddress,
+	}
+
 	if mode == ADD {
+		return n.AddNeigh(neigh)
+	}
+	return n.DeleteNeigh(neigh)
+}
+
+// linkFlags converts the interface flags of a link message to net.Flags.
+func linkFlags(rawFlags uint32) net.Flags {
+	var flags net.Flags
+	if rawFlags&unix.IFF_UP != 0 {
+		flags |= net.FlagUp
+	}
+	if rawFlags&unix.IFF_BROADCAST != 0 {
+		flags |= net.FlagBroadcast
+	}
+	if rawFlags&unix.IFF_LOOPBACK != 0 {
+		flags |= net.FlagLoopback
+	}
+	if rawFlags&unix.IFF_POINTOPOINT != 0 {
+		flags |= net.FlagPointToPoint
+	}
+	if rawFlags&unix.IFF_MULTICAST != 0 {
+		flags |= net.FlagMulticast
+	}
+	if rawFlags&unix.IFF_RUNNING != 0 {
+		flags |= net.FlagRunning
+	}
+	return flags
+}
+
+// deserializeLink decodes a netlink message into a Link of the type of the network interface.
+func deserializeLink(msg *message) (Link, error) {
+	if len(msg.data) < unix.SizeofIfInfomsg {
+		return nil, errors.Wrap(errInvalidMessage, "link message too short")
+	}
+
+	// Parse interface info message.
+	ifInfo := deserializeIfInfoMsg(msg.data)
+	attrs := msg.getAttributes(ifInfo)
+
+	info := LinkInfo{
+		Index: int(ifInfo.Index),
+		Flags: linkFlags(ifInfo.Flags),
+	}
+
+	// Populate link attributes.
+	var infoData []byte
+	for _, attr := range attrs {
+		switch attr.Type {
+		case unix.IFLA_IFNAME:
+			info.Name = string(bytes.TrimRight(attr.value, "\x00"))
+		case unix.IFLA_MTU:
+			info.MTU = uint(encoder.Uint32(attr.value[0:4]))
+		case unix.IFLA_TXQLEN:
+			info.TxQLen = uint(encoder.Uint32(attr.value[0:4]))
+		case unix.IFLA_LINK:
+			info.ParentIndex = int(encoder.Uint32(attr.value[0:4]))
+		case unix.IFLA_MASTER:
+			info.MasterIndex = int(encoder.Uint32(attr.value[0:4]))
+		case unix.IFLA_ADDRESS:
+			info.MacAddress = net.HardwareAddr(attr.value)
+		case unix.IFLA_LINKINFO:
+			for _, nested := range parseAttributes(attr.value) {
+				switch nested.Type {
+				case IFLA_INFO_KIND:
+					info.Type = string(bytes.TrimRight(nested.value, "\x00"))
+				case IFLA_INFO_DATA:
+					infoData = nested.value
+				}
+			}
+		}
+	}
+
+	// Decode link type-specific attributes.
+	switch info.Type {
+	case LINK_TYPE_BRIDGE:
+		return &BridgeLink{LinkInfo: info}, nil
+	case LINK_TYPE_VETH:
+		return &VEthLink{LinkInfo: info}, nil
+	case LINK_TYPE_IPVLAN:
+		ipvlan := &IPVlanLink{LinkInfo: info}
+		for _, attr := range parseAttributes(infoData) {
+			if attr.Type == IFLA_IPVLAN_MODE {
+				ipvlan.Mode = IPVlanMode(encoder.Uint16(attr.value[0:2]))
+			}
+		}
+		return ipvlan, nil
+	case LINK_TYPE_DUMMY:
+		return &DummyLink{LinkInfo: info}, nil
+	default:
+		return &info, nil
+	}
+}
+
+// GetLinks returns all the network interfaces.
+func (Netlink) GetLinks() ([]Link, error) {
+	s, err := getSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(unix.RTM_GETLINK, unix.NLM_F_DUMP)
+	req.addPayload(newIfInfoMsg())
+
+	msgs, err := s.sendAndWaitForResponse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]Link, 0, len(msgs))
+	for _, msg := range msgs {
+		link, err := deserializeLink(msg)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// GetLinkByIndex returns the network interface with the given index.
+func (Netlink) GetLinkByIndex(index int) (Link, error) {
+	s, err := getSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(unix.RTM_GETLINK, 0)
+
+	ifInfo := newIfInfoMsg()
+	ifInfo.Index = int32(index)
+	req.addPayload(ifInfo)
+
+	msgs, err := s.sendAndWaitForResponse(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get link %d", index)
+	}
+	if len(msgs) != 1 {
+		return nil, errors.Wrapf(errInvalidMessage, "expected one link message, got %d", len(msgs))
+	}
+
+	return deserializeLink(msgs[0])
+}
+
+// Neigh represents a neighbor (ARP or NDP) cache entry.
+type Neigh struct {
+	LinkIndex    int
+	Family       int
+	State        int
+	Flags        int
+	Type         int
+	IP           net.IP
+	HardwareAddr net.HardwareAddr
+}
+
+// deserializeNeigh decodes a netlink message into a Neigh struct.
+func deserializeNeigh(msg *message) (*Neigh, error) {
+	if len(msg.data) < unix.SizeofNdMsg {
+		return nil, errors.Wrap(errInvalidMessage, "neighbor message too short")
+	}
+
+	// Parse neighbor message.
+	ndmsg := deserializeNeighMsg(msg.data)
+	attrs := msg.getAttributes(ndmsg)
+
+	neigh := Neigh{
+		LinkIndex: int(ndmsg.Index),
+		Family:    int(ndmsg.Family),
+		State:     int(ndmsg.State),
+		Flags:     int(ndmsg.Flags),
+		Type:      int(ndmsg.Type),
+	}
+
+	// Populate neighbor attributes.
+	for _, attr := range attrs {
+		switch attr.Type {
+		case NDA_DST:
+			neigh.IP = net.IP(attr.value)
+		case NDA_LLADDR:
+			neigh.HardwareAddr = net.HardwareAddr(attr.value)
+		}
+	}
+
+	return &neigh, nil
+}
+
+// newNeighRequest creates a neighbor set request.
+func newNeighRequest(neigh *Neigh, add bool) *message {
+	var req *message
+
+	if add {
 		req = newRequest(unix.RTM_NEWNEIGH, unix.NLM_F_CREATE|unix.NLM_F_REPLACE|unix.NLM_F_ACK)
 	} else {
 		req = newRequest(unix.RTM_DELNEIGH, unix.NLM_F_ACK)
 	}
-	state = linkState
 
-	iface, err := net.InterfaceByName(linkInfo.Name)
-	if err != nil {
-		return err
+	family := neigh.Family
+	if family == 0 {
+		family = GetIPAddressFamily(neigh.IP)
 	}
 
 	msg := neighMsg{
-		Family: uint8(GetIPAddressFamily(linkInfo.IPAddr)),
-		Index:  uint32(iface.Index),
-		State:  uint16(state),
+		Family: uint8(family),
+		Index:  uint32(neigh.LinkIndex),
+		State:  uint16(neigh.State),
+		Flags:  uint8(neigh.Flags),
+		Type:   uint8(neigh.Type),
 	}
-
 	req.addPayload(&msg)
 
-	ipData := linkInfo.IPAddr.To4()
+	ipData := neigh.IP.To4()
 	if ipData == nil {
-		ipData = linkInfo.IPAddr.To16()
+		ipData = neigh.IP.To16()
 	}
+	req.addPayload(newRtAttr(NDA_DST, ipData))
 
-	dstData := newRtAttr(NDA_DST, ipData)
-	req.addPayload(dstData)
+	if neigh.HardwareAddr != nil {
+		req.addPayload(newRtAttr(NDA_LLADDR, []byte(neigh.HardwareAddr)))
+	}
 
-	hwData := newRtAttr(NDA_LLADDR, []byte(linkInfo.MacAddress))
-	req.addPayload(hwData)
+	return req
+}
 
-	return s.sendAndWaitForAck(req)
+// setNeigh sends a neighbor set request.
+func setNeigh(neigh *Neigh, add bool) error {
+	s, err := getSocket()
+	if err != nil {
+		return err
+	}
+
+	return s.sendAndWaitForAck(newNeighRequest(neigh, add))
+}
+
+// AddNeigh adds or replaces a neighbor cache entry.
+func (Netlink) AddNeigh(neigh *Neigh) error {
+	return setNeigh(neigh, true)
+}
+
+// DeleteNeigh deletes a neighbor cache entry.
+func (Netlink) DeleteNeigh(neigh *Neigh) error {
+	return setNeigh(neigh, false)
+}
+
+// GetNeighs returns the neighbor cache entries of the given family on a network interface,
+// or on all network interfaces if linkIndex is 0.
+func (Netlink) GetNeighs(linkIndex, family int) ([]*Neigh, error) {
+	s, err := getSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(unix.RTM_GETNEIGH, unix.NLM_F_DUMP)
+	req.addPayload(&neighMsg{Family: uint8(family)})
+
+	msgs, err := s.sendAndWaitForResponse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var neighs []*Neigh
+	for _, msg := range msgs {
+		neigh, err := deserializeNeigh(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter by link index.
+		if linkIndex != 0 && linkIndex != neigh.LinkIndex {
+			continue
+		}
+
+		neighs = append(neighs, neigh)
+	}
+
+	return neighs, nil
 }
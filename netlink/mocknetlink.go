@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
 )
 
 const BadEth = "badeth"
@@ -17,11 +18,25 @@ func newErrorMockNetlink(errStr string) error {
 
 type routeValidateFn func(route *Route) error
 
+type getRouteFn func(filter *Route) ([]*Route, error)
+
+type qdiscValidateFn func(ifName string, rate, burst uint64) error
+
+type getLinksFn func() ([]Link, error)
+
+// MockNetlink fakes the neighbor cache and the policy routing rules in memory,
+// the other calls only return the configured error.
 type MockNetlink struct {
 	returnError   bool
 	errorString   string
 	deleteRouteFn routeValidateFn
 	addRouteFn    routeValidateFn
+	getRouteFn    getRouteFn
+	addTbfFn      qdiscValidateFn
+	addPolicerFn  qdiscValidateFn
+	getLinksFn    getLinksFn
+	neighs        []*Neigh
+	rules         []*Rule
 }
 
 func NewMockNetlink(returnError bool, errorString string) *MockNetlink {
@@ -39,6 +54,22 @@ func (f *MockNetlink) SetAddRouteValidationFn(fn routeValidateFn) {
 	f.addRouteFn = fn
 }
 
+func (f *MockNetlink) SetGetRouteFn(fn getRouteFn) {
+	f.getRouteFn = fn
+}
+
+func (f *MockNetlink) SetAddTbfQdiscValidationFn(fn qdiscValidateFn) {
+	f.addTbfFn = fn
+}
+
+func (f *MockNetlink) SetAddIngressPolicerValidationFn(fn qdiscValidateFn) {
+	f.addPolicerFn = fn
+}
+
+func (f *MockNetlink) SetGetLinksFn(fn getLinksFn) {
+	f.getLinksFn = fn
+}
+
 func (f *MockNetlink) error() error {
 	if f.returnError {
 		return newErrorMockNetlink(f.errorString)
@@ -94,6 +125,52 @@ func (f *MockNetlink) SetOrRemoveLinkAddress(LinkInfo, int, int) error {
 	return f.error()
 }
 
+func (f *MockNetlink) GetLinks() ([]Link, error) {
+	if f.getLinksFn != nil {
+		return f.getLinksFn()
+	}
+	return nil, f.error()
+}
+
+func (f *MockNetlink) GetLinkByIndex(index int) (Link, error) {
+	links, err := f.GetLinks()
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.Info().Index == index {
+			return link, nil
+		}
+	}
+	return nil, newErrorMockNetlink(fmt.Sprintf("link %d not found", index))
+}
+
+func (f *MockNetlink) AddNeigh(neigh *Neigh) error {
+	if err := f.error(); err != nil {
+		return err
+	}
+	f.neighs = append(f.neighs, neigh)
+	return nil
+}
+
+func (f *MockNetlink) DeleteNeigh(neigh *Neigh) error {
+	if err := f.error(); err != nil {
+		return err
+	}
+	for i := range f.neighs {
+		if reflect.DeepEqual(f.neighs[i], neigh) {
+			f.neighs = append(f.neighs[:i], f.neighs[i+1:]...)
+			return nil
+		}
+	}
+	return newErrorMockNetlink("neighbor not found")
+}
+
+// GetNeighs returns all the neighbors added, regardless of the link and family.
+func (f *MockNetlink) GetNeighs(int, int) ([]*Neigh, error) {
+	return f.neighs, f.error()
+}
+
 func (f *MockNetlink) AddIPAddress(string, net.IP, *net.IPNet) error {
 	return f.error()
 }
@@ -102,7 +179,10 @@ func (f *MockNetlink) DeleteIPAddress(string, net.IP, *net.IPNet) error {
 	return f.error()
 }
 
-func (f *MockNetlink) GetIPRoute(*Route) ([]*Route, error) {
+func (f *MockNetlink) GetIPRoute(filter *Route) ([]*Route, error) {
+	if f.getRouteFn != nil {
+		return f.getRouteFn(filter)
+	}
 	return nil, f.error()
 }
 
@@ -119,3 +199,51 @@ func (f *MockNetlink) DeleteIPRoute(r *Route) error {
 	}
 	return f.error()
 }
+
+// GetIPRules returns all the rules added, regardless of the family.
+func (f *MockNetlink) GetIPRules(int) ([]*Rule, error) {
+	return f.rules, f.error()
+}
+
+func (f *MockNetlink) AddIPRule(rule *Rule) error {
+	if err := f.error(); err != nil {
+		return err
+	}
+	f.rules = append(f.rules, rule)
+	return nil
+}
+
+func (f *MockNetlink) DeleteIPRule(rule *Rule) error {
+	if err := f.error(); err != nil {
+		return err
+	}
+	for i := range f.rules {
+		if reflect.DeepEqual(f.rules[i], rule) {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return nil
+		}
+	}
+	return newErrorMockNetlink("rule not found")
+}
+
+func (f *MockNetlink) AddTbfQdisc(ifName string, rate, burst uint64) error {
+	if f.addTbfFn != nil {
+		return f.addTbfFn(ifName, rate, burst)
+	}
+	return f.error()
+}
+
+func (f *MockNetlink) AddIngressPolicer(ifName string, rate, burst uint64) error {
+	if f.addPolicerFn != nil {
+		return f.addPolicerFn(ifName, rate, burst)
+	}
+	return f.error()
+}
+
+func (f *MockNetlink) DeleteRootQdisc(string) error {
+	return f.error()
+}
+
+func (f *MockNetlink) DeleteIngressQdisc(string) error {
+	return f.error()
+}
@@ -7,8 +7,11 @@
 package netlink
 
 import (
+	"context"
 	"net"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/unix"
@@ -375,3 +378,277 @@ func TestAddDeleteRoute(t *testing.T) {
 		t.Errorf("DeleteLink failed: %+v", err)
 	}
 }
+
+// received converts a request to the message received for it, to test its deserialization.
+func received(t *testing.T, req *message) *message {
+	t.Helper()
+	nlMsgs, err := syscall.ParseNetlinkMessage(req.serialize())
+	require.NoError(t, err)
+	require.Len(t, nlMsgs, 1)
+
+	return newReceivedMessage(&nlMsgs[0])
+}
+
+func TestLinkDeserialize(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:b3:4d:5e:e2:4a")
+
+	req := newRequest(unix.RTM_NEWLINK, 0)
+	ifInfo := newIfInfoMsg()
+	ifInfo.Index = 5
+	ifInfo.Flags = unix.IFF_UP | unix.IFF_BROADCAST
+	req.addPayload(ifInfo)
+	req.addPayload(newAttributeStringZ(unix.IFLA_IFNAME, ifName))
+	req.addPayload(newAttributeUint32(unix.IFLA_MTU, 1500))
+	req.addPayload(newAttributeUint32(unix.IFLA_LINK, 2))
+	req.addPayload(newRtAttr(unix.IFLA_ADDRESS, mac))
+	attrLinkInfo := newAttribute(unix.IFLA_LINKINFO, nil)
+	attrLinkInfo.addNested(newAttributeString(IFLA_INFO_KIND, LINK_TYPE_IPVLAN))
+	attrData := newAttribute(IFLA_INFO_DATA, nil)
+	attrData.addNested(newAttributeUint16(IFLA_IPVLAN_MODE, uint16(IPVLAN_MODE_L3)))
+	attrLinkInfo.addNested(attrData)
+	req.addPayload(attrLinkInfo)
+
+	link, err := deserializeLink(received(t, req))
+	require.NoError(t, err)
+	require.Equal(t, &IPVlanLink{
+		LinkInfo: LinkInfo{
+			Type:        LINK_TYPE_IPVLAN,
+			Name:        ifName,
+			Index:       5,
+			Flags:       net.FlagUp | net.FlagBroadcast,
+			MTU:         1500,
+			ParentIndex: 2,
+			MacAddress:  mac,
+		},
+		Mode: IPVLAN_MODE_L3,
+	}, link)
+
+	_, err = deserializeLink(&message{data: []byte{0}})
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestNeighSerialize(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:b3:4d:5e:e2:4a")
+	neigh := &Neigh{
+		LinkIndex:    2,
+		Family:       unix.AF_INET,
+		State:        NUD_PERMANENT,
+		IP:           net.ParseIP("169.254.2.1").To4(),
+		HardwareAddr: mac,
+	}
+
+	got, err := deserializeNeigh(received(t, newNeighRequest(neigh, true)))
+	require.NoError(t, err)
+	require.Equal(t, neigh, got)
+}
+
+func TestRuleSerialize(t *testing.T) {
+	_, src, _ := net.ParseCIDR("10.0.0.0/24")
+	_, dst, _ := net.ParseCIDR("fd00::/64")
+	tests := []struct {
+		name string
+		rule *Rule
+	}{
+		{
+			name: "mark",
+			rule: &Rule{Family: unix.AF_INET, Priority: 100, Table: 2, Mark: 333, Mask: 0xff},
+		},
+		{
+			name: "source in a table above 255",
+			rule: &Rule{Family: unix.AF_INET, Table: 300, Src: src, IifName: "eth0", Invert: true},
+		},
+		{
+			name: "destination",
+			rule: &Rule{Family: unix.AF_INET6, Table: 2, Dst: dst, OifName: "eth1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deserializeRule(received(t, newIPRuleRequest(tt.rule, true)))
+			require.NoError(t, err)
+			require.Equal(t, tt.rule, got)
+		})
+	}
+}
+
+func TestGetLinks(t *testing.T) {
+	link := VEthLink{
+		LinkInfo: LinkInfo{
+			Type: LINK_TYPE_VETH,
+			Name: ifName,
+		},
+		PeerName: ifName2,
+	}
+	nl := NewNetlink()
+
+	require.NoError(t, nl.AddLink(&link))
+	//nolint:errcheck // not testing deletelink here
+	defer nl.DeleteLink(ifName)
+
+	iface, err := net.InterfaceByName(ifName)
+	require.NoError(t, err)
+
+	links, err := nl.GetLinks()
+	require.NoError(t, err)
+	found := false
+	for _, l := range links {
+		if l.Info().Name == ifName {
+			found = true
+			require.Equal(t, iface.Index, l.Info().Index)
+		}
+	}
+	require.True(t, found, "link %s not listed", ifName)
+
+	got, err := nl.GetLinkByIndex(iface.Index)
+	require.NoError(t, err)
+	veth, ok := got.(*VEthLink)
+	require.True(t, ok, "expected a veth link, got %T", got)
+	require.Equal(t, ifName, veth.Name)
+	require.Equal(t, iface.HardwareAddr, veth.MacAddress)
+	require.Equal(t, uint(iface.MTU), veth.MTU)
+
+	peer, err := net.InterfaceByName(ifName2)
+	require.NoError(t, err)
+	require.Equal(t, peer.Index, veth.ParentIndex)
+}
+
+func TestAddGetDeleteNeigh(t *testing.T) {
+	link := BridgeLink{
+		LinkInfo: LinkInfo{
+			Type: LINK_TYPE_BRIDGE,
+			Name: ifName,
+		},
+	}
+	nl := NewNetlink()
+
+	require.NoError(t, nl.AddLink(&link))
+	//nolint:errcheck // not testing deletelink here
+	defer nl.DeleteLink(ifName)
+
+	iface, err := net.InterfaceByName(ifName)
+	require.NoError(t, err)
+
+	mac, _ := net.ParseMAC("aa:b3:4d:5e:e2:4a")
+	neigh := &Neigh{
+		LinkIndex:    iface.Index,
+		State:        NUD_PERMANENT,
+		IP:           net.ParseIP("192.168.0.2"),
+		HardwareAddr: mac,
+	}
+	require.NoError(t, nl.AddNeigh(neigh))
+
+	neighs, err := nl.GetNeighs(iface.Index, unix.AF_INET)
+	require.NoError(t, err)
+	require.Len(t, neighs, 1)
+	require.True(t, neigh.IP.Equal(neighs[0].IP))
+	require.Equal(t, mac, neighs[0].HardwareAddr)
+	require.Equal(t, NUD_PERMANENT, neighs[0].State)
+
+	require.NoError(t, nl.DeleteNeigh(neigh))
+	neighs, err = nl.GetNeighs(iface.Index, unix.AF_INET)
+	require.NoError(t, err)
+	require.Empty(t, neighs)
+}
+
+func TestAddGetDeleteIPRule(t *testing.T) {
+	rule := &Rule{
+		Family:   unix.AF_INET,
+		Priority: 32000,
+		Mark:     0x1234,
+		Table:    2,
+	}
+	nl := NewNetlink()
+
+	find := func() *Rule {
+		rules, err := nl.GetIPRules(unix.AF_INET)
+		require.NoError(t, err)
+		for _, r := range rules {
+			if r.Priority == rule.Priority && r.Mark == rule.Mark {
+				return r
+			}
+		}
+		return nil
+	}
+
+	require.NoError(t, nl.AddIPRule(rule))
+	got := find()
+	require.NotNil(t, got, "rule not listed")
+	require.Equal(t, rule.Table, got.Table)
+
+	require.NoError(t, nl.DeleteIPRule(rule))
+	require.Nil(t, find())
+}
+
+func TestDecodeEvent(t *testing.T) {
+	req := newRequest(unix.RTM_DELADDR, 0)
+	ifAddr := newIfAddrMsg(unix.AF_INET)
+	ifAddr.Index = 5
+	ifAddr.Prefixlen = 24
+	req.addPayload(ifAddr)
+	req.addPayload(newAttributeIpAddress(unix.IFA_LOCAL, net.ParseIP("10.0.0.4")))
+	req.addPayload(newAttributeIpAddress(unix.IFA_ADDRESS, net.ParseIP("10.0.0.4")))
+
+	event, err := decodeEvent(received(t, req))
+	require.NoError(t, err)
+	require.Equal(t, EventDelAddress, event.Type)
+	require.Equal(t, 5, event.Address.LinkIndex)
+	require.Equal(t, "10.0.0.4/24", event.Address.IPNet.String())
+
+	_, dst, _ := net.ParseCIDR("10.0.0.4/32")
+	route := newRequest(unix.RTM_DELROUTE, 0)
+	route.addPayload(&rtMsg{RtMsg: unix.RtMsg{Family: unix.AF_INET, Dst_len: 32, Table: unix.RT_TABLE_MAIN}})
+	route.addPayload(newAttributeIpAddress(unix.RTA_DST, dst.IP))
+	route.addPayload(newAttributeUint32(unix.RTA_OIF, 5))
+
+	event, err = decodeEvent(received(t, route))
+	require.NoError(t, err)
+	require.Equal(t, EventDelRoute, event.Type)
+	require.Equal(t, dst, event.Route.Dst)
+	require.Equal(t, 5, event.Route.LinkIndex)
+
+	event, err = decodeEvent(received(t, newRequest(unix.RTM_NEWNEIGH, 0)))
+	require.NoError(t, err)
+	require.Nil(t, event)
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := Subscribe(ctx, GroupLink)
+	require.NoError(t, err)
+
+	link := BridgeLink{
+		LinkInfo: LinkInfo{
+			Type: LINK_TYPE_BRIDGE,
+			Name: ifName,
+		},
+	}
+	nl := NewNetlink()
+	require.NoError(t, nl.AddLink(&link))
+	require.NoError(t, nl.DeleteLink(ifName))
+
+	// wait for the link to be added and deleted, ignoring the other changes on the host.
+	next := func(eventType EventType) Event {
+		for {
+			select {
+			case e := <-events:
+				if e.Type == eventType && e.Link.Info().Name == ifName {
+					return e
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %s event", eventType)
+			}
+		}
+	}
+	added := next(EventNewLink)
+	require.Equal(t, LINK_TYPE_BRIDGE, added.Link.Info().Type)
+	deleted := next(EventDelLink)
+	require.Equal(t, added.Link.Info().Index, deleted.Link.Info().Index)
+
+	// the channel is closed once the subscription is done.
+	cancel()
+	for range events { //nolint:revive // drain
+	}
+}
@@ -12,10 +12,15 @@ type Link interface {
 
 type Route struct{}
 
+type Rule struct{}
+
+type Neigh struct{}
+
 // LinkInfo respresents the common properties of all network interfaces.
 type LinkInfo struct {
-	Type string
-	Name string
+	Type  string
+	Name  string
+	Index int
 }
 
 func (linkInfo *LinkInfo) Info() *LinkInfo {
@@ -66,6 +71,26 @@ func (Netlink) SetOrRemoveLinkAddress(linkInfo LinkInfo, mode, linkState int) er
 	return nil
 }
 
+func (Netlink) GetLinks() ([]Link, error) {
+	return nil, nil
+}
+
+func (Netlink) GetLinkByIndex(index int) (Link, error) {
+	return nil, nil
+}
+
+func (Netlink) AddNeigh(neigh *Neigh) error {
+	return nil
+}
+
+func (Netlink) DeleteNeigh(neigh *Neigh) error {
+	return nil
+}
+
+func (Netlink) GetNeighs(linkIndex, family int) ([]*Neigh, error) {
+	return nil, nil
+}
+
 func (Netlink) AddIPAddress(ifName string, ipAddress net.IP, ipNet *net.IPNet) error {
 	return nil
 }
@@ -85,3 +110,31 @@ func (Netlink) AddIPRoute(route *Route) error {
 func (Netlink) DeleteIPRoute(route *Route) error {
 	return nil
 }
+
+func (Netlink) GetIPRules(family int) ([]*Rule, error) {
+	return nil, nil
+}
+
+func (Netlink) AddIPRule(rule *Rule) error {
+	return nil
+}
+
+func (Netlink) DeleteIPRule(rule *Rule) error {
+	return nil
+}
+
+func (Netlink) AddTbfQdisc(ifName string, rate, burst uint64) error {
+	return nil
+}
+
+func (Netlink) AddIngressPolicer(ifName string, rate, burst uint64) error {
+	return nil
+}
+
+func (Netlink) DeleteRootQdisc(ifName string) error {
+	return nil
+}
+
+func (Netlink) DeleteIngressQdisc(ifName string) error {
+	return nil
+}
@@ -19,9 +19,21 @@ type NetlinkInterface interface {
 	SetLinkPromisc(ifName string, on bool) error
 	SetLinkHairpin(bridgeName string, on bool) error
 	SetOrRemoveLinkAddress(linkInfo LinkInfo, mode, linkState int) error
+	GetLinks() ([]Link, error)
+	GetLinkByIndex(index int) (Link, error)
+	AddNeigh(neigh *Neigh) error
+	DeleteNeigh(neigh *Neigh) error
+	GetNeighs(linkIndex, family int) ([]*Neigh, error)
 	AddIPAddress(ifName string, ipAddress net.IP, ipNet *net.IPNet) error
 	DeleteIPAddress(ifName string, ipAddress net.IP, ipNet *net.IPNet) error
 	GetIPRoute(filter *Route) ([]*Route, error)
 	AddIPRoute(route *Route) error
 	DeleteIPRoute(route *Route) error
+	GetIPRules(family int) ([]*Rule, error)
+	AddIPRule(rule *Rule) error
+	DeleteIPRule(rule *Rule) error
+	AddTbfQdisc(ifName string, rate, burst uint64) error
+	AddIngressPolicer(ifName string, rate, burst uint64) error
+	DeleteRootQdisc(ifName string) error
+	DeleteIngressQdisc(ifName string) error
 }
@@ -10,9 +10,12 @@ import (
 	"net"
 	"unsafe"
 
+	"github.com/pkg/errors"
 	"golang.org/x/sys/unix"
 )
 
+var errInvalidMessage = errors.New("invalid netlink message")
+
 const (
 	NDA_UNSPEC = iota
 	NDA_DST
@@ -344,6 +347,19 @@ func (ifAddr *ifAddrMsg) length() int {
 	return unix.SizeofIfAddrmsg
 }
 
+// Deserializes an interface address message.
+func deserializeIfAddrMsg(b []byte) *ifAddrMsg {
+	return &ifAddrMsg{
+		IfAddrmsg: unix.IfAddrmsg{
+			Family:    b[0],
+			Prefixlen: b[1],
+			Flags:     b[2],
+			Scope:     b[3],
+			Index:     encoder.Uint32(b[4:8]),
+		},
+	}
+}
+
 //
 // Network route service module
 //
@@ -456,3 +472,115 @@ func (rta *rtAttr) length() int {
 func (rta *rtAttr) addChild(attr serializable) {
 	rta.children = append(rta.children, attr)
 }
+
+// Deserializes an interface info message.
+func deserializeIfInfoMsg(b []byte) *ifInfoMsg {
+	return &ifInfoMsg{
+		IfInfomsg: unix.IfInfomsg{
+			Family: b[0],
+			Type:   encoder.Uint16(b[2:4]),
+			Index:  int32(encoder.Uint32(b[4:8])),
+			Flags:  encoder.Uint32(b[8:12]),
+			Change: encoder.Uint32(b[12:16]),
+		},
+	}
+}
+
+// deserialize neighbor message
+func deserializeNeighMsg(b []byte) *neighMsg {
+	return &neighMsg{
+		Family: b[0],
+		Index:  encoder.Uint32(b[4:8]),
+		State:  encoder.Uint16(b[8:10]),
+		Flags:  b[10],
+		Type:   b[11],
+	}
+}
+
+//
+// Policy routing rule service module
+//
+
+// Rule message, the fib_rule_hdr of the kernel
+type ruleMsg struct {
+	Family uint8
+	DstLen uint8
+	SrcLen uint8
+	Tos    uint8
+	Table  uint8
+	Action uint8
+	Flags  uint32
+}
+
+// Creates a new rule message.
+func newRuleMsg(family int) *ruleMsg {
+	return &ruleMsg{
+		Family: uint8(family),
+		Action: unix.FR_ACT_TO_TBL,
+	}
+}
+
+// Deserializes a rule message.
+func deserializeRuleMsg(b []byte) *ruleMsg {
+	return &ruleMsg{
+		Family: b[0],
+		DstLen: b[1],
+		SrcLen: b[2],
+		Tos:    b[3],
+		Table:  b[4],
+		Action: b[7],
+		Flags:  encoder.Uint32(b[8:12]),
+	}
+}
+
+// Serializes a rule message.
+func (rule *ruleMsg) serialize() []byte {
+	b := make([]byte, rule.length())
+	b[0] = rule.Family
+	b[1] = rule.DstLen
+	b[2] = rule.SrcLen
+	b[3] = rule.Tos
+	b[4] = rule.Table
+	b[5] = 0 // Reserved.
+	b[6] = 0 // Reserved.
+	b[7] = rule.Action
+	encoder.PutUint32(b[8:12], rule.Flags)
+	return b
+}
+
+// Returns the length of a rule message.
+func (rule *ruleMsg) length() int {
+	return sizeofRuleMsg
+}
+
+// Size of the fib_rule_hdr, which has the same layout as a route message.
+const sizeofRuleMsg = unix.SizeofRtMsg
+
+// Parses the attributes in a buffer, used for nested attributes and for the messages
+// whose attributes are not parsed by syscall.ParseNetlinkRouteAttr.
+func parseAttributes(b []byte) []*attribute {
+	var attrs []*attribute
+
+	for len(b) >= unix.SizeofNlAttr {
+		length := int(encoder.Uint16(b[0:2]))
+		if length < unix.SizeofNlAttr || length > len(b) {
+			break
+		}
+
+		attrs = append(attrs, &attribute{
+			NlAttr: unix.NlAttr{
+				Len:  uint16(length),
+				Type: encoder.Uint16(b[2:4]) &^ (unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER),
+			},
+			value: b[unix.SizeofNlAttr:length],
+		})
+
+		next := (length + unix.NLA_ALIGNTO - 1) & ^(unix.NLA_ALIGNTO - 1)
+		if next > len(b) {
+			break
+		}
+		b = b[next:]
+	}
+
+	return attrs
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/Azure/azure-container-networking/log"
 	"golang.org/x/sys/unix"
@@ -25,6 +26,9 @@ type socket struct {
 	sync.Mutex
 }
 
+// How long receiving on a multicast socket blocks before timing out.
+const multicastReceiveTimeout = time.Second
+
 // Default netlink socket.
 var (
 	s *socket
@@ -55,6 +59,28 @@ func ResetSocket() {
 
 // Creates a new netlink socket object.
 func newSocket() (*socket, error) {
+	return newGroupSocket(0)
+}
+
+// Creates a new netlink socket object which receives the messages of the given multicast groups.
+// Receiving on it times out periodically, so that its readers can tell when to stop.
+func newMulticastSocket(groups uint32) (*socket, error) {
+	s, err := newGroupSocket(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := unix.NsecToTimeval(multicastReceiveTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(s.fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		s.close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Creates a new netlink socket object bound to the given multicast groups.
+func newGroupSocket(groups uint32) (*socket, error) {
 	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW, unix.NETLINK_ROUTE)
 	if err != nil {
 		log.Debugf("[netlink] Failed to create socket, err=%v\n", err)
@@ -68,6 +94,7 @@ func newSocket() (*socket, error) {
 	}
 
 	s.sa.Family = unix.AF_NETLINK
+	s.sa.Groups = groups
 
 	err = unix.Bind(fd, &s.sa)
 	if err != nil {
@@ -145,20 +172,11 @@ func (s *socket) receiveResponse(sent *message) ([]*message, error) {
 		// Process received messages.
 		for _, nlMsg := range nlMsgs {
 			// Convert to message object.
-			msg := message{
-				NlMsghdr: unix.NlMsghdr{
-					Len:   nlMsg.Header.Len,
-					Type:  nlMsg.Header.Type,
-					Flags: nlMsg.Header.Flags,
-					Seq:   nlMsg.Header.Seq,
-					Pid:   nlMsg.Header.Pid,
-				},
-				data: nlMsg.Data,
-			}
+			msg := newReceivedMessage(&nlMsg)
 
 			// Ignore if the message is not in response to the sent message.
 			if msg.Seq != sent.Seq || msg.Pid != sent.Pid {
-				log.Printf("[netlink] Ignoring unexpected message %+v\n", msg)
+				log.Printf("[netlink] Ignoring unexpected message %+v\n", *msg)
 				continue
 			}
 
@@ -168,35 +186,16 @@ func (s *socket) receiveResponse(sent *message) ([]*message, error) {
 			if msg.Type == unix.NLMSG_ERROR {
 				errCode := int32(encoder.Uint32(msg.data[0:4]))
 				if errCode == 0 {
-					log.Debugf("[netlink] Received %+v, ack\n", msg)
+					log.Debugf("[netlink] Received %+v, ack\n", *msg)
 				} else {
 					err = syscall.Errno(-errCode)
-					log.Printf("[netlink] Received %+v, err=%v\n", msg, err)
+					log.Printf("[netlink] Received %+v, err=%v\n", *msg, err)
 				}
 				return nil, err
 			}
 
 			// Log response message.
-			log.Debugf("[netlink] Received %+v\n", msg)
-
-			// Parse body.
-			msg.payload = append(msg.payload, nil)
-
-			// Parse attributes.
-			// Ignore failures as not all messages have attributes.
-			nlAttrs, _ := syscall.ParseNetlinkRouteAttr(&nlMsg)
-
-			// Convert to attribute objects.
-			for _, nlAttr := range nlAttrs {
-				attr := attribute{
-					NlAttr: unix.NlAttr{
-						Len:  nlAttr.Attr.Len,
-						Type: nlAttr.Attr.Type,
-					},
-					value: nlAttr.Value,
-				}
-				msg.payload = append(msg.payload, &attr)
-			}
+			log.Debugf("[netlink] Received %+v\n", *msg)
 
 			multi = ((msg.Flags & unix.NLM_F_MULTI) != 0)
 			done = (msg.Type == unix.NLMSG_DONE)
@@ -206,7 +205,7 @@ func (s *socket) receiveResponse(sent *message) ([]*message, error) {
 				break
 			}
 
-			messages = append(messages, &msg)
+			messages = append(messages, msg)
 		}
 
 		// Exit if response is a single message,
@@ -218,3 +217,61 @@ func (s *socket) receiveResponse(sent *message) ([]*message, error) {
 
 	return messages, nil
 }
+
+// Converts a received netlink message to a message object with its attributes parsed.
+func newReceivedMessage(nlMsg *syscall.NetlinkMessage) *message {
+	msg := &message{
+		NlMsghdr: unix.NlMsghdr{
+			Len:   nlMsg.Header.Len,
+			Type:  nlMsg.Header.Type,
+			Flags: nlMsg.Header.Flags,
+			Seq:   nlMsg.Header.Seq,
+			Pid:   nlMsg.Header.Pid,
+		},
+		data: nlMsg.Data,
+	}
+
+	// Parse body.
+	msg.payload = append(msg.payload, nil)
+
+	// Parse attributes.
+	for _, attr := range parseMessageAttributes(nlMsg) {
+		msg.payload = append(msg.payload, attr)
+	}
+
+	return msg
+}
+
+// Parses the attributes of a received message.
+func parseMessageAttributes(nlMsg *syscall.NetlinkMessage) []*attribute {
+	var hdrLen int
+
+	// syscall only parses the attributes of link, address and route messages.
+	switch nlMsg.Header.Type {
+	case unix.RTM_NEWNEIGH, unix.RTM_DELNEIGH:
+		hdrLen = unix.SizeofNdMsg
+	case unix.RTM_NEWRULE, unix.RTM_DELRULE:
+		hdrLen = sizeofRuleMsg
+	default:
+		// Ignore failures as not all messages have attributes.
+		nlAttrs, _ := syscall.ParseNetlinkRouteAttr(nlMsg)
+
+		// Convert to attribute objects.
+		attrs := make([]*attribute, 0, len(nlAttrs))
+		for _, nlAttr := range nlAttrs {
+			attrs = append(attrs, &attribute{
+				NlAttr: unix.NlAttr{
+					Len:  nlAttr.Attr.Len,
+					Type: nlAttr.Attr.Type,
+				},
+				value: nlAttr.Value,
+			})
+		}
+		return attrs
+	}
+
+	if len(nlMsg.Data) < hdrLen {
+		return nil
+	}
+	return parseAttributes(nlMsg.Data[hdrLen:])
+}
@@ -0,0 +1,183 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+//go:build linux
+// +build linux
+
+package netlink
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/Azure/azure-container-networking/log"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// Group is a route netlink multicast group.
+type Group uint
+
+// Multicast groups whose messages are decoded into events.
+const (
+	GroupLink        Group = unix.RTNLGRP_LINK
+	GroupIPv4Address Group = unix.RTNLGRP_IPV4_IFADDR
+	GroupIPv6Address Group = unix.RTNLGRP_IPV6_IFADDR
+	GroupIPv4Route   Group = unix.RTNLGRP_IPV4_ROUTE
+	GroupIPv6Route   Group = unix.RTNLGRP_IPV6_ROUTE
+)
+
+// EventType is the type of change notified by an Event.
+type EventType int
+
+const (
+	// EventNewLink notifies a network interface which is added or changed.
+	EventNewLink EventType = iota + 1
+	// EventDelLink notifies a network interface which is deleted.
+	EventDelLink
+	// EventNewAddress notifies an IP address which is added to a network interface.
+	EventNewAddress
+	// EventDelAddress notifies an IP address which is deleted from a network interface.
+	EventDelAddress
+	// EventNewRoute notifies an IP route which is added or changed.
+	EventNewRoute
+	// EventDelRoute notifies an IP route which is deleted.
+	EventDelRoute
+	// EventOverrun notifies that the kernel dropped events because they were not received fast enough,
+	// subscribers have to resync the state they track.
+	EventOverrun
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventNewLink:
+		return "NewLink"
+	case EventDelLink:
+		return "DelLink"
+	case EventNewAddress:
+		return "NewAddress"
+	case EventDelAddress:
+		return "DelAddress"
+	case EventNewRoute:
+		return "NewRoute"
+	case EventDelRoute:
+		return "DelRoute"
+	case EventOverrun:
+		return "Overrun"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a change notified by the kernel, only the field of the type of the change is set.
+type Event struct {
+	Type    EventType
+	Link    Link
+	Address *Address
+	Route   *Route
+}
+
+// Size of the buffer of the events channel.
+const eventBufferSize = 64
+
+// Subscribe subscribes to the given multicast groups and returns the changes they notify.
+// The channel is closed once ctx is done, or if the subscription fails.
+func Subscribe(ctx context.Context, groups ...Group) (<-chan Event, error) {
+	var mask uint32
+	for _, group := range groups {
+		mask |= 1 << (group - 1)
+	}
+
+	s, err := newMulticastSocket(mask)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create multicast socket")
+	}
+
+	events := make(chan Event, eventBufferSize)
+	go func() {
+		defer close(events)
+		defer s.close()
+		for ctx.Err() == nil {
+			nlMsgs, err := s.receive()
+			if err != nil {
+				switch {
+				case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+					// Receive timed out, check if the subscription is done.
+					continue
+				case errors.Is(err, unix.ENOBUFS):
+					log.Printf("[netlink] Subscription overrun, events were dropped\n")
+					if !sendEvent(ctx, events, Event{Type: EventOverrun}) {
+						return
+					}
+					continue
+				case errors.Is(err, syscall.EINVAL):
+					log.Printf("[netlink] Ignoring invalid multicast message, err=%v\n", err)
+					continue
+				default:
+					log.Printf("[netlink] Subscription receive err=%v\n", err)
+					return
+				}
+			}
+
+			for i := range nlMsgs {
+				event, err := decodeEvent(newReceivedMessage(&nlMsgs[i]))
+				if err != nil {
+					log.Printf("[netlink] Ignoring multicast message, err=%v\n", err)
+					continue
+				}
+				if event == nil {
+					continue
+				}
+				if !sendEvent(ctx, events, *event) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// sendEvent sends an event unless ctx is done first, and returns whether it was sent.
+func sendEvent(ctx context.Context, events chan<- Event, event Event) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// decodeEvent decodes a multicast message into an event, or nil for the messages which are not decoded.
+func decodeEvent(msg *message) (*Event, error) {
+	var err error
+	var event Event
+
+	switch msg.Type {
+	case unix.RTM_NEWLINK, unix.RTM_DELLINK:
+		event.Type = EventNewLink
+		if msg.Type == unix.RTM_DELLINK {
+			event.Type = EventDelLink
+		}
+		event.Link, err = deserializeLink(msg)
+	case unix.RTM_NEWADDR, unix.RTM_DELADDR:
+		event.Type = EventNewAddress
+		if msg.Type == unix.RTM_DELADDR {
+			event.Type = EventDelAddress
+		}
+		event.Address, err = deserializeAddress(msg)
+	case unix.RTM_NEWROUTE, unix.RTM_DELROUTE:
+		event.Type = EventNewRoute
+		if msg.Type == unix.RTM_DELROUTE {
+			event.Type = EventDelRoute
+		}
+		event.Route, err = deserializeRoute(msg)
+	default:
+		return nil, nil //nolint:nilnil // not all multicast messages are events
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
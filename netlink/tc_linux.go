@@ -0,0 +1,316 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+//go:build linux
+// +build linux
+
+package netlink
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// Traffic control constants that are not already defined in unix package.
+const (
+	TCA_KIND    = 1
+	TCA_OPTIONS = 2
+
+	TCA_TBF_PARMS  = 1
+	TCA_TBF_RATE64 = 4
+	TCA_TBF_BURST  = 6
+
+	TCA_U32_SEL    = 5
+	TCA_U32_POLICE = 6
+
+	TCA_POLICE_TBF  = 1
+	TCA_POLICE_RATE = 2
+
+	TC_H_ROOT        = 0xFFFFFFFF
+	TC_H_INGRESS     = 0xFFFFFFF1
+	TC_H_INGRESS_MAJ = 0xFFFF0000
+
+	TC_LINKLAYER_ETHERNET = 1
+	TC_U32_TERMINAL       = 1
+	TC_POLICE_SHOT        = 2
+)
+
+const (
+	// tbfHandle is the handle of the root tbf qdisc, 1:0.
+	tbfHandle = 0x10000
+	// tbfLatencyInUsec bounds the time a packet may wait in the tbf queue.
+	tbfLatencyInUsec = 25000
+	// policerFilterPriority is the priority of the match-all ingress policing filter.
+	policerFilterPriority = 1
+	// rtabMTU is the default packet size covered by a rate table.
+	rtabMTU = 2047
+	// rtabSize is the number of slots of a rate table.
+	rtabSize             = 256
+	timeUnitsPerSec      = 1000000
+	sizeofTcMsg          = 20
+	sizeofTcRateSpec     = 12
+	sizeofTcTbfQopt      = 2*sizeofTcRateSpec + 12
+	sizeofTcPolice       = 2*sizeofTcRateSpec + 32
+	sizeofTcU32SelOneKey = 16 + 16
+)
+
+var errInvalidBandwidth = errors.New("rate and burst must be greater than zero")
+
+// Traffic control message
+type tcMsg struct {
+	Family  uint8
+	Ifindex int32
+	Handle  uint32
+	Parent  uint32
+	Info    uint32
+}
+
+// Creates a new traffic control message.
+func newTcMsg(ifIndex int, handle, parent, info uint32) *tcMsg {
+	return &tcMsg{
+		Family:  unix.AF_UNSPEC,
+		Ifindex: int32(ifIndex),
+		Handle:  handle,
+		Parent:  parent,
+		Info:    info,
+	}
+}
+
+// Serializes a traffic control message.
+func (tc *tcMsg) serialize() []byte {
+	b := make([]byte, tc.length())
+	b[0] = tc.Family
+	encoder.PutUint32(b[4:8], uint32(tc.Ifindex))
+	encoder.PutUint32(b[8:12], tc.Handle)
+	encoder.PutUint32(b[12:16], tc.Parent)
+	encoder.PutUint32(b[16:20], tc.Info)
+	return b
+}
+
+// Returns the length of a traffic control message.
+func (tc *tcMsg) length() int {
+	return sizeofTcMsg
+}
+
+// Rate specification shared by the tbf qdisc and the policer.
+type tcRateSpec struct {
+	CellLog   uint8
+	Linklayer uint8
+	Overhead  uint16
+	CellAlign int16
+	Mpu       uint16
+	Rate      uint32
+}
+
+func (r *tcRateSpec) serializeTo(b []byte) {
+	b[0] = r.CellLog
+	b[1] = r.Linklayer
+	encoder.PutUint16(b[2:4], r.Overhead)
+	encoder.PutUint16(b[4:6], uint16(r.CellAlign))
+	encoder.PutUint16(b[6:8], r.Mpu)
+	encoder.PutUint32(b[8:12], r.Rate)
+}
+
+// Packet scheduler clock, read from /proc/net/psched.
+var (
+	tickInUsec float64 = 1
+	clockOnce  sync.Once
+)
+
+func initClock() {
+	data, err := os.ReadFile("/proc/net/psched")
+	if err != nil {
+		return
+	}
+
+	parts := strings.Fields(string(data))
+	if len(parts) < 3 {
+		return
+	}
+
+	var vals [3]uint64
+	for i := range vals {
+		if vals[i], err = strconv.ParseUint(parts[i], 16, 32); err != nil {
+			return
+		}
+	}
+
+	// Kernels with a high resolution clock report the nanosecond to tick factor as 1:1.
+	if vals[2] == 1000000000 {
+		vals[0] = vals[1]
+	}
+
+	clockFactor := float64(vals[2]) / timeUnitsPerSec
+	tickInUsec = float64(vals[0]) / float64(vals[1]) * clockFactor
+}
+
+// xmitTime returns the time in scheduler ticks needed to send size bytes at rate bytes per second.
+func xmitTime(rate uint64, size uint32) uint32 {
+	clockOnce.Do(initClock)
+	return uint32(timeUnitsPerSec * (float64(size) / float64(rate)) * tickInUsec)
+}
+
+// rateTable computes the rate table the kernel uses to look up the transmit time of a packet by its size.
+func rateTable(rate *tcRateSpec, rtabRate uint64) []byte {
+	cellLog := uint8(0)
+	for (rtabMTU >> cellLog) > rtabSize-1 {
+		cellLog++
+	}
+
+	b := make([]byte, rtabSize*4)
+	for i := 0; i < rtabSize; i++ {
+		size := uint32((i + 1) << cellLog)
+		encoder.PutUint32(b[i*4:], xmitTime(rtabRate, size))
+	}
+
+	rate.CellAlign = -1
+	rate.CellLog = cellLog
+	rate.Linklayer = TC_LINKLAYER_ETHERNET
+	return b
+}
+
+// AddTbfQdisc sets a token bucket filter as the root qdisc of a network interface, shaping the traffic
+// it transmits to rate bytes per second with bursts of up to burst bytes.
+func (Netlink) AddTbfQdisc(ifName string, rate, burst uint64) error {
+	if rate == 0 || burst == 0 {
+		return errInvalidBandwidth
+	}
+
+	s, err := getSocket()
+	if err != nil {
+		return err
+	}
+
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	req := newRequest(unix.RTM_NEWQDISC, unix.NLM_F_CREATE|unix.NLM_F_REPLACE|unix.NLM_F_ACK)
+	req.addPayload(newTcMsg(iface.Index, tbfHandle, TC_H_ROOT, 0))
+	req.addPayload(newAttributeStringZ(TCA_KIND, "tbf"))
+
+	buffer := xmitTime(rate, uint32(burst))
+	limit := uint32(float64(rate)*tbfLatencyInUsec/timeUnitsPerSec) + uint32(burst)
+
+	qopt := make([]byte, sizeofTcTbfQopt)
+	rateSpec := tcRateSpec{Linklayer: TC_LINKLAYER_ETHERNET, Rate: uint32(min(rate, uint64(^uint32(0))))}
+	rateSpec.serializeTo(qopt[0:sizeofTcRateSpec])
+	encoder.PutUint32(qopt[2*sizeofTcRateSpec:], limit)
+	encoder.PutUint32(qopt[2*sizeofTcRateSpec+4:], buffer)
+
+	options := newAttribute(TCA_OPTIONS, nil)
+	options.addNested(newAttribute(TCA_TBF_PARMS, qopt))
+	if rate > uint64(^uint32(0)) {
+		rate64 := make([]byte, 8)
+		encoder.PutUint64(rate64, rate)
+		options.addNested(newAttribute(TCA_TBF_RATE64, rate64))
+	}
+	options.addNested(newAttributeUint32(TCA_TBF_BURST, uint32(burst)))
+	req.addPayload(options)
+
+	return s.sendAndWaitForAck(req)
+}
+
+// AddIngressPolicer polices the traffic a network interface receives to rate bytes per second with bursts
+// of up to burst bytes, dropping the excess. It replaces any ingress qdisc set on the interface.
+func (nl Netlink) AddIngressPolicer(ifName string, rate, burst uint64) error {
+	if rate == 0 || burst == 0 {
+		return errInvalidBandwidth
+	}
+
+	s, err := getSocket()
+	if err != nil {
+		return err
+	}
+
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	// Ignore the error as the interface may not have an ingress qdisc yet.
+	_ = nl.DeleteIngressQdisc(ifName)
+
+	req := newRequest(unix.RTM_NEWQDISC, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	req.addPayload(newTcMsg(iface.Index, TC_H_INGRESS_MAJ, TC_H_INGRESS, 0))
+	req.addPayload(newAttributeStringZ(TCA_KIND, "ingress"))
+	req.addPayload(newAttribute(TCA_OPTIONS, nil))
+	if err = s.sendAndWaitForAck(req); err != nil {
+		return err
+	}
+
+	// Match every packet and hand it to the policer.
+	info := uint32(policerFilterPriority<<16) | uint32(htons(unix.ETH_P_ALL))
+	req = newRequest(unix.RTM_NEWTFILTER, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	req.addPayload(newTcMsg(iface.Index, 0, TC_H_INGRESS_MAJ, info))
+	req.addPayload(newAttributeStringZ(TCA_KIND, "u32"))
+
+	sel := make([]byte, sizeofTcU32SelOneKey)
+	sel[0] = TC_U32_TERMINAL
+	sel[2] = 1 // a single key with a zero mask, which matches everything
+
+	police := make([]byte, sizeofTcPolice)
+	rateSpec := tcRateSpec{Rate: uint32(min(rate, uint64(^uint32(0))))}
+	rtab := rateTable(&rateSpec, rate)
+	encoder.PutUint32(police[4:8], TC_POLICE_SHOT)
+	encoder.PutUint32(police[12:16], xmitTime(rate, uint32(burst)))
+	rateSpec.serializeTo(police[20 : 20+sizeofTcRateSpec])
+
+	policeAttr := newAttribute(TCA_U32_POLICE, nil)
+	policeAttr.addNested(newAttribute(TCA_POLICE_TBF, police))
+	policeAttr.addNested(newAttribute(TCA_POLICE_RATE, rtab))
+
+	options := newAttribute(TCA_OPTIONS, nil)
+	options.addNested(newAttribute(TCA_U32_SEL, sel))
+	options.addNested(policeAttr)
+	req.addPayload(options)
+
+	if err = s.sendAndWaitForAck(req); err != nil {
+		// Do not leave an ingress qdisc without its policer behind.
+		_ = nl.DeleteIngressQdisc(ifName)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRootQdisc deletes the root qdisc of a network interface, restoring the default qdisc.
+func (Netlink) DeleteRootQdisc(ifName string) error {
+	return deleteQdisc(ifName, 0, TC_H_ROOT)
+}
+
+// DeleteIngressQdisc deletes the ingress qdisc of a network interface along with its filters.
+func (Netlink) DeleteIngressQdisc(ifName string) error {
+	return deleteQdisc(ifName, TC_H_INGRESS_MAJ, TC_H_INGRESS)
+}
+
+func deleteQdisc(ifName string, handle, parent uint32) error {
+	s, err := getSocket()
+	if err != nil {
+		return err
+	}
+
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	req := newRequest(unix.RTM_DELQDISC, unix.NLM_F_ACK)
+	req.addPayload(newTcMsg(iface.Index, handle, parent, 0))
+
+	return s.sendAndWaitForAck(req)
+}
+
+// htons converts a short from host to network byte order.
+func htons(v uint16) uint16 {
+	b := make([]byte, 2)
+	encoder.PutUint16(b, v)
+	return uint16(b[0])<<8 | uint16(b[1])
+}
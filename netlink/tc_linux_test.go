@@ -0,0 +1,75 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+//go:build linux
+// +build linux
+
+package netlink
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestTcMsgSerialize(t *testing.T) {
+	msg := newTcMsg(7, tbfHandle, TC_H_ROOT, 0)
+	b := msg.serialize()
+
+	require.Len(t, b, sizeofTcMsg)
+	require.Equal(t, uint32(7), encoder.Uint32(b[4:8]))
+	require.Equal(t, uint32(tbfHandle), encoder.Uint32(b[8:12]))
+	require.Equal(t, uint32(TC_H_ROOT), encoder.Uint32(b[12:16]))
+}
+
+func TestRateTable(t *testing.T) {
+	rate := tcRateSpec{Rate: 125000}
+	rtab := rateTable(&rate, 125000)
+
+	require.Len(t, rtab, rtabSize*4)
+	require.Equal(t, uint8(3), rate.CellLog)
+	require.Equal(t, uint8(TC_LINKLAYER_ETHERNET), rate.Linklayer)
+
+	// transmit times grow with the packet size
+	for i := 4; i < len(rtab); i += 4 {
+		require.Greater(t, encoder.Uint32(rtab[i:i+4]), encoder.Uint32(rtab[i-4:i]))
+	}
+	require.Equal(t, xmitTime(125000, rtabSize<<3), encoder.Uint32(rtab[len(rtab)-4:]))
+}
+
+// TestAddDeleteQdiscs tests shaping and policing the traffic of a veth.
+func TestAddDeleteQdiscs(t *testing.T) {
+	link := VEthLink{
+		LinkInfo: LinkInfo{
+			Type: LINK_TYPE_VETH,
+			Name: ifName,
+		},
+		PeerName: ifName2,
+	}
+	nl := NewNetlink()
+
+	err := nl.AddLink(&link)
+	require.NoError(t, err)
+
+	//nolint:errcheck // not testing deletelink here
+	defer nl.DeleteLink(ifName)
+
+	require.ErrorIs(t, nl.AddTbfQdisc(ifName, 0, 1000), errInvalidBandwidth)
+	require.NoError(t, nl.AddTbfQdisc(ifName, 125000, 10000))
+	// replacing the qdisc, e.g. on restore, succeeds
+	require.NoError(t, nl.AddTbfQdisc(ifName, 250000, 10000))
+	require.NoError(t, nl.DeleteRootQdisc(ifName))
+
+	err = nl.AddIngressPolicer(ifName, 125000, 10000)
+	// the ingress qdisc is removed when the policer cannot be added
+	require.Error(t, nl.DeleteIngressQdisc(ifName))
+	if errors.Is(err, unix.ENOENT) {
+		t.Skip("kernel does not support u32 policing")
+	}
+	require.NoError(t, err)
+	require.NoError(t, nl.AddIngressPolicer(ifName, 250000, 10000))
+	require.NoError(t, nl.DeleteIngressQdisc(ifName))
+	require.Error(t, nl.DeleteIngressQdisc(ifName))
+}
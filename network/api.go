@@ -6,6 +6,7 @@ package network
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -31,3 +32,19 @@ func (n *networkNotFoundError) Error() string {
 func IsNetworkNotFoundError(err error) bool {
 	return errors.Is(err, errNetworkNotFound)
 }
+
+// EndpointDriftError is returned by CheckEndpoint when the live dataplane no longer
+// matches the persisted endpoint. Drifts holds one entry per mismatch found.
+type EndpointDriftError struct {
+	EndpointID string
+	Drifts     []string
+}
+
+func (e *EndpointDriftError) Error() string {
+	return fmt.Sprintf("endpoint %s has drifted: %s", e.EndpointID, strings.Join(e.Drifts, "; "))
+}
+
+func IsEndpointDriftError(err error) bool {
+	var driftErr *EndpointDriftError
+	return errors.As(err, &driftErr)
+}
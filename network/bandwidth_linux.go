@@ -0,0 +1,83 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-container-networking/netlink"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// bitsPerByte converts the rates and bursts of the runtime config, which are in bits, to the bytes tc expects.
+const bitsPerByte = 8
+
+var errInvalidBandwidthLimits = errors.New("invalid bandwidth limits")
+
+// validateBandwidthLimits checks that every limited direction has a burst and that rates are at least a byte per second.
+func validateBandwidthLimits(limits *BandwidthLimits) error {
+	if limits.IngressRate > 0 && (limits.IngressRate < bitsPerByte || limits.IngressBurst < bitsPerByte) {
+		return errors.Wrapf(errInvalidBandwidthLimits, "ingress rate %d and burst %d", limits.IngressRate, limits.IngressBurst)
+	}
+	if limits.EgressRate > 0 && (limits.EgressRate < bitsPerByte || limits.EgressBurst < bitsPerByte) {
+		return errors.Wrapf(errInvalidBandwidthLimits, "egress rate %d and burst %d", limits.EgressRate, limits.EgressBurst)
+	}
+	return nil
+}
+
+// setBandwidthLimits shapes the traffic of an endpoint on its host interface. Traffic received by the endpoint is
+// transmitted by the host interface and goes through a token bucket filter, while traffic sent by the endpoint is
+// received by the host interface and policed.
+func setBandwidthLimits(nl netlink.NetlinkInterface, hostIfName string, limits *BandwidthLimits) error {
+	if limits == nil {
+		return nil
+	}
+
+	if err := validateBandwidthLimits(limits); err != nil {
+		return err
+	}
+
+	if limits.IngressRate > 0 {
+		logger.Info("Adding tbf qdisc", zap.String("hostIfName", hostIfName),
+			zap.Uint64("rate", limits.IngressRate), zap.Uint64("burst", limits.IngressBurst))
+		if err := nl.AddTbfQdisc(hostIfName, limits.IngressRate/bitsPerByte, limits.IngressBurst/bitsPerByte); err != nil {
+			return errors.Wrapf(err, "failed to add tbf qdisc on %s", hostIfName)
+		}
+	}
+
+	if limits.EgressRate > 0 {
+		logger.Info("Adding ingress policer", zap.String("hostIfName", hostIfName),
+			zap.Uint64("rate", limits.EgressRate), zap.Uint64("burst", limits.EgressBurst))
+		if err := nl.AddIngressPolicer(hostIfName, limits.EgressRate/bitsPerByte, limits.EgressBurst/bitsPerByte); err != nil {
+			return errors.Wrapf(err, "failed to add ingress policer on %s", hostIfName)
+		}
+	}
+
+	return nil
+}
+
+// restoreEndpointBandwidth reapplies the bandwidth limits recorded for the endpoints of the network whose host
+// interface still exists. It is called on restore after a reboot, when the qdiscs are gone.
+func (nm *networkManager) restoreEndpointBandwidth(nw *network) {
+	for _, ep := range nw.Endpoints {
+		if ep.Bandwidth == nil || ep.HostIfName == "" {
+			continue
+		}
+
+		apply := func() error {
+			if _, err := nm.netio.GetNetworkInterfaceByName(ep.HostIfName); err != nil {
+				return nil //nolint:nilerr // nothing to shape, the endpoint did not survive the reboot
+			}
+			return setBandwidthLimits(nm.netlink, ep.HostIfName, ep.Bandwidth)
+		}
+
+		var err error
+		if ep.VlanID != 0 && nw.Mode == opModeTransparentVlan {
+			err = ExecuteInNS(nm.nsClient, fmt.Sprintf("az_ns_%d", ep.VlanID), apply)
+		} else {
+			err = apply()
+		}
+		if err != nil {
+			logger.Error("Failed to restore bandwidth limits of endpoint", zap.String("endpointID", ep.Id), zap.Error(err))
+		}
+	}
+}
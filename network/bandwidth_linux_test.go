@@ -0,0 +1,130 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/netio"
+	"github.com/Azure/azure-container-networking/netlink"
+	"github.com/stretchr/testify/require"
+)
+
+type qdiscCall struct {
+	ifName      string
+	rate, burst uint64
+}
+
+func recordQdiscCalls(nl *netlink.MockNetlink) (tbf, policer *[]qdiscCall) {
+	tbf, policer = &[]qdiscCall{}, &[]qdiscCall{}
+	nl.SetAddTbfQdiscValidationFn(func(ifName string, rate, burst uint64) error {
+		*tbf = append(*tbf, qdiscCall{ifName, rate, burst})
+		return nil
+	})
+	nl.SetAddIngressPolicerValidationFn(func(ifName string, rate, burst uint64) error {
+		*policer = append(*policer, qdiscCall{ifName, rate, burst})
+		return nil
+	})
+	return tbf, policer
+}
+
+func TestSetBandwidthLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      *BandwidthLimits
+		wantTbf     []qdiscCall
+		wantPolicer []qdiscCall
+		wantErr     bool
+	}{
+		{
+			name: "no limits",
+		},
+		{
+			name:    "ingress only",
+			limits:  &BandwidthLimits{IngressRate: 1000000, IngressBurst: 80000},
+			wantTbf: []qdiscCall{{"azvhost", 125000, 10000}},
+		},
+		{
+			name:        "ingress and egress",
+			limits:      &BandwidthLimits{IngressRate: 1000000, IngressBurst: 80000, EgressRate: 2000000, EgressBurst: 160000},
+			wantTbf:     []qdiscCall{{"azvhost", 125000, 10000}},
+			wantPolicer: []qdiscCall{{"azvhost", 250000, 20000}},
+		},
+		{
+			name:    "rate without burst",
+			limits:  &BandwidthLimits{EgressRate: 2000000},
+			wantErr: true,
+		},
+		{
+			name:    "rate below a byte per second",
+			limits:  &BandwidthLimits{IngressRate: 4, IngressBurst: 80000},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			nl := netlink.NewMockNetlink(false, "")
+			tbf, policer := recordQdiscCalls(nl)
+
+			err := setBandwidthLimits(nl, "azvhost", tt.limits)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errInvalidBandwidthLimits)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTbf, nilIfEmpty(*tbf))
+			require.Equal(t, tt.wantPolicer, nilIfEmpty(*policer))
+		})
+	}
+}
+
+func TestSetBandwidthLimitsNetlinkError(t *testing.T) {
+	nl := netlink.NewMockNetlink(true, "qdisc")
+	err := setBandwidthLimits(nl, "azvhost", &BandwidthLimits{IngressRate: 1000000, IngressBurst: 80000})
+	require.ErrorIs(t, err, netlink.ErrorMockNetlink)
+}
+
+func TestRestoreEndpointBandwidth(t *testing.T) {
+	limits := &BandwidthLimits{IngressRate: 1000000, IngressBurst: 80000}
+	nw := &network{
+		Id:   "nw",
+		Mode: opModeTransparent,
+		Endpoints: map[string]*endpoint{
+			"shaped":   {Id: "shaped", HostIfName: "azvshaped", Bandwidth: limits},
+			"gone":     {Id: "gone", HostIfName: "azvgone", Bandwidth: limits},
+			"unshaped": {Id: "unshaped", HostIfName: "azvunshaped"},
+		},
+	}
+
+	nl := netlink.NewMockNetlink(false, "")
+	tbf, _ := recordQdiscCalls(nl)
+	nio := netio.NewMockNetIO(false, 0)
+	nio.SetGetInterfaceValidatonFn(func(name string) (*net.Interface, error) {
+		if name == "azvgone" {
+			return nil, netio.ErrMockNetIOFail
+		}
+		return &net.Interface{Name: name}, nil
+	})
+
+	nm := &networkManager{netlink: nl, netio: nio, nsClient: NewMockNamespaceClient()}
+	nm.restoreEndpointBandwidth(nw)
+
+	require.Equal(t, []qdiscCall{{"azvshaped", 125000, 10000}}, *tbf)
+}
+
+func TestEndpointBandwidthInfo(t *testing.T) {
+	limits := &BandwidthLimits{EgressRate: 2000000, EgressBurst: 160000}
+	ep := &endpoint{Id: "ep1", Bandwidth: limits}
+	require.Equal(t, limits, ep.getInfo().Bandwidth)
+}
+
+func nilIfEmpty(calls []qdiscCall) []qdiscCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls
+}
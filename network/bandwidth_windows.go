@@ -0,0 +1,4 @@
+package network
+
+// restoreEndpointBandwidth is a no-op on windows where bandwidth limits are not shaped by the endpoint clients.
+func (nm *networkManager) restoreEndpointBandwidth(_ *network) {}
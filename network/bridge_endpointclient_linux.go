@@ -31,6 +31,7 @@ type LinuxBridgeEndpointClient struct {
 	plClient          platform.ExecClient
 	netioshim         netio.NetIOInterface
 	nuc               networkutils.NetworkUtils
+	iptablesClient    ipTablesClient
 }
 
 func NewLinuxBridgeEndpointClient(
@@ -40,6 +41,7 @@ func NewLinuxBridgeEndpointClient(
 	mode string,
 	nl netlink.NetlinkInterface,
 	plc platform.ExecClient,
+	iptc ipTablesClient,
 ) *LinuxBridgeEndpointClient {
 	client := &LinuxBridgeEndpointClient{
 		bridgeName:        extIf.BridgeName,
@@ -52,6 +54,7 @@ func NewLinuxBridgeEndpointClient(
 		netlink:           nl,
 		plClient:          plc,
 		netioshim:         &netio.NetIO{},
+		iptablesClient:    iptc,
 	}
 
 	client.hostIPAddresses = append(client.hostIPAddresses, extIf.IPAddresses...)
@@ -118,7 +121,13 @@ func (client *LinuxBridgeEndpointClient) AddEndpointRules(epInfo *EndpointInfo)
 		return err
 	}
 
-	return nil
+	hostPortRules, err := addHostPortRules(client.iptablesClient, epInfo.EndpointID, hostPortLocalMatch, epInfo.IPAddresses, epInfo.PortMappings)
+	if err != nil {
+		return err
+	}
+	epInfo.HostPortRules = hostPortRules
+
+	return setBandwidthLimits(client.netlink, client.hostVethName, epInfo.Bandwidth)
 }
 
 func (client *LinuxBridgeEndpointClient) DeleteEndpointRules(ep *endpoint) {
@@ -153,6 +162,8 @@ func (client *LinuxBridgeEndpointClient) DeleteEndpointRules(ep *endpoint) {
 			}
 		}
 	}
+
+	deleteHostPortRules(client.iptablesClient, ep.HostPortRules)
 }
 
 // getArpReplyAddress returns the MAC address to use in ARP replies.
@@ -58,6 +58,10 @@ type endpoint struct {
 	SecondaryInterfaces map[string]*InterfaceInfo
 	// Store nic type since we no longer populate SecondaryInterfaces
 	NICType cns.NICType
+	// HostPortRules are the iptables rules programmed for the port mappings of the endpoint (linux only)
+	HostPortRules []HostPortRule `json:",omitempty"`
+	// Bandwidth is the traffic shaping applied on the host interface of the endpoint (linux only)
+	Bandwidth *BandwidthLimits `json:",omitempty"`
 }
 
 // EndpointInfo contains read-only information about an endpoint.
@@ -97,7 +101,11 @@ type EndpointInfo struct {
 	SkipDefaultRoutes        bool
 	HNSEndpointID            string
 	HNSNetworkID             string
-	HostIfName               string // unused in windows, and in linux
+	HostIfName               string           // unused in windows, and in linux
+	PortMappings             []PortMapping    // used in linux; windows programs port mappings as endpoint policies
+	HostPortRules            []HostPortRule   // populated by the linux endpoint clients when programming PortMappings
+	HasHostPortRules         bool             // set in stateless CNI from the endpoint state kept by CNS, which has no HostPortRules
+	Bandwidth                *BandwidthLimits // used in linux; shaped with tc on the host interface
 	// Fields related to the network are below
 	MasterIfName                  string
 	AdapterName                   string
@@ -125,6 +133,32 @@ type RouteInfo struct {
 	Table    int
 }
 
+// PortMapping maps a port on the host to a port on the endpoint.
+type PortMapping struct {
+	HostPort      int
+	ContainerPort int
+	Protocol      string
+	HostIP        string
+}
+
+// BandwidthLimits are the rates in bits per second and bursts in bits that the traffic of an endpoint is shaped to.
+// Ingress is the traffic received by the endpoint and egress is the traffic it sends. A zero rate is unlimited.
+type BandwidthLimits struct {
+	IngressRate  uint64
+	IngressBurst uint64
+	EgressRate   uint64
+	EgressBurst  uint64
+}
+
+// HostPortRule is an iptables rule programmed for a port mapping.
+type HostPortRule struct {
+	Version string
+	Table   string
+	Chain   string
+	Match   string
+	Target  string
+}
+
 // InterfaceInfo contains information for secondary interfaces
 type InterfaceInfo struct {
 	Name              string
@@ -161,6 +195,7 @@ func (ifInfo *InterfaceInfo) PrettyString() string {
 
 // NewEndpoint creates a new endpoint in the network.
 func (nw *network) newEndpoint(
+	ctx context.Context,
 	apipaCli apipaClient,
 	nl netlink.NetlinkInterface,
 	plc platform.ExecClient,
@@ -180,7 +215,7 @@ func (nw *network) newEndpoint(
 
 	// Call the platform implementation.
 	// Pass nil for epClient and will be initialized in newendpointImpl
-	ep, err = nw.newEndpointImpl(apipaCli, nl, plc, netioCli, nil, nsc, iptc, epInfo)
+	ep, err = nw.newEndpointImpl(ctx, apipaCli, nl, plc, netioCli, nil, nsc, iptc, epInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -296,6 +331,8 @@ func (ep *endpoint) getInfo() *EndpointInfo {
 		HNSEndpointID:            ep.HnsId,
 		HostIfName:               ep.HostIfName,
 		NICType:                  ep.NICType,
+		HostPortRules:            ep.HostPortRules,
+		Bandwidth:                ep.Bandwidth,
 	}
 
 	info.Routes = append(info.Routes, ep.Routes...)
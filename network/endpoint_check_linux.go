@@ -0,0 +1,225 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/ebtables"
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/Azure/azure-container-networking/netlink"
+	"go.uber.org/zap"
+)
+
+// getEbtableRules is swapped out in unit tests so bridge mode checks do not exec ebtables.
+var getEbtableRules = ebtables.GetEbtableRules
+
+// checkEndpointImpl compares the endpoint with the interfaces, addresses, routes and rules
+// programmed on the node and returns a description of every mismatch.
+func (nm *networkManager) checkEndpointImpl(nw *network, ep *endpoint) []string {
+	var drifts []string
+	drift := func(format string, args ...interface{}) {
+		drifts = append(drifts, fmt.Sprintf(format, args...))
+	}
+
+	logger.Info("Checking endpoint", zap.String("id", ep.Id), zap.String("mode", nw.Mode), zap.Int("vlanID", ep.VlanID))
+
+	switch {
+	case ep.NICType == cns.DelegatedVMNIC:
+		// The delegated NIC is moved into the container as is; there is no host side state to check.
+	case ep.VlanID != 0 && nw.Mode == opModeTransparentVlan:
+		nm.checkTransparentVlanHostState(nw, ep, drift)
+	default:
+		hostIf := nm.checkHostInterface(ep.HostIfName, drift)
+		if hostIf == nil || ep.VlanID != 0 {
+			break
+		}
+
+		if nw.Mode == opModeTransparent {
+			nm.checkHostRoutes(ep, hostIf, drift)
+		} else {
+			checkBridgeRules(nw, ep, drift)
+		}
+	}
+
+	if ep.NetworkNameSpace != "" {
+		nm.checkContainerState(ep, drift)
+	}
+
+	return drifts
+}
+
+// checkHostInterface verifies the host side of the veth pair exists and is up.
+func (nm *networkManager) checkHostInterface(hostIfName string, drift func(string, ...interface{})) *net.Interface {
+	if hostIfName == "" {
+		return nil
+	}
+
+	hostIf, err := nm.netio.GetNetworkInterfaceByName(hostIfName)
+	if err != nil {
+		drift("host interface %s is missing", hostIfName)
+		return nil
+	}
+
+	if hostIf.Flags&net.FlagUp == 0 {
+		drift("host interface %s is down", hostIfName)
+	}
+
+	return hostIf
+}
+
+// checkHostRoutes verifies the /32 (/128) routes that steer pod traffic to the host veth in transparent mode.
+func (nm *networkManager) checkHostRoutes(ep *endpoint, hostIf *net.Interface, drift func(string, ...interface{})) {
+	for _, ipAddr := range ep.IPAddresses {
+		dst := net.IPNet{IP: ipAddr.IP, Mask: net.CIDRMask(ipv4FullMask, ipv4Bits)}
+		if ipAddr.IP.To4() == nil {
+			dst = net.IPNet{IP: ipAddr.IP, Mask: net.CIDRMask(ipv6FullMask, ipv6Bits)}
+		}
+
+		if !nm.routeExists(&dst, hostIf.Index, 0) {
+			drift("host route to %s via %s is missing", dst.String(), hostIf.Name)
+		}
+	}
+}
+
+// checkBridgeRules verifies the ebtables MAC DNAT rules programmed by the bridge endpoint client.
+func checkBridgeRules(nw *network, ep *endpoint, drift func(string, ...interface{})) {
+	rules, err := getEbtableRules(ebtables.Nat, ebtables.PreRouting)
+	if err != nil {
+		drift("unable to list ebtables %s %s rules: %v", ebtables.Nat, ebtables.PreRouting, err)
+		return
+	}
+
+	for _, ipAddr := range ep.IPAddresses {
+		dst := "--ip-dst"
+		if ipAddr.IP.To4() == nil {
+			dst = "--ip6-dst"
+		}
+
+		match := fmt.Sprintf("%s %s ", dst, ipAddr.IP.String())
+		found := false
+		for _, rule := range rules {
+			if strings.Contains(rule, match) && strings.Contains(rule, "--to-dst "+ep.MacAddress.String()) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			drift("ebtables dnat rule for %s on %s is missing", ipAddr.IP.String(), nw.extIf.Name)
+		}
+	}
+}
+
+// checkTransparentVlanHostState verifies the vnet namespace side of a transparent vlan endpoint.
+func (nm *networkManager) checkTransparentVlanHostState(nw *network, ep *endpoint, drift func(string, ...interface{})) {
+	vnetNSName := fmt.Sprintf("az_ns_%d", ep.VlanID)
+	vlanIfName := fmt.Sprintf("%s_%d", nw.extIf.Name, ep.VlanID)
+
+	err := ExecuteInNS(nm.nsClient, vnetNSName, func() error {
+		nm.checkHostInterface(ep.HostIfName, drift)
+
+		if _, err := nm.netio.GetNetworkInterfaceByName(vlanIfName); err != nil {
+			drift("vlan interface %s is missing from %s", vlanIfName, vnetNSName)
+		}
+
+		match := fmt.Sprintf("-i %s", vlanIfName)
+		if !nm.iptablesClient.RuleExists(iptables.V4, iptables.Mangle, iptables.Prerouting, match, iptables.Accept) {
+			drift("iptables %s %s rule accepting %s is missing in %s", iptables.Mangle, iptables.Prerouting, vlanIfName, vnetNSName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		drift("vnet namespace %s is not reachable: %v", vnetNSName, err)
+	}
+}
+
+// checkContainerState enters the container netns and verifies the interface, addresses and routes.
+func (nm *networkManager) checkContainerState(ep *endpoint, drift func(string, ...interface{})) {
+	ns, err := nm.nsClient.OpenNamespace(ep.NetworkNameSpace)
+	if err != nil {
+		drift("container netns %s is not reachable: %v", ep.NetworkNameSpace, err)
+		return
+	}
+	defer ns.Close()
+
+	if err = ns.Enter(); err != nil {
+		drift("unable to enter container netns %s: %v", ep.NetworkNameSpace, err)
+		return
+	}
+
+	defer func() {
+		if err := ns.Exit(); err != nil {
+			logger.Error("Failed to exit netns with", zap.Error(err))
+		}
+	}()
+
+	containerIf, err := nm.netio.GetNetworkInterfaceByName(ep.IfName)
+	if err != nil {
+		drift("container interface %s is missing", ep.IfName)
+		return
+	}
+
+	if containerIf.Flags&net.FlagUp == 0 {
+		drift("container interface %s is down", ep.IfName)
+	}
+
+	if len(ep.MacAddress) > 0 && containerIf.HardwareAddr.String() != ep.MacAddress.String() {
+		drift("container interface %s has mac %s, expected %s", ep.IfName, containerIf.HardwareAddr.String(), ep.MacAddress.String())
+	}
+
+	addrs, err := nm.netio.GetNetworkInterfaceAddrs(containerIf)
+	if err != nil {
+		drift("unable to list addresses on container interface %s: %v", ep.IfName, err)
+	} else {
+		for _, ipAddr := range ep.IPAddresses {
+			if !containsAddr(addrs, ipAddr.IP) {
+				drift("address %s is missing from container interface %s", ipAddr.String(), ep.IfName)
+			}
+		}
+	}
+
+	for i := range ep.Routes {
+		route := ep.Routes[i]
+		if !nm.routeExists(&route.Dst, containerIf.Index, route.Table) {
+			drift("container route to %s is missing", route.Dst.String())
+		}
+	}
+}
+
+// routeExists reports whether a route to dst exists on the given link and table, regardless of gateway.
+// A zero table means the main table.
+func (nm *networkManager) routeExists(dst *net.IPNet, linkIndex, table int) bool {
+	routes, err := nm.netlink.GetIPRoute(&netlink.Route{
+		Family:    netlink.GetIPAddressFamily(dst.IP),
+		Dst:       dst,
+		LinkIndex: linkIndex,
+		Table:     table,
+	})
+	if err != nil {
+		logger.Error("Failed to list routes", zap.String("dst", dst.String()), zap.Error(err))
+		return false
+	}
+
+	return len(routes) > 0
+}
+
+func containsAddr(addrs []net.Addr, ip net.IP) bool {
+	for _, addr := range addrs {
+		var addrIP net.IP
+		switch v := addr.(type) {
+		case *net.IPNet:
+			addrIP = v.IP
+		case *net.IPAddr:
+			addrIP = v.IP
+		}
+
+		if addrIP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,206 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/ebtables"
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/Azure/azure-container-networking/netio"
+	"github.com/Azure/azure-container-networking/netlink"
+	"github.com/stretchr/testify/require"
+)
+
+var errMockEbtables = errors.New("mock ebtables error")
+
+func newCheckTestManager(nl *netlink.MockNetlink, nio *netio.MockNetIO, mode string, ep *endpoint) *networkManager {
+	nw := &network{
+		Id:        "nw",
+		Mode:      mode,
+		Endpoints: map[string]*endpoint{ep.Id: ep},
+		extIf:     &externalInterface{Name: "eth0"},
+	}
+
+	return &networkManager{
+		ExternalInterfaces: map[string]*externalInterface{
+			"eth0": {Name: "eth0", Networks: map[string]*network{nw.Id: nw}},
+		},
+		netlink:        nl,
+		netio:          nio,
+		nsClient:       NewMockNamespaceClient(),
+		iptablesClient: iptables.NewClient(),
+	}
+}
+
+func newCheckTestEndpoint() *endpoint {
+	_, defaultDst, _ := net.ParseCIDR("0.0.0.0/0")
+	return &endpoint{
+		Id:               "ep",
+		HostIfName:       "azvhost",
+		IfName:           "eth0",
+		NetworkNameSpace: "/var/run/netns/test",
+		MacAddress:       netio.HwAddr,
+		IPAddresses: []net.IPNet{
+			{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)},
+		},
+		Routes: []RouteInfo{{Dst: *defaultDst}},
+	}
+}
+
+func upInterfaceFn(missing string) func(string) (*net.Interface, error) {
+	return func(name string) (*net.Interface, error) {
+		if name == missing {
+			return nil, netio.ErrMockNetIOFail
+		}
+		return &net.Interface{Name: name, Index: 2, Flags: net.FlagUp, HardwareAddr: netio.HwAddr}, nil
+	}
+}
+
+func addrsFn(addrs ...string) func(*net.Interface) ([]net.Addr, error) {
+	return func(*net.Interface) ([]net.Addr, error) {
+		result := []net.Addr{}
+		for _, addr := range addrs {
+			ip, ipNet, _ := net.ParseCIDR(addr)
+			result = append(result, &net.IPNet{IP: ip, Mask: ipNet.Mask})
+		}
+		return result, nil
+	}
+}
+
+func routesFn(present bool) func(*netlink.Route) ([]*netlink.Route, error) {
+	return func(filter *netlink.Route) ([]*netlink.Route, error) {
+		if !present {
+			return nil, nil
+		}
+		return []*netlink.Route{filter}, nil
+	}
+}
+
+func TestCheckEndpointTransparent(t *testing.T) {
+	tests := []struct {
+		name       string
+		missingIf  string
+		addrs      []string
+		routes     bool
+		wantDrifts []string
+	}{
+		{
+			name:   "healthy endpoint",
+			addrs:  []string{"10.0.0.4/24"},
+			routes: true,
+		},
+		{
+			name:       "host veth missing",
+			missingIf:  "azvhost",
+			addrs:      []string{"10.0.0.4/24"},
+			routes:     true,
+			wantDrifts: []string{"host interface azvhost is missing"},
+		},
+		{
+			name:       "container interface missing",
+			missingIf:  "eth0",
+			routes:     true,
+			wantDrifts: []string{"container interface eth0 is missing"},
+		},
+		{
+			name:       "address missing",
+			addrs:      []string{"10.0.0.5/24"},
+			routes:     true,
+			wantDrifts: []string{"address 10.0.0.4/24 is missing from container interface eth0"},
+		},
+		{
+			name:  "routes missing",
+			addrs: []string{"10.0.0.4/24"},
+			wantDrifts: []string{
+				"host route to 10.0.0.4/32 via azvhost is missing",
+				"container route to 0.0.0.0/0 is missing",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			nl := netlink.NewMockNetlink(false, "")
+			nl.SetGetRouteFn(routesFn(tt.routes))
+			nio := netio.NewMockNetIO(false, 0)
+			nio.SetGetInterfaceValidatonFn(upInterfaceFn(tt.missingIf))
+			nio.SetGetInterfaceAddrsFn(addrsFn(tt.addrs...))
+
+			nm := newCheckTestManager(nl, nio, opModeTransparent, newCheckTestEndpoint())
+			err := nm.CheckEndpoint("nw", "ep")
+			if len(tt.wantDrifts) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var driftErr *EndpointDriftError
+			require.ErrorAs(t, err, &driftErr)
+			require.Equal(t, tt.wantDrifts, driftErr.Drifts)
+			require.True(t, IsEndpointDriftError(err))
+		})
+	}
+}
+
+func TestCheckEndpointBridge(t *testing.T) {
+	defer func() { getEbtableRules = ebtables.GetEbtableRules }()
+
+	tests := []struct {
+		name       string
+		rules      []string
+		rulesErr   error
+		wantDrifts []string
+	}{
+		{
+			name:  "dnat rule present",
+			rules: []string{"-p IPv4 --ip-dst 10.0.0.4 -j dnat --to-dst ab:cd:ef:12:34:56 --dnat-target ACCEPT"},
+		},
+		{
+			name:       "dnat rule missing",
+			rules:      []string{"-p IPv4 --ip-dst 10.0.0.44 -j dnat --to-dst ab:cd:ef:12:34:56 --dnat-target ACCEPT"},
+			wantDrifts: []string{"ebtables dnat rule for 10.0.0.4 on eth0 is missing"},
+		},
+		{
+			name:       "ebtables fails",
+			rulesErr:   errMockEbtables,
+			wantDrifts: []string{"unable to list ebtables nat PREROUTING rules: " + errMockEbtables.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			getEbtableRules = func(string, string) ([]string, error) {
+				return tt.rules, tt.rulesErr
+			}
+
+			nl := netlink.NewMockNetlink(false, "")
+			nl.SetGetRouteFn(routesFn(true))
+			nio := netio.NewMockNetIO(false, 0)
+			nio.SetGetInterfaceValidatonFn(upInterfaceFn(""))
+			nio.SetGetInterfaceAddrsFn(addrsFn("10.0.0.4/24"))
+
+			nm := newCheckTestManager(nl, nio, opModeBridge, newCheckTestEndpoint())
+			err := nm.CheckEndpoint("nw", "ep")
+			if len(tt.wantDrifts) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var driftErr *EndpointDriftError
+			require.ErrorAs(t, err, &driftErr)
+			require.Equal(t, tt.wantDrifts, driftErr.Drifts)
+		})
+	}
+}
+
+func TestCheckEndpointNotFound(t *testing.T) {
+	nm := newCheckTestManager(netlink.NewMockNetlink(false, ""), netio.NewMockNetIO(false, 0), opModeTransparent, newCheckTestEndpoint())
+
+	require.ErrorIs(t, nm.CheckEndpoint("missing", "ep"), errNetworkNotFound)
+	require.ErrorIs(t, nm.CheckEndpoint("nw", "missing"), errEndpointNotFound)
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// checkEndpointImpl verifies the HNS endpoint backing the endpoint still exists and, for HNS v2,
+// is still attached to the container namespace. It returns a description of every mismatch.
+func (nm *networkManager) checkEndpointImpl(_ *network, ep *endpoint) []string {
+	var drifts []string
+
+	if ep.HnsId == "" {
+		return drifts
+	}
+
+	logger.Info("Checking endpoint", zap.String("id", ep.Id), zap.String("HnsId", ep.HnsId))
+
+	if useHnsV2, err := UseHnsV2(ep.NetNs); useHnsV2 && err == nil {
+		hcnEndpoint, err := Hnsv2.GetEndpointByID(ep.HnsId)
+		if err != nil {
+			return append(drifts, fmt.Sprintf("hcn endpoint %s is missing: %v", ep.HnsId, err))
+		}
+
+		if ep.NetNs != "" && hcnEndpoint.HostComputeNamespace != ep.NetNs {
+			drifts = append(drifts, fmt.Sprintf("hcn endpoint %s is attached to namespace %q, expected %q",
+				ep.HnsId, hcnEndpoint.HostComputeNamespace, ep.NetNs))
+		}
+
+		return drifts
+	}
+
+	if _, err := Hnsv1.GetHNSEndpointByID(ep.HnsId); err != nil {
+		drifts = append(drifts, fmt.Sprintf("hns endpoint %s is missing: %v", ep.HnsId, err))
+	}
+
+	return drifts
+}
@@ -4,6 +4,7 @@
 package network
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
@@ -50,6 +51,7 @@ func ConstructEndpointID(containerID string, _ string, ifName string) (string, s
 
 // newEndpointImpl creates a new endpoint in the network.
 func (nw *network) newEndpointImpl(
+	ctx context.Context,
 	_ apipaClient,
 	nl netlink.NetlinkInterface,
 	plc platform.ExecClient,
@@ -164,15 +166,16 @@ func (nw *network) newEndpointImpl(
 			}
 		} else if nw.Mode != opModeTransparent {
 			logger.Info("Bridge client")
-			epClient = NewLinuxBridgeEndpointClient(nw.extIf, hostIfName, contIfName, nw.Mode, nl, plc)
+			epClient = NewLinuxBridgeEndpointClient(nw.extIf, hostIfName, contIfName, nw.Mode, nl, plc, iptc)
 		} else if epInfo.NICType == cns.DelegatedVMNIC {
 			logger.Info("Secondary client")
 			epClient = NewSecondaryEndpointClient(nl, netioCli, plc, nsc, ep)
 		} else {
 			logger.Info("Transparent client")
-			epClient = NewTransparentEndpointClient(nw.extIf, hostIfName, contIfName, nw.Mode, nl, netioCli, plc)
+			epClient = NewTransparentEndpointClient(nw.extIf, hostIfName, contIfName, nw.Mode, nl, netioCli, plc, iptc)
 		}
 	}
+	epClient = newTracedEndpointClient(ctx, epClient, epInfo)
 
 	//nolint:gocritic
 	defer func(client EndpointClient, contIfName string) {
@@ -208,6 +211,8 @@ func (nw *network) newEndpointImpl(
 		if epErr := epClient.AddEndpointRules(epInfo); epErr != nil {
 			return epErr
 		}
+		ep.HostPortRules = epInfo.HostPortRules
+		ep.Bandwidth = epInfo.Bandwidth
 
 		// If a network namespace for the container interface is specified...
 		if epInfo.NetNsPath != "" {
@@ -283,7 +288,7 @@ func (nw *network) deleteEndpointImpl(nl netlink.NetlinkInterface, plc platform.
 				epClient = NewOVSEndpointClient(nw, epInfo, ep.HostIfName, "", ep.VlanID, ep.LocalIP, nl, ovsctl.NewOvsctl(), plc, iptc)
 			}
 		} else if nw.Mode != opModeTransparent {
-			epClient = NewLinuxBridgeEndpointClient(nw.extIf, ep.HostIfName, "", nw.Mode, nl, plc)
+			epClient = NewLinuxBridgeEndpointClient(nw.extIf, ep.HostIfName, "", nw.Mode, nl, plc, iptc)
 		} else {
 			// delete if secondary interfaces populated or endpoint of type delegated (new way)
 			if len(ep.SecondaryInterfaces) > 0 || ep.NICType == cns.DelegatedVMNIC {
@@ -297,7 +302,7 @@ func (nw *network) deleteEndpointImpl(nl netlink.NetlinkInterface, plc platform.
 				}
 			}
 
-			epClient = NewTransparentEndpointClient(nw.extIf, ep.HostIfName, "", nw.Mode, nl, nioc, plc)
+			epClient = NewTransparentEndpointClient(nw.extIf, ep.HostIfName, "", nw.Mode, nl, nioc, plc, iptc)
 		}
 	}
 
@@ -67,6 +67,7 @@ func ConstructEndpointID(containerID string, netNsPath string, ifName string) (s
 
 // newEndpointImpl creates a new endpoint in the network.
 func (nw *network) newEndpointImpl(
+	_ context.Context,
 	cli apipaClient,
 	_ netlink.NetlinkInterface,
 	plc platform.ExecClient,
@@ -0,0 +1,64 @@
+package network
+
+import (
+	"context"
+
+	"github.com/Azure/azure-container-networking/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracedEndpointClient records a span, child of the span of the CNI command, for each step of the endpoint creation
+// and cleanup done by the wrapped client.
+type tracedEndpointClient struct {
+	EndpointClient
+	ctx   context.Context //nolint:containedctx // the EndpointClient methods do not take a context
+	attrs []attribute.KeyValue
+}
+
+func newTracedEndpointClient(ctx context.Context, client EndpointClient, epInfo *EndpointInfo) EndpointClient {
+	return &tracedEndpointClient{
+		EndpointClient: client,
+		ctx:            ctx,
+		attrs: []attribute.KeyValue{
+			attribute.String("endpointID", epInfo.EndpointID),
+			attribute.String("nicType", string(epInfo.NICType)),
+		},
+	}
+}
+
+func (c *tracedEndpointClient) trace(name string, fn func() error) error {
+	return tracing.Trace(c.ctx, "endpointclient."+name, func(context.Context) error { return fn() }, c.attrs...)
+}
+
+func (c *tracedEndpointClient) AddEndpoints(epInfo *EndpointInfo) error {
+	return c.trace("AddEndpoints", func() error { return c.EndpointClient.AddEndpoints(epInfo) })
+}
+
+func (c *tracedEndpointClient) AddEndpointRules(epInfo *EndpointInfo) error {
+	return c.trace("AddEndpointRules", func() error { return c.EndpointClient.AddEndpointRules(epInfo) })
+}
+
+func (c *tracedEndpointClient) DeleteEndpointRules(ep *endpoint) {
+	_ = c.trace("DeleteEndpointRules", func() error {
+		c.EndpointClient.DeleteEndpointRules(ep)
+		return nil
+	})
+}
+
+func (c *tracedEndpointClient) MoveEndpointsToContainerNS(epInfo *EndpointInfo, nsID uintptr) error {
+	return c.trace("MoveEndpointsToContainerNS", func() error { return c.EndpointClient.MoveEndpointsToContainerNS(epInfo, nsID) })
+}
+
+func (c *tracedEndpointClient) SetupContainerInterfaces(epInfo *EndpointInfo) error {
+	return c.trace("SetupContainerInterfaces", func() error { return c.EndpointClient.SetupContainerInterfaces(epInfo) })
+}
+
+func (c *tracedEndpointClient) ConfigureContainerInterfacesAndRoutes(epInfo *EndpointInfo) error {
+	return c.trace("ConfigureContainerInterfacesAndRoutes", func() error {
+		return c.EndpointClient.ConfigureContainerInterfacesAndRoutes(epInfo)
+	})
+}
+
+func (c *tracedEndpointClient) DeleteEndpoints(ep *endpoint) error {
+	return c.trace("DeleteEndpoints", func() error { return c.EndpointClient.DeleteEndpoints(ep) })
+}
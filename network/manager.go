@@ -43,6 +43,18 @@ const (
 	dummyGUID = "12345678-1234-1234-1234-123456789012" // guid to trigger hnsv2 in windows
 )
 
+// stateSchema lists the migrations of the network manager state persisted under storeKey.
+// Append a migration whenever a change of the state cannot be read by the previous version.
+// Each endpoint is a record of its own in a bolt store, so saving the state only writes the endpoints that changed.
+var stateSchema = store.MustNewSchema("network",
+	store.Migration{
+		Version:     1,
+		Description: "start versioning the network state",
+		Up:          store.NoopMigration,
+		Down:        store.NoopMigration,
+	},
+).WithRecords("ExternalInterfaces/*/Networks/*/Endpoints/*")
+
 var Ipv4DefaultRouteDstPrefix = net.IPNet{
 	IP:   net.IPv4zero,
 	Mask: net.IPv4Mask(0, 0, 0, 0),
@@ -104,9 +116,10 @@ type NetworkManager interface {
 	GetNumEndpointsByContainerID(containerID string) int
 
 	CreateEndpoint(client apipaClient, networkID string, epInfo *EndpointInfo) error
-	EndpointCreate(client apipaClient, epInfos []*EndpointInfo) error // TODO: change name
+	EndpointCreate(ctx context.Context, client apipaClient, epInfos []*EndpointInfo) error // TODO: change name
 	DeleteEndpoint(networkID string, endpointID string, epInfo *EndpointInfo) error
 	GetEndpointInfo(networkID string, endpointID string) (*EndpointInfo, error)
+	CheckEndpoint(networkID string, endpointID string) error
 	GetAllEndpoints(networkID string) (map[string]*EndpointInfo, error)
 	GetEndpointInfoBasedOnPODDetails(networkID string, podName string, podNameSpace string, doExactMatchForPodName bool) (*EndpointInfo, error)
 	AttachEndpoint(networkID string, endpointID string, sandboxKey string) (*endpoint, error)
@@ -187,7 +200,7 @@ func (nm *networkManager) restore(isRehydrationRequired bool) error {
 	// Ignore the persisted state if it is older than the last reboot time.
 
 	// Read any persisted state.
-	err := nm.store.Read(storeKey, nm)
+	err := stateSchema.Read(nm.store, storeKey, nm)
 	if err != nil {
 		if err == store.ErrKeyNotFound {
 			logger.Info("network store key not found")
@@ -230,7 +243,8 @@ func (nm *networkManager) restore(isRehydrationRequired bool) error {
 						delete(nm.ExternalInterfaces, extIfName)
 					}
 
-					return nil
+					// A bolt store is not removed with the JSON file, so the cleared state replaces the old one.
+					return nm.save()
 				}
 			}
 		}
@@ -247,6 +261,9 @@ func (nm *networkManager) restore(isRehydrationRequired bool) error {
 		logger.Info("Rehydrating network state from persistent store")
 		for _, extIf := range nm.ExternalInterfaces {
 			for _, nw := range extIf.Networks {
+				// Host port rules of endpoints from before the reboot are stale.
+				nm.deleteEndpointHostPortRules(nw)
+
 				nwInfo, err := nm.GetNetworkInfo(nw.Id)
 				if err != nil {
 					logger.Error("Failed to fetch network info for network extif err. This should not happen",
@@ -262,6 +279,9 @@ func (nm *networkManager) restore(isRehydrationRequired bool) error {
 						zap.Any("nwInfo", nwInfo), zap.Any("extIf", extIf), zap.Error(err))
 					return err
 				}
+
+				// Qdiscs do not survive a reboot, reapply the limits of endpoints that did.
+				nm.restoreEndpointBandwidth(nw)
 			}
 		}
 	}
@@ -284,7 +304,7 @@ func (nm *networkManager) save() error {
 	// Update time stamp.
 	nm.TimeStamp = time.Now()
 
-	err := nm.store.Write(storeKey, nm)
+	err := stateSchema.Write(nm.store, storeKey, nm)
 	if err == nil {
 		logger.Info("Save succeeded")
 	} else {
@@ -370,7 +390,7 @@ func (nm *networkManager) GetNetworkInfo(networkID string) (EndpointInfo, error)
 	return nwInfo, nil
 }
 
-func (nm *networkManager) createEndpoint(cli apipaClient, networkID string, epInfo *EndpointInfo) (*endpoint, error) {
+func (nm *networkManager) createEndpoint(ctx context.Context, cli apipaClient, networkID string, epInfo *EndpointInfo) (*endpoint, error) {
 	nm.Lock()
 	defer nm.Unlock()
 
@@ -386,7 +406,7 @@ func (nm *networkManager) createEndpoint(cli apipaClient, networkID string, epIn
 		}
 	}
 
-	ep, err := nw.newEndpoint(cli, nm.netlink, nm.plClient, nm.netio, nm.nsClient, nm.iptablesClient, epInfo)
+	ep, err := nw.newEndpoint(ctx, cli, nm.netlink, nm.plClient, nm.netio, nm.nsClient, nm.iptablesClient, epInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -407,7 +427,7 @@ func (nm *networkManager) createEndpoint(cli apipaClient, networkID string, epIn
 
 // CreateEndpoint creates a new container endpoint (this is for compatibility-- add flow should no longer use this).
 func (nm *networkManager) CreateEndpoint(cli apipaClient, networkID string, epInfo *EndpointInfo) error {
-	_, err := nm.createEndpoint(cli, networkID, epInfo)
+	_, err := nm.createEndpoint(context.TODO(), cli, networkID, epInfo)
 	return err
 }
 
@@ -511,6 +531,8 @@ func (nm *networkManager) DeleteEndpointState(networkID string, epInfo *Endpoint
 		IfName:                   epInfo.IfName, // TODO: For stateless cni linux populate IfName here to use in deletion in secondary endpoint client
 	}
 	logger.Info("Deleting endpoint with", zap.String("Endpoint Info: ", epInfo.PrettyString()), zap.String("HNISID : ", ep.HnsId))
+	// the host port rules of the endpoint are not in the endpoint state kept by CNS
+	nm.deleteStatelessEndpointHostPortRules(epInfo)
 	// do not need to Delete HNS endpoint if the there is no HNS in state
 	if ep.HnsId != "" {
 		err := nw.deleteEndpointImpl(netlink.NewNetlink(), platform.NewExecClient(logger), nil, nil, nil, nil, ep)
@@ -563,6 +585,30 @@ func (nm *networkManager) GetEndpointInfo(networkID, endpointID string) (*Endpoi
 	return ep.getInfo(), nil
 }
 
+// CheckEndpoint compares the persisted state of an endpoint with the live dataplane.
+// It returns an EndpointDriftError naming every piece of state that no longer matches.
+func (nm *networkManager) CheckEndpoint(networkID, endpointID string) error {
+	nm.Lock()
+	defer nm.Unlock()
+
+	nw, err := nm.getNetwork(networkID)
+	if err != nil {
+		return err
+	}
+
+	ep, err := nw.getEndpoint(endpointID)
+	if err != nil {
+		return err
+	}
+
+	drifts := nm.checkEndpointImpl(nw, ep)
+	if len(drifts) > 0 {
+		return &EndpointDriftError{EndpointID: endpointID, Drifts: drifts}
+	}
+
+	return nil
+}
+
 func (nm *networkManager) GetAllEndpoints(networkId string) (map[string]*EndpointInfo, error) {
 	nm.Lock()
 	defer nm.Unlock()
@@ -784,6 +830,7 @@ func cnsEndpointInfotoCNIEpInfos(endpointInfo restserver.EndpointInfo, endpointI
 		epInfo.NICType = ipInfo.NICType
 		epInfo.HNSNetworkID = ipInfo.HnsNetworkID
 		epInfo.MacAddress = net.HardwareAddr(ipInfo.MacAddress)
+		epInfo.HasHostPortRules = ipInfo.HasHostPortRules
 		ret = append(ret, epInfo)
 	}
 	return ret
@@ -812,11 +859,12 @@ func generateCNSIPInfoMap(eps []*endpoint) map[string]*restserver.IPInfo {
 
 	for _, ep := range eps {
 		ifNametoIPInfoMap[ep.IfName] = &restserver.IPInfo{ // in windows, the nicname is args ifname, in linux, it's ethX
-			NICType:       ep.NICType,
-			HnsEndpointID: ep.HnsId,
-			HnsNetworkID:  ep.HNSNetworkID,
-			HostVethName:  ep.HostIfName,
-			MacAddress:    ep.MacAddress.String(),
+			NICType:          ep.NICType,
+			HnsEndpointID:    ep.HnsId,
+			HnsNetworkID:     ep.HNSNetworkID,
+			HostVethName:     ep.HostIfName,
+			MacAddress:       ep.MacAddress.String(),
+			HasHostPortRules: len(ep.HostPortRules) > 0,
 		}
 	}
 
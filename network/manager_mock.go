@@ -1,6 +1,8 @@
 package network
 
 import (
+	"context"
+
 	"github.com/Azure/azure-container-networking/common"
 )
 
@@ -106,6 +108,14 @@ func (nm *MockNetworkManager) GetEndpointInfo(_, endpointID string) (*EndpointIn
 	return nil, errEndpointNotFound
 }
 
+// CheckEndpoint mock
+func (nm *MockNetworkManager) CheckEndpoint(_, endpointID string) error {
+	if _, exists := nm.TestEndpointInfoMap[endpointID]; !exists {
+		return errEndpointNotFound
+	}
+	return nil
+}
+
 // GetEndpointInfoBasedOnPODDetails mock
 func (nm *MockNetworkManager) GetEndpointInfoBasedOnPODDetails(networkID string, podName string, podNameSpace string, doExactMatchForPodName bool) (*EndpointInfo, error) {
 	return &EndpointInfo{}, nil
@@ -164,7 +174,7 @@ func (nm *MockNetworkManager) SaveState(eps []*endpoint) error {
 	return nil
 }
 
-func (nm *MockNetworkManager) EndpointCreate(client apipaClient, epInfos []*EndpointInfo) error {
+func (nm *MockNetworkManager) EndpointCreate(_ context.Context, client apipaClient, epInfos []*EndpointInfo) error {
 	eps := []*endpoint{}
 	for _, epInfo := range epInfos {
 		_, nwGetErr := nm.GetNetworkInfo(epInfo.NetworkID)
@@ -1,8 +1,11 @@
 package network
 
 import (
+	"context"
 	"errors"
 	"net"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/cns"
 	"github.com/Azure/azure-container-networking/cns/restserver"
+	"github.com/Azure/azure-container-networking/processlock"
 	"github.com/Azure/azure-container-networking/store"
 	"github.com/Azure/azure-container-networking/testutils"
 )
@@ -80,6 +84,17 @@ var _ = Describe("Test Manager", func() {
 			})
 		})
 
+		Context("When the state was written by a newer schema version", func() {
+			It("Should raise error", func() {
+				kvs := store.NewMockStore("")
+				err := kvs.Write(storeKey, map[string]interface{}{store.SchemaVersionField: stateSchema.Version() + 1})
+				Expect(err).NotTo(HaveOccurred())
+				nm := &networkManager{store: kvs}
+				err = nm.restore(false)
+				Expect(errors.Is(err, store.ErrSchemaTooNew)).To(BeTrue())
+			})
+		})
+
 		Context("When GetModificationTime error and not rebooted", func() {
 			It("Should populate pointers", func() {
 				extIfName := "eth0"
@@ -102,6 +117,77 @@ var _ = Describe("Test Manager", func() {
 		})
 	})
 
+	Describe("Test restore after the store migration", func() {
+		Context("When the state was migrated to a bolt store and the store type is not configured", func() {
+			It("Should restore the migrated state, e.g. for GET_ENDPOINT_STATE", func() {
+				dir, err := os.MkdirTemp("", "azure-vnet")
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(dir)
+				path := filepath.Join(dir, "azure-vnet")
+
+				newStore := func(storeType string) store.KeyValueStore {
+					kvs, err := store.NewStore(storeType, path, processlock.NewMockFileLock(false), nil)
+					Expect(err).NotTo(HaveOccurred())
+					return kvs
+				}
+
+				// the state of a JSON store
+				nm := &networkManager{
+					store: newStore(store.JSONStoreType),
+					ExternalInterfaces: map[string]*externalInterface{
+						"eth0": {
+							Name: "eth0",
+							Networks: map[string]*network{
+								"azure": {
+									Id:        "azure",
+									Endpoints: map[string]*endpoint{"ep1": {Id: "ep1", ContainerID: "container1"}},
+								},
+							},
+						},
+					},
+				}
+				Expect(nm.save()).To(Succeed())
+
+				// an ADD configured with a bolt store migrates it
+				nm = &networkManager{store: newStore(store.BoltStoreType), ExternalInterfaces: map[string]*externalInterface{}}
+				Expect(nm.restore(false)).To(Succeed())
+				Expect(nm.store.Unlock()).To(Succeed())
+				_, err = os.Stat(path + store.JSONExtension)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+
+				// GET_ENDPOINT_STATE has no network config, so no store type
+				nm = &networkManager{store: newStore(""), ExternalInterfaces: map[string]*externalInterface{}}
+				Expect(nm.restore(false)).To(Succeed())
+				endpoints, err := nm.GetAllEndpoints("azure")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(endpoints).To(HaveKey("ep1"))
+				Expect(endpoints["ep1"].ContainerID).To(Equal("container1"))
+
+				// the endpoints are saved as records of the bolt store
+				nm.ExternalInterfaces["eth0"].Networks["azure"].Endpoints["ep2"] = &endpoint{Id: "ep2", ContainerID: "container2"}
+				Expect(nm.save()).To(Succeed())
+				Expect(nm.store.Unlock()).To(Succeed())
+
+				nm = &networkManager{store: newStore(""), ExternalInterfaces: map[string]*externalInterface{}}
+				Expect(nm.restore(false)).To(Succeed())
+				endpoints, err = nm.GetAllEndpoints("azure")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(endpoints).To(HaveLen(2))
+				Expect(endpoints["ep2"].ContainerID).To(Equal("container2"))
+				Expect(nm.store.Unlock()).To(Succeed())
+
+				// a JSON store configured for a rollback gets the endpoints back in the network state
+				nm = &networkManager{store: newStore(store.JSONStoreType), ExternalInterfaces: map[string]*externalInterface{}}
+				Expect(nm.restore(false)).To(Succeed())
+				endpoints, err = nm.GetAllEndpoints("azure")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(endpoints).To(HaveLen(2))
+				_, err = os.Stat(path + store.BoltExtension)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+	})
+
 	Describe("Test save", func() {
 		Context("When store is nil", func() {
 			It("Should return nil", func() {
@@ -234,7 +320,7 @@ var _ = Describe("Test Manager", func() {
 		Context("When no endpoints provided", func() {
 			It("Should return 0", func() {
 				nm := &networkManager{}
-				err := nm.EndpointCreate(nil, []*EndpointInfo{})
+				err := nm.EndpointCreate(context.Background(), nil, []*EndpointInfo{})
 				Expect(err).NotTo(HaveOccurred())
 				num := nm.GetNumberOfEndpoints("", "")
 				Expect(num).To(Equal(0))
@@ -413,6 +499,9 @@ var _ = Describe("Test Manager", func() {
 						HNSNetworkID: "hnsNetworkID1",
 						HostIfName:   "hostIfName1",
 						MacAddress:   mac1,
+						HostPortRules: []HostPortRule{
+							{Version: "4", Table: "nat", Chain: "AZCNIHP-1", Match: "-p tcp --dport 8080", Target: "DNAT --to-destination 10.0.0.4:80"},
+						},
 					},
 					{
 						IfName:       "eth1",
@@ -429,11 +518,12 @@ var _ = Describe("Test Manager", func() {
 				Expect(cnsEpInfos).To(HaveKey("eth0"))
 				Expect(cnsEpInfos["eth0"]).To(Equal(
 					&restserver.IPInfo{
-						NICType:       cns.InfraNIC,
-						HnsEndpointID: "hnsEndpointID1",
-						HnsNetworkID:  "hnsNetworkID1",
-						HostVethName:  "hostIfName1",
-						MacAddress:    "12:34:56:78:9a:bc",
+						NICType:          cns.InfraNIC,
+						HnsEndpointID:    "hnsEndpointID1",
+						HnsNetworkID:     "hnsNetworkID1",
+						HostVethName:     "hostIfName1",
+						MacAddress:       "12:34:56:78:9a:bc",
+						HasHostPortRules: true,
 					},
 				))
 
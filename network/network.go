@@ -4,6 +4,7 @@
 package network
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
@@ -298,7 +299,7 @@ func (nm *networkManager) GetNumEndpointsByContainerID(containerID string) int {
 }
 
 // Creates the network and corresponding endpoint (should be called once during Add)
-func (nm *networkManager) EndpointCreate(cnsclient apipaClient, epInfos []*EndpointInfo) error {
+func (nm *networkManager) EndpointCreate(ctx context.Context, cnsclient apipaClient, epInfos []*EndpointInfo) error {
 	eps := []*endpoint{} // save endpoints for stateless
 
 	for _, epInfo := range epInfos {
@@ -323,7 +324,7 @@ func (nm *networkManager) EndpointCreate(cnsclient apipaClient, epInfos []*Endpo
 			}
 		}
 
-		ep, err := nm.createEndpoint(cnsclient, epInfo.NetworkID, epInfo)
+		ep, err := nm.createEndpoint(ctx, cnsclient, epInfo.NetworkID, epInfo)
 		if err != nil {
 			return err
 		}
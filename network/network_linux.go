@@ -136,8 +136,8 @@ func (nm *networkManager) handleCommonOptions(ifName string, nwInfo *EndpointInf
 		}
 	}
 
-	if iptcmds, exists := nwInfo.Options[IPTablesKey]; exists {
-		err = nm.addToIptables(iptcmds.([]iptables.IPTableEntry))
+	if changes, exists := nwInfo.Options[IPTablesKey]; exists {
+		err = nm.addToIptables(changes.([]iptables.RuleChange))
 		if err != nil {
 			return err
 		}
@@ -612,7 +612,9 @@ func (nm *networkManager) connectExternalInterface(extIf *externalInterface, nwI
 
 		// unmark packet if set by kube-proxy to skip kube-postrouting rule and processed
 		// by cni snat rule
-		if err = nm.iptablesClient.InsertIptableRule(iptables.V6, iptables.Mangle, iptables.Postrouting, "", "MARK --set-mark 0x0"); err != nil {
+		tx := nm.iptablesClient.Begin(iptables.V6)
+		tx.Insert(iptables.Mangle, iptables.Postrouting, "", "MARK --set-mark 0x0")
+		if err = tx.Commit(); err != nil {
 			logger.Error("Adding Iptable mangle rule failed", zap.Error(err))
 			return err
 		}
@@ -650,15 +652,15 @@ func (nm *networkManager) disconnectExternalInterface(extIf *externalInterface,
 	logger.Info("Disconnected interface", zap.String("Name", extIf.Name))
 }
 
-func (nm *networkManager) addToIptables(cmds []iptables.IPTableEntry) error {
+// addToIptables commits the additional ipv4 rules of the network in one transaction.
+func (nm *networkManager) addToIptables(changes []iptables.RuleChange) error {
 	logger.Info("Adding additional iptable rules...")
-	for _, cmd := range cmds {
-		err := nm.iptablesClient.RunCmd(cmd.Version, cmd.Params)
-		if err != nil {
-			return err
-		}
-		logger.Info("Successfully run iptables rule", zap.Any("cmd", cmd))
+	tx := nm.iptablesClient.Begin(iptables.V4)
+	tx.AddChanges(changes...)
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to add additional iptables rules")
 	}
+	logger.Info("Successfully added iptables rules", zap.Any("changes", changes))
 	return nil
 }
 
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCommonOptionsCommitsIPTablesInOneTransaction(t *testing.T) {
+	iptc := newFakeIPTablesClient()
+	nm := &networkManager{iptablesClient: iptc}
+	nwInfo := &EndpointInfo{
+		Options: map[string]interface{}{
+			IPTablesKey: []iptables.RuleChange{
+				{Action: iptables.NewChain, Table: iptables.Nat, Chain: iptables.Swift},
+				{Action: iptables.Append, Table: iptables.Nat, Chain: iptables.Postrouting, Target: iptables.Swift},
+				{Action: iptables.Insert, Table: iptables.Nat, Chain: iptables.Swift, Match: "-d 168.63.129.16", Target: "SNAT --to 10.0.1.20"},
+			},
+		},
+	}
+
+	require.NoError(t, nm.handleCommonOptions("eth0", nwInfo))
+	require.Equal(t, 1, iptc.commits)
+	require.True(t, iptc.chains[iptables.V4+" "+iptables.Nat+" "+iptables.Swift])
+	require.True(t, iptc.RuleExists(iptables.V4, iptables.Nat, iptables.Postrouting, "", iptables.Swift))
+	require.True(t, iptc.RuleExists(iptables.V4, iptables.Nat, iptables.Swift, "-d 168.63.129.16", "SNAT --to 10.0.1.20"))
+	require.Empty(t, iptc.cmds, "no rule is run on its own")
+}
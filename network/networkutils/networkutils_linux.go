@@ -41,9 +41,7 @@ const (
 var logger = log.CNILogger.With(zap.String("component", "net-utils"))
 
 type ipTablesClient interface {
-	InsertIptableRule(version, tableName, chainName, match, target string) error
-	AppendIptableRule(version, tableName, chainName, match, target string) error
-	DeleteIptableRule(version, tableName, chainName, match, target string) error
+	Begin(version string) *iptables.Transaction
 }
 
 var errorNetworkUtils = errors.New("NetworkUtils Error")
@@ -132,8 +130,7 @@ func (nu NetworkUtils) AssignIPToInterface(interfaceName string, ipAddresses []n
 	return nil
 }
 
-func (nu NetworkUtils) addOrDeleteFilterRule(iptablesClient ipTablesClient, bridgeName, action, ipAddress, chainName, target string) error {
-	var err error
+func (nu NetworkUtils) addOrDeleteFilterRule(tx *iptables.Transaction, bridgeName, action, ipAddress, chainName, target string) {
 	option := "i"
 
 	if chainName == iptables.Output {
@@ -144,14 +141,12 @@ func (nu NetworkUtils) addOrDeleteFilterRule(iptablesClient ipTablesClient, brid
 
 	switch action {
 	case iptables.Insert:
-		err = iptablesClient.InsertIptableRule(iptables.V4, iptables.Filter, chainName, matchCondition, target)
+		tx.Insert(iptables.Filter, chainName, matchCondition, target)
 	case iptables.Append:
-		err = iptablesClient.AppendIptableRule(iptables.V4, iptables.Filter, chainName, matchCondition, target)
+		tx.Add(iptables.Filter, chainName, matchCondition, target)
 	case iptables.Delete:
-		err = iptablesClient.DeleteIptableRule(iptables.V4, iptables.Filter, chainName, matchCondition, target)
+		tx.Delete(iptables.Filter, chainName, matchCondition, target)
 	}
-
-	return err
 }
 
 func (nu NetworkUtils) AllowIPAddresses(iptablesClient ipTablesClient, bridgeName string, skipAddresses []string, action string) error {
@@ -160,28 +155,22 @@ func (nu NetworkUtils) AllowIPAddresses(iptablesClient ipTablesClient, bridgeNam
 
 	logger.Info("Addresses to allow", zap.Any("skipAddresses", skipAddresses))
 
+	tx := iptablesClient.Begin(iptables.V4)
 	for _, address := range skipAddresses {
-		if err := nu.addOrDeleteFilterRule(iptablesClient, bridgeName, action, address, chains[0], target[0]); err != nil {
-			return err
-		}
-
-		if err := nu.addOrDeleteFilterRule(iptablesClient, bridgeName, action, address, chains[1], target[0]); err != nil {
-			return err
-		}
-
-		if err := nu.addOrDeleteFilterRule(iptablesClient, bridgeName, action, address, chains[2], target[0]); err != nil {
-			return err
-		}
-
+		nu.addOrDeleteFilterRule(tx, bridgeName, action, address, chains[0], target[0])
+		nu.addOrDeleteFilterRule(tx, bridgeName, action, address, chains[1], target[0])
+		nu.addOrDeleteFilterRule(tx, bridgeName, action, address, chains[2], target[0])
 	}
 
-	return nil
+	return errors.Wrap(tx.Commit(), "failed to allow ip addresses")
 }
 
 func (nu NetworkUtils) BlockEgressTrafficFromContainer(iptablesClient ipTablesClient, version, ipAddress, protocol string, port int) error {
 	// iptables -t filter -I FORWARD -j DROP -d <ip> -p <protocol> -m <protocol> --dport <port>
 	dropTraffic := fmt.Sprintf("-d %s -p %s -m %s --dport %d", ipAddress, protocol, protocol, port)
-	return errors.Wrap(iptablesClient.InsertIptableRule(version, iptables.Filter, iptables.Forward, dropTraffic, iptables.Drop), "iptables block traffic failed")
+	tx := iptablesClient.Begin(version)
+	tx.Insert(iptables.Filter, iptables.Forward, dropTraffic, iptables.Drop)
+	return errors.Wrap(tx.Commit(), "iptables block traffic failed")
 }
 
 func (nu NetworkUtils) BlockIPAddresses(iptablesClient ipTablesClient, bridgeName, action string) error {
@@ -191,21 +180,14 @@ func (nu NetworkUtils) BlockIPAddresses(iptablesClient ipTablesClient, bridgeNam
 
 	logger.Info("Addresses to block", zap.Any("privateIPAddresses", privateIPAddresses))
 
+	tx := iptablesClient.Begin(iptables.V4)
 	for _, ipAddress := range privateIPAddresses {
-		if err := nu.addOrDeleteFilterRule(iptablesClient, bridgeName, action, ipAddress, chains[0], target[1]); err != nil {
-			return err
-		}
-
-		if err := nu.addOrDeleteFilterRule(iptablesClient, bridgeName, action, ipAddress, chains[1], target[1]); err != nil {
-			return err
-		}
-
-		if err := nu.addOrDeleteFilterRule(iptablesClient, bridgeName, action, ipAddress, chains[2], target[1]); err != nil {
-			return err
-		}
+		nu.addOrDeleteFilterRule(tx, bridgeName, action, ipAddress, chains[0], target[1])
+		nu.addOrDeleteFilterRule(tx, bridgeName, action, ipAddress, chains[1], target[1])
+		nu.addOrDeleteFilterRule(tx, bridgeName, action, ipAddress, chains[2], target[1])
 	}
 
-	return nil
+	return errors.Wrap(tx.Commit(), "failed to block ip addresses")
 }
 
 func (nu NetworkUtils) EnableIPV4Forwarding() error {
@@ -249,7 +231,9 @@ func (nu NetworkUtils) AddSnatRule(iptablesClient ipTablesClient, match string,
 	}
 
 	target := fmt.Sprintf("SNAT --to %s", ip.String())
-	return errors.Wrap(iptablesClient.InsertIptableRule(version, iptables.Nat, iptables.Postrouting, match, target), "failed to add snat rule")
+	tx := iptablesClient.Begin(version)
+	tx.Insert(iptables.Nat, iptables.Postrouting, match, target)
+	return errors.Wrap(tx.Commit(), "failed to add snat rule")
 }
 
 func (nu NetworkUtils) DisableRAForInterface(ifName string) error {
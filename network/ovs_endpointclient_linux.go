@@ -153,6 +153,10 @@ func (client *OVSEndpointClient) AddEndpointRules(epInfo *EndpointInfo) error {
 		return err
 	}
 
+	if err := setBandwidthLimits(client.netlink, client.hostVethName, epInfo.Bandwidth); err != nil {
+		return err
+	}
+
 	return client.AddSnatEndpointRules()
 }
 
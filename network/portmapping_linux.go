@@ -0,0 +1,260 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// hostPortChain is the shared nat chain that dispatches locally destined traffic to the per endpoint chains.
+	hostPortChain = "AZURECNIHOSTPORT"
+	// hostPortEndpointChainPrefix prefixes the per endpoint nat chain holding the port mapping rules.
+	hostPortEndpointChainPrefix = "AZCNIHP-"
+	// hostPortChainHashLength keeps the per endpoint chain name within the iptables limit of 28 characters.
+	hostPortChainHashLength = 16
+	// hostPortMasqMark marks hairpin connections so they are masqueraded on the way back to the pod. The bit is not
+	// used by NPM (0x200 to 0x3000 and the upper 16 bits), kube-proxy (0x4000 and 0x8000) or the transparent vlan
+	// tunneling mark.
+	hostPortMasqMark = "0x80/0x80"
+	// hostPortLocalMatch selects traffic destined to an address owned by the host.
+	hostPortLocalMatch = "-m addrtype --dst-type LOCAL"
+)
+
+var errInvalidPortMapping = errors.New("invalid port mapping")
+
+// hostPortChainName returns the per endpoint chain for the endpoint's port mappings.
+func hostPortChainName(endpointID string) string {
+	hash := sha256.Sum256([]byte(endpointID))
+	return hostPortEndpointChainPrefix + strings.ToUpper(hex.EncodeToString(hash[:]))[:hostPortChainHashLength]
+}
+
+// newHostPortRules builds the rules that DNAT the mapped host ports to the endpoint and mark hairpin traffic
+// for masquerade. ingressMatch selects which traffic is dispatched to the endpoint chain, e.g. traffic for a local
+// address in the host namespace. The rules are returned in the order they must be programmed.
+func newHostPortRules(endpointID, ingressMatch string, ipAddresses []net.IPNet, portMappings []PortMapping) ([]HostPortRule, error) {
+	var rules []HostPortRule
+
+	chain := hostPortChainName(endpointID)
+	for _, version := range []string{iptables.V4, iptables.V6} {
+		var chainRules []HostPortRule
+
+		for _, ipAddr := range ipAddresses {
+			if ipVersion(ipAddr.IP) != version {
+				continue
+			}
+
+			for _, mapping := range portMappings {
+				if mapping.HostPort <= 0 || mapping.ContainerPort <= 0 {
+					return nil, errors.Wrapf(errInvalidPortMapping, "%+v", mapping)
+				}
+
+				protocol := strings.ToLower(mapping.Protocol)
+				if protocol == "" {
+					protocol = iptables.TCP
+				}
+
+				match := fmt.Sprintf("-p %s --dport %d", protocol, mapping.HostPort)
+				if mapping.HostIP != "" {
+					hostIP := net.ParseIP(mapping.HostIP)
+					if hostIP == nil {
+						return nil, errors.Wrapf(errInvalidPortMapping, "host ip %s", mapping.HostIP)
+					}
+					if hostIP.IsUnspecified() {
+						hostIP = nil
+					} else if ipVersion(hostIP) != version {
+						continue
+					}
+					if hostIP != nil {
+						match = fmt.Sprintf("%s -d %s", match, hostIP.String())
+					}
+				}
+
+				destination := net.JoinHostPort(ipAddr.IP.String(), fmt.Sprint(mapping.ContainerPort))
+				chainRules = append(chainRules,
+					HostPortRule{
+						Version: version,
+						Table:   iptables.Nat,
+						Chain:   chain,
+						Match:   fmt.Sprintf("%s -s %s", match, ipAddr.IP.String()),
+						Target:  "MARK --set-xmark " + hostPortMasqMark,
+					},
+					HostPortRule{
+						Version: version,
+						Table:   iptables.Nat,
+						Chain:   chain,
+						Match:   match,
+						Target:  "DNAT --to-destination " + destination,
+					})
+			}
+		}
+
+		if len(chainRules) == 0 {
+			continue
+		}
+
+		rules = append(rules, chainRules...)
+		rules = append(rules, HostPortRule{
+			Version: version,
+			Table:   iptables.Nat,
+			Chain:   hostPortChain,
+			Match:   fmt.Sprintf("%s -m comment --comment %s", ingressMatch, endpointID),
+			Target:  chain,
+		})
+	}
+
+	return rules, nil
+}
+
+// addHostPortRules programs the rules for the endpoint's port mappings and returns them so they can be
+// recorded in the endpoint state. The rules of each ip version are committed as one transaction, on failure the
+// rules committed so far are removed.
+func addHostPortRules(iptc ipTablesClient, endpointID, ingressMatch string, ipAddresses []net.IPNet, portMappings []PortMapping) ([]HostPortRule, error) {
+	if len(portMappings) == 0 {
+		return nil, nil
+	}
+
+	rules, err := newHostPortRules(endpointID, ingressMatch, ipAddresses, portMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Adding host port rules", zap.String("endpointID", endpointID), zap.Any("portMappings", portMappings))
+
+	// the rules are ordered by version, each version is committed before the next one is started.
+	var committed int
+	for start := 0; start < len(rules); {
+		version := rules[start].Version
+		tx := iptc.Begin(version)
+		end := start
+		for ; end < len(rules) && rules[end].Version == version; end++ {
+			rule := rules[end]
+			ensureHostPortChains(tx, rule.Chain)
+			tx.Add(rule.Table, rule.Chain, rule.Match, rule.Target)
+		}
+
+		if err := tx.Commit(); err != nil {
+			deleteHostPortRules(iptc, rules[:committed])
+			return nil, errors.Wrapf(err, "failed to add ipv%s host port rules", version)
+		}
+		committed, start = end, end
+	}
+
+	return rules, nil
+}
+
+// ensureHostPortChains adds the shared host port chains, their jump rules and the hairpin masquerade rule to the
+// transaction, followed by the per endpoint chain when chain is one.
+func ensureHostPortChains(tx *iptables.Transaction, chain string) {
+	tx.CreateChain(iptables.Nat, hostPortChain)
+	for _, parent := range []string{iptables.Prerouting, iptables.Output} {
+		tx.Add(iptables.Nat, parent, "", hostPortChain)
+	}
+	tx.Add(iptables.Nat, iptables.Postrouting, "-m mark --mark "+hostPortMasqMark, iptables.Masquerade)
+
+	if chain != hostPortChain {
+		tx.CreateChain(iptables.Nat, chain)
+	}
+}
+
+// deleteHostPortRules removes recorded host port rules and the per endpoint chains that held them, with one
+// transaction per ip version. Rules that are already gone, e.g. after a reboot, are skipped.
+func deleteHostPortRules(iptc ipTablesClient, rules []HostPortRule) {
+	var versions []string
+	txs := map[string]*iptables.Transaction{}
+	chains := map[string][]string{}
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+		tx, ok := txs[rule.Version]
+		if !ok {
+			tx = iptc.Begin(rule.Version)
+			txs[rule.Version] = tx
+			versions = append(versions, rule.Version)
+		}
+		tx.Delete(rule.Table, rule.Chain, rule.Match, rule.Target)
+
+		if strings.HasPrefix(rule.Chain, hostPortEndpointChainPrefix) {
+			found := false
+			for _, c := range chains[rule.Version] {
+				found = found || c == rule.Chain
+			}
+			if !found {
+				chains[rule.Version] = append(chains[rule.Version], rule.Chain)
+			}
+		}
+	}
+
+	for _, version := range versions {
+		tx := txs[version]
+		for _, chain := range chains[version] {
+			tx.DeleteChain(iptables.Nat, chain)
+		}
+		if err := tx.Commit(); err != nil {
+			logger.Info("Failed to delete host port rules", zap.String("version", version), zap.Error(err))
+		}
+	}
+}
+
+// deleteHostPortRulesOfEndpoint removes the host port rules of an endpoint whose rules were not recorded, e.g. in
+// stateless CNI where the endpoint state is kept by CNS. The jump to the endpoint chain is found by the endpoint id
+// in its comment, and the endpoint chain by its name.
+func deleteHostPortRulesOfEndpoint(iptc ipTablesClient, endpointID string) {
+	chain := hostPortChainName(endpointID)
+	for _, version := range []string{iptables.V4, iptables.V6} {
+		tx := iptc.Begin(version)
+		tx.DeleteCommented(iptables.Nat, hostPortChain, endpointID)
+		tx.DeleteChain(iptables.Nat, chain)
+		if err := tx.Commit(); err != nil {
+			logger.Info("Failed to delete host port rules of endpoint", zap.String("endpointID", endpointID),
+				zap.String("version", version), zap.Error(err))
+		}
+	}
+}
+
+// deleteStatelessEndpointHostPortRules removes the host port rules of an endpoint deleted in stateless CNI, if CNS
+// recorded that it has some. The endpoints of a container share the endpoint id, so the rules are only looked up
+// for the infra NIC.
+func (nm *networkManager) deleteStatelessEndpointHostPortRules(epInfo *EndpointInfo) {
+	if epInfo.NICType != cns.InfraNIC || !epInfo.HasHostPortRules {
+		return
+	}
+	deleteHostPortRulesOfEndpoint(nm.iptablesClient, epInfo.EndpointID)
+}
+
+// deleteEndpointHostPortRules removes the host port rules recorded for the endpoints of the network.
+// It is called on restore after a reboot since endpoints left behind are not deleted through the clients.
+func (nm *networkManager) deleteEndpointHostPortRules(nw *network) {
+	for _, ep := range nw.Endpoints {
+		if len(ep.HostPortRules) == 0 {
+			continue
+		}
+
+		logger.Info("Deleting host port rules of endpoint", zap.String("endpointID", ep.Id))
+		if ep.VlanID != 0 && nw.Mode == opModeTransparentVlan {
+			vnetNSName := fmt.Sprintf("az_ns_%d", ep.VlanID)
+			//nolint:errcheck // best effort, the namespace does not survive a reboot
+			ExecuteInNS(nm.nsClient, vnetNSName, func() error {
+				deleteHostPortRules(nm.iptablesClient, ep.HostPortRules)
+				return nil
+			})
+		} else {
+			deleteHostPortRules(nm.iptablesClient, ep.HostPortRules)
+		}
+		ep.HostPortRules = nil
+	}
+}
+
+func ipVersion(ip net.IP) string {
+	if ip.To4() != nil {
+		return iptables.V4
+	}
+	return iptables.V6
+}
@@ -0,0 +1,320 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cns"
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/stretchr/testify/require"
+)
+
+var errFakeIptables = errors.New("fake iptables error")
+
+// fakeIPTablesClient keeps the rules in memory so the programmed state can be inspected.
+type fakeIPTablesClient struct {
+	chains  map[string]bool
+	rules   []string
+	cmds    []string
+	failOn  string
+	deleted []string
+	commits int
+}
+
+func newFakeIPTablesClient() *fakeIPTablesClient {
+	return &fakeIPTablesClient{chains: map[string]bool{}}
+}
+
+func fakeRule(version, table, chain, match, target string) string {
+	return fmt.Sprintf("%s %s %s %s -j %s", version, table, chain, match, target)
+}
+
+func (c *fakeIPTablesClient) InsertIptableRule(version, table, chain, match, target string) error {
+	return c.AppendIptableRule(version, table, chain, match, target)
+}
+
+func (c *fakeIPTablesClient) AppendIptableRule(version, table, chain, match, target string) error {
+	if c.failOn != "" && strings.Contains(target, c.failOn) {
+		return errFakeIptables
+	}
+	if !c.RuleExists(version, table, chain, match, target) {
+		c.rules = append(c.rules, fakeRule(version, table, chain, match, target))
+	}
+	return nil
+}
+
+func (c *fakeIPTablesClient) DeleteIptableRule(version, table, chain, match, target string) error {
+	rule := fakeRule(version, table, chain, match, target)
+	for i := range c.rules {
+		if c.rules[i] == rule {
+			c.rules = append(c.rules[:i], c.rules[i+1:]...)
+			c.deleted = append(c.deleted, rule)
+			return nil
+		}
+	}
+	return errFakeIptables
+}
+
+func (c *fakeIPTablesClient) CreateChain(version, table, chain string) error {
+	c.chains[version+" "+table+" "+chain] = true
+	return nil
+}
+
+func (c *fakeIPTablesClient) RuleExists(version, table, chain, match, target string) bool {
+	rule := fakeRule(version, table, chain, match, target)
+	for _, r := range c.rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fakeIPTablesClient) RunCmd(version, params string) error {
+	c.cmds = append(c.cmds, version+" "+params)
+	return nil
+}
+
+func (c *fakeIPTablesClient) Begin(version string) *iptables.Transaction {
+	return iptables.NewTransaction(version, c.commit)
+}
+
+// commit applies all changes or, if one of them fails, none of them.
+func (c *fakeIPTablesClient) commit(version string, changes []iptables.RuleChange) error {
+	c.commits++
+	for _, change := range changes {
+		if c.failOn != "" && strings.Contains(change.Target, c.failOn) {
+			return errFakeIptables
+		}
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case iptables.NewChain:
+			_ = c.CreateChain(version, change.Table, change.Chain)
+		case iptables.DeleteChain:
+			delete(c.chains, version+" "+change.Table+" "+change.Chain)
+		case iptables.Append, iptables.Insert:
+			_ = c.AppendIptableRule(version, change.Table, change.Chain, change.Match, change.Target)
+		case iptables.Delete:
+			if c.RuleExists(version, change.Table, change.Chain, change.Match, change.Target) {
+				_ = c.DeleteIptableRule(version, change.Table, change.Chain, change.Match, change.Target)
+			}
+		case iptables.DeleteCommented:
+			prefix := version + " " + change.Table + " " + change.Chain + " "
+			var kept []string
+			for _, rule := range c.rules {
+				if strings.HasPrefix(rule, prefix) && strings.Contains(rule, "--comment "+change.Match+" -j ") {
+					c.deleted = append(c.deleted, rule)
+					continue
+				}
+				kept = append(kept, rule)
+			}
+			c.rules = kept
+		}
+	}
+	return nil
+}
+
+func TestNewHostPortRules(t *testing.T) {
+	ipv4 := net.IPNet{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)}
+	ipv6 := net.IPNet{IP: net.ParseIP("fd00::4"), Mask: net.CIDRMask(64, 128)}
+	chain := hostPortChainName("ep1")
+
+	tests := []struct {
+		name         string
+		ipAddresses  []net.IPNet
+		portMappings []PortMapping
+		wantRules    []HostPortRule
+		wantErr      bool
+	}{
+		{
+			name:         "ipv4 mapping without host ip",
+			ipAddresses:  []net.IPNet{ipv4},
+			portMappings: []PortMapping{{HostPort: 8080, ContainerPort: 80, Protocol: "TCP"}},
+			wantRules: []HostPortRule{
+				{Version: iptables.V4, Table: iptables.Nat, Chain: chain, Match: "-p tcp --dport 8080 -s 10.0.0.4", Target: "MARK --set-xmark 0x80/0x80"},
+				{Version: iptables.V4, Table: iptables.Nat, Chain: chain, Match: "-p tcp --dport 8080", Target: "DNAT --to-destination 10.0.0.4:80"},
+				{Version: iptables.V4, Table: iptables.Nat, Chain: hostPortChain, Match: hostPortLocalMatch + " -m comment --comment ep1", Target: chain},
+			},
+		},
+		{
+			name:         "dual stack mapping with ipv6 host ip",
+			ipAddresses:  []net.IPNet{ipv4, ipv6},
+			portMappings: []PortMapping{{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "fd00::1"}},
+			wantRules: []HostPortRule{
+				{Version: iptables.V6, Table: iptables.Nat, Chain: chain, Match: "-p udp --dport 5353 -d fd00::1 -s fd00::4", Target: "MARK --set-xmark 0x80/0x80"},
+				{Version: iptables.V6, Table: iptables.Nat, Chain: chain, Match: "-p udp --dport 5353 -d fd00::1", Target: "DNAT --to-destination [fd00::4]:53"},
+				{Version: iptables.V6, Table: iptables.Nat, Chain: hostPortChain, Match: hostPortLocalMatch + " -m comment --comment ep1", Target: chain},
+			},
+		},
+		{
+			name:         "unspecified host ip matches any address",
+			ipAddresses:  []net.IPNet{ipv4},
+			portMappings: []PortMapping{{HostPort: 8080, ContainerPort: 80, HostIP: "0.0.0.0"}},
+			wantRules: []HostPortRule{
+				{Version: iptables.V4, Table: iptables.Nat, Chain: chain, Match: "-p tcp --dport 8080 -s 10.0.0.4", Target: "MARK --set-xmark 0x80/0x80"},
+				{Version: iptables.V4, Table: iptables.Nat, Chain: chain, Match: "-p tcp --dport 8080", Target: "DNAT --to-destination 10.0.0.4:80"},
+				{Version: iptables.V4, Table: iptables.Nat, Chain: hostPortChain, Match: hostPortLocalMatch + " -m comment --comment ep1", Target: chain},
+			},
+		},
+		{
+			name:         "invalid host port",
+			ipAddresses:  []net.IPNet{ipv4},
+			portMappings: []PortMapping{{HostPort: 0, ContainerPort: 80}},
+			wantErr:      true,
+		},
+		{
+			name:         "invalid host ip",
+			ipAddresses:  []net.IPNet{ipv4},
+			portMappings: []PortMapping{{HostPort: 8080, ContainerPort: 80, HostIP: "not-an-ip"}},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := newHostPortRules("ep1", hostPortLocalMatch, tt.ipAddresses, tt.portMappings)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errInvalidPortMapping)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRules, rules)
+		})
+	}
+}
+
+func TestHostPortChainName(t *testing.T) {
+	name := hostPortChainName("0123456789abcdef-eth0")
+	require.True(t, strings.HasPrefix(name, hostPortEndpointChainPrefix))
+	require.LessOrEqual(t, len(name), 28)
+	require.NotEqual(t, name, hostPortChainName("0123456789abcdef-eth1"))
+}
+
+func TestAddDeleteHostPortRules(t *testing.T) {
+	iptc := newFakeIPTablesClient()
+	ipAddresses := []net.IPNet{{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)}}
+	portMappings := []PortMapping{{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}}
+
+	rules, err := addHostPortRules(iptc, "ep1", hostPortLocalMatch, ipAddresses, portMappings)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	// shared jumps and the hairpin masquerade rule are programmed once
+	require.True(t, iptc.RuleExists(iptables.V4, iptables.Nat, iptables.Prerouting, "", hostPortChain))
+	require.True(t, iptc.RuleExists(iptables.V4, iptables.Nat, iptables.Output, "", hostPortChain))
+	require.True(t, iptc.RuleExists(iptables.V4, iptables.Nat, iptables.Postrouting, "-m mark --mark "+hostPortMasqMark, iptables.Masquerade))
+	require.True(t, iptc.chains[iptables.V4+" "+iptables.Nat+" "+hostPortChainName("ep1")])
+	for _, rule := range rules {
+		require.True(t, iptc.RuleExists(rule.Version, rule.Table, rule.Chain, rule.Match, rule.Target))
+	}
+
+	deleteHostPortRules(iptc, rules)
+	for _, rule := range rules {
+		require.False(t, iptc.RuleExists(rule.Version, rule.Table, rule.Chain, rule.Match, rule.Target))
+	}
+	require.False(t, iptc.chains[iptables.V4+" "+iptables.Nat+" "+hostPortChainName("ep1")])
+	require.True(t, iptc.chains[iptables.V4+" "+iptables.Nat+" "+hostPortChain])
+
+	// the shared rules stay for other endpoints
+	require.True(t, iptc.RuleExists(iptables.V4, iptables.Nat, iptables.Prerouting, "", hostPortChain))
+
+	// deleting again, e.g. on DEL after a reboot, is a no-op
+	deleteHostPortRules(iptc, rules)
+}
+
+func TestAddHostPortRulesRollback(t *testing.T) {
+	iptc := newFakeIPTablesClient()
+	iptc.failOn = "DNAT"
+	ipAddresses := []net.IPNet{{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)}}
+	portMappings := []PortMapping{{HostPort: 8080, ContainerPort: 80}}
+
+	rules, err := addHostPortRules(iptc, "ep1", hostPortLocalMatch, ipAddresses, portMappings)
+	require.ErrorIs(t, err, errFakeIptables)
+	require.Nil(t, rules)
+
+	// the transaction is not applied at all
+	require.Empty(t, iptc.rules)
+	require.Empty(t, iptc.chains)
+}
+
+func TestAddHostPortRulesDualStackRollback(t *testing.T) {
+	iptc := newFakeIPTablesClient()
+	iptc.failOn = "[fd00::4]"
+	ipAddresses := []net.IPNet{
+		{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)},
+		{IP: net.ParseIP("fd00::4"), Mask: net.CIDRMask(64, 128)},
+	}
+	portMappings := []PortMapping{{HostPort: 8080, ContainerPort: 80}}
+
+	rules, err := addHostPortRules(iptc, "ep1", hostPortLocalMatch, ipAddresses, portMappings)
+	require.ErrorIs(t, err, errFakeIptables)
+	require.Nil(t, rules)
+
+	// the committed ipv4 rules are removed again
+	require.Len(t, iptc.deleted, 3)
+	require.False(t, iptc.chains[iptables.V4+" "+iptables.Nat+" "+hostPortChainName("ep1")])
+}
+
+func TestTransparentEndpointClientHostPortRules(t *testing.T) {
+	iptc := newFakeIPTablesClient()
+	client := &TransparentEndpointClient{iptablesClient: iptc}
+	epInfo := &EndpointInfo{
+		EndpointID:   "ep1",
+		IPAddresses:  []net.IPNet{{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)}},
+		PortMappings: []PortMapping{{HostPort: 8080, ContainerPort: 80}},
+	}
+
+	rules, err := addHostPortRules(client.iptablesClient, epInfo.EndpointID, hostPortLocalMatch, epInfo.IPAddresses, epInfo.PortMappings)
+	require.NoError(t, err)
+
+	ep := &endpoint{Id: "ep1", HostPortRules: rules}
+	require.Equal(t, rules, ep.getInfo().HostPortRules)
+
+	client.DeleteEndpointRules(ep)
+	for _, rule := range rules {
+		require.False(t, iptc.RuleExists(rule.Version, rule.Table, rule.Chain, rule.Match, rule.Target))
+	}
+}
+
+func TestDeleteStatelessEndpointHostPortRules(t *testing.T) {
+	iptc := newFakeIPTablesClient()
+	nm := &networkManager{iptablesClient: iptc}
+	ipAddresses := []net.IPNet{
+		{IP: net.ParseIP("10.0.0.4").To4(), Mask: net.CIDRMask(24, 32)},
+		{IP: net.ParseIP("fd00::4"), Mask: net.CIDRMask(64, 128)},
+	}
+	portMappings := []PortMapping{{HostPort: 8080, ContainerPort: 80}}
+
+	// the rules of both endpoints are programmed, but they are not recorded in the endpoint state
+	_, err := addHostPortRules(iptc, "container1", hostPortLocalMatch, ipAddresses, portMappings)
+	require.NoError(t, err)
+	otherRules, err := addHostPortRules(iptc, "container2", hostPortLocalMatch, ipAddresses[:1], portMappings)
+	require.NoError(t, err)
+
+	// only the infra NIC looks up the rules, and only if CNS recorded that it has some
+	commits := iptc.commits
+	nm.deleteStatelessEndpointHostPortRules(&EndpointInfo{EndpointID: "container1", NICType: cns.DelegatedVMNIC, HasHostPortRules: true})
+	nm.deleteStatelessEndpointHostPortRules(&EndpointInfo{EndpointID: "container1", NICType: cns.InfraNIC})
+	require.Equal(t, commits, iptc.commits)
+	require.True(t, iptc.chains[iptables.V4+" "+iptables.Nat+" "+hostPortChainName("container1")])
+
+	nm.deleteStatelessEndpointHostPortRules(&EndpointInfo{EndpointID: "container1", NICType: cns.InfraNIC, HasHostPortRules: true})
+	for _, version := range []string{iptables.V4, iptables.V6} {
+		require.False(t, iptc.chains[version+" "+iptables.Nat+" "+hostPortChainName("container1")])
+		require.False(t, iptc.RuleExists(version, iptables.Nat, hostPortChain, hostPortLocalMatch+" -m comment --comment container1", hostPortChainName("container1")))
+	}
+
+	// the rules of other endpoints are kept
+	for _, rule := range otherRules {
+		require.True(t, iptc.RuleExists(rule.Version, rule.Table, rule.Chain, rule.Match, rule.Target))
+	}
+}
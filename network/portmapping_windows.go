@@ -0,0 +1,8 @@
+package network
+
+// deleteEndpointHostPortRules is a no-op on windows where port mappings are HNS endpoint policies
+// and are removed along with the HNS endpoint.
+func (nm *networkManager) deleteEndpointHostPortRules(_ *network) {}
+
+// deleteStatelessEndpointHostPortRules is a no-op on windows, see deleteEndpointHostPortRules.
+func (nm *networkManager) deleteStatelessEndpointHostPortRules(_ *EndpointInfo) {}
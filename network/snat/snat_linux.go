@@ -36,6 +36,7 @@ type ipTablesClient interface {
 	AppendIptableRule(version, tableName, chainName, match, target string) error
 	DeleteIptableRule(version, tableName, chainName, match, target string) error
 	CreateChain(version, tableName, chainName string) error
+	Begin(version string) *iptables.Transaction
 }
 
 var errorSnatClient = errors.New("SnatClient Error")
@@ -183,43 +184,27 @@ func getNCLocalAndGatewayIP(client *Client) (brIP, contIP net.IP) {
 func (client *Client) AllowInboundFromHostToNC() error {
 	bridgeIP, containerIP := getNCLocalAndGatewayIP(client)
 
-	// Create CNI Output chain
-	if err := client.ipTablesClient.CreateChain(iptables.V4, iptables.Filter, iptables.CNIOutputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Creating failed with", zap.Any("CNIOutputChain", iptables.CNIOutputChain), zap.Error(err))
-		return newErrorSnatClient(err.Error())
-	}
+	// The chains and rules are committed in one transaction
+	tx := client.ipTablesClient.Begin(iptables.V4)
 
-	// Forward traffic from Ouptut chain to CNI Output chain
-	if err := client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.Output, "", iptables.CNIOutputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Creating failed with", zap.Any("CNIOutputChain", iptables.CNIOutputChain), zap.Error(err))
-		return newErrorSnatClient(err.Error())
-	}
+	// Create CNI Output chain and forward traffic from Ouptut chain to it
+	tx.CreateChain(iptables.Filter, iptables.CNIOutputChain)
+	tx.Insert(iptables.Filter, iptables.Output, "", iptables.CNIOutputChain)
 
 	// Allow connection from Host to NC
 	matchCondition := fmt.Sprintf("-s %s -d %s", bridgeIP.String(), containerIP.String())
-	err := client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.CNIOutputChain, matchCondition, iptables.Accept)
-	if err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting output rule failed with ", zap.Error(err))
-		return newErrorSnatClient(err.Error())
-	}
+	tx.Insert(iptables.Filter, iptables.CNIOutputChain, matchCondition, iptables.Accept)
 
-	// Create cniinput chain
-	if err = client.ipTablesClient.CreateChain(iptables.V4, iptables.Filter, iptables.CNIInputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Creating failed with", zap.Any("CNIOutputChain", iptables.CNIOutputChain), zap.Error(err))
-		return newErrorSnatClient(err.Error())
-	}
-
-	// Forward from Input to cniinput chain
-	if err = client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.Input, "", iptables.CNIInputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting forward rule to failed with", zap.Any("CNIOutputChain", iptables.CNIOutputChain), zap.Error(err))
-		return newErrorSnatClient(err.Error())
-	}
+	// Create cniinput chain and forward from Input to it
+	tx.CreateChain(iptables.Filter, iptables.CNIInputChain)
+	tx.Insert(iptables.Filter, iptables.Input, "", iptables.CNIInputChain)
 
 	// Accept packets from NC only if established connection
 	matchCondition = fmt.Sprintf(" -i %s -m state --state %s,%s", SnatBridgeName, iptables.Established, iptables.Related)
-	err = client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.CNIInputChain, matchCondition, iptables.Accept)
-	if err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting input rule failed with", zap.Error(err))
+	tx.Insert(iptables.Filter, iptables.CNIInputChain, matchCondition, iptables.Accept)
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("AllowInboundFromHostToNC: Programming iptables rules failed with", zap.Error(err))
 		return newErrorSnatClient(err.Error())
 	}
 
@@ -234,7 +219,7 @@ func (client *Client) AllowInboundFromHostToNC() error {
 		MacAddress: snatContainerVeth.HardwareAddr,
 	}
 
-	err = client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.ADD, netlink.NUD_PERMANENT)
+	err := client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.ADD, netlink.NUD_PERMANENT)
 	if err != nil {
 		logger.Error("AllowInboundFromHostToNC: Error adding static arp entry for ip", zap.Any("containerIP", containerIP),
 			zap.String("HardwareAddr", snatContainerVeth.HardwareAddr.String()), zap.Error(err))
@@ -249,8 +234,9 @@ func (client *Client) DeleteInboundFromHostToNC() error {
 
 	// Delete allow connection from Host to NC
 	matchCondition := fmt.Sprintf("-s %s -d %s", bridgeIP.String(), containerIP.String())
-	err := client.ipTablesClient.DeleteIptableRule(iptables.V4, iptables.Filter, iptables.CNIOutputChain, matchCondition, iptables.Accept)
-	if err != nil {
+	tx := client.ipTablesClient.Begin(iptables.V4)
+	tx.Delete(iptables.Filter, iptables.CNIOutputChain, matchCondition, iptables.Accept)
+	if err := tx.Commit(); err != nil {
 		logger.Error("DeleteInboundFromHostToNC: Error removing output rule", zap.Error(err))
 	}
 
@@ -262,7 +248,7 @@ func (client *Client) DeleteInboundFromHostToNC() error {
 		MacAddress: nil,
 	}
 
-	err = client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.REMOVE, netlink.NUD_INCOMPLETE)
+	err := client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.REMOVE, netlink.NUD_INCOMPLETE)
 	if err != nil {
 		logger.Error("AllowInboundFromHostToNC: Error removing static arp entry for ip", zap.Any("containerIP", containerIP),
 			zap.Error(err))
@@ -275,47 +261,27 @@ func (client *Client) DeleteInboundFromHostToNC() error {
 func (client *Client) AllowInboundFromNCToHost() error {
 	bridgeIP, containerIP := getNCLocalAndGatewayIP(client)
 
-	// Create CNI Input chain
-	if err := client.ipTablesClient.CreateChain(iptables.V4, iptables.Filter, iptables.CNIInputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Creating failed with", zap.String("CNIInputChain", iptables.CNIInputChain),
-			zap.Error(err))
-		return err
-	}
+	// The chains and rules are committed in one transaction
+	tx := client.ipTablesClient.Begin(iptables.V4)
 
-	// Forward traffic from Input to cniinput chain
-	if err := client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.Input, "", iptables.CNIInputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting forward rule to failed with", zap.String("CNIInputChain", iptables.CNIInputChain),
-			zap.Error(err))
-		return err
-	}
+	// Create CNI Input chain and forward traffic from Input to it
+	tx.CreateChain(iptables.Filter, iptables.CNIInputChain)
+	tx.Insert(iptables.Filter, iptables.Input, "", iptables.CNIInputChain)
 
 	// Allow NC to Host connection
 	matchCondition := fmt.Sprintf("-s %s -d %s", containerIP.String(), bridgeIP.String())
-	err := client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.CNIInputChain, matchCondition, iptables.Accept)
-	if err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting output rule failed with", zap.Error(err))
-		return err
-	}
+	tx.Insert(iptables.Filter, iptables.CNIInputChain, matchCondition, iptables.Accept)
 
-	// Create CNI output chain
-	if err = client.ipTablesClient.CreateChain(iptables.V4, iptables.Filter, iptables.CNIOutputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Creating failed with", zap.String("CNIInputChain", iptables.CNIInputChain),
-			zap.Error(err))
-		return err
-	}
-
-	// Forward traffic from Output to CNI Output chain
-	if err = client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.Output, "", iptables.CNIOutputChain); err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting forward rule to failed with", zap.String("CNIInputChain", iptables.CNIInputChain),
-			zap.Error(err))
-		return err
-	}
+	// Create CNI output chain and forward traffic from Output to it
+	tx.CreateChain(iptables.Filter, iptables.CNIOutputChain)
+	tx.Insert(iptables.Filter, iptables.Output, "", iptables.CNIOutputChain)
 
 	// Accept packets from Host only if established connection
 	matchCondition = fmt.Sprintf(" -o %s -m state --state %s,%s", SnatBridgeName, iptables.Established, iptables.Related)
-	err = client.ipTablesClient.InsertIptableRule(iptables.V4, iptables.Filter, iptables.CNIOutputChain, matchCondition, iptables.Accept)
-	if err != nil {
-		logger.Error("AllowInboundFromHostToNC: Inserting input rule failed with", zap.Error(err))
+	tx.Insert(iptables.Filter, iptables.CNIOutputChain, matchCondition, iptables.Accept)
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("AllowInboundFromNCToHost: Programming iptables rules failed with", zap.Error(err))
 		return err
 	}
 
@@ -329,7 +295,7 @@ func (client *Client) AllowInboundFromNCToHost() error {
 		MacAddress: snatContainerVeth.HardwareAddr,
 	}
 
-	err = client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.ADD, netlink.NUD_PERMANENT)
+	err := client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.ADD, netlink.NUD_PERMANENT)
 	if err != nil {
 		logger.Error("AllowInboundFromNCToHost: Error adding static arp entry for ip", zap.Any("containerIP", containerIP),
 			zap.String("HardwareAddr", snatContainerVeth.HardwareAddr.String()), zap.Error(err))
@@ -343,8 +309,9 @@ func (client *Client) DeleteInboundFromNCToHost() error {
 
 	// Delete allow NC to Host connection
 	matchCondition := fmt.Sprintf("-s %s -d %s", containerIP.String(), bridgeIP.String())
-	err := client.ipTablesClient.DeleteIptableRule(iptables.V4, iptables.Filter, iptables.CNIInputChain, matchCondition, iptables.Accept)
-	if err != nil {
+	tx := client.ipTablesClient.Begin(iptables.V4)
+	tx.Delete(iptables.Filter, iptables.CNIInputChain, matchCondition, iptables.Accept)
+	if err := tx.Commit(); err != nil {
 		logger.Error("DeleteInboundFromNCToHost: Error removing output rule", zap.Error(err))
 	}
 
@@ -356,7 +323,7 @@ func (client *Client) DeleteInboundFromNCToHost() error {
 		MacAddress: nil,
 	}
 
-	err = client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.REMOVE, netlink.NUD_INCOMPLETE)
+	err := client.netlink.SetOrRemoveLinkAddress(linkInfo, netlink.REMOVE, netlink.NUD_INCOMPLETE)
 	if err != nil {
 		logger.Error("DeleteInboundFromNCToHost: Error removing static arp entry for ip",
 			zap.Any("containerIP", containerIP), zap.Error(err))
@@ -469,6 +436,16 @@ func (client *Client) addMasqueradeRule(snatBridgeIPWithPrefix string) error {
 
 // Drop all vlan traffic on linux bridge
 func (client *Client) addVlanDropRule() error {
+	if ebtables.EnableNFTables {
+		exists, err := ebtables.EbTableRuleExists(ebtables.Nat, ebtables.PreRouting, vlanDropMatch)
+		if err != nil || exists {
+			return errors.Wrap(err, "failed to list ebtable rules")
+		}
+
+		logger.Info("Adding ebtable rule to drop vlan traffic on snat bridge", zap.String("vlanDropMatch", vlanDropMatch))
+		return errors.Wrap(ebtables.SetEbRule(ebtables.Nat, ebtables.Append, ebtables.PreRouting, vlanDropMatch), "failed to add vlan drop rule")
+	}
+
 	out, err := client.plClient.ExecuteCommand(l2PreroutingEntries)
 	if err != nil {
 		logger.Error("Error while listing ebtable rules")
@@ -44,6 +44,7 @@ type TransparentEndpointClient struct {
 	netioshim         netio.NetIOInterface
 	plClient          platform.ExecClient
 	netUtilsClient    networkutils.NetworkUtils
+	iptablesClient    ipTablesClient
 }
 
 func NewTransparentEndpointClient(
@@ -54,6 +55,7 @@ func NewTransparentEndpointClient(
 	nl netlink.NetlinkInterface,
 	nioc netio.NetIOInterface,
 	plc platform.ExecClient,
+	iptc ipTablesClient,
 ) *TransparentEndpointClient {
 	client := &TransparentEndpointClient{
 		bridgeName:        extIf.BridgeName,
@@ -66,6 +68,7 @@ func NewTransparentEndpointClient(
 		netioshim:         nioc,
 		plClient:          plc,
 		netUtilsClient:    networkutils.NewNetworkUtils(nl, plc),
+		iptablesClient:    iptc,
 	}
 
 	return client
@@ -167,6 +170,16 @@ func (client *TransparentEndpointClient) AddEndpointRules(epInfo *EndpointInfo)
 		return err
 	}
 
+	hostPortRules, err := addHostPortRules(client.iptablesClient, epInfo.EndpointID, hostPortLocalMatch, epInfo.IPAddresses, epInfo.PortMappings)
+	if err != nil {
+		return newErrorTransparentEndpointClient(err)
+	}
+	epInfo.HostPortRules = hostPortRules
+
+	if err := setBandwidthLimits(client.netlink, client.hostVethName, epInfo.Bandwidth); err != nil {
+		return newErrorTransparentEndpointClient(err)
+	}
+
 	return nil
 }
 
@@ -191,6 +204,8 @@ func (client *TransparentEndpointClient) DeleteEndpointRules(ep *endpoint) {
 			logger.Error("Failed to delete route on VM for the", zap.String("ip", ipNet.String()), zap.Error(err))
 		}
 	}
+
+	deleteHostPortRules(client.iptablesClient, ep.HostPortRules)
 }
 
 func (client *TransparentEndpointClient) MoveEndpointsToContainerNS(epInfo *EndpointInfo, nsID uintptr) error {
@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	vishnetlink "github.com/vishvananda/netlink"
 	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -389,22 +390,40 @@ func (client *TransparentVlanEndpointClient) AddEndpointRules(epInfo *EndpointIn
 	}
 	logger.Info("[transparent-vlan] Adding tunneling rules in vnet namespace")
 	err := ExecuteInNS(client.nsClient, client.vnetNSName, func() error {
-		return client.AddVnetRules(epInfo)
+		if err := client.AddVnetRules(epInfo); err != nil {
+			return err
+		}
+		if err := client.AddVnetHostPortRules(epInfo); err != nil {
+			return err
+		}
+		// The vnet veth is the host side of the container veth pair.
+		return setBandwidthLimits(client.netlink, client.vnetVethName, epInfo.Bandwidth)
 	})
 	return err
 }
 
+// Add port mapping rules for traffic that enters the vnet namespace on the vlan interface. Namespace: vnet
+func (client *TransparentVlanEndpointClient) AddVnetHostPortRules(epInfo *EndpointInfo) error {
+	match := fmt.Sprintf("-i %s", client.vlanIfName)
+	hostPortRules, err := addHostPortRules(client.iptablesClient, epInfo.EndpointID, match, epInfo.IPAddresses, epInfo.PortMappings)
+	if err != nil {
+		return errors.Wrap(err, "failed to add host port rules in vnet namespace")
+	}
+	epInfo.HostPortRules = hostPortRules
+	return nil
+}
+
 // Add rules related to tunneling the packet outside of the VM, assumes all calls are idempotent. Namespace: vnet
 func (client *TransparentVlanEndpointClient) AddVnetRules(epInfo *EndpointInfo) error {
+	tx := client.iptablesClient.Begin(iptables.V4)
 	// iptables -t mangle -I PREROUTING -j MARK --set-mark <TUNNELING MARK>
 	markOption := fmt.Sprintf("MARK --set-mark %d", tunnelingMark)
-	if err := client.iptablesClient.InsertIptableRule(iptables.V4, "mangle", "PREROUTING", "", markOption); err != nil {
-		return errors.Wrap(err, "unable to insert iptables rule mark all packets not entering on vlan interface")
-	}
+	tx.Insert(iptables.Mangle, iptables.Prerouting, "", markOption)
 	// iptables -t mangle -I PREROUTING -j ACCEPT -i <VLAN IF>
 	match := fmt.Sprintf("-i %s", client.vlanIfName)
-	if err := client.iptablesClient.InsertIptableRule(iptables.V4, "mangle", "PREROUTING", match, "ACCEPT"); err != nil {
-		return errors.Wrap(err, "unable to insert iptables rule accept all incoming from vlan interface")
+	tx.Insert(iptables.Mangle, iptables.Prerouting, match, iptables.Accept)
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "unable to insert iptables rules to mark all packets not entering on vlan interface")
 	}
 	// Blocks wireserver traffic from customer vnet nic
 	if err := client.netUtilsClient.BlockEgressTrafficFromContainer(client.iptablesClient, iptables.V4, networkutils.AzureDNS, iptables.TCP, iptables.HTTPPort); err != nil {
@@ -412,10 +431,12 @@ func (client *TransparentVlanEndpointClient) AddVnetRules(epInfo *EndpointInfo)
 	}
 
 	// Packets that are marked should go to the tunneling table
-	newRule := vishnetlink.NewRule()
-	newRule.Mark = tunnelingMark
-	newRule.Table = tunnelingTable
-	rules, err := vishnetlink.RuleList(vishnetlink.FAMILY_V4)
+	newRule := &netlink.Rule{
+		Family: unix.AF_INET,
+		Mark:   tunnelingMark,
+		Table:  tunnelingTable,
+	}
+	rules, err := client.netlink.GetIPRules(unix.AF_INET)
 	if err != nil {
 		return errors.Wrap(err, "unable to get existing ip rule list")
 	}
@@ -427,7 +448,7 @@ func (client *TransparentVlanEndpointClient) AddVnetRules(epInfo *EndpointInfo)
 		}
 	}
 	if !ruleExists {
-		if err := vishnetlink.RuleAdd(newRule); err != nil {
+		if err := client.netlink.AddIPRule(newRule); err != nil {
 			return errors.Wrap(err, "failed to add rule that forwards packet with mark to tunneling routing table")
 		}
 	}
@@ -437,6 +458,17 @@ func (client *TransparentVlanEndpointClient) AddVnetRules(epInfo *EndpointInfo)
 
 func (client *TransparentVlanEndpointClient) DeleteEndpointRules(ep *endpoint) {
 	client.DeleteSnatEndpointRules()
+
+	if len(ep.HostPortRules) == 0 {
+		return
+	}
+	err := ExecuteInNS(client.nsClient, client.vnetNSName, func() error {
+		deleteHostPortRules(client.iptablesClient, ep.HostPortRules)
+		return nil
+	})
+	if err != nil {
+		logger.Error("[transparent-vlan] Failed to delete host port rules in vnet namespace", zap.Error(err))
+	}
 }
 
 func (client *TransparentVlanEndpointClient) MoveEndpointsToContainerNS(epInfo *EndpointInfo, nsID uintptr) error {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-container-networking/nmagent/internal"
+	"github.com/Azure/azure-container-networking/tracing"
 	"github.com/pkg/errors"
 )
 
@@ -23,9 +24,9 @@ func NewClient(c Config) (*Client, error) {
 
 	client := &Client{
 		httpClient: &http.Client{
-			Transport: &internal.WireserverTransport{
+			Transport: tracing.NewTransport(&internal.WireserverTransport{
 				Transport: http.DefaultTransport,
-			},
+			}),
 		},
 		host:      c.Host,
 		port:      c.Port,
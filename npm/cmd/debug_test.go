@@ -13,10 +13,12 @@ import (
 const (
 	iptableSaveFile = "../pkg/dataplane/testdata/iptablesave-v1"
 	npmCacheFile    = "../pkg/dataplane/testdata/npmcachev1.json"
+	dropLogFile     = "../pkg/dataplane/testdata/droplogs"
 	nonExistingFile = "non-existing-iptables-file"
 
 	npmCacheFlag         = "-c"
 	iptablesSaveFileFlag = "-i"
+	logFileFlag          = "-l"
 	dstFlag              = "-d"
 	srcFlag              = "-s"
 	unknownShorthandFlag = "-z"
@@ -28,6 +30,7 @@ const (
 	convertIPTableCmdString = "convertiptable"
 	getTuplesCmdString      = "gettuples"
 	parseIPTableCmdString   = "parseiptable"
+	replayDropsCmdString    = "replaydrops"
 )
 
 type testCases struct {
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/debug"
+	"github.com/Azure/azure-container-networking/npm/util/errors"
+	"github.com/spf13/cobra"
+)
+
+func newReplayDropsCmd() *cobra.Command {
+	replayDropsCmd := &cobra.Command{
+		Use:   "replaydrops",
+		Short: "Explain the packets logged as dropped in NPM logs (requires drop logging to be enabled)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logFile, _ := cmd.Flags().GetString("log-file")
+			if logFile == "" {
+				return fmt.Errorf("%w", errors.ErrLogFileNotSpecified)
+			}
+
+			tuples, err := debug.ReplayDropLogsFile(logFile)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			debug.PrettyPrintDropTuples(tuples)
+
+			return nil
+		},
+	}
+
+	replayDropsCmd.Flags().StringP("log-file", "l", "", "Set the NPM log file path")
+	return replayDropsCmd
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestReplayDropsCmd(t *testing.T) {
+	baseArgs := []string{debugCmdString, replayDropsCmdString}
+
+	tests := []*testCases{
+		{
+			name:    "no log file",
+			args:    baseArgs,
+			wantErr: true,
+		},
+		{
+			name:    "non-existing log file",
+			args:    concatArgs(baseArgs, logFileFlag, nonExistingFile),
+			wantErr: true,
+		},
+		{
+			name:    "correct log file",
+			args:    concatArgs(baseArgs, logFileFlag, dropLogFile),
+			wantErr: false,
+		},
+	}
+
+	testCommand(t, tests)
+}
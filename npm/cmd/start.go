@@ -14,6 +14,7 @@ import (
 	restserver "github.com/Azure/azure-container-networking/npm/http/server"
 	"github.com/Azure/azure-container-networking/npm/metrics"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"github.com/Azure/azure-container-networking/npm/pkg/models"
@@ -28,6 +29,8 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
 	"k8s.io/utils/exec"
+	anpclientset "sigs.k8s.io/network-policy-api/pkg/client/clientset/versioned"
+	anpinformers "sigs.k8s.io/network-policy-api/pkg/client/informers/externalversions"
 )
 
 var npmV2DataplaneCfg = &dataplane.Config{
@@ -119,7 +122,21 @@ func start(config npmconfig.Config, flags npmconfig.Flags) error {
 
 	k8sServerVersion := k8sServerVersion(clientset)
 
+	// AdminNetworkPolicy is only supported in Linux
+	var anpFactory anpinformers.SharedInformerFactory
+	enableAdminNetworkPolicy := config.Toggles.EnableV2NPM && config.Toggles.EnableAdminNetworkPolicy && !util.IsWindowsDP()
+	if enableAdminNetworkPolicy {
+		anpClientset, err := anpclientset.NewForConfig(k8sConfig)
+		if err != nil {
+			klog.Infof("AdminNetworkPolicy clientset creation failed with error %v.", err)
+			return fmt.Errorf("failed to generate AdminNetworkPolicy clientset with cluster config: %w", err)
+		}
+		anpFactory = anpinformers.NewSharedInformerFactory(anpClientset, resyncPeriod)
+	}
+
 	var dp dataplane.GenericDataplane
+	// explainer stays nil for v1 so that the explain endpoint isn't served
+	var explainer restserver.FlowExplainer
 	stopChannel := wait.NeverStop
 	if config.Toggles.EnableV2NPM {
 		// update the dataplane config
@@ -163,6 +180,22 @@ func start(config npmconfig.Config, flags npmconfig.Flags) error {
 			npmV2DataplaneCfg.IPSetMode = ipsets.ApplyAllIPSets
 		}
 
+		// IPv6 is only supported in Linux
+		enableIPv6 := config.Toggles.EnableIPv6 && !util.IsWindowsDP()
+		npmV2DataplaneCfg.IPSetManagerCfg.EnableIPv6 = enableIPv6
+		npmV2DataplaneCfg.PolicyManagerCfg.EnableIPv6 = enableIPv6
+
+		// nftables is only supported in Linux
+		enableNFTables := config.Toggles.EnableNFTables && !util.IsWindowsDP()
+		npmV2DataplaneCfg.IPSetManagerCfg.EnableNFTables = enableNFTables
+		npmV2DataplaneCfg.PolicyManagerCfg.EnableNFTables = enableNFTables
+
+		npmV2DataplaneCfg.PolicyManagerCfg.EnableAdminNetworkPolicy = enableAdminNetworkPolicy
+
+		// drop logging is only supported in Linux
+		enableDropLogging := config.Toggles.EnableDropLogging && !util.IsWindowsDP()
+		npmV2DataplaneCfg.PolicyManagerCfg.EnableDropLogging = enableDropLogging
+
 		var nodeIP string
 		if util.IsWindowsDP() {
 			nodeIP, err = util.NodeIP()
@@ -174,20 +207,25 @@ func start(config npmconfig.Config, flags npmconfig.Flags) error {
 		}
 		npmV2DataplaneCfg.NodeIP = nodeIP
 
-		dp, err = dataplane.NewDataPlane(models.GetNodeName(), common.NewIOShim(), npmV2DataplaneCfg, stopChannel)
+		dataplaneV2, err := dataplane.NewDataPlane(models.GetNodeName(), common.NewIOShim(), npmV2DataplaneCfg, stopChannel)
 		if err != nil {
 			metrics.SendErrorLogAndMetric(util.NpmID, "error: failed to create dataplane with error %v", err)
 			return fmt.Errorf("failed to create dataplane with error %w", err)
 		}
-		dp.RunPeriodicTasks()
+		dataplaneV2.RunPeriodicTasks()
+		if enableDropLogging {
+			go droplog.NewReader(dataplaneV2).Run(stopChannel)
+		}
+		dp = dataplaneV2
+		explainer = dataplaneV2
 	}
-	npMgr := npm.NewNetworkPolicyManager(config, factory, dp, exec.New(), version, k8sServerVersion)
+	npMgr := npm.NewNetworkPolicyManager(config, factory, anpFactory, dp, exec.New(), version, k8sServerVersion)
 	err = metrics.CreateTelemetryHandle(config.NPMVersion(), version, npm.GetAIMetadata())
 	if err != nil {
 		klog.Infof("CreateTelemetryHandle failed with error %v. AITelemetry is not initialized.", err)
 	}
 
-	go restserver.NPMRestServerListenAndServe(config, npMgr)
+	go restserver.NPMRestServerListenAndServe(config, npMgr, explainer)
 
 	metrics.SendLog(util.NpmID, "starting NPM", metrics.PrintLog)
 	if err = npMgr.Start(config, stopChannel); err != nil {
@@ -66,15 +66,16 @@ func startDaemon(config npmconfig.Config) error {
 
 	var dp dataplane.GenericDataplane
 
-	dp, err = dataplane.NewDataPlane(models.GetNodeName(), common.NewIOShim(), npmV2DataplaneCfg, wait.NeverStop)
+	dataplaneV2, err := dataplane.NewDataPlane(models.GetNodeName(), common.NewIOShim(), npmV2DataplaneCfg, wait.NeverStop)
 	if err != nil {
 		klog.Errorf("failed to create dataplane: %v", err)
 		return fmt.Errorf("failed to create dataplane with error %w", err)
 	}
+	dp = dataplaneV2
 
 	dp.RunPeriodicTasks()
 	// TODO Daemon should implement cache encoder
-	go restserver.NPMRestServerListenAndServe(config, nil)
+	go restserver.NPMRestServerListenAndServe(config, nil, dataplaneV2)
 
 	client, err := transport.NewEventsClient(ctx, pod, node, addr)
 	if err != nil {
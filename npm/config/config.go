@@ -94,6 +94,14 @@ type Toggles struct {
 	ApplyInBackground bool
 	// NetPolInBackground
 	NetPolInBackground bool
+	// EnableIPv6 applies for Linux only. It enforces NetworkPolicies for IPv6 traffic in dual-stack clusters.
+	EnableIPv6 bool
+	// EnableNFTables applies for Linux only. It programs the dataplane with native nftables instead of iptables and ipsets.
+	EnableNFTables bool
+	// EnableAdminNetworkPolicy applies for Linux only. It enforces AdminNetworkPolicies and BaselineAdminNetworkPolicies.
+	EnableAdminNetworkPolicy bool
+	// EnableDropLogging applies for Linux only. It logs packets dropped by each policy (rate-limited) and counts them in Prometheus.
+	EnableDropLogging bool
 }
 
 type Flags struct {
@@ -6,6 +6,15 @@ const (
 	NodeMetricsPath    = "/node-metrics"
 	ClusterMetricsPath = "/cluster-metrics"
 	NPMMgrPath         = "/npm/v1/debug/manager"
+	NPMExplainPath     = "/npm/v2/debug/explain"
+)
+
+// query parameters for NPMExplainPath. The response is a JSON array with an explanation for each flow from src to dst.
+const (
+	ExplainSrcParam      = "src"
+	ExplainDstParam      = "dst"
+	ExplainProtocolParam = "protocol"
+	ExplainPortParam     = "port"
 )
 
 type DescribeIPSetRequest struct{}
@@ -2,14 +2,23 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-container-networking/npm/http/api"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 
 	"github.com/Azure/azure-container-networking/npm"
 )
 
+var ErrUnexpectedStatus = errors.New("unexpected status from NPM")
+
 type NPMHttpClient struct {
 	endpoint string
 	client   *http.Client
@@ -44,3 +53,39 @@ func (n *NPMHttpClient) GetNpmMgr() (*npm.NetworkPolicyManager, error) {
 
 	return &ns, nil
 }
+
+// ExplainFlows explains the flows from src to dst. protocol and port are optional (empty and 0 respectively).
+func (n *NPMHttpClient) ExplainFlows(src, dst, protocol string, port int) ([]*policies.FlowExplanation, error) {
+	query := url.Values{}
+	query.Set(api.ExplainSrcParam, src)
+	query.Set(api.ExplainDstParam, dst)
+	if protocol != "" {
+		query.Set(api.ExplainProtocolParam, protocol)
+	}
+	if port != 0 {
+		query.Set(api.ExplainPortParam, strconv.Itoa(port))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, n.endpoint+api.NPMExplainPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("%w: %s: %s", ErrUnexpectedStatus, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var flows []*policies.FlowExplanation
+	err = json.NewDecoder(res.Body).Decode(&flows)
+	if err != nil {
+		return nil, err
+	}
+
+	return flows, nil
+}
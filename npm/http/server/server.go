@@ -2,26 +2,43 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
 	_ "net/http/pprof"
+	"strconv"
 
 	"github.com/Azure/azure-container-networking/log"
 	npmconfig "github.com/Azure/azure-container-networking/npm/config"
 	"github.com/Azure/azure-container-networking/npm/http/api"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"k8s.io/klog"
 
 	"github.com/gorilla/mux"
 )
 
+const defaultExplainProtocol = "TCP"
+
+var (
+	errMissingFlowEndpoint = errors.New("src and dst are required")
+	errInvalidPort         = errors.New("port must be between 1 and 65535")
+)
+
+// FlowExplainer explains flows based on the live state of the dataplane (i.e. the v2 DataPlane).
+type FlowExplainer interface {
+	ExplainFlows(src, dst, protocol string, dstPort int32) ([]*policies.FlowExplanation, error)
+}
+
 type NPMRestServer struct {
 	listeningAddress string
 	router           *mux.Router
 }
 
-func NPMRestServerListenAndServe(config npmconfig.Config, npmEncoder json.Marshaler) {
+// NPMRestServerListenAndServe serves the NPM HTTP API. explainer should be nil unless the v2 dataplane runs in this process.
+func NPMRestServerListenAndServe(config npmconfig.Config, npmEncoder json.Marshaler, explainer FlowExplainer) {
 	rs := NPMRestServer{}
 
 	rs.router = mux.NewRouter()
@@ -37,6 +54,9 @@ func NPMRestServerListenAndServe(config npmconfig.Config, npmEncoder json.Marsha
 		// ACN CLI debug handlers
 		rs.router.Handle(api.NPMMgrPath, rs.npmCacheHandler(npmEncoder)).Methods(http.MethodGet)
 	}
+	if config.Toggles.EnableHTTPDebugAPI && explainer != nil {
+		rs.router.Handle(api.NPMExplainPath, rs.explainHandler(explainer)).Methods(http.MethodGet)
+	}
 
 	if config.Toggles.EnablePprof {
 		rs.router.PathPrefix("/debug/").Handler(http.DefaultServeMux)
@@ -74,3 +94,47 @@ func (n *NPMRestServer) npmCacheHandler(npmCacheEncoder json.Marshaler) http.Han
 		}
 	})
 }
+
+func (n *NPMRestServer) explainHandler(explainer FlowExplainer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		src := query.Get(api.ExplainSrcParam)
+		dst := query.Get(api.ExplainDstParam)
+		if src == "" || dst == "" {
+			http.Error(w, errMissingFlowEndpoint.Error(), http.StatusBadRequest)
+			return
+		}
+		protocol := query.Get(api.ExplainProtocolParam)
+		if protocol == "" {
+			protocol = defaultExplainProtocol
+		}
+		var port int64
+		if portString := query.Get(api.ExplainPortParam); portString != "" {
+			var err error
+			port, err = strconv.ParseInt(portString, 10, 32)
+			if err != nil || port < 1 || port > 65535 {
+				http.Error(w, errInvalidPort.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		flows, err := explainer.ExplainFlows(src, dst, protocol, int32(port))
+		if errors.Is(err, dataplane.ErrUnknownFlowEndpoint) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b, err := json.Marshal(flows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, err = w.Write(b)
+		if err != nil {
+			log.Errorf("failed to write resp: %v", err)
+		}
+	})
+}
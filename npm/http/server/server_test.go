@@ -2,15 +2,20 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/Azure/azure-container-networking/npm"
 	"github.com/Azure/azure-container-networking/npm/http/api"
 	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetNPMCacheHandler(t *testing.T) {
@@ -55,3 +60,83 @@ func TestGetNPMCacheHandler(t *testing.T) {
 
 	assert.Exactly(expected, actual)
 }
+
+type fakeExplainer struct{}
+
+func (fakeExplainer) ExplainFlows(src, dst, protocol string, dstPort int32) ([]*policies.FlowExplanation, error) {
+	if src == "unknown" {
+		return nil, fmt.Errorf("%w: %s", dataplane.ErrUnknownFlowEndpoint, src)
+	}
+	return []*policies.FlowExplanation{
+		{
+			Flow:     policies.Flow{SrcIP: src, DstIP: dst, Protocol: protocol, DstPort: dstPort},
+			Policies: []string{},
+			ACLs:     []*policies.MatchedACL{},
+			Verdict:  policies.Allowed,
+		},
+	}, nil
+}
+
+func TestExplainHandler(t *testing.T) {
+	n := &NPMRestServer{}
+	handler := n.explainHandler(fakeExplainer{})
+
+	tests := []struct {
+		name           string
+		query          url.Values
+		expectedStatus int
+		expectedFlow   policies.Flow
+	}{
+		{
+			name:           "missing dst",
+			query:          url.Values{api.ExplainSrcParam: {"10.0.0.1"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid port",
+			query:          url.Values{api.ExplainSrcParam: {"10.0.0.1"}, api.ExplainDstParam: {"10.0.0.2"}, api.ExplainPortParam: {"70000"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown endpoint",
+			query:          url.Values{api.ExplainSrcParam: {"unknown"}, api.ExplainDstParam: {"10.0.0.2"}},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "default protocol",
+			query:          url.Values{api.ExplainSrcParam: {"10.0.0.1"}, api.ExplainDstParam: {"10.0.0.2"}, api.ExplainPortParam: {"80"}},
+			expectedStatus: http.StatusOK,
+			expectedFlow:   policies.Flow{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "TCP", DstPort: 80},
+		},
+		{
+			name: "protocol without port",
+			query: url.Values{
+				api.ExplainSrcParam:      {"10.0.0.1"},
+				api.ExplainDstParam:      {"10.0.0.2"},
+				api.ExplainProtocolParam: {"UDP"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedFlow:   policies.Flow{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "UDP"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, api.NPMExplainPath+"?"+tt.query.Encode(), nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var flows []*policies.FlowExplanation
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &flows))
+			require.Len(t, flows, 1)
+			require.Equal(t, tt.expectedFlow, flows[0].Flow)
+			require.Equal(t, policies.Allowed, flows[0].Verdict)
+		})
+	}
+}
@@ -41,3 +41,20 @@ func TotalIPTablesRestoreFailures(op OperationKind) (int, error) {
 		operationLabel: string(op),
 	}))
 }
+
+func IncPolicyDrops(policyNamespace, policyKey, direction string) {
+	labels := prometheus.Labels{
+		namespaceLabel: policyNamespace,
+		policyLabel:    policyKey,
+		directionLabel: direction,
+	}
+	policyDrops.With(labels).Inc()
+}
+
+func TotalPolicyDrops(policyNamespace, policyKey, direction string) (int, error) {
+	return counterValue(policyDrops.With(prometheus.Labels{
+		namespaceLabel: policyNamespace,
+		policyLabel:    policyKey,
+		directionLabel: direction,
+	}))
+}
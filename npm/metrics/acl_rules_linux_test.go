@@ -47,3 +47,17 @@ func TestIncIPTablesRestoreFailures(t *testing.T) {
 	require.Nil(t, err, "failed to get metric")
 	require.Equal(t, 1, count, "should have failed to update once")
 }
+
+func TestIncPolicyDrops(t *testing.T) {
+	IncPolicyDrops("x", "x/deny-all", "INGRESS")
+	IncPolicyDrops("x", "x/deny-all", "INGRESS")
+	IncPolicyDrops("x", "x/deny-all", "EGRESS")
+
+	count, err := TotalPolicyDrops("x", "x/deny-all", "INGRESS")
+	require.Nil(t, err, "failed to get metric")
+	require.Equal(t, 2, count, "should have dropped twice on ingress")
+
+	count, err = TotalPolicyDrops("x", "x/deny-all", "EGRESS")
+	require.Nil(t, err, "failed to get metric")
+	require.Equal(t, 1, count, "should have dropped once on egress")
+}
@@ -118,13 +118,20 @@ var (
 	setPolicyFailures     *prometheus.CounterVec
 )
 
-const linuxPrefix = "linux"
+const (
+	linuxPrefix = "linux"
+
+	namespaceLabel = "namespace"
+	policyLabel    = "policy"
+	directionLabel = "direction"
+)
 
 // linux metrics added in v1.5.5
 var (
 	itpablesRestoreLatency  *prometheus.HistogramVec
 	iptablesDeleteLatency   prometheus.Histogram
 	iptablesRestoreFailures *prometheus.CounterVec
+	policyDrops             *prometheus.CounterVec
 )
 
 type RegistryType string
@@ -196,6 +203,7 @@ func InitializeAll() {
 		register(itpablesRestoreLatency, "iptables_restore_latency_seconds", NodeMetrics)
 		register(iptablesDeleteLatency, "iptables_delete_latency_seconds", NodeMetrics)
 		register(iptablesRestoreFailures, "iptables_restore_failure_total", NodeMetrics)
+		register(policyDrops, "policy_drops_total", NodeMetrics)
 	}
 
 	log.Logf("Finished initializing all Prometheus metrics")
@@ -354,6 +362,16 @@ func InitializeLinuxMetrics() {
 		},
 		[]string{operationLabel},
 	)
+
+	policyDrops = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "policy_drops_total",
+			Subsystem: linuxPrefix,
+			Help:      "Number of logged packets dropped by each policy when drop logging is enabled. Logging is rate-limited per AdminNetworkPolicy ACL and per direction for NetworkPolicies",
+		},
+		[]string{namespaceLabel, policyLabel, directionLabel},
+	)
 }
 
 // GetHandler returns the HTTP handler for the metrics endpoint
@@ -21,6 +21,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 	utilexec "k8s.io/utils/exec"
+	anpinformers "sigs.k8s.io/network-policy-api/pkg/client/informers/externalversions"
 )
 
 var aiMetadata string //nolint // aiMetadata is set in Makefile
@@ -55,9 +56,11 @@ type NetworkPolicyManager struct {
 	models.AzureConfig
 }
 
-// NewNetworkPolicyManager creates a NetworkPolicyManager
+// NewNetworkPolicyManager creates a NetworkPolicyManager.
+// anpInformerFactory should be nil unless AdminNetworkPolicy is enabled.
 func NewNetworkPolicyManager(config npmconfig.Config,
 	informerFactory informers.SharedInformerFactory,
+	anpInformerFactory anpinformers.SharedInformerFactory,
 	dp dataplane.GenericDataplane,
 	exec utilexec.Interface,
 	npmVersion string,
@@ -88,6 +91,12 @@ func NewNetworkPolicyManager(config npmconfig.Config,
 		npMgr.NamespaceControllerV2 = controllersv2.NewNamespaceController(npMgr.NsInformer, dp, npMgr.NpmNamespaceCacheV2)
 		// Question(jungukcho): Is config.Toggles.PlaceAzureChainFirst needed for v2?
 		npMgr.NetPolControllerV2 = controllersv2.NewNetworkPolicyController(npMgr.NpInformer, dp)
+		if anpInformerFactory != nil {
+			npMgr.AnpInformerFactory = anpInformerFactory
+			npMgr.AnpInformer = anpInformerFactory.Policy().V1alpha1().AdminNetworkPolicies()
+			npMgr.BanpInformer = anpInformerFactory.Policy().V1alpha1().BaselineAdminNetworkPolicies()
+			npMgr.AdminNetPolControllerV2 = controllersv2.NewAdminNetworkPolicyController(npMgr.AnpInformer, npMgr.BanpInformer, dp)
+		}
 		return npMgr
 	}
 
@@ -200,9 +209,20 @@ func (npMgr *NetworkPolicyManager) Start(config npmconfig.Config, stopCh <-chan
 		return fmt.Errorf("NetworkPolicy informer error: %w", models.ErrInformerSyncFailure)
 	}
 
+	if npMgr.AnpInformerFactory != nil {
+		npMgr.AnpInformerFactory.Start(stopCh)
+
+		if !cache.WaitForCacheSync(stopCh, npMgr.AnpInformer.Informer().HasSynced, npMgr.BanpInformer.Informer().HasSynced) {
+			return fmt.Errorf("AdminNetworkPolicy informer error: %w", models.ErrInformerSyncFailure)
+		}
+	}
+
 	// start v2 NPM controllers after synced
 	if config.Toggles.EnableV2NPM {
 		go npMgr.NetPolControllerV2.Run(stopCh)
+		if npMgr.AdminNetPolControllerV2 != nil {
+			go npMgr.AdminNetPolControllerV2.Run(stopCh)
+		}
 
 		if util.IsWindowsDP() && config.Toggles.ApplyInBackground {
 			klog.Infof("optimizing NPM bootup by letting NetPol controller process changes first. waiting %v before starting pod and namespace controllers", waitDurationAfterStartingNetPolController)
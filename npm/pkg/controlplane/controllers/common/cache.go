@@ -70,6 +70,12 @@ func (c *Cache) GetPod(input *Input) (*NpmPod, error) {
 			if pod.PodIP == input.Content {
 				return pod, nil
 			}
+			// the other IP of a dual-stack pod
+			for _, podIP := range pod.PodIPs {
+				if podIP == input.Content {
+					return pod, nil
+				}
+			}
 		}
 		return nil, ErrInvalidIPAddress
 	case EXTERNAL:
@@ -14,6 +14,9 @@ type NpmPod struct {
 	Labels         map[string]string
 	ContainerPorts []corev1.ContainerPort
 	Phase          corev1.PodPhase
+	// PodIPs are all the IPs of the Pod (from Status.PodIPs) e.g. an IPv4 and an IPv6 address for a dual-stack Pod.
+	// PodIP is always the first entry if there are any.
+	PodIPs []string `json:",omitempty"`
 }
 
 type LabelAppendOperation bool
@@ -36,6 +39,7 @@ func NewNpmPod(podObj *corev1.Pod) *NpmPod {
 		Name:           podObj.ObjectMeta.Name,
 		Namespace:      podObj.ObjectMeta.Namespace,
 		PodIP:          podObj.Status.PodIP,
+		PodIPs:         GetPodIPList(podObj),
 		Labels:         make(map[string]string),
 		ContainerPorts: []corev1.ContainerPort{},
 		Phase:          podObj.Status.Phase,
@@ -76,12 +80,25 @@ func (n *NpmPod) NoUpdate(podObj *corev1.Pod) bool {
 		n.Name == podObj.ObjectMeta.Name &&
 		n.Phase == podObj.Status.Phase &&
 		n.PodIP == podObj.Status.PodIP &&
+		reflect.DeepEqual(n.PodIPs, GetPodIPList(podObj)) &&
 		k8slabels.Equals(n.Labels, podObj.ObjectMeta.Labels) &&
 		// TODO(jungukcho) to avoid using DeepEqual for ContainerPorts,
 		// it needs a precise sorting. Will optimize it later if needed.
 		reflect.DeepEqual(n.ContainerPorts, GetContainerPortList(podObj))
 }
 
+// GetPodIPList returns the IPs in the Pod's Status.PodIPs, or nil if there are none.
+func GetPodIPList(podObj *corev1.Pod) []string {
+	if len(podObj.Status.PodIPs) == 0 {
+		return nil
+	}
+	podIPs := make([]string, 0, len(podObj.Status.PodIPs))
+	for _, podIP := range podObj.Status.PodIPs {
+		podIPs = append(podIPs, podIP.IP)
+	}
+	return podIPs
+}
+
 func GetContainerPortList(podObj *corev1.Pod) []corev1.ContainerPort {
 	portList := []corev1.ContainerPort{}
 	for _, container := range podObj.Spec.Containers { //nolint:gocritic // intentionally copying full struct :(
@@ -0,0 +1,306 @@
+// Copyright 2018 Microsoft. All rights reserved.
+// MIT License
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/translation"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	anpv1alpha1 "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+	anpinformers "sigs.k8s.io/network-policy-api/pkg/client/informers/externalversions/apis/v1alpha1"
+	anplisters "sigs.k8s.io/network-policy-api/pkg/client/listers/apis/v1alpha1"
+)
+
+var errAdminNetPolKeyFormat = errors.New("invalid admin network policy key format")
+
+// AdminNetworkPolicyController syncs AdminNetworkPolicies and BaselineAdminNetworkPolicies to the dataplane.
+// Both kinds are cluster-scoped and share a workqueue keyed by their PolicyKey ("<kind>/<name>").
+type AdminNetworkPolicyController struct {
+	sync.RWMutex
+	anpLister  anplisters.AdminNetworkPolicyLister
+	banpLister anplisters.BaselineAdminNetworkPolicyLister
+	workqueue  workqueue.RateLimitingInterface
+	// Key is the PolicyKey. Value is *AdminNetworkPolicySpec or *BaselineAdminNetworkPolicySpec
+	rawSpecMap map[string]interface{}
+	dp         dataplane.GenericDataplane
+}
+
+func NewAdminNetworkPolicyController(anpInformer anpinformers.AdminNetworkPolicyInformer, banpInformer anpinformers.BaselineAdminNetworkPolicyInformer,
+	dp dataplane.GenericDataplane,
+) *AdminNetworkPolicyController {
+	anpController := &AdminNetworkPolicyController{
+		anpLister:  anpInformer.Lister(),
+		banpLister: banpInformer.Lister(),
+		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AdminNetworkPolicy"),
+		rawSpecMap: make(map[string]interface{}),
+		dp:         dp,
+	}
+
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc:    anpController.addPolicy,
+		UpdateFunc: anpController.updatePolicy,
+		DeleteFunc: anpController.deletePolicy,
+	}
+	anpInformer.Informer().AddEventHandler(handlers)
+	banpInformer.Informer().AddEventHandler(handlers)
+	return anpController
+}
+
+func (c *AdminNetworkPolicyController) LengthOfRawSpecMap() int {
+	return len(c.rawSpecMap)
+}
+
+// getPolicyKey returns the PolicyKey of an AdminNetworkPolicy or BaselineAdminNetworkPolicy object.
+// If obj is neither, it returns error.
+func (c *AdminNetworkPolicyController) getPolicyKey(obj interface{}) (string, error) {
+	switch policy := obj.(type) {
+	case *anpv1alpha1.AdminNetworkPolicy:
+		return policies.AdminPolicyKey(policies.AdminTier, policy.Name), nil
+	case *anpv1alpha1.BaselineAdminNetworkPolicy:
+		return policies.AdminPolicyKey(policies.BaselineTier, policy.Name), nil
+	default:
+		return "", fmt.Errorf("cannot cast obj (%v) to admin network policy obj err: %w", obj, errAdminNetPolKeyFormat)
+	}
+}
+
+func (c *AdminNetworkPolicyController) addPolicy(obj interface{}) {
+	key, err := c.getPolicyKey(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	c.workqueue.Add(key)
+}
+
+func (c *AdminNetworkPolicyController) updatePolicy(old, newPolicy interface{}) {
+	key, err := c.getPolicyKey(newPolicy)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	oldMeta, oldErr := meta.Accessor(old)
+	newMeta, newErr := meta.Accessor(newPolicy)
+	if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+		// Periodic resync will send update events for all known policies.
+		// Two different versions of the same policy will always have different RVs.
+		return
+	}
+
+	c.workqueue.Add(key)
+}
+
+func (c *AdminNetworkPolicyController) deletePolicy(obj interface{}) {
+	// DeleteFunc gets the final state of the resource (if it is known).
+	// Otherwise, it gets an object of type DeletedFinalStateUnknown.
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	key, err := c.getPolicyKey(obj)
+	if err != nil {
+		metrics.SendErrorLogAndMetric(util.NetpolID, "[ADMIN NETPOL DELETE EVENT] Received unexpected object type: %v", obj)
+		return
+	}
+
+	c.workqueue.Add(key)
+}
+
+func (c *AdminNetworkPolicyController) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.Infof("Starting Admin Network Policy worker")
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	klog.Infof("Started Admin Network Policy worker")
+	<-stopCh
+	klog.Info("Shutting down Admin Network Policy workers")
+}
+
+func (c *AdminNetworkPolicyController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *AdminNetworkPolicyController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.workqueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			// As the item in the workqueue is actually invalid, we call
+			// Forget here else we'd go into a loop of attempting to
+			// process a work item that is invalid.
+			c.workqueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v, err %w", obj, errWorkqueueFormatting))
+			return nil
+		}
+		if err := c.syncPolicy(key); err != nil {
+			// Put the item back on the workqueue to handle any transient errors.
+			c.workqueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %w, requeuing", key, err)
+		}
+		c.workqueue.Forget(obj)
+		klog.Infof("Successfully synced '%s'", key)
+		return nil
+	}(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		metrics.SendErrorLogAndMetric(util.NetpolID, "syncAdminNetPol error due to %v", err)
+		return true
+	}
+
+	return true
+}
+
+// syncPolicy compares the actual state with the desired, and attempts to converge the two.
+func (c *AdminNetworkPolicyController) syncPolicy(key string) error {
+	// timer for recording execution times
+	timer := metrics.StartNewTimer()
+
+	kind, name, found := strings.Cut(key, "/")
+	if !found {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s err: %w", key, errAdminNetPolKeyFormat))
+		return nil //nolint HandleError  is used instead of returning error to caller
+	}
+
+	// record exec time after syncing
+	operationKind := metrics.NoOp
+	var err error
+	defer func() {
+		metrics.RecordControllerPolicyExecTime(timer, operationKind, err != nil)
+	}()
+
+	var objMeta metav1.Object
+	var spec interface{}
+	var translate func() (*policies.NPMNetworkPolicy, error)
+	switch kind {
+	case policies.AdminTier.Kind():
+		var anp *anpv1alpha1.AdminNetworkPolicy
+		anp, err = c.anpLister.Get(name)
+		if err == nil {
+			objMeta, spec = anp, &anp.Spec
+			translate = func() (*policies.NPMNetworkPolicy, error) { return translation.TranslateAdminNetworkPolicy(anp) }
+		}
+	case policies.BaselineTier.Kind():
+		var banp *anpv1alpha1.BaselineAdminNetworkPolicy
+		banp, err = c.banpLister.Get(name)
+		if err == nil {
+			objMeta, spec = banp, &banp.Spec
+			translate = func() (*policies.NPMNetworkPolicy, error) {
+				return translation.TranslateBaselineAdminNetworkPolicy(banp)
+			}
+		}
+	default:
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s err: %w", key, errAdminNetPolKeyFormat))
+		return nil //nolint HandleError  is used instead of returning error to caller
+	}
+
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.Infof("%s is not found, may be it is deleted", key)
+			if _, ok := c.rawSpecMap[key]; ok {
+				operationKind = metrics.DeleteOp
+			}
+			err = c.cleanUpPolicy(key)
+			if err != nil {
+				return fmt.Errorf("[syncAdminNetPol] error: %w when policy is not found", err)
+			}
+			return nil
+		}
+		return err
+	}
+
+	// If DeletionTimestamp is set, start cleaning up lastly applied states.
+	if objMeta.GetDeletionTimestamp() != nil || objMeta.GetDeletionGracePeriodSeconds() != nil {
+		if _, ok := c.rawSpecMap[key]; ok {
+			operationKind = metrics.DeleteOp
+		}
+		err = c.cleanUpPolicy(key)
+		if err != nil {
+			return fmt.Errorf("error: %w when ObjectMeta.DeletionTimestamp field is set", err)
+		}
+		return nil
+	}
+
+	if cachedSpec, ok := c.rawSpecMap[key]; ok && reflect.DeepEqual(cachedSpec, spec) {
+		return nil
+	}
+
+	operationKind, err = c.syncAddAndUpdatePolicy(key, spec, translate)
+	if err != nil {
+		return fmt.Errorf("[syncAdminNetPol] error due to  %w", err)
+	}
+	return nil
+}
+
+// syncAddAndUpdatePolicy handles a new or updated policy triggered by add and update events.
+func (c *AdminNetworkPolicyController) syncAddAndUpdatePolicy(key string, spec interface{}, translate func() (*policies.NPMNetworkPolicy, error)) (metrics.OperationKind, error) {
+	npmNetPolObj, err := translate()
+	if err != nil {
+		klog.Errorf("Failed to translate %s: %s", key, err.Error())
+		// The exec time isn't relevant here, so consider a no-op. Returning nil to prevent re-queuing since this is not a transient error.
+		return metrics.NoOp, nil
+	}
+
+	_, policyExisted := c.rawSpecMap[key]
+	operationKind := metrics.CreateOp
+	if policyExisted {
+		operationKind = metrics.UpdateOp
+	}
+
+	err = c.dp.UpdatePolicy(npmNetPolObj)
+	if err != nil {
+		// if error occurred the key is re-queued in workqueue and process this function again,
+		// which eventually meets desired states of the policy
+		return operationKind, fmt.Errorf("[syncAddAndUpdateAdminNetPol] Error: failed to update translated NPMNetworkPolicy into Dataplane due to %w", err)
+	}
+
+	if !policyExisted {
+		metrics.IncNumPolicies()
+	}
+
+	c.rawSpecMap[key] = spec
+	return operationKind, nil
+}
+
+// cleanUpPolicy removes the policy from the dataplane if it was applied.
+func (c *AdminNetworkPolicyController) cleanUpPolicy(key string) error {
+	if _, ok := c.rawSpecMap[key]; !ok {
+		return nil
+	}
+
+	err := c.dp.RemovePolicy(key)
+	if err != nil {
+		return fmt.Errorf("[cleanUpAdminNetworkPolicy] Error: failed to remove policy due to %w", err)
+	}
+
+	delete(c.rawSpecMap, key)
+	metrics.DecNumPolicies()
+	return nil
+}
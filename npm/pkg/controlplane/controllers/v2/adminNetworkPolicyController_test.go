@@ -0,0 +1,229 @@
+// Copyright 2018 Microsoft. All rights reserved.
+// MIT License
+package controllers
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane"
+	dpmocks "github.com/Azure/azure-container-networking/npm/pkg/dataplane/mocks"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	anpv1alpha1 "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+	anpfake "sigs.k8s.io/network-policy-api/pkg/client/clientset/versioned/fake"
+	anpinformers "sigs.k8s.io/network-policy-api/pkg/client/informers/externalversions"
+)
+
+type anpFixture struct {
+	t *testing.T
+
+	anpController *AdminNetworkPolicyController
+	anpInformer   anpinformers.SharedInformerFactory
+}
+
+func newANPFixture(t *testing.T, dp dataplane.GenericDataplane) *anpFixture {
+	client := anpfake.NewSimpleClientset()
+	f := &anpFixture{
+		t:           t,
+		anpInformer: anpinformers.NewSharedInformerFactory(client, noResyncPeriodFunc()),
+	}
+	f.anpController = NewAdminNetworkPolicyController(
+		f.anpInformer.Policy().V1alpha1().AdminNetworkPolicies(),
+		f.anpInformer.Policy().V1alpha1().BaselineAdminNetworkPolicies(),
+		dp,
+	)
+	metrics.ReinitializeAll()
+	return f
+}
+
+func (f *anpFixture) anpIndexer() cache.Indexer {
+	return f.anpInformer.Policy().V1alpha1().AdminNetworkPolicies().Informer().GetIndexer()
+}
+
+func (f *anpFixture) banpIndexer() cache.Indexer {
+	return f.anpInformer.Policy().V1alpha1().BaselineAdminNetworkPolicies().Informer().GetIndexer()
+}
+
+// processEvent processes the queued key (if any) after simulating an informer event
+func (f *anpFixture) processEvent() {
+	if f.anpController.workqueue.Len() == 0 {
+		return
+	}
+	f.anpController.processNextWorkItem()
+}
+
+func createANP() *anpv1alpha1.AdminNetworkPolicy {
+	return &anpv1alpha1.AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "isolate-tenants",
+			ResourceVersion: "1",
+		},
+		Spec: anpv1alpha1.AdminNetworkPolicySpec{
+			Priority: 10,
+			Subject: anpv1alpha1.AdminNetworkPolicySubject{
+				Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			},
+			Ingress: []anpv1alpha1.AdminNetworkPolicyIngressRule{
+				{
+					Action: anpv1alpha1.AdminNetworkPolicyRuleActionDeny,
+					From: []anpv1alpha1.AdminNetworkPolicyPeer{
+						{
+							Namespaces: &anpv1alpha1.NamespacedPeer{
+								NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createBANP() *anpv1alpha1.BaselineAdminNetworkPolicy {
+	return &anpv1alpha1.BaselineAdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "default",
+			ResourceVersion: "1",
+		},
+		Spec: anpv1alpha1.BaselineAdminNetworkPolicySpec{
+			Subject: anpv1alpha1.AdminNetworkPolicySubject{Namespaces: &metav1.LabelSelector{}},
+			Egress: []anpv1alpha1.BaselineAdminNetworkPolicyEgressRule{
+				{
+					Action: anpv1alpha1.BaselineAdminNetworkPolicyRuleActionDeny,
+					To: []anpv1alpha1.AdminNetworkPolicyPeer{
+						{Namespaces: &anpv1alpha1.NamespacedPeer{NamespaceSelector: &metav1.LabelSelector{}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// policyKeyMatcher matches an NPMNetworkPolicy with the PolicyKey
+type policyKeyMatcher string
+
+func (m policyKeyMatcher) Matches(x interface{}) bool {
+	npmNetPol, ok := x.(*policies.NPMNetworkPolicy)
+	return ok && npmNetPol.PolicyKey == string(m)
+}
+
+func (m policyKeyMatcher) String() string {
+	return "has PolicyKey " + string(m)
+}
+
+func TestAddAdminNetworkPolicies(t *testing.T) {
+	if util.IsWindowsDP() {
+		return
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	f := newANPFixture(t, dp)
+
+	anp := createANP()
+	banp := createBANP()
+	require.NoError(t, f.anpIndexer().Add(anp))
+	require.NoError(t, f.banpIndexer().Add(banp))
+
+	dp.EXPECT().UpdatePolicy(policyKeyMatcher("AdminNetworkPolicy/isolate-tenants")).Times(1)
+	dp.EXPECT().UpdatePolicy(policyKeyMatcher("BaselineAdminNetworkPolicy/default")).Times(1)
+
+	f.anpController.addPolicy(anp)
+	f.processEvent()
+	f.anpController.addPolicy(banp)
+	f.processEvent()
+
+	// already exists (will be a no-op)
+	f.anpController.addPolicy(anp)
+	f.processEvent()
+
+	require.Equal(t, 2, f.anpController.LengthOfRawSpecMap())
+	require.Equal(t, 0, f.anpController.workqueue.Len())
+	(&netPolPromVals{2, 2, 0, 0}).testPrometheusMetrics(t)
+}
+
+func TestUpdateAdminNetworkPolicy(t *testing.T) {
+	if util.IsWindowsDP() {
+		return
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	f := newANPFixture(t, dp)
+
+	oldANP := createANP()
+	require.NoError(t, f.anpIndexer().Add(oldANP))
+	dp.EXPECT().UpdatePolicy(gomock.Any()).Times(2)
+	f.anpController.addPolicy(oldANP)
+	f.processEvent()
+
+	// a resync with the same resource version isn't queued
+	f.anpController.updatePolicy(oldANP, oldANP)
+	require.Equal(t, 0, f.anpController.workqueue.Len())
+
+	newANP := oldANP.DeepCopy()
+	newANP.ResourceVersion = "2"
+	newANP.Spec.Priority = 20
+	require.NoError(t, f.anpIndexer().Update(newANP))
+	f.anpController.updatePolicy(oldANP, newANP)
+	f.processEvent()
+
+	require.Equal(t, 1, f.anpController.LengthOfRawSpecMap())
+	(&netPolPromVals{1, 1, 1, 0}).testPrometheusMetrics(t)
+}
+
+func TestDeleteAdminNetworkPolicy(t *testing.T) {
+	if util.IsWindowsDP() {
+		return
+	}
+
+	for _, withTombstone := range []bool{false, true} {
+		ctrl := gomock.NewController(t)
+		dp := dpmocks.NewMockGenericDataplane(ctrl)
+		f := newANPFixture(t, dp)
+
+		banp := createBANP()
+		require.NoError(t, f.banpIndexer().Add(banp))
+		dp.EXPECT().UpdatePolicy(gomock.Any()).Times(1)
+		dp.EXPECT().RemovePolicy("BaselineAdminNetworkPolicy/default").Times(1)
+		f.anpController.addPolicy(banp)
+		f.processEvent()
+
+		require.NoError(t, f.banpIndexer().Delete(banp))
+		if withTombstone {
+			f.anpController.deletePolicy(cache.DeletedFinalStateUnknown{Key: banp.Name, Obj: banp})
+		} else {
+			f.anpController.deletePolicy(banp)
+		}
+		f.processEvent()
+
+		require.Equal(t, 0, f.anpController.LengthOfRawSpecMap())
+		(&netPolPromVals{0, 1, 0, 1}).testPrometheusMetrics(t)
+		ctrl.Finish()
+	}
+}
+
+func TestAddUnsupportedAdminNetworkPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	f := newANPFixture(t, dp)
+
+	anp := createANP()
+	anp.Spec.Ingress[0].From[0].Namespaces = &anpv1alpha1.NamespacedPeer{SameLabels: []string{"tenant"}}
+	require.NoError(t, f.anpIndexer().Add(anp))
+
+	// translation errors aren't requeued
+	f.anpController.addPolicy(anp)
+	f.processEvent()
+
+	require.Equal(t, 0, f.anpController.LengthOfRawSpecMap())
+	require.Equal(t, 0, f.anpController.workqueue.Len())
+}
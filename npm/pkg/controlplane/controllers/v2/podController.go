@@ -350,8 +350,9 @@ func (c *PodController) syncAddedPod(podObj *corev1.Pod) error {
 	klog.Infof("POD CREATING: [%s/%s/%s/%s/%+v/%s]", string(podObj.GetUID()), podObj.Namespace,
 		podObj.Name, podObj.Spec.NodeName, podObj.Labels, podObj.Status.PodIP)
 
-	if !util.IsIPV4(podObj.Status.PodIP) {
-		msg := fmt.Sprintf("[syncAddedPod] warning: ADD POD  [%s/%s/%s/%+v] ignored as the PodIP is not valid ipv4 address. ip: [%s]", podObj.Namespace,
+	ips := podIPs(podObj.Status.PodIP, common.GetPodIPList(podObj))
+	if len(ips) == 0 {
+		msg := fmt.Sprintf("[syncAddedPod] warning: ADD POD  [%s/%s/%s/%+v] ignored as the PodIP is not a supported ip address. ip: [%s]", podObj.Namespace,
 			podObj.Name, podObj.Spec.NodeName, podObj.Labels, podObj.Status.PodIP)
 		metrics.SendLog(util.PodID, msg, metrics.PrintLog)
 		// return nil so that we don't requeue.
@@ -364,14 +365,15 @@ func (c *PodController) syncAddedPod(podObj *corev1.Pod) error {
 	var err error
 	podKey, _ := cache.MetaNamespaceKeyFunc(podObj)
 
-	podMetadata := dataplane.NewPodMetadata(podKey, podObj.Status.PodIP, podObj.Spec.NodeName)
-
 	namespaceSet := []*ipsets.IPSetMetadata{ipsets.NewIPSetMetadata(podObj.Namespace, ipsets.Namespace)}
 
 	// Add the pod ip information into namespace's ipset.
-	klog.Infof("Adding pod %s (ip : %s) to ipset %s", podKey, podObj.Status.PodIP, podObj.Namespace)
-	if err = c.dp.AddToSets(namespaceSet, podMetadata); err != nil {
-		return fmt.Errorf("[syncAddedPod] Error: failed to add pod to namespace ipset with err: %w", err)
+	for _, podIP := range ips {
+		podMetadata := dataplane.NewPodMetadata(podKey, podIP, podObj.Spec.NodeName)
+		klog.Infof("Adding pod %s (ip : %s) to ipset %s", podKey, podIP, podObj.Namespace)
+		if err = c.dp.AddToSets(namespaceSet, podMetadata); err != nil {
+			return fmt.Errorf("[syncAddedPod] Error: failed to add pod to namespace ipset with err: %w", err)
+		}
 	}
 
 	// Create npmPod and add it to the podMap
@@ -388,9 +390,12 @@ func (c *PodController) syncAddedPod(podObj *corev1.Pod) error {
 		allSets := []*ipsets.IPSetMetadata{targetSetKey, targetSetKeyValue}
 
 		klog.Infof("Creating ipsets %+v and %+v if they do not exist", targetSetKey, targetSetKeyValue)
-		klog.Infof("Adding pod %s (ip : %s) to ipset %s and %s", podKey, npmPodObj.PodIP, labelKey, labelKeyValue)
-		if err = c.dp.AddToSets(allSets, podMetadata); err != nil {
-			return fmt.Errorf("[syncAddedPod] Error: failed to add pod to label ipset with err: %w", err)
+		for _, podIP := range ips {
+			podMetadata := dataplane.NewPodMetadata(podKey, podIP, podObj.Spec.NodeName)
+			klog.Infof("Adding pod %s (ip : %s) to ipset %s and %s", podKey, podIP, labelKey, labelKeyValue)
+			if err = c.dp.AddToSets(allSets, podMetadata); err != nil {
+				return fmt.Errorf("[syncAddedPod] Error: failed to add pod to label ipset with err: %w", err)
+			}
 		}
 		npmPodObj.AppendLabels(map[string]string{labelKey: labelVal}, common.AppendToExistingLabels)
 	}
@@ -398,8 +403,10 @@ func (c *PodController) syncAddedPod(podObj *corev1.Pod) error {
 	// Add pod's named ports from its ipset.
 	klog.Infof("Adding named port ipsets")
 	containerPorts := common.GetContainerPortList(podObj)
-	if err = c.manageNamedPortIpsets(containerPorts, podKey, npmPodObj.PodIP, podObj.Spec.NodeName, addNamedPort); err != nil {
-		return fmt.Errorf("[syncAddedPod] Error: failed to add pod to named port ipset with err: %w", err)
+	for _, podIP := range ips {
+		if err = c.manageNamedPortIpsets(containerPorts, podKey, podIP, podObj.Spec.NodeName, addNamedPort); err != nil {
+			return fmt.Errorf("[syncAddedPod] Error: failed to add pod to named port ipset with err: %w", err)
+		}
 	}
 	npmPodObj.AppendContainerPorts(podObj)
 
@@ -445,7 +452,10 @@ func (c *PodController) syncAddAndUpdatePod(newPodObj *corev1.Pod) (metrics.Oper
 	// Dealing with #2 pod update event, the IP addresses of cached npmPod and newPodObj are different
 	// NPM should clean up existing references of cached pod obj and its IP.
 	// then, re-add new pod obj.
-	if cachedNpmPod.PodIP != newPodObj.Status.PodIP {
+	// A dual-stack pod is also re-added if its secondary IP changes.
+	cachedPodIPs := podIPs(cachedNpmPod.PodIP, cachedNpmPod.PodIPs)
+	newPodIPs := podIPs(newPodObj.Status.PodIP, common.GetPodIPList(newPodObj))
+	if cachedNpmPod.PodIP != newPodObj.Status.PodIP || !reflect.DeepEqual(cachedPodIPs, newPodIPs) {
 		klog.Infof("Pod (Namespace:%s, Name:%s, newUid:%s), has cachedPodIp:%s which is different from PodIp:%s",
 			newPodObj.Namespace, newPodObj.Name, string(newPodObj.UID), cachedNpmPod.PodIP, newPodObj.Status.PodIP)
 
@@ -463,21 +473,21 @@ func (c *PodController) syncAddAndUpdatePod(newPodObj *corev1.Pod) (metrics.Oper
 	// Otherwise it returns list of deleted PodIP from cached pod's labels and list of added PodIp from new pod's labels
 	addToIPSets, deleteFromIPSets := util.GetIPSetListCompareLabels(cachedNpmPod.Labels, newPodObj.Labels)
 
-	newPodMetadata := dataplane.NewPodMetadata(podKey, newPodObj.Status.PodIP, newPodObj.Spec.NodeName)
-	// should have newPodMetadata == cachedPodMetadata since from branch above, we have cachedNpmPod.PodIP == newPodObj.Status.PodIP
-	cachedPodMetadata := dataplane.NewPodMetadata(podKey, cachedNpmPod.PodIP, newPodMetadata.NodeName)
 	// Delete the pod from its label's ipset.
 	for _, removeIPSetName := range deleteFromIPSets {
-		klog.Infof("Deleting pod %s (ip : %s) from ipset %s", podKey, cachedNpmPod.PodIP, removeIPSetName)
-
 		var toRemoveSet *ipsets.IPSetMetadata
 		if util.IsKeyValueLabelSetName(removeIPSetName) {
 			toRemoveSet = ipsets.NewIPSetMetadata(removeIPSetName, ipsets.KeyValueLabelOfPod)
 		} else {
 			toRemoveSet = ipsets.NewIPSetMetadata(removeIPSetName, ipsets.KeyLabelOfPod)
 		}
-		if err = c.dp.RemoveFromSets([]*ipsets.IPSetMetadata{toRemoveSet}, cachedPodMetadata); err != nil {
-			return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to delete pod from label ipset with err: %w", err)
+		// should have the same IPs for the cached and new pod since from branch above, we have cachedPodIPs == newPodIPs
+		for _, podIP := range cachedPodIPs {
+			klog.Infof("Deleting pod %s (ip : %s) from ipset %s", podKey, podIP, removeIPSetName)
+			cachedPodMetadata := dataplane.NewPodMetadata(podKey, podIP, newPodObj.Spec.NodeName)
+			if err = c.dp.RemoveFromSets([]*ipsets.IPSetMetadata{toRemoveSet}, cachedPodMetadata); err != nil {
+				return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to delete pod from label ipset with err: %w", err)
+			}
 		}
 		// {IMPORTANT} The order of compared list will be key and then key+val. NPM should only append after both key
 		// key + val ipsets are worked on. 0th index will be key and 1st index will be value of the label
@@ -499,9 +509,12 @@ func (c *PodController) syncAddAndUpdatePod(newPodObj *corev1.Pod) (metrics.Oper
 			toAddSet = ipsets.NewIPSetMetadata(addIPSetName, ipsets.KeyLabelOfPod)
 		}
 
-		klog.Infof("Adding pod %s (ip : %s) to ipset %s", podKey, newPodObj.Status.PodIP, addIPSetName)
-		if err = c.dp.AddToSets([]*ipsets.IPSetMetadata{toAddSet}, newPodMetadata); err != nil {
-			return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to add pod to label ipset with err: %w", err)
+		for _, podIP := range newPodIPs {
+			klog.Infof("Adding pod %s (ip : %s) to ipset %s", podKey, podIP, addIPSetName)
+			newPodMetadata := dataplane.NewPodMetadata(podKey, podIP, newPodObj.Spec.NodeName)
+			if err = c.dp.AddToSets([]*ipsets.IPSetMetadata{toAddSet}, newPodMetadata); err != nil {
+				return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to add pod to label ipset with err: %w", err)
+			}
 		}
 		// {IMPORTANT} Same as above order is assumed to be key and then key+val. NPM should only append to existing labels
 		// only after both ipsets for a given label's key value pair are added successfully
@@ -522,16 +535,20 @@ func (c *PodController) syncAddAndUpdatePod(newPodObj *corev1.Pod) (metrics.Oper
 	newPodPorts := common.GetContainerPortList(newPodObj)
 	if !reflect.DeepEqual(cachedNpmPod.ContainerPorts, newPodPorts) {
 		// Delete cached pod's named ports from its ipset.
-		if err = c.manageNamedPortIpsets(
-			cachedNpmPod.ContainerPorts, podKey, cachedNpmPod.PodIP, "", deleteNamedPort); err != nil {
-			return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to delete pod from named port ipset with err: %w", err)
+		for _, podIP := range cachedPodIPs {
+			if err = c.manageNamedPortIpsets(
+				cachedNpmPod.ContainerPorts, podKey, podIP, "", deleteNamedPort); err != nil {
+				return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to delete pod from named port ipset with err: %w", err)
+			}
 		}
 		// Since portList ipset deletion is successful, NPM can remove cachedContainerPorts
 		cachedNpmPod.RemoveContainerPorts()
 
 		// Add new pod's named ports from its ipset.
-		if err = c.manageNamedPortIpsets(newPodPorts, podKey, newPodObj.Status.PodIP, newPodObj.Spec.NodeName, addNamedPort); err != nil {
-			return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to add pod to named port ipset with err: %w", err)
+		for _, podIP := range newPodIPs {
+			if err = c.manageNamedPortIpsets(newPodPorts, podKey, podIP, newPodObj.Spec.NodeName, addNamedPort); err != nil {
+				return metrics.UpdateOp, fmt.Errorf("[syncAddAndUpdatePod] Error: failed to add pod to named port ipset with err: %w", err)
+			}
 		}
 		cachedNpmPod.AppendContainerPorts(newPodObj)
 	}
@@ -550,34 +567,41 @@ func (c *PodController) cleanUpDeletedPod(cachedNpmPodKey string) error {
 	}
 
 	var err error
-	cachedPodMetadata := dataplane.NewPodMetadata(cachedNpmPodKey, cachedNpmPod.PodIP, "")
+	cachedPodIPs := podIPs(cachedNpmPod.PodIP, cachedNpmPod.PodIPs)
 	// Delete the pod from its namespace's ipset.
 	// note: NodeName empty is not going to call update pod
-	if err = c.dp.RemoveFromSets(
-		[]*ipsets.IPSetMetadata{ipsets.NewIPSetMetadata(cachedNpmPod.Namespace, ipsets.Namespace)},
-		cachedPodMetadata); err != nil {
-		return fmt.Errorf("[cleanUpDeletedPod] Error: failed to delete pod from namespace ipset with err: %w", err)
+	for _, podIP := range cachedPodIPs {
+		cachedPodMetadata := dataplane.NewPodMetadata(cachedNpmPodKey, podIP, "")
+		if err = c.dp.RemoveFromSets(
+			[]*ipsets.IPSetMetadata{ipsets.NewIPSetMetadata(cachedNpmPod.Namespace, ipsets.Namespace)},
+			cachedPodMetadata); err != nil {
+			return fmt.Errorf("[cleanUpDeletedPod] Error: failed to delete pod from namespace ipset with err: %w", err)
+		}
 	}
 
 	// Get lists of podLabelKey and podLabelKey + podLavelValue ,and then start deleting them from ipsets
 	for labelKey, labelVal := range cachedNpmPod.Labels {
 		labelKeyValue := util.GetIpSetFromLabelKV(labelKey, labelVal)
-		klog.Infof("Deleting pod %s (ip : %s) from ipsets %s and %s", cachedNpmPodKey, cachedNpmPod.PodIP, labelKey, labelKeyValue)
-		if err = c.dp.RemoveFromSets(
-			[]*ipsets.IPSetMetadata{
-				ipsets.NewIPSetMetadata(labelKey, ipsets.KeyLabelOfPod),
-				ipsets.NewIPSetMetadata(labelKeyValue, ipsets.KeyValueLabelOfPod),
-			},
-			cachedPodMetadata); err != nil {
-			return fmt.Errorf("[cleanUpDeletedPod] Error: failed to delete pod from label ipset with err: %w", err)
+		for _, podIP := range cachedPodIPs {
+			klog.Infof("Deleting pod %s (ip : %s) from ipsets %s and %s", cachedNpmPodKey, podIP, labelKey, labelKeyValue)
+			if err = c.dp.RemoveFromSets(
+				[]*ipsets.IPSetMetadata{
+					ipsets.NewIPSetMetadata(labelKey, ipsets.KeyLabelOfPod),
+					ipsets.NewIPSetMetadata(labelKeyValue, ipsets.KeyValueLabelOfPod),
+				},
+				dataplane.NewPodMetadata(cachedNpmPodKey, podIP, "")); err != nil {
+				return fmt.Errorf("[cleanUpDeletedPod] Error: failed to delete pod from label ipset with err: %w", err)
+			}
 		}
 		cachedNpmPod.RemoveLabelsWithKey(labelKey)
 	}
 
 	// Delete pod's named ports from its ipset. Need to pass true in the manageNamedPortIpsets function call
-	if err = c.manageNamedPortIpsets(
-		cachedNpmPod.ContainerPorts, cachedNpmPodKey, cachedNpmPod.PodIP, "", deleteNamedPort); err != nil {
-		return fmt.Errorf("[cleanUpDeletedPod] Error: failed to delete pod from named port ipset with err: %w", err)
+	for _, podIP := range cachedPodIPs {
+		if err = c.manageNamedPortIpsets(
+			cachedNpmPod.ContainerPorts, cachedNpmPodKey, podIP, "", deleteNamedPort); err != nil {
+			return fmt.Errorf("[cleanUpDeletedPod] Error: failed to delete pod from named port ipset with err: %w", err)
+		}
 	}
 
 	metrics.RemovePod()
@@ -646,6 +670,28 @@ func isCompletePod(podObj *corev1.Pod) bool {
 	return false
 }
 
+// podIPs returns the IPs of a pod which NPM tracks in ipsets: the primary podIP,
+// and in Linux, the IP of the other family for a dual-stack pod. Windows only supports IPv4.
+// Returns nil if the primary podIP isn't supported (e.g. if it's empty).
+func podIPs(podIP string, allPodIPs []string) []string {
+	primaryIsIPv4 := util.IsIPV4(podIP)
+	if util.IsWindowsDP() || (!primaryIsIPv4 && !util.IsIPV6(podIP)) {
+		if primaryIsIPv4 {
+			return []string{podIP}
+		}
+		return nil
+	}
+
+	ips := []string{podIP}
+	for _, ip := range allPodIPs {
+		// dual-stack pods have at most one IP per family
+		if (primaryIsIPv4 && util.IsIPV6(ip)) || (!primaryIsIPv4 && util.IsIPV4(ip)) {
+			return append(ips, ip)
+		}
+	}
+	return ips
+}
+
 func hasValidPodIP(podObj *corev1.Pod) bool {
 	return len(podObj.Status.PodIP) > 0
 }
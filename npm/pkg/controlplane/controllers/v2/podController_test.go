@@ -1039,6 +1039,123 @@ func TestPodMapMarshalJSON(t *testing.T) {
 	assert.ElementsMatch(t, expect, npMapRaw)
 }
 
+func TestAddAndDeleteDualStackPod(t *testing.T) {
+	labels := map[string]string{
+		"app": "test-pod",
+	}
+	podObj := createPod("test-pod", "test-namespace", "0", "1.2.3.4", labels, NonHostNetwork, corev1.PodRunning)
+	podObj.Status.PodIPs = []corev1.PodIP{{IP: "1.2.3.4"}, {IP: "2001:db8::4"}}
+	podKey := getKey(podObj, t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dp := dpmocks.NewMockGenericDataplane(ctrl)
+	f := newFixture(t, dp)
+	f.podLister = append(f.podLister, podObj)
+	f.kubeobjects = append(f.kubeobjects, podObj)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.newPodController(stopCh)
+
+	mockIPSets := []*ipsets.IPSetMetadata{
+		ipsets.NewIPSetMetadata("test-namespace", ipsets.Namespace),
+		ipsets.NewIPSetMetadata("app", ipsets.KeyLabelOfPod),
+		ipsets.NewIPSetMetadata("app:test-pod", ipsets.KeyValueLabelOfPod),
+	}
+	namedPortSet := []*ipsets.IPSetMetadata{ipsets.NewIPSetMetadata("app:test-pod", ipsets.NamedPorts)}
+	podIPs := []string{"1.2.3.4"}
+	if !util.IsWindowsDP() {
+		// Windows only supports IPv4
+		podIPs = append(podIPs, "2001:db8::4")
+	}
+
+	dp.EXPECT().AddToLists([]*ipsets.IPSetMetadata{kubeAllNamespaces}, mockIPSets[:1]).Return(nil).Times(1)
+	dp.EXPECT().ApplyDataPlane().Return(nil).Times(2)
+	for _, podIP := range podIPs {
+		podMetadata := dataplane.NewPodMetadata("test-namespace/test-pod", podIP, "")
+		dp.EXPECT().AddToSets(mockIPSets[:1], podMetadata).Return(nil).Times(1)
+		dp.EXPECT().AddToSets(mockIPSets[1:], podMetadata).Return(nil).Times(1)
+		dp.EXPECT().RemoveFromSets(mockIPSets[:1], podMetadata).Return(nil).Times(1)
+		dp.EXPECT().RemoveFromSets(mockIPSets[1:], podMetadata).Return(nil).Times(1)
+		if !util.IsWindowsDP() {
+			namedPortMetadata := dataplane.NewPodMetadata("test-namespace/test-pod", podIP+",8080", "")
+			dp.EXPECT().AddToSets(namedPortSet, namedPortMetadata).Return(nil).Times(1)
+			dp.EXPECT().RemoveFromSets(namedPortSet, namedPortMetadata).Return(nil).Times(1)
+		}
+	}
+
+	deletePod(t, f, podObj, DeletedFinalStateknownObject)
+	// sleep in case rate limiter adds back to workqueue
+	time.Sleep(sleepDurationForRateLimiter)
+	checkPodTestResult("TestAddAndDeleteDualStackPod", f, []expectedValues{{0, 1, 0, podPromVals{0, 1, 0, 1, 0, 0, 0}}})
+	if _, exists := f.podController.podMap[podKey]; exists {
+		t.Error("TestAddAndDeleteDualStackPod failed @ cached pod obj exists check")
+	}
+}
+
+func TestPodIPs(t *testing.T) {
+	tests := []struct {
+		name       string
+		podIP      string
+		allPodIPs  []string
+		wantLinux  []string
+		wantWindow []string
+	}{
+		{
+			name:       "empty",
+			podIP:      "",
+			wantLinux:  nil,
+			wantWindow: nil,
+		},
+		{
+			name:       "ipv4",
+			podIP:      "1.2.3.4",
+			allPodIPs:  []string{"1.2.3.4"},
+			wantLinux:  []string{"1.2.3.4"},
+			wantWindow: []string{"1.2.3.4"},
+		},
+		{
+			name:       "ipv6",
+			podIP:      "2001:db8::4",
+			allPodIPs:  []string{"2001:db8::4"},
+			wantLinux:  []string{"2001:db8::4"},
+			wantWindow: nil,
+		},
+		{
+			name:       "dual-stack with ipv4 primary",
+			podIP:      "1.2.3.4",
+			allPodIPs:  []string{"1.2.3.4", "2001:db8::4"},
+			wantLinux:  []string{"1.2.3.4", "2001:db8::4"},
+			wantWindow: []string{"1.2.3.4"},
+		},
+		{
+			name:       "dual-stack with ipv6 primary",
+			podIP:      "2001:db8::4",
+			allPodIPs:  []string{"2001:db8::4", "1.2.3.4"},
+			wantLinux:  []string{"2001:db8::4", "1.2.3.4"},
+			wantWindow: nil,
+		},
+		{
+			name:       "invalid secondary ip",
+			podIP:      "1.2.3.4",
+			allPodIPs:  []string{"1.2.3.4", "invalid"},
+			wantLinux:  []string{"1.2.3.4"},
+			wantWindow: []string{"1.2.3.4"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.wantLinux
+			if util.IsWindowsDP() {
+				want = tt.wantWindow
+			}
+			require.Equal(t, want, podIPs(tt.podIP, tt.allPodIPs))
+		})
+	}
+}
+
 func TestHasValidPodIP(t *testing.T) {
 	podObj := &corev1.Pod{
 		Status: corev1.PodStatus{
@@ -0,0 +1,302 @@
+package translation
+
+import (
+	"errors"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	anpv1alpha1 "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+)
+
+var (
+	// ErrUnsupportedAdminNetworkPolicy is returned when AdminNetworkPolicy or BaselineAdminNetworkPolicy is used in windows.
+	ErrUnsupportedAdminNetworkPolicy = errors.New("unsupported AdminNetworkPolicy or BaselineAdminNetworkPolicy used on windows")
+	// ErrUnsupportedSameLabels is returned when sameLabels or notSameLabels is used in an AdminNetworkPolicy peer.
+	ErrUnsupportedSameLabels    = errors.New("unsupported sameLabels or notSameLabels in AdminNetworkPolicy peer")
+	errMissingNamespaceSelector = errors.New("namespaces peer has no namespaceSelector")
+	errUnknownAdminAction       = errors.New("unknown AdminNetworkPolicy rule action")
+	errUnknownAdminPort         = errors.New("AdminNetworkPolicy port has no portNumber, namedPort, or portRange")
+)
+
+/*
+Admin tier policies have no policy chains, so there's no PodSelectorList to jump on.
+Instead, every ACL matches the subject as well as the peer:
+- ingress ACLs have the subject in DstList and the peer in SrcList.
+- egress ACLs have the subject in SrcList and the peer in DstList.
+A subject or peer with multiple (flattened) namespace selectors results in an ACL for each combination.
+*/
+
+// TranslateAdminNetworkPolicy translates an AdminNetworkPolicy object to an NPMNetworkPolicy in the AdminTier.
+func TranslateAdminNetworkPolicy(anp *anpv1alpha1.AdminNetworkPolicy) (*policies.NPMNetworkPolicy, error) {
+	if util.IsWindowsDP() {
+		return nil, ErrUnsupportedAdminNetworkPolicy
+	}
+
+	npmNetPol := policies.NewAdminNPMNetworkPolicy(policies.AdminTier, anp.Name, anp.Spec.Priority)
+	subjects, err := adminSubject(npmNetPol, &anp.Spec.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range anp.Spec.Ingress {
+		rule := &anp.Spec.Ingress[i]
+		verdict, err := adminVerdict(string(rule.Action))
+		if err != nil {
+			return nil, err
+		}
+		if err := adminRule(npmNetPol, verdict, policies.Ingress, subjects, rule.From, rule.Ports); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range anp.Spec.Egress {
+		rule := &anp.Spec.Egress[i]
+		verdict, err := adminVerdict(string(rule.Action))
+		if err != nil {
+			return nil, err
+		}
+		if err := adminRule(npmNetPol, verdict, policies.Egress, subjects, rule.To, rule.Ports); err != nil {
+			return nil, err
+		}
+	}
+	return npmNetPol, nil
+}
+
+// TranslateBaselineAdminNetworkPolicy translates a BaselineAdminNetworkPolicy object to an NPMNetworkPolicy in the BaselineTier.
+func TranslateBaselineAdminNetworkPolicy(banp *anpv1alpha1.BaselineAdminNetworkPolicy) (*policies.NPMNetworkPolicy, error) {
+	if util.IsWindowsDP() {
+		return nil, ErrUnsupportedAdminNetworkPolicy
+	}
+
+	// there's only one BaselineAdminNetworkPolicy, so it has no priority
+	npmNetPol := policies.NewAdminNPMNetworkPolicy(policies.BaselineTier, banp.Name, 0)
+	subjects, err := adminSubject(npmNetPol, &banp.Spec.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range banp.Spec.Ingress {
+		rule := &banp.Spec.Ingress[i]
+		verdict, err := baselineAdminVerdict(rule.Action)
+		if err != nil {
+			return nil, err
+		}
+		if err := adminRule(npmNetPol, verdict, policies.Ingress, subjects, rule.From, rule.Ports); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range banp.Spec.Egress {
+		rule := &banp.Spec.Egress[i]
+		verdict, err := baselineAdminVerdict(rule.Action)
+		if err != nil {
+			return nil, err
+		}
+		if err := adminRule(npmNetPol, verdict, policies.Egress, subjects, rule.To, rule.Ports); err != nil {
+			return nil, err
+		}
+	}
+	return npmNetPol, nil
+}
+
+// adminVerdict returns the verdict for an AdminNetworkPolicy or BaselineAdminNetworkPolicy rule action.
+func adminVerdict(action string) (policies.Verdict, error) {
+	switch action {
+	case string(anpv1alpha1.AdminNetworkPolicyRuleActionAllow):
+		return policies.Allowed, nil
+	case string(anpv1alpha1.AdminNetworkPolicyRuleActionDeny):
+		return policies.Dropped, nil
+	case string(anpv1alpha1.AdminNetworkPolicyRuleActionPass):
+		return policies.Passed, nil
+	default:
+		return "", errUnknownAdminAction
+	}
+}
+
+// baselineAdminVerdict returns the verdict for a BaselineAdminNetworkPolicy rule action, which can't be Pass.
+func baselineAdminVerdict(action anpv1alpha1.BaselineAdminNetworkPolicyRuleAction) (policies.Verdict, error) {
+	if action == anpv1alpha1.BaselineAdminNetworkPolicyRuleAction(anpv1alpha1.AdminNetworkPolicyRuleActionPass) {
+		return "", errUnknownAdminAction
+	}
+	return adminVerdict(string(action))
+}
+
+// adminSubject adds the subject's IPSets to the policy's pod selector IPSets,
+// and returns the SetInfos for each way the subject can be matched.
+func adminSubject(npmNetPol *policies.NPMNetworkPolicy, subject *anpv1alpha1.AdminNetworkPolicySubject) ([][]policies.SetInfo, error) {
+	var nsSelector *metav1.LabelSelector
+	var psResult *podSelectorResult
+	if subject.Pods != nil {
+		var err error
+		psResult, err = podSelector(npmNetPol.PolicyKey, policies.EitherMatch, &subject.Pods.PodSelector)
+		if err != nil {
+			return nil, err
+		}
+		npmNetPol.PodSelectorIPSets = append(npmNetPol.PodSelectorIPSets, psResult.psSets...)
+		npmNetPol.ChildPodSelectorIPSets = append(npmNetPol.ChildPodSelectorIPSets, psResult.childPSSets...)
+		nsSelector = &subject.Pods.NamespaceSelector
+	} else {
+		// a subject with neither namespaces nor pods selects all Pods
+		nsSelector = &metav1.LabelSelector{}
+		if subject.Namespaces != nil {
+			nsSelector = subject.Namespaces
+		}
+	}
+
+	// Before translating NamespaceSelector, flattenNameSpaceSelector function call should be called
+	// to handle multiple values in matchExpressions spec.
+	flattenNSSelector, err := flattenNameSpaceSelector(nsSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := make([][]policies.SetInfo, 0, len(flattenNSSelector))
+	for i := range flattenNSSelector {
+		nsSelectorIPSets, nsSelectorList := nameSpaceSelector(policies.EitherMatch, &flattenNSSelector[i])
+		npmNetPol.PodSelectorIPSets = append(npmNetPol.PodSelectorIPSets, nsSelectorIPSets...)
+		if psResult != nil {
+			nsSelectorList = append(nsSelectorList, psResult.psList...)
+		}
+		subjects = append(subjects, nsSelectorList)
+	}
+	return subjects, nil
+}
+
+// adminPeers adds the peers' IPSets to the policy's rule IPSets,
+// and returns the SetInfos for each way the peers can be matched.
+func adminPeers(npmNetPol *policies.NPMNetworkPolicy, matchType policies.MatchType, peers []anpv1alpha1.AdminNetworkPolicyPeer) ([][]policies.SetInfo, error) {
+	peerInfos := make([][]policies.SetInfo, 0, len(peers))
+	for i := range peers {
+		var namespaces *anpv1alpha1.NamespacedPeer
+		var psResult *podSelectorResult
+		switch {
+		case peers[i].Namespaces != nil:
+			namespaces = peers[i].Namespaces
+		case peers[i].Pods != nil:
+			var err error
+			psResult, err = podSelector(npmNetPol.PolicyKey, matchType, &peers[i].Pods.PodSelector)
+			if err != nil {
+				return nil, err
+			}
+			npmNetPol.RuleIPSets = append(npmNetPol.RuleIPSets, psResult.psSets...)
+			npmNetPol.RuleIPSets = append(npmNetPol.RuleIPSets, psResult.childPSSets...)
+			namespaces = &peers[i].Pods.Namespaces
+		default:
+			continue
+		}
+
+		if len(namespaces.SameLabels) > 0 || len(namespaces.NotSameLabels) > 0 {
+			return nil, ErrUnsupportedSameLabels
+		}
+		if namespaces.NamespaceSelector == nil {
+			return nil, errMissingNamespaceSelector
+		}
+
+		flattenNSSelector, err := flattenNameSpaceSelector(namespaces.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range flattenNSSelector {
+			nsSelectorIPSets, nsSelectorList := nameSpaceSelector(matchType, &flattenNSSelector[j])
+			npmNetPol.RuleIPSets = append(npmNetPol.RuleIPSets, nsSelectorIPSets...)
+			if psResult != nil {
+				nsSelectorList = append(nsSelectorList, psResult.psList...)
+			}
+			peerInfos = append(peerInfos, nsSelectorList)
+		}
+	}
+	return peerInfos, nil
+}
+
+// adminRule adds an ACL for each combination of subject, peer, and port.
+func adminRule(npmNetPol *policies.NPMNetworkPolicy, verdict policies.Verdict, direction policies.Direction, subjects [][]policies.SetInfo,
+	peers []anpv1alpha1.AdminNetworkPolicyPeer, ports *[]anpv1alpha1.AdminNetworkPolicyPort,
+) error {
+	subjectMatchType := policies.DstMatch
+	peerMatchType := policies.SrcMatch
+	if direction == policies.Egress {
+		subjectMatchType = policies.SrcMatch
+		peerMatchType = policies.DstMatch
+	}
+
+	peerInfos, err := adminPeers(npmNetPol, peerMatchType, peers)
+	if err != nil {
+		return err
+	}
+
+	// a rule without ports applies to all ports
+	var portRules []anpv1alpha1.AdminNetworkPolicyPort
+	if ports != nil {
+		portRules = *ports
+	}
+
+	for _, subjectInfos := range subjects {
+		subjectInfos = withMatchType(subjectInfos, subjectMatchType)
+		for _, peerSetInfos := range peerInfos {
+			if len(portRules) == 0 {
+				npmNetPol.ACLs = append(npmNetPol.ACLs, adminACL(verdict, direction, subjectInfos, peerSetInfos))
+				continue
+			}
+
+			for i := range portRules {
+				acl := adminACL(verdict, direction, subjectInfos, peerSetInfos)
+				if err := adminPortRule(npmNetPol, acl, &portRules[i]); err != nil {
+					return err
+				}
+				npmNetPol.ACLs = append(npmNetPol.ACLs, acl)
+			}
+		}
+	}
+	return nil
+}
+
+func adminACL(verdict policies.Verdict, direction policies.Direction, subjectInfos, peerInfos []policies.SetInfo) *policies.ACLPolicy {
+	acl := policies.NewACLPolicy(verdict, direction)
+	acl.AddSetInfo(peerInfos)
+	// AddSetInfo() only adds to the peer's side
+	if direction == policies.Ingress {
+		acl.DstList = append(acl.DstList, subjectInfos...)
+	} else {
+		acl.SrcList = append(acl.SrcList, subjectInfos...)
+	}
+	return acl
+}
+
+// adminPortRule sets the destination port(s) of the ACL. Port is always applied to destination side.
+func adminPortRule(npmNetPol *policies.NPMNetworkPolicy, acl *policies.ACLPolicy, port *anpv1alpha1.AdminNetworkPolicyPort) error {
+	switch {
+	case port.PortNumber != nil:
+		acl.Protocol = adminProtocol(port.PortNumber.Protocol)
+		acl.DstPorts = policies.Ports{Port: port.PortNumber.Port, EndPort: port.PortNumber.Port}
+	case port.PortRange != nil:
+		acl.Protocol = adminProtocol(port.PortRange.Protocol)
+		acl.DstPorts = policies.Ports{Port: port.PortRange.Start, EndPort: port.PortRange.End}
+	case port.NamedPort != nil:
+		// the named port IPSet includes the protocol
+		npmNetPol.RuleIPSets = append(npmNetPol.RuleIPSets, ipsets.NewTranslatedIPSet(*port.NamedPort, ipsets.NamedPorts))
+		acl.AddSetInfo([]policies.SetInfo{policies.NewSetInfo(*port.NamedPort, ipsets.NamedPorts, included, policies.DstDstMatch)})
+	default:
+		return errUnknownAdminPort
+	}
+	return nil
+}
+
+func adminProtocol(protocol v1.Protocol) policies.Protocol {
+	if protocol == "" {
+		return policies.TCP
+	}
+	return policies.Protocol(protocol)
+}
+
+func withMatchType(infos []policies.SetInfo, matchType policies.MatchType) []policies.SetInfo {
+	result := make([]policies.SetInfo, len(infos))
+	for i, info := range infos {
+		info.MatchType = matchType
+		result[i] = info
+	}
+	return result
+}
@@ -0,0 +1,323 @@
+package translation
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	anpv1alpha1 "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+)
+
+func TestTranslateAdminNetworkPolicy(t *testing.T) {
+	if util.IsWindowsDP() {
+		return
+	}
+
+	namedPort := "serve-tcp"
+	tests := []struct {
+		name      string
+		anp       *anpv1alpha1.AdminNetworkPolicy
+		npmNetPol *policies.NPMNetworkPolicy
+		wantErr   error
+	}{
+		{
+			name: "deny ingress from namespace to subject namespace",
+			anp: &anpv1alpha1.AdminNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "isolate-tenant"},
+				Spec: anpv1alpha1.AdminNetworkPolicySpec{
+					Priority: 10,
+					Subject: anpv1alpha1.AdminNetworkPolicySubject{
+						Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+					},
+					Ingress: []anpv1alpha1.AdminNetworkPolicyIngressRule{
+						{
+							Action: anpv1alpha1.AdminNetworkPolicyRuleActionDeny,
+							From: []anpv1alpha1.AdminNetworkPolicyPeer{
+								{
+									Namespaces: &anpv1alpha1.NamespacedPeer{
+										NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			npmNetPol: &policies.NPMNetworkPolicy{
+				PolicyKey: "AdminNetworkPolicy/isolate-tenant",
+				Tier:      policies.AdminTier,
+				Priority:  10,
+				PodSelectorIPSets: []*ipsets.TranslatedIPSet{
+					ipsets.NewTranslatedIPSet("tenant:a", ipsets.KeyValueLabelOfNamespace),
+				},
+				RuleIPSets: []*ipsets.TranslatedIPSet{
+					ipsets.NewTranslatedIPSet("tenant:b", ipsets.KeyValueLabelOfNamespace),
+				},
+				ACLs: []*policies.ACLPolicy{
+					{
+						Target:    policies.Dropped,
+						Direction: policies.Ingress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo("tenant:b", ipsets.KeyValueLabelOfNamespace, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo("tenant:a", ipsets.KeyValueLabelOfNamespace, included, policies.DstMatch),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "allow egress to pods on ports and pass the rest",
+			anp: &anpv1alpha1.AdminNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "allow-dns"},
+				Spec: anpv1alpha1.AdminNetworkPolicySpec{
+					Priority: 1,
+					Subject: anpv1alpha1.AdminNetworkPolicySubject{
+						Pods: &anpv1alpha1.NamespacedPodSubject{
+							PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+						},
+					},
+					Egress: []anpv1alpha1.AdminNetworkPolicyEgressRule{
+						{
+							Action: anpv1alpha1.AdminNetworkPolicyRuleActionAllow,
+							To: []anpv1alpha1.AdminNetworkPolicyPeer{
+								{
+									Pods: &anpv1alpha1.NamespacedPodPeer{
+										Namespaces:  anpv1alpha1.NamespacedPeer{NamespaceSelector: &metav1.LabelSelector{}},
+										PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+									},
+								},
+							},
+							Ports: &[]anpv1alpha1.AdminNetworkPolicyPort{
+								{PortNumber: &anpv1alpha1.Port{Protocol: v1.ProtocolUDP, Port: 53}},
+								{PortRange: &anpv1alpha1.PortRange{Start: 8000, End: 8100}},
+								{NamedPort: &namedPort},
+							},
+						},
+						{
+							Action: anpv1alpha1.AdminNetworkPolicyRuleActionPass,
+							To: []anpv1alpha1.AdminNetworkPolicyPeer{
+								{
+									Namespaces: &anpv1alpha1.NamespacedPeer{NamespaceSelector: &metav1.LabelSelector{}},
+								},
+							},
+						},
+					},
+				},
+			},
+			npmNetPol: &policies.NPMNetworkPolicy{
+				PolicyKey: "AdminNetworkPolicy/allow-dns",
+				Tier:      policies.AdminTier,
+				Priority:  1,
+				PodSelectorIPSets: []*ipsets.TranslatedIPSet{
+					ipsets.NewTranslatedIPSet("app:web", ipsets.KeyValueLabelOfPod),
+					ipsets.NewTranslatedIPSet(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace),
+				},
+				RuleIPSets: []*ipsets.TranslatedIPSet{
+					ipsets.NewTranslatedIPSet("k8s-app:kube-dns", ipsets.KeyValueLabelOfPod),
+					ipsets.NewTranslatedIPSet(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace),
+					ipsets.NewTranslatedIPSet(namedPort, ipsets.NamedPorts),
+					ipsets.NewTranslatedIPSet(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace),
+				},
+				ACLs: []*policies.ACLPolicy{
+					{
+						Target:    policies.Allowed,
+						Direction: policies.Egress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+							policies.NewSetInfo("app:web", ipsets.KeyValueLabelOfPod, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.DstMatch),
+							policies.NewSetInfo("k8s-app:kube-dns", ipsets.KeyValueLabelOfPod, included, policies.DstMatch),
+						},
+						Protocol: policies.UDP,
+						DstPorts: policies.Ports{Port: 53, EndPort: 53},
+					},
+					{
+						Target:    policies.Allowed,
+						Direction: policies.Egress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+							policies.NewSetInfo("app:web", ipsets.KeyValueLabelOfPod, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.DstMatch),
+							policies.NewSetInfo("k8s-app:kube-dns", ipsets.KeyValueLabelOfPod, included, policies.DstMatch),
+						},
+						Protocol: policies.TCP,
+						DstPorts: policies.Ports{Port: 8000, EndPort: 8100},
+					},
+					{
+						Target:    policies.Allowed,
+						Direction: policies.Egress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+							policies.NewSetInfo("app:web", ipsets.KeyValueLabelOfPod, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.DstMatch),
+							policies.NewSetInfo("k8s-app:kube-dns", ipsets.KeyValueLabelOfPod, included, policies.DstMatch),
+							policies.NewSetInfo(namedPort, ipsets.NamedPorts, included, policies.DstDstMatch),
+						},
+					},
+					{
+						Target:    policies.Passed,
+						Direction: policies.Egress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+							policies.NewSetInfo("app:web", ipsets.KeyValueLabelOfPod, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.DstMatch),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "subject namespaces with multiple values",
+			anp: &anpv1alpha1.AdminNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "multi"},
+				Spec: anpv1alpha1.AdminNetworkPolicySpec{
+					Priority: 5,
+					Subject: anpv1alpha1.AdminNetworkPolicySubject{
+						Namespaces: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: "tenant", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+							},
+						},
+					},
+					Ingress: []anpv1alpha1.AdminNetworkPolicyIngressRule{
+						{
+							Action: anpv1alpha1.AdminNetworkPolicyRuleActionAllow,
+							From: []anpv1alpha1.AdminNetworkPolicyPeer{
+								{Namespaces: &anpv1alpha1.NamespacedPeer{NamespaceSelector: &metav1.LabelSelector{}}},
+							},
+						},
+					},
+				},
+			},
+			npmNetPol: &policies.NPMNetworkPolicy{
+				PolicyKey: "AdminNetworkPolicy/multi",
+				Tier:      policies.AdminTier,
+				Priority:  5,
+				PodSelectorIPSets: []*ipsets.TranslatedIPSet{
+					ipsets.NewTranslatedIPSet("tenant:a", ipsets.KeyValueLabelOfNamespace),
+					ipsets.NewTranslatedIPSet("tenant:b", ipsets.KeyValueLabelOfNamespace),
+				},
+				RuleIPSets: []*ipsets.TranslatedIPSet{
+					ipsets.NewTranslatedIPSet(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace),
+				},
+				ACLs: []*policies.ACLPolicy{
+					{
+						Target:    policies.Allowed,
+						Direction: policies.Ingress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo("tenant:a", ipsets.KeyValueLabelOfNamespace, included, policies.DstMatch),
+						},
+					},
+					{
+						Target:    policies.Allowed,
+						Direction: policies.Ingress,
+						SrcList: []policies.SetInfo{
+							policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+						},
+						DstList: []policies.SetInfo{
+							policies.NewSetInfo("tenant:b", ipsets.KeyValueLabelOfNamespace, included, policies.DstMatch),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "sameLabels is unsupported",
+			anp: &anpv1alpha1.AdminNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "same-labels"},
+				Spec: anpv1alpha1.AdminNetworkPolicySpec{
+					Subject: anpv1alpha1.AdminNetworkPolicySubject{Namespaces: &metav1.LabelSelector{}},
+					Ingress: []anpv1alpha1.AdminNetworkPolicyIngressRule{
+						{
+							Action: anpv1alpha1.AdminNetworkPolicyRuleActionAllow,
+							From: []anpv1alpha1.AdminNetworkPolicyPeer{
+								{Namespaces: &anpv1alpha1.NamespacedPeer{SameLabels: []string{"tenant"}}},
+							},
+						},
+					},
+				},
+			},
+			wantErr: ErrUnsupportedSameLabels,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			npmNetPol, err := TranslateAdminNetworkPolicy(tt.anp)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.npmNetPol, npmNetPol)
+		})
+	}
+}
+
+func TestTranslateBaselineAdminNetworkPolicy(t *testing.T) {
+	if util.IsWindowsDP() {
+		return
+	}
+
+	banp := &anpv1alpha1.BaselineAdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: anpv1alpha1.BaselineAdminNetworkPolicySpec{
+			Subject: anpv1alpha1.AdminNetworkPolicySubject{Namespaces: &metav1.LabelSelector{}},
+			Ingress: []anpv1alpha1.BaselineAdminNetworkPolicyIngressRule{
+				{
+					Action: anpv1alpha1.BaselineAdminNetworkPolicyRuleActionDeny,
+					From: []anpv1alpha1.AdminNetworkPolicyPeer{
+						{Namespaces: &anpv1alpha1.NamespacedPeer{NamespaceSelector: &metav1.LabelSelector{}}},
+					},
+				},
+			},
+		},
+	}
+	expected := &policies.NPMNetworkPolicy{
+		PolicyKey: "BaselineAdminNetworkPolicy/default",
+		Tier:      policies.BaselineTier,
+		PodSelectorIPSets: []*ipsets.TranslatedIPSet{
+			ipsets.NewTranslatedIPSet(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace),
+		},
+		RuleIPSets: []*ipsets.TranslatedIPSet{
+			ipsets.NewTranslatedIPSet(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace),
+		},
+		ACLs: []*policies.ACLPolicy{
+			{
+				Target:    policies.Dropped,
+				Direction: policies.Ingress,
+				SrcList: []policies.SetInfo{
+					policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.SrcMatch),
+				},
+				DstList: []policies.SetInfo{
+					policies.NewSetInfo(util.KubeAllNamespacesFlag, ipsets.KeyLabelOfNamespace, included, policies.DstMatch),
+				},
+			},
+		},
+	}
+	npmNetPol, err := TranslateBaselineAdminNetworkPolicy(banp)
+	require.NoError(t, err)
+	require.Equal(t, expected, npmNetPol)
+
+	banp.Spec.Ingress[0].Action = anpv1alpha1.BaselineAdminNetworkPolicyRuleAction(anpv1alpha1.AdminNetworkPolicyRuleActionPass)
+	_, err = TranslateBaselineAdminNetworkPolicy(banp)
+	require.ErrorIs(t, err, errUnknownAdminAction)
+}
@@ -32,8 +32,14 @@ var (
 	ErrInvalidMatchExpressionValues = errors.New(
 		"matchExpression label values must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character",
 	)
-	// ErrUnsupportedIPAddress is returned when an unsupported IP address, such as IPV6, is used
+	// ErrUnsupportedIPAddress is returned when an unsupported IP address, such as IPV6 in windows, is used
 	ErrUnsupportedIPAddress = errors.New("unsupported IP address")
+
+	// splitCIDRsForAllIPs maps the CIDRs covering all IPs (which ipset doesn't allow) to their two halves
+	splitCIDRsForAllIPs = map[string][]string{
+		"0.0.0.0/0": {"0.0.0.0/1", "128.0.0.0/1"},
+		"::/0":      {"::/1", "8000::/1"},
+	}
 )
 
 type podSelectorResult struct {
@@ -179,17 +185,16 @@ func ipBlockIPSet(policyName, ns string, direction policies.Direction, ipBlockSe
 
 	var members []string
 	indexOfMembers := 0
-	// Ipset doesn't allow 0.0.0.0/0 to be added.
-	// A solution is split 0.0.0.0/0 in half which convert to 0.0.0.0/1 and 128.0.0.0/1.
+	// Ipset doesn't allow 0.0.0.0/0 (or ::/0) to be added.
+	// A solution is split 0.0.0.0/0 in half which convert to 0.0.0.0/1 and 128.0.0.0/1 (and ::/0 to ::/1 and 8000::/1).
 	// splitCIDRSet is used to handle case where IPBlock has "0.0.0.0/0" in CIDR and "0.0.0.0/1" or "128.0.0.0/1"  in Except.
 	// splitCIDRSet has two entries ("0.0.0.0/1" and "128.0.0.0/1") as key.
 	splitCIDRLen := 2
 	splitCIDRSet := make(map[string]int, splitCIDRLen)
-	if ipBlockRule.CIDR == "0.0.0.0/0" {
+	if splitCIDRs, ok := splitCIDRsForAllIPs[ipBlockRule.CIDR]; ok {
 		// two cidrs (0.0.0.0/1 and 128.0.0.0/1) for 0.0.0.0/0 + except.
 		members = make([]string, lenOfDeDupExcepts+splitCIDRLen)
 		// in case of "0.0.0.0/0", "0.0.0.0/1" or "0.0.0.0/1 nomatch" comes eariler than "128.0.0.0/1" or "128.0.0.0/1 nomatch".
-		splitCIDRs := []string{"0.0.0.0/1", "128.0.0.0/1"}
 		for _, cidr := range splitCIDRs {
 			members[indexOfMembers] = cidr
 			splitCIDRSet[cidr] = indexOfMembers
@@ -227,7 +232,8 @@ func ipBlockRule(policyName, ns string, direction policies.Direction, matchType
 		return nil, policies.SetInfo{}, nil
 	}
 
-	if !util.IsIPV4(ipBlockRule.CIDR) {
+	// IPv6 is only supported in Linux
+	if !util.IsIPV4(ipBlockRule.CIDR) && (util.IsWindowsDP() || !util.IsIPV6(ipBlockRule.CIDR)) {
 		return nil, policies.SetInfo{}, ErrUnsupportedIPAddress
 	}
 
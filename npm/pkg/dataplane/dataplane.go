@@ -8,6 +8,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
 	"github.com/Azure/azure-container-networking/npm/util"
@@ -515,6 +516,10 @@ func (dp *DataPlane) addPolicies(netPols []*policies.NPMNetworkPolicy) error {
 
 		// not in bootup phase
 		// this codepath is always taken in Linux
+		if dp.appliesIPSetsWithPolicies() {
+			// the IPSets are applied in the same transaction as the policies
+			continue
+		}
 		err = dp.applyDataPlaneNow(contextAddNetPol)
 		if err != nil {
 			return err
@@ -537,6 +542,11 @@ func (dp *DataPlane) addPolicies(netPols []*policies.NPMNetworkPolicy) error {
 		return fmt.Errorf("[DataPlane] [%s] error while adding policies: %w", contextAddNetPolBootup, err)
 	}
 
+	if dp.appliesIPSetsWithPolicies() {
+		// apply the IPSets of policies without ACLs, which the PolicyManager skips. Otherwise, there's nothing left to apply.
+		return dp.applyDataPlaneNow(contextAddNetPol)
+	}
+
 	return nil
 }
 
@@ -567,6 +577,13 @@ func (dp *DataPlane) RemovePolicy(policyKey string) error {
 		endpoints[podIP] = endpointID
 	}
 
+	if dp.appliesIPSetsWithPolicies() {
+		// the IPSets are deleted in the same transaction as the policy, after its rules
+		if err := dp.deletePolicyIPSetsAndReferences(policy); err != nil {
+			return err
+		}
+	}
+
 	// Use the endpoint list saved in cache for this network policy to remove
 	err := dp.policyMgr.RemovePolicy(policy.PolicyKey)
 	if err != nil {
@@ -591,19 +608,24 @@ func (dp *DataPlane) RemovePolicy(policyKey string) error {
 
 	}
 
-	// Remove references for Rule IPSets first
-	err = dp.deleteIPSetsAndReferences(policy.RuleIPSets, policy.PolicyKey, ipsets.NetPolType)
-	if err != nil {
-		return err
+	if !dp.appliesIPSetsWithPolicies() {
+		if err := dp.deletePolicyIPSetsAndReferences(policy); err != nil {
+			return err
+		}
 	}
 
-	// Remove references for Selector IPSets
-	err = dp.deleteIPSetsAndReferences(policy.AllPodSelectorIPSets(), policy.PolicyKey, ipsets.SelectorType)
+	return dp.applyDataPlaneNow(contextApplyDP)
+}
+
+func (dp *DataPlane) deletePolicyIPSetsAndReferences(policy *policies.NPMNetworkPolicy) error {
+	// Remove references for Rule IPSets first
+	err := dp.deleteIPSetsAndReferences(policy.RuleIPSets, policy.PolicyKey, ipsets.NetPolType)
 	if err != nil {
 		return err
 	}
 
-	return dp.applyDataPlaneNow(contextApplyDP)
+	// Remove references for Selector IPSets
+	return dp.deleteIPSetsAndReferences(policy.AllPodSelectorIPSets(), policy.PolicyKey, ipsets.SelectorType)
 }
 
 // UpdatePolicy takes in updated policy object, calculates the delta and applies changes
@@ -636,6 +658,16 @@ func (dp *DataPlane) GetAllIPSets() map[string]string {
 	return dp.ipsetMgr.GetAllIPSets()
 }
 
+// ResolveDropLogTag returns the policy and ACL which logged a dropped packet (see the droplog package).
+func (dp *DataPlane) ResolveDropLogTag(tag droplog.Tag) (droplog.Attribution, bool) {
+	return dp.policyMgr.ResolveDropLogTag(tag)
+}
+
+// DropLogMarkTag returns the NetworkPolicy ACL whose ID is in the mark of a dropped packet (see the droplog package).
+func (dp *DataPlane) DropLogMarkTag(mark uint32) (droplog.Tag, bool) {
+	return dp.policyMgr.DropLogMarkTag(mark)
+}
+
 // GetAllPolicies is deprecated and only used in the goalstateprocessor, which is deprecated
 func (dp *DataPlane) GetAllPolicies() []string {
 	return nil
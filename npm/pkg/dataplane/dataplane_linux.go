@@ -20,9 +20,19 @@ func (dp *DataPlane) updatePod(pod *updateNPMPod) error {
 	return nil
 }
 
+// appliesIPSetsWithPolicies is true with nftables, where the IPSets are applied in the same transaction as the policies,
+// so that adding or removing a policy is one nft transaction.
+func (dp *DataPlane) appliesIPSetsWithPolicies() bool {
+	return dp.PolicyManagerCfg.EnableNFTables
+}
+
 func (dp *DataPlane) bootupDataPlane() error {
 	util.DetectIptablesVersion(dp.ioShim)
 
+	if dp.appliesIPSetsWithPolicies() {
+		dp.policyMgr.SetNFTablesTransaction(dp.ipsetMgr.ApplyIPSetsWithNFTablesLines)
+	}
+
 	// It is important to keep order to clean-up ACLs before ipsets. Otherwise we won't be able to delete ipsets referenced by ACLs
 	if err := dp.policyMgr.Bootup(nil); err != nil {
 		return npmerrors.ErrorWrapper(npmerrors.BootupDataplane, false, "failed to reset policy dataplane", err)
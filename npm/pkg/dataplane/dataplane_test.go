@@ -160,9 +160,13 @@ func TestAddToSet(t *testing.T) {
 	require.NoError(t, err)
 
 	v6PodMetadata := NewPodMetadata("testns/a", "2001:db8:0:0:0:0:2:1", nodeName)
-	// Test IPV6 addess it should error out
+	// Test IPV6 addess it should error out in windows
 	err = dp.AddToSets(setsTocreate, v6PodMetadata)
-	require.Error(t, err)
+	if util.IsWindowsDP() {
+		require.Error(t, err)
+	} else {
+		require.NoError(t, err)
+	}
 
 	for _, v := range setsTocreate {
 		dp.DeleteIPSet(v, util.SoftDelete)
@@ -178,7 +182,11 @@ func TestAddToSet(t *testing.T) {
 	require.NoError(t, err)
 
 	err = dp.RemoveFromSets(setsTocreate, v6PodMetadata)
-	require.Error(t, err)
+	if util.IsWindowsDP() {
+		require.Error(t, err)
+	} else {
+		require.NoError(t, err)
+	}
 
 	for _, v := range setsTocreate {
 		dp.DeleteIPSet(v, util.SoftDelete)
@@ -421,3 +429,39 @@ func getAffectedIPSets(networkPolicy *policies.NPMNetworkPolicy) []*ipsets.IPSet
 	}
 	return sets
 }
+
+func TestExplainFlows(t *testing.T) {
+	metrics.InitializeAll()
+
+	calls := getBootupTestCalls()
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	dp, err := NewDataPlane("testnode", ioshim, dpCfg, nil)
+	require.NoError(t, err)
+
+	nsX := ipsets.NewIPSetMetadata("x", ipsets.Namespace)
+	nsY := ipsets.NewIPSetMetadata("y", ipsets.Namespace)
+	require.NoError(t, dp.ipsetMgr.AddToSets([]*ipsets.IPSetMetadata{nsX}, "10.0.0.2", "x/b"))
+	require.NoError(t, dp.ipsetMgr.AddToSets([]*ipsets.IPSetMetadata{nsX}, "10.0.0.1", "x/a"))
+	require.NoError(t, dp.ipsetMgr.AddToSets([]*ipsets.IPSetMetadata{nsY}, "10.0.1.1", "y/a"))
+
+	// a namespace expands to its pods, sorted by pod key
+	explanations, err := dp.ExplainFlows("y/a", "x", "TCP", 80)
+	require.NoError(t, err)
+	require.Len(t, explanations, 2)
+	require.Equal(t, policies.Flow{SrcIP: "10.0.1.1", SrcPodKey: "y/a", DstIP: "10.0.0.1", DstPodKey: "x/a", Protocol: "TCP", DstPort: 80}, explanations[0].Flow)
+	require.Equal(t, policies.Flow{SrcIP: "10.0.1.1", SrcPodKey: "y/a", DstIP: "10.0.0.2", DstPodKey: "x/b", Protocol: "TCP", DstPort: 80}, explanations[1].Flow)
+	for _, explanation := range explanations {
+		require.Equal(t, policies.Allowed, explanation.Verdict)
+	}
+
+	// IPs are used as is, and flows between IPv4 and IPv6 are skipped
+	explanations, err = dp.ExplainFlows("fd00::1", "10.0.0.9", "UDP", 0)
+	require.NoError(t, err)
+	require.Empty(t, explanations)
+
+	for _, endpoint := range []string{"z", "x/c"} {
+		_, err = dp.ExplainFlows(endpoint, "10.0.0.1", "TCP", 80)
+		require.ErrorIs(t, err, ErrUnknownFlowEndpoint)
+	}
+}
@@ -126,6 +126,10 @@ func (dp *DataPlane) shouldUpdatePod() bool {
 	return true
 }
 
+func (dp *DataPlane) appliesIPSetsWithPolicies() bool {
+	return false
+}
+
 // updatePod has two responsibilities in windows
 // 1. Will call into dataplane and updates endpoint references of this pod.
 // 2. Will check for existing applicable network policies and applies it on endpoint.
@@ -37,4 +37,6 @@ const (
 	// stored file with json compatible form (i.e., can call json.Unmarshal)
 	npmCacheFileV1 = "../testdata/npmcachev1.json"
 	npmCacheFileV2 = "../testdata/npmcachev2.json"
+	// NPM logs with drop logging enabled
+	dropLogFile = "../testdata/droplogs"
 )
@@ -237,12 +237,22 @@ func (c *Converter) GetProtobufRulesFromIptableFile(
 
 // GetProtobufRulesFromIptable returns a list of protobuf rules from node.
 func (c *Converter) GetProtobufRulesFromIptable(tableName string) (map[*pb.RuleResponse]struct{}, error) {
+	return c.GetProtobufRulesFromIptableForFamily(tableName, ipsets.IPv4)
+}
+
+// GetProtobufRulesFromIptableForFamily returns a list of protobuf rules from node's iptables, or ip6tables for IPv6.
+func (c *Converter) GetProtobufRulesFromIptableForFamily(tableName string, family ipsets.IPFamily) (map[*pb.RuleResponse]struct{}, error) {
 	err := c.InitConverter()
 	if err != nil {
 		return nil, fmt.Errorf("error occurred during getting protobuf rules from iptables : %w", err)
 	}
 
-	ipTable, err := parse.Iptables(tableName)
+	var ipTable *NPMIPtable.Table
+	if family == ipsets.IPv6 {
+		ipTable, err = parse.Ip6tables(tableName)
+	} else {
+		ipTable, err = parse.Iptables(tableName)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error occurred during parsing iptables : %w", err)
 	}
@@ -332,6 +342,9 @@ func (c *Converter) getRulesFromChain(iptableChain *NPMIPtable.Chain) ([]*pb.Rul
 			// chain name has to end in hash np for it to determine if allow or drop
 			// ignore jumps from parent AZURE-NPM
 			switch v.Target.Name {
+			case util.IptablesNFLog:
+				// drop logging rules don't affect the verdict
+				continue
 			case util.IptablesAzureIngressAllowMarkChain:
 				rule.Allowed = true
 
@@ -508,7 +521,9 @@ func (c *Converter) populateSetInfo(setInfo *pb.RuleResponse_SetInfo, values []s
 	setInfo.HashedSetName = ipsetHashedName
 
 	if c.EnableV2NPM {
-		setInfo.Name = c.SetMap[ipsetHashedName]
+		// the IPv6 counterpart of a set is named after the set's hashed name
+		hashedName, _ := ipsets.HashedNameAndFamily(ipsetHashedName)
+		setInfo.Name = c.SetMap[hashedName]
 		settype, _ := c.getSetTypeV2(setInfo.Name)
 		if settype == pb.SetType_UNKNOWN {
 			return errors.Wrapf(ErrUnknownSetType, "unknown set type for set: %s", setInfo.Name)
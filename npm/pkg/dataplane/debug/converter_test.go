@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	NPMIPtable "github.com/Azure/azure-container-networking/npm/pkg/dataplane/iptables"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/pb"
 	"github.com/Azure/azure-container-networking/npm/util"
@@ -99,7 +100,7 @@ func TestGetProtobufRulesFromIptableFileV2(t *testing.T) {
 		},
 	}
 
-	hitrules, _, _, err := getHitRules(srcPod, dstPod, rules, c.NPMCache)
+	hitrules, _, _, err := getHitRules(srcPod, dstPod, rules, c.NPMCache, ipsets.IPv4)
 	require.NoError(t, err)
 	log.Printf("hitrules %+v", hitrules)
 	if err != nil {
@@ -242,8 +243,32 @@ func TestGetRulesFromChain(t *testing.T) {
 		Comment:       "[EGRESS-POLICY-y/base-FROM-podlabel-pod:a-AND-ns-y-IN-ns-y]",
 	}}
 
+	// drop logging rules are skipped
+	chainWithDropLogging := &NPMIPtable.Chain{
+		Name: rawchain.Name,
+		Rules: append([]*NPMIPtable.Rule{
+			{
+				Protocol: "",
+				Target: &NPMIPtable.Target{
+					Name: util.IptablesNFLog,
+					OptionValueMap: map[string][]string{
+						"nflog-prefix": {"NPM-DROP:E:2697641196:0"},
+						"nflog-group":  {"100"},
+					},
+				},
+				Modules: []*NPMIPtable.Module{
+					{
+						Verb:           "limit",
+						OptionValueMap: map[string][]string{"limit": {"10/sec"}, "limit-burst": {"20"}},
+					},
+				},
+			},
+		}, rawchain.Rules...),
+	}
+
 	testCases := map[string]*test{
-		"allowed rule": {input: rawchain, expected: expected},
+		"allowed rule":             {input: rawchain, expected: expected},
+		"rule after drop log rule": {input: chainWithDropLogging, expected: expected},
 	}
 
 	c := &Converter{
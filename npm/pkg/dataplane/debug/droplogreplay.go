@@ -0,0 +1,98 @@
+package debug
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/pb"
+)
+
+// ReplayDropLogsFile explains the drop log lines in an NPM log file (see the droplog package).
+func ReplayDropLogsFile(logFile string) ([]*TupleAndRule, error) {
+	f, err := os.Open(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open drop log file: %w", err)
+	}
+	defer f.Close()
+	return ReplayDropLogs(f)
+}
+
+// ReplayDropLogs returns a tuple for each packet logged in NPM logs when drop logging is enabled.
+// Each tuple has the logged packet, and its rule is the ACL which marked the packet for drop.
+// Lines which aren't drop log lines are skipped.
+func ReplayDropLogs(logs io.Reader) ([]*TupleAndRule, error) {
+	tuples := make([]*TupleAndRule, 0)
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		entry, err := droplog.ParseLogLine(scanner.Text())
+		if errors.Is(err, droplog.ErrNoLogMarker) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay drop logs: %w", err)
+		}
+		tuples = append(tuples, dropLogTuple(entry))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read drop logs: %w", err)
+	}
+	return tuples, nil
+}
+
+func dropLogTuple(entry *droplog.Entry) *TupleAndRule {
+	tuple := &Tuple{
+		RuleType:  "NOT ALLOWED",
+		Direction: entry.Direction,
+		SrcIP:     entry.SrcIP,
+		SrcPort:   portOrAny(entry.SrcPort),
+		DstIP:     entry.DstIP,
+		DstPort:   portOrAny(entry.DstPort),
+		Protocol:  entry.Protocol,
+	}
+
+	rule := &pb.RuleResponse{
+		Chain:     entry.Chain,
+		Protocol:  entry.Protocol,
+		Allowed:   false,
+		Direction: pb.Direction_UNDEFINED,
+		Comment:   entry.Comment,
+	}
+	switch entry.Direction {
+	case droplog.Ingress:
+		rule.Direction = pb.Direction_INGRESS
+	case droplog.Egress:
+		rule.Direction = pb.Direction_EGRESS
+	}
+	// admin tier comments already start with the policy key
+	if !strings.HasPrefix(rule.Comment, entry.PolicyKey) {
+		rule.Comment = strings.TrimSpace(fmt.Sprintf("%s-ACL-%d %s", entry.PolicyKey, entry.ACLID, rule.Comment))
+	}
+
+	return &TupleAndRule{
+		Tuple: tuple,
+		Rule:  rule,
+	}
+}
+
+func portOrAny(port int) string {
+	if port == 0 {
+		return ANY
+	}
+	return strconv.Itoa(port)
+}
+
+// PrettyPrintDropTuples prints the tuples from ReplayDropLogs.
+func PrettyPrintDropTuples(tuples []*TupleAndRule) {
+	fmt.Printf("Dropped:\n")
+	for _, tuple := range tuples {
+		fmt.Printf("\t%s: %s %s:%s -> %s:%s, Chain: %v, Comment: %v\n",
+			tuple.Tuple.Direction, tuple.Tuple.Protocol, tuple.Tuple.SrcIP, tuple.Tuple.SrcPort, tuple.Tuple.DstIP, tuple.Tuple.DstPort,
+			tuple.Rule.Chain, tuple.Rule.Comment)
+	}
+}
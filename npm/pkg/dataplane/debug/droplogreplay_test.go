@@ -0,0 +1,76 @@
+package debug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayDropLogsFile(t *testing.T) {
+	tuples, err := ReplayDropLogsFile(dropLogFile)
+	require.NoError(t, err)
+	expected := []*TupleAndRule{
+		{
+			Tuple: &Tuple{
+				RuleType:  "NOT ALLOWED",
+				Direction: "INGRESS",
+				SrcIP:     "10.224.0.20",
+				SrcPort:   "34567",
+				DstIP:     "10.224.0.17",
+				DstPort:   "80",
+				Protocol:  "TCP",
+			},
+			Rule: &pb.RuleResponse{
+				Chain:     "AZURE-NPM-INGRESS-1234567",
+				Protocol:  "TCP",
+				Direction: pb.Direction_INGRESS,
+				Comment:   "x/deny-all-ACL-0 DROP-ALL",
+			},
+		},
+		{
+			Tuple: &Tuple{
+				RuleType:  "NOT ALLOWED",
+				Direction: "EGRESS",
+				SrcIP:     "10.224.0.17",
+				SrcPort:   "5353",
+				DstIP:     "10.0.0.10",
+				DstPort:   "53",
+				Protocol:  "UDP",
+			},
+			Rule: &pb.RuleResponse{
+				Chain:     "AZURE-NPM-ADMIN-EGRESS",
+				Protocol:  "UDP",
+				Direction: pb.Direction_EGRESS,
+				Comment:   "AdminNetworkPolicy/deny-DROP-TO-cidr-ips-ON-UDP-TO-PORT-53",
+			},
+		},
+		{
+			Tuple: &Tuple{
+				RuleType:  "NOT ALLOWED",
+				Direction: "EGRESS",
+				SrcIP:     "10.224.0.17",
+				SrcPort:   ANY,
+				DstIP:     "10.224.0.20",
+				DstPort:   ANY,
+				Protocol:  "ICMP",
+			},
+			Rule: &pb.RuleResponse{
+				Protocol:  "ICMP",
+				Direction: pb.Direction_EGRESS,
+				Comment:   "unknown-ACL-2",
+			},
+		},
+	}
+	require.Equal(t, expected, tuples)
+}
+
+func TestReplayDropLogsInvalidLine(t *testing.T) {
+	_, err := ReplayDropLogs(strings.NewReader("I0102 03:04:08.000000       1 reader_linux.go:78] [DropLog] {\"aclID\":"))
+	require.Error(t, err)
+
+	tuples, err := ReplayDropLogs(strings.NewReader("no drop logs\n"))
+	require.NoError(t, err)
+	require.Empty(t, tuples)
+}
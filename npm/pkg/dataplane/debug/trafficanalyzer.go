@@ -10,6 +10,7 @@ import (
 
 	npmconfig "github.com/Azure/azure-container-networking/npm/config"
 	common "github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/pb"
 	"github.com/Azure/azure-container-networking/npm/util"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -89,19 +90,21 @@ func PrettyPrintTuples(tuples []*TupleAndRule, srcList map[string]*pb.RuleRespon
 // returns a list of hit rules between the source and the destination in
 // JSON format and a list of tuples from those rules.
 func (c *Converter) GetNetworkTuple(src, dst *common.Input, config *npmconfig.Config) ([][]byte, []*TupleAndRule, map[string]*pb.RuleResponse_SetInfo, map[string]*pb.RuleResponse_SetInfo, error) { //nolint: gocritic,lll
-	allRules, err := c.GetProtobufRulesFromIptable("filter")
+	family := inputFamily(src, dst)
+	allRules, err := c.GetProtobufRulesFromIptableForFamily("filter", family)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("error occurred during get network tuple : %w", err)
 	}
 
 	// after we have all rules from the AZURE-NPM chains in the filter table, get the network tuples of src and dst
 
-	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules)
+	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules, family)
 }
 
 // GetNetworkTupleFile read from NPM cache and iptables-save files and
 // returns a list of hit rules between the source and the destination in
 // JSON format and a list of tuples from those rules.
+// For IPv6 src or dst IPs, the file should be from ip6tables-save.
 func (c *Converter) GetNetworkTupleFile( //nolint:gocritic
 	src, dst *common.Input,
 	npmCacheFile string,
@@ -112,7 +115,27 @@ func (c *Converter) GetNetworkTupleFile( //nolint:gocritic
 		return nil, nil, nil, nil, fmt.Errorf("error occurred during get network tuple : %w", err)
 	}
 
-	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules)
+	return getNetworkTupleCommon(src, dst, c.NPMCache, allRules, inputFamily(src, dst))
+}
+
+// inputFamily returns IPv6 if the src or dst is an IPv6 address, and IPv4 otherwise.
+func inputFamily(src, dst *common.Input) ipsets.IPFamily {
+	for _, input := range []*common.Input{src, dst} {
+		if input.Type == common.IPADDRS && util.IsIPV6(input.Content) {
+			return ipsets.IPv6
+		}
+	}
+	return ipsets.IPv4
+}
+
+// podIPForFamily returns the pod's IP in the given family, or its primary IP if it has none in the family.
+func podIPForFamily(pod *common.NpmPod, family ipsets.IPFamily) string {
+	for _, ip := range pod.PodIPs {
+		if util.IsIPV6(ip) == (family == ipsets.IPv6) {
+			return ip
+		}
+	}
+	return pod.IP()
 }
 
 // Common function.
@@ -120,6 +143,7 @@ func getNetworkTupleCommon(
 	src, dst *common.Input,
 	npmCache common.GenericCache,
 	allRules map[*pb.RuleResponse]struct{},
+	family ipsets.IPFamily,
 ) ([][]byte, []*TupleAndRule, map[string]*pb.RuleResponse_SetInfo, map[string]*pb.RuleResponse_SetInfo, error) {
 
 	srcPod, err := npmCache.GetPod(src)
@@ -133,7 +157,7 @@ func getNetworkTupleCommon(
 	}
 
 	// find all rules where the source pod and dest pod exist
-	hitRules, srcSets, dstSets, err := getHitRules(srcPod, dstPod, allRules, npmCache)
+	hitRules, srcSets, dstSets, err := getHitRules(srcPod, dstPod, allRules, npmCache, family)
 	if err != nil {
 		return nil, nil, srcSets, dstSets, fmt.Errorf("%w", err)
 	}
@@ -153,7 +177,7 @@ func getNetworkTupleCommon(
 
 	resTupleList := make([]*TupleAndRule, 0)
 	for _, rule := range hitRules {
-		tuple := generateTuple(srcPod, dstPod, rule)
+		tuple := generateTuple(srcPod, dstPod, rule, family)
 		resTupleList = append(resTupleList, tuple)
 	}
 	// tupleResListJson := make([][]byte, 0)
@@ -167,7 +191,7 @@ func getNetworkTupleCommon(
 	return ruleResListJSON, resTupleList, srcSets, dstSets, nil
 }
 
-func generateTuple(src, dst *common.NpmPod, rule *pb.RuleResponse) *TupleAndRule {
+func generateTuple(src, dst *common.NpmPod, rule *pb.RuleResponse, family ipsets.IPFamily) *TupleAndRule {
 	tuple := &Tuple{}
 	if rule.Allowed {
 		tuple.RuleType = "ALLOWED"
@@ -188,7 +212,7 @@ func generateTuple(src, dst *common.NpmPod, rule *pb.RuleResponse) *TupleAndRule
 	if len(rule.SrcList) == 0 {
 		tuple.SrcIP = ANY
 	} else {
-		tuple.SrcIP = src.IP()
+		tuple.SrcIP = podIPForFamily(src, family)
 	}
 	if rule.SPort != 0 {
 		tuple.SrcPort = strconv.Itoa(int(rule.SPort))
@@ -198,7 +222,7 @@ func generateTuple(src, dst *common.NpmPod, rule *pb.RuleResponse) *TupleAndRule
 	if len(rule.DstList) == 0 {
 		tuple.DstIP = ANY
 	} else {
-		tuple.DstIP = dst.IP()
+		tuple.DstIP = podIPForFamily(dst, family)
 	}
 	if rule.DPort != 0 {
 		tuple.DstPort = strconv.Itoa(int(rule.DPort))
@@ -220,6 +244,7 @@ func getHitRules(
 	src, dst *common.NpmPod,
 	rules map[*pb.RuleResponse]struct{},
 	npmCache common.GenericCache,
+	family ipsets.IPFamily,
 ) ([]*pb.RuleResponse, map[string]*pb.RuleResponse_SetInfo, map[string]*pb.RuleResponse_SetInfo, error) {
 
 	res := make([]*pb.RuleResponse, 0)
@@ -236,7 +261,7 @@ func getHitRules(
 				break
 			}
 
-			matchedSource, err := evaluateSetInfo("src", setInfo, src, rule, npmCache)
+			matchedSource, err := evaluateSetInfo("src", setInfo, src, rule, npmCache, family)
 			if err != nil {
 				return nil, nil, nil, fmt.Errorf("error occurred during evaluating source's set info : %w", err)
 			}
@@ -254,7 +279,7 @@ func getHitRules(
 				break
 			}
 
-			matchedDestination, err := evaluateSetInfo("dst", setInfo, dst, rule, npmCache)
+			matchedDestination, err := evaluateSetInfo("dst", setInfo, dst, rule, npmCache, family)
 			if err != nil {
 				return nil, nil, nil, fmt.Errorf("error occurred during evaluating destination's set info : %w", err)
 			}
@@ -292,6 +317,7 @@ func evaluateSetInfo(
 	pod *common.NpmPod,
 	rule *pb.RuleResponse,
 	npmCache common.GenericCache,
+	family ipsets.IPFamily,
 ) (bool, error) {
 
 	switch setInfo.Type {
@@ -310,7 +336,7 @@ func evaluateSetInfo(
 	case pb.SetType_NAMEDPORTS:
 		return matchNAMEDPORTS(pod, setInfo, rule, origin), nil
 	case pb.SetType_CIDRBLOCKS:
-		return matchCIDRBLOCKS(pod, setInfo), nil
+		return matchCIDRBLOCKS(podIPForFamily(pod, family), setInfo), nil
 	default:
 		return false, common.ErrSetType
 	}
@@ -426,21 +452,19 @@ func matchNAMEDPORTS(pod *common.NpmPod, setInfo *pb.RuleResponse_SetInfo, rule
 	return false
 }
 
-func matchCIDRBLOCKS(pod *common.NpmPod, setInfo *pb.RuleResponse_SetInfo) bool {
+func matchCIDRBLOCKS(podIP string, setInfo *pb.RuleResponse_SetInfo) bool {
 	matched := false
 	for _, entry := range setInfo.Contents {
 		entrySplitted := strings.Split(entry, " ")
 		if len(entrySplitted) > 1 { // nomatch condition. i.e [172.17.1.0/24 nomatch]
 			_, ipnet, _ := net.ParseCIDR(strings.TrimSpace(entrySplitted[0]))
-			podIP := net.ParseIP(pod.PodIP)
-			if ipnet.Contains(podIP) {
+			if ipnet.Contains(net.ParseIP(podIP)) {
 				matched = false
 				break
 			}
 		} else {
 			_, ipnet, _ := net.ParseCIDR(strings.TrimSpace(entrySplitted[0]))
-			podIP := net.ParseIP(pod.PodIP)
-			if ipnet.Contains(podIP) {
+			if ipnet.Contains(net.ParseIP(podIP)) {
 				matched = true
 			}
 		}
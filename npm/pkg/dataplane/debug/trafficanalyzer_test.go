@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	common "github.com/Azure/azure-container-networking/npm/pkg/controlplane/controllers/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/util"
 	"github.com/stretchr/testify/require"
 )
@@ -37,6 +38,7 @@ func TestGetInputType(t *testing.T) {
 		"external":  {input: "External", expected: common.EXTERNAL},
 		"podname":   {input: "test/server", expected: common.NSPODNAME},
 		"ipaddress": {input: "10.240.0.38", expected: common.IPADDRS},
+		"ipv6":      {input: "fd00::26", expected: common.IPADDRS},
 	}
 	for name, test := range tests {
 		test := test
@@ -49,6 +51,18 @@ func TestGetInputType(t *testing.T) {
 	}
 }
 
+func TestInputFamilyAndPodIP(t *testing.T) {
+	dualStackPod := &common.NpmPod{PodIP: "10.224.0.17", PodIPs: []string{"10.224.0.17", "fd00::17"}}
+	podInput := &common.Input{Content: "y/b", Type: common.NSPODNAME}
+	v6Input := &common.Input{Content: "fd00::20", Type: common.IPADDRS}
+
+	require.Equal(t, ipsets.IPv4, inputFamily(podInput, podInput))
+	require.Equal(t, ipsets.IPv6, inputFamily(podInput, v6Input))
+	require.Equal(t, "10.224.0.17", podIPForFamily(dualStackPod, ipsets.IPv4))
+	require.Equal(t, "fd00::17", podIPForFamily(dualStackPod, ipsets.IPv6))
+	require.Equal(t, "10.224.0.20", podIPForFamily(&common.NpmPod{PodIP: "10.224.0.20"}, ipsets.IPv6))
+}
+
 func TestGetNetworkTuple(t *testing.T) {
 	type srcDstPair struct {
 		src *common.Input
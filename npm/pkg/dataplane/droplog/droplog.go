@@ -0,0 +1,175 @@
+// Package droplog attributes packets dropped by NetworkPolicies to the policy and ACL which dropped them.
+// When drop logging is enabled, the Linux PolicyManager writes rate-limited NFLOG rules where packets are dropped:
+//   - before each drop rule of AdminNetworkPolicies, tagged with the policy and ACL.
+//   - before the DROP-ON-MARK rules of AZURE-NPM-INGRESS and AZURE-NPM-EGRESS, tagged with the direction only.
+//     NetworkPolicy ACLs only mark packets for drop, since a later policy may still allow them,
+//     so each ACL sets its MarkIDs ID in the packet mark along with the drop mark.
+//
+// The Reader receives the logged packets from the NFLOG group,
+// then writes a log line and increments a Prometheus counter for each packet.
+package droplog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Group is the NFLOG group for dropped packets.
+	Group uint16 = 100
+	// RateLimit is the maximum rate at which each NFLOG rule logs packets.
+	RateLimit = "10/second"
+	// RateLimitBurst is the maximum number of packets each NFLOG rule logs in a burst.
+	RateLimitBurst = 20
+
+	// LogMarker precedes the JSON entry in each log line written by the Reader.
+	LogMarker = "[DropLog]"
+
+	// UnknownPolicy is the PolicyKey of an Entry whose policy was removed before the packet was read.
+	UnknownPolicy = "unknown"
+
+	Ingress = "INGRESS"
+	Egress  = "EGRESS"
+
+	// MarkIDMask is the part of the packet mark which holds the ID of the NetworkPolicy ACL which marked the packet for drop.
+	MarkIDMask  uint32 = 0xffff0000
+	markIDShift        = 16
+
+	tagPrefix        = "NPM-DROP"
+	markTagPrefix    = "NPM-DROP-MARK"
+	tagDelimiter     = ":"
+	tagIngress       = "I"
+	tagEgress        = "E"
+	numTagFields     = 4
+	numMarkTagFields = 2
+)
+
+var (
+	ErrInvalidTag    = errors.New("invalid drop log tag")
+	ErrNoLogMarker   = errors.New("line is not a drop log line")
+	ErrInvalidPacket = errors.New("invalid packet")
+	ErrUnsupported   = errors.New("drop logging is only supported on Linux")
+)
+
+// Tag identifies the ACL which logged a packet. It's used as the NFLOG prefix, e.g. "NPM-DROP:I:1234567:0".
+// The NFLOG prefix is limited to 64 characters, so the tag has the hash of the PolicyKey (like policy chain names)
+// instead of the PolicyKey itself.
+type Tag struct {
+	// Direction is Ingress or Egress
+	Direction  string
+	PolicyHash string
+	// ACLID is the index of the ACL in the NPMNetworkPolicy
+	ACLID int
+}
+
+func (tag Tag) String() string {
+	return strings.Join([]string{tagPrefix, directionTag(tag.Direction), tag.PolicyHash, strconv.Itoa(tag.ACLID)}, tagDelimiter)
+}
+
+// MarkTag returns the NFLOG prefix of the DROP-ON-MARK rule of the direction, e.g. "NPM-DROP-MARK:I".
+// The ACL which marked the packet is identified by the ID in the packet mark.
+func MarkTag(direction string) string {
+	return strings.Join([]string{markTagPrefix, directionTag(direction)}, tagDelimiter)
+}
+
+// parseMarkTag returns the direction of a MarkTag prefix, and false if the prefix isn't a MarkTag.
+func parseMarkTag(prefix string) (string, bool) {
+	fields := strings.Split(prefix, tagDelimiter)
+	if len(fields) != numMarkTagFields || fields[0] != markTagPrefix {
+		return "", false
+	}
+	switch fields[1] {
+	case tagIngress:
+		return Ingress, true
+	case tagEgress:
+		return Egress, true
+	}
+	return "", false
+}
+
+func directionTag(direction string) string {
+	if direction == Ingress {
+		return tagIngress
+	}
+	return tagEgress
+}
+
+// ParseTag parses the NFLOG prefix of a logged packet.
+func ParseTag(prefix string) (Tag, error) {
+	fields := strings.Split(prefix, tagDelimiter)
+	if len(fields) != numTagFields || fields[0] != tagPrefix || fields[2] == "" {
+		return Tag{}, fmt.Errorf("%w: %q", ErrInvalidTag, prefix)
+	}
+
+	tag := Tag{PolicyHash: fields[2]}
+	switch fields[1] {
+	case tagIngress:
+		tag.Direction = Ingress
+	case tagEgress:
+		tag.Direction = Egress
+	default:
+		return Tag{}, fmt.Errorf("%w: unknown direction in %q", ErrInvalidTag, prefix)
+	}
+
+	aclID, err := strconv.Atoi(fields[3])
+	if err != nil || aclID < 0 {
+		return Tag{}, fmt.Errorf("%w: unknown ACL ID in %q", ErrInvalidTag, prefix)
+	}
+	tag.ACLID = aclID
+	return tag, nil
+}
+
+// Attribution is the policy and ACL which a Tag refers to.
+type Attribution struct {
+	PolicyKey string `json:"policyKey"`
+	// Namespace is empty for cluster-scoped policies
+	Namespace string `json:"namespace,omitempty"`
+	Direction string `json:"direction"`
+	ACLID     int    `json:"aclID"`
+	Chain     string `json:"chain,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Resolver looks up the policy and ACL for a Tag, or for the ID in the mark of a packet logged with a MarkTag.
+type Resolver interface {
+	ResolveDropLogTag(tag Tag) (Attribution, bool)
+	DropLogMarkTag(mark uint32) (Tag, bool)
+}
+
+// Entry is a logged packet and the ACL which logged it.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Attribution
+	Protocol string `json:"protocol"`
+	SrcIP    string `json:"srcIP"`
+	SrcPort  int    `json:"srcPort,omitempty"`
+	DstIP    string `json:"dstIP"`
+	DstPort  int    `json:"dstPort,omitempty"`
+}
+
+// LogLine returns the log line for the entry: LogMarker followed by the entry as JSON.
+func (entry *Entry) LogLine() (string, error) {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal drop log entry: %w", err)
+	}
+	return LogMarker + " " + string(bytes), nil
+}
+
+// ParseLogLine parses an Entry from a log line written by the Reader.
+// Anything before LogMarker (e.g. the klog header) is ignored.
+func ParseLogLine(line string) (*Entry, error) {
+	_, entryJSON, found := strings.Cut(line, LogMarker)
+	if !found {
+		return nil, ErrNoLogMarker
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(entryJSON)), entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal drop log entry: %w", err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,229 @@
+package droplog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	attributions map[Tag]Attribution
+	markIDs      *MarkIDs
+}
+
+func (r fakeResolver) ResolveDropLogTag(tag Tag) (Attribution, bool) {
+	attribution, ok := r.attributions[tag]
+	return attribution, ok
+}
+
+func (r fakeResolver) DropLogMarkTag(mark uint32) (Tag, bool) {
+	return r.markIDs.Tag(mark)
+}
+
+var (
+	testTag = Tag{
+		Direction:  Ingress,
+		PolicyHash: "1234567",
+		ACLID:      2,
+	}
+	testAttribution = Attribution{
+		PolicyKey: "x/deny-all",
+		Namespace: "x",
+		Direction: Ingress,
+		ACLID:     2,
+		Chain:     "AZURE-NPM-INGRESS-1234567",
+		Comment:   "DROP-ALL",
+	}
+
+	// TCP from 10.0.0.1:34567 to 10.0.0.2:80
+	ipv4TCPPacket = []byte{
+		0x45, 0x00, 0x00, 0x28, 0x00, 0x00, 0x40, 0x00, 0x40, 0x06, 0x00, 0x00,
+		10, 0, 0, 1,
+		10, 0, 0, 2,
+		0x87, 0x07, 0x00, 0x50,
+	}
+	// UDP from fd00::1:53 to fd00::2:5353
+	ipv6UDPPacket = append(append(append([]byte{
+		0x60, 0x00, 0x00, 0x00, 0x00, 0x08, 0x11, 0x40,
+	},
+		0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1),
+		0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2),
+		0x00, 0x35, 0x14, 0xe9,
+	)
+)
+
+func TestTag(t *testing.T) {
+	require.Equal(t, "NPM-DROP:I:1234567:2", testTag.String())
+	egressTag := Tag{Direction: Egress, PolicyHash: "89", ACLID: 0}
+	require.Equal(t, "NPM-DROP:E:89:0", egressTag.String())
+
+	for _, tag := range []Tag{testTag, egressTag} {
+		parsed, err := ParseTag(tag.String())
+		require.NoError(t, err)
+		require.Equal(t, tag, parsed)
+	}
+
+	for _, prefix := range []string{
+		"",
+		"NPM-DROP:I:1234567",
+		"OTHER:I:1234567:2",
+		"NPM-DROP:X:1234567:2",
+		"NPM-DROP:I::2",
+		"NPM-DROP:I:1234567:-1",
+		"NPM-DROP:I:1234567:two",
+	} {
+		_, err := ParseTag(prefix)
+		require.ErrorIs(t, err, ErrInvalidTag, "prefix %q", prefix)
+	}
+}
+
+func TestLogLine(t *testing.T) {
+	entry := &Entry{
+		Timestamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Attribution: testAttribution,
+		Protocol:    "TCP",
+		SrcIP:       "10.0.0.1",
+		SrcPort:     34567,
+		DstIP:       "10.0.0.2",
+		DstPort:     80,
+	}
+	line, err := entry.LogLine()
+	require.NoError(t, err)
+	require.Equal(t,
+		`[DropLog] {"timestamp":"2024-01-02T03:04:05Z","policyKey":"x/deny-all","namespace":"x","direction":"INGRESS","aclID":2,`+
+			`"chain":"AZURE-NPM-INGRESS-1234567","comment":"DROP-ALL","protocol":"TCP","srcIP":"10.0.0.1","srcPort":34567,"dstIP":"10.0.0.2","dstPort":80}`,
+		line,
+	)
+
+	// with a klog header
+	parsed, err := ParseLogLine("I0102 03:04:05.000000       1 reader_linux.go:76] " + line)
+	require.NoError(t, err)
+	require.Equal(t, entry, parsed)
+
+	_, err = ParseLogLine("I0102 03:04:05.000000       1 dataplane.go:100] some other log")
+	require.ErrorIs(t, err, ErrNoLogMarker)
+
+	_, err = ParseLogLine("[DropLog] {not json")
+	require.Error(t, err)
+}
+
+func TestNewEntry(t *testing.T) {
+	timestamp := time.Now()
+	r := NewReader(fakeResolver{attributions: map[Tag]Attribution{testTag: testAttribution}, markIDs: NewMarkIDs()})
+
+	entry, err := r.newEntry(testTag.String(), 0, ipv4TCPPacket, timestamp)
+	require.NoError(t, err)
+	require.Equal(t, &Entry{
+		Timestamp:   timestamp,
+		Attribution: testAttribution,
+		Protocol:    "TCP",
+		SrcIP:       "10.0.0.1",
+		SrcPort:     34567,
+		DstIP:       "10.0.0.2",
+		DstPort:     80,
+	}, entry)
+
+	// the policy was removed
+	removedTag := Tag{Direction: Egress, PolicyHash: "89", ACLID: 1}
+	entry, err = r.newEntry(removedTag.String(), 0, ipv6UDPPacket, timestamp)
+	require.NoError(t, err)
+	require.Equal(t, &Entry{
+		Timestamp: timestamp,
+		Attribution: Attribution{
+			PolicyKey: UnknownPolicy,
+			Direction: Egress,
+			ACLID:     1,
+		},
+		Protocol: "UDP",
+		SrcIP:    "fd00::1",
+		SrcPort:  53,
+		DstIP:    "fd00::2",
+		DstPort:  5353,
+	}, entry)
+
+	_, err = r.newEntry("some-other-prefix", 0, ipv4TCPPacket, timestamp)
+	require.ErrorIs(t, err, ErrInvalidTag)
+
+	for _, payload := range [][]byte{nil, ipv4TCPPacket[:10], ipv6UDPPacket[:20], {0x10}} {
+		_, err = r.newEntry(testTag.String(), 0, payload, timestamp)
+		require.ErrorIs(t, err, ErrInvalidPacket)
+	}
+}
+
+func TestNewEntryWithoutPorts(t *testing.T) {
+	// ICMP from 10.0.0.1 to 10.0.0.2
+	icmpPacket := append([]byte{}, ipv4TCPPacket...)
+	icmpPacket[9] = 1
+	r := NewReader(fakeResolver{markIDs: NewMarkIDs()})
+	entry, err := r.newEntry(testTag.String(), 0, icmpPacket, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "ICMP", entry.Protocol)
+	require.Zero(t, entry.SrcPort)
+	require.Zero(t, entry.DstPort)
+
+	// truncated TCP header
+	entry, err = r.newEntry(testTag.String(), 0, ipv4TCPPacket[:22], time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "TCP", entry.Protocol)
+	require.Zero(t, entry.DstPort)
+}
+
+func TestNewEntryFromMark(t *testing.T) {
+	timestamp := time.Now()
+	markIDs := NewMarkIDs()
+	r := NewReader(fakeResolver{attributions: map[Tag]Attribution{testTag: testAttribution}, markIDs: markIDs})
+
+	// the ACL's ID is in the upper 16 bits, next to the drop mark and other marks
+	value, _ := Mark(markIDs.Assign(testTag), 0x400)
+	entry, err := r.newEntry(MarkTag(Ingress), value|0x4000, ipv4TCPPacket, timestamp)
+	require.NoError(t, err)
+	require.Equal(t, testAttribution, entry.Attribution)
+
+	// the policy was removed, or the ACL had no ID
+	markIDs.Release(testTag.PolicyHash)
+	for _, mark := range []uint32{value, 0x400} {
+		entry, err = r.newEntry(MarkTag(Ingress), mark, ipv4TCPPacket, timestamp)
+		require.NoError(t, err)
+		require.Equal(t, Attribution{PolicyKey: UnknownPolicy, Direction: Ingress}, entry.Attribution)
+	}
+
+	_, err = r.newEntry("NPM-DROP-MARK:X", value, ipv4TCPPacket, timestamp)
+	require.ErrorIs(t, err, ErrInvalidTag)
+}
+
+func TestMarkIDs(t *testing.T) {
+	markIDs := NewMarkIDs()
+	egressTag := Tag{Direction: Egress, PolicyHash: "89", ACLID: 0}
+	require.Equal(t, uint32(1), markIDs.Assign(testTag))
+	require.Equal(t, uint32(2), markIDs.Assign(egressTag))
+	// rewriting the rules of the ACL keeps its ID
+	require.Equal(t, uint32(1), markIDs.Assign(testTag))
+
+	value, mask := Mark(2, 0x800)
+	require.Equal(t, uint32(0x20800), value)
+	require.Equal(t, uint32(0xffff0800), mask)
+	tag, ok := markIDs.Tag(value)
+	require.True(t, ok)
+	require.Equal(t, egressTag, tag)
+
+	// released IDs aren't reused right away
+	markIDs.Release(testTag.PolicyHash)
+	_, ok = markIDs.Tag(1 << 16)
+	require.False(t, ok)
+	require.Equal(t, uint32(3), markIDs.Assign(testTag))
+
+	// the IDs wrap around, skipping the ones in use
+	markIDs.last = maxMarkID - 1
+	otherTag := Tag{Direction: Ingress, PolicyHash: "12", ACLID: 1}
+	require.Equal(t, maxMarkID, markIDs.Assign(otherTag))
+	require.Equal(t, uint32(1), markIDs.Assign(Tag{Direction: Ingress, PolicyHash: "12", ACLID: 2}))
+	require.Equal(t, uint32(4), markIDs.Assign(Tag{Direction: Ingress, PolicyHash: "12", ACLID: 3}))
+
+	// without an ID, the drop mark is set and the ID bits are cleared
+	value, mask = Mark(0, 0x400)
+	require.Equal(t, uint32(0x400), value)
+	require.Equal(t, uint32(0xffff0400), mask)
+	_, ok = markIDs.Tag(value)
+	require.False(t, ok)
+}
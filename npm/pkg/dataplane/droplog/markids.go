@@ -0,0 +1,74 @@
+package droplog
+
+import "sync"
+
+const maxMarkID = MarkIDMask >> markIDShift
+
+// MarkIDs assigns the IDs which NetworkPolicy ACLs set in the packet mark along with the drop mark.
+// IDs are assigned round robin, so that the ID of a removed policy isn't reused right away
+// while packets it marked may still be read.
+type MarkIDs struct {
+	sync.Mutex
+	// last is the last assigned ID
+	last uint32
+	ids  map[Tag]uint32
+	tags map[uint32]Tag
+}
+
+func NewMarkIDs() *MarkIDs {
+	return &MarkIDs{
+		ids:  make(map[Tag]uint32),
+		tags: make(map[uint32]Tag),
+	}
+}
+
+// Assign returns the ID of the ACL, assigning one if the ACL has none.
+// It returns 0 if all IDs are in use.
+func (m *MarkIDs) Assign(tag Tag) uint32 {
+	m.Lock()
+	defer m.Unlock()
+
+	if id, ok := m.ids[tag]; ok {
+		return id
+	}
+	for i := uint32(0); i < maxMarkID; i++ {
+		id := (m.last+i)%maxMarkID + 1
+		if _, ok := m.tags[id]; ok {
+			continue
+		}
+		m.last = id
+		m.ids[tag] = id
+		m.tags[id] = tag
+		return id
+	}
+	return 0
+}
+
+// Release releases the IDs of the ACLs of a policy.
+func (m *MarkIDs) Release(policyHash string) {
+	m.Lock()
+	defer m.Unlock()
+
+	for tag, id := range m.ids {
+		if tag.PolicyHash == policyHash {
+			delete(m.ids, tag)
+			delete(m.tags, id)
+		}
+	}
+}
+
+// Tag returns the ACL whose ID is in the mark.
+func (m *MarkIDs) Tag(mark uint32) (Tag, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	tag, ok := m.tags[(mark&MarkIDMask)>>markIDShift]
+	return tag, ok
+}
+
+// Mark returns the value and mask which set the ID along with the drop mark, e.g. 0x10400 and 0xffff0400 for ID 1 and drop mark 0x400.
+// Without an ID, i.e. when all IDs are in use, they clear the ID bits, so that an ID left in the mark by another ACL
+// doesn't attribute the drop to that ACL.
+func Mark(id, dropMark uint32) (value, mask uint32) {
+	return id<<markIDShift | dropMark, MarkIDMask | dropMark
+}
@@ -0,0 +1,119 @@
+package droplog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	ipv4Version         = 4
+	ipv6Version         = 6
+	minIPv4HeaderLength = 20
+	ipv6HeaderLength    = 40
+	portsLength         = 4
+)
+
+// IANA protocol numbers and their names, which match the protocols in NetworkPolicies.
+var protocolNames = map[uint8]string{
+	1:   "ICMP",
+	6:   "TCP",
+	17:  "UDP",
+	58:  "ICMPv6",
+	132: "SCTP",
+}
+
+// Reader reads packets logged by the NFLOG rules of the PolicyManager.
+type Reader struct {
+	resolver Resolver
+}
+
+// NewReader creates a Reader which attributes logged packets with the resolver (i.e. the dataplane).
+func NewReader(resolver Resolver) *Reader {
+	return &Reader{resolver: resolver}
+}
+
+// newEntry creates an Entry for a packet logged with the NFLOG prefix and packet mark.
+// If the policy no longer exists, the Entry has UnknownPolicy as its PolicyKey.
+func (r *Reader) newEntry(prefix string, mark uint32, payload []byte, timestamp time.Time) (*Entry, error) {
+	tag, err := r.tag(prefix, mark)
+	if err != nil {
+		return nil, err
+	}
+
+	attribution, ok := r.resolver.ResolveDropLogTag(tag)
+	if !ok {
+		attribution = Attribution{
+			PolicyKey: UnknownPolicy,
+			Direction: tag.Direction,
+			ACLID:     tag.ACLID,
+		}
+	}
+
+	entry := &Entry{
+		Timestamp:   timestamp,
+		Attribution: attribution,
+	}
+	if err := entry.setPacketFields(payload); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// tag returns the Tag of the ACL which dropped the packet, from the NFLOG prefix or from the ID in the packet mark.
+// If the ID in the mark is unknown, the Tag only has the direction.
+func (r *Reader) tag(prefix string, mark uint32) (Tag, error) {
+	direction, ok := parseMarkTag(prefix)
+	if !ok {
+		return ParseTag(prefix)
+	}
+	if tag, ok := r.resolver.DropLogMarkTag(mark); ok {
+		return tag, nil
+	}
+	return Tag{Direction: direction}, nil
+}
+
+// setPacketFields sets the protocol, IPs and ports from the IPv4 or IPv6 packet.
+// Ports are only set for TCP, UDP and SCTP, and IPv6 extension headers aren't followed.
+func (entry *Entry) setPacketFields(payload []byte) error {
+	if len(payload) == 0 {
+		return fmt.Errorf("%w: empty payload", ErrInvalidPacket)
+	}
+
+	var protocol uint8
+	var transportHeader []byte
+	switch payload[0] >> 4 {
+	case ipv4Version:
+		headerLength := int(payload[0]&0x0f) * 4
+		if headerLength < minIPv4HeaderLength || len(payload) < headerLength {
+			return fmt.Errorf("%w: truncated IPv4 header", ErrInvalidPacket)
+		}
+		protocol = payload[9]
+		entry.SrcIP = net.IP(payload[12:16]).String()
+		entry.DstIP = net.IP(payload[16:20]).String()
+		transportHeader = payload[headerLength:]
+	case ipv6Version:
+		if len(payload) < ipv6HeaderLength {
+			return fmt.Errorf("%w: truncated IPv6 header", ErrInvalidPacket)
+		}
+		protocol = payload[6]
+		entry.SrcIP = net.IP(payload[8:24]).String()
+		entry.DstIP = net.IP(payload[24:40]).String()
+		transportHeader = payload[ipv6HeaderLength:]
+	default:
+		return fmt.Errorf("%w: unknown IP version %d", ErrInvalidPacket, payload[0]>>4)
+	}
+
+	name, ok := protocolNames[protocol]
+	if !ok {
+		name = fmt.Sprint(protocol)
+	}
+	entry.Protocol = name
+
+	if (name == "TCP" || name == "UDP" || name == "SCTP") && len(transportHeader) >= portsLength {
+		entry.SrcPort = int(binary.BigEndian.Uint16(transportHeader[0:2]))
+		entry.DstPort = int(binary.BigEndian.Uint16(transportHeader[2:4]))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package droplog
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/florianl/go-nflog/v2"
+	"k8s.io/klog"
+)
+
+// copyRange is the number of bytes of each packet copied from the kernel: enough for the IP and transport headers.
+const copyRange = 128
+
+// Run reads logged packets from the NFLOG group until stopCh is closed.
+func (r *Reader) Run(stopCh <-chan struct{}) {
+	nf, err := nflog.Open(&nflog.Config{
+		Group:    Group,
+		Copymode: nflog.CopyPacket,
+		Bufsize:  copyRange,
+	})
+	if err != nil {
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: failed to open NFLOG group %d for drop logging: %s", Group, err.Error())
+		return
+	}
+	defer nf.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hook := func(attrs nflog.Attribute) int {
+		r.handle(attrs)
+		return 0
+	}
+	errFunc := func(err error) int {
+		if ctx.Err() != nil {
+			return 1
+		}
+		// e.g. ENOBUFS when packets are logged faster than they are read. Keep reading.
+		klog.Warningf("[DropLog] error while reading NFLOG group %d: %s", Group, err.Error())
+		return 0
+	}
+	if err := nf.RegisterWithErrorFunc(ctx, hook, errFunc); err != nil {
+		metrics.SendErrorLogAndMetric(util.IptmID, "error: failed to read NFLOG group %d for drop logging: %s", Group, err.Error())
+		return
+	}
+
+	klog.Infof("[DropLog] reading dropped packets from NFLOG group %d", Group)
+	<-stopCh
+	klog.Info("[DropLog] stopped reading dropped packets")
+}
+
+func (r *Reader) handle(attrs nflog.Attribute) {
+	if attrs.Prefix == nil || attrs.Payload == nil {
+		return
+	}
+	timestamp := time.Now()
+	if attrs.Timestamp != nil {
+		timestamp = *attrs.Timestamp
+	}
+
+	var mark uint32
+	if attrs.Mark != nil {
+		mark = *attrs.Mark
+	}
+
+	entry, err := r.newEntry(*attrs.Prefix, mark, *attrs.Payload, timestamp)
+	if err != nil {
+		klog.Warningf("[DropLog] failed to read logged packet: %s", err.Error())
+		return
+	}
+	record(entry)
+}
+
+// record writes the log line and increments the Prometheus counter for the entry.
+func record(entry *Entry) {
+	line, err := entry.LogLine()
+	if err != nil {
+		klog.Warningf("[DropLog] %s", err.Error())
+		return
+	}
+	klog.Info(line)
+	metrics.IncPolicyDrops(entry.Namespace, entry.PolicyKey, entry.Direction)
+}
@@ -0,0 +1,11 @@
+package droplog
+
+import (
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/util"
+)
+
+// Run returns immediately since drop logging is only supported on Linux.
+func (r *Reader) Run(_ <-chan struct{}) {
+	metrics.SendErrorLogAndMetric(util.IptmID, "error: %s", ErrUnsupported.Error())
+}
@@ -0,0 +1,83 @@
+package dataplane
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+)
+
+var ErrUnknownFlowEndpoint = errors.New("no pods found for flow endpoint")
+
+// flowEndpoint is the source or destination of a flow
+type flowEndpoint struct {
+	ip     string
+	podKey string
+}
+
+// ExplainFlows explains the verdict for each flow from src to dst based on the policies and IPSets in the cache.
+// src and dst can each be an IP, a Pod ("namespace/name"), or a namespace (any of its Pods).
+// Flows between IPv4 and IPv6 endpoints are skipped.
+func (dp *DataPlane) ExplainFlows(src, dst, protocol string, dstPort int32) ([]*policies.FlowExplanation, error) {
+	srcEndpoints, err := dp.flowEndpoints(src)
+	if err != nil {
+		return nil, err
+	}
+	dstEndpoints, err := dp.flowEndpoints(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	explanations := make([]*policies.FlowExplanation, 0, len(srcEndpoints)*len(dstEndpoints))
+	for _, srcEndpoint := range srcEndpoints {
+		for _, dstEndpoint := range dstEndpoints {
+			if isIPv4(srcEndpoint.ip) != isIPv4(dstEndpoint.ip) {
+				continue
+			}
+			flow := policies.Flow{
+				SrcIP:     srcEndpoint.ip,
+				SrcPodKey: srcEndpoint.podKey,
+				DstIP:     dstEndpoint.ip,
+				DstPodKey: dstEndpoint.podKey,
+				Protocol:  protocol,
+				DstPort:   dstPort,
+			}
+			explanations = append(explanations, dp.policyMgr.ExplainFlow(flow, dp.ipsetMgr))
+		}
+	}
+	return explanations, nil
+}
+
+// flowEndpoints returns the IP or the IPs of the Pod or namespace, sorted by pod key and IP.
+// Pods are looked up in the namespace IPSets.
+func (dp *DataPlane) flowEndpoints(input string) ([]flowEndpoint, error) {
+	if net.ParseIP(input) != nil {
+		return []flowEndpoint{{ip: input}}, nil
+	}
+
+	namespace, _, isPod := strings.Cut(input, "/")
+	endpoints := make([]flowEndpoint, 0)
+	for ip, podKey := range dp.ipsetMgr.NamespacePodIPs(namespace) {
+		if !isPod || podKey == input {
+			endpoints = append(endpoints, flowEndpoint{ip: ip, podKey: podKey})
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFlowEndpoint, input)
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].podKey != endpoints[j].podKey {
+			return endpoints[i].podKey < endpoints[j].podKey
+		}
+		return endpoints[i].ip < endpoints[j].ip
+	})
+	return endpoints, nil
+}
+
+func isIPv4(ip string) bool {
+	return net.ParseIP(ip).To4() != nil
+}
@@ -3,6 +3,7 @@ package ipsets
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-container-networking/log"
 	"github.com/Azure/azure-container-networking/npm/metrics"
@@ -25,6 +26,49 @@ const (
 	UnknownKind SetKind = "unknown"
 )
 
+// IPFamily is the address family of the members of a kernel set.
+// In Linux, each IPSet has an IPv6 counterpart when IPv6 is enabled, since an ipset can only hold members of one family.
+type IPFamily string
+
+const (
+	// IPv4 is the family of the kernel set named by IPSet.HashedName
+	IPv4 IPFamily = "inet"
+	// IPv6 is the family of the kernel set named by HashedNameForFamily(IPSet.HashedName, IPv6)
+	IPv6 IPFamily = "inet6"
+
+	// ipv6SetSuffix is appended to the hashed name of a set to get the name of its IPv6 counterpart
+	ipv6SetSuffix = "-v6"
+)
+
+// HashedNameForFamily returns the name of the kernel set holding the members of the given family.
+// The IPv6 counterpart of a list holds the IPv6 counterparts of the list's members.
+func HashedNameForFamily(hashedName string, family IPFamily) string {
+	if family == IPv6 {
+		return hashedName + ipv6SetSuffix
+	}
+	return hashedName
+}
+
+// HashedNameAndFamily is the inverse of HashedNameForFamily.
+// It returns the hashed name of the IPSet and the family of the kernel set with the given name.
+func HashedNameAndFamily(kernelSetName string) (string, IPFamily) {
+	if strings.HasSuffix(kernelSetName, ipv6SetSuffix) {
+		return strings.TrimSuffix(kernelSetName, ipv6SetSuffix), IPv6
+	}
+	return kernelSetName, IPv4
+}
+
+// MemberFamily returns the family of a HashSet member.
+// Possible formats are the same as in validateIPSetMemberIP() e.g. "2001:db8::/64 nomatch" or "2001:db8::1,tcp:80".
+func MemberFamily(member string) IPFamily {
+	ipDetails := strings.Split(member, ",")
+	ipField := strings.Split(ipDetails[0], " ")
+	if util.IsIPV6(ipField[0]) {
+		return IPv6
+	}
+	return IPv4
+}
+
 // NewIPSetMetadata is used for controllers to send in skeleton ipsets to DP
 func NewIPSetMetadata(name string, setType SetType) *IPSetMetadata {
 	set := &IPSetMetadata{
@@ -42,6 +86,15 @@ func (setMetadata *IPSetMetadata) GetHashedName() string {
 	return util.GetHashedName(prefixedName)
 }
 
+// GetHashedNameForFamily returns the name of the kernel set holding the members of the given family.
+func (setMetadata *IPSetMetadata) GetHashedNameForFamily(family IPFamily) string {
+	hashedName := setMetadata.GetHashedName()
+	if hashedName == Unknown {
+		return Unknown
+	}
+	return HashedNameForFamily(hashedName, family)
+}
+
 // TODO join with colon instead of dash for easier readability?
 func (setMetadata *IPSetMetadata) GetPrefixName() string {
 	switch setMetadata.Type {
@@ -151,3 +151,15 @@ func TestShouldBeInKernelAndCanDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestHashedNameAndFamily(t *testing.T) {
+	hashedName := NewIPSetMetadata("test", Namespace).GetHashedName()
+
+	name, family := HashedNameAndFamily(HashedNameForFamily(hashedName, IPv4))
+	require.Equal(t, hashedName, name)
+	require.Equal(t, IPv4, family)
+
+	name, family = HashedNameAndFamily(HashedNameForFamily(hashedName, IPv6))
+	require.Equal(t, hashedName, name)
+	require.Equal(t, IPv6, family)
+}
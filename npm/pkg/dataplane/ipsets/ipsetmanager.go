@@ -62,6 +62,11 @@ type IPSetManagerCfg struct {
 	// This is necessary for HNS (Windows); otherwise, an allow ACL with a list condition
 	// allows all IPs if the list has no members.
 	AddEmptySetToLists bool
+	// EnableIPv6 creates an IPv6 counterpart of each set in Linux, holding the IPv6 members of the set.
+	// Otherwise IPv6 members are kept in the cache but not added to the kernel.
+	EnableIPv6 bool
+	// EnableNFTables programs native nftables sets in Linux instead of ipsets.
+	EnableNFTables bool
 }
 
 func NewIPSetManager(iMgrCfg *IPSetManagerCfg, ioShim *common.IOShim) *IPSetManager {
@@ -592,9 +597,10 @@ func validateIPSetMemberIP(ip string) bool {
 	// 192.168.0.0/24
 	// 192.168.0.0/24,tcp:25227
 	// 192.168.0.0/24 nomatch
+	// and the same formats with an IPv6 address or CIDR, which are only supported in Linux.
 	// always guaranteed to have ip, not guaranteed to have port + protocol
 	ipDetails := strings.Split(ip, ",")
 	ipField := strings.Split(ipDetails[0], " ")
 
-	return util.IsIPV4(ipField[0])
+	return util.IsIPV4(ipField[0]) || (!util.IsWindowsDP() && util.IsIPV6(ipField[0]))
 }
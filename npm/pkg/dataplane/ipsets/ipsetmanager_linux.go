@@ -18,7 +18,7 @@ const (
 	ipsetFlushAndDestroyString = "ipset flush && ipset destroy"
 
 	azureNPMPrefix        = "azure-npm-"
-	azureNPMRegex         = "azure-npm-\\d+"
+	azureNPMRegex         = "azure-npm-\\d+(-v6)?"
 	positiveRefsRegex     = "References: [1-9]"
 	referenceGrepLookBack = "5"
 	maxLinesToPrint       = 10
@@ -34,6 +34,7 @@ const (
 	ipsetDeleteFlag     = "-D"
 	ipsetDestroyFlag    = "-X"
 	ipsetExistFlag      = "--exist"
+	ipsetFamilyFlag     = "family"
 	ipsetNetHashFlag    = "nethash"
 	ipsetSetListFlag    = "setlist"
 	ipsetIPPortHashFlag = "hash:ip,port"
@@ -97,6 +98,14 @@ var (
 		If a flush fails, we could update the num entries for that set, but that would be a lot of overhead.
 */
 func (iMgr *IPSetManager) resetIPSets() error {
+	if iMgr.iMgrCfg.EnableNFTables {
+		return iMgr.resetIPSetsForNFTables()
+	}
+	return iMgr.resetKernelIPSets()
+}
+
+// resetKernelIPSets flushes and destroys all NPM ipsets. See resetIPSets().
+func (iMgr *IPSetManager) resetKernelIPSets() error {
 	if success := iMgr.resetWithoutRestore(); success {
 		return nil
 	}
@@ -405,6 +414,9 @@ example where:
 		-X set4
 */
 func (iMgr *IPSetManager) applyIPSets() error {
+	if iMgr.iMgrCfg.EnableNFTables {
+		return iMgr.applyIPSetsWithNFTables()
+	}
 	creator := iMgr.fileCreatorForApply(maxTryCount)
 	restoreError := creator.RunCommandWithFile(ipsetCommand, ipsetRestoreFlag)
 	if restoreError != nil {
@@ -445,14 +457,13 @@ func (iMgr *IPSetManager) fileCreatorForApplyWithSaveFile(maxTryCount int, saveF
 	// 3. for the remaining dirty sets, add their members to the kernel
 	for prefixedName := range setsToAddOrUpdate {
 		set := iMgr.setMap[prefixedName]
-		sectionID := sectionID(addOrUpdateSectionPrefix, prefixedName)
 		if set.Kind == HashSet {
 			for ip := range set.IPPodKey {
-				iMgr.addMemberForApply(creator, set, sectionID, ip)
+				iMgr.addMemberForApply(creator, set, ip)
 			}
 		} else {
 			for _, member := range set.MemberIPSets {
-				iMgr.addMemberForApply(creator, set, sectionID, member.HashedName)
+				iMgr.addMemberForApply(creator, set, member.HashedName)
 			}
 		}
 	}
@@ -490,14 +501,13 @@ func (iMgr *IPSetManager) fileCreatorForApply(maxTryCount int) *ioutil.FileCreat
 
 	// 2. delete/add members from dirty sets to add or update
 	for prefixedName := range setsToAddOrUpdate {
-		sectionID := sectionID(addOrUpdateSectionPrefix, prefixedName)
 		set := iMgr.setMap[prefixedName]
 		diff := iMgr.dirtyCache.memberDiff(prefixedName)
 		for member := range diff.membersToDelete {
-			iMgr.deleteMemberForApply(creator, set, sectionID, member)
+			iMgr.deleteMemberForApply(creator, set, member)
 		}
 		for member := range diff.membersToAdd {
-			iMgr.addMemberForApply(creator, set, sectionID, member)
+			iMgr.addMemberForApply(creator, set, member)
 		}
 	}
 
@@ -522,6 +532,7 @@ func (iMgr *IPSetManager) fileCreatorForApply(maxTryCount int) *ioutil.FileCreat
 
 // updates the creator (adds/deletes members) for dirty sets already in the kernel
 // updates the setsToAddOrUpdate: after calling this function, the map will only consist of sets to create
+// NOTE: only the IPv4 sets are read from the save file, so IPv6 members are always added, and stale IPv6 members aren't deleted.
 // error handling principal:
 // - if contract with ipset save (or grep) is breaking, salvage what we can, take a snapshot (TODO), and log the failure
 // - have a background process for sending/removing snapshots intermittently
@@ -622,13 +633,12 @@ func (iMgr *IPSetManager) updateDirtyKernelSets(setsToAddOrUpdate map[string]str
 		}
 
 		// 3.5 delete undesired members from restore file
-		sectionID := sectionID(addOrUpdateSectionPrefix, prefixedName)
 		for member := range membersToDelete {
-			iMgr.deleteMemberForApply(creator, set, sectionID, member)
+			iMgr.deleteMemberForApply(creator, set, member)
 		}
 		// 3.5 add new members to restore file
 		for member := range membersToAdd {
-			iMgr.addMemberForApply(creator, set, sectionID, member)
+			iMgr.addMemberForApply(creator, set, member)
 		}
 	}
 }
@@ -682,50 +692,56 @@ func hasPrefix(line []byte, prefix string) bool {
 }
 
 func (iMgr *IPSetManager) flushSetForApply(creator *ioutil.FileCreator, prefixedName string) {
-	errorHandlers := []*ioutil.LineErrorHandler{
-		{
-			Definition: setDoesntExistDefinition,
-			Method:     ioutil.ContinueAndAbortSection,
-			Callback: func() {
-				klog.Infof("skipping flush and upcoming destroy for set %s since the set doesn't exist", prefixedName)
+	for _, family := range iMgr.families() {
+		familyName := HashedNameForFamily(prefixedName, family)
+		errorHandlers := []*ioutil.LineErrorHandler{
+			{
+				Definition: setDoesntExistDefinition,
+				Method:     ioutil.ContinueAndAbortSection,
+				Callback: func() {
+					klog.Infof("skipping flush and upcoming destroy for set %s since the set doesn't exist", familyName)
+				},
 			},
-		},
-		{
-			Definition: ioutil.AlwaysMatchDefinition,
-			Method:     ioutil.ContinueAndAbortSection,
-			Callback: func() {
-				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping flush and upcoming destroy for set %s due to unknown error", prefixedName)
-				// TODO mark as a failure
-				// would this ever happen?
+			{
+				Definition: ioutil.AlwaysMatchDefinition,
+				Method:     ioutil.ContinueAndAbortSection,
+				Callback: func() {
+					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping flush and upcoming destroy for set %s due to unknown error", familyName)
+					// TODO mark as a failure
+					// would this ever happen?
+				},
 			},
-		},
+		}
+		sectionID := sectionID(destroySectionPrefix, familyName)
+		hashedName := HashedNameForFamily(util.GetHashedName(prefixedName), family)
+		creator.AddLine(sectionID, errorHandlers, ipsetFlushFlag, hashedName) // flush set
 	}
-	sectionID := sectionID(destroySectionPrefix, prefixedName)
-	hashedName := util.GetHashedName(prefixedName)
-	creator.AddLine(sectionID, errorHandlers, ipsetFlushFlag, hashedName) // flush set
 }
 
 func (iMgr *IPSetManager) destroySetForApply(creator *ioutil.FileCreator, prefixedName string) {
-	errorHandlers := []*ioutil.LineErrorHandler{
-		{
-			Definition: setInUseByKernelDefinition,
-			Method:     ioutil.Continue,
-			Callback: func() {
-				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping destroy line for set %s since the set is in use by a kernel component", prefixedName)
-				// TODO mark the set as a failure and reconcile what iptables rule or ipset is referring to it
+	for _, family := range iMgr.families() {
+		familyName := HashedNameForFamily(prefixedName, family)
+		errorHandlers := []*ioutil.LineErrorHandler{
+			{
+				Definition: setInUseByKernelDefinition,
+				Method:     ioutil.Continue,
+				Callback: func() {
+					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping destroy line for set %s since the set is in use by a kernel component", familyName)
+					// TODO mark the set as a failure and reconcile what iptables rule or ipset is referring to it
+				},
 			},
-		},
-		{
-			Definition: ioutil.AlwaysMatchDefinition,
-			Method:     ioutil.Continue,
-			Callback: func() {
-				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping destroy line for set %s due to unknown error", prefixedName)
+			{
+				Definition: ioutil.AlwaysMatchDefinition,
+				Method:     ioutil.Continue,
+				Callback: func() {
+					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping destroy line for set %s due to unknown error", familyName)
+				},
 			},
-		},
+		}
+		sectionID := sectionID(destroySectionPrefix, familyName)
+		hashedName := HashedNameForFamily(util.GetHashedName(prefixedName), family)
+		creator.AddLine(sectionID, errorHandlers, ipsetDestroyFlag, hashedName) // destroy set
 	}
-	sectionID := sectionID(destroySectionPrefix, prefixedName)
-	hashedName := util.GetHashedName(prefixedName)
-	creator.AddLine(sectionID, errorHandlers, ipsetDestroyFlag, hashedName) // destroy set
 }
 
 func (iMgr *IPSetManager) createSetForApply(creator *ioutil.FileCreator, set *IPSet) {
@@ -736,79 +752,124 @@ func (iMgr *IPSetManager) createSetForApply(creator *ioutil.FileCreator, set *IP
 		methodFlag = ipsetIPPortHashFlag
 	}
 
-	specs := []string{ipsetCreateFlag, set.HashedName, ipsetExistFlag, methodFlag}
-	if set.Type == CIDRBlocks {
-		specs = append(specs, ipsetMaxelemName, ipsetMaxelemNum)
-	}
-
-	prefixedName := set.Name // to appease golint complaints about function literal
-	errorHandlers := []*ioutil.LineErrorHandler{
-		{
-			Definition: setAlreadyExistsDefinition,
-			Method:     ioutil.ContinueAndAbortSection,
-			Callback: func() {
-				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping create and any following adds/deletes for set %s since the set already exists with different specs", prefixedName)
-				// TODO mark the set as a failure and handle this
-			},
-		},
-		{
-			Definition: ioutil.AlwaysMatchDefinition,
-			Method:     ioutil.ContinueAndAbortSection,
-			Callback: func() {
-				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping create and any following adds/deletes for set %s due to unknown error", prefixedName)
-				// TODO same as above error handler
-			},
-		},
-	}
-	sectionID := sectionID(addOrUpdateSectionPrefix, prefixedName)
-	creator.AddLine(sectionID, errorHandlers, specs...) // create set
-}
-
-func (iMgr *IPSetManager) deleteMemberForApply(creator *ioutil.FileCreator, set *IPSet, sectionID, member string) {
-	errorHandlers := []*ioutil.LineErrorHandler{
-		{
-			Definition: ioutil.AlwaysMatchDefinition,
-			Method:     ioutil.Continue,
-			Callback: func() {
-				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping delete line for set %s due to unknown error", set.Name)
-			},
-		},
-	}
-	creator.AddLine(sectionID, errorHandlers, ipsetDeleteFlag, set.HashedName, member) // delete member
-}
+	for _, family := range iMgr.families() {
+		specs := []string{ipsetCreateFlag, HashedNameForFamily(set.HashedName, family), ipsetExistFlag, methodFlag}
+		if family == IPv6 && set.Kind == HashSet {
+			specs = append(specs, ipsetFamilyFlag, string(IPv6))
+		}
+		if set.Type == CIDRBlocks {
+			specs = append(specs, ipsetMaxelemName, ipsetMaxelemNum)
+		}
 
-func (iMgr *IPSetManager) addMemberForApply(creator *ioutil.FileCreator, set *IPSet, sectionID, member string) {
-	var errorHandlers []*ioutil.LineErrorHandler
-	if set.Kind == ListSet {
-		errorHandlers = []*ioutil.LineErrorHandler{
+		familyName := HashedNameForFamily(set.Name, family) // to appease golint complaints about function literal
+		errorHandlers := []*ioutil.LineErrorHandler{
 			{
-				Definition: memberSetDoesntExistDefinition,
-				Method:     ioutil.Continue,
+				Definition: setAlreadyExistsDefinition,
+				Method:     ioutil.ContinueAndAbortSection,
 				Callback: func() {
-					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping add of %s to list %s since the member doesn't exist", member, set.Name)
-					// TODO reconcile
+					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping create and any following adds/deletes for set %s since the set already exists with different specs", familyName)
+					// TODO mark the set as a failure and handle this
 				},
 			},
 			{
 				Definition: ioutil.AlwaysMatchDefinition,
-				Method:     ioutil.Continue,
+				Method:     ioutil.ContinueAndAbortSection,
 				Callback: func() {
-					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping add of %s to list %s due to unknown error", member, set.Name)
+					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping create and any following adds/deletes for set %s due to unknown error", familyName)
+					// TODO same as above error handler
 				},
 			},
 		}
-	} else {
-		errorHandlers = []*ioutil.LineErrorHandler{
+		sectionID := sectionID(addOrUpdateSectionPrefix, familyName)
+		creator.AddLine(sectionID, errorHandlers, specs...) // create set
+	}
+}
+
+func (iMgr *IPSetManager) deleteMemberForApply(creator *ioutil.FileCreator, set *IPSet, member string) {
+	for _, family := range iMgr.memberFamilies(set, member) {
+		familyName := HashedNameForFamily(set.Name, family)
+		errorHandlers := []*ioutil.LineErrorHandler{
 			{
 				Definition: ioutil.AlwaysMatchDefinition,
 				Method:     ioutil.Continue,
 				Callback: func() {
-					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping add line for hash set %s due to unknown error", set.Name)
+					metrics.SendErrorLogAndMetric(util.IpsmID, "skipping delete line for set %s due to unknown error", familyName)
 				},
 			},
 		}
+		sectionID := sectionID(addOrUpdateSectionPrefix, familyName)
+		creator.AddLine(sectionID, errorHandlers, ipsetDeleteFlag, HashedNameForFamily(set.HashedName, family), memberForFamily(set, member, family)) // delete member
+	}
+}
+
+func (iMgr *IPSetManager) addMemberForApply(creator *ioutil.FileCreator, set *IPSet, member string) {
+	for _, family := range iMgr.memberFamilies(set, member) {
+		familyName := HashedNameForFamily(set.Name, family)
+		familyMember := memberForFamily(set, member, family)
+		var errorHandlers []*ioutil.LineErrorHandler
+		if set.Kind == ListSet {
+			errorHandlers = []*ioutil.LineErrorHandler{
+				{
+					Definition: memberSetDoesntExistDefinition,
+					Method:     ioutil.Continue,
+					Callback: func() {
+						metrics.SendErrorLogAndMetric(util.IpsmID, "skipping add of %s to list %s since the member doesn't exist", familyMember, familyName)
+						// TODO reconcile
+					},
+				},
+				{
+					Definition: ioutil.AlwaysMatchDefinition,
+					Method:     ioutil.Continue,
+					Callback: func() {
+						metrics.SendErrorLogAndMetric(util.IpsmID, "skipping add of %s to list %s due to unknown error", familyMember, familyName)
+					},
+				},
+			}
+		} else {
+			errorHandlers = []*ioutil.LineErrorHandler{
+				{
+					Definition: ioutil.AlwaysMatchDefinition,
+					Method:     ioutil.Continue,
+					Callback: func() {
+						metrics.SendErrorLogAndMetric(util.IpsmID, "skipping add line for hash set %s due to unknown error", familyName)
+					},
+				},
+			}
+		}
+		sectionID := sectionID(addOrUpdateSectionPrefix, familyName)
+		creator.AddLine(sectionID, errorHandlers, ipsetAddFlag, HashedNameForFamily(set.HashedName, family), familyMember) // add member
+	}
+}
+
+// families returns the families of the kernel sets created for each IPSet.
+func (iMgr *IPSetManager) families() []IPFamily {
+	if iMgr.iMgrCfg.EnableIPv6 {
+		return []IPFamily{IPv4, IPv6}
+	}
+	return []IPFamily{IPv4}
+}
+
+// memberFamilies returns the families of the kernel sets which the member belongs in.
+// A HashSet member belongs in the set of its family, which is skipped for IPv6 if IPv6 isn't enabled.
+// A list member belongs in the list of each family.
+func (iMgr *IPSetManager) memberFamilies(set *IPSet, member string) []IPFamily {
+	if set.Kind == ListSet {
+		return iMgr.families()
+	}
+	family := MemberFamily(member)
+	if family == IPv6 && !iMgr.iMgrCfg.EnableIPv6 {
+		return nil
+	}
+	return []IPFamily{family}
+}
+
+// memberForFamily returns the member of the kernel set of the given family.
+// For a list, this is the counterpart of the member set in the same family.
+func memberForFamily(set *IPSet, member string, family IPFamily) string {
+	if set.Kind == ListSet {
+		return HashedNameForFamily(member, family)
 	}
-	creator.AddLine(sectionID, errorHandlers, ipsetAddFlag, set.HashedName, member) // add member
+	return member
 }
 
 func sectionID(prefix, prefixedName string) string {
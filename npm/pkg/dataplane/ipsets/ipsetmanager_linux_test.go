@@ -31,6 +31,12 @@ var (
 	resetIPSetsListOutputString = strings.Join(resetIPSetsNames, "\n") + "\n"
 	resetIPSetsListOutput       = []byte(resetIPSetsListOutputString)
 	otherIPSetsListOutput       = "azure-npm-123456\n"
+
+	applyAlwaysIPv6Cfg = &IPSetManagerCfg{
+		IPSetMode:   ApplyAllIPSets,
+		NetworkName: "azure",
+		EnableIPv6:  true,
+	}
 )
 
 // TODO test that a reconcile list is updated for all the TestFailure UTs
@@ -464,7 +470,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, ExitCode: 1},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, ExitCode: 1},
 				fakeRestoreSuccessCommand,
 			},
 			wantErr: false,
@@ -504,7 +510,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, ExitCode: 1},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, ExitCode: 1},
 				{Cmd: ipsetRestoreStringSlice, ExitCode: 1},
 				{Cmd: ipsetRestoreStringSlice, ExitCode: 1},
 				{Cmd: ipsetRestoreStringSlice, ExitCode: 1},
@@ -523,7 +529,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, Stdout: resetIPSetsListOutputString},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, Stdout: resetIPSetsListOutputString},
 			},
 			wantErr: false,
 		},
@@ -537,7 +543,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, Stdout: otherIPSetsListOutput},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, Stdout: otherIPSetsListOutput},
 				fakeRestoreSuccessCommand,
 			},
 			wantErr: false,
@@ -557,7 +563,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, ExitCode: 1},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, ExitCode: 1},
 				fakeRestoreSuccessCommand,
 			},
 			wantErr: false,
@@ -577,7 +583,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, ExitCode: 1},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, ExitCode: 1},
 				fakeRestoreSuccessCommand,
 			},
 			wantErr: false,
@@ -592,7 +598,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, ExitCode: 1},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, ExitCode: 1},
 				{
 					Cmd:      ipsetRestoreStringSlice,
 					Stdout:   "Error in line 2: The set with the given name does not exist",
@@ -612,7 +618,7 @@ func TestDestroyNPMIPSets(t *testing.T) {
 				fakeRestoreSuccessCommand,
 				{Cmd: []string{"ipset", "list"}, PipedToCommand: true},
 				{Cmd: []string{"grep", "-B", "5", "-P", "References: [1-9]"}, PipedToCommand: true},
-				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+"}, ExitCode: 1},
+				{Cmd: []string{"grep", "-o", "-P", "azure-npm-\\d+(-v6)?"}, ExitCode: 1},
 				{
 					Cmd:      ipsetRestoreStringSlice,
 					Stdout:   "Error in line 2: for some other error",
@@ -992,6 +998,93 @@ func TestDeleteMembers(t *testing.T) {
 	require.False(t, wasFileAltered, "file should not be altered")
 }
 
+func TestApplyWithIPv6(t *testing.T) {
+	tests := []struct {
+		name         string
+		withSaveFile bool
+	}{
+		{name: "with save file", withSaveFile: true},
+		{name: "no save file", withSaveFile: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			calls := []testutils.TestCmd{fakeRestoreSuccessCommand}
+			ioshim := common.NewMockIOShim(calls)
+			defer ioshim.VerifyCalls(t, calls)
+			iMgr := NewIPSetManager(applyAlwaysIPv6Cfg, ioshim)
+
+			iMgr.CreateIPSets([]*IPSetMetadata{TestCIDRSet.Metadata}) // create so we can delete
+			// clear dirty cache, otherwise a set deletion will be a no-op
+			iMgr.clearDirtyCache()
+
+			require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+			require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "2001:db8::1", "b"))
+			require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNamedportSet.Metadata}, "2001:db8::1,tcp:80", "b"))
+			require.NoError(t, iMgr.AddToLists([]*IPSetMetadata{TestKeyNSList.Metadata}, []*IPSetMetadata{TestNSSet.Metadata}))
+			iMgr.DeleteIPSet(TestCIDRSet.PrefixName, util.SoftDelete)
+
+			var creator *ioutil.FileCreator
+			if tt.withSaveFile {
+				creator = iMgr.fileCreatorForApplyWithSaveFile(len(calls), nil)
+			} else {
+				creator = iMgr.fileCreatorForApply(len(calls))
+			}
+			actualLines := testAndSortRestoreFileString(t, creator.ToString())
+
+			expectedLines := []string{
+				fmt.Sprintf("-N %s --exist nethash", TestNSSet.HashedName),
+				fmt.Sprintf("-N %s-v6 --exist nethash family inet6", TestNSSet.HashedName),
+				fmt.Sprintf("-N %s --exist hash:ip,port", TestNamedportSet.HashedName),
+				fmt.Sprintf("-N %s-v6 --exist hash:ip,port family inet6", TestNamedportSet.HashedName),
+				fmt.Sprintf("-N %s --exist setlist", TestKeyNSList.HashedName),
+				fmt.Sprintf("-N %s-v6 --exist setlist", TestKeyNSList.HashedName),
+				fmt.Sprintf("-A %s 10.0.0.0", TestNSSet.HashedName),
+				fmt.Sprintf("-A %s-v6 2001:db8::1", TestNSSet.HashedName),
+				fmt.Sprintf("-A %s-v6 2001:db8::1,tcp:80", TestNamedportSet.HashedName),
+				fmt.Sprintf("-A %s %s", TestKeyNSList.HashedName, TestNSSet.HashedName),
+				fmt.Sprintf("-A %s-v6 %s-v6", TestKeyNSList.HashedName, TestNSSet.HashedName),
+				fmt.Sprintf("-F %s", TestCIDRSet.HashedName),
+				fmt.Sprintf("-F %s-v6", TestCIDRSet.HashedName),
+				fmt.Sprintf("-X %s", TestCIDRSet.HashedName),
+				fmt.Sprintf("-X %s-v6", TestCIDRSet.HashedName),
+				"",
+			}
+			sortedExpectedLines := testAndSortRestoreFileLines(t, expectedLines)
+
+			dptestutils.AssertEqualLines(t, sortedExpectedLines, actualLines)
+			wasFileAltered, err := creator.RunCommandOnceWithFile("ipset", "restore")
+			require.NoError(t, err, "ipset restore should be successful")
+			require.False(t, wasFileAltered, "file should not be altered")
+		})
+	}
+}
+
+// IPv6 members stay in the cache but aren't added to the kernel when IPv6 isn't enabled
+func TestIPv6MembersSkippedWithoutIPv6(t *testing.T) {
+	calls := []testutils.TestCmd{fakeRestoreSuccessCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	iMgr := NewIPSetManager(applyAlwaysCfg, ioshim)
+
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "2001:db8::1", "b"))
+	require.True(t, iMgr.setMap[TestNSSet.PrefixName].IPPodKey["2001:db8::1"] == "b", "IPv6 member should be in the cache")
+
+	expectedLines := []string{
+		fmt.Sprintf("-N %s --exist nethash", TestNSSet.HashedName),
+		fmt.Sprintf("-A %s 10.0.0.0", TestNSSet.HashedName),
+		"",
+	}
+	sortedExpectedLines := testAndSortRestoreFileLines(t, expectedLines)
+	creator := iMgr.fileCreatorForApply(len(calls))
+	actualLines := testAndSortRestoreFileString(t, creator.ToString())
+	dptestutils.AssertEqualLines(t, sortedExpectedLines, actualLines)
+	wasFileAltered, err := creator.RunCommandOnceWithFile("ipset", "restore")
+	require.NoError(t, err, "ipset restore should be successful")
+	require.False(t, wasFileAltered, "file should not be altered")
+}
+
 func TestUpdateWithIdenticalSaveFile(t *testing.T) {
 	calls := []testutils.TestCmd{fakeRestoreSuccessCommand}
 	ioshim := common.NewMockIOShim(calls)
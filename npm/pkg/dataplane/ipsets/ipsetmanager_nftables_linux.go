@@ -0,0 +1,362 @@
+package ipsets
+
+// This file contains code for the nftables implementation of applying IPSets.
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/util"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+	"k8s.io/klog"
+)
+
+const (
+	// nft reports errors like "/dev/stdin:3:1-35: Error: Could not process rule: No such file or directory"
+	nftLineFailurePattern = ":(\\d+):\\d+-\\d+: Error:"
+
+	nftNoMatchSuffix   = " nomatch"
+	nftDefaultProtocol = "tcp"
+)
+
+/*
+applyIPSetsWithNFTables programs the dirty sets as native nftables sets in one atomic nft transaction.
+
+nftables sets can't contain other sets, so a list is programmed as the union of the IPs of its member sets.
+A list is reprogrammed whenever it or one of its members is dirty.
+hash:net "nomatch" members are resolved into the set of intervals that the ipset would match.
+
+Each dirty set is flushed and all of its members are added back, so the member diff isn't needed.
+If a line fails, nft applies none of the transaction, so only the failed set's lines are skipped on retry (see ioutil.AbortSection).
+
+example nft file where set1 is updated and set2 is destroyed:
+
+	add table inet azure-npm
+	add set inet azure-npm azure-npm-1111 { type ipv4_addr; flags interval; auto-merge; }
+	flush set inet azure-npm azure-npm-1111
+	add element inet azure-npm azure-npm-1111 { 10.0.0.1, 10.0.0.2 }
+	delete set inet azure-npm azure-npm-2222
+*/
+func (iMgr *IPSetManager) applyIPSetsWithNFTables() error {
+	creator := iMgr.fileCreatorForNFTablesApply(maxTryCount, nil)
+	err := creator.RunCommandWithFile(util.Nft, util.NftFileFlag, util.NftStdinFile)
+	if err != nil {
+		return npmerrors.SimpleErrorWrapper("nft transaction failed when applying ipsets", err)
+	}
+	return nil
+}
+
+// ApplyIPSetsWithNFTablesLines applies the dirty sets like ApplyIPSets, in one nft transaction with the lines that writeLines adds.
+// The lines come after sets are added or updated and before sets are deleted, so they can add rules referencing new sets
+// and remove the rules referencing deleted sets. The DataPlane applies policies with it (see PolicyManager.SetNFTablesTransaction()).
+func (iMgr *IPSetManager) ApplyIPSetsWithNFTablesLines(writeLines func(creator *ioutil.FileCreator)) error {
+	iMgr.Lock()
+	defer iMgr.Unlock()
+
+	iMgr.sanitizeDirtyCache()
+
+	prometheusTimer := metrics.StartNewTimer()
+	defer metrics.RecordIPSetExecTime(prometheusTimer) // record execution time regardless of failure
+	creator := iMgr.fileCreatorForNFTablesApply(maxTryCount, writeLines)
+	err := creator.RunCommandWithFile(util.Nft, util.NftFileFlag, util.NftStdinFile)
+	if err != nil {
+		metrics.SendErrorLogAndMetric(util.IpsmID, "error: failed to apply ipsets with nft lines: %s", err.Error())
+		return npmerrors.SimpleErrorWrapper("nft transaction failed when applying ipsets with nft lines", err)
+	}
+
+	iMgr.clearDirtyCache()
+	return nil
+}
+
+// resetIPSetsForNFTables destroys the kernel ipsets left over from the iptables dataplane.
+// The nftables sets are deleted along with the azure-npm table when the PolicyManager boots up, which happens before resetting IPSets.
+func (iMgr *IPSetManager) resetIPSetsForNFTables() error {
+	if err := iMgr.resetKernelIPSets(); err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to destroy ipsets left over from the iptables dataplane", err)
+	}
+	return nil
+}
+
+// fileCreatorForNFTablesApply writes the dirty sets, with the lines of writeLines (if any) before the sets are deleted.
+func (iMgr *IPSetManager) fileCreatorForNFTablesApply(maxTryCount int, writeLines func(creator *ioutil.FileCreator)) *ioutil.FileCreator {
+	creator := ioutil.NewFileCreator(iMgr.ioShim, maxTryCount, nftLineFailurePattern)
+	creator.AddLine("", nil, "add", "table", util.NftAzureTableFamily, util.NftAzureTable)
+
+	// 1. reprogram the dirty sets and the lists that have dirty members
+	setsToAddOrUpdate := iMgr.dirtyCache.setsToAddOrUpdate()
+	setsToDelete := iMgr.dirtyCache.setsToDelete()
+	for _, set := range iMgr.listsWithDirtyMembers(setsToAddOrUpdate, setsToDelete) {
+		setsToAddOrUpdate[set.Name] = struct{}{}
+	}
+	for _, prefixedName := range sortedKeys(setsToAddOrUpdate) {
+		set := iMgr.setMap[prefixedName]
+		for _, family := range iMgr.families() {
+			iMgr.writeNFTablesSet(creator, set, family)
+		}
+	}
+
+	// 2. add the lines applied along with the sets, e.g. the rules of policies
+	if writeLines != nil {
+		writeLines(creator)
+	}
+
+	// 3. delete sets. No rule references them anymore, and lists don't reference sets in nftables.
+	for _, prefixedName := range sortedKeys(setsToDelete) {
+		hashedName := util.GetHashedName(prefixedName)
+		for _, family := range iMgr.families() {
+			familyName := HashedNameForFamily(prefixedName, family)
+			errorHandlers := []*ioutil.LineErrorHandler{
+				{
+					Definition: ioutil.AlwaysMatchDefinition,
+					Method:     ioutil.AbortSection,
+					Callback: func() {
+						metrics.SendErrorLogAndMetric(util.IpsmID, "skipping delete of nft set %s due to unknown error", familyName)
+					},
+				},
+			}
+			creator.AddLine(sectionID(destroySectionPrefix, familyName), errorHandlers, "delete", "set", util.NftAzureTableFamily, util.NftAzureTable, HashedNameForFamily(hashedName, family))
+		}
+	}
+	return creator
+}
+
+// listsWithDirtyMembers returns the lists in the kernel which aren't dirty but have a dirty member set.
+func (iMgr *IPSetManager) listsWithDirtyMembers(setsToAddOrUpdate, setsToDelete map[string]struct{}) []*IPSet {
+	lists := make([]*IPSet, 0)
+	for _, set := range iMgr.setMap {
+		if set.Kind != ListSet || !iMgr.shouldBeInKernel(set) {
+			continue
+		}
+		if _, ok := setsToAddOrUpdate[set.Name]; ok {
+			continue
+		}
+		for memberName := range set.MemberIPSets {
+			_, isUpdated := setsToAddOrUpdate[memberName]
+			_, isDeleted := setsToDelete[memberName]
+			if isUpdated || isDeleted {
+				lists = append(lists, set)
+				break
+			}
+		}
+	}
+	return lists
+}
+
+func (iMgr *IPSetManager) writeNFTablesSet(creator *ioutil.FileCreator, set *IPSet, family IPFamily) {
+	kernelName := HashedNameForFamily(set.HashedName, family)
+	familyName := HashedNameForFamily(set.Name, family)
+	sectionID := sectionID(addOrUpdateSectionPrefix, familyName)
+	errorHandlers := []*ioutil.LineErrorHandler{
+		{
+			Definition: ioutil.AlwaysMatchDefinition,
+			Method:     ioutil.AbortSection,
+			Callback: func() {
+				metrics.SendErrorLogAndMetric(util.IpsmID, "skipping nft set %s due to unknown error", familyName)
+			},
+		},
+	}
+
+	creator.AddLine(sectionID, errorHandlers, "add", "set", util.NftAzureTableFamily, util.NftAzureTable, kernelName, nftSetSpec(set, family))
+	creator.AddLine(sectionID, errorHandlers, "flush", "set", util.NftAzureTableFamily, util.NftAzureTable, kernelName)
+
+	elements := iMgr.nftElements(set, family)
+	if len(elements) == 0 {
+		return
+	}
+	creator.AddLine(sectionID, errorHandlers, "add", "element", util.NftAzureTableFamily, util.NftAzureTable, kernelName, "{", strings.Join(elements, ", "), "}")
+}
+
+// nftSetSpec returns the type and flags of the nftables set.
+func nftSetSpec(set *IPSet, family IPFamily) string {
+	addrType := "ipv4_addr"
+	if family == IPv6 {
+		addrType = "ipv6_addr"
+	}
+	if set.Type == NamedPorts {
+		return fmt.Sprintf("{ type %s . inet_proto . inet_service; }", addrType)
+	}
+	return fmt.Sprintf("{ type %s; flags interval; auto-merge; }", addrType)
+}
+
+// nftElements returns the sorted elements of the nftables set of the given family.
+func (iMgr *IPSetManager) nftElements(set *IPSet, family IPFamily) []string {
+	if set.Kind == ListSet {
+		members := make(map[string]struct{})
+		for _, memberSet := range set.MemberIPSets {
+			for member := range memberSet.IPPodKey {
+				if MemberFamily(member) == family {
+					members[member] = struct{}{}
+				}
+			}
+		}
+		return nftIntervalElements(sortedKeys(members))
+	}
+
+	members := make([]string, 0, len(set.IPPodKey))
+	for member := range set.IPPodKey {
+		if MemberFamily(member) == family {
+			members = append(members, member)
+		}
+	}
+	sort.Strings(members)
+
+	if set.Type == NamedPorts {
+		elements := make([]string, 0, len(members))
+		for _, member := range members {
+			elements = append(elements, nftNamedPortElement(member))
+		}
+		return elements
+	}
+	return nftIntervalElements(members)
+}
+
+// nftNamedPortElement converts a hash:ip,port member like "10.0.0.1,TCP:8080" into "10.0.0.1 . tcp . 8080".
+// Like ipset, the protocol defaults to tcp.
+func nftNamedPortElement(member string) string {
+	ipAndPort := strings.SplitN(member, ",", 2)
+	if len(ipAndPort) != 2 {
+		return member
+	}
+	protocol := nftDefaultProtocol
+	port := ipAndPort[1]
+	if protoAndPort := strings.SplitN(port, ":", 2); len(protoAndPort) == 2 {
+		protocol = strings.ToLower(protoAndPort[0])
+		port = protoAndPort[1]
+	}
+	return fmt.Sprintf("%s . %s . %s", ipAndPort[0], protocol, port)
+}
+
+type addrRange struct {
+	start netip.Addr
+	end   netip.Addr
+}
+
+// nftIntervalElements converts hash:net members into elements of an nftables interval set.
+// Without "nomatch" members, the members are returned as is since auto-merge handles overlaps.
+// Otherwise, like ipset, the most specific prefix containing an IP decides whether the IP matches,
+// so prefixes are applied from least to most specific and the resulting ranges are returned.
+func nftIntervalElements(members []string) []string {
+	hasNoMatch := false
+	for _, member := range members {
+		if strings.HasSuffix(member, nftNoMatchSuffix) {
+			hasNoMatch = true
+			break
+		}
+	}
+	if !hasNoMatch {
+		return members
+	}
+
+	type prefixMember struct {
+		prefix  netip.Prefix
+		nomatch bool
+	}
+	prefixes := make([]prefixMember, 0, len(members))
+	for _, member := range members {
+		cidr := strings.TrimSuffix(member, nftNoMatchSuffix)
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			klog.Warningf("ignoring member %s of nft interval set since it isn't an IP or CIDR. err: %v", member, err)
+			continue
+		}
+		prefixes = append(prefixes, prefixMember{prefix: prefix.Masked(), nomatch: cidr != member})
+	}
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return prefixes[i].prefix.Bits() < prefixes[j].prefix.Bits()
+	})
+
+	ranges := make([]addrRange, 0, len(prefixes))
+	for _, p := range prefixes {
+		r := addrRange{start: p.prefix.Addr(), end: lastAddr(p.prefix)}
+		if p.nomatch {
+			ranges = subtractRange(ranges, r)
+		} else {
+			ranges = unionRange(ranges, r)
+		}
+	}
+
+	elements := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r.start == r.end {
+			elements = append(elements, r.start.String())
+		} else {
+			elements = append(elements, fmt.Sprintf("%s-%s", r.start, r.end))
+		}
+	}
+	return elements
+}
+
+func parsePrefix(cidr string) (netip.Prefix, error) {
+	if strings.Contains(cidr, "/") {
+		return netip.ParsePrefix(cidr) //nolint:wrapcheck // caller logs the error
+	}
+	addr, err := netip.ParseAddr(cidr)
+	if err != nil {
+		return netip.Prefix{}, err //nolint:wrapcheck // caller logs the error
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// lastAddr returns the last address in the masked prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// unionRange adds r to the sorted, disjoint ranges, merging overlapping and adjacent ranges.
+func unionRange(ranges []addrRange, r addrRange) []addrRange {
+	all := append(ranges, r) //nolint:gocritic // ranges is replaced by the result
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].start.Less(all[j].start)
+	})
+	result := make([]addrRange, 0, len(all))
+	for _, current := range all {
+		if len(result) > 0 {
+			last := &result[len(result)-1]
+			next := last.end.Next()
+			if !next.IsValid() || current.start.Compare(next) <= 0 {
+				if last.end.Less(current.end) {
+					last.end = current.end
+				}
+				continue
+			}
+		}
+		result = append(result, current)
+	}
+	return result
+}
+
+// subtractRange removes r from the sorted, disjoint ranges.
+func subtractRange(ranges []addrRange, r addrRange) []addrRange {
+	result := make([]addrRange, 0, len(ranges)+1)
+	for _, current := range ranges {
+		if current.end.Less(r.start) || r.end.Less(current.start) {
+			result = append(result, current)
+			continue
+		}
+		if current.start.Less(r.start) {
+			result = append(result, addrRange{start: current.start, end: r.start.Prev()})
+		}
+		if r.end.Less(current.end) {
+			result = append(result, addrRange{start: r.end.Next(), end: current.end})
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
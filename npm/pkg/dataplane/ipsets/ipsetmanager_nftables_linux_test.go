@@ -0,0 +1,289 @@
+package ipsets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	dptestutils "github.com/Azure/azure-container-networking/npm/pkg/dataplane/testutils"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+	testutils "github.com/Azure/azure-container-networking/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	nftIntervalSpec      = "{ type ipv4_addr; flags interval; auto-merge; }"
+	nftIntervalIPv6Spec  = "{ type ipv6_addr; flags interval; auto-merge; }"
+	nftNamedPortSpec     = "{ type ipv4_addr . inet_proto . inet_service; }"
+	nftNamedPortIPv6Spec = "{ type ipv6_addr . inet_proto . inet_service; }"
+)
+
+var (
+	fakeNFTSuccessCommand = testutils.TestCmd{Cmd: []string{"nft", "-f", "-"}}
+
+	applyAlwaysNFTablesCfg = &IPSetManagerCfg{
+		IPSetMode:      ApplyAllIPSets,
+		NetworkName:    "azure",
+		EnableNFTables: true,
+	}
+	applyAlwaysNFTablesIPv6Cfg = &IPSetManagerCfg{
+		IPSetMode:      ApplyAllIPSets,
+		NetworkName:    "azure",
+		EnableIPv6:     true,
+		EnableNFTables: true,
+	}
+)
+
+type nftExpectedSet struct {
+	prefixName string
+	lines      []string
+}
+
+func nftSetLines(set *TestSet, kernelName, spec string, elements ...string) nftExpectedSet {
+	lines := []string{
+		fmt.Sprintf("add set inet azure-npm %s %s", kernelName, spec),
+		fmt.Sprintf("flush set inet azure-npm %s", kernelName),
+	}
+	if len(elements) > 0 {
+		lines = append(lines, fmt.Sprintf("add element inet azure-npm %s { %s }", kernelName, strings.Join(elements, ", ")))
+	}
+	return nftExpectedSet{prefixName: set.PrefixName, lines: lines}
+}
+
+// nftExpectedLines orders the sets by prefixed name like fileCreatorForNFTablesApply.
+// The lines of each family are kept in the given order.
+func nftExpectedLines(sets []nftExpectedSet, deleteLines ...string) []string {
+	sort.SliceStable(sets, func(i, j int) bool {
+		return sets[i].prefixName < sets[j].prefixName
+	})
+	lines := []string{"add table inet azure-npm"}
+	for _, set := range sets {
+		lines = append(lines, set.lines...)
+	}
+	lines = append(lines, deleteLines...)
+	return append(lines, "")
+}
+
+func TestNFTablesCreateForAllSetTypes(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTSuccessCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	iMgr := NewIPSetManager(applyAlwaysNFTablesCfg, ioshim)
+
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.1", "b"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestKeyPodSet.Metadata}, "10.0.0.5", "c"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNamedportSet.Metadata}, "10.0.0.5,UDP:53", "c"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNamedportSet.Metadata}, "10.0.0.5,8080", "c"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestCIDRSet.Metadata}, "10.0.0.0/16", ""))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestCIDRSet.Metadata}, "10.0.1.0/24 nomatch", ""))
+	iMgr.CreateIPSets([]*IPSetMetadata{TestKVPodSet.Metadata})
+	require.NoError(t, iMgr.AddToLists([]*IPSetMetadata{TestKeyNSList.Metadata}, []*IPSetMetadata{TestNSSet.Metadata, TestKeyPodSet.Metadata}))
+	iMgr.CreateIPSets([]*IPSetMetadata{TestNestedLabelList.Metadata})
+
+	creator := iMgr.fileCreatorForNFTablesApply(len(calls), nil)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := nftExpectedLines([]nftExpectedSet{
+		nftSetLines(TestNSSet, TestNSSet.HashedName, nftIntervalSpec, "10.0.0.0", "10.0.0.1"),
+		nftSetLines(TestKeyPodSet, TestKeyPodSet.HashedName, nftIntervalSpec, "10.0.0.5"),
+		nftSetLines(TestKVPodSet, TestKVPodSet.HashedName, nftIntervalSpec),
+		nftSetLines(TestNamedportSet, TestNamedportSet.HashedName, nftNamedPortSpec, "10.0.0.5 . tcp . 8080", "10.0.0.5 . udp . 53"),
+		nftSetLines(TestCIDRSet, TestCIDRSet.HashedName, nftIntervalSpec, "10.0.0.0-10.0.0.255", "10.0.2.0-10.0.255.255"),
+		// lists are flattened
+		nftSetLines(TestKeyNSList, TestKeyNSList.HashedName, nftIntervalSpec, "10.0.0.0", "10.0.0.1", "10.0.0.5"),
+		nftSetLines(TestNestedLabelList, TestNestedLabelList.HashedName, nftIntervalSpec),
+	})
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	require.NoError(t, iMgr.ApplyIPSets())
+}
+
+func TestNFTablesUpdateAndDelete(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTSuccessCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	iMgr := NewIPSetManager(applyAlwaysNFTablesCfg, ioshim)
+
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.1", "b"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestKeyPodSet.Metadata}, "10.0.0.5", "c"))
+	require.NoError(t, iMgr.AddToLists([]*IPSetMetadata{TestKeyNSList.Metadata}, []*IPSetMetadata{TestNSSet.Metadata}))
+	iMgr.CreateIPSets([]*IPSetMetadata{TestCIDRSet.Metadata})
+	iMgr.clearDirtyCache()
+
+	// the list isn't dirty, but it's reprogrammed since its member is dirty
+	require.NoError(t, iMgr.RemoveFromSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.1", "b"))
+	iMgr.DeleteIPSet(TestCIDRSet.PrefixName, util.SoftDelete)
+
+	creator := iMgr.fileCreatorForNFTablesApply(len(calls), nil)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := nftExpectedLines(
+		[]nftExpectedSet{
+			nftSetLines(TestNSSet, TestNSSet.HashedName, nftIntervalSpec, "10.0.0.0"),
+			nftSetLines(TestKeyNSList, TestKeyNSList.HashedName, nftIntervalSpec, "10.0.0.0"),
+		},
+		"delete set inet azure-npm "+TestCIDRSet.HashedName,
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	require.NoError(t, iMgr.ApplyIPSets())
+}
+
+func TestNFTablesApplyWithLines(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTSuccessCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	iMgr := NewIPSetManager(applyAlwaysNFTablesCfg, ioshim)
+
+	iMgr.CreateIPSets([]*IPSetMetadata{TestCIDRSet.Metadata})
+	iMgr.clearDirtyCache()
+
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+	iMgr.DeleteIPSet(TestCIDRSet.PrefixName, util.SoftDelete)
+
+	writeLines := func(creator *ioutil.FileCreator) {
+		creator.AddLine("", nil, "flush chain inet azure-npm AZURE-NPM")
+	}
+
+	creator := iMgr.fileCreatorForNFTablesApply(len(calls), writeLines)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := nftExpectedLines(
+		[]nftExpectedSet{
+			nftSetLines(TestNSSet, TestNSSet.HashedName, nftIntervalSpec, "10.0.0.0"),
+		},
+		// the lines come before the sets are deleted
+		"flush chain inet azure-npm AZURE-NPM",
+		"delete set inet azure-npm "+TestCIDRSet.HashedName,
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	require.NoError(t, iMgr.ApplyIPSetsWithNFTablesLines(writeLines))
+	// nothing is left to apply
+	require.NoError(t, iMgr.ApplyIPSets())
+}
+
+func TestNFTablesApplyWithIPv6(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTSuccessCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	iMgr := NewIPSetManager(applyAlwaysNFTablesIPv6Cfg, ioshim)
+
+	iMgr.CreateIPSets([]*IPSetMetadata{TestCIDRSet.Metadata}) // create so we can delete
+	iMgr.clearDirtyCache()
+
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "2001:db8::1", "b"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNamedportSet.Metadata}, "2001:db8::1,tcp:80", "b"))
+	iMgr.DeleteIPSet(TestCIDRSet.PrefixName, util.SoftDelete)
+
+	creator := iMgr.fileCreatorForNFTablesApply(len(calls), nil)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	nsSetLines := nftSetLines(TestNSSet, TestNSSet.HashedName, nftIntervalSpec, "10.0.0.0")
+	nsSetLines.lines = append(nsSetLines.lines, nftSetLines(TestNSSet, TestNSSet.HashedName+"-v6", nftIntervalIPv6Spec, "2001:db8::1").lines...)
+	namedPortSetLines := nftSetLines(TestNamedportSet, TestNamedportSet.HashedName, nftNamedPortSpec)
+	namedPortSetLines.lines = append(namedPortSetLines.lines, nftSetLines(TestNamedportSet, TestNamedportSet.HashedName+"-v6", nftNamedPortIPv6Spec, "2001:db8::1 . tcp . 80").lines...)
+	expectedLines := nftExpectedLines(
+		[]nftExpectedSet{nsSetLines, namedPortSetLines},
+		"delete set inet azure-npm "+TestCIDRSet.HashedName,
+		"delete set inet azure-npm "+TestCIDRSet.HashedName+"-v6",
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	require.NoError(t, iMgr.ApplyIPSets())
+}
+
+func TestNFTablesApplyFailure(t *testing.T) {
+	failure := testutils.TestCmd{
+		Cmd:      []string{"nft", "-f", "-"},
+		Stdout:   "/dev/stdin:3:1-50: Error: Could not process rule: No such file or directory",
+		ExitCode: 1,
+	}
+	calls := []testutils.TestCmd{failure, fakeNFTSuccessCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	iMgr := NewIPSetManager(applyAlwaysNFTablesCfg, ioshim)
+
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestNSSet.Metadata}, "10.0.0.0", "a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{TestKeyPodSet.Metadata}, "10.0.0.5", "c"))
+
+	creator := iMgr.fileCreatorForNFTablesApply(len(calls), nil)
+	wasFileAltered, err := creator.RunCommandOnceWithFile("nft", "-f", "-")
+	require.Error(t, err)
+	require.True(t, wasFileAltered)
+
+	// the whole section of the set on line 3 is skipped
+	expectedLines := nftExpectedLines([]nftExpectedSet{
+		nftSetLines(TestNSSet, TestNSSet.HashedName, nftIntervalSpec, "10.0.0.0"),
+		nftSetLines(TestKeyPodSet, TestKeyPodSet.HashedName, nftIntervalSpec, "10.0.0.5"),
+	})
+	expectedLines = append(expectedLines[:1], expectedLines[4:]...)
+	dptestutils.AssertEqualLines(t, expectedLines, strings.Split(creator.ToString(), "\n"))
+
+	wasFileAltered, err = creator.RunCommandOnceWithFile("nft", "-f", "-")
+	require.NoError(t, err)
+	require.False(t, wasFileAltered)
+}
+
+func TestNFTIntervalElements(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  []string
+		expected []string
+	}{
+		{
+			name:     "no nomatch",
+			members:  []string{"10.0.0.0/24", "10.0.0.5"},
+			expected: []string{"10.0.0.0/24", "10.0.0.5"},
+		},
+		{
+			name:     "nomatch in the middle",
+			members:  []string{"10.0.0.0/24", "10.0.0.128/25 nomatch", "10.0.0.129"},
+			expected: []string{"10.0.0.0-10.0.0.127", "10.0.0.129"},
+		},
+		{
+			name:     "nomatch at the edges",
+			members:  []string{"10.0.0.0/30", "10.0.0.0 nomatch", "10.0.0.3 nomatch"},
+			expected: []string{"10.0.0.1-10.0.0.2"},
+		},
+		{
+			name:     "more specific prefix wins",
+			members:  []string{"10.0.0.0/24", "10.0.0.0/16 nomatch"},
+			expected: []string{"10.0.0.0-10.0.0.255"},
+		},
+		{
+			name:     "nomatch everything",
+			members:  []string{"10.0.0.0/24", "10.0.0.0/24 nomatch"},
+			expected: []string{},
+		},
+		{
+			name:     "merge adjacent",
+			members:  []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.0.1 nomatch"},
+			expected: []string{"10.0.0.0", "10.0.0.2-10.0.0.255"},
+		},
+		{
+			name:     "whole address space",
+			members:  []string{"0.0.0.0/0", "10.0.0.0/8 nomatch"},
+			expected: []string{"0.0.0.0-9.255.255.255", "11.0.0.0-255.255.255.255"},
+		},
+		{
+			name:     "ipv6",
+			members:  []string{"2001:db8::/64", "2001:db8::/65 nomatch"},
+			expected: []string{"2001:db8:0:0:8000::-2001:db8::ffff:ffff:ffff:ffff"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, nftIntervalElements(tt.members))
+		})
+	}
+}
+
+func TestNFTNamedPortElement(t *testing.T) {
+	require.Equal(t, "10.0.0.1 . tcp . 8080", nftNamedPortElement("10.0.0.1,8080"))
+	require.Equal(t, "10.0.0.1 . udp . 53", nftNamedPortElement("10.0.0.1,UDP:53"))
+	require.Equal(t, "10.0.0.1 . sctp . 9000", nftNamedPortElement("10.0.0.1,sctp:9000"))
+}
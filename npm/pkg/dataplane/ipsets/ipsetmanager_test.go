@@ -600,6 +600,22 @@ func TestAddToSets(t *testing.T) {
 	ipv4 := "1.2.3.4"
 	ipv6 := "2001:db8:0:0:0:0:2:1"
 
+	// IPv6 members are only supported in Linux
+	ipv6Info := expectedInfo{
+		mainCache: []setMembers{
+			{metadata: namespaceSet, members: []member{}},
+		},
+	}
+	if !util.IsWindowsDP() {
+		ipv6Info = expectedInfo{
+			mainCache: []setMembers{
+				{metadata: namespaceSet, members: []member{{ipv6, isHashMember}}},
+			},
+			toAddUpdateCache: []*IPSetMetadata{namespaceSet},
+			setsForKernel:    []*IPSetMetadata{namespaceSet},
+		}
+	}
+
 	type args struct {
 		cfg                *IPSetManagerCfg
 		toCreateMetadatas  []*IPSetMetadata
@@ -693,15 +709,8 @@ func TestAddToSets(t *testing.T) {
 				toAddMetadatas:    []*IPSetMetadata{namespaceSet},
 				member:            ipv6,
 			},
-			expectedInfo: expectedInfo{
-				mainCache: []setMembers{
-					{metadata: namespaceSet, members: []member{}},
-				},
-				toAddUpdateCache: nil,
-				toDeleteCache:    nil,
-				setsForKernel:    nil,
-			},
-			wantErr: true,
+			expectedInfo: ipv6Info,
+			wantErr:      util.IsWindowsDP(),
 		},
 		{
 			name: "add cidr",
@@ -1509,7 +1518,7 @@ func TestValidateIPSetMemberIP(t *testing.T) {
 		{
 			name:    "ipv6",
 			ipblock: "2345:0425:2CA1:0000:0000:0567:5673:23b5/24",
-			want:    false,
+			want:    !util.IsWindowsDP(),
 		},
 		{
 			name:    "tcp",
@@ -1539,12 +1548,12 @@ func TestValidateIPSetMemberIP(t *testing.T) {
 		{
 			name:    "ipv6 tcp",
 			ipblock: "2345:0425:2CA1:0000:0000:0567:5673:23b5/24,tcp:25227",
-			want:    false,
+			want:    !util.IsWindowsDP(),
 		},
 		{
 			name:    "ipv6 nomatch",
 			ipblock: "2345:0425:2CA1:0000:0000:0567:5673:23b5 nomatch",
-			want:    false,
+			want:    !util.IsWindowsDP(),
 		},
 		{
 			name:    "invalid/0",
@@ -0,0 +1,124 @@
+package ipsets
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// defaultNamedPortProtocol is the protocol of a NamedPorts member without a protocol, like in ipset.
+const defaultNamedPortProtocol = "tcp"
+
+// ContainsIP returns whether the set with the prefixed name matches the IP, like the set would in the kernel:
+// - a list matches if any member set matches.
+// - a CIDRBlocks set matches if the most specific CIDR containing the IP isn't a "nomatch" member.
+// - a NamedPorts set matches if the IP is a member with the protocol and port.
+// Sets which aren't in the cache don't match.
+func (iMgr *IPSetManager) ContainsIP(setName, ip, protocol string, port int32) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	iMgr.RLock()
+	defer iMgr.RUnlock()
+	set, ok := iMgr.setMap[setName]
+	if !ok {
+		return false
+	}
+	return set.containsAddr(addr, protocol, port)
+}
+
+// NamespacePodIPs returns the IPs of the Pods in the namespace, mapped to their pod keys.
+func (iMgr *IPSetManager) NamespacePodIPs(namespace string) map[string]string {
+	iMgr.RLock()
+	defer iMgr.RUnlock()
+	podIPs := make(map[string]string)
+	set, ok := iMgr.setMap[NewIPSetMetadata(namespace, Namespace).GetPrefixName()]
+	if !ok {
+		return podIPs
+	}
+	for ip, podKey := range set.IPPodKey {
+		podIPs[ip] = podKey
+	}
+	return podIPs
+}
+
+func (set *IPSet) containsAddr(addr netip.Addr, protocol string, port int32) bool {
+	if set.Kind == ListSet {
+		for _, member := range set.MemberIPSets {
+			if member.containsAddr(addr, protocol, port) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch set.Type {
+	case CIDRBlocks:
+		return cidrMembersContain(set.IPPodKey, addr)
+	case NamedPorts:
+		for member := range set.IPPodKey {
+			if namedPortMemberMatches(member, addr, protocol, port) {
+				return true
+			}
+		}
+		return false
+	default:
+		for member := range set.IPPodKey {
+			if memberAddr, err := netip.ParseAddr(member); err == nil && memberAddr == addr {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// cidrMembersContain returns whether the hash:net members match the IP.
+// Like ipset, the most specific prefix containing the IP decides, so a "nomatch" member excludes IPs from a larger prefix.
+func cidrMembersContain(members map[string]string, addr netip.Addr) bool {
+	bestBits := -1
+	matches := false
+	for member := range members {
+		cidr := strings.TrimSuffix(member, " nomatch")
+		prefix, ok := memberPrefix(cidr)
+		if !ok || !prefix.Contains(addr) || prefix.Bits() <= bestBits {
+			continue
+		}
+		bestBits = prefix.Bits()
+		matches = cidr == member
+	}
+	return matches
+}
+
+func memberPrefix(cidr string) (netip.Prefix, bool) {
+	if strings.Contains(cidr, "/") {
+		prefix, err := netip.ParsePrefix(cidr)
+		return prefix, err == nil
+	}
+	addr, err := netip.ParseAddr(cidr)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
+// namedPortMemberMatches returns whether a member like "10.0.0.1,tcp:80" or "10.0.0.1,80" matches the IP, protocol, and port.
+func namedPortMemberMatches(member string, addr netip.Addr, protocol string, port int32) bool {
+	ipAndPort := strings.SplitN(member, ",", 2)
+	if len(ipAndPort) != 2 {
+		return false
+	}
+	memberAddr, err := netip.ParseAddr(ipAndPort[0])
+	if err != nil || memberAddr != addr {
+		return false
+	}
+
+	memberProtocol := defaultNamedPortProtocol
+	memberPort := ipAndPort[1]
+	if protocolAndPort := strings.SplitN(memberPort, ":", 2); len(protocolAndPort) == 2 {
+		memberProtocol = protocolAndPort[0]
+		memberPort = protocolAndPort[1]
+	}
+	return strings.EqualFold(memberProtocol, protocol) && memberPort == strconv.Itoa(int(port))
+}
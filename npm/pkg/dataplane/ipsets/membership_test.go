@@ -0,0 +1,71 @@
+package ipsets
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainsIP(t *testing.T) {
+	iMgr := NewIPSetManager(applyAlwaysCfg, common.NewMockIOShim(nil))
+	cidrSet := NewIPSetMetadata("test-cidr-set", CIDRBlocks)
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{namespaceSet, keyLabelOfPodSet}, "10.0.0.1", "x/a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{namespaceSet}, "10.0.0.2", "x/b"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{portSet}, "10.0.0.1,TCP:8080", "x/a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{cidrSet}, "10.1.0.0/16", ""))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{cidrSet}, "10.1.1.0/24 nomatch", ""))
+	require.NoError(t, iMgr.AddToLists([]*IPSetMetadata{list}, []*IPSetMetadata{namespaceSet}))
+
+	tests := []struct {
+		name     string
+		setName  string
+		ip       string
+		protocol string
+		port     int32
+		expected bool
+	}{
+		{name: "hash set member", setName: namespaceSet.GetPrefixName(), ip: "10.0.0.2", expected: true},
+		{name: "not a hash set member", setName: keyLabelOfPodSet.GetPrefixName(), ip: "10.0.0.2", expected: false},
+		{name: "list member", setName: list.GetPrefixName(), ip: "10.0.0.1", expected: true},
+		{name: "not a list member", setName: list.GetPrefixName(), ip: "10.0.0.3", expected: false},
+		{name: "named port", setName: portSet.GetPrefixName(), ip: "10.0.0.1", protocol: "tcp", port: 8080, expected: true},
+		{name: "named port with another port", setName: portSet.GetPrefixName(), ip: "10.0.0.1", protocol: "TCP", port: 80, expected: false},
+		{name: "named port with another protocol", setName: portSet.GetPrefixName(), ip: "10.0.0.1", protocol: "UDP", port: 8080, expected: false},
+		{name: "in CIDR", setName: cidrSet.GetPrefixName(), ip: "10.1.2.3", expected: true},
+		{name: "in nomatch CIDR", setName: cidrSet.GetPrefixName(), ip: "10.1.1.3", expected: false},
+		{name: "outside CIDR", setName: cidrSet.GetPrefixName(), ip: "10.2.0.1", expected: false},
+		{name: "unknown set", setName: "ns-unknown", ip: "10.0.0.1", expected: false},
+		{name: "invalid IP", setName: namespaceSet.GetPrefixName(), ip: "x/a", expected: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, iMgr.ContainsIP(tt.setName, tt.ip, tt.protocol, tt.port))
+		})
+	}
+}
+
+func TestCIDRMembersContain(t *testing.T) {
+	members := map[string]string{
+		"2001:db8::/32":           "",
+		"2001:db8:1::/48 nomatch": "",
+		"2001:db8:1::1":           "",
+	}
+	require.True(t, cidrMembersContain(members, netip.MustParseAddr("2001:db8:2::1")))
+	require.False(t, cidrMembersContain(members, netip.MustParseAddr("2001:db8:1::2")))
+	// a more specific prefix overrides nomatch
+	require.True(t, cidrMembersContain(members, netip.MustParseAddr("2001:db8:1::1")))
+	require.False(t, cidrMembersContain(members, netip.MustParseAddr("10.0.0.1")))
+}
+
+func TestNamespacePodIPs(t *testing.T) {
+	iMgr := NewIPSetManager(applyAlwaysCfg, common.NewMockIOShim(nil))
+	x := NewIPSetMetadata("x", Namespace)
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{x}, "10.0.0.1", "x/a"))
+	require.NoError(t, iMgr.AddToSets([]*IPSetMetadata{x}, "10.0.0.2", "x/b"))
+
+	require.Equal(t, map[string]string{"10.0.0.1": "x/a", "10.0.0.2": "x/b"}, iMgr.NamespacePodIPs("x"))
+	require.Empty(t, iMgr.NamespacePodIPs("y"))
+}
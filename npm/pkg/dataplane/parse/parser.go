@@ -65,10 +65,24 @@ func (i *IPTablesParser) Iptables(tableName string) (*NPMIPtable.Table, error) {
 
 // Iptables creates a Go object from specified iptable by calling iptables-save within node.
 func Iptables(tableName string) (*NPMIPtable.Table, error) {
+	return iptablesWithSaveCommand(util.IptablesSave, tableName)
+}
+
+// Ip6tables creates a Go object from specified iptable by calling ip6tables-save within node.
+// ip6tables-save uses the same backend (nft or legacy) as iptables-save.
+func Ip6tables(tableName string) (*NPMIPtable.Table, error) { //nolint (avoid warning to capitalize this p)
+	saveCommand := util.Ip6tablesSaveLegacy
+	if util.IptablesSave == util.IptablesSaveNft {
+		saveCommand = util.Ip6tablesSaveNft
+	}
+	return iptablesWithSaveCommand(saveCommand, tableName)
+}
+
+func iptablesWithSaveCommand(saveCommand, tableName string) (*NPMIPtable.Table, error) {
 	iptableBuffer := bytes.NewBuffer(nil)
 	// TODO: need to get iptable's lock
 	cmdArgs := []string{util.IptablesTableFlag, string(tableName)}
-	cmd := exec.Command(util.IptablesSave, cmdArgs...) //nolint:gosec // client usage is filter table only
+	cmd := exec.Command(saveCommand, cmdArgs...) //nolint:gosec // client usage is filter table only
 
 	cmd.Stdout = iptableBuffer
 	stderrBuffer := bytes.NewBuffer(nil)
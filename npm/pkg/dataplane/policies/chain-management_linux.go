@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/util"
 	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
 	"github.com/Azure/azure-container-networking/npm/util/ioutil"
@@ -34,7 +36,14 @@ var (
 		util.IptablesAzureEgressChain,
 		util.IptablesAzureAcceptChain,
 	}
-	// Should not be used directly. Initialized from iptablesAzureChains on first use of isAzureChain().
+	// Only created if AdminNetworkPolicy is enabled.
+	iptablesAdminTierChains = []string{
+		util.IptablesAzureAdminIngressChain,
+		util.IptablesAzureAdminEgressChain,
+		util.IptablesAzureBaselineIngressChain,
+		util.IptablesAzureBaselineEgressChain,
+	}
+	// Should not be used directly. Initialized from iptablesAzureChains and iptablesAdminTierChains on first use of isAzureChain().
 	iptablesAzureChainsMap map[string]struct{}
 
 	jumpToAzureChainArgs = []string{
@@ -153,11 +162,24 @@ func isBaseChain(chain string) bool {
 		for _, chain := range iptablesAzureChains {
 			iptablesAzureChainsMap[chain] = struct{}{}
 		}
+		for _, chain := range iptablesAdminTierChains {
+			iptablesAzureChainsMap[chain] = struct{}{}
+		}
 	}
 	_, exist := iptablesAzureChainsMap[chain]
 	return exist
 }
 
+// baseChains returns the chains created at bootup.
+func (pMgr *PolicyManager) baseChains() []string {
+	if !pMgr.EnableAdminNetworkPolicy {
+		return iptablesAzureChains
+	}
+	chains := make([]string, 0, len(iptablesAzureChains)+len(iptablesAdminTierChains))
+	chains = append(chains, iptablesAzureChains...)
+	return append(chains, iptablesAdminTierChains...)
+}
+
 /*
 Called once at startup.
 Like the rest of PolicyManager, minimizes the number of OS calls by consolidating all possible actions into one iptables-restore call.
@@ -174,11 +196,16 @@ Like the rest of PolicyManager, minimizes the number of OS calls by consolidatin
     - delete old v2 policy chains
 
 3. Add/reposition the jump from FORWARD chain to AZURE-NPM chain.
+4. If IPv6 is enabled, repeat steps 2 and 3 for ip6tables.
 
 TODO: could use one grep call instead of separate calls for getting jump line nums and for getting deprecated chains and old v2 policy chains
   - would use a grep pattern like so: <line num...AZURE-NPM>|<Chain AZURE-NPM>
 */
 func (pMgr *PolicyManager) bootup(_ []string) error {
+	if pMgr.EnableNFTables {
+		return pMgr.bootupNFTables()
+	}
+
 	klog.Infof("booting up iptables Azure chains")
 
 	// Stop reconciling so we don't contend for iptables, and so we don't update the staleChains at the same time as reconcile()
@@ -279,15 +306,37 @@ func (pMgr *PolicyManager) bootup(_ []string) error {
 
 	klog.Infof("found %d current chains in the default iptables", len(currentChains))
 
+	pMgr.staleChains.empty()
+	if err := pMgr.bootupFamily(currentChains, ipsets.IPv4); err != nil {
+		return err
+	}
+
+	if !pMgr.EnableIPv6 {
+		return nil
+	}
+
+	// 4. repeat for ip6tables. NPM v1 never used ip6tables, so there are no deprecated jumps to delete.
+	currentIPv6Chains, err := ioutil.AllCurrentAzureChainsWithCommand(pMgr.ioShim.Exec, iptablesCommand(ipsets.IPv6), util.IptablesDefaultWaitTime)
+	if err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to get current ip6tables chains for bootup", err)
+	}
+
+	klog.Infof("found %d current chains in ip6tables", len(currentIPv6Chains))
+
+	return pMgr.bootupFamily(currentIPv6Chains, ipsets.IPv6)
+}
+
+// bootupFamily does steps 2 and 3 of bootup() for the iptables of the given family.
+func (pMgr *PolicyManager) bootupFamily(currentChains map[string]struct{}, family ipsets.IPFamily) error {
 	// 2. cleanup old NPM chains, and configure base chains and their rules.
 	creator := pMgr.creatorForBootup(currentChains)
-	if err := restore(creator); err != nil {
-		return npmerrors.SimpleErrorWrapper("failed to run iptables-restore for bootup", err)
+	if err := restore(creator, family); err != nil {
+		return npmerrors.SimpleErrorWrapper(fmt.Sprintf("failed to run %s for bootup", iptablesRestoreCommand(family)), err)
 	}
 
 	// 3. add/reposition the jump to AZURE-NPM
-	if err := pMgr.positionAzureChainJumpRule(); err != nil {
-		baseErrString := "failed to add/reposition jump from FORWARD chain to AZURE-NPM chain"
+	if err := pMgr.positionAzureChainJumpRule(family); err != nil {
+		baseErrString := fmt.Sprintf("failed to add/reposition jump from FORWARD chain to AZURE-NPM chain in %s", iptablesCommand(family))
 		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s with error: %s", baseErrString, err.Error())
 		return npmerrors.SimpleErrorWrapper(baseErrString, err) // we used to ignore this error in v1
 	}
@@ -296,12 +345,18 @@ func (pMgr *PolicyManager) bootup(_ []string) error {
 
 // reconcile does the following:
 // - creates the jump rule from FORWARD chain to AZURE-NPM chain (if it does not exist) and makes sure it's after the jumps to KUBE-FORWARD & KUBE-SERVICES chains (if they exist).
+// This is skipped for nftables, where the forward chain has a fixed priority.
 // - cleans up stale policy chains. It can be forced to stop this process if reconcileManager.forceLock() is called.
+// For nftables, these are the iptables chains from a previous run (see bootupNFTables()).
 func (pMgr *PolicyManager) reconcile() {
-	if err := pMgr.positionAzureChainJumpRule(); err != nil {
-		msg := fmt.Sprintf("failed to reconcile jump rule to Azure-NPM due to %s", err.Error())
-		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s", msg)
-		klog.Error(msg)
+	if !pMgr.EnableNFTables {
+		for _, family := range pMgr.families() {
+			if err := pMgr.positionAzureChainJumpRule(family); err != nil {
+				msg := fmt.Sprintf("failed to reconcile jump rule to Azure-NPM in %s due to %s", iptablesCommand(family), err.Error())
+				metrics.SendErrorLogAndMetric(util.IptmID, "error: %s", msg)
+				klog.Error(msg)
+			}
+		}
 	}
 
 	pMgr.reconcileManager.Lock()
@@ -320,7 +375,7 @@ func (pMgr *PolicyManager) reconcile() {
 	}
 }
 
-// cleanupChains deletes all the chains in the given list.
+// cleanupChains deletes all the chains in the given list (in the iptables of each family).
 // If a chain fails to delete and it isn't one of the iptablesAzureChains, then it is added to the staleChains.
 // This is a separate function for with a slice argument so that UTs can have deterministic behavior for ioshim.
 func (pMgr *PolicyManager) cleanupChains(chains []string) error {
@@ -335,15 +390,17 @@ deleteLoop:
 			}
 			break deleteLoop
 		default:
-			errCode, err := pMgr.runIPTablesCommand(util.IptablesDestroyFlag, chain)
-			if err != nil && errCode != doesNotExistErrorCode {
-				// add to staleChains if it's not one of the iptablesAzureChains
-				pMgr.staleChains.add(chain)
-				currentErrString := fmt.Sprintf("failed to clean up chain %s with err [%v]", chain, err)
-				if aggregateError == nil {
-					aggregateError = npmerrors.SimpleError(currentErrString)
-				} else {
-					aggregateError = npmerrors.SimpleErrorWrapper(fmt.Sprintf("%s and had previous error", currentErrString), aggregateError)
+			for _, family := range pMgr.families() {
+				errCode, err := pMgr.runIPTablesCommandForFamily(family, util.IptablesDestroyFlag, chain)
+				if err != nil && errCode != doesNotExistErrorCode {
+					// add to staleChains if it's not one of the iptablesAzureChains
+					pMgr.staleChains.add(chain)
+					currentErrString := fmt.Sprintf("failed to clean up chain %s in %s with err [%v]", chain, iptablesCommand(family), err)
+					if aggregateError == nil {
+						aggregateError = npmerrors.SimpleError(currentErrString)
+					} else {
+						aggregateError = npmerrors.SimpleErrorWrapper(fmt.Sprintf("%s and had previous error", currentErrString), aggregateError)
+					}
 				}
 			}
 		}
@@ -356,16 +413,25 @@ deleteLoop:
 
 // this function has a direct comparison in NPM v1 iptables manager (iptm.go)
 func (pMgr *PolicyManager) runIPTablesCommand(operationFlag string, args ...string) (int, error) {
-	return pMgr.ignoreErrorsAndRunIPTablesCommand(nil, operationFlag, args...)
+	return pMgr.runIPTablesCommandForFamily(ipsets.IPv4, operationFlag, args...)
+}
+
+func (pMgr *PolicyManager) runIPTablesCommandForFamily(family ipsets.IPFamily, operationFlag string, args ...string) (int, error) {
+	return pMgr.ignoreErrorsAndRunIPTablesCommandForFamily(family, nil, operationFlag, args...)
 }
 
 func (pMgr *PolicyManager) ignoreErrorsAndRunIPTablesCommand(ignored []*exitErrorInfo, operationFlag string, args ...string) (int, error) {
+	return pMgr.ignoreErrorsAndRunIPTablesCommandForFamily(ipsets.IPv4, ignored, operationFlag, args...)
+}
+
+func (pMgr *PolicyManager) ignoreErrorsAndRunIPTablesCommandForFamily(family ipsets.IPFamily, ignored []*exitErrorInfo, operationFlag string, args ...string) (int, error) {
 	allArgs := []string{util.IptablesWaitFlag, util.IptablesDefaultWaitTime, operationFlag}
 	allArgs = append(allArgs, args...)
 
-	klog.Infof("Executing iptables command with args %v", allArgs)
+	iptables := iptablesCommand(family)
+	klog.Infof("Executing %s command with args %v", iptables, allArgs)
 
-	command := pMgr.ioShim.Exec.Command(util.Iptables, allArgs...)
+	command := pMgr.ioShim.Exec.Command(iptables, allArgs...)
 	output, err := command.CombinedOutput()
 
 	var exitError utilexec.ExitError
@@ -375,14 +441,14 @@ func (pMgr *PolicyManager) ignoreErrorsAndRunIPTablesCommand(ignored []*exitErro
 		outputString := strings.TrimSuffix(string(output), "\n")
 		for _, info := range ignored {
 			if errCode == info.exitCode && strings.Contains(outputString, info.stdErr) {
-				klog.Infof("%s. not able to run iptables command [%s %s]. exit code: %d, output: %s", info.messageToLog, util.Iptables, allArgsString, errCode, outputString)
+				klog.Infof("%s. not able to run iptables command [%s %s]. exit code: %d, output: %s", info.messageToLog, iptables, allArgsString, errCode, outputString)
 				return errCode, nil
 			}
 		}
 		if errCode > 0 {
-			metrics.SendErrorLogAndMetric(util.IptmID, "error: There was an error running command: [%s %s] Stderr: [%v, %s]", util.Iptables, allArgsString, exitError, outputString)
+			metrics.SendErrorLogAndMetric(util.IptmID, "error: There was an error running command: [%s %s] Stderr: [%v, %s]", iptables, allArgsString, exitError, outputString)
 		}
-		return errCode, fmt.Errorf("failed to run iptables command [%s %s] Stderr: [%s]. err: [%w]", util.Iptables, allArgsString, outputString, exitError)
+		return errCode, fmt.Errorf("failed to run iptables command [%s %s] Stderr: [%s]. err: [%w]", iptables, allArgsString, outputString, exitError)
 	}
 	return 0, nil
 }
@@ -390,8 +456,9 @@ func (pMgr *PolicyManager) ignoreErrorsAndRunIPTablesCommand(ignored []*exitErro
 // Writes the restore file for bootup, and marks the following as stale: deprecated chains and old v2 policy chains.
 // This is a separate function to help with UTs.
 func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *ioutil.FileCreator {
-	chainsToCreate := make([]string, 0, len(iptablesAzureChains))
-	for _, chain := range iptablesAzureChains {
+	baseChains := pMgr.baseChains()
+	chainsToCreate := make([]string, 0, len(baseChains))
+	for _, chain := range baseChains {
 		_, exists := currentChains[chain]
 		if !exists {
 			chainsToCreate = append(chainsToCreate, chain)
@@ -401,18 +468,29 @@ func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *
 	// Step 2.1 in bootup() comment: cleanup old NPM chains, and configure base chains and their rules
 	// To leave NPM deactivated, don't specify any rules for AZURE-NPM chain.
 	creator := pMgr.newCreatorWithChains(chainsToCreate)
-	pMgr.staleChains.empty()
 	for chain := range currentChains {
 		creator.AddLine("", nil, fmt.Sprintf("-F %s", chain))
 		// Step 2.2 in bootup() comment: delete deprecated chains and old v2 policy chains in the background
 		pMgr.staleChains.add(chain) // won't add base chains
 	}
 
-	// add AZURE-NPM-INGRESS chain rules
+	// add AZURE-NPM-INGRESS chain rules.
+	// AdminNetworkPolicies are evaluated before NetworkPolicies, and BaselineAdminNetworkPolicies are evaluated after NetworkPolicies.
+	// The jumps to NetworkPolicy chains are inserted after the jump to AZURE-NPM-ADMIN-INGRESS (see firstPolicyJumpLineNumber()).
+	if pMgr.EnableAdminNetworkPolicy {
+		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureIngressChain, util.IptablesJumpFlag, util.IptablesAzureAdminIngressChain)
+	}
+	if pMgr.EnableDropLogging {
+		// NetworkPolicies only mark packets for drop, so they're logged where they're dropped, with the ACL ID in the mark
+		creator.AddLine("", nil, nflogRuleSpecs(util.IptablesAzureIngressChain, droplog.MarkTag(droplog.Ingress), onMarkSpecs(util.IptablesAzureIngressDropMarkHex))...)
+	}
 	ingressDropSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureIngressChain, util.IptablesJumpFlag, util.IptablesDrop}
 	ingressDropSpecs = append(ingressDropSpecs, onMarkSpecs(util.IptablesAzureIngressDropMarkHex)...)
 	ingressDropSpecs = append(ingressDropSpecs, commentSpecs(fmt.Sprintf("DROP-ON-INGRESS-DROP-MARK-%s", util.IptablesAzureIngressDropMarkHex))...)
 	creator.AddLine("", nil, ingressDropSpecs...)
+	if pMgr.EnableAdminNetworkPolicy {
+		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureIngressChain, util.IptablesJumpFlag, util.IptablesAzureBaselineIngressChain)
+	}
 
 	// add AZURE-NPM-INGRESS-ALLOW-MARK chain
 	markIngressAllowSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureIngressAllowMarkChain}
@@ -422,10 +500,19 @@ func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *
 	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureIngressAllowMarkChain, util.IptablesJumpFlag, util.IptablesAzureEgressChain)
 
 	// add AZURE-NPM-EGRESS chain rules
+	if pMgr.EnableAdminNetworkPolicy {
+		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureEgressChain, util.IptablesJumpFlag, util.IptablesAzureAdminEgressChain)
+	}
+	if pMgr.EnableDropLogging {
+		creator.AddLine("", nil, nflogRuleSpecs(util.IptablesAzureEgressChain, droplog.MarkTag(droplog.Egress), onMarkSpecs(util.IptablesAzureEgressDropMarkHex))...)
+	}
 	egressDropSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureEgressChain, util.IptablesJumpFlag, util.IptablesDrop}
 	egressDropSpecs = append(egressDropSpecs, onMarkSpecs(util.IptablesAzureEgressDropMarkHex)...)
 	egressDropSpecs = append(egressDropSpecs, commentSpecs(fmt.Sprintf("DROP-ON-EGRESS-DROP-MARK-%s", util.IptablesAzureEgressDropMarkHex))...)
 	creator.AddLine("", nil, egressDropSpecs...)
+	if pMgr.EnableAdminNetworkPolicy {
+		creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureEgressChain, util.IptablesJumpFlag, util.IptablesAzureBaselineEgressChain)
+	}
 
 	jumpOnIngressMatchSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureEgressChain, util.IptablesJumpFlag, util.IptablesAzureAcceptChain}
 	jumpOnIngressMatchSpecs = append(jumpOnIngressMatchSpecs, onMarkSpecs(util.IptablesAzureIngressAllowMarkHex)...)
@@ -433,6 +520,13 @@ func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *
 	creator.AddLine("", nil, jumpOnIngressMatchSpecs...)
 
 	// add AZURE-NPM-ACCEPT chain rules
+	if pMgr.EnableDropLogging {
+		// a packet accepted after a NetworkPolicy marked it for drop mustn't keep the drop mark and ACL ID
+		clearMarkSpecs := []string{util.IptablesAppendFlag, util.IptablesAzureAcceptChain}
+		clearMarkSpecs = append(clearMarkSpecs, setMarkSpecs(util.IptablesAzureClearMarkHexV2)...)
+		clearMarkSpecs = append(clearMarkSpecs, commentSpecs("CLEAR-AZURE-NPM-MARKS")...)
+		creator.AddLine("", nil, clearMarkSpecs...)
+	}
 	creator.AddLine("", nil, util.IptablesAppendFlag, util.IptablesAzureAcceptChain, util.IptablesJumpFlag, util.IptablesAccept)
 	creator.AddLine("", nil, util.IptablesRestoreCommit)
 	return creator
@@ -441,9 +535,9 @@ func (pMgr *PolicyManager) creatorForBootup(currentChains map[string]struct{}) *
 // add/reposition the jump from FORWARD chain to AZURE-NPM chain to be in the correct position based on config:
 // option 1) jump to AZURE-NPM chain should be the first rule
 // option 2) jump to AZURE-NPM chain should be after the jump to KUBE-SERVICES chain
-func (pMgr *PolicyManager) positionAzureChainJumpRule() error {
+func (pMgr *PolicyManager) positionAzureChainJumpRule(family ipsets.IPFamily) error {
 	// get the line number for the azure jump
-	azureChainLineNum, err := pMgr.chainLineNumber(util.IptablesAzureChain)
+	azureChainLineNum, err := pMgr.chainLineNumber(util.IptablesAzureChain, family)
 	if err != nil {
 		baseErrString := "failed to get index of jump from FORWARD chain to AZURE-NPM chain"
 		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s: %s", baseErrString, err.Error())
@@ -458,7 +552,7 @@ func (pMgr *PolicyManager) positionAzureChainJumpRule() error {
 	// place the azure jump in the first position, unless we want option 2 above and the kube jump exists
 	targetIndex := 1
 	if pMgr.PlaceAzureChainFirst == util.PlaceAzureChainAfterKubeServices {
-		kubeChainLineNum, err := pMgr.chainLineNumber(util.IptablesKubeServicesChain)
+		kubeChainLineNum, err := pMgr.chainLineNumber(util.IptablesKubeServicesChain, family)
 		if err != nil {
 			baseErrString := "failed to get index of jump from FORWARD chain to KUBE-SERVICES chain"
 			metrics.SendErrorLogAndMetric(util.IptmID, "error: %s: %s", baseErrString, err.Error())
@@ -480,7 +574,7 @@ func (pMgr *PolicyManager) positionAzureChainJumpRule() error {
 	// delete the azure jump if it exists and update the target index
 	if azureChainLineNum != 0 {
 		metrics.SendErrorLogAndMetric(util.IptmID, "Info: Reconciler deleting and re-adding jump from FORWARD chain to AZURE-NPM chain table.")
-		if deleteErrCode, deleteErr := pMgr.runIPTablesCommand(util.IptablesDeletionFlag, jumpFromForwardToAzureChainArgs...); deleteErr != nil {
+		if deleteErrCode, deleteErr := pMgr.runIPTablesCommandForFamily(family, util.IptablesDeletionFlag, jumpFromForwardToAzureChainArgs...); deleteErr != nil {
 			baseErrString := "failed to delete jump from FORWARD chain to AZURE-NPM chain"
 			metrics.SendErrorLogAndMetric(util.IptmID, "error: %s with error code %d and error %s", baseErrString, deleteErrCode, deleteErr.Error())
 			return npmerrors.SimpleErrorWrapper(baseErrString, deleteErr)
@@ -504,7 +598,7 @@ func (pMgr *PolicyManager) positionAzureChainJumpRule() error {
 		args = []string{util.IptablesForwardChain, strconv.Itoa(targetIndex)}
 		args = append(args, jumpToAzureChainArgs...)
 	}
-	if insertErrCode, err := pMgr.runIPTablesCommand(util.IptablesInsertionFlag, args...); err != nil {
+	if insertErrCode, err := pMgr.runIPTablesCommandForFamily(family, util.IptablesInsertionFlag, args...); err != nil {
 		baseErrString := "failed to insert jump from FORWARD chain to AZURE-NPM chain"
 		metrics.SendErrorLogAndMetric(util.IptmID, "error: %s with error code %d and error %s", baseErrString, insertErrCode, err.Error())
 		return npmerrors.SimpleErrorWrapper(baseErrString, err)
@@ -514,8 +608,8 @@ func (pMgr *PolicyManager) positionAzureChainJumpRule() error {
 
 // returns 0 if the chain does not exist
 // this function has a direct comparison in NPM v1 iptables manager (iptm.go)
-func (pMgr *PolicyManager) chainLineNumber(chain string) (int, error) {
-	listForwardEntriesCommand := pMgr.ioShim.Exec.Command(util.Iptables, listForwardEntriesArgs...)
+func (pMgr *PolicyManager) chainLineNumber(chain string, family ipsets.IPFamily) (int, error) {
+	listForwardEntriesCommand := pMgr.ioShim.Exec.Command(iptablesCommand(family), listForwardEntriesArgs...)
 	grepCommand := pMgr.ioShim.Exec.Command(ioutil.Grep, chain)
 	searchResults, gotMatches, err := ioutil.PipeCommandToGrep(listForwardEntriesCommand, grepCommand)
 	if err != nil {
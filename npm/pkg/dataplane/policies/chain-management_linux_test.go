@@ -8,6 +8,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	dptestutils "github.com/Azure/azure-container-networking/npm/pkg/dataplane/testutils"
 	"github.com/Azure/azure-container-networking/npm/util"
 	testutils "github.com/Azure/azure-container-networking/test/utils"
@@ -187,6 +188,41 @@ func TestCleanupChainsFailure(t *testing.T) {
 	assertStaleChainsContain(t, pMgr.staleChains, testChain1, testChain3)
 }
 
+func TestCleanupChainsWithIPv6(t *testing.T) {
+	calls := []testutils.TestCmd{
+		getFakeDestroyCommand(testChain1),
+		getFakeIPv6DestroyCommand(testChain1),
+		getFakeDestroyCommandWithExitCode(testChain2, 1), // exit code 1 means the chain does not exist
+		getFakeIPv6DestroyCommandWithExitCode(testChain2, 2),
+	}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, ipv6Config)
+
+	require.Error(t, pMgr.cleanupChains([]string{testChain1, testChain2}))
+	assertStaleChainsContain(t, pMgr.staleChains, testChain2)
+}
+
+func TestBootupWithIPv6(t *testing.T) {
+	metrics.ReinitializeAll()
+	calls := GetBootupTestCalls(false)
+	calls = append(calls,
+		testutils.TestCmd{Cmd: listAllIPv6CommandStrings, PipedToCommand: true},
+		testutils.TestCmd{Cmd: []string{"grep", "Chain AZURE-NPM"}, Stdout: "Chain AZURE-NPM-INGRESS-123456 (1 references)\n"},
+		fakeIP6TablesRestoreCommand,
+		testutils.TestCmd{Cmd: listLineNumbersIPv6CommandStrings, PipedToCommand: true},
+		testutils.TestCmd{Cmd: []string{"grep", "AZURE-NPM"}, ExitCode: 1},
+		testutils.TestCmd{Cmd: []string{"ip6tables", "-w", "60", "-I", "FORWARD", "-j", "AZURE-NPM", "-m", "conntrack", "--ctstate", "NEW"}},
+	)
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, ipv6Config)
+
+	require.NoError(t, pMgr.Bootup(nil))
+	// old policy chains in ip6tables are cleaned up in the background too
+	assertStaleChainsContain(t, pMgr.staleChains, "AZURE-NPM-INGRESS-123456")
+}
+
 func TestCreatorForBootup(t *testing.T) {
 	v1Chains := []string{
 		"AZURE-NPM-INGRESS-DROPS",
@@ -330,6 +366,78 @@ func TestCreatorForBootup(t *testing.T) {
 	}
 }
 
+func TestCreatorForBootupWithAdminNetworkPolicy(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, adminTierConfig)
+	creator := pMgr.creatorForBootup(stringsToMap([]string{}))
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		":AZURE-NPM - -",
+		":AZURE-NPM-INGRESS - -",
+		":AZURE-NPM-INGRESS-ALLOW-MARK - -",
+		":AZURE-NPM-EGRESS - -",
+		":AZURE-NPM-ACCEPT - -",
+		":AZURE-NPM-ADMIN-INGRESS - -",
+		":AZURE-NPM-ADMIN-EGRESS - -",
+		":AZURE-NPM-BASELINE-INGRESS - -",
+		":AZURE-NPM-BASELINE-EGRESS - -",
+		// AdminNetworkPolicies before NetworkPolicies, and BaselineAdminNetworkPolicies after
+		"-A AZURE-NPM-INGRESS -j AZURE-NPM-ADMIN-INGRESS",
+		"-A AZURE-NPM-INGRESS -j DROP -m mark --mark 0x400/0x400 -m comment --comment DROP-ON-INGRESS-DROP-MARK-0x400/0x400",
+		"-A AZURE-NPM-INGRESS -j AZURE-NPM-BASELINE-INGRESS",
+		"-A AZURE-NPM-INGRESS-ALLOW-MARK -j MARK --set-mark 0x200/0x200 -m comment --comment SET-INGRESS-ALLOW-MARK-0x200/0x200",
+		"-A AZURE-NPM-INGRESS-ALLOW-MARK -j AZURE-NPM-EGRESS",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-ADMIN-EGRESS",
+		"-A AZURE-NPM-EGRESS -j DROP -m mark --mark 0x800/0x800 -m comment --comment DROP-ON-EGRESS-DROP-MARK-0x800/0x800",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-BASELINE-EGRESS",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-ACCEPT -m mark --mark 0x200/0x200 -m comment --comment ACCEPT-ON-INGRESS-ALLOW-MARK-0x200/0x200",
+		"-A AZURE-NPM-ACCEPT -j ACCEPT",
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestCreatorForBootupWithDropLogging(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, dropLoggingConfig)
+	creator := pMgr.creatorForBootup(stringsToMap([]string{}))
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		":AZURE-NPM - -",
+		":AZURE-NPM-INGRESS - -",
+		":AZURE-NPM-INGRESS-ALLOW-MARK - -",
+		":AZURE-NPM-EGRESS - -",
+		":AZURE-NPM-ACCEPT - -",
+		":AZURE-NPM-ADMIN-INGRESS - -",
+		":AZURE-NPM-ADMIN-EGRESS - -",
+		":AZURE-NPM-BASELINE-INGRESS - -",
+		":AZURE-NPM-BASELINE-EGRESS - -",
+		"-A AZURE-NPM-INGRESS -j AZURE-NPM-ADMIN-INGRESS",
+		// packets marked for drop by NetworkPolicies are logged where they're dropped
+		"-A AZURE-NPM-INGRESS -j NFLOG --nflog-group 100 --nflog-prefix NPM-DROP-MARK:I -m mark --mark 0x400/0x400 -m limit --limit 10/second --limit-burst 20",
+		"-A AZURE-NPM-INGRESS -j DROP -m mark --mark 0x400/0x400 -m comment --comment DROP-ON-INGRESS-DROP-MARK-0x400/0x400",
+		"-A AZURE-NPM-INGRESS -j AZURE-NPM-BASELINE-INGRESS",
+		"-A AZURE-NPM-INGRESS-ALLOW-MARK -j MARK --set-mark 0x200/0x200 -m comment --comment SET-INGRESS-ALLOW-MARK-0x200/0x200",
+		"-A AZURE-NPM-INGRESS-ALLOW-MARK -j AZURE-NPM-EGRESS",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-ADMIN-EGRESS",
+		"-A AZURE-NPM-EGRESS -j NFLOG --nflog-group 100 --nflog-prefix NPM-DROP-MARK:E -m mark --mark 0x800/0x800 -m limit --limit 10/second --limit-burst 20",
+		"-A AZURE-NPM-EGRESS -j DROP -m mark --mark 0x800/0x800 -m comment --comment DROP-ON-EGRESS-DROP-MARK-0x800/0x800",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-BASELINE-EGRESS",
+		"-A AZURE-NPM-EGRESS -j AZURE-NPM-ACCEPT -m mark --mark 0x200/0x200 -m comment --comment ACCEPT-ON-INGRESS-ALLOW-MARK-0x200/0x200",
+		// packets accepted after a NetworkPolicy marked them for drop don't keep the drop mark and ACL ID
+		"-A AZURE-NPM-ACCEPT -j MARK --set-mark 0x0/0xffff0e00 -m comment --comment CLEAR-AZURE-NPM-MARKS",
+		"-A AZURE-NPM-ACCEPT -j ACCEPT",
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
 func sortFlushes(lines []string) []string {
 	result := make([]string, len(lines))
 	copy(result, lines)
@@ -772,7 +880,7 @@ func TestPositionAzureChainJumpRule(t *testing.T) {
 				PlaceAzureChainFirst: tt.placeAzureChainFirst,
 			}
 			pMgr := NewPolicyManager(ioshim, cfg)
-			err := pMgr.positionAzureChainJumpRule()
+			err := pMgr.positionAzureChainJumpRule(ipsets.IPv4)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -863,7 +971,7 @@ func TestChainLineNumber(t *testing.T) {
 			ioshim := common.NewMockIOShim(tt.calls)
 			defer ioshim.VerifyCalls(t, tt.calls)
 			pMgr := NewPolicyManager(ioshim, ipsetConfig)
-			lineNum, err := pMgr.chainLineNumber(testChainName)
+			lineNum, err := pMgr.chainLineNumber(testChainName, ipsets.IPv4)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -884,6 +992,16 @@ func getFakeDestroyCommandWithExitCode(chain string, exitCode int) testutils.Tes
 	return command
 }
 
+func getFakeIPv6DestroyCommand(chain string) testutils.TestCmd {
+	return testutils.TestCmd{Cmd: []string{"ip6tables", "-w", "60", "-X", chain}}
+}
+
+func getFakeIPv6DestroyCommandWithExitCode(chain string, exitCode int) testutils.TestCmd {
+	command := getFakeIPv6DestroyCommand(chain)
+	command.ExitCode = exitCode
+	return command
+}
+
 func stringsToMap(items []string) map[string]struct{} {
 	if items == nil {
 		return nil
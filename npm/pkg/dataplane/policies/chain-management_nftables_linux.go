@@ -0,0 +1,135 @@
+package policies
+
+// This file contains code for booting up nftables
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/util"
+	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+	"k8s.io/klog"
+)
+
+const (
+	// the forward chain is evaluated before the iptables filter table (priority 0) if PlaceAzureChainFirst,
+	// and after it otherwise, which is the closest equivalent to placing the jump after KUBE-SERVICES.
+	nftForwardPriorityFirst = "-1"
+	nftForwardPriorityAfter = "1"
+)
+
+/*
+Called once at startup in place of bootup() for the nftables dataplane.
+
+1. Deactivate the iptables dataplane from a previous run: delete the jump from FORWARD chain to AZURE-NPM chain,
+flush all NPM chains, and delete the non-base chains in the background (see reconcile()).
+The base chains are left empty.
+2. In one nft transaction, recreate the azure-npm table (deleting all old sets, maps, and chains),
+and configure the base chains and their rules, leaving NPM deactivated.
+*/
+func (pMgr *PolicyManager) bootupNFTables() error {
+	klog.Infof("booting up nftables Azure chains")
+
+	// Stop reconciling so we don't update the staleChains at the same time as reconcile()
+	pMgr.reconcileManager.forceLock()
+	defer pMgr.reconcileManager.forceUnlock()
+
+	pMgr.staleChains.empty()
+	for _, family := range pMgr.families() {
+		if err := pMgr.cleanupIPTablesForNFTables(family); err != nil {
+			return err
+		}
+	}
+
+	creator := pMgr.creatorForNFTablesBootup()
+	if err := runNFT(creator); err != nil {
+		return npmerrors.SimpleErrorWrapper("failed to run nft for bootup", err)
+	}
+	return nil
+}
+
+// cleanupIPTablesForNFTables does step 1 of bootupNFTables() for the iptables of the given family.
+func (pMgr *PolicyManager) cleanupIPTablesForNFTables(family ipsets.IPFamily) error {
+	errCode, err := pMgr.ignoreErrorsAndRunIPTablesCommandForFamily(family, removeDeprecatedJumpIgnoredErrors, util.IptablesDeletionFlag, jumpFromForwardToAzureChainArgs...)
+	if errCode == 0 {
+		klog.Infof("deleted jump rule from FORWARD chain to AZURE-NPM chain in %s", iptablesCommand(family))
+	} else if err != nil {
+		metrics.SendErrorLogAndMetric(util.IptmID,
+			"failed to delete jump rule from FORWARD chain to AZURE-NPM chain in %s with exit code %d and error: %s",
+			iptablesCommand(family), errCode, err.Error())
+	}
+
+	currentChains, err := ioutil.AllCurrentAzureChainsWithCommand(pMgr.ioShim.Exec, iptablesCommand(family), util.IptablesDefaultWaitTime)
+	if err != nil {
+		return npmerrors.SimpleErrorWrapper(fmt.Sprintf("failed to get current chains in %s for bootup", iptablesCommand(family)), err)
+	}
+	if len(currentChains) == 0 {
+		return nil
+	}
+
+	klog.Infof("flushing %d chains in %s", len(currentChains), iptablesCommand(family))
+	chains := make([]string, 0, len(currentChains))
+	for chain := range currentChains {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+
+	creator := pMgr.newCreatorWithChains(nil)
+	for _, chain := range chains {
+		creator.AddLine("", nil, util.IptablesFlushFlag, chain)
+		pMgr.staleChains.add(chain) // won't add base chains
+	}
+	creator.AddLine("", nil, util.IptablesRestoreCommit)
+	if err := restore(creator, family); err != nil {
+		return npmerrors.SimpleErrorWrapper(fmt.Sprintf("failed to flush chains in %s for bootup", iptablesCommand(family)), err)
+	}
+	return nil
+}
+
+func (pMgr *PolicyManager) creatorForNFTablesBootup() *ioutil.FileCreator {
+	creator := newNFTablesCreator(pMgr.ioShim)
+
+	// recreate the table. The table must exist to be deleted.
+	creator.AddLine("", nil, "add", "table", util.NftAzureTableFamily, util.NftAzureTable)
+	creator.AddLine("", nil, "delete", "table", util.NftAzureTableFamily, util.NftAzureTable)
+	creator.AddLine("", nil, "add", "table", util.NftAzureTableFamily, util.NftAzureTable)
+
+	priority := nftForwardPriorityAfter
+	if pMgr.PlaceAzureChainFirst == util.PlaceAzureChainFirst {
+		priority = nftForwardPriorityFirst
+	}
+	addNFTLine(creator, "", nil, "add", "chain", util.NftAzureForwardChain, fmt.Sprintf("{ type filter hook forward priority %s; policy accept; }", priority))
+	for _, chain := range pMgr.baseChains() {
+		addNFTLine(creator, "", nil, "add", "chain", chain)
+	}
+
+	// add the forward chain rule, which replaces the jump from FORWARD chain to AZURE-NPM chain
+	jumpToAzureChainSpecs := []string{"ct", "state", "new", "jump", util.IptablesAzureChain}
+	if !pMgr.EnableIPv6 {
+		jumpToAzureChainSpecs = append([]string{"meta", "nfproto", "ipv4"}, jumpToAzureChainSpecs...)
+	}
+	addNFTRule(creator, util.NftAzureForwardChain, jumpToAzureChainSpecs...)
+
+	// add AZURE-NPM-INGRESS-ALLOW-MARK chain rules
+	markIngressAllowSpecs := nftSetMarkSpecs(util.NftAzureIngressAllowMark)
+	markIngressAllowSpecs = append(markIngressAllowSpecs, nftCommentSpecs(fmt.Sprintf("SET-INGRESS-ALLOW-MARK-%s", util.NftAzureIngressAllowMark))...)
+	addNFTRule(creator, util.IptablesAzureIngressAllowMarkChain, markIngressAllowSpecs...)
+	addNFTRule(creator, util.IptablesAzureIngressAllowMarkChain, "jump", util.IptablesAzureEgressChain)
+
+	// add AZURE-NPM-ACCEPT chain rules
+	if pMgr.EnableDropLogging {
+		// a packet accepted after a NetworkPolicy marked it for drop mustn't keep the drop mark and ACL ID
+		clearMarkSpecs := []string{"meta", "mark", "set", "meta", "mark", "&", util.NftAzureKeepMarks}
+		clearMarkSpecs = append(clearMarkSpecs, nftCommentSpecs("CLEAR-AZURE-NPM-MARKS")...)
+		addNFTRule(creator, util.IptablesAzureAcceptChain, clearMarkSpecs...)
+	}
+	addNFTRule(creator, util.IptablesAzureAcceptChain, "accept")
+
+	// add AZURE-NPM-INGRESS and AZURE-NPM-EGRESS chain rules (and the admin tier chains, which are empty).
+	// To leave NPM deactivated, don't specify any rules for AZURE-NPM chain.
+	pMgr.writeNFTablesJumpChains(creator, nil)
+	return creator
+}
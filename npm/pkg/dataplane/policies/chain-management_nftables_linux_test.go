@@ -0,0 +1,154 @@
+package policies
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	dptestutils "github.com/Azure/azure-container-networking/npm/pkg/dataplane/testutils"
+	"github.com/Azure/azure-container-networking/npm/util"
+	testutils "github.com/Azure/azure-container-networking/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNFTablesCreatorForBootup(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          *PolicyManagerCfg
+		priority     string
+		forwardMatch string
+	}{
+		{
+			name:         "place azure chain first",
+			cfg:          nftablesConfig,
+			priority:     "-1",
+			forwardMatch: "meta nfproto ipv4 ",
+		},
+		{
+			name: "place azure chain after kube-services",
+			cfg: &PolicyManagerCfg{
+				PolicyMode:           IPSetPolicyMode,
+				PlaceAzureChainFirst: util.PlaceAzureChainAfterKubeServices,
+				EnableNFTables:       true,
+			},
+			priority:     "1",
+			forwardMatch: "meta nfproto ipv4 ",
+		},
+		{
+			name:     "ipv6",
+			cfg:      nftablesIPv6Config,
+			priority: "-1",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ioshim := common.NewMockIOShim(nil)
+			defer ioshim.VerifyCalls(t, nil)
+			pMgr := NewPolicyManager(ioshim, tt.cfg)
+
+			creator := pMgr.creatorForNFTablesBootup()
+			actualLines := strings.Split(creator.ToString(), "\n")
+			expectedLines := joinLines(
+				[]string{
+					"add table inet azure-npm",
+					"delete table inet azure-npm",
+					"add table inet azure-npm",
+					"add chain inet azure-npm AZURE-NPM-FORWARD { type filter hook forward priority " + tt.priority + "; policy accept; }",
+					"add chain inet azure-npm AZURE-NPM",
+					"add chain inet azure-npm AZURE-NPM-INGRESS",
+					"add chain inet azure-npm AZURE-NPM-INGRESS-ALLOW-MARK",
+					"add chain inet azure-npm AZURE-NPM-EGRESS",
+					"add chain inet azure-npm AZURE-NPM-ACCEPT",
+					"add rule inet azure-npm AZURE-NPM-FORWARD " + tt.forwardMatch + "ct state new jump AZURE-NPM",
+					`add rule inet azure-npm AZURE-NPM-INGRESS-ALLOW-MARK meta mark set meta mark | 0x200 comment "SET-INGRESS-ALLOW-MARK-0x200"`,
+					"add rule inet azure-npm AZURE-NPM-INGRESS-ALLOW-MARK jump AZURE-NPM-EGRESS",
+					"add rule inet azure-npm AZURE-NPM-ACCEPT accept",
+				},
+				nftFlushJumpChainsLines,
+				[]string{nftIngressDropOnMarkLine},
+				nftEgressDropOnMarkLines,
+				[]string{""},
+			)
+			dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+		})
+	}
+}
+
+func TestNFTablesCreatorForBootupWithDropLogging(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	cfg := *nftablesConfig
+	cfg.EnableDropLogging = true
+	pMgr := NewPolicyManager(ioshim, &cfg)
+
+	creator := pMgr.creatorForNFTablesBootup()
+	actualLines := strings.Split(creator.ToString(), "\n")
+	// packets accepted after a NetworkPolicy marked them for drop don't keep the drop mark and ACL ID
+	clearMarksLine := `add rule inet azure-npm AZURE-NPM-ACCEPT meta mark set meta mark & 0xf1ff comment "CLEAR-AZURE-NPM-MARKS"`
+	require.Contains(t, actualLines, clearMarksLine)
+	require.Contains(t, actualLines, "add rule inet azure-npm AZURE-NPM-ACCEPT accept")
+	require.Less(t, slices.Index(actualLines, clearMarksLine), slices.Index(actualLines, "add rule inet azure-npm AZURE-NPM-ACCEPT accept"))
+}
+
+func TestNFTablesBootup(t *testing.T) {
+	deleteJumpCommand := testutils.TestCmd{
+		Cmd:      []string{"iptables", "-w", "60", "-D", "FORWARD", "-j", "AZURE-NPM", "-m", "conntrack", "--ctstate", "NEW"},
+		ExitCode: 2, // AZURE-NPM chain didn't exist
+	}
+
+	t.Run("no iptables chains", func(t *testing.T) {
+		calls := []testutils.TestCmd{
+			deleteJumpCommand,
+			{Cmd: listAllCommandStrings, PipedToCommand: true},
+			{Cmd: []string{"grep", "Chain AZURE-NPM"}, ExitCode: 1},
+			fakeNFTCommand,
+		}
+		ioshim := common.NewMockIOShim(calls)
+		defer ioshim.VerifyCalls(t, calls)
+		pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+		require.NoError(t, pMgr.Bootup(nil))
+		require.Empty(t, pMgr.staleChains.chainsToCleanup)
+	})
+
+	t.Run("flush iptables chains from a previous run", func(t *testing.T) {
+		calls := []testutils.TestCmd{
+			{
+				Cmd: []string{"iptables", "-w", "60", "-D", "FORWARD", "-j", "AZURE-NPM", "-m", "conntrack", "--ctstate", "NEW"},
+			},
+			{Cmd: listAllCommandStrings, PipedToCommand: true},
+			{
+				Cmd: []string{"grep", "Chain AZURE-NPM"},
+				Stdout: "Chain AZURE-NPM (1 references)\n" +
+					"Chain AZURE-NPM-INGRESS-123456 (1 references)\n",
+			},
+			fakeIPTablesRestoreCommand,
+			fakeNFTCommand,
+		}
+		ioshim := common.NewMockIOShim(calls)
+		defer ioshim.VerifyCalls(t, calls)
+		pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+		require.NoError(t, pMgr.Bootup(nil))
+		assertStaleChainsContain(t, pMgr.staleChains, "AZURE-NPM-INGRESS-123456")
+	})
+
+	t.Run("nft failure", func(t *testing.T) {
+		nftFailure := testutils.TestCmd{Cmd: []string{"nft", "-f", "-"}, ExitCode: 1}
+		calls := []testutils.TestCmd{
+			deleteJumpCommand,
+			{Cmd: listAllCommandStrings, PipedToCommand: true},
+			{Cmd: []string{"grep", "Chain AZURE-NPM"}, ExitCode: 1},
+			nftFailure,
+			nftFailure,
+		}
+		ioshim := common.NewMockIOShim(calls)
+		defer ioshim.VerifyCalls(t, calls)
+		pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+		require.Error(t, pMgr.Bootup(nil))
+	})
+}
@@ -0,0 +1,223 @@
+package policies
+
+import "strings"
+
+// SetMembership checks whether IPSets match an IP (i.e. the IPSetManager).
+type SetMembership interface {
+	// ContainsIP expects the prefixed ipset name. The protocol and port are only used for NamedPorts sets.
+	ContainsIP(setName, ip, protocol string, port int32) bool
+}
+
+// Flow is a flow to explain.
+type Flow struct {
+	SrcIP string `json:"srcIP"`
+	// SrcPodKey is optional and only informational
+	SrcPodKey string `json:"srcPodKey,omitempty"`
+	DstIP     string `json:"dstIP"`
+	// DstPodKey is optional and only informational
+	DstPodKey string `json:"dstPodKey,omitempty"`
+	// Protocol is e.g. TCP, UDP, or SCTP (case-insensitive)
+	Protocol string `json:"protocol"`
+	// DstPort can be zero if unknown, in which case ACLs with ports or named ports don't match the flow
+	DstPort int32 `json:"dstPort,omitempty"`
+}
+
+// MatchedACL is an ACL which matched a flow.
+type MatchedACL struct {
+	PolicyKey string `json:"policyKey"`
+	// ACLID is the index of the ACL in the policy
+	ACLID int `json:"aclID"`
+	// Direction is the direction the ACL matched in (IN or OUT)
+	Direction Direction `json:"direction"`
+	Target    Verdict   `json:"target"`
+	// Chain and Comment are empty in Windows
+	Chain   string `json:"chain,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// FlowExplanation explains the verdict of the dataplane for a flow.
+type FlowExplanation struct {
+	Flow Flow `json:"flow"`
+	// Policies are the keys of the policies which apply to the flow, in evaluation order.
+	Policies []string `json:"policies"`
+	// ACLs are the ACLs matching the flow, in evaluation order. ACLs after a decisive ACL aren't evaluated.
+	ACLs []*MatchedACL `json:"acls"`
+	// IngressVerdict and EgressVerdict are empty if the direction isn't evaluated since the flow is already dropped.
+	IngressVerdict Verdict `json:"ingressVerdict,omitempty"`
+	EgressVerdict  Verdict `json:"egressVerdict,omitempty"`
+	Verdict        Verdict `json:"verdict"`
+}
+
+/*
+ExplainFlow evaluates the flow against the cached policies in the same order as the Linux dataplane.
+Ingress is evaluated before egress, and each direction is evaluated as follows:
+ 1. AdminTier ACLs in order of policy priority: the first Allowed or Dropped ACL decides, and a Passed ACL skips to NetworkPolicies.
+ 2. NetworkPolicies selecting the destination (ingress) or source (egress): any Allowed ACL allows the flow.
+    Otherwise, any Dropped ACL drops the flow.
+ 3. BaselineTier ACLs: the first Allowed or Dropped ACL decides.
+ 4. Otherwise, the flow is allowed.
+
+NetworkPolicies are evaluated in order of policy key since their order doesn't affect the verdict.
+*/
+func (pMgr *PolicyManager) ExplainFlow(flow Flow, sets SetMembership) *FlowExplanation {
+	pMgr.policyMap.RLock()
+	defer pMgr.policyMap.RUnlock()
+
+	explanation := &FlowExplanation{
+		Flow:     flow,
+		Policies: make([]string, 0),
+		ACLs:     make([]*MatchedACL, 0),
+	}
+	explanation.IngressVerdict = pMgr.explainDirection(explanation, Ingress, sets)
+	if explanation.IngressVerdict == Dropped {
+		explanation.Verdict = Dropped
+		return explanation
+	}
+	explanation.EgressVerdict = pMgr.explainDirection(explanation, Egress, sets)
+	explanation.Verdict = explanation.EgressVerdict
+	return explanation
+}
+
+func (pMgr *PolicyManager) explainDirection(explanation *FlowExplanation, direction Direction, sets SetMembership) Verdict {
+	// 1. AdminTier
+	for _, networkPolicy := range sortedTierPolicies(pMgr.policyMap.cache, AdminTier) {
+		verdict := explanation.firstMatch(networkPolicy, direction, sets)
+		if verdict == Allowed || verdict == Dropped {
+			return verdict
+		}
+		if verdict == Passed {
+			break
+		}
+	}
+
+	// 2. NetworkPolicies
+	markedForDrop := false
+	for _, networkPolicy := range sortedTierPolicies(pMgr.policyMap.cache, NamespaceTier) {
+		if !hasDirection(networkPolicy, direction) || !explanation.selects(networkPolicy, direction, sets) {
+			continue
+		}
+		explanation.addPolicy(networkPolicy.PolicyKey)
+		for aclID, aclPolicy := range networkPolicy.ACLs {
+			if !explanation.matches(aclPolicy, direction, sets) {
+				continue
+			}
+			explanation.addACL(networkPolicy, aclID, direction)
+			if aclPolicy.Target == Allowed {
+				return Allowed
+			}
+			markedForDrop = true
+		}
+	}
+	if markedForDrop {
+		return Dropped
+	}
+
+	// 3. BaselineTier
+	for _, networkPolicy := range sortedTierPolicies(pMgr.policyMap.cache, BaselineTier) {
+		if verdict := explanation.firstMatch(networkPolicy, direction, sets); verdict != "" {
+			return verdict
+		}
+	}
+
+	// 4. default allow
+	return Allowed
+}
+
+// firstMatch returns the target of the first ACL of the admin tier policy matching the flow, or "" if no ACL matches.
+func (explanation *FlowExplanation) firstMatch(networkPolicy *NPMNetworkPolicy, direction Direction, sets SetMembership) Verdict {
+	for aclID, aclPolicy := range networkPolicy.ACLs {
+		if explanation.matches(aclPolicy, direction, sets) {
+			explanation.addPolicy(networkPolicy.PolicyKey)
+			explanation.addACL(networkPolicy, aclID, direction)
+			return aclPolicy.Target
+		}
+	}
+	return ""
+}
+
+// hasDirection returns whether the policy has ACLs in the direction. Otherwise, the policy doesn't apply to the direction.
+func hasDirection(networkPolicy *NPMNetworkPolicy, direction Direction) bool {
+	for _, aclPolicy := range networkPolicy.ACLs {
+		if aclPolicy.hasDirection(direction) {
+			return true
+		}
+	}
+	return false
+}
+
+func (aclPolicy *ACLPolicy) hasDirection(direction Direction) bool {
+	if direction == Ingress {
+		return aclPolicy.hasIngress()
+	}
+	return aclPolicy.hasEgress()
+}
+
+// selects returns whether the NetworkPolicy's pod selector matches the destination (ingress) or source (egress).
+func (explanation *FlowExplanation) selects(networkPolicy *NPMNetworkPolicy, direction Direction, sets SetMembership) bool {
+	ip := explanation.Flow.SrcIP
+	if direction == Ingress {
+		ip = explanation.Flow.DstIP
+	}
+	for _, setInfo := range networkPolicy.PodSelectorList {
+		if sets.ContainsIP(setInfo.IPSet.GetPrefixName(), ip, "", 0) != setInfo.Included {
+			return false
+		}
+	}
+	return true
+}
+
+func (explanation *FlowExplanation) matches(aclPolicy *ACLPolicy, direction Direction, sets SetMembership) bool {
+	if !aclPolicy.hasDirection(direction) {
+		return false
+	}
+
+	flow := explanation.Flow
+	if aclPolicy.Protocol != UnspecifiedProtocol && !strings.EqualFold(string(aclPolicy.Protocol), flow.Protocol) {
+		return false
+	}
+	if !aclPolicy.DstPorts.isUnspecified() {
+		endPort := aclPolicy.DstPorts.EndPort
+		if endPort < aclPolicy.DstPorts.Port {
+			endPort = aclPolicy.DstPorts.Port
+		}
+		if flow.DstPort < aclPolicy.DstPorts.Port || flow.DstPort > endPort {
+			return false
+		}
+	}
+
+	return explanation.matchesSets(aclPolicy.SrcList, sets) && explanation.matchesSets(aclPolicy.DstList, sets)
+}
+
+func (explanation *FlowExplanation) matchesSets(setInfos []SetInfo, sets SetMembership) bool {
+	flow := explanation.Flow
+	for _, setInfo := range setInfos {
+		ip := flow.DstIP
+		if setInfo.MatchType == SrcMatch {
+			ip = flow.SrcIP
+		}
+		if sets.ContainsIP(setInfo.IPSet.GetPrefixName(), ip, flow.Protocol, flow.DstPort) != setInfo.Included {
+			return false
+		}
+	}
+	return true
+}
+
+func (explanation *FlowExplanation) addPolicy(policyKey string) {
+	for _, key := range explanation.Policies {
+		if key == policyKey {
+			return
+		}
+	}
+	explanation.Policies = append(explanation.Policies, policyKey)
+}
+
+func (explanation *FlowExplanation) addACL(networkPolicy *NPMNetworkPolicy, aclID int, direction Direction) {
+	matchedACL := &MatchedACL{
+		PolicyKey: networkPolicy.PolicyKey,
+		ACLID:     aclID,
+		Direction: direction,
+		Target:    networkPolicy.ACLs[aclID].Target,
+	}
+	matchedACL.Chain, matchedACL.Comment = networkPolicy.aclChainAndComment(aclID)
+	explanation.ACLs = append(explanation.ACLs, matchedACL)
+}
@@ -0,0 +1,180 @@
+package policies
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSetMembership maps prefixed set names to their member IPs
+type fakeSetMembership map[string]map[string]bool
+
+func (sets fakeSetMembership) ContainsIP(setName, ip, _ string, _ int32) bool {
+	return sets[setName][ip]
+}
+
+var (
+	explainNSX     = ipsets.NewIPSetMetadata("x", ipsets.Namespace)
+	explainNSY     = ipsets.NewIPSetMetadata("y", ipsets.Namespace)
+	explainAppDB   = ipsets.NewIPSetMetadata("app:db", ipsets.KeyValueLabelOfPod)
+	explainCIDRSet = ipsets.NewIPSetMetadata("external", ipsets.CIDRBlocks)
+
+	explainSets = fakeSetMembership{
+		explainNSX.GetPrefixName():     {"10.0.0.1": true, "10.0.0.2": true},
+		explainNSY.GetPrefixName():     {"10.0.1.1": true},
+		explainAppDB.GetPrefixName():   {"10.0.0.2": true},
+		explainCIDRSet.GetPrefixName(): {"20.0.0.1": true},
+	}
+)
+
+// allow ingress to pods in x from pods in y on TCP 80, and drop all other ingress to pods in x
+func explainAllowWebNetPol() *NPMNetworkPolicy {
+	return &NPMNetworkPolicy{
+		Namespace:   "x",
+		PolicyKey:   "x/allow-web",
+		ACLPolicyID: "azure-acl-x-allow-web",
+		PodSelectorList: []SetInfo{
+			{IPSet: explainNSX, Included: true, MatchType: EitherMatch},
+		},
+		ACLs: []*ACLPolicy{
+			{
+				SrcList:   []SetInfo{{IPSet: explainNSY, Included: true, MatchType: SrcMatch}},
+				Target:    Allowed,
+				Direction: Ingress,
+				Protocol:  TCP,
+				DstPorts:  Ports{Port: 80, EndPort: 80},
+			},
+			{
+				Target:    Dropped,
+				Direction: Ingress,
+				Protocol:  UnspecifiedProtocol,
+			},
+		},
+	}
+}
+
+func explainACLs(explanation *FlowExplanation) []string {
+	acls := make([]string, 0, len(explanation.ACLs))
+	for _, matchedACL := range explanation.ACLs {
+		acls = append(acls, fmt.Sprintf("%s:%d:%s:%s", matchedACL.PolicyKey, matchedACL.ACLID, matchedACL.Direction, matchedACL.Target))
+	}
+	return acls
+}
+
+func TestExplainFlow(t *testing.T) {
+	anpDenyDB := adminTierNetPol(AdminTier, "deny-db", 10, &ACLPolicy{
+		SrcList:   []SetInfo{{IPSet: explainNSY, Included: true, MatchType: SrcMatch}},
+		DstList:   []SetInfo{{IPSet: explainAppDB, Included: true, MatchType: DstMatch}},
+		Target:    Dropped,
+		Direction: Ingress,
+		Protocol:  UnspecifiedProtocol,
+	})
+	anpPass := adminTierNetPol(AdminTier, "pass", 5, &ACLPolicy{
+		DstList:   []SetInfo{{IPSet: explainNSY, Included: true, MatchType: DstMatch}},
+		Target:    Passed,
+		Direction: Egress,
+		Protocol:  UnspecifiedProtocol,
+	})
+	banpDenyExternal := adminTierNetPol(BaselineTier, "default", 0, &ACLPolicy{
+		DstList:   []SetInfo{{IPSet: explainCIDRSet, Included: true, MatchType: DstMatch}},
+		Target:    Dropped,
+		Direction: Egress,
+		Protocol:  UnspecifiedProtocol,
+	})
+
+	pMgr := NewPolicyManager(common.NewMockIOShim(nil), ipsetConfig)
+	for _, networkPolicy := range []*NPMNetworkPolicy{explainAllowWebNetPol(), anpDenyDB, anpPass, banpDenyExternal} {
+		pMgr.policyMap.cache[networkPolicy.PolicyKey] = networkPolicy
+	}
+
+	tests := []struct {
+		name             string
+		flow             Flow
+		expectedPolicies []string
+		expectedACLs     []string
+		expectedIngress  Verdict
+		expectedEgress   Verdict
+		expectedVerdict  Verdict
+	}{
+		{
+			name:             "allowed by NetworkPolicy",
+			flow:             Flow{SrcIP: "10.0.1.1", DstIP: "10.0.0.1", Protocol: "tcp", DstPort: 80},
+			expectedPolicies: []string{"x/allow-web"},
+			expectedACLs:     []string{"x/allow-web:0:IN:ALLOW"},
+			expectedIngress:  Allowed,
+			expectedEgress:   Allowed,
+			expectedVerdict:  Allowed,
+		},
+		{
+			name:             "dropped by NetworkPolicy on another port",
+			flow:             Flow{SrcIP: "10.0.1.1", DstIP: "10.0.0.1", Protocol: "TCP", DstPort: 443},
+			expectedPolicies: []string{"x/allow-web"},
+			expectedACLs:     []string{"x/allow-web:1:IN:DROP"},
+			expectedIngress:  Dropped,
+			expectedVerdict:  Dropped,
+		},
+		{
+			name:             "dropped by AdminNetworkPolicy before NetworkPolicies",
+			flow:             Flow{SrcIP: "10.0.1.1", DstIP: "10.0.0.2", Protocol: "TCP", DstPort: 80},
+			expectedPolicies: []string{"AdminNetworkPolicy/deny-db"},
+			expectedACLs:     []string{"AdminNetworkPolicy/deny-db:0:IN:DROP"},
+			expectedIngress:  Dropped,
+			expectedVerdict:  Dropped,
+		},
+		{
+			name:             "passed by AdminNetworkPolicy and allowed by default",
+			flow:             Flow{SrcIP: "10.0.0.1", DstIP: "10.0.1.1", Protocol: "UDP", DstPort: 53},
+			expectedPolicies: []string{"AdminNetworkPolicy/pass"},
+			expectedACLs:     []string{"AdminNetworkPolicy/pass:0:OUT:PASS"},
+			expectedIngress:  Allowed,
+			expectedEgress:   Allowed,
+			expectedVerdict:  Allowed,
+		},
+		{
+			name:             "dropped by BaselineAdminNetworkPolicy",
+			flow:             Flow{SrcIP: "10.0.0.1", DstIP: "20.0.0.1", Protocol: "TCP", DstPort: 443},
+			expectedPolicies: []string{"BaselineAdminNetworkPolicy/default"},
+			expectedACLs:     []string{"BaselineAdminNetworkPolicy/default:0:OUT:DROP"},
+			expectedIngress:  Allowed,
+			expectedEgress:   Dropped,
+			expectedVerdict:  Dropped,
+		},
+		{
+			name:             "allowed by default",
+			flow:             Flow{SrcIP: "10.0.0.1", DstIP: "10.0.0.9", Protocol: "TCP", DstPort: 443},
+			expectedPolicies: []string{},
+			expectedACLs:     []string{},
+			expectedIngress:  Allowed,
+			expectedEgress:   Allowed,
+			expectedVerdict:  Allowed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			explanation := pMgr.ExplainFlow(tt.flow, explainSets)
+			require.Equal(t, tt.flow, explanation.Flow)
+			require.Equal(t, tt.expectedPolicies, explanation.Policies)
+			require.Equal(t, tt.expectedACLs, explainACLs(explanation))
+			require.Equal(t, tt.expectedIngress, explanation.IngressVerdict)
+			require.Equal(t, tt.expectedEgress, explanation.EgressVerdict)
+			require.Equal(t, tt.expectedVerdict, explanation.Verdict)
+		})
+	}
+}
+
+func TestExplainFlowIgnoresPoliciesWithoutDirection(t *testing.T) {
+	pMgr := NewPolicyManager(common.NewMockIOShim(nil), ipsetConfig)
+	networkPolicy := explainAllowWebNetPol()
+	pMgr.policyMap.cache[networkPolicy.PolicyKey] = networkPolicy
+
+	// the policy selects the source but only has ingress ACLs
+	explanation := pMgr.ExplainFlow(Flow{SrcIP: "10.0.0.1", DstIP: "10.0.1.1", Protocol: "TCP", DstPort: 80}, explainSets)
+	require.Empty(t, explanation.Policies)
+	require.Empty(t, explanation.ACLs)
+	require.Equal(t, Allowed, explanation.Verdict)
+}
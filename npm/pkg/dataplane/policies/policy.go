@@ -29,6 +29,11 @@ type NPMNetworkPolicy struct {
 	// and not from pod selector IPSets, including children of a NestedLabelOfPod ipset
 	RuleIPSets []*ipsets.TranslatedIPSet
 	ACLs       []*ACLPolicy
+	// Tier is NamespaceTier for NetworkPolicies.
+	// ACLs of AdminTier and BaselineTier policies are evaluated in order and include the subject in their SrcList or DstList.
+	Tier PolicyTier
+	// Priority orders the AdminTier policies. Lower values are evaluated first.
+	Priority int32
 	// podIP is key and endpoint ID as value
 	// Will be populated by dataplane and policy manager
 	PodEndpoints map[string]string
@@ -42,6 +47,25 @@ func NewNPMNetworkPolicy(netPolName, netPolNamespace string) *NPMNetworkPolicy {
 	}
 }
 
+// NewAdminNPMNetworkPolicy creates a policy for an AdminNetworkPolicy or BaselineAdminNetworkPolicy.
+// These policies are cluster-scoped, so the PolicyKey is "<kind>/<name>". Kinds are capitalized so they can't collide with a namespace.
+func NewAdminNPMNetworkPolicy(tier PolicyTier, name string, priority int32) *NPMNetworkPolicy {
+	return &NPMNetworkPolicy{
+		PolicyKey: AdminPolicyKey(tier, name),
+		Tier:      tier,
+		Priority:  priority,
+	}
+}
+
+// AdminPolicyKey returns the PolicyKey for the AdminNetworkPolicy or BaselineAdminNetworkPolicy with the name.
+func AdminPolicyKey(tier PolicyTier, name string) string {
+	return fmt.Sprintf("%s/%s", tier.Kind(), name)
+}
+
+func (netPol *NPMNetworkPolicy) isAdminTier() bool {
+	return netPol.Tier == AdminTier || netPol.Tier == BaselineTier
+}
+
 func (netPol *NPMNetworkPolicy) AllPodSelectorIPSets() []*ipsets.TranslatedIPSet {
 	return append(netPol.PodSelectorIPSets, netPol.ChildPodSelectorIPSets...)
 }
@@ -61,6 +85,11 @@ func (netPol *NPMNetworkPolicy) numACLRulesProducedInKernel() int {
 		}
 	}
 
+	// admin tier ACLs are written directly into the tier chains
+	if netPol.isAdminTier() {
+		return numRules
+	}
+
 	// both Windows and Linux have an extra ACL rule for ingress and an extra rule for egress
 	if hasIngress {
 		numRules++
@@ -132,10 +161,20 @@ func NormalizePolicy(networkPolicy *NPMNetworkPolicy) {
 }
 
 func ValidatePolicy(networkPolicy *NPMNetworkPolicy) error {
+	if !networkPolicy.hasKnownTier() {
+		return npmerrors.SimpleError(fmt.Sprintf("NetPol %s has unknown tier [%s]", networkPolicy.PolicyKey, networkPolicy.Tier))
+	}
+	if util.IsWindowsDP() && networkPolicy.isAdminTier() {
+		return npmerrors.SimpleError(fmt.Sprintf("NetPol %s has unsupported tier [%s] on Windows", networkPolicy.PolicyKey, networkPolicy.Tier))
+	}
+
 	for _, aclPolicy := range networkPolicy.ACLs {
 		if !aclPolicy.hasKnownTarget() {
 			return npmerrors.SimpleError(fmt.Sprintf("ACL policy for NetPol %s has unknown target [%s]", networkPolicy.PolicyKey, aclPolicy.Target))
 		}
+		if aclPolicy.Target == Passed && networkPolicy.Tier != AdminTier {
+			return npmerrors.SimpleError(fmt.Sprintf("ACL policy for NetPol %s has target [%s], which is only valid in tier [%s]", networkPolicy.PolicyKey, Passed, AdminTier))
+		}
 		if !aclPolicy.hasKnownDirection() {
 			return npmerrors.SimpleError(fmt.Sprintf("ACL policy for NetPol %s has unknown direction [%s]", networkPolicy.PolicyKey, aclPolicy.Direction))
 		}
@@ -224,7 +263,11 @@ func (aclPolicy *ACLPolicy) hasKnownProtocol() bool {
 }
 
 func (aclPolicy *ACLPolicy) hasKnownTarget() bool {
-	return aclPolicy.Target == Allowed || aclPolicy.Target == Dropped
+	return aclPolicy.Target == Allowed || aclPolicy.Target == Dropped || aclPolicy.Target == Passed
+}
+
+func (netPol *NPMNetworkPolicy) hasKnownTier() bool {
+	return netPol.Tier == NamespaceTier || netPol.isAdminTier()
 }
 
 func (aclPolicy *ACLPolicy) satisifiesPortAndProtocolConstraints() bool {
@@ -330,8 +373,34 @@ const (
 	Allowed Verdict = "ALLOW"
 	// Dropped is denying a flow
 	Dropped Verdict = "DROP"
+	// Passed skips the remaining AdminTier ACLs and lets NetworkPolicies (or else BaselineTier ACLs) decide the flow
+	Passed Verdict = "PASS"
 )
 
+// PolicyTier determines when a policy is evaluated relative to other policies.
+type PolicyTier string
+
+const (
+	// AdminTier policies are AdminNetworkPolicies, which are evaluated before NetworkPolicies
+	AdminTier PolicyTier = "Admin"
+	// NamespaceTier policies are NetworkPolicies
+	NamespaceTier PolicyTier = ""
+	// BaselineTier policies are BaselineAdminNetworkPolicies, which are evaluated if no NetworkPolicy selects the Pod
+	BaselineTier PolicyTier = "Baseline"
+)
+
+// Kind returns the kind of Kubernetes object for policies in the tier.
+func (tier PolicyTier) Kind() string {
+	switch tier {
+	case AdminTier:
+		return "AdminNetworkPolicy"
+	case BaselineTier:
+		return "BaselineAdminNetworkPolicy"
+	default:
+		return "NetworkPolicy"
+	}
+}
+
 // Protocol can be TCP, UDP, SCTP, or unspecified since they are currently supported in networkpolicy.
 // Protocol value is case-sensitive (Capital now).
 // TODO: Need to remove this dependency on case-sensitivity.
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/util"
 )
@@ -75,6 +76,49 @@ func (networkPolicy *NPMNetworkPolicy) commentForJump(direction UniqueDirection)
 	return fmt.Sprintf("%s-POLICY-%s-%s-%s-IN-ns-%s", prefix, networkPolicy.PolicyKey, toFrom, podSelectorComment, networkPolicy.Namespace)
 }
 
+// commentForAdminTierACL prefixes the ACL comment with the policy key since admin tier ACLs aren't in a policy chain.
+func (networkPolicy *NPMNetworkPolicy) commentForAdminTierACL(aclPolicy *ACLPolicy) string {
+	return fmt.Sprintf("%s-%s", networkPolicy.PolicyKey, aclPolicy.comment())
+}
+
+// dropLogTag identifies the ACL at index aclID in drop logs, as the NFLOG prefix of admin tier ACLs and by the ID which NetworkPolicy ACLs set in the drop mark.
+func (networkPolicy *NPMNetworkPolicy) dropLogTag(aclID int) droplog.Tag {
+	direction := droplog.Egress
+	if networkPolicy.ACLs[aclID].hasIngress() {
+		direction = droplog.Ingress
+	}
+	return droplog.Tag{
+		Direction:  direction,
+		PolicyHash: util.Hash(networkPolicy.PolicyKey),
+		ACLID:      aclID,
+	}
+}
+
+// dropLogAttribution returns the policy, chain, and ACL comment for the ACL at index aclID.
+func (networkPolicy *NPMNetworkPolicy) dropLogAttribution(aclID int) droplog.Attribution {
+	attribution := droplog.Attribution{
+		PolicyKey: networkPolicy.PolicyKey,
+		Namespace: networkPolicy.Namespace,
+		Direction: networkPolicy.dropLogTag(aclID).Direction,
+		ACLID:     aclID,
+	}
+	attribution.Chain, attribution.Comment = networkPolicy.aclChainAndComment(aclID)
+	return attribution
+}
+
+// aclChainAndComment returns the chain and comment of the rule for the ACL at index aclID.
+func (networkPolicy *NPMNetworkPolicy) aclChainAndComment(aclID int) (chain, comment string) {
+	aclPolicy := networkPolicy.ACLs[aclID]
+	direction := UniqueDirection(aclPolicy.hasIngress())
+	if networkPolicy.isAdminTier() {
+		return adminTierChainName(networkPolicy.Tier, direction), networkPolicy.commentForAdminTierACL(aclPolicy)
+	}
+	if direction == forIngress {
+		return networkPolicy.ingressChainName(), aclPolicy.comment()
+	}
+	return networkPolicy.egressChainName(), aclPolicy.comment()
+}
+
 func commentForInfos(infos []SetInfo) string {
 	infoComments := make([]string, 0, len(infos))
 	for _, info := range infos {
@@ -91,13 +135,13 @@ func (info SetInfo) comment() string {
 	return "!" + name
 }
 
-func (info SetInfo) matchSetSpecs(matchString string) []string {
+func (info SetInfo) matchSetSpecs(matchString string, family ipsets.IPFamily) []string {
 	specs := make([]string, 0, maxLengthForMatchSetSpecs)
 	specs = append(specs, util.IptablesModuleFlag, util.IptablesSetModuleFlag)
 	if !info.Included {
 		specs = append(specs, util.IptablesNotFlag)
 	}
-	hashedSetName := info.IPSet.GetHashedName()
+	hashedSetName := info.IPSet.GetHashedNameForFamily(family)
 	specs = append(specs, util.IptablesMatchSetFlag, hashedSetName, matchString)
 	return specs
 }
@@ -128,9 +172,12 @@ func (aclPolicy *ACLPolicy) comment() string {
 	}
 
 	builder := strings.Builder{}
-	if aclPolicy.Target == Allowed {
+	switch aclPolicy.Target {
+	case Allowed:
 		builder.WriteString("ALLOW")
-	} else {
+	case Passed:
+		builder.WriteString("PASS")
+	default:
 		builder.WriteString("DROP")
 	}
 
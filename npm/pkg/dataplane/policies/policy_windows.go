@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Microsoft/hcsshim/hcn"
 )
@@ -207,3 +208,22 @@ func getHCNAction(verdict Verdict) hcn.ActionType {
 	}
 	return ""
 }
+
+// dropLogAttribution returns the policy for the ACL at index aclID. Drop logging is only supported in Linux.
+func (networkPolicy *NPMNetworkPolicy) dropLogAttribution(aclID int) droplog.Attribution {
+	direction := droplog.Egress
+	if networkPolicy.ACLs[aclID].hasIngress() {
+		direction = droplog.Ingress
+	}
+	return droplog.Attribution{
+		PolicyKey: networkPolicy.PolicyKey,
+		Namespace: networkPolicy.Namespace,
+		Direction: direction,
+		ACLID:     aclID,
+	}
+}
+
+// aclChainAndComment returns empty strings since ACLs in Windows have no chain or comment.
+func (networkPolicy *NPMNetworkPolicy) aclChainAndComment(_ int) (chain, comment string) {
+	return "", ""
+}
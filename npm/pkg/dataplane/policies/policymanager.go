@@ -2,10 +2,12 @@ package policies
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
 	"github.com/Azure/azure-container-networking/npm/util"
 	npmerrors "github.com/Azure/azure-container-networking/npm/util/errors"
 	"k8s.io/klog"
@@ -26,6 +28,8 @@ const (
 	// it represents the number of rules unrelated to policies
 	// it's technically 3 off when there are no policies since we flush the AZURE-NPM chain then
 	numLinuxBaseACLRules = 11
+	// the jumps from AZURE-NPM-INGRESS and AZURE-NPM-EGRESS to the admin and baseline tier chains
+	numLinuxAdminTierBaseACLRules = 4
 )
 
 type PolicyManagerCfg struct {
@@ -39,6 +43,15 @@ type PolicyManagerCfg struct {
 	// The zero value is valid.
 	// A NetworkPolicy's ACLs are always in the same batch, and there will be at least one NetworkPolicy per batch.
 	MaxBatchedACLsPerPod int
+	// EnableIPv6 only affects Linux. If true, policies are also enforced for IPv6 traffic via ip6tables.
+	EnableIPv6 bool
+	// EnableNFTables only affects Linux. If true, policies are programmed with nftables instead of iptables.
+	EnableNFTables bool
+	// EnableAdminNetworkPolicy only affects Linux. If true, the chains for AdminNetworkPolicies and BaselineAdminNetworkPolicies are created.
+	EnableAdminNetworkPolicy bool
+	// EnableDropLogging only affects Linux. If true, dropped packets are logged to an NFLOG group with the ACL which dropped them.
+	// Logging is rate-limited per AdminNetworkPolicy ACL, and per direction for NetworkPolicies.
+	EnableDropLogging bool
 }
 
 type PolicyMap struct {
@@ -62,6 +75,10 @@ type PolicyManager struct {
 	ioShim           *common.IOShim
 	staleChains      *staleChains
 	reconcileManager *reconcileManager
+	// dropLogMarkIDs are the IDs which NetworkPolicy ACLs set in the drop mark when drop logging is enabled
+	dropLogMarkIDs *droplog.MarkIDs
+	// nftTransaction runs the nft transactions of policy changes if set (see SetNFTablesTransaction()). Only used in Linux.
+	nftTransaction nftTransaction
 	*PolicyManagerCfg
 }
 
@@ -75,6 +92,7 @@ func NewPolicyManager(ioShim *common.IOShim, cfg *PolicyManagerCfg) *PolicyManag
 		reconcileManager: &reconcileManager{
 			releaseLockSignal: make(chan struct{}, 1),
 		},
+		dropLogMarkIDs:   droplog.NewMarkIDs(),
 		PolicyManagerCfg: cfg,
 	}
 }
@@ -97,6 +115,9 @@ func (pMgr *PolicyManager) Bootup(epIDs []string) error {
 	if !util.IsWindowsDP() {
 		// update Prometheus metrics on success
 		metrics.IncNumACLRulesBy(numLinuxBaseACLRules)
+		if pMgr.EnableAdminNetworkPolicy {
+			metrics.IncNumACLRulesBy(numLinuxAdminTierBaseACLRules)
+		}
 	}
 
 	if util.IsWindowsDP() && pMgr.NodeIP == "" {
@@ -126,6 +147,29 @@ func (pMgr *PolicyManager) GetPolicy(policyKey string) (*NPMNetworkPolicy, bool)
 	return policy, ok
 }
 
+// ResolveDropLogTag returns the policy and ACL which logged a dropped packet.
+// It returns false if the policy no longer exists.
+func (pMgr *PolicyManager) ResolveDropLogTag(tag droplog.Tag) (droplog.Attribution, bool) {
+	pMgr.policyMap.RLock()
+	defer pMgr.policyMap.RUnlock()
+
+	for _, policy := range pMgr.policyMap.cache {
+		if util.Hash(policy.PolicyKey) != tag.PolicyHash {
+			continue
+		}
+		if tag.ACLID >= len(policy.ACLs) {
+			return droplog.Attribution{}, false
+		}
+		return policy.dropLogAttribution(tag.ACLID), true
+	}
+	return droplog.Attribution{}, false
+}
+
+// DropLogMarkTag returns the NetworkPolicy ACL whose ID is in the mark of a dropped packet.
+func (pMgr *PolicyManager) DropLogMarkTag(mark uint32) (droplog.Tag, bool) {
+	return pMgr.dropLogMarkIDs.Tag(mark)
+}
+
 func (pMgr *PolicyManager) AddPolicies(policies []*NPMNetworkPolicy, endpointList map[string]string) error {
 	nonEmptyPolicies := make([]*NPMNetworkPolicy, 0, len(policies))
 	for _, policy := range policies {
@@ -220,6 +264,7 @@ func (pMgr *PolicyManager) RemovePolicy(policyKey string) error {
 
 	// remove policy from cache
 	delete(pMgr.policyMap.cache, policyKey)
+	pMgr.dropLogMarkIDs.Release(util.Hash(policyKey))
 	return nil
 }
 
@@ -257,3 +302,20 @@ func (pMgr *PolicyManager) isLastPolicy() bool {
 	numPoliciesToDelete := 1
 	return len(pMgr.policyMap.cache) == numPoliciesToDelete
 }
+
+// sortedTierPolicies returns the policies in the tier, sorted by priority and then policy key.
+func sortedTierPolicies(allPolicies map[string]*NPMNetworkPolicy, tier PolicyTier) []*NPMNetworkPolicy {
+	tierPolicies := make([]*NPMNetworkPolicy, 0)
+	for _, networkPolicy := range allPolicies {
+		if networkPolicy.Tier == tier {
+			tierPolicies = append(tierPolicies, networkPolicy)
+		}
+	}
+	sort.Slice(tierPolicies, func(i, j int) bool {
+		if tierPolicies[i].Priority != tierPolicies[j].Priority {
+			return tierPolicies[i].Priority < tierPolicies[j].Priority
+		}
+		return tierPolicies[i].PolicyKey < tierPolicies[j].PolicyKey
+	})
+	return tierPolicies
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	"github.com/Azure/azure-container-networking/npm/util"
 	"github.com/Azure/azure-container-networking/npm/util/ioutil"
 	"k8s.io/klog"
@@ -42,20 +44,26 @@ Known errors that we should retry on:
 */
 
 func (pMgr *PolicyManager) addPolicies(networkPolicies []*NPMNetworkPolicy, _ map[string]string) error {
+	if pMgr.EnableNFTables {
+		return pMgr.addPoliciesWithNFTables(networkPolicies)
+	}
+
 	// 1. Add rules for the network policies and activate NPM (if necessary).
 	chainsToCreate := chainNames(networkPolicies)
-	creator := pMgr.creatorForNewNetworkPolicies(chainsToCreate, networkPolicies)
 
 	// Stop reconciling so we don't contend for iptables, and so reconcile doesn't delete chainsToCreate.
 	pMgr.reconcileManager.forceLock()
 	defer pMgr.reconcileManager.forceUnlock()
 
-	timer := metrics.StartNewTimer()
-	err := restore(creator)
-	metrics.RecordIPTablesRestoreLatency(timer, metrics.CreateOp)
-	if err != nil {
-		metrics.IncIPTablesRestoreFailures(metrics.CreateOp)
-		return fmt.Errorf("failed to restore iptables with updated policies. err: %w", err)
+	for _, family := range pMgr.families() {
+		creator := pMgr.creatorForNewNetworkPolicies(chainsToCreate, networkPolicies, family)
+		timer := metrics.StartNewTimer()
+		err := restore(creator, family)
+		metrics.RecordIPTablesRestoreLatency(timer, metrics.CreateOp)
+		if err != nil {
+			metrics.IncIPTablesRestoreFailures(metrics.CreateOp)
+			return fmt.Errorf("failed to restore %s iptables with updated policies. err: %w", family, err)
+		}
 	}
 
 	// 2. Make sure the new chains don't get deleted in the background
@@ -66,27 +74,37 @@ func (pMgr *PolicyManager) addPolicies(networkPolicies []*NPMNetworkPolicy, _ ma
 }
 
 func (pMgr *PolicyManager) removePolicy(networkPolicy *NPMNetworkPolicy, _ map[string]string) error {
+	if pMgr.EnableNFTables {
+		return pMgr.removePolicyWithNFTables(networkPolicy)
+	}
+
+	if networkPolicy.isAdminTier() {
+		return pMgr.removeAdminTierPolicy(networkPolicy)
+	}
+
 	chainsToDelete := chainNames([]*NPMNetworkPolicy{networkPolicy})
-	creator := pMgr.creatorForRemovingPolicies(chainsToDelete)
 
 	// Stop reconciling so we don't contend for iptables, and so we don't update the staleChains at the same time as reconcile()
 	pMgr.reconcileManager.forceLock()
 	defer pMgr.reconcileManager.forceUnlock()
 
-	// 1. Delete jump rules from ingress/egress chains to ingress/egress policy chains.
-	// We ought to delete these jump rules here in the foreground since if we add an NP back after deleting, iptables-restore --noflush can add duplicate jump rules.
-	deleteErr := pMgr.deleteOldJumpRulesOnRemove(networkPolicy)
-	if deleteErr != nil {
-		return fmt.Errorf("failed to delete jumps to policy chains. err: %w", deleteErr)
-	}
+	for _, family := range pMgr.families() {
+		// 1. Delete jump rules from ingress/egress chains to ingress/egress policy chains.
+		// We ought to delete these jump rules here in the foreground since if we add an NP back after deleting, iptables-restore --noflush can add duplicate jump rules.
+		deleteErr := pMgr.deleteOldJumpRulesOnRemove(networkPolicy, family)
+		if deleteErr != nil {
+			return fmt.Errorf("failed to delete jumps to policy chains. err: %w", deleteErr)
+		}
 
-	// 2. Flush the policy chains and deactivate NPM (if necessary).
-	timer := metrics.StartNewTimer()
-	restoreErr := restore(creator)
-	metrics.RecordIPTablesRestoreLatency(timer, metrics.DeleteOp)
-	if restoreErr != nil {
-		metrics.IncIPTablesRestoreFailures(metrics.DeleteOp)
-		return fmt.Errorf("failed to flush policies. err: %w", restoreErr)
+		// 2. Flush the policy chains and deactivate NPM (if necessary).
+		creator := pMgr.creatorForRemovingPolicies(chainsToDelete)
+		timer := metrics.StartNewTimer()
+		restoreErr := restore(creator, family)
+		metrics.RecordIPTablesRestoreLatency(timer, metrics.DeleteOp)
+		if restoreErr != nil {
+			metrics.IncIPTablesRestoreFailures(metrics.DeleteOp)
+			return fmt.Errorf("failed to flush %s policies. err: %w", family, restoreErr)
+		}
 	}
 
 	// 3. Delete policy chains in the background.
@@ -96,14 +114,65 @@ func (pMgr *PolicyManager) removePolicy(networkPolicy *NPMNetworkPolicy, _ map[s
 	return nil
 }
 
-func restore(creator *ioutil.FileCreator) error {
-	err := creator.RunCommandWithFile(util.IptablesRestore, util.IptablesWaitFlag, util.IptablesDefaultWaitTime, util.IptablesRestoreTableFlag, util.IptablesFilterTable, util.IptablesRestoreNoFlushFlag)
+// removeAdminTierPolicy rewrites the chains of the policy's tier without the policy.
+// Admin tier policies don't have their own chains, so there are no jumps to delete or chains to clean up.
+func (pMgr *PolicyManager) removeAdminTierPolicy(networkPolicy *NPMNetworkPolicy) error {
+	// Stop reconciling so we don't contend for iptables
+	pMgr.reconcileManager.forceLock()
+	defer pMgr.reconcileManager.forceUnlock()
+
+	for _, family := range pMgr.families() {
+		creator := pMgr.creatorForRemovingAdminTierPolicy(networkPolicy, family)
+		timer := metrics.StartNewTimer()
+		restoreErr := restore(creator, family)
+		metrics.RecordIPTablesRestoreLatency(timer, metrics.DeleteOp)
+		if restoreErr != nil {
+			metrics.IncIPTablesRestoreFailures(metrics.DeleteOp)
+			return fmt.Errorf("failed to rewrite %s %s tier chains. err: %w", family, networkPolicy.Tier, restoreErr)
+		}
+	}
+	return nil
+}
+
+func restore(creator *ioutil.FileCreator, family ipsets.IPFamily) error {
+	err := creator.RunCommandWithFile(iptablesRestoreCommand(family), util.IptablesWaitFlag, util.IptablesDefaultWaitTime, util.IptablesRestoreTableFlag, util.IptablesFilterTable, util.IptablesRestoreNoFlushFlag)
 	if err != nil {
 		return fmt.Errorf("failed to restore iptables file. err: %w", err)
 	}
 	return nil
 }
 
+// families returns the IP families which policies are enforced for.
+func (pMgr *PolicyManager) families() []ipsets.IPFamily {
+	if pMgr.EnableIPv6 {
+		return []ipsets.IPFamily{ipsets.IPv4, ipsets.IPv6}
+	}
+	return []ipsets.IPFamily{ipsets.IPv4}
+}
+
+// iptablesCommand returns the iptables binary for the family.
+// ip6tables uses the same backend (nft or legacy) as iptables.
+func iptablesCommand(family ipsets.IPFamily) string {
+	if family != ipsets.IPv6 {
+		return util.Iptables
+	}
+	if util.Iptables == util.IptablesNft {
+		return util.Ip6tablesNft
+	}
+	return util.Ip6tablesLegacy
+}
+
+// iptablesRestoreCommand returns the iptables-restore binary for the family.
+func iptablesRestoreCommand(family ipsets.IPFamily) string {
+	if family != ipsets.IPv6 {
+		return util.IptablesRestore
+	}
+	if util.IptablesRestore == util.IptablesRestoreNft {
+		return util.Ip6tablesRestoreNft
+	}
+	return util.Ip6tablesRestoreLegacy
+}
+
 // NOTE: if removing multiple policies, would need to add a isLastPolicy argument instead
 func (pMgr *PolicyManager) creatorForRemovingPolicies(allChainNames []string) *ioutil.FileCreator {
 	creator := pMgr.newCreatorWithChains(nil)
@@ -120,10 +189,28 @@ func (pMgr *PolicyManager) creatorForRemovingPolicies(allChainNames []string) *i
 	return creator
 }
 
-// returns ingress and egress chain names for the policies
+func (pMgr *PolicyManager) creatorForRemovingAdminTierPolicy(networkPolicy *NPMNetworkPolicy, family ipsets.IPFamily) *ioutil.FileCreator {
+	tiers := []PolicyTier{networkPolicy.Tier}
+	// the chain headers flush the tier chains
+	creator := pMgr.newCreatorWithChains(adminTierChainNames(tiers))
+	// 1. Deactivate NPM (if necessary).
+	if pMgr.isLastPolicy() {
+		creator.AddLine("", nil, util.IptablesFlushFlag, util.IptablesAzureChain)
+	}
+
+	// 2. Rewrite the tier chains.
+	pMgr.writeAdminTierRules(creator, tiers, pMgr.cachedPoliciesWithout(networkPolicy.PolicyKey), family)
+	creator.AddLine("", nil, util.IptablesRestoreCommit)
+	return creator
+}
+
+// returns ingress and egress chain names for the policies. Admin tier policies don't have their own chains.
 func chainNames(networkPolicies []*NPMNetworkPolicy) []string {
 	chainNames := make([]string, 0)
 	for _, networkPolicy := range networkPolicies {
+		if networkPolicy.isAdminTier() {
+			continue
+		}
 		hasIngress, hasEgress := networkPolicy.hasIngressAndEgress()
 
 		if hasIngress {
@@ -151,38 +238,38 @@ func (pMgr *PolicyManager) newCreatorWithChains(chainNames []string) *ioutil.Fil
 }
 
 // will make a similar func for on update eventually
-func (pMgr *PolicyManager) deleteOldJumpRulesOnRemove(policy *NPMNetworkPolicy) error {
+func (pMgr *PolicyManager) deleteOldJumpRulesOnRemove(policy *NPMNetworkPolicy, family ipsets.IPFamily) error {
 	shouldDeleteIngress, shouldDeleteEgress := policy.hasIngressAndEgress()
 	if shouldDeleteIngress {
-		if err := pMgr.deleteJumpRule(policy, true); err != nil {
+		if err := pMgr.deleteJumpRule(policy, true, family); err != nil {
 			return err
 		}
 	}
 	if shouldDeleteEgress {
-		if err := pMgr.deleteJumpRule(policy, false); err != nil {
+		if err := pMgr.deleteJumpRule(policy, false, family); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (pMgr *PolicyManager) deleteJumpRule(policy *NPMNetworkPolicy, direction UniqueDirection) error {
+func (pMgr *PolicyManager) deleteJumpRule(policy *NPMNetworkPolicy, direction UniqueDirection, family ipsets.IPFamily) error {
 	var specs []string
 	var baseChainName string
 	var chainName string
 	if direction == forIngress {
-		specs = ingressJumpSpecs(policy)
+		specs = ingressJumpSpecs(policy, family)
 		baseChainName = util.IptablesAzureIngressChain
 		chainName = policy.ingressChainName()
 	} else {
-		specs = egressJumpSpecs(policy)
+		specs = egressJumpSpecs(policy, family)
 		baseChainName = util.IptablesAzureEgressChain
 		chainName = policy.egressChainName()
 	}
 
 	specs = append([]string{baseChainName}, specs...)
 	timer := metrics.StartNewTimer()
-	errCode, err := pMgr.runIPTablesCommand(util.IptablesDeletionFlag, specs...)
+	errCode, err := pMgr.runIPTablesCommandForFamily(family, util.IptablesDeletionFlag, specs...)
 	metrics.RecordIPTablesDeleteLatency(timer)
 	// if this actually happens (don't think it should), could use ignoreErrorsAndRunIPTablesCommand instead with: "Bad rule (does a matching rule exist in that chain?)"
 	if err != nil && errCode != doesNotExistErrorCode && errCode != couldntLoadTargetErrorCode {
@@ -193,24 +280,29 @@ func (pMgr *PolicyManager) deleteJumpRule(policy *NPMNetworkPolicy, direction Un
 	return nil
 }
 
-func ingressJumpSpecs(networkPolicy *NPMNetworkPolicy) []string {
+func ingressJumpSpecs(networkPolicy *NPMNetworkPolicy, family ipsets.IPFamily) []string {
 	chainName := networkPolicy.ingressChainName()
 	specs := []string{util.IptablesJumpFlag, chainName}
-	specs = append(specs, matchSetSpecsForNetworkPolicy(networkPolicy, DstMatch)...)
+	specs = append(specs, matchSetSpecsForNetworkPolicy(networkPolicy, DstMatch, family)...)
 	specs = append(specs, commentSpecs(networkPolicy.commentForJumpToIngress())...)
 	return specs
 }
 
-func egressJumpSpecs(networkPolicy *NPMNetworkPolicy) []string {
+func egressJumpSpecs(networkPolicy *NPMNetworkPolicy, family ipsets.IPFamily) []string {
 	chainName := networkPolicy.egressChainName()
 	specs := []string{util.IptablesJumpFlag, chainName}
-	specs = append(specs, matchSetSpecsForNetworkPolicy(networkPolicy, SrcMatch)...)
+	specs = append(specs, matchSetSpecsForNetworkPolicy(networkPolicy, SrcMatch, family)...)
 	specs = append(specs, commentSpecs(networkPolicy.commentForJumpToEgress())...)
 	return specs
 }
 
-func (pMgr *PolicyManager) creatorForNewNetworkPolicies(policyChains []string, networkPolicies []*NPMNetworkPolicy) *ioutil.FileCreator {
-	creator := pMgr.newCreatorWithChains(policyChains)
+func (pMgr *PolicyManager) creatorForNewNetworkPolicies(policyChains []string, networkPolicies []*NPMNetworkPolicy, family ipsets.IPFamily) *ioutil.FileCreator {
+	tiers := adminTiers(networkPolicies)
+	// the chain headers flush the admin tier chains, which are rewritten below
+	allChains := make([]string, 0, len(policyChains)+2*len(tiers))
+	allChains = append(allChains, policyChains...)
+	allChains = append(allChains, adminTierChainNames(tiers)...)
+	creator := pMgr.newCreatorWithChains(allChains)
 
 	// 1. Activate NPM if necessary
 	if pMgr.isFirstPolicy() {
@@ -221,32 +313,147 @@ func (pMgr *PolicyManager) creatorForNewNetworkPolicies(policyChains []string, n
 	}
 
 	// 2. Add all rules for the network policies
-	ingressJumpLineNumber := 1
-	egressJumpLineNumber := 1
+	ingressJumpLineNumber := pMgr.firstPolicyJumpLineNumber()
+	egressJumpLineNumber := pMgr.firstPolicyJumpLineNumber()
 	for _, networkPolicy := range networkPolicies {
+		if networkPolicy.isAdminTier() {
+			continue
+		}
+
 		// 2.1 add all rules for the policy chain(s)
-		writeNetworkPolicyRules(creator, networkPolicy)
+		pMgr.writeNetworkPolicyRules(creator, networkPolicy, family)
 
 		// 2.2 add jump rule(s) to the policy chain(s)
 		hasIngress, hasEgress := networkPolicy.hasIngressAndEgress()
 		if hasIngress {
-			ingressJumpSpecs := insertSpecs(util.IptablesAzureIngressChain, ingressJumpLineNumber, ingressJumpSpecs(networkPolicy))
+			ingressJumpSpecs := insertSpecs(util.IptablesAzureIngressChain, ingressJumpLineNumber, ingressJumpSpecs(networkPolicy, family))
 			creator.AddLine("", nil, ingressJumpSpecs...) // TODO error handler
 			ingressJumpLineNumber++
 		}
 		if hasEgress {
-			egressJumpSpecs := insertSpecs(util.IptablesAzureEgressChain, egressJumpLineNumber, egressJumpSpecs(networkPolicy))
+			egressJumpSpecs := insertSpecs(util.IptablesAzureEgressChain, egressJumpLineNumber, egressJumpSpecs(networkPolicy, family))
 			creator.AddLine("", nil, egressJumpSpecs...) // TODO error handler
 			egressJumpLineNumber++
 		}
 	}
+
+	// 3. Rewrite the admin tier chains with the new and existing admin tier policies
+	pMgr.writeAdminTierRules(creator, tiers, pMgr.cachedPoliciesWith(networkPolicies), family)
 	creator.AddLine("", nil, util.IptablesRestoreCommit)
 	return creator
 }
 
+// firstPolicyJumpLineNumber returns the position of the first jump to a policy chain in AZURE-NPM-INGRESS and AZURE-NPM-EGRESS.
+// The jump to the AdminNetworkPolicy chain must come before the jumps to policy chains.
+func (pMgr *PolicyManager) firstPolicyJumpLineNumber() int {
+	if pMgr.EnableAdminNetworkPolicy {
+		return 2
+	}
+	return 1
+}
+
+/*
+writeAdminTierRules writes the rules for the admin tier chains of the given tiers.
+Admin tier ACLs are written in order of policy priority (then policy key) and ACL order,
+directly into the tier chains instead of into policy chains, since the first matching ACL decides the flow:
+- Allowed ACLs jump to AZURE-NPM-INGRESS-ALLOW-MARK (ingress) or AZURE-NPM-ACCEPT (egress) like NetworkPolicies.
+- Dropped ACLs drop the packet (after logging it if drop logging is enabled).
+- Passed ACLs return from the AdminNetworkPolicy chain, skipping the remaining AdminNetworkPolicy ACLs.
+*/
+func (pMgr *PolicyManager) writeAdminTierRules(creator *ioutil.FileCreator, tiers []PolicyTier, allPolicies map[string]*NPMNetworkPolicy, family ipsets.IPFamily) {
+	for _, tier := range tiers {
+		for _, networkPolicy := range sortedTierPolicies(allPolicies, tier) {
+			for aclID, aclPolicy := range networkPolicy.ACLs {
+				chainName := adminTierChainName(tier, UniqueDirection(aclPolicy.hasIngress()))
+				if pMgr.EnableDropLogging && aclPolicy.Target == Dropped {
+					creator.AddLine("", nil, nflogRuleSpecs(chainName, networkPolicy.dropLogTag(aclID).String(), iptablesMatchSpecs(aclPolicy, family))...)
+				}
+				line := []string{util.IptablesAppendFlag, chainName}
+				line = append(line, adminTierActionSpecs(aclPolicy)...)
+				line = append(line, iptablesMatchSpecs(aclPolicy, family)...)
+				line = append(line, commentSpecs(networkPolicy.commentForAdminTierACL(aclPolicy))...)
+				creator.AddLine("", nil, line...)
+			}
+		}
+	}
+}
+
+func adminTierActionSpecs(aclPolicy *ACLPolicy) []string {
+	switch aclPolicy.Target {
+	case Allowed:
+		if aclPolicy.hasIngress() {
+			return []string{util.IptablesJumpFlag, util.IptablesAzureIngressAllowMarkChain}
+		}
+		return []string{util.IptablesJumpFlag, util.IptablesAzureAcceptChain}
+	case Passed:
+		return []string{util.IptablesJumpFlag, util.IptablesReturn}
+	default:
+		return []string{util.IptablesJumpFlag, util.IptablesDrop}
+	}
+}
+
+// adminTiers returns the admin tiers of the policies in the order they're evaluated.
+func adminTiers(networkPolicies []*NPMNetworkPolicy) []PolicyTier {
+	hasTier := make(map[PolicyTier]bool)
+	for _, networkPolicy := range networkPolicies {
+		hasTier[networkPolicy.Tier] = true
+	}
+	tiers := make([]PolicyTier, 0, 2)
+	for _, tier := range []PolicyTier{AdminTier, BaselineTier} {
+		if hasTier[tier] {
+			tiers = append(tiers, tier)
+		}
+	}
+	return tiers
+}
+
+func adminTierChainName(tier PolicyTier, direction UniqueDirection) string {
+	if tier == AdminTier {
+		if direction == forIngress {
+			return util.IptablesAzureAdminIngressChain
+		}
+		return util.IptablesAzureAdminEgressChain
+	}
+	if direction == forIngress {
+		return util.IptablesAzureBaselineIngressChain
+	}
+	return util.IptablesAzureBaselineEgressChain
+}
+
+func adminTierChainNames(tiers []PolicyTier) []string {
+	chains := make([]string, 0, 2*len(tiers))
+	for _, tier := range tiers {
+		chains = append(chains, adminTierChainName(tier, forIngress), adminTierChainName(tier, forEgress))
+	}
+	return chains
+}
+
+// cachedPoliciesWith returns the cached policies and the given policies.
+func (pMgr *PolicyManager) cachedPoliciesWith(networkPolicies []*NPMNetworkPolicy) map[string]*NPMNetworkPolicy {
+	allPolicies := make(map[string]*NPMNetworkPolicy, len(pMgr.policyMap.cache)+len(networkPolicies))
+	for key, networkPolicy := range pMgr.policyMap.cache {
+		allPolicies[key] = networkPolicy
+	}
+	for _, networkPolicy := range networkPolicies {
+		allPolicies[networkPolicy.PolicyKey] = networkPolicy
+	}
+	return allPolicies
+}
+
+// cachedPoliciesWithout returns the cached policies except the policy with the given key.
+func (pMgr *PolicyManager) cachedPoliciesWithout(policyKey string) map[string]*NPMNetworkPolicy {
+	remainingPolicies := make(map[string]*NPMNetworkPolicy, len(pMgr.policyMap.cache))
+	for key, networkPolicy := range pMgr.policyMap.cache {
+		if key != policyKey {
+			remainingPolicies[key] = networkPolicy
+		}
+	}
+	return remainingPolicies
+}
+
 // write rules for the policy chain(s)
-func writeNetworkPolicyRules(creator *ioutil.FileCreator, networkPolicy *NPMNetworkPolicy) {
-	for _, aclPolicy := range networkPolicy.ACLs {
+func (pMgr *PolicyManager) writeNetworkPolicyRules(creator *ioutil.FileCreator, networkPolicy *NPMNetworkPolicy, family ipsets.IPFamily) {
+	for aclID, aclPolicy := range networkPolicy.ACLs {
 		var chainName string
 		var actionSpecs []string
 		if aclPolicy.hasIngress() {
@@ -254,32 +461,65 @@ func writeNetworkPolicyRules(creator *ioutil.FileCreator, networkPolicy *NPMNetw
 			if aclPolicy.Target == Allowed {
 				actionSpecs = []string{util.IptablesJumpFlag, util.IptablesAzureIngressAllowMarkChain}
 			} else {
-				actionSpecs = setMarkSpecs(util.IptablesAzureIngressDropMarkHex)
+				actionSpecs = setMarkSpecs(pMgr.dropMarkHex(networkPolicy, aclID, util.IptablesAzureIngressDropMarkHex, util.AzureIngressDropMark))
 			}
 		} else {
 			chainName = networkPolicy.egressChainName()
 			if aclPolicy.Target == Allowed {
 				actionSpecs = []string{util.IptablesJumpFlag, util.IptablesAzureAcceptChain}
 			} else {
-				actionSpecs = setMarkSpecs(util.IptablesAzureEgressDropMarkHex)
+				actionSpecs = setMarkSpecs(pMgr.dropMarkHex(networkPolicy, aclID, util.IptablesAzureEgressDropMarkHex, util.AzureEgressDropMark))
 			}
 		}
 		line := []string{"-A", chainName}
 		line = append(line, actionSpecs...)
-		line = append(line, iptablesRuleSpecs(aclPolicy)...)
+		line = append(line, iptablesRuleSpecs(aclPolicy, family)...)
 		creator.AddLine("", nil, line...) // TODO add error handler
 	}
 }
 
-func iptablesRuleSpecs(aclPolicy *ACLPolicy) []string {
+// dropMarkHex returns the mark which an ACL of a NetworkPolicy sets to drop packets.
+// With drop logging, the ACL also sets its ID in the mark, so that the DROP-ON-MARK rule can log the ACL which marked the packet.
+// The packet isn't logged when the ACL marks it, since another policy may still allow it.
+func (pMgr *PolicyManager) dropMarkHex(networkPolicy *NPMNetworkPolicy, aclID int, markHex string, mark uint32) string {
+	if !pMgr.EnableDropLogging {
+		return markHex
+	}
+	value, mask := droplog.Mark(pMgr.dropLogMarkIDs.Assign(networkPolicy.dropLogTag(aclID)), mark)
+	return fmt.Sprintf("0x%x/0x%x", value, mask)
+}
+
+// nflogRuleSpecs returns the rule which logs packets with the matchSpecs to the drop log NFLOG group, with the prefix.
+// The limit match is last so that only matching packets count towards the rate limit.
+func nflogRuleSpecs(chainName, prefix string, matchSpecs []string) []string {
+	specs := []string{
+		util.IptablesAppendFlag, chainName,
+		util.IptablesJumpFlag, util.IptablesNFLog,
+		util.IptablesNFLogGroupFlag, fmt.Sprint(droplog.Group),
+		util.IptablesNFLogPrefixFlag, prefix,
+	}
+	specs = append(specs, matchSpecs...)
+	return append(specs,
+		util.IptablesModuleFlag, util.IptablesLimitModuleFlag,
+		util.IptablesLimitFlag, droplog.RateLimit,
+		util.IptablesLimitBurstFlag, fmt.Sprint(droplog.RateLimitBurst),
+	)
+}
+
+func iptablesRuleSpecs(aclPolicy *ACLPolicy, family ipsets.IPFamily) []string {
+	specs := iptablesMatchSpecs(aclPolicy, family)
+	specs = append(specs, commentSpecs(aclPolicy.comment())...)
+	return specs
+}
+
+func iptablesMatchSpecs(aclPolicy *ACLPolicy, family ipsets.IPFamily) []string {
 	specs := make([]string, 0)
 	if aclPolicy.Protocol != UnspecifiedProtocol {
 		specs = append(specs, util.IptablesProtFlag, string(aclPolicy.Protocol))
 	}
 	specs = append(specs, dstPortSpecs(aclPolicy.DstPorts)...)
-	specs = append(specs, matchSetSpecsFromSetInfo(aclPolicy.SrcList)...)
-	specs = append(specs, matchSetSpecsFromSetInfo(aclPolicy.DstList)...)
-	specs = append(specs, commentSpecs(aclPolicy.comment())...)
+	specs = append(specs, matchSetSpecsFromSetInfo(aclPolicy.SrcList, family)...)
+	specs = append(specs, matchSetSpecsFromSetInfo(aclPolicy.DstList, family)...)
 	return specs
 }
 
@@ -290,20 +530,20 @@ func dstPortSpecs(portRange Ports) []string {
 	return []string{util.IptablesDstPortFlag, portRange.toIPTablesString()}
 }
 
-func matchSetSpecsForNetworkPolicy(networkPolicy *NPMNetworkPolicy, matchType MatchType) []string {
+func matchSetSpecsForNetworkPolicy(networkPolicy *NPMNetworkPolicy, matchType MatchType, family ipsets.IPFamily) []string {
 	specs := make([]string, 0, maxLengthForMatchSetSpecs*len(networkPolicy.PodSelectorList))
 	matchString := matchType.toIPTablesString()
 	for _, setInfo := range networkPolicy.PodSelectorList {
-		specs = append(specs, setInfo.matchSetSpecs(matchString)...)
+		specs = append(specs, setInfo.matchSetSpecs(matchString, family)...)
 	}
 	return specs
 }
 
-func matchSetSpecsFromSetInfo(setInfoList []SetInfo) []string {
+func matchSetSpecsFromSetInfo(setInfoList []SetInfo, family ipsets.IPFamily) []string {
 	specs := make([]string, 0, maxLengthForMatchSetSpecs*len(setInfoList))
 	for _, setInfo := range setInfoList {
 		matchString := setInfo.MatchType.toIPTablesString()
-		specs = append(specs, setInfo.matchSetSpecs(matchString)...)
+		specs = append(specs, setInfo.matchSetSpecs(matchString, family)...)
 	}
 	return specs
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Azure/azure-container-networking/common"
 	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
 	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
 	dptestutils "github.com/Azure/azure-container-networking/npm/pkg/dataplane/testutils"
 	"github.com/Azure/azure-container-networking/npm/util"
@@ -197,6 +198,12 @@ var (
 
 var allTestNetworkPolicies = []*NPMNetworkPolicy{bothDirectionsNetPol, ingressNetPol, egressNetPol}
 
+var ipv6Config = &PolicyManagerCfg{
+	PolicyMode:           IPSetPolicyMode,
+	PlaceAzureChainFirst: util.PlaceAzureChainFirst,
+	EnableIPv6:           true,
+}
+
 func TestChainNames(t *testing.T) {
 	expectedName := fmt.Sprintf("AZURE-NPM-INGRESS-%s", util.Hash(bothDirectionsNetPol.PolicyKey))
 	require.Equal(t, expectedName, bothDirectionsNetPol.ingressChainName())
@@ -227,7 +234,7 @@ func TestCreatorForAddPolicies(t *testing.T) {
 
 	// 1. test with activation
 	policies := []*NPMNetworkPolicy{allTestNetworkPolicies[0]}
-	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies)
+	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv4)
 	actualLines := strings.Split(creator.ToString(), "\n")
 	expectedLines := []string{
 		"*filter",
@@ -254,7 +261,7 @@ func TestCreatorForAddPolicies(t *testing.T) {
 	// 2. test without activation
 	// add a policy to the cache so that we don't activate (the cache doesn't impact creatorForNewNetworkPolicies)
 	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{allTestNetworkPolicies[0]}, nil))
-	creator = pMgr.creatorForNewNetworkPolicies(chainNames(allTestNetworkPolicies), allTestNetworkPolicies)
+	creator = pMgr.creatorForNewNetworkPolicies(chainNames(allTestNetworkPolicies), allTestNetworkPolicies, ipsets.IPv4)
 	actualLines = strings.Split(creator.ToString(), "\n")
 	expectedLines = []string{
 		"*filter",
@@ -282,6 +289,79 @@ func TestCreatorForAddPolicies(t *testing.T) {
 	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
 }
 
+func TestCreatorForAddPoliciesIPv6(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, ipv6Config)
+
+	policies := []*NPMNetworkPolicy{ingressNetPol}
+	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv6)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		fmt.Sprintf(":%s - -", ingressNetPolChain),
+		"-F AZURE-NPM",
+		"-A AZURE-NPM -j AZURE-NPM-INGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-EGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-ACCEPT",
+		fmt.Sprintf(
+			"-A %s -j MARK --set-mark %s -p TCP --dport 222:333 -m set --match-set %s-v6 src -m set ! --match-set %s-v6 dst -m comment --comment %s",
+			ingressNetPolChain,
+			util.IptablesAzureIngressDropMarkHex,
+			ipsets.TestCIDRSet.HashedName,
+			ipsets.TestKeyPodSet.HashedName,
+			ingressDropComment,
+		),
+		fmt.Sprintf(
+			"-I AZURE-NPM-INGRESS 1 -j %s -m set --match-set %s-v6 dst -m set --match-set %s-v6 dst -m comment --comment %s",
+			ingressNetPolChain,
+			ipsets.TestKeyPodSet.HashedName,
+			ipsets.TestNSSet.HashedName,
+			ingressNetPolJumpComment,
+		),
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestAddAndRemovePolicyWithIPv6(t *testing.T) {
+	metrics.ReinitializeAll()
+	ipv6IngressJump := fmt.Sprintf(
+		"-j %s -m set --match-set %s-v6 dst -m comment --comment %s",
+		bothDirectionsNetPolIngressChain,
+		ipsets.TestKeyPodSet.HashedName,
+		bothDirectionsNetPolIngressJumpComment,
+	)
+	ipv6EgressJump := fmt.Sprintf(
+		"-j %s -m set --match-set %s-v6 src -m comment --comment %s",
+		bothDirectionsNetPolEgressChain,
+		ipsets.TestKeyPodSet.HashedName,
+		bothDirectionsNetPolEgressJumpComment,
+	)
+	ipv6DeleteIngressJump := getFakeDeleteJumpCommand("AZURE-NPM-INGRESS", ipv6IngressJump)
+	ipv6DeleteIngressJump.Cmd[0] = "ip6tables"
+	ipv6DeleteEgressJump := getFakeDeleteJumpCommand("AZURE-NPM-EGRESS", ipv6EgressJump)
+	ipv6DeleteEgressJump.Cmd[0] = "ip6tables"
+	calls := []testutils.TestCmd{
+		fakeIPTablesRestoreCommand,
+		fakeIP6TablesRestoreCommand,
+		getFakeDeleteJumpCommand("AZURE-NPM-INGRESS", ingressEgressNetPolIngressJump),
+		getFakeDeleteJumpCommand("AZURE-NPM-EGRESS", ingressEgressNetPolEgressJump),
+		fakeIPTablesRestoreCommand,
+		ipv6DeleteIngressJump,
+		ipv6DeleteEgressJump,
+		fakeIP6TablesRestoreCommand,
+	}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, ipv6Config)
+
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol}, nil))
+	require.NoError(t, pMgr.RemovePolicy(bothDirectionsNetPol.PolicyKey))
+	assertStaleChainsContain(t, pMgr.staleChains, bothDirectionsNetPolIngressChain, bothDirectionsNetPolEgressChain)
+}
+
 func TestCreatorForRemovePolicies(t *testing.T) {
 	calls := []testutils.TestCmd{fakeIPTablesRestoreCommand}
 	ioshim := common.NewMockIOShim(calls)
@@ -435,3 +515,294 @@ func TestUpdatingStaleChains(t *testing.T) {
 	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol}, nil))
 	assertStaleChainsContain(t, pMgr.staleChains, egressNetPolChain)
 }
+
+// admin tier policies
+var (
+	adminTierConfig = &PolicyManagerCfg{
+		PolicyMode:               IPSetPolicyMode,
+		PlaceAzureChainFirst:     util.PlaceAzureChainFirst,
+		EnableAdminNetworkPolicy: true,
+	}
+
+	anpPassNetPol = adminTierNetPol(AdminTier, "pass", 10, &ACLPolicy{
+		SrcList: []SetInfo{
+			{
+				ipsets.TestNSSet.Metadata,
+				true,
+				SrcMatch,
+			},
+		},
+		Target:    Passed,
+		Direction: Ingress,
+		Protocol:  UnspecifiedProtocol,
+	})
+	anpDenyNetPol   = adminTierNetPol(AdminTier, "deny", 20, ingressAllowedACL, egressDeniedACL)
+	banpAllowNetPol = adminTierNetPol(BaselineTier, "default", 0, egressAllowedACL)
+)
+
+// iptables rule variables for admin tier policies
+var (
+	anpPassRule = fmt.Sprintf("-A AZURE-NPM-ADMIN-INGRESS -j RETURN -m set --match-set %s src -m comment --comment AdminNetworkPolicy/pass-PASS-FROM-ns-test-ns-set",
+		ipsets.TestNSSet.HashedName,
+	)
+	anpDenyIngressRule = fmt.Sprintf("-A AZURE-NPM-ADMIN-INGRESS -j AZURE-NPM-INGRESS-ALLOW-MARK -m set --match-set %s src -m comment --comment AdminNetworkPolicy/deny-%s",
+		ipsets.TestCIDRSet.HashedName,
+		ingressAllowComment,
+	)
+	anpDenyEgressRule = fmt.Sprintf("-A AZURE-NPM-ADMIN-EGRESS -j DROP -p UDP --dport 144 -m set --match-set %s dst -m comment --comment AdminNetworkPolicy/deny-%s",
+		ipsets.TestCIDRSet.HashedName,
+		egressDropComment,
+	)
+	banpAllowRule = fmt.Sprintf("-A AZURE-NPM-BASELINE-EGRESS -j AZURE-NPM-ACCEPT -m set --match-set %s dst -m comment --comment BaselineAdminNetworkPolicy/default-%s",
+		ipsets.TestNamedportSet.HashedName,
+		egressAllowComment,
+	)
+)
+
+func TestCreatorForAddAdminTierPolicies(t *testing.T) {
+	calls := []testutils.TestCmd{fakeIPTablesRestoreCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, adminTierConfig)
+
+	// 1. test with activation. NetworkPolicy jumps come after the jump to the AdminNetworkPolicy chain
+	policies := []*NPMNetworkPolicy{anpDenyNetPol, bothDirectionsNetPol}
+	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv4)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		// all chains
+		fmt.Sprintf(":%s - -", bothDirectionsNetPolIngressChain),
+		fmt.Sprintf(":%s - -", bothDirectionsNetPolEgressChain),
+		":AZURE-NPM-ADMIN-INGRESS - -",
+		":AZURE-NPM-ADMIN-EGRESS - -",
+		"-F AZURE-NPM",
+		// activation rules for AZURE-NPM chain
+		"-A AZURE-NPM -j AZURE-NPM-INGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-EGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-ACCEPT",
+		// NetworkPolicy
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolIngressChain, ingressDropRule),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolIngressChain, ingressAllowRule),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressDropRule),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressAllowRule),
+		fmt.Sprintf("-I AZURE-NPM-INGRESS 2 %s", ingressEgressNetPolIngressJump),
+		fmt.Sprintf("-I AZURE-NPM-EGRESS 2 %s", ingressEgressNetPolEgressJump),
+		// AdminNetworkPolicy
+		anpDenyIngressRule,
+		anpDenyEgressRule,
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// 2. test without activation. the tier chains are rewritten with existing policies ordered by priority
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{anpDenyNetPol}, nil))
+	policies = []*NPMNetworkPolicy{anpPassNetPol, banpAllowNetPol}
+	creator = pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv4)
+	actualLines = strings.Split(creator.ToString(), "\n")
+	expectedLines = []string{
+		"*filter",
+		":AZURE-NPM-ADMIN-INGRESS - -",
+		":AZURE-NPM-ADMIN-EGRESS - -",
+		":AZURE-NPM-BASELINE-INGRESS - -",
+		":AZURE-NPM-BASELINE-EGRESS - -",
+		anpPassRule,
+		anpDenyIngressRule,
+		anpDenyEgressRule,
+		banpAllowRule,
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestCreatorForRemoveAdminTierPolicy(t *testing.T) {
+	calls := []testutils.TestCmd{fakeIPTablesRestoreCommand, fakeIPTablesRestoreCommand, fakeIPTablesRestoreCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, adminTierConfig)
+
+	// 1. test without deactivation. only the policy's tier chains are rewritten
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{anpPassNetPol, anpDenyNetPol, banpAllowNetPol}, nil))
+	creator := pMgr.creatorForRemovingAdminTierPolicy(anpDenyNetPol, ipsets.IPv4)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		":AZURE-NPM-ADMIN-INGRESS - -",
+		":AZURE-NPM-ADMIN-EGRESS - -",
+		anpPassRule,
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// 2. test with deactivation
+	require.NoError(t, pMgr.RemovePolicy(anpPassNetPol.PolicyKey))
+	require.NoError(t, pMgr.RemovePolicy(anpDenyNetPol.PolicyKey))
+	creator = pMgr.creatorForRemovingAdminTierPolicy(banpAllowNetPol, ipsets.IPv4)
+	actualLines = strings.Split(creator.ToString(), "\n")
+	expectedLines = []string{
+		"*filter",
+		":AZURE-NPM-BASELINE-INGRESS - -",
+		":AZURE-NPM-BASELINE-EGRESS - -",
+		"-F AZURE-NPM",
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// admin tier policies don't have chains to clean up
+	assertStaleChainsContain(t, pMgr.staleChains)
+}
+
+var dropLoggingConfig = &PolicyManagerCfg{
+	PolicyMode:               IPSetPolicyMode,
+	PlaceAzureChainFirst:     util.PlaceAzureChainFirst,
+	EnableAdminNetworkPolicy: true,
+	EnableDropLogging:        true,
+}
+
+func TestCreatorForAddPoliciesWithDropLogging(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, dropLoggingConfig)
+
+	anpHash := util.Hash(anpDenyNetPol.PolicyKey)
+	policies := []*NPMNetworkPolicy{bothDirectionsNetPol, anpDenyNetPol}
+	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv4)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := []string{
+		"*filter",
+		fmt.Sprintf(":%s - -", bothDirectionsNetPolIngressChain),
+		fmt.Sprintf(":%s - -", bothDirectionsNetPolEgressChain),
+		":AZURE-NPM-ADMIN-INGRESS - -",
+		":AZURE-NPM-ADMIN-EGRESS - -",
+		"-F AZURE-NPM",
+		"-A AZURE-NPM -j AZURE-NPM-INGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-EGRESS",
+		"-A AZURE-NPM -j AZURE-NPM-ACCEPT",
+		// NetworkPolicy: no log rules, the ACLs which mark for drop set their ID in the mark
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolIngressChain, strings.Replace(ingressDropRule, util.IptablesAzureIngressDropMarkHex, "0x10400/0xffff0400", 1)),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolIngressChain, ingressAllowRule),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, strings.Replace(egressDropRule, util.IptablesAzureEgressDropMarkHex, "0x20800/0xffff0800", 1)),
+		fmt.Sprintf("-A %s %s", bothDirectionsNetPolEgressChain, egressAllowRule),
+		fmt.Sprintf("-I AZURE-NPM-INGRESS 2 %s", ingressEgressNetPolIngressJump),
+		fmt.Sprintf("-I AZURE-NPM-EGRESS 2 %s", ingressEgressNetPolEgressJump),
+		// AdminNetworkPolicy: a log rule before each ACL which drops
+		anpDenyIngressRule,
+		fmt.Sprintf(
+			"-A AZURE-NPM-ADMIN-EGRESS -j NFLOG --nflog-group 100 --nflog-prefix NPM-DROP:E:%s:1 -p UDP --dport 144 -m set --match-set %s dst -m limit --limit 10/second --limit-burst 20",
+			anpHash,
+			ipsets.TestCIDRSet.HashedName,
+		),
+		anpDenyEgressRule,
+		"COMMIT",
+		"",
+	}
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestResolveDropLogTag(t *testing.T) {
+	calls := []testutils.TestCmd{fakeIPTablesRestoreCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, dropLoggingConfig)
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol, anpDenyNetPol}, nil))
+
+	policyHash := util.Hash(bothDirectionsNetPol.PolicyKey)
+	attribution, ok := pMgr.ResolveDropLogTag(droplog.Tag{Direction: droplog.Egress, PolicyHash: policyHash, ACLID: 2})
+	require.True(t, ok)
+	require.Equal(t, droplog.Attribution{
+		PolicyKey: bothDirectionsNetPol.PolicyKey,
+		Namespace: "x",
+		Direction: droplog.Egress,
+		ACLID:     2,
+		Chain:     bothDirectionsNetPolEgressChain,
+		Comment:   egressDropComment,
+	}, attribution)
+
+	attribution, ok = pMgr.ResolveDropLogTag(droplog.Tag{Direction: droplog.Egress, PolicyHash: util.Hash(anpDenyNetPol.PolicyKey), ACLID: 1})
+	require.True(t, ok)
+	require.Equal(t, droplog.Attribution{
+		PolicyKey: anpDenyNetPol.PolicyKey,
+		Direction: droplog.Egress,
+		ACLID:     1,
+		Chain:     "AZURE-NPM-ADMIN-EGRESS",
+		Comment:   "AdminNetworkPolicy/deny-" + egressDropComment,
+	}, attribution)
+
+	// ACL out of range
+	_, ok = pMgr.ResolveDropLogTag(droplog.Tag{Direction: droplog.Egress, PolicyHash: policyHash, ACLID: 4})
+	require.False(t, ok)
+	// unknown policy
+	_, ok = pMgr.ResolveDropLogTag(droplog.Tag{Direction: droplog.Ingress, PolicyHash: util.Hash("x/removed"), ACLID: 0})
+	require.False(t, ok)
+}
+
+// A packet marked for drop by one policy is still allowed by another policy, so it must not be logged when it's marked.
+// It's only logged by the DROP-ON-MARK rule, which allowed packets don't reach, with the ID of the ACL in the mark.
+func TestDropLoggingWithAllowingPolicy(t *testing.T) {
+	calls := []testutils.TestCmd{
+		fakeIPTablesRestoreCommand,
+		getFakeDeleteJumpCommand("AZURE-NPM-INGRESS", ingressNetPolJump),
+		fakeIPTablesRestoreCommand,
+	}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, dropLoggingConfig)
+
+	allowNetPol := &NPMNetworkPolicy{
+		Namespace:         ingressNetPol.Namespace,
+		PolicyKey:         "y/allow",
+		ACLPolicyID:       "azure-acl-y-allow",
+		PodSelectorIPSets: ingressNetPol.PodSelectorIPSets,
+		PodSelectorList:   ingressNetPol.PodSelectorList,
+		ACLs:              []*ACLPolicy{ingressAllowedACL},
+	}
+	policies := []*NPMNetworkPolicy{ingressNetPol, allowNetPol}
+	require.NoError(t, pMgr.AddPolicies(policies, nil))
+
+	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv4)
+	lines := strings.Split(creator.ToString(), "\n")
+	require.Contains(t, lines, fmt.Sprintf("-A %s %s", ingressNetPolChain, strings.Replace(ingressDropRule, util.IptablesAzureIngressDropMarkHex, "0x10400/0xffff0400", 1)))
+	require.Contains(t, lines, fmt.Sprintf("-A %s %s", allowNetPol.ingressChainName(), ingressAllowRule))
+	for _, line := range lines {
+		require.NotContains(t, line, util.IptablesNFLog, "NetworkPolicy rules must not log packets which another policy may allow")
+	}
+
+	// the packet dropped by the DROP-ON-MARK rule is attributed to the ACL which marked it
+	tag, ok := pMgr.DropLogMarkTag(0x10400)
+	require.True(t, ok)
+	attribution, ok := pMgr.ResolveDropLogTag(tag)
+	require.True(t, ok)
+	require.Equal(t, droplog.Attribution{
+		PolicyKey: ingressNetPol.PolicyKey,
+		Namespace: "y",
+		Direction: droplog.Ingress,
+		ACLID:     0,
+		Chain:     ingressNetPolChain,
+		Comment:   ingressDropComment,
+	}, attribution)
+
+	// the ID is released with the policy
+	require.NoError(t, pMgr.RemovePolicy(ingressNetPol.PolicyKey))
+	_, ok = pMgr.DropLogMarkTag(0x10400)
+	require.False(t, ok)
+}
+
+// When all IDs are in use, an ACL which marks for drop clears the ID bits, so that an ID left in the mark by another ACL
+// doesn't attribute the drop to it.
+func TestDropLoggingWithoutMarkID(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, dropLoggingConfig)
+	for i := 0; i < int(droplog.MarkIDMask>>16); i++ {
+		require.NotZero(t, pMgr.dropLogMarkIDs.Assign(droplog.Tag{Direction: droplog.Egress, PolicyHash: "other", ACLID: i}))
+	}
+
+	policies := []*NPMNetworkPolicy{ingressNetPol}
+	creator := pMgr.creatorForNewNetworkPolicies(chainNames(policies), policies, ipsets.IPv4)
+	lines := strings.Split(creator.ToString(), "\n")
+	require.Contains(t, lines, fmt.Sprintf("-A %s %s", ingressNetPolChain, strings.Replace(ingressDropRule, util.IptablesAzureIngressDropMarkHex, "0x400/0xffff0400", 1)))
+}
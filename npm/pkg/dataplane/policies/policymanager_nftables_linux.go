@@ -0,0 +1,504 @@
+package policies
+
+// This file contains code for the nftables implementation of adding/removing policies.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/droplog"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/util"
+	"github.com/Azure/azure-container-networking/npm/util/ioutil"
+)
+
+const (
+	// nft reports errors like "/dev/stdin:3:1-35: Error: Could not process rule: No such file or directory"
+	nftLineErrorPattern = ":(\\d+):\\d+-\\d+: Error:"
+	// nft rejects comments longer than this
+	nftMaxCommentLength = 128
+
+	// anyFamily is for rules without set matches, which apply to both IPv4 and IPv6 traffic.
+	// IPv6 traffic only reaches the NPM chains if IPv6 is enabled (see the forward chain in creatorForNFTablesBootup()).
+	anyFamily ipsets.IPFamily = ""
+
+	portsMapSuffix = "PORTS"
+)
+
+// nftTransaction runs the lines that writeLines adds to an nft file in one transaction.
+type nftTransaction func(writeLines func(creator *ioutil.FileCreator)) error
+
+/*
+The nftables dataplane has the same chain layout and marks as the iptables dataplane, within the inet azure-npm table.
+Policy chains are the same as in iptables, except allowed ACLs with only a protocol and a single port are
+looked up in a verdict map per policy chain. The order of ACLs within a policy chain doesn't matter,
+since drop ACLs only set a mark and allow ACLs end evaluation of the chain.
+
+nft can only delete rules by handle, so the AZURE-NPM, AZURE-NPM-INGRESS, and AZURE-NPM-EGRESS chains
+are rewritten with the jumps to all policy chains whenever a policy is added or removed.
+Each add/remove is one nft transaction, so there is no point in time where the chains are partially written.
+The DataPlane applies the IPSets in the same transaction (see SetNFTablesTransaction()).
+
+example nft file for adding a policy with one ingress chain:
+
+	add chain inet azure-npm AZURE-NPM-INGRESS-123
+	flush chain inet azure-npm AZURE-NPM-INGRESS-123
+	add map inet azure-npm AZURE-NPM-INGRESS-123-PORTS { type inet_proto . inet_service : verdict; }
+	flush map inet azure-npm AZURE-NPM-INGRESS-123-PORTS
+	add element inet azure-npm AZURE-NPM-INGRESS-123-PORTS { tcp . 80 : jump AZURE-NPM-INGRESS-ALLOW-MARK }
+	add rule inet azure-npm AZURE-NPM-INGRESS-123 meta l4proto . th dport vmap @AZURE-NPM-INGRESS-123-PORTS
+	add rule inet azure-npm AZURE-NPM-INGRESS-123 ip saddr @azure-npm-456 meta mark set meta mark | 0x400 comment "DROP-FROM-..."
+	flush chain inet azure-npm AZURE-NPM
+	flush chain inet azure-npm AZURE-NPM-INGRESS
+	flush chain inet azure-npm AZURE-NPM-EGRESS
+	add rule inet azure-npm AZURE-NPM jump AZURE-NPM-INGRESS
+	add rule inet azure-npm AZURE-NPM jump AZURE-NPM-EGRESS
+	add rule inet azure-npm AZURE-NPM jump AZURE-NPM-ACCEPT
+	add rule inet azure-npm AZURE-NPM-INGRESS ip daddr @azure-npm-789 jump AZURE-NPM-INGRESS-123 comment "INGRESS-POLICY-..."
+	add rule inet azure-npm AZURE-NPM-INGRESS meta mark & 0x400 == 0x400 drop comment "DROP-ON-INGRESS-DROP-MARK-0x400"
+	add rule inet azure-npm AZURE-NPM-EGRESS meta mark & 0x800 == 0x800 drop comment "DROP-ON-EGRESS-DROP-MARK-0x800"
+	add rule inet azure-npm AZURE-NPM-EGRESS meta mark & 0x200 == 0x200 jump AZURE-NPM-ACCEPT comment "ACCEPT-ON-INGRESS-ALLOW-MARK-0x200"
+*/
+func (pMgr *PolicyManager) addPoliciesWithNFTables(networkPolicies []*NPMNetworkPolicy) error {
+	timer := metrics.StartNewTimer()
+	err := pMgr.runNFTablesTransaction(func(creator *ioutil.FileCreator) {
+		pMgr.writeNFTablesNewPolicies(creator, networkPolicies)
+	})
+	metrics.RecordIPTablesRestoreLatency(timer, metrics.CreateOp)
+	if err != nil {
+		metrics.IncIPTablesRestoreFailures(metrics.CreateOp)
+		return fmt.Errorf("failed to run nft with updated policies. err: %w", err)
+	}
+	return nil
+}
+
+func (pMgr *PolicyManager) removePolicyWithNFTables(networkPolicy *NPMNetworkPolicy) error {
+	timer := metrics.StartNewTimer()
+	err := pMgr.runNFTablesTransaction(func(creator *ioutil.FileCreator) {
+		pMgr.writeNFTablesRemovingPolicy(creator, networkPolicy)
+	})
+	metrics.RecordIPTablesRestoreLatency(timer, metrics.DeleteOp)
+	if err != nil {
+		metrics.IncIPTablesRestoreFailures(metrics.DeleteOp)
+		return fmt.Errorf("failed to run nft to remove policy. err: %w", err)
+	}
+	return nil
+}
+
+// SetNFTablesTransaction makes policy changes run in the nft transactions of tx, so that the DataPlane can apply IPSets in the same transaction.
+func (pMgr *PolicyManager) SetNFTablesTransaction(tx func(writeLines func(creator *ioutil.FileCreator)) error) {
+	pMgr.nftTransaction = tx
+}
+
+// runNFTablesTransaction runs the lines that writeLines adds in one nft transaction, which is the DataPlane's if set.
+func (pMgr *PolicyManager) runNFTablesTransaction(writeLines func(creator *ioutil.FileCreator)) error {
+	if pMgr.nftTransaction != nil {
+		return pMgr.nftTransaction(writeLines)
+	}
+	creator := newNFTablesCreator(pMgr.ioShim)
+	writeLines(creator)
+	return runNFT(creator)
+}
+
+func runNFT(creator *ioutil.FileCreator) error {
+	err := creator.RunCommandWithFile(util.Nft, util.NftFileFlag, util.NftStdinFile)
+	if err != nil {
+		return fmt.Errorf("failed to run nft file. err: %w", err)
+	}
+	return nil
+}
+
+func newNFTablesCreator(ioShim *common.IOShim) *ioutil.FileCreator {
+	return ioutil.NewFileCreator(ioShim, maxTryCount, nftLineErrorPattern)
+}
+
+func (pMgr *PolicyManager) creatorForNFTablesNewPolicies(networkPolicies []*NPMNetworkPolicy) *ioutil.FileCreator {
+	creator := newNFTablesCreator(pMgr.ioShim)
+	pMgr.writeNFTablesNewPolicies(creator, networkPolicies)
+	return creator
+}
+
+func (pMgr *PolicyManager) writeNFTablesNewPolicies(creator *ioutil.FileCreator, networkPolicies []*NPMNetworkPolicy) {
+	// 1. write the policy chains. Admin tier policies don't have their own chains.
+	for _, networkPolicy := range networkPolicies {
+		if !networkPolicy.isAdminTier() {
+			pMgr.writeNFTablesPolicyChains(creator, networkPolicy)
+		}
+	}
+
+	// 2. rewrite the jumps to all policy chains and the admin tier chains, and activate NPM (if necessary)
+	pMgr.writeNFTablesJumpChains(creator, pMgr.cachedPoliciesWith(networkPolicies))
+}
+
+func (pMgr *PolicyManager) creatorForNFTablesRemovingPolicy(networkPolicy *NPMNetworkPolicy) *ioutil.FileCreator {
+	creator := newNFTablesCreator(pMgr.ioShim)
+	pMgr.writeNFTablesRemovingPolicy(creator, networkPolicy)
+	return creator
+}
+
+func (pMgr *PolicyManager) writeNFTablesRemovingPolicy(creator *ioutil.FileCreator, networkPolicy *NPMNetworkPolicy) {
+	// 1. rewrite the jumps to the remaining policy chains and the admin tier chains, and deactivate NPM (if necessary)
+	pMgr.writeNFTablesJumpChains(creator, pMgr.cachedPoliciesWithout(networkPolicy.PolicyKey))
+	if networkPolicy.isAdminTier() {
+		return
+	}
+
+	// 2. delete the policy chains and their verdict maps, which are no longer referenced
+	for _, direction := range []UniqueDirection{forIngress, forEgress} {
+		acls := aclsForDirection(networkPolicy, direction)
+		if len(acls) == 0 {
+			continue
+		}
+		chainName := nftPolicyChainName(networkPolicy, direction)
+		sectionID := joinWithDash(chainSectionPrefix, chainName)
+		errorHandlers := []*ioutil.LineErrorHandler{
+			{
+				Definition: ioutil.AlwaysMatchDefinition,
+				Method:     ioutil.AbortSection,
+				Callback: func() {
+					metrics.SendErrorLogAndMetric(util.IptmID, "skipping delete of nft chain %s due to unknown error", chainName)
+				},
+			},
+		}
+		addNFTLine(creator, sectionID, errorHandlers, "flush", "chain", chainName)
+		if portACLs, _ := splitPortACLs(acls); len(portACLs) > 0 {
+			addNFTLine(creator, sectionID, errorHandlers, "delete", "map", joinWithDash(chainName, portsMapSuffix))
+		}
+		addNFTLine(creator, sectionID, errorHandlers, "delete", "chain", chainName)
+	}
+}
+
+// writeNFTablesJumpChains rewrites the AZURE-NPM, AZURE-NPM-INGRESS, and AZURE-NPM-EGRESS chains,
+// as well as the admin tier chains if AdminNetworkPolicy is enabled.
+// NPM is activated (AZURE-NPM has rules) if and only if there are policies.
+func (pMgr *PolicyManager) writeNFTablesJumpChains(creator *ioutil.FileCreator, allPolicies map[string]*NPMNetworkPolicy) {
+	addNFTLine(creator, "", nil, "flush", "chain", util.IptablesAzureChain)
+	addNFTLine(creator, "", nil, "flush", "chain", util.IptablesAzureIngressChain)
+	addNFTLine(creator, "", nil, "flush", "chain", util.IptablesAzureEgressChain)
+	if pMgr.EnableAdminNetworkPolicy {
+		for _, chain := range iptablesAdminTierChains {
+			addNFTLine(creator, "", nil, "flush", "chain", chain)
+		}
+		pMgr.writeNFTablesAdminTierRules(creator, allPolicies)
+	}
+
+	if len(allPolicies) > 0 {
+		addNFTRule(creator, util.IptablesAzureChain, "jump", util.IptablesAzureIngressChain)
+		addNFTRule(creator, util.IptablesAzureChain, "jump", util.IptablesAzureEgressChain)
+		addNFTRule(creator, util.IptablesAzureChain, "jump", util.IptablesAzureAcceptChain)
+	}
+
+	policyKeys := make([]string, 0, len(allPolicies))
+	for key := range allPolicies {
+		policyKeys = append(policyKeys, key)
+	}
+	sort.Strings(policyKeys)
+
+	// add AZURE-NPM-INGRESS chain rules
+	if pMgr.EnableAdminNetworkPolicy {
+		addNFTRule(creator, util.IptablesAzureIngressChain, "jump", util.IptablesAzureAdminIngressChain)
+	}
+	for _, key := range policyKeys {
+		pMgr.writeNFTablesJumps(creator, allPolicies[key], forIngress)
+	}
+	if pMgr.EnableDropLogging {
+		// NetworkPolicies only mark packets for drop, so they're logged where they're dropped, with the ACL ID in the mark
+		addNFTRule(creator, util.IptablesAzureIngressChain, append(nftOnMarkSpecs(util.NftAzureIngressDropMark), nftLogSpecs(droplog.MarkTag(droplog.Ingress))...)...)
+	}
+	ingressDropSpecs := nftOnMarkSpecs(util.NftAzureIngressDropMark)
+	ingressDropSpecs = append(ingressDropSpecs, "drop")
+	ingressDropSpecs = append(ingressDropSpecs, nftCommentSpecs(fmt.Sprintf("DROP-ON-INGRESS-DROP-MARK-%s", util.NftAzureIngressDropMark))...)
+	addNFTRule(creator, util.IptablesAzureIngressChain, ingressDropSpecs...)
+	if pMgr.EnableAdminNetworkPolicy {
+		addNFTRule(creator, util.IptablesAzureIngressChain, "jump", util.IptablesAzureBaselineIngressChain)
+	}
+
+	// add AZURE-NPM-EGRESS chain rules
+	if pMgr.EnableAdminNetworkPolicy {
+		addNFTRule(creator, util.IptablesAzureEgressChain, "jump", util.IptablesAzureAdminEgressChain)
+	}
+	for _, key := range policyKeys {
+		pMgr.writeNFTablesJumps(creator, allPolicies[key], forEgress)
+	}
+	if pMgr.EnableDropLogging {
+		addNFTRule(creator, util.IptablesAzureEgressChain, append(nftOnMarkSpecs(util.NftAzureEgressDropMark), nftLogSpecs(droplog.MarkTag(droplog.Egress))...)...)
+	}
+	egressDropSpecs := nftOnMarkSpecs(util.NftAzureEgressDropMark)
+	egressDropSpecs = append(egressDropSpecs, "drop")
+	egressDropSpecs = append(egressDropSpecs, nftCommentSpecs(fmt.Sprintf("DROP-ON-EGRESS-DROP-MARK-%s", util.NftAzureEgressDropMark))...)
+	addNFTRule(creator, util.IptablesAzureEgressChain, egressDropSpecs...)
+	if pMgr.EnableAdminNetworkPolicy {
+		addNFTRule(creator, util.IptablesAzureEgressChain, "jump", util.IptablesAzureBaselineEgressChain)
+	}
+
+	acceptOnIngressAllowSpecs := nftOnMarkSpecs(util.NftAzureIngressAllowMark)
+	acceptOnIngressAllowSpecs = append(acceptOnIngressAllowSpecs, "jump", util.IptablesAzureAcceptChain)
+	acceptOnIngressAllowSpecs = append(acceptOnIngressAllowSpecs, nftCommentSpecs(fmt.Sprintf("ACCEPT-ON-INGRESS-ALLOW-MARK-%s", util.NftAzureIngressAllowMark))...)
+	addNFTRule(creator, util.IptablesAzureEgressChain, acceptOnIngressAllowSpecs...)
+}
+
+// writeNFTablesAdminTierRules writes the ACLs of admin tier policies into the admin tier chains (see writeAdminTierRules()).
+func (pMgr *PolicyManager) writeNFTablesAdminTierRules(creator *ioutil.FileCreator, allPolicies map[string]*NPMNetworkPolicy) {
+	for _, tier := range []PolicyTier{AdminTier, BaselineTier} {
+		for _, networkPolicy := range sortedTierPolicies(allPolicies, tier) {
+			for aclID, aclPolicy := range networkPolicy.ACLs {
+				chainName := adminTierChainName(tier, UniqueDirection(aclPolicy.hasIngress()))
+				hasSets := len(aclPolicy.SrcList) > 0 || len(aclPolicy.DstList) > 0
+				for _, family := range pMgr.nftFamilies(hasSets) {
+					if pMgr.EnableDropLogging && aclPolicy.Target == Dropped {
+						addNFTRule(creator, chainName, append(nftRuleSpecs(aclPolicy, family), nftLogSpecs(networkPolicy.dropLogTag(aclID).String())...)...)
+					}
+					specs := nftRuleSpecs(aclPolicy, family)
+					specs = append(specs, nftAdminTierActionSpecs(aclPolicy)...)
+					specs = append(specs, nftCommentSpecs(networkPolicy.commentForAdminTierACL(aclPolicy))...)
+					addNFTRule(creator, chainName, specs...)
+				}
+			}
+		}
+	}
+}
+
+// writeNFTablesJumps writes the jump(s) from AZURE-NPM-INGRESS/AZURE-NPM-EGRESS to the policy chain for the direction.
+func (pMgr *PolicyManager) writeNFTablesJumps(creator *ioutil.FileCreator, networkPolicy *NPMNetworkPolicy, direction UniqueDirection) {
+	if networkPolicy.isAdminTier() || len(aclsForDirection(networkPolicy, direction)) == 0 {
+		return
+	}
+
+	baseChainName := util.IptablesAzureEgressChain
+	matchType := SrcMatch
+	comment := networkPolicy.commentForJumpToEgress()
+	if direction == forIngress {
+		baseChainName = util.IptablesAzureIngressChain
+		matchType = DstMatch
+		comment = networkPolicy.commentForJumpToIngress()
+	}
+
+	for _, family := range pMgr.nftFamilies(len(networkPolicy.PodSelectorList) > 0) {
+		specs := make([]string, 0)
+		for _, setInfo := range networkPolicy.PodSelectorList {
+			specs = append(specs, setInfo.nftMatchSpecs(matchType, family)...)
+		}
+		specs = append(specs, "jump", nftPolicyChainName(networkPolicy, direction))
+		specs = append(specs, nftCommentSpecs(comment)...)
+		addNFTRule(creator, baseChainName, specs...)
+	}
+}
+
+// writeNFTablesPolicyChains writes the policy chain(s) and verdict map(s) for the policy.
+func (pMgr *PolicyManager) writeNFTablesPolicyChains(creator *ioutil.FileCreator, networkPolicy *NPMNetworkPolicy) {
+	aclIDs := make(map[*ACLPolicy]int, len(networkPolicy.ACLs))
+	for aclID, aclPolicy := range networkPolicy.ACLs {
+		aclIDs[aclPolicy] = aclID
+	}
+
+	for _, direction := range []UniqueDirection{forIngress, forEgress} {
+		acls := aclsForDirection(networkPolicy, direction)
+		if len(acls) == 0 {
+			continue
+		}
+
+		chainName := nftPolicyChainName(networkPolicy, direction)
+		addNFTLine(creator, "", nil, "add", "chain", chainName)
+		addNFTLine(creator, "", nil, "flush", "chain", chainName)
+
+		portACLs, otherACLs := splitPortACLs(acls)
+		if len(portACLs) > 0 {
+			mapName := joinWithDash(chainName, portsMapSuffix)
+			addNFTLine(creator, "", nil, "add", "map", mapName, "{ type inet_proto . inet_service : verdict; }")
+			addNFTLine(creator, "", nil, "flush", "map", mapName)
+
+			elements := make([]string, 0, len(portACLs))
+			seen := make(map[string]struct{}, len(portACLs))
+			for _, aclPolicy := range portACLs {
+				element := fmt.Sprintf("%s . %d : %s", nftProtocol(aclPolicy.Protocol), aclPolicy.DstPorts.Port, strings.Join(nftActionSpecs(aclPolicy), " "))
+				if _, ok := seen[element]; ok {
+					continue
+				}
+				seen[element] = struct{}{}
+				elements = append(elements, element)
+			}
+			addNFTLine(creator, "", nil, "add", "element", mapName, "{", strings.Join(elements, ", "), "}")
+			addNFTRule(creator, chainName, "meta", "l4proto", ".", "th", "dport", "vmap", "@"+mapName)
+		}
+
+		for _, aclPolicy := range otherACLs {
+			hasSets := len(aclPolicy.SrcList) > 0 || len(aclPolicy.DstList) > 0
+			for _, family := range pMgr.nftFamilies(hasSets) {
+				specs := nftRuleSpecs(aclPolicy, family)
+				specs = append(specs, pMgr.nftPolicyActionSpecs(networkPolicy, aclIDs[aclPolicy])...)
+				specs = append(specs, nftCommentSpecs(aclPolicy.comment())...)
+				addNFTRule(creator, chainName, specs...)
+			}
+		}
+	}
+}
+
+// nftFamilies returns the families to write a rule for. Rules without set matches are written once for both families.
+func (pMgr *PolicyManager) nftFamilies(hasSets bool) []ipsets.IPFamily {
+	if !hasSets {
+		return []ipsets.IPFamily{anyFamily}
+	}
+	return pMgr.families()
+}
+
+// aclsForDirection returns the ACLs in the ingress or egress policy chain. ACLs for both directions are in the ingress chain.
+func aclsForDirection(networkPolicy *NPMNetworkPolicy, direction UniqueDirection) []*ACLPolicy {
+	acls := make([]*ACLPolicy, 0, len(networkPolicy.ACLs))
+	for _, aclPolicy := range networkPolicy.ACLs {
+		if UniqueDirection(aclPolicy.hasIngress()) == direction {
+			acls = append(acls, aclPolicy)
+		}
+	}
+	return acls
+}
+
+// splitPortACLs separates the ACLs which can be looked up in a verdict map:
+// allowed ACLs with no set matches, a protocol, and a single port.
+func splitPortACLs(acls []*ACLPolicy) (portACLs, otherACLs []*ACLPolicy) {
+	for _, aclPolicy := range acls {
+		isPortACL := aclPolicy.Target == Allowed &&
+			len(aclPolicy.SrcList) == 0 && len(aclPolicy.DstList) == 0 &&
+			aclPolicy.Protocol != UnspecifiedProtocol &&
+			aclPolicy.DstPorts.Port != 0 && aclPolicy.DstPorts.Port == aclPolicy.DstPorts.EndPort
+		if isPortACL {
+			portACLs = append(portACLs, aclPolicy)
+		} else {
+			otherACLs = append(otherACLs, aclPolicy)
+		}
+	}
+	return portACLs, otherACLs
+}
+
+func nftPolicyChainName(networkPolicy *NPMNetworkPolicy, direction UniqueDirection) string {
+	if direction == forIngress {
+		return networkPolicy.ingressChainName()
+	}
+	return networkPolicy.egressChainName()
+}
+
+func nftRuleSpecs(aclPolicy *ACLPolicy, family ipsets.IPFamily) []string {
+	specs := make([]string, 0)
+	if aclPolicy.Protocol != UnspecifiedProtocol {
+		specs = append(specs, "meta", "l4proto", nftProtocol(aclPolicy.Protocol))
+	}
+	if aclPolicy.DstPorts.Port != 0 {
+		specs = append(specs, "th", "dport", aclPolicy.DstPorts.toNFTablesString())
+	}
+	for _, setInfo := range aclPolicy.SrcList {
+		specs = append(specs, setInfo.nftMatchSpecs(setInfo.MatchType, family)...)
+	}
+	for _, setInfo := range aclPolicy.DstList {
+		specs = append(specs, setInfo.nftMatchSpecs(setInfo.MatchType, family)...)
+	}
+	return specs
+}
+
+func nftActionSpecs(aclPolicy *ACLPolicy) []string {
+	if aclPolicy.hasIngress() {
+		if aclPolicy.Target == Allowed {
+			return []string{"jump", util.IptablesAzureIngressAllowMarkChain}
+		}
+		return nftSetMarkSpecs(util.NftAzureIngressDropMark)
+	}
+	if aclPolicy.Target == Allowed {
+		return []string{"jump", util.IptablesAzureAcceptChain}
+	}
+	return nftSetMarkSpecs(util.NftAzureEgressDropMark)
+}
+
+// nftPolicyActionSpecs returns nftActionSpecs for the ACL of a NetworkPolicy at index aclID.
+// With drop logging, ACLs which drop also set their ID in the mark (see dropMarkHex()).
+func (pMgr *PolicyManager) nftPolicyActionSpecs(networkPolicy *NPMNetworkPolicy, aclID int) []string {
+	aclPolicy := networkPolicy.ACLs[aclID]
+	if !pMgr.EnableDropLogging || aclPolicy.Target != Dropped {
+		return nftActionSpecs(aclPolicy)
+	}
+	mark := util.AzureEgressDropMark
+	if aclPolicy.hasIngress() {
+		mark = util.AzureIngressDropMark
+	}
+	value, mask := droplog.Mark(pMgr.dropLogMarkIDs.Assign(networkPolicy.dropLogTag(aclID)), mark)
+	return []string{"meta", "mark", "set", "meta", "mark", "&", fmt.Sprintf("0x%x", ^mask), "|", fmt.Sprintf("0x%x", value)}
+}
+
+func nftAdminTierActionSpecs(aclPolicy *ACLPolicy) []string {
+	switch aclPolicy.Target {
+	case Allowed:
+		return nftActionSpecs(aclPolicy)
+	case Passed:
+		return []string{"return"}
+	default:
+		return []string{"drop"}
+	}
+}
+
+// nftMatchSpecs matches the nftables set of the given family, e.g. "ip saddr != @azure-npm-123".
+// A named port set matches the destination IP, protocol, and port.
+func (info SetInfo) nftMatchSpecs(matchType MatchType, family ipsets.IPFamily) []string {
+	addressKeyword := "ip"
+	if family == ipsets.IPv6 {
+		addressKeyword = "ip6"
+	}
+
+	var specs []string
+	switch matchType {
+	case SrcMatch:
+		specs = []string{addressKeyword, "saddr"}
+	case DstDstMatch:
+		specs = []string{addressKeyword, "daddr", ".", "meta", "l4proto", ".", "th", "dport"}
+	default:
+		specs = []string{addressKeyword, "daddr"}
+	}
+	if !info.Included {
+		specs = append(specs, "!=")
+	}
+	return append(specs, "@"+info.IPSet.GetHashedNameForFamily(family))
+}
+
+func (portRange *Ports) toNFTablesString() string {
+	if portRange.Port == portRange.EndPort {
+		return fmt.Sprint(portRange.Port)
+	}
+	return fmt.Sprintf("%d-%d", portRange.Port, portRange.EndPort)
+}
+
+func nftProtocol(protocol Protocol) string {
+	return strings.ToLower(string(protocol))
+}
+
+func nftSetMarkSpecs(mark string) []string {
+	return []string{"meta", "mark", "set", "meta", "mark", "|", mark}
+}
+
+func nftOnMarkSpecs(mark string) []string {
+	return []string{"meta", "mark", "&", mark, "==", mark}
+}
+
+// nftLogSpecs logs packets to the drop log NFLOG group, with the prefix. The limit comes first so that it only applies to logging.
+func nftLogSpecs(prefix string) []string {
+	return []string{
+		"limit", "rate", droplog.RateLimit, "burst", fmt.Sprint(droplog.RateLimitBurst), "packets",
+		"log", "prefix", fmt.Sprintf("%q", prefix), "group", fmt.Sprint(droplog.Group),
+	}
+}
+
+func nftCommentSpecs(comment string) []string {
+	if len(comment) > nftMaxCommentLength {
+		comment = comment[:nftMaxCommentLength]
+	}
+	return []string{"comment", fmt.Sprintf("%q", comment)}
+}
+
+func addNFTRule(creator *ioutil.FileCreator, chainName string, specs ...string) {
+	addNFTLine(creator, "", nil, "add", "rule", append([]string{chainName}, specs...)...)
+}
+
+// addNFTLine adds a line like "<verb> <object> inet azure-npm <items...>"
+func addNFTLine(creator *ioutil.FileCreator, sectionID string, errorHandlers []*ioutil.LineErrorHandler, verb, object string, items ...string) {
+	line := []string{verb, object, util.NftAzureTableFamily, util.NftAzureTable}
+	line = append(line, items...)
+	creator.AddLine(sectionID, errorHandlers, line...)
+}
@@ -0,0 +1,463 @@
+package policies
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/common"
+	"github.com/Azure/azure-container-networking/npm/metrics"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	dptestutils "github.com/Azure/azure-container-networking/npm/pkg/dataplane/testutils"
+	"github.com/Azure/azure-container-networking/npm/util"
+	testutils "github.com/Azure/azure-container-networking/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	fakeNFTCommand = testutils.TestCmd{Cmd: []string{"nft", "-f", "-"}}
+
+	nftablesConfig = &PolicyManagerCfg{
+		PolicyMode:           IPSetPolicyMode,
+		PlaceAzureChainFirst: util.PlaceAzureChainFirst,
+		EnableNFTables:       true,
+	}
+	nftablesIPv6Config = &PolicyManagerCfg{
+		PolicyMode:           IPSetPolicyMode,
+		PlaceAzureChainFirst: util.PlaceAzureChainFirst,
+		EnableIPv6:           true,
+		EnableNFTables:       true,
+	}
+)
+
+// nft rule variables for ACLs
+var (
+	nftIngressDropRule = fmt.Sprintf(
+		"meta l4proto tcp th dport 222-333 ip saddr @%s ip daddr != @%s meta mark set meta mark | 0x400 comment %q",
+		ipsets.TestCIDRSet.HashedName,
+		ipsets.TestKeyPodSet.HashedName,
+		ingressDropComment,
+	)
+	nftIngressAllowRule = fmt.Sprintf("ip saddr @%s jump AZURE-NPM-INGRESS-ALLOW-MARK comment %q", ipsets.TestCIDRSet.HashedName, ingressAllowComment)
+	nftEgressDropRule   = fmt.Sprintf("meta l4proto udp th dport 144 ip daddr @%s meta mark set meta mark | 0x800 comment %q",
+		ipsets.TestCIDRSet.HashedName,
+		egressDropComment,
+	)
+	nftEgressAllowRule = fmt.Sprintf("ip daddr @%s jump AZURE-NPM-ACCEPT comment %q", ipsets.TestNamedportSet.HashedName, egressAllowComment)
+)
+
+// nft rule variables for NetworkPolicies
+var (
+	nftIngressEgressNetPolIngressJump = fmt.Sprintf(
+		"ip daddr @%s jump %s comment %q",
+		ipsets.TestKeyPodSet.HashedName,
+		bothDirectionsNetPolIngressChain,
+		bothDirectionsNetPolIngressJumpComment,
+	)
+	nftIngressEgressNetPolEgressJump = fmt.Sprintf(
+		"ip saddr @%s jump %s comment %q",
+		ipsets.TestKeyPodSet.HashedName,
+		bothDirectionsNetPolEgressChain,
+		bothDirectionsNetPolEgressJumpComment,
+	)
+	nftIngressNetPolJump = fmt.Sprintf(
+		"ip daddr @%s ip daddr @%s jump %s comment %q",
+		ipsets.TestKeyPodSet.HashedName,
+		ipsets.TestNSSet.HashedName,
+		ingressNetPolChain,
+		ingressNetPolJumpComment,
+	)
+	nftEgressNetPolJump = fmt.Sprintf("jump %s comment %q", egressNetPolChain, egressNetPolJumpComment)
+
+	nftActivationLines = []string{
+		"add rule inet azure-npm AZURE-NPM jump AZURE-NPM-INGRESS",
+		"add rule inet azure-npm AZURE-NPM jump AZURE-NPM-EGRESS",
+		"add rule inet azure-npm AZURE-NPM jump AZURE-NPM-ACCEPT",
+	}
+	nftFlushJumpChainsLines = []string{
+		"flush chain inet azure-npm AZURE-NPM",
+		"flush chain inet azure-npm AZURE-NPM-INGRESS",
+		"flush chain inet azure-npm AZURE-NPM-EGRESS",
+	}
+	nftIngressDropOnMarkLine = `add rule inet azure-npm AZURE-NPM-INGRESS meta mark & 0x400 == 0x400 drop comment "DROP-ON-INGRESS-DROP-MARK-0x400"`
+	nftEgressDropOnMarkLines = []string{
+		`add rule inet azure-npm AZURE-NPM-EGRESS meta mark & 0x800 == 0x800 drop comment "DROP-ON-EGRESS-DROP-MARK-0x800"`,
+		`add rule inet azure-npm AZURE-NPM-EGRESS meta mark & 0x200 == 0x200 jump AZURE-NPM-ACCEPT comment "ACCEPT-ON-INGRESS-ALLOW-MARK-0x200"`,
+	}
+)
+
+func joinLines(lineGroups ...[]string) []string {
+	lines := make([]string, 0)
+	for _, group := range lineGroups {
+		lines = append(lines, group...)
+	}
+	return lines
+}
+
+func TestNFTablesCreatorForAddPolicies(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+	// 1. test with activation
+	policies := []*NPMNetworkPolicy{bothDirectionsNetPol}
+	creator := pMgr.creatorForNFTablesNewPolicies(policies)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := joinLines(
+		[]string{
+			// policy 1
+			"add chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolIngressChain, nftIngressDropRule),
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolIngressChain, nftIngressAllowRule),
+			"add chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolEgressChain, nftEgressDropRule),
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolEgressChain, nftEgressAllowRule),
+		},
+		nftFlushJumpChainsLines,
+		nftActivationLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressEgressNetPolIngressJump,
+			nftIngressDropOnMarkLine,
+			"add rule inet azure-npm AZURE-NPM-EGRESS " + nftIngressEgressNetPolEgressJump,
+		},
+		nftEgressDropOnMarkLines,
+		[]string{""},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// 2. test with policies already in the cache. Jumps are written for all policies in order of policy key.
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol}, nil))
+	policies = []*NPMNetworkPolicy{egressNetPol, ingressNetPol}
+	creator = pMgr.creatorForNFTablesNewPolicies(policies)
+	actualLines = strings.Split(creator.ToString(), "\n")
+	expectedLines = joinLines(
+		[]string{
+			// policy 3
+			"add chain inet azure-npm " + egressNetPolChain,
+			"flush chain inet azure-npm " + egressNetPolChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", egressNetPolChain, nftEgressAllowRule),
+			// policy 2
+			"add chain inet azure-npm " + ingressNetPolChain,
+			"flush chain inet azure-npm " + ingressNetPolChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", ingressNetPolChain, nftIngressDropRule),
+		},
+		nftFlushJumpChainsLines,
+		nftActivationLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressEgressNetPolIngressJump,
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressNetPolJump,
+			nftIngressDropOnMarkLine,
+			"add rule inet azure-npm AZURE-NPM-EGRESS " + nftIngressEgressNetPolEgressJump,
+			"add rule inet azure-npm AZURE-NPM-EGRESS " + nftEgressNetPolJump,
+		},
+		nftEgressDropOnMarkLines,
+		[]string{""},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestNFTablesCreatorForAddPoliciesWithVerdictMap(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+	portPolicy := &NPMNetworkPolicy{
+		Namespace: "x",
+		PolicyKey: "x/ports",
+		ACLs: []*ACLPolicy{
+			{Target: Allowed, Direction: Ingress, Protocol: TCP, DstPorts: Ports{80, 80}},
+			{Target: Allowed, Direction: Ingress, Protocol: UDP, DstPorts: Ports{53, 53}},
+			// duplicate element
+			{Target: Allowed, Direction: Ingress, Protocol: TCP, DstPorts: Ports{80, 80}},
+			// port ranges aren't in the verdict map
+			{Target: Allowed, Direction: Ingress, Protocol: TCP, DstPorts: Ports{8000, 8080}},
+			// drops aren't in the verdict map
+			{Target: Dropped, Direction: Ingress, Protocol: TCP, DstPorts: Ports{22, 22}},
+		},
+	}
+	chainName := portPolicy.ingressChainName()
+	mapName := chainName + "-PORTS"
+
+	creator := pMgr.creatorForNFTablesNewPolicies([]*NPMNetworkPolicy{portPolicy})
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := joinLines(
+		[]string{
+			"add chain inet azure-npm " + chainName,
+			"flush chain inet azure-npm " + chainName,
+			fmt.Sprintf("add map inet azure-npm %s { type inet_proto . inet_service : verdict; }", mapName),
+			"flush map inet azure-npm " + mapName,
+			fmt.Sprintf("add element inet azure-npm %s { tcp . 80 : jump AZURE-NPM-INGRESS-ALLOW-MARK, udp . 53 : jump AZURE-NPM-INGRESS-ALLOW-MARK }", mapName),
+			fmt.Sprintf("add rule inet azure-npm %s meta l4proto . th dport vmap @%s", chainName, mapName),
+			fmt.Sprintf(`add rule inet azure-npm %s meta l4proto tcp th dport 8000-8080 jump AZURE-NPM-INGRESS-ALLOW-MARK comment "ALLOW-ALL-ON-TCP-TO-PORT-8000:8080"`, chainName),
+			fmt.Sprintf(`add rule inet azure-npm %s meta l4proto tcp th dport 22 meta mark set meta mark | 0x400 comment "DROP-ALL-ON-TCP-TO-PORT-22"`, chainName),
+		},
+		nftFlushJumpChainsLines,
+		nftActivationLines,
+		[]string{
+			fmt.Sprintf(`add rule inet azure-npm AZURE-NPM-INGRESS jump %s comment "INGRESS-POLICY-x/ports-TO-all-IN-ns-x"`, chainName),
+			nftIngressDropOnMarkLine,
+		},
+		nftEgressDropOnMarkLines,
+		[]string{""},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// the verdict map is deleted with the policy
+	creator = pMgr.creatorForNFTablesRemovingPolicy(portPolicy)
+	actualLines = strings.Split(creator.ToString(), "\n")
+	expectedLines = joinLines(
+		nftFlushJumpChainsLines,
+		[]string{nftIngressDropOnMarkLine},
+		nftEgressDropOnMarkLines,
+		[]string{
+			"flush chain inet azure-npm " + chainName,
+			"delete map inet azure-npm " + mapName,
+			"delete chain inet azure-npm " + chainName,
+			"",
+		},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestNFTablesCreatorForAddPoliciesWithIPv6(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	pMgr := NewPolicyManager(ioshim, nftablesIPv6Config)
+
+	creator := pMgr.creatorForNFTablesNewPolicies([]*NPMNetworkPolicy{ingressNetPol, egressNetPol})
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := joinLines(
+		[]string{
+			"add chain inet azure-npm " + ingressNetPolChain,
+			"flush chain inet azure-npm " + ingressNetPolChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", ingressNetPolChain, nftIngressDropRule),
+			fmt.Sprintf(
+				"add rule inet azure-npm %s meta l4proto tcp th dport 222-333 ip6 saddr @%s-v6 ip6 daddr != @%s-v6 meta mark set meta mark | 0x400 comment %q",
+				ingressNetPolChain,
+				ipsets.TestCIDRSet.HashedName,
+				ipsets.TestKeyPodSet.HashedName,
+				ingressDropComment,
+			),
+			"add chain inet azure-npm " + egressNetPolChain,
+			"flush chain inet azure-npm " + egressNetPolChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", egressNetPolChain, nftEgressAllowRule),
+			fmt.Sprintf("add rule inet azure-npm %s ip6 daddr @%s-v6 jump AZURE-NPM-ACCEPT comment %q", egressNetPolChain, ipsets.TestNamedportSet.HashedName, egressAllowComment),
+		},
+		nftFlushJumpChainsLines,
+		nftActivationLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressNetPolJump,
+			fmt.Sprintf(
+				"add rule inet azure-npm AZURE-NPM-INGRESS ip6 daddr @%s-v6 ip6 daddr @%s-v6 jump %s comment %q",
+				ipsets.TestKeyPodSet.HashedName,
+				ipsets.TestNSSet.HashedName,
+				ingressNetPolChain,
+				ingressNetPolJumpComment,
+			),
+			nftIngressDropOnMarkLine,
+			// a jump without pod selectors applies to both families
+			"add rule inet azure-npm AZURE-NPM-EGRESS " + nftEgressNetPolJump,
+		},
+		nftEgressDropOnMarkLines,
+		[]string{""},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestNFTablesCreatorForRemovingPolicy(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, nftablesConfig)
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol, ingressNetPol}, nil))
+
+	// 1. test without deactivation
+	creator := pMgr.creatorForNFTablesRemovingPolicy(bothDirectionsNetPol)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := joinLines(
+		nftFlushJumpChainsLines,
+		nftActivationLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressNetPolJump,
+			nftIngressDropOnMarkLine,
+		},
+		nftEgressDropOnMarkLines,
+		[]string{
+			"flush chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			"delete chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			"delete chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			"",
+		},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// 2. test with deactivation
+	delete(pMgr.policyMap.cache, bothDirectionsNetPol.PolicyKey)
+	creator = pMgr.creatorForNFTablesRemovingPolicy(ingressNetPol)
+	actualLines = strings.Split(creator.ToString(), "\n")
+	expectedLines = joinLines(
+		nftFlushJumpChainsLines,
+		[]string{nftIngressDropOnMarkLine},
+		nftEgressDropOnMarkLines,
+		[]string{
+			"flush chain inet azure-npm " + ingressNetPolChain,
+			"delete chain inet azure-npm " + ingressNetPolChain,
+			"",
+		},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestNFTablesCreatorForAdminTierPolicies(t *testing.T) {
+	calls := []testutils.TestCmd{fakeNFTCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	cfg := *nftablesConfig
+	cfg.EnableAdminNetworkPolicy = true
+	pMgr := NewPolicyManager(ioshim, &cfg)
+
+	nftFlushAdminTierChainsLines := []string{
+		"flush chain inet azure-npm AZURE-NPM-ADMIN-INGRESS",
+		"flush chain inet azure-npm AZURE-NPM-ADMIN-EGRESS",
+		"flush chain inet azure-npm AZURE-NPM-BASELINE-INGRESS",
+		"flush chain inet azure-npm AZURE-NPM-BASELINE-EGRESS",
+	}
+
+	// 1. AdminNetworkPolicy ACLs are ordered by priority and NetworkPolicy jumps come between the tier jumps
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{anpDenyNetPol}, nil))
+	policies := []*NPMNetworkPolicy{anpPassNetPol, banpAllowNetPol, bothDirectionsNetPol}
+	creator := pMgr.creatorForNFTablesNewPolicies(policies)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := joinLines(
+		[]string{
+			"add chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolIngressChain, nftIngressDropRule),
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolIngressChain, nftIngressAllowRule),
+			"add chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolEgressChain, nftEgressDropRule),
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolEgressChain, nftEgressAllowRule),
+		},
+		nftFlushJumpChainsLines,
+		nftFlushAdminTierChainsLines,
+		[]string{
+			fmt.Sprintf(`add rule inet azure-npm AZURE-NPM-ADMIN-INGRESS ip saddr @%s return comment "AdminNetworkPolicy/pass-PASS-FROM-ns-test-ns-set"`,
+				ipsets.TestNSSet.HashedName),
+			fmt.Sprintf(`add rule inet azure-npm AZURE-NPM-ADMIN-INGRESS ip saddr @%s jump AZURE-NPM-INGRESS-ALLOW-MARK comment "AdminNetworkPolicy/deny-%s"`,
+				ipsets.TestCIDRSet.HashedName, ingressAllowComment),
+			fmt.Sprintf(`add rule inet azure-npm AZURE-NPM-ADMIN-EGRESS meta l4proto udp th dport 144 ip daddr @%s drop comment "AdminNetworkPolicy/deny-%s"`,
+				ipsets.TestCIDRSet.HashedName, egressDropComment),
+			fmt.Sprintf(`add rule inet azure-npm AZURE-NPM-BASELINE-EGRESS ip daddr @%s jump AZURE-NPM-ACCEPT comment "BaselineAdminNetworkPolicy/default-%s"`,
+				ipsets.TestNamedportSet.HashedName, egressAllowComment),
+		},
+		nftActivationLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS jump AZURE-NPM-ADMIN-INGRESS",
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressEgressNetPolIngressJump,
+			nftIngressDropOnMarkLine,
+			"add rule inet azure-npm AZURE-NPM-INGRESS jump AZURE-NPM-BASELINE-INGRESS",
+			"add rule inet azure-npm AZURE-NPM-EGRESS jump AZURE-NPM-ADMIN-EGRESS",
+			"add rule inet azure-npm AZURE-NPM-EGRESS " + nftIngressEgressNetPolEgressJump,
+			nftEgressDropOnMarkLines[0],
+			"add rule inet azure-npm AZURE-NPM-EGRESS jump AZURE-NPM-BASELINE-EGRESS",
+			nftEgressDropOnMarkLines[1],
+			"",
+		},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+
+	// 2. removing an admin tier policy only rewrites the jump and tier chains
+	creator = pMgr.creatorForNFTablesRemovingPolicy(anpDenyNetPol)
+	actualLines = strings.Split(creator.ToString(), "\n")
+	expectedLines = joinLines(
+		nftFlushJumpChainsLines,
+		nftFlushAdminTierChainsLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS jump AZURE-NPM-ADMIN-INGRESS",
+			nftIngressDropOnMarkLine,
+			"add rule inet azure-npm AZURE-NPM-INGRESS jump AZURE-NPM-BASELINE-INGRESS",
+			"add rule inet azure-npm AZURE-NPM-EGRESS jump AZURE-NPM-ADMIN-EGRESS",
+			nftEgressDropOnMarkLines[0],
+			"add rule inet azure-npm AZURE-NPM-EGRESS jump AZURE-NPM-BASELINE-EGRESS",
+			nftEgressDropOnMarkLines[1],
+			"",
+		},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestNFTablesCreatorForAddPoliciesWithDropLogging(t *testing.T) {
+	ioshim := common.NewMockIOShim(nil)
+	defer ioshim.VerifyCalls(t, nil)
+	cfg := *nftablesConfig
+	cfg.EnableDropLogging = true
+	pMgr := NewPolicyManager(ioshim, &cfg)
+
+	policies := []*NPMNetworkPolicy{bothDirectionsNetPol}
+	creator := pMgr.creatorForNFTablesNewPolicies(policies)
+	actualLines := strings.Split(creator.ToString(), "\n")
+	expectedLines := joinLines(
+		[]string{
+			// no log rules in the policy chains, the ACLs which mark for drop set their ID in the mark
+			"add chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolIngressChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolIngressChain,
+				strings.Replace(nftIngressDropRule, "meta mark | 0x400", "meta mark & 0xfbff | 0x10400", 1)),
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolIngressChain, nftIngressAllowRule),
+			"add chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			"flush chain inet azure-npm " + bothDirectionsNetPolEgressChain,
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolEgressChain,
+				strings.Replace(nftEgressDropRule, "meta mark | 0x800", "meta mark & 0xf7ff | 0x20800", 1)),
+			fmt.Sprintf("add rule inet azure-npm %s %s", bothDirectionsNetPolEgressChain, nftEgressAllowRule),
+		},
+		nftFlushJumpChainsLines,
+		nftActivationLines,
+		[]string{
+			"add rule inet azure-npm AZURE-NPM-INGRESS " + nftIngressEgressNetPolIngressJump,
+			// packets marked for drop are logged where they're dropped
+			`add rule inet azure-npm AZURE-NPM-INGRESS meta mark & 0x400 == 0x400 limit rate 10/second burst 20 packets log prefix "NPM-DROP-MARK:I" group 100`,
+			nftIngressDropOnMarkLine,
+			"add rule inet azure-npm AZURE-NPM-EGRESS " + nftIngressEgressNetPolEgressJump,
+			`add rule inet azure-npm AZURE-NPM-EGRESS meta mark & 0x800 == 0x800 limit rate 10/second burst 20 packets log prefix "NPM-DROP-MARK:E" group 100`,
+		},
+		nftEgressDropOnMarkLines,
+		[]string{""},
+	)
+	dptestutils.AssertEqualLines(t, expectedLines, actualLines)
+}
+
+func TestNFTablesAddAndRemovePolicy(t *testing.T) {
+	metrics.ReinitializeAll()
+	calls := []testutils.TestCmd{fakeNFTCommand, fakeNFTCommand}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+	require.NoError(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol}, nil))
+	_, ok := pMgr.GetPolicy(bothDirectionsNetPol.PolicyKey)
+	require.True(t, ok)
+
+	require.NoError(t, pMgr.RemovePolicy(bothDirectionsNetPol.PolicyKey))
+	_, ok = pMgr.GetPolicy(bothDirectionsNetPol.PolicyKey)
+	require.False(t, ok)
+	promVals{0, 1}.testPrometheusMetrics(t)
+}
+
+func TestNFTablesAddPolicyFailure(t *testing.T) {
+	metrics.ReinitializeAll()
+	failure := testutils.TestCmd{Cmd: []string{"nft", "-f", "-"}, ExitCode: 1}
+	calls := []testutils.TestCmd{failure, failure}
+	ioshim := common.NewMockIOShim(calls)
+	defer ioshim.VerifyCalls(t, calls)
+	pMgr := NewPolicyManager(ioshim, nftablesConfig)
+
+	require.Error(t, pMgr.AddPolicies([]*NPMNetworkPolicy{bothDirectionsNetPol}, nil))
+	_, ok := pMgr.GetPolicy(bothDirectionsNetPol.PolicyKey)
+	require.False(t, ok)
+	promVals{0, 1}.testPrometheusMetrics(t)
+}
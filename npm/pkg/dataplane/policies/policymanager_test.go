@@ -38,6 +38,12 @@ type promVals struct {
 	execCount int
 }
 
+func adminTierNetPol(tier PolicyTier, name string, priority int32, acls ...*ACLPolicy) *NPMNetworkPolicy {
+	networkPolicy := NewAdminNPMNetworkPolicy(tier, name, priority)
+	networkPolicy.ACLs = acls
+	return networkPolicy
+}
+
 // need this as a function so that PodEndpoints is reset everytime
 func testNetworkPolicy() *NPMNetworkPolicy {
 	return &NPMNetworkPolicy{
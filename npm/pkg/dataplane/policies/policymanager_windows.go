@@ -94,6 +94,8 @@ type aclBatch struct {
 
 type staleChains struct{} // unused in Windows
 
+type nftTransaction struct{} // unused in Windows
+
 type shouldResetAllACLs bool
 
 type endpointPolicyBuilder struct {
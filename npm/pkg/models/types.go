@@ -10,6 +10,8 @@ import (
 	"k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	networkinginformers "k8s.io/client-go/informers/networking/v1"
+	anpinformers "sigs.k8s.io/network-policy-api/pkg/client/informers/externalversions"
+	anpv1alpha1informers "sigs.k8s.io/network-policy-api/pkg/client/informers/externalversions/apis/v1alpha1"
 )
 
 var (
@@ -34,6 +36,8 @@ type K8SControllersV2 struct {
 	NamespaceControllerV2 *controllersv2.NamespaceController     //nolint:structcheck // false lint error
 	NpmNamespaceCacheV2   *controllersv2.NpmNamespaceCache       //nolint:structcheck // false lint error
 	NetPolControllerV2    *controllersv2.NetworkPolicyController //nolint:structcheck // false lint error
+	// AdminNetPolControllerV2 is nil unless AdminNetworkPolicy is enabled
+	AdminNetPolControllerV2 *controllersv2.AdminNetworkPolicyController //nolint:structcheck // false lint error
 }
 
 // Informers are the informers for the k8s controllers
@@ -42,6 +46,10 @@ type Informers struct {
 	PodInformer     coreinformers.PodInformer                 //nolint:structcheck // false lint error
 	NsInformer      coreinformers.NamespaceInformer           //nolint:structcheck // false lint error
 	NpInformer      networkinginformers.NetworkPolicyInformer //nolint:structcheck // false lint error
+	// AnpInformerFactory, AnpInformer, and BanpInformer are nil unless AdminNetworkPolicy is enabled
+	AnpInformerFactory anpinformers.SharedInformerFactory                      //nolint:structcheck // false lint error
+	AnpInformer        anpv1alpha1informers.AdminNetworkPolicyInformer         //nolint:structcheck // false lint error
+	BanpInformer       anpv1alpha1informers.BaselineAdminNetworkPolicyInformer //nolint:structcheck // false lint error
 }
 
 // AzureConfig captures the Azure specific configurations and fields
@@ -46,6 +46,11 @@ const (
 	IptablesLegacy             string = "iptables"
 	IptablesSaveLegacy         string = "iptables-save"
 	IptablesRestoreLegacy      string = "iptables-restore"
+	Ip6tablesNft               string = "ip6tables-nft"         //nolint (avoid warning to capitalize this p)
+	Ip6tablesSaveNft           string = "ip6tables-nft-save"    //nolint (avoid warning to capitalize this p)
+	Ip6tablesRestoreNft        string = "ip6tables-nft-restore" //nolint (avoid warning to capitalize this p)
+	Ip6tablesSaveLegacy        string = "ip6tables-save"        //nolint (avoid warning to capitalize this p)
+	Ip6tablesRestoreLegacy     string = "ip6tables-restore"     //nolint (avoid warning to capitalize this p)
 	IptablesRestoreNoFlushFlag string = "--noflush"
 	IptablesRestoreTableFlag   string = "-T"
 	IptablesRestoreCommit      string = "COMMIT"
@@ -93,6 +98,12 @@ const (
 	IptablesFilterTable        string = "filter"
 	IptablesCommentModuleFlag  string = "comment"
 	IptablesCommentFlag        string = "--comment"
+	IptablesNFLog              string = "NFLOG"
+	IptablesNFLogGroupFlag     string = "--nflog-group"
+	IptablesNFLogPrefixFlag    string = "--nflog-prefix"
+	IptablesLimitModuleFlag    string = "limit"
+	IptablesLimitFlag          string = "--limit"
+	IptablesLimitBurstFlag     string = "--limit-burst"
 	IptablesAddCommentFlag
 
 	IptablesTableFlag       string = "-t"
@@ -110,6 +121,13 @@ const (
 	IptablesAzureIngressAllowMarkChain string = "AZURE-NPM-INGRESS-ALLOW-MARK"
 	IptablesAzureEgressChain           string = "AZURE-NPM-EGRESS"
 
+	// Chains for AdminNetworkPolicies, which are evaluated before NetworkPolicies,
+	// and for BaselineAdminNetworkPolicies, which are evaluated if no NetworkPolicy selects the Pod.
+	IptablesAzureAdminIngressChain    string = "AZURE-NPM-ADMIN-INGRESS"
+	IptablesAzureAdminEgressChain     string = "AZURE-NPM-ADMIN-EGRESS"
+	IptablesAzureBaselineIngressChain string = "AZURE-NPM-BASELINE-INGRESS"
+	IptablesAzureBaselineEgressChain  string = "AZURE-NPM-BASELINE-EGRESS"
+
 	// Chains used in NPM v1
 	IptablesAzureIngressPortChain  string = "AZURE-NPM-INGRESS-PORT"
 	IptablesAzureIngressFromChain  string = "AZURE-NPM-INGRESS-FROM"
@@ -136,8 +154,8 @@ const (
 	// Below are the skb->mark NPM will use for different criteria
 	// for V1
 	IptablesAzureClearMarkHex string = "0x0"
-	// for v2, deprecated
-	IptablesAzureClearMarkHexV2 string = "0x0/0xE00"
+	// for v2, clears the v2 marks and the ACL ID which drop logging sets in the upper 16 bits on accept
+	IptablesAzureClearMarkHexV2 string = "0x0/0xffff0e00"
 
 	// marks in NPM v2
 	// NPM uses the 3rd word of the 32-bit mark for the purpose of
@@ -146,6 +164,9 @@ const (
 	IptablesAzureIngressAllowMarkHex string = "0x200/0x200"
 	IptablesAzureIngressDropMarkHex  string = "0x400/0x400"
 	IptablesAzureEgressDropMarkHex   string = "0x800/0x800"
+	// With drop logging, NetworkPolicy ACLs set the drop marks together with an ID in the upper 16 bits of the mark.
+	AzureIngressDropMark uint32 = 0x400
+	AzureEgressDropMark  uint32 = 0x800
 
 	// marks in NPM v1
 	IptablesAzureIngressMarkHex string = "0x2000"
@@ -158,6 +179,28 @@ const (
 	IptablesAzureAcceptMarkHex string = "0x3000"
 )
 
+// nftables related constants.
+const (
+	Nft         string = "nft"
+	NftFileFlag string = "-f"
+	// NftStdinFile makes nft read the file from stdin.
+	NftStdinFile string = "-"
+
+	// NftAzureTable is the table holding all NPM sets, maps and chains for the nftables dataplane.
+	// It's in the inet family so that one table handles both IPv4 and IPv6.
+	NftAzureTable       string = "azure-npm"
+	NftAzureTableFamily string = "inet"
+	// NftAzureForwardChain is the base chain hooked into forward. It replaces the jump from FORWARD chain to AZURE-NPM chain.
+	NftAzureForwardChain string = "AZURE-NPM-FORWARD"
+
+	// marks in the nftables dataplane are the same bits as the v2 iptables marks
+	NftAzureIngressAllowMark string = "0x200"
+	NftAzureIngressDropMark  string = "0x400"
+	NftAzureEgressDropMark   string = "0x800"
+	// NftAzureKeepMarks is ANDed with the mark to clear the same bits as IptablesAzureClearMarkHexV2
+	NftAzureKeepMarks string = "0xf1ff"
+)
+
 // ipset related constants.
 const (
 	Ipset               string = "ipset"
@@ -14,6 +14,9 @@ var (
 
 	// ErrDstNotSpecified thrown during NPM debug cli mode when the source packet is not specified
 	ErrDstNotSpecified = errors.New("destination not specified")
+
+	// ErrLogFileNotSpecified thrown during NPM debug cli mode when the NPM log file is not specified
+	ErrLogFileNotSpecified = errors.New("log file not specified")
 )
 
 /*
@@ -71,6 +71,9 @@ const (
 	Continue LineErrorHandlerMethod = "continue"
 	// ContinueAndAbortSection specifies skipping this line, all previous lines, and all lines tied to this line's section
 	ContinueAndAbortSection LineErrorHandlerMethod = "continue-and-abort"
+	// AbortSection specifies skipping only the lines tied to this line's section.
+	// This is for commands that apply the file atomically (e.g. nft -f), where no lines are applied if a line fails.
+	AbortSection LineErrorHandlerMethod = "abort-section"
 
 	anyMatchPattern = ".*"
 )
@@ -309,6 +312,12 @@ func (creator *FileCreator) handleLineError(stdErr, commandString string, lineNu
 			for _, lineNum := range section.lineNums {
 				creator.lineNumbersToOmit[lineNum] = struct{}{}
 			}
+		case AbortSection:
+			klog.Infof("aborting section [%s] after line %d for command [%s]", line.sectionID, lineNum, commandString)
+			section := creator.sections[line.sectionID]
+			for _, lineNum := range section.lineNums {
+				creator.lineNumbersToOmit[lineNum] = struct{}{}
+			}
 		}
 		errorHandler.Callback()
 		return true, creator.lines[lineIndex]
@@ -235,6 +235,36 @@ func TestHandleLineErrorForContinue(t *testing.T) {
 	require.Equal(t, creator.lines[3], line, "expected a failure in line 2 to map to original line 4")
 }
 
+func TestHandleLineErrorForAbortSection(t *testing.T) {
+	fakeErrorCommand := testutils.TestCmd{
+		Cmd:      []string{testCommandString},
+		Stdout:   "failure on line 2: match-pattern do something please",
+		ExitCode: 1,
+	}
+	calls := []testutils.TestCmd{fakeErrorCommand}
+	creator := NewFileCreator(common.NewMockIOShim(calls), 2, "failure on line (\\d+)")
+	errorHandlers := []*LineErrorHandler{
+		{
+			Definition: NewErrorDefinition("match-pattern"),
+			Method:     AbortSection,
+			Callback:   func() { log.Logf("'abort-section' callback") },
+		},
+	}
+	creator.AddLine(section1ID, nil, "line1-item1", "line1-item2", "line1-item3")
+	creator.AddLine(section2ID, errorHandlers, "line2-item1", "line2-item2", "line2-item3")
+	creator.AddLine(section1ID, nil, "line3-item1", "line3-item2", "line3-item3")
+	creator.AddLine(section2ID, nil, "line4-item1", "line4-item2", "line4-item3")
+	wasFileAltered, err := creator.RunCommandOnceWithFile(testCommandString)
+	require.Error(t, err)
+	require.True(t, wasFileAltered)
+	fileString := creator.ToString()
+	assert.Equal(t, "line1-item1 line1-item2 line1-item3\nline3-item1 line3-item2 line3-item3\n", fileString)
+	require.Equal(t, map[int]struct{}{1: {}, 3: {}}, creator.lineNumbersToOmit, "expected only the lines in section 2 to be marked omitted")
+
+	_, line := creator.handleLineError("some error", testCommandString, 2)
+	require.Equal(t, creator.lines[2], line, "expected a failure in line 2 to map to original line 3")
+}
+
 func TestHandleLineErrorNoMatch(t *testing.T) {
 	fakeErrorCommand := testutils.TestCmd{
 		Cmd:      []string{testCommandString},
@@ -342,6 +342,25 @@ func IsIPV4(ip string) bool {
 	return address.Is4()
 }
 
+// IsIPV6 is the IPv6 counterpart of IsIPV4. It accepts an IPv6 address or CIDR, but not an IPv4-mapped IPv6 address.
+func IsIPV6(ip string) bool {
+	isIPBlock := strings.Contains(ip, "/")
+	ipOnly := strings.Split(ip, "/")
+	address, err := netip.ParseAddr(ipOnly[0])
+	if err != nil || !address.Is6() || address.Is4In6() || address.Zone() != "" {
+		return false
+	}
+	if strings.HasSuffix(ip, "/0") && !address.IsUnspecified() {
+		return false
+	}
+
+	if isIPBlock {
+		_, _, err := net.ParseCIDR(ip)
+		return err == nil
+	}
+	return true
+}
+
 // Get preferred outbound ip of this machine
 // source: https://stackoverflow.com/questions/23558425/how-do-i-get-the-local-ip-address-in-go
 func NodeIP() (string, error) {
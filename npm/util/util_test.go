@@ -451,3 +451,25 @@ func TestNodeIP(t *testing.T) {
 	_, err := NodeIP()
 	require.Nil(t, err, "NodeIP() returned error")
 }
+
+func TestIsIPV6(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "2001:db8::1", want: true},
+		{ip: "2001:db8::/32", want: true},
+		{ip: "2001:db8::1/64", want: true},
+		{ip: "::/0", want: true},
+		{ip: "2001:db8::/0", want: false},
+		{ip: "2001:db8::/129", want: false},
+		{ip: "::ffff:10.0.0.1", want: false},
+		{ip: "fe80::1%eth0", want: false},
+		{ip: "10.0.0.1", want: false},
+		{ip: "10.0.0.0/8", want: false},
+		{ip: "", want: false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, IsIPV6(tt.ip), tt.ip)
+	}
+}
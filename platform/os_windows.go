@@ -148,8 +148,12 @@ func SetOutboundSNAT(subnet string) error {
 
 // ClearNetworkConfiguration clears the azure-vnet.json contents.
 // This will be called only when reboot is detected - This is windows specific
+// A state migrated to a bolt store has no JSON file, the network manager saves the cleared state to it instead.
 func (p *execClient) ClearNetworkConfiguration() (bool, error) {
 	jsonStore := CNIRuntimePath + "azure-vnet.json"
+	if _, err := os.Stat(jsonStore); os.IsNotExist(err) {
+		return true, nil
+	}
 	p.logger.Info("Deleting the json", zap.String("store", jsonStore))
 	cmd := exec.Command("cmd", "/c", "del", jsonStore)
 
@@ -0,0 +1,485 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/log"
+	"github.com/Azure/azure-container-networking/processlock"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+var (
+	// boltBucket is the bucket holding the key value pairs.
+	boltBucket = []byte("state")
+	// boltRecordsBucket holds a bucket of records for each key written with WriteRecords.
+	boltRecordsBucket = []byte("records")
+)
+
+// boltStore is an implementation of RecordStore using an embedded bbolt database. Each key, and each record of a
+// key, is a record of the database, so writing a key does not re-serialize the others and writing the records of
+// a key only writes those that changed. Every write is a transaction synced to disk.
+type boltStore struct {
+	fileName string
+	// migrateFrom is the JSON file whose keys are imported the first time the database is opened.
+	migrateFrom string
+	db          *bolt.DB
+	processLock processlock.Interface
+	sync.Mutex
+	logger *zap.Logger
+}
+
+// NewBoltStore creates a new boltStore object, accessed as a KeyValueStore. The database is opened on first use
+// and, when the store is locked, closed again on Unlock so other processes can open it.
+func NewBoltStore(fileName string, lockclient processlock.Interface, logger *zap.Logger) (KeyValueStore, error) {
+	if fileName == "" {
+		return &boltStore{}, errors.New("need to pass in a database file path")
+	}
+
+	return &boltStore{
+		fileName:    fileName,
+		processLock: lockclient,
+		logger:      logger,
+	}, nil
+}
+
+// open opens the database if it is not open yet, migrating the JSON file first if there is one.
+func (kvs *boltStore) open() error {
+	if kvs.db != nil {
+		return nil
+	}
+
+	if kvs.migrateFrom != "" {
+		if _, err := MigrateJSONFileStore(kvs.migrateFrom, kvs.fileName, kvs.logger); err != nil {
+			return err
+		}
+	}
+
+	db, err := bolt.Open(kvs.fileName, 0o644, &bolt.Options{Timeout: DefaultLockTimeout})
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", kvs.fileName)
+	}
+
+	kvs.db = db
+	return nil
+}
+
+// close closes the database if it is open.
+func (kvs *boltStore) close() error {
+	if kvs.db == nil {
+		return nil
+	}
+
+	err := kvs.db.Close()
+	kvs.db = nil
+	return errors.Wrapf(err, "failed to close %s", kvs.fileName)
+}
+
+func (kvs *boltStore) Exists() bool {
+	if _, err := os.Stat(kvs.fileName); err != nil {
+		return false
+	}
+	return true
+}
+
+// Read restores the value for the given key from persistent store.
+func (kvs *boltStore) Read(key string, value interface{}) error {
+	kvs.Mutex.Lock()
+	defer kvs.Mutex.Unlock()
+
+	// Do not create the database just to find out it is empty.
+	if kvs.db == nil && !kvs.Exists() && (kvs.migrateFrom == "" || !fileExists(kvs.migrateFrom)) {
+		return ErrKeyNotFound
+	}
+
+	if err := kvs.open(); err != nil {
+		return err
+	}
+
+	var raw []byte
+	err := kvs.db.View(func(tx *bolt.Tx) error {
+		var err error
+		raw, err = readBoltValue(tx, []byte(key))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if raw == nil {
+		return ErrKeyNotFound
+	}
+
+	return json.Unmarshal(raw, value)
+}
+
+// Write saves the given key value pair to persistent store.
+func (kvs *boltStore) Write(key string, value interface{}) error {
+	kvs.Mutex.Lock()
+	defer kvs.Mutex.Unlock()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err := kvs.open(); err != nil {
+		return err
+	}
+
+	return kvs.db.Update(func(tx *bolt.Tx) error {
+		if err := putBoltValue(tx, []byte(key), raw); err != nil {
+			return err
+		}
+
+		// The value is whole, drop the records of a previous WriteRecords.
+		records := tx.Bucket(boltRecordsBucket)
+		if records == nil || records.Bucket([]byte(key)) == nil {
+			return nil
+		}
+		return errors.Wrapf(records.DeleteBucket([]byte(key)), "failed to delete the records of %s", key)
+	})
+}
+
+// WriteRecords saves the given value and its records to persistent store. Records which did not change are not
+// written again.
+func (kvs *boltStore) WriteRecords(key string, value json.RawMessage, records map[string]json.RawMessage) error {
+	kvs.Mutex.Lock()
+	defer kvs.Mutex.Unlock()
+
+	if err := kvs.open(); err != nil {
+		return err
+	}
+
+	return kvs.db.Update(func(tx *bolt.Tx) error {
+		if err := putBoltValue(tx, []byte(key), value); err != nil {
+			return err
+		}
+
+		parent, err := tx.CreateBucketIfNotExists(boltRecordsBucket)
+		if err != nil {
+			return errors.Wrap(err, "failed to create records bucket")
+		}
+		bucket, err := parent.CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the records bucket of %s", key)
+		}
+
+		// Keys must not be deleted while iterating over the bucket.
+		var stale [][]byte
+		err = bucket.ForEach(func(name, _ []byte) error {
+			if _, ok := records[string(name)]; !ok {
+				stale = append(stale, append([]byte{}, name...))
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list the records of %s", key)
+		}
+		for _, name := range stale {
+			if err := bucket.Delete(name); err != nil {
+				return errors.Wrapf(err, "failed to delete record %s of %s", name, key)
+			}
+		}
+
+		for name, record := range records {
+			if bytes.Equal(bucket.Get([]byte(name)), record) {
+				continue
+			}
+			if err := bucket.Put([]byte(name), record); err != nil {
+				return errors.Wrapf(err, "failed to put record %s of %s", name, key)
+			}
+		}
+
+		return nil
+	})
+}
+
+// putBoltValue puts the value of a key in the state bucket.
+func putBoltValue(tx *bolt.Tx, key, value []byte) error {
+	bucket, err := tx.CreateBucketIfNotExists(boltBucket)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bucket")
+	}
+	return errors.Wrapf(bucket.Put(key, value), "failed to put %s", key)
+}
+
+// readBoltValue returns a copy of the value of a key with its records back in place, or nil if there is none.
+func readBoltValue(tx *bolt.Tx, key []byte) ([]byte, error) {
+	bucket := tx.Bucket(boltBucket)
+	if bucket == nil {
+		return nil, nil
+	}
+
+	// The value is only valid for the life of the transaction.
+	v := bucket.Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	raw := append([]byte{}, v...)
+
+	var recordsBucket *bolt.Bucket
+	if parent := tx.Bucket(boltRecordsBucket); parent != nil {
+		recordsBucket = parent.Bucket(key)
+	}
+	if recordsBucket == nil {
+		return raw, nil
+	}
+
+	records := make(map[string]json.RawMessage)
+	err := recordsBucket.ForEach(func(name, record []byte) error {
+		records[string(name)] = append([]byte{}, record...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the records of %s", key)
+	}
+
+	raw, err = joinRecords(raw, records)
+	return raw, errors.Wrapf(err, "failed to join the records of %s", key)
+}
+
+// Flush is a no-op as every write is committed to persistent store.
+func (kvs *boltStore) Flush() error {
+	return nil
+}
+
+func (kvs *boltStore) lockUtil(status chan error) {
+	err := kvs.processLock.Lock()
+	status <- err
+}
+
+// Lock locks the store for exclusive access.
+func (kvs *boltStore) Lock(timeout time.Duration) error {
+	kvs.Mutex.Lock()
+	defer kvs.Mutex.Unlock()
+
+	afterTime := time.After(timeout)
+	status := make(chan error)
+
+	if kvs.logger != nil {
+		kvs.logger.Info("Acquiring process lock")
+	} else {
+		log.Printf("Acquiring process lock")
+	}
+
+	go kvs.lockUtil(status)
+
+	var err error
+	select {
+	case <-afterTime:
+		return ErrTimeoutLockingStore
+	case err = <-status:
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "processLock acquire error")
+	}
+
+	if kvs.logger != nil {
+		kvs.logger.Info("Acquired process lock with timeout value of", zap.Any("timeout", timeout))
+	} else {
+		log.Printf("Acquired process lock with timeout value of %v", timeout)
+	}
+
+	return nil
+}
+
+// Unlock closes the database and unlocks the store.
+func (kvs *boltStore) Unlock() error {
+	kvs.Mutex.Lock()
+	defer kvs.Mutex.Unlock()
+
+	if err := kvs.close(); err != nil {
+		return err
+	}
+
+	err := kvs.processLock.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "unlock error")
+	}
+
+	if kvs.logger != nil {
+		kvs.logger.Info("Released process lock")
+	} else {
+		log.Printf("Released process lock")
+	}
+
+	return nil
+}
+
+// GetModificationTime returns the modification time of the persistent store.
+func (kvs *boltStore) GetModificationTime() (time.Time, error) {
+	kvs.Mutex.Lock()
+	defer kvs.Mutex.Unlock()
+
+	info, err := os.Stat(kvs.fileName)
+	if err != nil {
+		if kvs.logger != nil {
+			kvs.logger.Info("os.stat() for file", zap.String("fileName", kvs.fileName), zap.Error(err))
+		} else {
+			log.Printf("os.stat() for file %v failed: %v", kvs.fileName, err)
+		}
+
+		return time.Time{}.UTC(), err
+	}
+
+	return info.ModTime().UTC(), nil
+}
+
+func (kvs *boltStore) Remove() {
+	kvs.Mutex.Lock()
+	if err := kvs.close(); err != nil {
+		log.Errorf("could not close file %s. Error: %v", kvs.fileName, err)
+	}
+	if err := os.Remove(kvs.fileName); err != nil {
+		log.Errorf("could not remove file %s. Error: %v", kvs.fileName, err)
+	}
+	kvs.Mutex.Unlock()
+}
+
+// MigrateJSONFileStore imports the key value pairs of a JSON file store into a new bolt database. The database is
+// built next to its final path and renamed into place, after which the JSON file is renamed with the
+// MigratedExtension so it is not used again. It returns false if there was nothing to migrate, either because
+// there is no JSON file or because the database already exists. The caller must hold the store lock.
+func MigrateJSONFileStore(jsonFileName, boltFileName string, logger *zap.Logger) (bool, error) {
+	if fileExists(boltFileName) {
+		// A previous migration may have stopped before retiring the JSON file.
+		if fileExists(jsonFileName) {
+			if err := os.Rename(jsonFileName, jsonFileName+MigratedExtension); err != nil {
+				return false, errors.Wrapf(err, "failed to retire migrated %s", jsonFileName)
+			}
+		}
+		return false, nil
+	}
+
+	if !fileExists(jsonFileName) {
+		return false, nil
+	}
+
+	// Read through the JSON store so a corrupted file is recovered from its generations.
+	src := &jsonFileStore{fileName: jsonFileName, generations: DefaultGenerations, logger: logger}
+	data, err := readJSONFile(jsonFileName)
+	if err != nil {
+		if data, err = src.recover(err); err != nil && !errors.Is(err, ErrStoreEmpty) {
+			return false, errors.Wrapf(err, "failed to read %s", jsonFileName)
+		}
+	}
+
+	tmpFileName := boltFileName + ".tmp"
+	_ = os.Remove(tmpFileName)
+
+	db, err := bolt.Open(tmpFileName, 0o644, &bolt.Options{Timeout: DefaultLockTimeout})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to create %s", tmpFileName)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(boltBucket)
+		if err != nil {
+			return errors.Wrap(err, "failed to create bucket")
+		}
+		for key, raw := range data {
+			if raw == nil {
+				continue
+			}
+			if err := bucket.Put([]byte(key), *raw); err != nil {
+				return errors.Wrapf(err, "failed to put %s", key)
+			}
+		}
+		return nil
+	})
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpFileName)
+		return false, errors.Wrapf(err, "failed to migrate %s", jsonFileName)
+	}
+
+	if err := os.Rename(tmpFileName, boltFileName); err != nil {
+		_ = os.Remove(tmpFileName)
+		return false, errors.Wrapf(err, "failed to rename %s", tmpFileName)
+	}
+
+	if err := os.Rename(jsonFileName, jsonFileName+MigratedExtension); err != nil {
+		return true, errors.Wrapf(err, "failed to retire migrated %s", jsonFileName)
+	}
+
+	if logger != nil {
+		logger.Info("Migrated store", zap.String("from", jsonFileName), zap.String("to", boltFileName), zap.Int("keys", len(data)))
+	} else {
+		log.Printf("Migrated store %s to %s with %d keys", jsonFileName, boltFileName, len(data))
+	}
+
+	return true, nil
+}
+
+// MigrateBoltStore exports the key value pairs of a bolt database to a JSON file store, with the records of each key
+// back in its value, so the state can be read by a version without bolt stores. The database is then renamed with
+// the MigratedExtension so it is not used again, and a later migration to a bolt store imports the JSON file anew.
+// It returns false if there is no database to migrate. The caller must hold the store lock.
+func MigrateBoltStore(boltFileName, jsonFileName string, logger *zap.Logger) (bool, error) {
+	if !fileExists(boltFileName) {
+		return false, nil
+	}
+
+	db, err := bolt.Open(boltFileName, 0o644, &bolt.Options{Timeout: DefaultLockTimeout, ReadOnly: true})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to open %s", boltFileName)
+	}
+
+	data := make(map[string]*json.RawMessage)
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, _ []byte) error {
+			raw, err := readBoltValue(tx, key)
+			if err != nil {
+				return err
+			}
+			value := json.RawMessage(raw)
+			data[string(key)] = &value
+			return nil
+		})
+	})
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to migrate %s", boltFileName)
+	}
+
+	// The database is the newest state, a JSON file left by an interrupted migration is replaced.
+	dst := &jsonFileStore{fileName: jsonFileName, generations: DefaultGenerations, data: data, logger: logger}
+	if err := dst.flush(); err != nil {
+		return false, errors.Wrapf(err, "failed to write %s", jsonFileName)
+	}
+
+	if err := os.Rename(boltFileName, boltFileName+MigratedExtension); err != nil {
+		return true, errors.Wrapf(err, "failed to retire migrated %s", boltFileName)
+	}
+
+	if logger != nil {
+		logger.Info("Migrated store", zap.String("from", boltFileName), zap.String("to", jsonFileName), zap.Int("keys", len(data)))
+	} else {
+		log.Printf("Migrated store %s to %s with %d keys", boltFileName, jsonFileName, len(data))
+	}
+
+	return true, nil
+}
+
+func fileExists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-container-networking/processlock"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that key value pairs are written to and read back from the database.
+func TestBoltStoreWriteRead(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+	kvs, err := NewBoltStore(fileName, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+
+	var value testType1
+	require.ErrorIs(t, kvs.Read(testKey1, &value), ErrKeyNotFound)
+	// reading an empty store does not create the database
+	require.False(t, kvs.Exists())
+
+	require.NoError(t, kvs.Write(testKey1, &testType1{"test", 42}))
+	require.NoError(t, kvs.Write(testKey2, &testType1{"any", 14}))
+	require.NoError(t, kvs.Flush())
+	require.True(t, kvs.Exists())
+
+	require.NoError(t, kvs.Read(testKey1, &value))
+	require.Equal(t, testType1{"test", 42}, value)
+	require.ErrorIs(t, kvs.Read("missing", &value), ErrKeyNotFound)
+
+	modTime, err := kvs.GetModificationTime()
+	require.NoError(t, err)
+	require.False(t, modTime.IsZero())
+
+	kvs.Remove()
+	require.False(t, kvs.Exists())
+}
+
+// Tests that unlocking the store closes the database so another process can open it.
+func TestBoltStoreLockUnlock(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.db")
+
+	first, err := NewBoltStore(fileName, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, first.Lock(time.Second))
+	require.NoError(t, first.Write(testKey1, &testType1{"first", 1}))
+	require.NoError(t, first.Unlock())
+
+	second, err := NewBoltStore(fileName, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, second.Lock(time.Second))
+	var value testType1
+	require.NoError(t, second.Read(testKey1, &value))
+	require.Equal(t, testType1{"first", 1}, value)
+	require.NoError(t, second.Unlock())
+
+	locked, err := NewBoltStore(fileName, processlock.NewMockFileLock(true), nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, locked.Lock(time.Second), processlock.ErrMockFileLock)
+}
+
+// Tests that a JSON store is migrated to a bolt store the first time it is opened.
+func TestNewStoreMigratesJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure-vnet")
+
+	jsonStore, err := NewStore(JSONStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, jsonStore.Write(testKey1, &testType1{"test", 42}))
+	require.NoError(t, jsonStore.Write(testKey2, &testType1{"any", 14}))
+
+	boltStore, err := NewStore(BoltStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, boltStore.Lock(time.Second))
+
+	var value testType1
+	require.NoError(t, boltStore.Read(testKey2, &value))
+	require.Equal(t, testType1{"any", 14}, value)
+	require.NoError(t, boltStore.Unlock())
+
+	// the JSON file is retired so it is not migrated twice
+	_, err = os.Stat(path + JSONExtension)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path + JSONExtension + MigratedExtension)
+	require.NoError(t, err)
+
+	migrated, err := MigrateJSONFileStore(path+JSONExtension, path+BoltExtension, nil)
+	require.NoError(t, err)
+	require.False(t, migrated)
+}
+
+// Tests that the migration recovers a corrupted JSON file from its generations.
+func TestMigrateJSONFileStoreRecovers(t *testing.T) {
+	dir := t.TempDir()
+	jsonFileName := filepath.Join(dir, "state.json")
+	boltFileName := filepath.Join(dir, "state.db")
+
+	jsonStore, err := NewJsonFileStore(jsonFileName, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, jsonStore.Write(testKey1, &testType1{"old", 1}))
+	require.NoError(t, jsonStore.Write(testKey1, &testType1{"new", 2}))
+	require.NoError(t, os.Truncate(jsonFileName, 10))
+
+	migrated, err := MigrateJSONFileStore(jsonFileName, boltFileName, nil)
+	require.NoError(t, err)
+	require.True(t, migrated)
+
+	boltStore, err := NewBoltStore(boltFileName, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	var value testType1
+	require.NoError(t, boltStore.Read(testKey1, &value))
+	require.Equal(t, testType1{"old", 1}, value)
+	boltStore.Remove()
+}
+
+// Tests that the bolt store keeps being used once the state is migrated when no store type is configured, e.g. by
+// GET_ENDPOINT_STATE which has no network config.
+func TestNewStoreKeepsMigratedBoltStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure-vnet")
+	require.Equal(t, JSONStoreType, ResolveStoreType("", path))
+	require.False(t, StateExists(path))
+
+	jsonStore, err := NewStore(JSONStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, jsonStore.Write(testKey1, &testType1{"test", 42}))
+	require.True(t, StateExists(path))
+
+	// the first command configured with a bolt store migrates the state
+	boltStore, err := NewStore(BoltStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, boltStore.Lock(time.Second))
+	var migrated testType1
+	require.NoError(t, boltStore.Read(testKey1, &migrated))
+	require.NoError(t, boltStore.Unlock())
+	require.True(t, StateExists(path))
+
+	require.Equal(t, BoltStoreType, ResolveStoreType("", path))
+	kvs, err := NewStore("", path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	var value testType1
+	require.NoError(t, kvs.Read(testKey1, &value))
+	require.Equal(t, testType1{"test", 42}, value)
+	require.NoError(t, kvs.Unlock())
+}
+
+// Tests that a bolt store is migrated back to a JSON store when the JSON store is configured, so the state can be
+// rolled back to a version without bolt stores, and that it can be migrated to a bolt store again.
+func TestNewStoreMigratesBoltStoreBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure-vnet")
+
+	boltStore, err := NewStore(BoltStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, boltStore.Write(testKey1, &testType1{"test", 42}))
+	require.NoError(t, boltStore.(RecordStore).WriteRecords(testKey2, []byte(`{"Endpoints":{}}`),
+		map[string]json.RawMessage{"Endpoints/ep1": []byte(`{"Id":"ep1"}`)}))
+	require.NoError(t, boltStore.Unlock())
+
+	jsonStore, err := NewStore(JSONStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	var value testType1
+	require.NoError(t, jsonStore.Read(testKey1, &value))
+	require.Equal(t, testType1{"test", 42}, value)
+	var withRecords map[string]map[string]map[string]string
+	require.NoError(t, jsonStore.Read(testKey2, &withRecords))
+	require.Equal(t, map[string]map[string]map[string]string{"Endpoints": {"ep1": {"Id": "ep1"}}}, withRecords)
+
+	// the database is retired, so the state is read from the JSON file by a version without bolt stores
+	require.False(t, fileExists(path+BoltExtension))
+	require.True(t, fileExists(path+BoltExtension+MigratedExtension))
+	require.Equal(t, JSONStoreType, ResolveStoreType("", path))
+	data, err := readJSONFile(path + JSONExtension)
+	require.NoError(t, err)
+	require.Contains(t, data, testKey1)
+
+	migrated, err := MigrateBoltStore(path+BoltExtension, path+JSONExtension, nil)
+	require.NoError(t, err)
+	require.False(t, migrated)
+
+	boltStore, err = NewStore(BoltStoreType, path, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.NoError(t, boltStore.Read(testKey1, &value))
+	require.Equal(t, testType1{"test", 42}, value)
+	require.NoError(t, boltStore.Unlock())
+}
+
+// Tests that the records of a key are put back in its value, and that only the given records are kept.
+func TestBoltStoreWriteRecords(t *testing.T) {
+	kvs, err := NewBoltStore(filepath.Join(t.TempDir(), "test.db"), processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	rs := kvs.(RecordStore)
+
+	type state struct {
+		Name      string
+		Endpoints map[string]testType1
+	}
+
+	require.NoError(t, rs.WriteRecords(testKey1, []byte(`{"Name":"azure","Endpoints":{}}`), map[string]json.RawMessage{
+		"Endpoints/ep1": []byte(`{"Field1":"one","Field2":1}`),
+		"Endpoints/ep2": []byte(`{"Field1":"two","Field2":2}`),
+	}))
+	var value state
+	require.NoError(t, kvs.Read(testKey1, &value))
+	require.Equal(t, state{"azure", map[string]testType1{"ep1": {"one", 1}, "ep2": {"two", 2}}}, value)
+
+	require.NoError(t, rs.WriteRecords(testKey1, []byte(`{"Name":"azure","Endpoints":{}}`), map[string]json.RawMessage{
+		"Endpoints/ep2": []byte(`{"Field1":"two","Field2":2}`),
+	}))
+	value = state{}
+	require.NoError(t, kvs.Read(testKey1, &value))
+	require.Equal(t, state{"azure", map[string]testType1{"ep2": {"two", 2}}}, value)
+
+	// a whole value replaces the records
+	require.NoError(t, kvs.Write(testKey1, &state{Name: "azure"}))
+	value = state{}
+	require.NoError(t, kvs.Read(testKey1, &value))
+	require.Equal(t, state{Name: "azure"}, value)
+	require.NoError(t, kvs.Unlock())
+}
+
+func TestNewStoreUnknownType(t *testing.T) {
+	_, err := NewStore("sqlite", filepath.Join(t.TempDir(), "state"), processlock.NewMockFileLock(false), nil)
+	require.ErrorIs(t, err, ErrUnknownStoreType)
+}
@@ -4,6 +4,8 @@
 package store
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,13 +29,31 @@ const (
 	DefaultLockTimeout        = 10000 * time.Millisecond
 	DefaultLockTimeoutLinux   = 30000 * time.Millisecond
 	DefaultLockTimeoutWindows = 60000 * time.Millisecond
+
+	// DefaultGenerations - number of previous versions of the file kept to recover from a corrupted write.
+	DefaultGenerations = 2
+
+	// headerKey is the reserved key of the header holding the checksum of the other keys. It sorts before
+	// every key used by the stores so the header is at the top of the file.
+	headerKey = "$header"
+	// headerVersion is the version of the header format.
+	headerVersion = 1
 )
 
+// fileHeader is written at the top of the file so a truncated or partially written file is detected.
+type fileHeader struct {
+	Version  int    `json:"version"`
+	Checksum string `json:"checksum"`
+}
+
 // jsonFileStore is an implementation of KeyValueStore using a local JSON file.
 type jsonFileStore struct {
 	fileName    string
 	data        map[string]*json.RawMessage
 	inSync      bool
+	generations int
+	// migrateFrom is the bolt database whose keys are exported the first time the file is used.
+	migrateFrom string
 	processLock processlock.Interface
 	sync.Mutex
 	logger *zap.Logger
@@ -49,6 +69,7 @@ func NewJsonFileStore(fileName string, lockclient processlock.Interface, logger
 	kvs := &jsonFileStore{
 		fileName:    fileName,
 		processLock: lockclient,
+		generations: DefaultGenerations,
 		data:        make(map[string]*json.RawMessage),
 		logger:      logger,
 	}
@@ -68,38 +89,26 @@ func (kvs *jsonFileStore) Read(key string, value interface{}) error {
 	kvs.Mutex.Lock()
 	defer kvs.Mutex.Unlock()
 
+	if err := kvs.migrate(); err != nil {
+		return err
+	}
+
 	// Read contents from file if memory is not in sync.
 	if !kvs.inSync {
-		// Open and parse the file if it exists.
-		file, err := os.Open(kvs.fileName)
+		data, err := readJSONFile(kvs.fileName)
 		if err != nil {
 			if os.IsNotExist(err) {
 				return ErrKeyNotFound
 			}
-			return err
-		}
-		defer file.Close()
-
-		b, err := io.ReadAll(file)
-		if err != nil {
-			return err
-		}
 
-		if len(b) == 0 {
-			if kvs.logger != nil {
-				kvs.logger.Info("Unable to read empty file", zap.String("fileName", kvs.fileName))
-			} else {
-				log.Printf("Unable to read file %s, was empty", kvs.fileName)
+			// The file is empty or corrupted, fall back to the newest valid generation.
+			data, err = kvs.recover(err)
+			if err != nil {
+				return err
 			}
-
-			return ErrStoreEmpty
-		}
-
-		// Decode to raw JSON messages.
-		if err := json.Unmarshal(b, &kvs.data); err != nil {
-			return err
 		}
 
+		kvs.data = data
 		kvs.inSync = true
 	}
 
@@ -122,11 +131,33 @@ func (kvs *jsonFileStore) Write(key string, value interface{}) error {
 		return err
 	}
 
+	if key == headerKey {
+		return errors.Errorf("%s is a reserved key", headerKey)
+	}
+
+	if err := kvs.migrate(); err != nil {
+		return err
+	}
+
 	kvs.data[key] = &raw
 
 	return kvs.flush()
 }
 
+// migrate exports the bolt database the state was migrated to, if any, before the file is first used.
+func (kvs *jsonFileStore) migrate() error {
+	if kvs.migrateFrom == "" {
+		return nil
+	}
+
+	if _, err := MigrateBoltStore(kvs.migrateFrom, kvs.fileName, kvs.logger); err != nil {
+		return err
+	}
+
+	kvs.migrateFrom = ""
+	return nil
+}
+
 // Flush commits in-memory state to persistent store.
 func (kvs *jsonFileStore) Flush() error {
 	kvs.Mutex.Lock()
@@ -137,7 +168,7 @@ func (kvs *jsonFileStore) Flush() error {
 
 // Lock-free flush for internal callers.
 func (kvs *jsonFileStore) flush() error {
-	buf, err := json.MarshalIndent(&kvs.data, "", "\t")
+	buf, err := marshalWithHeader(kvs.data)
 	if err != nil {
 		return err
 	}
@@ -167,18 +198,182 @@ func (kvs *jsonFileStore) flush() error {
 		return fmt.Errorf("Temp file write failed with: %v", err)
 	}
 
+	// make sure the contents are on disk before the rename makes them visible
+	if err = f.Sync(); err != nil {
+		return fmt.Errorf("temp file sync failed with: %v", err)
+	}
+
 	if err = f.Close(); err != nil {
 		return fmt.Errorf("temp file close failed with: %v", err)
 	}
 
+	kvs.rotate()
+
 	// atomic replace
 	if err = platform.ReplaceFile(tmpFileName, kvs.fileName); err != nil {
 		return fmt.Errorf("rename temp file to state file failed:%v", err)
 	}
 
+	syncDir(dir)
+
 	return nil
 }
 
+// generationFileName returns the name of the nth previous version of the file.
+func (kvs *jsonFileStore) generationFileName(n int) string {
+	return fmt.Sprintf("%s.%d", kvs.fileName, n)
+}
+
+// rotate shifts the previous versions of the file and keeps the current one as the newest generation.
+// Failures are logged and ignored as the generations are only used to recover from a corrupted file.
+func (kvs *jsonFileStore) rotate() {
+	if kvs.generations <= 0 {
+		return
+	}
+
+	if _, err := os.Stat(kvs.fileName); err != nil {
+		return
+	}
+
+	for n := kvs.generations - 1; n > 0; n-- {
+		if err := os.Rename(kvs.generationFileName(n), kvs.generationFileName(n+1)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to rotate generation %d of %s: %v", n, kvs.fileName, err)
+		}
+	}
+
+	// Hard link the current version so the file itself is never missing.
+	newest := kvs.generationFileName(1)
+	_ = os.Remove(newest)
+	if err := os.Link(kvs.fileName, newest); err != nil {
+		log.Printf("Failed to keep generation of %s: %v", kvs.fileName, err)
+	}
+}
+
+// recover reads the newest valid generation of the file. It returns readErr, the error the file itself
+// failed with, if no generation can be read.
+func (kvs *jsonFileStore) recover(readErr error) (map[string]*json.RawMessage, error) {
+	for n := 1; n <= kvs.generations; n++ {
+		name := kvs.generationFileName(n)
+		data, err := readJSONFile(name)
+		if err != nil {
+			continue
+		}
+
+		if kvs.logger != nil {
+			kvs.logger.Info("Recovered store from previous generation",
+				zap.String("fileName", kvs.fileName), zap.String("generation", name), zap.Error(readErr))
+		} else {
+			log.Printf("Recovered store %s from previous generation %s, err:%v", kvs.fileName, name, readErr)
+		}
+
+		return data, nil
+	}
+
+	if errors.Is(readErr, ErrStoreEmpty) {
+		if kvs.logger != nil {
+			kvs.logger.Info("Unable to read empty file", zap.String("fileName", kvs.fileName))
+		} else {
+			log.Printf("Unable to read file %s, was empty", kvs.fileName)
+		}
+	}
+
+	return nil, readErr
+}
+
+// readJSONFile reads and decodes a file, verifying its checksum. Files written before the header was
+// introduced have no checksum and are accepted as is.
+func readJSONFile(name string) (map[string]*json.RawMessage, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, ErrStoreEmpty
+	}
+
+	// Decode to raw JSON messages.
+	data := make(map[string]*json.RawMessage)
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	raw, ok := data[headerKey]
+	if !ok {
+		return data, nil
+	}
+	delete(data, headerKey)
+
+	var header fileHeader
+	if raw == nil {
+		return nil, errors.Wrapf(ErrStoreCorrupted, "missing header in %s", name)
+	}
+	if err := json.Unmarshal(*raw, &header); err != nil {
+		return nil, errors.Wrapf(ErrStoreCorrupted, "invalid header in %s: %v", name, err)
+	}
+
+	checksum, err := checksumOf(data)
+	if err != nil {
+		return nil, err
+	}
+	if header.Checksum != checksum {
+		return nil, errors.Wrapf(ErrStoreCorrupted, "%s has checksum %s, expected %s", name, checksum, header.Checksum)
+	}
+
+	return data, nil
+}
+
+// marshalWithHeader encodes the key value pairs along with the header holding their checksum.
+func marshalWithHeader(data map[string]*json.RawMessage) ([]byte, error) {
+	checksum, err := checksumOf(data)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(fileHeader{Version: headerVersion, Checksum: checksum})
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string]*json.RawMessage, len(data)+1)
+	for k, v := range data {
+		content[k] = v
+	}
+	raw := json.RawMessage(header)
+	content[headerKey] = &raw
+
+	return json.MarshalIndent(&content, "", "\t")
+}
+
+// checksumOf returns the checksum of the compact encoding of the key value pairs, which does not depend on
+// the indentation of the file.
+func checksumOf(data map[string]*json.RawMessage) (string, error) {
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// syncDir flushes the directory entry of a renamed file to disk. It is best effort as directories cannot be
+// synced on every platform.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
+}
+
 func (kvs *jsonFileStore) lockUtil(status chan error) {
 	err := kvs.processLock.Lock()
 	status <- err
@@ -263,5 +458,11 @@ func (kvs *jsonFileStore) Remove() {
 	if err := os.Remove(kvs.fileName); err != nil {
 		log.Errorf("could not remove file %s. Error: %v", kvs.fileName, err)
 	}
+	// remove the generations too so they are not recovered from
+	for n := 1; n <= kvs.generations; n++ {
+		if err := os.Remove(kvs.generationFileName(n)); err != nil && !os.IsNotExist(err) {
+			log.Errorf("could not remove file %s. Error: %v", kvs.generationFileName(n), err)
+		}
+	}
 	kvs.Mutex.Unlock()
 }
@@ -4,7 +4,10 @@
 package store
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -87,25 +90,31 @@ func TestKeyValuePairsArePersistedToJSONFile(t *testing.T) {
 	}
 
 	// Read the persisted file contents.
-	file, err := os.Open(testFileName)
-	if err != nil {
-		t.Fatalf("Failed to open file %v", err)
-	}
-
-	data := make([]byte, 100)
-	n, err := file.Read(data)
+	data, err := os.ReadFile(testFileName)
 	if err != nil {
 		t.Fatalf("Failed to read from file %v", err)
 	}
 
-	file.Close()
 	os.Remove(testFileName)
+	os.Remove(testFileName + ".1")
 
-	// Remove indentation to normalize the JSON encoding.
-	actualPair = string(data[:n])
-	actualPair = strings.Replace(actualPair, " ", "", -1)
-	actualPair = strings.Replace(actualPair, "\t", "", -1)
-	actualPair = strings.Replace(actualPair, "\n", "", -1)
+	// The checksum header is at the top of the file.
+	if !strings.HasPrefix(string(data), "{\n\t\"$header\"") {
+		t.Fatalf("File does not start with the header: %s", data)
+	}
+
+	var pairs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		t.Fatalf("Failed to decode file %v", err)
+	}
+	delete(pairs, headerKey)
+
+	// Re-encode compactly to normalize the JSON encoding.
+	b, err := json.Marshal(pairs)
+	if err != nil {
+		t.Fatalf("Failed to encode pairs %v", err)
+	}
+	actualPair = string(b)
 
 	// Fail if the contents do not match expected JSON encoding.
 	if actualPair != expectedPair {
@@ -155,6 +164,8 @@ func TestKeyValuePairsAreWrittenAndReadCorrectly(t *testing.T) {
 
 	// Cleanup.
 	os.Remove(testFileName)
+	os.Remove(testFileName + ".1")
+	os.Remove(testFileName + ".2")
 }
 
 // test case for testing newjsonfilestore idempotent
@@ -237,3 +248,106 @@ func TestFileName(t *testing.T) {
 		t.Fatalf("This should not fail for a non-empty file %v", err)
 	}
 }
+
+// Tests that a corrupted or truncated file is detected and the newest valid generation is recovered.
+func TestRecoverFromGenerations(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, fileName string)
+	}{
+		{
+			name: "truncated file",
+			corrupt: func(t *testing.T, fileName string) {
+				require.NoError(t, os.Truncate(fileName, 20))
+			},
+		},
+		{
+			name: "empty file",
+			corrupt: func(t *testing.T, fileName string) {
+				require.NoError(t, os.Truncate(fileName, 0))
+			},
+		},
+		{
+			name: "checksum mismatch",
+			corrupt: func(t *testing.T, fileName string) {
+				b, err := os.ReadFile(fileName)
+				require.NoError(t, err)
+				b = []byte(strings.Replace(string(b), `"third"`, `"other"`, 1))
+				require.NoError(t, os.WriteFile(fileName, b, 0o600))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), testFileName)
+			kvs, err := NewJsonFileStore(fileName, processlock.NewMockFileLock(false), nil)
+			require.NoError(t, err)
+
+			require.NoError(t, kvs.Write(testKey1, &testType1{"first", 1}))
+			require.NoError(t, kvs.Write(testKey1, &testType1{"second", 2}))
+			require.NoError(t, kvs.Write(testKey1, &testType1{"third", 3}))
+
+			tt.corrupt(t, fileName)
+
+			kvs, err = NewJsonFileStore(fileName, processlock.NewMockFileLock(false), nil)
+			require.NoError(t, err)
+
+			var value testType1
+			require.NoError(t, kvs.Read(testKey1, &value))
+			require.Equal(t, testType1{"second", 2}, value)
+		})
+	}
+}
+
+// Tests that only the configured number of generations is kept and that they are removed with the store.
+func TestGenerations(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), testFileName)
+	kvs, err := NewJsonFileStore(fileName, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+
+	for i := 0; i < DefaultGenerations+2; i++ {
+		require.NoError(t, kvs.Write(testKey1, &testType1{"value", i}))
+	}
+
+	for n := 1; n <= DefaultGenerations; n++ {
+		_, err := readJSONFile(fmt.Sprintf("%s.%d", fileName, n))
+		require.NoError(t, err)
+	}
+	_, err = os.Stat(fmt.Sprintf("%s.%d", fileName, DefaultGenerations+1))
+	require.True(t, os.IsNotExist(err))
+
+	kvs.Remove()
+	matches, err := filepath.Glob(fileName + "*")
+	require.NoError(t, err)
+	require.Empty(t, matches)
+}
+
+// Tests the errors returned when neither the file nor a generation can be read.
+func TestReadCorruptedStore(t *testing.T) {
+	dir := t.TempDir()
+
+	// a missing file is not recovered from generations as it is deleted on purpose, e.g. on reboot
+	missing := filepath.Join(dir, "missing.json")
+	require.NoError(t, os.WriteFile(missing+".1", []byte(`{"key1":{"Field1":"stale","Field2":1}}`), 0o600))
+	kvs, err := NewJsonFileStore(missing, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	var value testType1
+	require.ErrorIs(t, kvs.Read(testKey1, &value), ErrKeyNotFound)
+
+	empty := filepath.Join(dir, "empty.json")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	kvs, err = NewJsonFileStore(empty, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, kvs.Read(testKey1, &value), ErrStoreEmpty)
+
+	corrupted := filepath.Join(dir, "corrupted.json")
+	content := `{"$header":{"version":1,"checksum":"sha256:0000"},"key1":{"Field1":"test","Field2":42}}`
+	require.NoError(t, os.WriteFile(corrupted, []byte(content), 0o600))
+	kvs, err = NewJsonFileStore(corrupted, processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, kvs.Read(testKey1, &value), ErrStoreCorrupted)
+
+	require.Error(t, kvs.Write(headerKey, &value))
+}
@@ -0,0 +1,243 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaVersionField is the field added to a persisted object to record the version of its schema.
+// Objects persisted before their component declared a schema have no such field and are at version 0.
+const SchemaVersionField = "$schemaVersion"
+
+var (
+	// ErrSchemaTooNew is returned when the persisted state was written by a newer binary. It must be
+	// downgraded by that binary before an older one can use it, or fields the older one does not know
+	// would be lost.
+	ErrSchemaTooNew = fmt.Errorf("state schema version is newer than supported")
+	// ErrInvalidSchema is returned when the migrations of a schema are not well formed.
+	ErrInvalidSchema = fmt.Errorf("invalid schema")
+	// ErrInvalidSchemaVersion is returned when a persisted object is not a versioned JSON object
+	// or a downgrade targets a version that does not exist.
+	ErrInvalidSchemaVersion = fmt.Errorf("invalid schema version")
+)
+
+// State is the raw form of a persisted object that migrations operate on, keyed by its top level fields.
+type State map[string]json.RawMessage
+
+// Migration upgrades a persisted object from the previous version of its schema to Version,
+// and downgrades it back.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(State) error
+	Down        func(State) error
+}
+
+// Schema is the ordered list of migrations of an object persisted in a KeyValueStore.
+// Its current version is the version of its last migration.
+type Schema struct {
+	name       string
+	migrations []Migration
+	// records is the pattern of the parts of the object kept as records of their own in a RecordStore.
+	records string
+}
+
+// NewSchema creates the schema of an object from its migrations, which must be numbered from 1
+// without gaps and provide both an upgrade and a downgrade.
+func NewSchema(name string, migrations ...Migration) (*Schema, error) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, errors.Wrapf(ErrInvalidSchema, "%s: migration %d has version %d", name, i+1, m.Version)
+		}
+		if m.Up == nil || m.Down == nil {
+			return nil, errors.Wrapf(ErrInvalidSchema, "%s: migration %d is not reversible", name, m.Version)
+		}
+	}
+
+	return &Schema{name: name, migrations: migrations}, nil
+}
+
+// MustNewSchema is like NewSchema but panics if the migrations are not well formed.
+// It is meant for the package level declaration of the schema of a component.
+func MustNewSchema(name string, migrations ...Migration) *Schema {
+	s, err := NewSchema(name, migrations...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// WithRecords keeps the parts of the object matching pattern as records of their own when it is written to a
+// RecordStore, so only those that changed are written. The pattern is a path of fields separated by slashes,
+// where * matches any field, e.g. "Networks/*/Endpoints/*".
+func (s *Schema) WithRecords(pattern string) *Schema {
+	s.records = pattern
+	return s
+}
+
+// Version returns the current version of the schema.
+func (s *Schema) Version() int {
+	return len(s.migrations)
+}
+
+// Read reads the object persisted under key into value, upgrading it to the current version first.
+// Errors of the store are returned unwrapped. An object written by a newer version is refused with
+// ErrSchemaTooNew.
+func (s *Schema) Read(kvs KeyValueStore, key string, value interface{}) error {
+	var raw json.RawMessage
+	if err := kvs.Read(key, &raw); err != nil {
+		return err //nolint:wrapcheck // callers compare the errors of the store
+	}
+
+	// Nothing was read.
+	if len(raw) == 0 {
+		return nil
+	}
+
+	state, version, err := s.decode(raw)
+	if err != nil {
+		return err
+	}
+
+	if version > s.Version() {
+		return errors.Wrapf(ErrSchemaTooNew, "%s: persisted version %d, supported up to %d", s.name, version, s.Version())
+	}
+
+	if err = s.migrate(state, version, s.Version()); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to encode migrated state", s.name)
+	}
+
+	return errors.Wrapf(json.Unmarshal(b, value), "%s: failed to decode migrated state", s.name)
+}
+
+// Write writes value under key, tagged with the current version of the schema.
+func (s *Schema) Write(kvs KeyValueStore, key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to encode state", s.name)
+	}
+
+	var state State
+	if err = json.Unmarshal(b, &state); err != nil || state == nil {
+		return errors.Wrapf(ErrInvalidSchemaVersion, "%s: state is not a JSON object", s.name)
+	}
+
+	return s.write(kvs, key, state, s.Version())
+}
+
+// Downgrade rewrites the object persisted under key at the given older version of the schema, so that
+// a binary supporting only that version can read it without losing fields. Version 0 is the format
+// used before the schema was declared. An object already at or below that version is left as is.
+func (s *Schema) Downgrade(kvs KeyValueStore, key string, version int) error {
+	var raw json.RawMessage
+	if err := kvs.Read(key, &raw); err != nil {
+		return err //nolint:wrapcheck // callers compare the errors of the store
+	}
+
+	state, from, err := s.decode(raw)
+	if err != nil {
+		return err
+	}
+
+	if from > s.Version() {
+		return errors.Wrapf(ErrSchemaTooNew, "%s: persisted version %d, supported up to %d", s.name, from, s.Version())
+	}
+
+	if version < 0 || version > s.Version() {
+		return errors.Wrapf(ErrInvalidSchemaVersion, "%s: cannot downgrade from version %d to %d", s.name, from, version)
+	}
+
+	// The object is already readable at that version.
+	if version >= from {
+		return nil
+	}
+
+	if err = s.migrate(state, from, version); err != nil {
+		return err
+	}
+
+	return s.write(kvs, key, state, version)
+}
+
+// decode splits a persisted object into its state and schema version.
+func (s *Schema) decode(raw json.RawMessage) (State, int, error) {
+	var state State
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, 0, errors.Wrapf(ErrInvalidSchemaVersion, "%s: state is not a JSON object: %v", s.name, err)
+	}
+
+	if state == nil {
+		state = State{}
+	}
+
+	version := 0
+	if v, ok := state[SchemaVersionField]; ok {
+		if err := json.Unmarshal(v, &version); err != nil || version < 0 {
+			return nil, 0, errors.Wrapf(ErrInvalidSchemaVersion, "%s: %s", s.name, v)
+		}
+		delete(state, SchemaVersionField)
+	}
+
+	return state, version, nil
+}
+
+// write persists the state tagged with the given version. Version 0 is left untagged.
+func (s *Schema) write(kvs KeyValueStore, key string, state State, version int) error {
+	delete(state, SchemaVersionField)
+	if version > 0 {
+		v, err := json.Marshal(version)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to encode version", s.name)
+		}
+		state[SchemaVersionField] = v
+	}
+
+	if rs, ok := kvs.(RecordStore); ok && s.records != "" {
+		b, err := json.Marshal(state)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to encode state", s.name)
+		}
+		value, records, err := splitRecords(b, s.records)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to split records", s.name)
+		}
+		return rs.WriteRecords(key, value, records) //nolint:wrapcheck // callers compare the errors of the store
+	}
+
+	return kvs.Write(key, state) //nolint:wrapcheck // callers compare the errors of the store
+}
+
+// migrate applies the upgrades or downgrades needed to take the state from one version to another.
+func (s *Schema) migrate(state State, from, to int) error {
+	for v := from + 1; v <= to; v++ {
+		m := s.migrations[v-1]
+		if err := m.Up(state); err != nil {
+			return errors.Wrapf(err, "%s: failed to upgrade to version %d (%s)", s.name, v, m.Description)
+		}
+	}
+
+	for v := from; v > to; v-- {
+		m := s.migrations[v-1]
+		if err := m.Down(state); err != nil {
+			return errors.Wrapf(err, "%s: failed to downgrade from version %d (%s)", s.name, v, m.Description)
+		}
+	}
+
+	return nil
+}
+
+// NoopMigration is the upgrade and downgrade of a migration that only bumps the version, e.g. to
+// start versioning a component or to mark a change readable by both versions.
+func NoopMigration(State) error {
+	return nil
+}
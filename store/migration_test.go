@@ -0,0 +1,180 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+package store
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/processlock"
+	"github.com/stretchr/testify/require"
+)
+
+// Version 2 of the test type, which renamed Field1 to Name and added Labels.
+type testTypeV2 struct {
+	Name   string
+	Field2 int
+	Labels map[string]string `json:",omitempty"`
+}
+
+func renameField(from, to string) func(State) error {
+	return func(state State) error {
+		if v, ok := state[from]; ok {
+			state[to] = v
+			delete(state, from)
+		}
+		return nil
+	}
+}
+
+var testSchema = MustNewSchema("test",
+	Migration{
+		Version:     1,
+		Description: "start versioning",
+		Up:          NoopMigration,
+		Down:        NoopMigration,
+	},
+	Migration{
+		Version:     2,
+		Description: "rename Field1 to Name",
+		Up:          renameField("Field1", "Name"),
+		Down:        renameField("Name", "Field1"),
+	},
+)
+
+func TestNewSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    bool
+	}{
+		{
+			name: "no migrations",
+		},
+		{
+			name: "ordered migrations",
+			migrations: []Migration{
+				{Version: 1, Up: NoopMigration, Down: NoopMigration},
+				{Version: 2, Up: NoopMigration, Down: NoopMigration},
+			},
+		},
+		{
+			name: "gap in versions",
+			migrations: []Migration{
+				{Version: 1, Up: NoopMigration, Down: NoopMigration},
+				{Version: 3, Up: NoopMigration, Down: NoopMigration},
+			},
+			wantErr: true,
+		},
+		{
+			name: "out of order",
+			migrations: []Migration{
+				{Version: 2, Up: NoopMigration, Down: NoopMigration},
+				{Version: 1, Up: NoopMigration, Down: NoopMigration},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "missing downgrade",
+			migrations: []Migration{{Version: 1, Up: NoopMigration}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSchema(tt.name, tt.migrations...)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSchema)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tt.migrations), s.Version())
+		})
+	}
+}
+
+// Tests that unversioned state is upgraded on read and written back at the current version.
+func TestSchemaUpgrade(t *testing.T) {
+	kvs := NewMockStore("")
+	require.NoError(t, kvs.Write(testKey1, &testType1{"test", 42}))
+
+	var value testTypeV2
+	require.NoError(t, testSchema.Read(kvs, testKey1, &value))
+	require.Equal(t, testTypeV2{Name: "test", Field2: 42}, value)
+
+	require.NoError(t, testSchema.Write(kvs, testKey1, &value))
+
+	var state State
+	require.NoError(t, kvs.Read(testKey1, &state))
+	require.JSONEq(t, "2", string(state[SchemaVersionField]))
+	require.JSONEq(t, `"test"`, string(state["Name"]))
+}
+
+// Tests that state written by a newer binary is refused and left untouched.
+func TestSchemaTooNew(t *testing.T) {
+	kvs := NewMockStore("")
+	newer := State{SchemaVersionField: json.RawMessage("3"), "Name": json.RawMessage(`"test"`)}
+	require.NoError(t, kvs.Write(testKey1, newer))
+
+	var value testTypeV2
+	require.ErrorIs(t, testSchema.Read(kvs, testKey1, &value), ErrSchemaTooNew)
+	require.ErrorIs(t, testSchema.Downgrade(kvs, testKey1, 1), ErrSchemaTooNew)
+
+	var state State
+	require.NoError(t, kvs.Read(testKey1, &state))
+	require.Equal(t, newer, state)
+}
+
+// Tests that state is downgraded explicitly and read back by an older schema without losing fields.
+func TestSchemaDowngrade(t *testing.T) {
+	kvs, err := NewJsonFileStore(filepath.Join(t.TempDir(), testFileName), processlock.NewMockFileLock(false), nil)
+	require.NoError(t, err)
+
+	written := testTypeV2{Name: "test", Field2: 42, Labels: map[string]string{"k": "v"}}
+	require.NoError(t, testSchema.Write(kvs, testKey1, &written))
+
+	require.ErrorIs(t, testSchema.Downgrade(kvs, testKey1, 3), ErrInvalidSchemaVersion)
+	require.ErrorIs(t, testSchema.Downgrade(kvs, testKey1, -1), ErrInvalidSchemaVersion)
+
+	// Version 1 is readable by the binary that only knows the first migration.
+	require.NoError(t, testSchema.Downgrade(kvs, testKey1, 1))
+	v1Schema := MustNewSchema("test", testSchema.migrations[:1]...)
+	var v1 testType1
+	require.NoError(t, v1Schema.Read(kvs, testKey1, &v1))
+	require.Equal(t, testType1{"test", 42}, v1)
+
+	// Version 0 drops the version field for binaries that predate the schema.
+	require.NoError(t, testSchema.Downgrade(kvs, testKey1, 0))
+	var state State
+	require.NoError(t, kvs.Read(testKey1, &state))
+	require.NotContains(t, state, SchemaVersionField)
+	require.JSONEq(t, `{"k":"v"}`, string(state["Labels"]))
+
+	// State already older than the target is left as is.
+	require.NoError(t, testSchema.Downgrade(kvs, testKey1, 1))
+	require.NoError(t, kvs.Read(testKey1, &state))
+	require.NotContains(t, state, SchemaVersionField)
+
+	// Upgrading again restores the original value.
+	var value testTypeV2
+	require.NoError(t, testSchema.Read(kvs, testKey1, &value))
+	require.Equal(t, written, value)
+}
+
+func TestSchemaInvalidState(t *testing.T) {
+	kvs := NewMockStore("")
+
+	var value testTypeV2
+	require.ErrorIs(t, testSchema.Read(kvs, testKey1, &value), ErrStoreEmpty)
+
+	require.NoError(t, kvs.Write(testKey1, []int{1}))
+	require.ErrorIs(t, testSchema.Read(kvs, testKey1, &value), ErrInvalidSchemaVersion)
+	require.ErrorIs(t, testSchema.Write(kvs, testKey1, []int{1}), ErrInvalidSchemaVersion)
+
+	require.NoError(t, kvs.Write(testKey1, State{SchemaVersionField: json.RawMessage(`"1"`)}))
+	require.ErrorIs(t, testSchema.Read(kvs, testKey1, &value), ErrInvalidSchemaVersion)
+}
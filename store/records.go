@@ -0,0 +1,108 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+package store
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// recordPathSeparator separates the fields of the path of a record in its value.
+const recordPathSeparator = "/"
+
+// The separator is escaped in the fields of a record path as in a JSON pointer.
+var (
+	recordFieldEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	recordFieldUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// splitRecords takes the records matching pattern out of a JSON object. The pattern is a path of fields separated
+// by slashes, where * matches any field, e.g. "Networks/*/Endpoints/*". It returns the object without the records
+// and the records by their path in the object.
+func splitRecords(value json.RawMessage, pattern string) (json.RawMessage, map[string]json.RawMessage, error) {
+	records := make(map[string]json.RawMessage)
+	value, err := split(value, strings.Split(pattern, recordPathSeparator), "", records)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, records, nil
+}
+
+func split(value json.RawMessage, pattern []string, prefix string, records map[string]json.RawMessage) (json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(value, &obj); err != nil || obj == nil {
+		// Only an object has fields to split, e.g. a nil map has no records.
+		return value, nil
+	}
+
+	for field, child := range obj {
+		if pattern[0] != "*" && pattern[0] != field {
+			continue
+		}
+
+		path := recordFieldEscaper.Replace(field)
+		if prefix != "" {
+			path = prefix + recordPathSeparator + path
+		}
+
+		if len(pattern) == 1 {
+			records[path] = child
+			delete(obj, field)
+			continue
+		}
+
+		child, err := split(child, pattern[1:], path, records)
+		if err != nil {
+			return nil, err
+		}
+		obj[field] = child
+	}
+
+	b, err := json.Marshal(obj)
+	return b, errors.Wrap(err, "failed to encode object")
+}
+
+// joinRecords puts records split by splitRecords back at their path in the object.
+func joinRecords(value json.RawMessage, records map[string]json.RawMessage) (json.RawMessage, error) {
+	if len(records) == 0 {
+		return value, nil
+	}
+
+	obj := make(map[string]json.RawMessage)
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return nil, errors.Wrap(err, "records of a value which is not an object")
+		}
+		if obj == nil {
+			obj = make(map[string]json.RawMessage)
+		}
+	}
+
+	children := make(map[string]map[string]json.RawMessage)
+	for path, record := range records {
+		field, rest, nested := strings.Cut(path, recordPathSeparator)
+		field = recordFieldUnescaper.Replace(field)
+		if !nested {
+			obj[field] = record
+			continue
+		}
+		if children[field] == nil {
+			children[field] = make(map[string]json.RawMessage)
+		}
+		children[field][rest] = record
+	}
+
+	for field, childRecords := range children {
+		child, err := joinRecords(obj[field], childRecords)
+		if err != nil {
+			return nil, err
+		}
+		obj[field] = child
+	}
+
+	b, err := json.Marshal(obj)
+	return b, errors.Wrap(err, "failed to encode object")
+}
@@ -0,0 +1,30 @@
+// Copyright 2017 Microsoft. All rights reserved.
+// MIT License
+
+package store
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that records are taken out of a value by their path and put back in place.
+func TestSplitJoinRecords(t *testing.T) {
+	value := json.RawMessage(`{"$schemaVersion":1,"Networks":{"azure":{"Id":"azure","Endpoints":{"ep/1":{"Id":"ep/1"},` +
+		`"ep2":{"Id":"ep2"}}},"empty":{"Id":"empty","Endpoints":null}}}`)
+
+	skeleton, records, err := splitRecords(value, "Networks/*/Endpoints/*")
+	require.NoError(t, err)
+	require.Equal(t, map[string]json.RawMessage{
+		"Networks/azure/Endpoints/ep~11": json.RawMessage(`{"Id":"ep/1"}`),
+		"Networks/azure/Endpoints/ep2":   json.RawMessage(`{"Id":"ep2"}`),
+	}, records)
+	require.JSONEq(t, `{"$schemaVersion":1,"Networks":{"azure":{"Id":"azure","Endpoints":{}},`+
+		`"empty":{"Id":"empty","Endpoints":null}}}`, string(skeleton))
+
+	joined, err := joinRecords(skeleton, records)
+	require.NoError(t, err)
+	require.JSONEq(t, string(value), string(joined))
+}
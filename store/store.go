@@ -4,8 +4,28 @@
 package store
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/Azure/azure-container-networking/processlock"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Types of KeyValueStore selectable in the CNS and CNI configuration.
+const (
+	// JSONStoreType keeps the whole store in a JSON file rewritten on every write. It is the default.
+	JSONStoreType = "json"
+	// BoltStoreType keeps every key in its own record of an embedded bbolt database.
+	BoltStoreType = "bolt"
+
+	// JSONExtension - Extension of the file of a JSON store.
+	JSONExtension = ".json"
+	// BoltExtension - Extension of the file of a bolt store.
+	BoltExtension = ".db"
+	// MigratedExtension - Extension added to the file of a JSON store once migrated to a bolt store.
+	MigratedExtension = ".migrated"
 )
 
 // KeyValueStore represents a persistent store of (key,value) pairs.
@@ -20,12 +40,68 @@ type KeyValueStore interface {
 	Remove()
 }
 
+// RecordStore is a KeyValueStore which keeps parts of a value as records of their own, so writing a large value
+// only writes the records that changed. Read returns the value with the records back in place.
+type RecordStore interface {
+	KeyValueStore
+	// WriteRecords saves value under key along with its records, by their path in the value. Records of the key
+	// which are not given are removed.
+	WriteRecords(key string, value json.RawMessage, records map[string]json.RawMessage) error
+}
+
 var (
 	// Errors returned by KeyValueStore methods.
 	ErrKeyNotFound                    = fmt.Errorf("key not found")
 	ErrStoreLocked                    = fmt.Errorf("store is already locked")
 	ErrStoreNotLocked                 = fmt.Errorf("store is not locked")
 	ErrStoreEmpty                     = fmt.Errorf("store is empty")
+	ErrStoreCorrupted                 = fmt.Errorf("store checksum mismatch")
 	ErrTimeoutLockingStore            = fmt.Errorf("timed out locking store")
 	ErrNonBlockingLockIsAlreadyLocked = fmt.Errorf("attempted to perform non-blocking lock on an already locked store")
+	ErrUnknownStoreType               = fmt.Errorf("unknown store type")
 )
+
+// NewStore creates a KeyValueStore of the given type for the state at path, a file name without extension.
+// The state is migrated to the configured type the first time the store is opened: a bolt store imports the JSON
+// file of the state and a JSON store exports the bolt database, e.g. to roll back to a version without bolt stores.
+// Without a configured type, the store the state is already in is used, see ResolveStoreType.
+func NewStore(storeType, path string, lockclient processlock.Interface, logger *zap.Logger) (KeyValueStore, error) {
+	switch ResolveStoreType(storeType, path) {
+	case JSONStoreType:
+		kvs, err := NewJsonFileStore(path+JSONExtension, lockclient, logger)
+		if err != nil {
+			return nil, err
+		}
+		if storeType == JSONStoreType {
+			kvs.(*jsonFileStore).migrateFrom = path + BoltExtension
+		}
+		return kvs, nil
+	case BoltStoreType:
+		kvs, err := NewBoltStore(path+BoltExtension, lockclient, logger)
+		if err != nil {
+			return nil, err
+		}
+		kvs.(*boltStore).migrateFrom = path + JSONExtension
+		return kvs, nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownStoreType, "%q", storeType)
+	}
+}
+
+// ResolveStoreType returns the type of the store to open for the state at path. Commands without a network config,
+// like GET_ENDPOINT_STATE, do not know the configured type, so when no type is configured the state is read from
+// the store it is in: a bolt store if its database exists, a JSON store otherwise.
+func ResolveStoreType(storeType, path string) string {
+	if storeType != "" {
+		return storeType
+	}
+	if fileExists(path + BoltExtension) {
+		return BoltStoreType
+	}
+	return JSONStoreType
+}
+
+// StateExists returns whether there is state at path, a file name without extension, in a store of any type.
+func StateExists(path string) bool {
+	return fileExists(path+JSONExtension) || fileExists(path+BoltExtension)
+}
@@ -43,6 +43,24 @@ func (tb *TelemetryBuffer) CreateAITelemetryHandle(aiConfig aitelemetry.AIConfig
 	return nil
 }
 
+// CreateOTLPTelemetryHandle adds export to an OpenTelemetry collector, alongside appinsights if its handle was
+// created.
+func (tb *TelemetryBuffer) CreateOTLPTelemetryHandle(otlpConfig aitelemetry.OTLPConfig, disableAll, disableMetric, disableTrace bool) error {
+	if disableAll {
+		return ErrTelemetryDisabled
+	}
+
+	otlpHandle, err := aitelemetry.NewOTLPTelemetry(otlpConfig)
+	if err != nil {
+		return err
+	}
+
+	th = aitelemetry.NewMultiTelemetry(th, otlpHandle)
+	gDisableMetric = disableMetric
+	gDisableTrace = disableTrace
+	return nil
+}
+
 func SendAITelemetry(cnireport CNIReport) {
 	if th == nil || gDisableTrace {
 		return
@@ -34,6 +34,12 @@ type TelemetryConfig struct {
 	BatchSizeInBytes              int
 	GetEnvRetryCount              int
 	GetEnvRetryWaitTimeInSecs     int
+	// OTLP collector endpoint (host:port), telemetry is also exported over OTLP if set
+	OTLPEndpoint string
+	// grpc or http/protobuf, grpc if empty
+	OTLPProtocol string
+	OTLPInsecure bool
+	OTLPHeaders  map[string]string
 }
 
 // FdName - file descriptor name
@@ -29,6 +29,12 @@ const (
 	FlagFollow      = "follow"
 	FlagLogFilePath = "log-file"
 
+	// NPM Explain Flags
+	FlagSrc      = "src"
+	FlagDst      = "dst"
+	FlagProtocol = "protocol"
+	FlagPort     = "port"
+
 	// tenancy flags
 	Singletenancy = "singletenancy"
 	Multitenancy  = "multitenancy"
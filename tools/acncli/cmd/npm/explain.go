@@ -0,0 +1,45 @@
+//go:build !ignore_uncovered
+// +build !ignore_uncovered
+
+package npm
+
+import (
+	"github.com/Azure/azure-container-networking/log"
+	npm "github.com/Azure/azure-container-networking/npm/http/client"
+	c "github.com/Azure/azure-container-networking/tools/acncli/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ExplainCmd explains whether Azure NPM allows flows between a source and destination
+func ExplainCmd(npmClient *npm.NPMHttpClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Explain why Azure NPM allows or denies flows from a source to a destination",
+		Long: "The explain command evaluates flows against the policies in Azure NPM on this node. " +
+			"The source and destination can each be an IP, a pod (namespace/name), or a namespace (all of its pods).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			explanation, err := npmClient.ExplainFlows(
+				viper.GetString(c.FlagSrc),
+				viper.GetString(c.FlagDst),
+				viper.GetString(c.FlagProtocol),
+				viper.GetInt(c.FlagPort),
+			)
+			if err == nil {
+				c.PrettyPrint(explanation)
+			} else {
+				log.Printf("err %v", err)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringP(c.FlagSrc, "s", "", "Source IP, pod (namespace/name), or namespace")
+	cmd.Flags().StringP(c.FlagDst, "d", "", "Destination IP, pod (namespace/name), or namespace")
+	cmd.Flags().String(c.FlagProtocol, "TCP", "Protocol of the flows")
+	cmd.Flags().IntP(c.FlagPort, "p", 0, "Destination port of the flows (optional)")
+	_ = cmd.MarkFlagRequired(c.FlagSrc)
+	_ = cmd.MarkFlagRequired(c.FlagDst)
+
+	return cmd
+}
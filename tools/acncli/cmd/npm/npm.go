@@ -30,6 +30,7 @@ func NPMRootCmd() *cobra.Command {
 	npmClient := npm.NewNPMHttpClient(npmEndpoint)
 
 	cmd.AddCommand(GetCmd(npmClient))
+	cmd.AddCommand(ExplainCmd(npmClient))
 	return cmd
 }
 
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// ExporterStdout writes the spans to stdout as indented JSON.
+	ExporterStdout = "stdout"
+	// ExporterFile appends the spans to Config.Path, one JSON object per line.
+	ExporterFile = "file"
+)
+
+var (
+	ErrUnknownExporter = errors.New("unknown trace exporter")
+	ErrMissingPath     = errors.New("path is required by the file exporter")
+)
+
+// Config selects the exporter spans are sent to.
+type Config struct {
+	// Exporter is the name of a registered exporter, e.g. stdout or file.
+	Exporter string
+	// Path is the file the file exporter appends to.
+	Path string
+	// ServiceName and ServiceVersion describe the process in the exported spans, unless Init adds the exporter to
+	// an existing tracer provider, which already has its resource.
+	ServiceName    string
+	ServiceVersion string
+}
+
+// ExporterFactory creates a span exporter from the config.
+type ExporterFactory func(Config) (sdktrace.SpanExporter, error)
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = map[string]ExporterFactory{
+		ExporterStdout: newStdoutExporter,
+		ExporterFile:   newFileExporter,
+	}
+)
+
+// RegisterExporter makes an exporter available to Init under name, replacing any exporter of the same name.
+func RegisterExporter(name string, factory ExporterFactory) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	exporters[name] = factory
+}
+
+// Init exports the spans to the exporter of the config. The exporter is added to the global tracer provider if it
+// was already set to an SDK one, e.g. by the OTLP telemetry handle, otherwise a tracer provider is registered.
+// The returned function flushes the pending spans and stops the export, it must be called before the process exits.
+func Init(config Config) (func(context.Context) error, error) {
+	exportersMu.RLock()
+	factory, ok := exporters[config.Exporter]
+	exportersMu.RUnlock()
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownExporter, config.Exporter)
+	}
+
+	exporter, err := factory(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s trace exporter", config.Exporter)
+	}
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
+
+	if tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+		tp.RegisterSpanProcessor(processor)
+		return func(ctx context.Context) error {
+			tp.UnregisterSpanProcessor(processor)
+			return errors.Wrap(processor.Shutdown(ctx), "failed to shut down span processor")
+		}, nil
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(config.ServiceName),
+			semconv.ServiceVersion(config.ServiceVersion),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+	return func(ctx context.Context) error {
+		return errors.Wrap(tp.Shutdown(ctx), "failed to shut down tracer provider")
+	}, nil
+}
+
+func newStdoutExporter(Config) (sdktrace.SpanExporter, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	return exporter, errors.Wrap(err, "failed to create stdout exporter")
+}
+
+// fileExporter closes its file once shut down.
+type fileExporter struct {
+	*stdouttrace.Exporter
+	file *os.File
+}
+
+func newFileExporter(config Config) (sdktrace.SpanExporter, error) {
+	if config.Path == "" {
+		return nil, ErrMissingPath
+	}
+
+	// spans are appended, so that the processes sharing the file (one per CNI command) do not truncate it
+	f, err := os.OpenFile(config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gomnd // readable traces
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", config.Path)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "failed to create file exporter")
+	}
+	return &fileExporter{Exporter: exporter, file: f}, nil
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	if err := e.Exporter.Shutdown(ctx); err != nil {
+		e.file.Close()
+		return errors.Wrap(err, "failed to shut down file exporter")
+	}
+	return errors.Wrap(e.file.Close(), "failed to close trace file")
+}
@@ -0,0 +1,73 @@
+// Package tracing propagates W3C trace context through the calls made for a pod, from the CNI plugin to CNS and from
+// CNS to NMAgent and IMDS, and exports the spans recorded along the way.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// TracerName is the instrumentation scope of the spans started with Start.
+const TracerName = "github.com/Azure/azure-container-networking"
+
+// propagator carries the trace context in the traceparent and tracestate headers (and gRPC metadata).
+// It is passed explicitly rather than registered globally, so importing packages do not depend on init order.
+var propagator = propagation.TraceContext{}
+
+// Start starts a span, child of the span carried by ctx if any. The span is a no-op unless a tracer provider was
+// registered, by Init or by the OTLP telemetry handle.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...)) //nolint:spancheck // ended by the caller
+}
+
+// End records err on span, if set, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Trace runs fn in a span named name.
+func Trace(ctx context.Context, name string, fn func(context.Context) error, attrs ...attribute.KeyValue) error {
+	ctx, span := Start(ctx, name, attrs...)
+	err := fn(ctx)
+	End(span, err)
+	return err
+}
+
+// NewTransport returns a RoundTripper which records a client span per request and sends the trace context of the
+// request in its headers. A nil base uses http.DefaultTransport.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base,
+		otelhttp.WithPropagators(propagator),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}))
+}
+
+// NewHandler returns a handler which continues the trace context sent in the request headers, recording a server
+// span named operation around h.
+func NewHandler(h http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(h, operation, otelhttp.WithPropagators(propagator))
+}
+
+// GRPCDialOption records a client span per call and sends the trace context in the gRPC metadata.
+func GRPCDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithPropagators(propagator)))
+}
+
+// GRPCServerOption continues the trace context sent in the gRPC metadata, recording a server span per call.
+func GRPCServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithPropagators(propagator)))
+}
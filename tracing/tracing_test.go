@@ -0,0 +1,180 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// recordSpans registers a tracer provider recording the ended spans for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func spansByName(spans []sdktrace.ReadOnlySpan) map[string]sdktrace.ReadOnlySpan {
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		byName[s.Name()] = s
+	}
+	return byName
+}
+
+func TestHTTPPropagation(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var traceparent string
+	srv := httptest.NewServer(NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, span := Start(r.Context(), "handler")
+		span.End()
+		w.WriteHeader(http.StatusOK)
+	}), "/test"))
+	defer srv.Close()
+
+	ctx, parent := Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/test", http.NoBody)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: NewTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End()
+
+	require.NotEmpty(t, traceparent)
+	spans := spansByName(recorder.Ended())
+	require.Len(t, spans, 4)
+	traceID := parent.SpanContext().TraceID()
+	for _, s := range spans {
+		require.Equal(t, traceID, s.SpanContext().TraceID(), s.Name())
+	}
+	require.Equal(t, parent.SpanContext().SpanID(), spans["GET /test"].Parent().SpanID())
+	require.Equal(t, spans["GET /test"].SpanContext().SpanID(), spans["/test"].Parent().SpanID())
+	require.True(t, spans["/test"].Parent().IsRemote())
+	require.Equal(t, spans["/test"].SpanContext().SpanID(), spans["handler"].Parent().SpanID())
+}
+
+func TestGRPCPropagation(t *testing.T) {
+	recorder := recordSpans(t)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(GRPCServerOption())
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis) //nolint:errcheck // stopped with the test
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), GRPCDialOption())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	parent.End()
+	srv.GracefulStop()
+
+	var server sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		require.Equal(t, parent.SpanContext().TraceID(), s.SpanContext().TraceID(), s.Name())
+		if s.Parent().IsRemote() {
+			server = s
+		}
+	}
+	require.NotNil(t, server)
+	require.Equal(t, "grpc.health.v1.Health/Check", server.Name())
+}
+
+func TestTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	errFailed := errors.New("failed")
+	err := Trace(context.Background(), "outer", func(ctx context.Context) error {
+		return Trace(ctx, "inner", func(context.Context) error { return errFailed })
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	spans := spansByName(recorder.Ended())
+	require.Equal(t, spans["outer"].SpanContext().SpanID(), spans["inner"].Parent().SpanID())
+	for _, s := range spans {
+		require.Equal(t, codes.Error, s.Status().Code)
+	}
+}
+
+func TestInitFileExporter(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	// the file exporter is added to the tracer provider already registered
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Init(Config{Exporter: ExporterFile, Path: path})
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second"} {
+		_, span := Start(context.Background(), name)
+		span.End()
+	}
+	require.NoError(t, shutdown(context.Background()))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var span struct{ Name string }
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		names = append(names, span.Name)
+	}
+	require.Equal(t, []string{"first", "second"}, names)
+}
+
+// keepSpans keeps the exported spans once shut down, unlike the InMemoryExporter.
+type keepSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepSpans) Shutdown(context.Context) error { return nil }
+
+func TestInitRegisteredExporter(t *testing.T) {
+	// a tracer provider is registered if the global one is not an SDK one
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	exporter := tracetest.NewInMemoryExporter()
+	RegisterExporter("memory", func(Config) (sdktrace.SpanExporter, error) { return keepSpans{exporter}, nil })
+
+	shutdown, err := Init(Config{Exporter: "memory", ServiceName: "test", ServiceVersion: "v1"})
+	require.NoError(t, err)
+	_, span := Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "span", spans[0].Name)
+	require.Contains(t, spans[0].Resource.String(), "service.name=test")
+}
+
+func TestInitErrors(t *testing.T) {
+	_, err := Init(Config{Exporter: "unknown"})
+	require.ErrorIs(t, err, ErrUnknownExporter)
+
+	_, err = Init(Config{Exporter: ExporterFile})
+	require.ErrorIs(t, err, ErrMissingPath)
+}
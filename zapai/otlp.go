@@ -0,0 +1,240 @@
+package zapai
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+const (
+	// OTLPSinkScheme is the registerable Sink Scheme for OTLP.
+	OTLPSinkScheme     = "otlp"
+	paramProtocol      = "protocol"
+	paramInsecure      = "insecure"
+	paramHeader        = "header"
+	paramServiceName   = "serviceName"
+	paramBatchInterval = "batchInterval"
+)
+
+// OTLP protocols
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+const instrumentationName = "github.com/Azure/azure-container-networking/zapai"
+
+var levelToOTLPSev = map[contracts.SeverityLevel]otellog.Severity{
+	contracts.Verbose:     otellog.SeverityDebug,
+	contracts.Information: otellog.SeverityInfo,
+	contracts.Warning:     otellog.SeverityWarn,
+	contracts.Error:       otellog.SeverityError,
+	contracts.Critical:    otellog.SeverityFatal,
+}
+
+var errUnknownOTLPProtocol = errors.New("unknown otlp protocol")
+
+func init() {
+	// register the otlp sink factory
+	_ = zap.RegisterSink(OTLPSinkScheme, otlpSinkbuilder)
+}
+
+// otlpSinkbuilder builds an OTLP Sink for zap from the passed URL, like sinkbuilder does for appinsights.
+func otlpSinkbuilder(u *url.URL) (zap.Sink, error) {
+	cfg, err := otlpFromURI(u)
+	if err != nil {
+		return nil, err
+	}
+	return newOTLPSink(cfg)
+}
+
+// OTLPSinkConfig is a container struct for an OTLP Sink configuration.
+type OTLPSinkConfig struct {
+	GracePeriod time.Duration
+	// Endpoint is the host:port of the collector.
+	Endpoint string
+	// Protocol is grpc or http/protobuf, grpc if empty.
+	Protocol string
+	// Insecure disables TLS to the collector.
+	Insecure bool
+	// Headers are sent with each export, e.g. for authentication.
+	Headers       map[string]string
+	ServiceName   string
+	BatchInterval time.Duration
+}
+
+// otlpFromURI parses a URL in to an OTLPSinkConfig.
+func otlpFromURI(u *url.URL) (*OTLPSinkConfig, error) {
+	q := u.Query()
+	gracePeriod, err := time.ParseDuration(q.Get(paramGracePeriod))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse duration parameter")
+	}
+	interval, err := time.ParseDuration(q.Get(paramBatchInterval))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse duration parameter")
+	}
+	insecure, err := strconv.ParseBool(q.Get(paramInsecure))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse insecure parameter")
+	}
+	headers := map[string]string{}
+	for _, h := range q[paramHeader] {
+		key, value, ok := strings.Cut(h, "=")
+		if !ok {
+			return nil, errors.Errorf("failed to parse header parameter %q", h)
+		}
+		headers[key] = value
+	}
+	return &OTLPSinkConfig{
+		GracePeriod:   gracePeriod,
+		Endpoint:      u.Host,
+		Protocol:      q.Get(paramProtocol),
+		Insecure:      insecure,
+		Headers:       headers,
+		ServiceName:   q.Get(paramServiceName),
+		BatchInterval: interval,
+	}, nil
+}
+
+// otlpToURI generates a URL from an OTLPSinkConfig.
+func otlpToURI(sc *OTLPSinkConfig) *url.URL {
+	u := &url.URL{
+		Scheme: OTLPSinkScheme,
+		Host:   sc.Endpoint,
+	}
+	q := u.Query()
+	q.Add(paramProtocol, sc.Protocol)
+	q.Add(paramInsecure, strconv.FormatBool(sc.Insecure))
+	q.Add(paramGracePeriod, sc.GracePeriod.String())
+	q.Add(paramBatchInterval, sc.BatchInterval.String())
+	q.Add(paramServiceName, sc.ServiceName)
+	keys := make([]string, 0, len(sc.Headers))
+	for key := range sc.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		q.Add(paramHeader, key+"="+sc.Headers[key])
+	}
+	u.RawQuery = q.Encode()
+	return u
+}
+
+// URI builds an OTLP Sink URI string suitable for passing to zap.Open.
+func (sc *OTLPSinkConfig) URI() string {
+	return otlpToURI(sc).String()
+}
+
+var _ zap.Sink = (*OTLPSink)(nil)
+
+// OTLPSink implements zap.Sink for an OpenTelemetry collector. It is used with the same Core as the appinsights
+// Sink, and exports the traces the Core writes as OpenTelemetry log records.
+// The Go log SDK is not stable yet, but log records are the only OTLP signal a log sink can map to, and the zapai
+// module is versioned on its own so the experimental dependency does not reach the ACN binaries.
+type OTLPSink struct {
+	*OTLPSinkConfig
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+	dec      traceDecoder
+}
+
+// newOTLPSink constructs an OTLPSink, with its exporter, from the passed OTLPSinkConfig.
+func newOTLPSink(cfg *OTLPSinkConfig) (*OTLPSink, error) {
+	exporter, err := newOTLPExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create otlp resource")
+	}
+
+	opts := []sdklog.BatchProcessorOption{}
+	if cfg.BatchInterval > 0 {
+		opts = append(opts, sdklog.WithExportInterval(cfg.BatchInterval))
+	}
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter, opts...)),
+	)
+	return &OTLPSink{
+		OTLPSinkConfig: cfg,
+		provider:       provider,
+		logger:         provider.Logger(instrumentationName),
+		dec:            newTraceDecoder(),
+	}, nil
+}
+
+// newOTLPExporter creates the log exporter for the configured protocol.
+func newOTLPExporter(cfg *OTLPSinkConfig) (sdklog.Exporter, error) {
+	ctx := context.Background()
+	switch cfg.Protocol {
+	case "", OTLPProtocolGRPC:
+		opts := []otlploggrpc.Option{otlploggrpc.WithEndpoint(cfg.Endpoint), otlploggrpc.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		}
+		exporter, err := otlploggrpc.New(ctx, opts...)
+		return exporter, errors.Wrap(err, "failed to create otlp log exporter")
+	case OTLPProtocolHTTP:
+		opts := []otlploghttp.Option{otlploghttp.WithEndpoint(cfg.Endpoint), otlploghttp.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlploghttp.WithInsecure())
+		}
+		exporter, err := otlploghttp.New(ctx, opts...)
+		return exporter, errors.Wrap(err, "failed to create otlp log exporter")
+	}
+	return nil, errors.Wrapf(errUnknownOTLPProtocol, "%s", cfg.Protocol)
+}
+
+// Write accepts a gob []byte that must be Decodable to an appinsights.TraceTelemetry{}, which is then emitted
+// as a log record with its properties and tags as attributes.
+func (s *OTLPSink) Write(b []byte) (int, error) {
+	t, err := s.dec.decode(b)
+	if err != nil {
+		return 0, errors.Wrap(err, "sink failed to decode trace")
+	}
+
+	var record otellog.Record
+	record.SetTimestamp(t.Timestamp)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(levelToOTLPSev[t.SeverityLevel])
+	record.SetSeverityText(t.SeverityLevel.String())
+	record.SetBody(otellog.StringValue(t.Message))
+	for key, value := range t.Tags {
+		record.AddAttributes(otellog.String(key, value))
+	}
+	for key, value := range t.Properties {
+		record.AddAttributes(otellog.String(key, value))
+	}
+	s.logger.Emit(context.Background(), record)
+	return 0, nil
+}
+
+// Sync exports the queued records.
+func (s *OTLPSink) Sync() error {
+	return errors.Wrap(s.provider.ForceFlush(context.Background()), "sink failed to flush")
+}
+
+// Close exports the queued records and shuts the exporter down.
+// Waits up to the GracePeriod duration for the export to complete.
+func (s *OTLPSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.GracePeriod)
+	defer cancel()
+	return errors.Wrap(s.provider.Shutdown(ctx), "sink failed to shut down")
+}